@@ -54,39 +54,85 @@ scaffold_test_project() {
   header_text "Initializing project ..."
   $kb init $init_flags --domain testproject.org --license apache2 --owner "The Kubernetes authors"
 
+  if [ $project == "project-v3-config" ]; then
+    header_text 'Changing the namespace ...'
+    $kb edit --namespace fleet-operators
+  fi
+
   if [ $project == "project-v2" ] || [ $project == "project-v3" ] || [ $project == "project-v3-config" ]; then
     header_text 'Creating APIs ...'
     $kb create api --group crew --version v1 --kind Captain --controller=true --resource=true --make=false
     $kb create api --group crew --version v1 --kind Captain --controller=true --resource=true --make=false --force
     $kb create webhook --group crew --version v1 --kind Captain --defaulting --programmatic-validation
-    $kb create api --group crew --version v1 --kind FirstMate --controller=true --resource=true --make=false
+    if [ $project == "project-v3" ]; then
+      $kb create api --group crew --version v1 --kind FirstMate --controller=true --resource=true --make=false --owner-index --adoption --defaults-configmap --with-child Deployment
+    else
+      $kb create api --group crew --version v1 --kind FirstMate --controller=true --resource=true --make=false
+    fi
     $kb create webhook --group crew --version v1 --kind FirstMate --conversion
-    $kb create api --group crew --version v1 --kind Admiral --controller=true --resource=true --namespaced=false --make=false
+    if [ $project == "project-v3" ]; then
+      $kb create api --group crew --version v1 --kind Admiral --controller=true --resource=true --namespaced=false --make=false --expectations --benchmark --api-docs --cache-label-selector app.kubernetes.io/managed-by=project-v3 --sample-tests
+    else
+      $kb create api --group crew --version v1 --kind Admiral --controller=true --resource=true --namespaced=false --make=false
+    fi
     $kb create webhook --group crew --version v1 --kind Admiral --defaulting
     $kb create api --group crew --version v1 --kind Laker --controller=true --resource=false --make=false
     if [ $project == "project-v3" ]; then
-      $kb create webhook --group crew --version v1 --kind Captain --defaulting --programmatic-validation --force
+      $kb create webhook --group crew --version v1 --kind Captain --defaulting --programmatic-validation --force --metrics --max-in-flight 50
+      $kb create webhook --group crew --version v1 --kind Admiral --programmatic-validation --failure-policy ignore --timeout-seconds 5
+      $kb create webhook --group crew --version v1 --kind FirstMate --owner-labels
+      $kb create webhook --group crew --version v1 --kind Captain --subresource status
     fi
   elif [[ $project =~ multigroup ]]; then
     header_text 'Switching to multigroup layout ...'
-    $kb edit --multigroup=true
+    if [ $project == "project-v3-multigroup" ]; then
+      $kb edit --multigroup=true --group-registration
+    else
+      $kb edit --multigroup=true
+    fi
 
     header_text 'Creating APIs ...'
     $kb create api --group crew --version v1 --kind Captain --controller=true --resource=true --make=false
     $kb create webhook --group crew --version v1 --kind Captain --defaulting --programmatic-validation
-    $kb create api --group ship --version v1beta1 --kind Frigate --controller=true --resource=true --make=false
+    if [ $project == "project-v3-multigroup" ]; then
+      $kb create api --group ship --version v1beta1 --kind Frigate --controller=true --resource=true --make=false --scale-subresource
+    else
+      $kb create api --group ship --version v1beta1 --kind Frigate --controller=true --resource=true --make=false
+    fi
     $kb create webhook --group ship --version v1beta1 --kind Frigate --conversion
-    $kb create api --group ship --version v1 --kind Destroyer --controller=true --resource=true --namespaced=false --make=false
+    if [ $project == "project-v3-multigroup" ]; then
+      $kb create api --group ship --version v1 --kind Destroyer --controller=true --resource=true --namespaced=false --make=false --resync-period 1h --raw-extension-field values --raw-extension-field template:resource
+    else
+      $kb create api --group ship --version v1 --kind Destroyer --controller=true --resource=true --namespaced=false --make=false
+    fi
     $kb create webhook --group ship --version v1 --kind Destroyer --defaulting
-    $kb create api --group ship --version v2alpha1 --kind Cruiser --controller=true --resource=true --namespaced=false --make=false
-    $kb create webhook --group ship --version v2alpha1 --kind Cruiser --programmatic-validation
-    $kb create api --group sea-creatures --version v1beta1 --kind Kraken --controller=true --resource=true --make=false
-    $kb create api --group sea-creatures --version v1beta2 --kind Leviathan --controller=true --resource=true --make=false
+    if [ $project == "project-v3-multigroup" ]; then
+      $kb create api --group ship --version v2alpha1 --kind Cruiser --controller=true --resource=true --namespaced=false --make=false --union
+    else
+      $kb create api --group ship --version v2alpha1 --kind Cruiser --controller=true --resource=true --namespaced=false --make=false
+    fi
+    if [ $project == "project-v3-multigroup" ]; then
+      $kb create webhook --group ship --version v2alpha1 --kind Cruiser --programmatic-validation --wasm-policy
+    else
+      $kb create webhook --group ship --version v2alpha1 --kind Cruiser --programmatic-validation
+    fi
+    if [ $project == "project-v3-multigroup" ]; then
+      $kb create api --group sea-creatures --version v1beta1 --kind Kraken --controller=true --resource=true --make=false --common-types --cross-namespace-children
+    else
+      $kb create api --group sea-creatures --version v1beta1 --kind Kraken --controller=true --resource=true --make=false
+    fi
+    if [ $project == "project-v3-multigroup" ]; then
+      $kb create api --group sea-creatures --version v1beta2 --kind Leviathan --controller=true --resource=true --make=false --metadata-only-watches --readiness-metrics --pausable
+    else
+      $kb create api --group sea-creatures --version v1beta2 --kind Leviathan --controller=true --resource=true --make=false
+    fi
     $kb create api --group foo.policy --version v1 --kind HealthCheckPolicy --controller=true --resource=true --make=false
     $kb create api --group apps --version v1 --kind Pod --controller=true --resource=false --make=false
     if [ $project == "project-v3-multigroup" ]; then
       $kb create api --version v1 --kind Lakers --controller=true --resource=true --make=false
       $kb create webhook --version v1 --kind Lakers --defaulting --programmatic-validation
+      $kb create webhook --group ship --version v1 --kind Destroyer --programmatic-validation --immutable-fields foo
+      $kb create webhook --group sea-creatures --version v1beta1 --kind Kraken --immutable-fields foo --immutability cel
     fi
   elif [[ $project =~ addon ]]; then
     header_text 'enabling --pattern flag ...'
@@ -96,6 +142,10 @@ scaffold_test_project() {
     $kb create api --group crew --version v1 --kind FirstMate --controller=true --resource=true --make=false --pattern=addon
     $kb create api --group crew --version v1 --kind Admiral --controller=true --resource=true --namespaced=false --make=false --pattern=addon
     unset KUBEBUILDER_ENABLE_PLUGINS
+  elif [[ $project =~ aggregated-apiserver ]]; then
+    header_text 'Creating APIs ...'
+    $kb create api --group ship --version v1 --kind Frigate --controller=false --resource=true --make=false
+    $kb create api --group ship --version v1alpha1 --kind Cruiser --controller=false --resource=true --namespaced=false --make=false
   fi
 
   make all test
@@ -115,7 +165,8 @@ scaffold_test_project project-v2 --project-version=2
 scaffold_test_project project-v2-multigroup --project-version=2
 scaffold_test_project project-v2-addon --project-version=2
 # Project version 3 (default) uses plugin go/v3 (default).
-scaffold_test_project project-v3
-scaffold_test_project project-v3-multigroup
+scaffold_test_project project-v3 --overlays --webhook-dev --dependency-updates renovate
+scaffold_test_project project-v3-multigroup --feature-gates --marker-docs --multi-cluster --dependency-updates dependabot
 scaffold_test_project project-v3-addon
-scaffold_test_project project-v3-config --component-config
+scaffold_test_project project-v3-aggregated-apiserver --pattern aggregated-apiserver
+scaffold_test_project project-v3-config --component-config --ip-family ipv6 --cert-provider vault --agent deployment --expose-metrics httproute --metrics-hostname metrics.testproject.org
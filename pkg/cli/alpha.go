@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func (c cli) newAlphaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alpha",
+		Short: "Experimental commands",
+		Long: `Experimental commands.
+
+These commands may change or be removed in any release, without deprecation.
+`,
+	}
+
+	// kubebuilder alpha backstage-template
+	cmd.AddCommand(c.newAlphaBackstageTemplateCmd())
+	// kubebuilder alpha deprecate-version
+	cmd.AddCommand(c.newAlphaDeprecateVersionCmd())
+	// kubebuilder alpha graph
+	cmd.AddCommand(c.newAlphaGraphCmd())
+	// kubebuilder alpha migrate
+	cmd.AddCommand(c.newAlphaMigrateCmd())
+	// kubebuilder alpha policies
+	cmd.AddCommand(c.newAlphaPoliciesCmd())
+	// kubebuilder alpha storage-versions
+	cmd.AddCommand(c.newAlphaStorageVersionsCmd())
+
+	return cmd
+}
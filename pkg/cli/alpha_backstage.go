@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/backstage"
+	internalconfig "sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+func (c cli) newAlphaBackstageTemplateCmd() *cobra.Command {
+	var opts backstage.Options
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "backstage-template",
+		Short: "Generate a Backstage software template running the init and create commands",
+		Long: fmt.Sprintf(`Generate a Backstage software template running the init and create commands.
+
+The template asks for the flags of init, create api and create webhook of the
+plugins of the CLI, a page of parameters per command, and runs the commands with
+them in the steps of the --action action, which passes each set parameter to its
+flag. The create api and create webhook steps are skipped when no kind or webhook
+is requested. The generated project is then published to GitHub and registered
+in the catalog.
+
+The template is regenerated when %[1]s is upgraded, so that it maps its new flags.
+`, c.commandName),
+		Example: fmt.Sprintf(`  # Print the template
+  %[1]s alpha backstage-template
+
+  # Write the template of the platform team, run by the kubebuilder:run action
+  %[1]s alpha backstage-template --owner group:platform --output template.yaml
+`, c.commandName),
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			commands, err := c.backstageCommands()
+			if err != nil {
+				return err
+			}
+			content, err := backstage.Template(opts, commands)
+			if err != nil {
+				return err
+			}
+			if output == "" {
+				_, err = os.Stdout.Write(content)
+				return err
+			}
+			if err := ioutil.WriteFile(output, content, 0644); err != nil { //nolint:gosec
+				return err
+			}
+			fmt.Printf("Template written to %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", "", "file where the template is written, printed if empty")
+	cmd.Flags().StringVar(&opts.Name, "name", "kubebuilder-operator", "name of the template")
+	cmd.Flags().StringVar(&opts.Title, "title", "Kubernetes operator", "title of the template")
+	cmd.Flags().StringVar(&opts.Description, "description",
+		fmt.Sprintf("Scaffold a Kubernetes operator with %s", c.commandName), "description of the template")
+	cmd.Flags().StringVar(&opts.Owner, "owner", "", "owner of the template in the catalog, e.g. group:platform")
+	cmd.Flags().StringVar(&opts.Action, "action", backstage.DefaultAction,
+		"action of the steps running the commands in the workspace of the template")
+	cmd.Flags().StringSliceVar(&opts.AllowedHosts, "allowed-hosts", []string{"github.com"},
+		"hosts of the repositories the projects may be published to")
+
+	return cmd
+}
+
+// backstageCommands returns the init, create api and create webhook commands of the resolved plugins, with
+// their flags bound to a new configuration.
+func (c cli) backstageCommands() ([]backstage.Command, error) {
+	var initSubcommand, apiSubcommand, webhookSubcommand plugin.Subcommand
+	for _, p := range c.resolvedPlugins {
+		if p, isInit := p.(plugin.Init); isInit && initSubcommand == nil {
+			initSubcommand = p.GetInitSubcommand()
+		}
+		if p, isCreateAPI := p.(plugin.CreateAPI); isCreateAPI && apiSubcommand == nil {
+			apiSubcommand = p.GetCreateAPISubcommand()
+		}
+		if p, isCreateWebhook := p.(plugin.CreateWebhook); isCreateWebhook && webhookSubcommand == nil {
+			webhookSubcommand = p.GetCreateWebhookSubcommand()
+		}
+	}
+	if initSubcommand == nil || apiSubcommand == nil {
+		return nil, fmt.Errorf("resolved plugins do not provide the init and create api commands: %v", c.pluginKeys)
+	}
+	cfg := internalconfig.New(internalconfig.DefaultPath)
+	cfg.Version = c.projectVersion
+
+	commands := []backstage.Command{
+		{
+			Args:        []string{"init"},
+			Title:       "Project",
+			Description: "Options of the project, see kubebuilder init --help",
+			Flags:       flagsOf(initSubcommand, cfg),
+			Required:    []string{"repo"},
+		},
+		{
+			Args:        []string{"create", "api"},
+			Title:       "API",
+			Description: "API of the project, none is created without a kind, see kubebuilder create api --help",
+			Flags:       flagsOf(apiSubcommand, cfg),
+			If:          []string{"kind"},
+		},
+	}
+	if webhookSubcommand != nil {
+		commands = append(commands, backstage.Command{
+			Args:        []string{"create", "webhook"},
+			Title:       "Webhooks",
+			Description: "Webhooks of the API, see kubebuilder create webhook --help",
+			Flags:       flagsOf(webhookSubcommand, cfg),
+			Inherit:     []string{"group", "version", "kind"},
+			If:          []string{"defaulting", "programmatic-validation", "conversion", "owner-labels"},
+		})
+	}
+	return commands, nil
+}
+
+func flagsOf(subcommand plugin.Subcommand, cfg *internalconfig.Config) *pflag.FlagSet {
+	subcommand.InjectConfig(&cfg.Config)
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	subcommand.BindFlags(fs)
+	return fs
+}
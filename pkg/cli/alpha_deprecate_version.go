@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/deprecation"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/projectcheck"
+	modelconfig "sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+func (c cli) newAlphaDeprecateVersionCmd() *cobra.Command {
+	var group, version, kind, replacement, warning, removeAfter, samplesDir string
+
+	cmd := &cobra.Command{
+		Use:   "deprecate-version",
+		Short: "Deprecate a version of an API in favor of a newer one",
+		Long: `Deprecate a version of an API in favor of a newer one.
+
+The version keeps being served, but the API server returns a warning to its clients,
+e.g. printed by kubectl, and marks it as deprecated in its discovery documents:
+  - the +kubebuilder:deprecatedversion marker is added to the type of the version,
+    with the warning;
+  - the samples of the version are updated to the replacement version, which should
+    be reviewed if the schemas of the versions differ;
+  - the deprecation is recorded in the PROJECT file, along with the replacement
+    version and the date after which the version may stop being served.
+
+The replacement defaults to the version of the kind with the highest priority, and the
+removal date to the deprecation policy of the Kubernetes APIs: 12 months for the GA
+versions, 9 months for the beta versions, and at once for the alpha versions. The doctor
+command reports the versions past their removal date.
+
+Run "make manifests" afterwards to regenerate the CRD.
+`,
+		Example: fmt.Sprintf(`  # Deprecate crew/v1beta1 Captain in favor of crew/v1
+  %[1]s alpha deprecate-version --group crew --version v1beta1 --kind Captain
+
+  # Deprecate it with a custom warning and removal date
+  %[1]s alpha deprecate-version --group crew --version v1beta1 --kind Captain \
+    --warning "use crew.example.org/v1 Captain, see https://example.org/migration" --remove-after 2022-06-30
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := config.LoadInitialized()
+			if err != nil {
+				return err
+			}
+			if cfg.IsV2() {
+				return fmt.Errorf("deprecating a version requires project version %q", modelconfig.Version3Alpha)
+			}
+
+			var res *modelconfig.ResourceData
+			var versions []string
+			knownReplacement := false
+			for i, r := range cfg.Resources {
+				if r.Group != group || r.Kind != kind || r.API == nil {
+					continue
+				}
+				versions = append(versions, r.Version)
+				if r.Version == version {
+					res = &cfg.Resources[i]
+				} else if r.Version == replacement {
+					knownReplacement = true
+				}
+			}
+			if res == nil {
+				return fmt.Errorf("no API found in the PROJECT file for group %q, version %q and kind %q",
+					group, version, kind)
+			}
+			if replacement == "" {
+				if replacement = deprecation.Replacement(versions, version); replacement == "" {
+					return fmt.Errorf("%s has no other version to replace %s, create it first with create api",
+						kind, version)
+				}
+			} else if !knownReplacement {
+				return fmt.Errorf("replacement version %q is not another version of %s in the PROJECT file, "+
+					"may be one of %s", replacement, kind, strings.Join(versions, ", "))
+			}
+			if removeAfter == "" {
+				removeAfter = deprecation.RemoveAfter(version, time.Now()).Format(modelconfig.DeprecationDateLayout)
+			} else if _, err := time.Parse(modelconfig.DeprecationDateLayout, removeAfter); err != nil {
+				return fmt.Errorf("removal date (%s) is invalid, expected a date such as %s",
+					removeAfter, modelconfig.DeprecationDateLayout)
+			}
+			qualifiedGroup := cfg.Domain
+			if group != "" {
+				qualifiedGroup = group + "." + cfg.Domain
+			}
+			if warning == "" {
+				warning = deprecation.Warning(qualifiedGroup, version, kind, replacement, removeAfter)
+			}
+
+			types := filepath.Join(projectcheck.APIDir(cfg.Config, *res), strings.ToLower(kind)+"_types.go")
+			content, err := ioutil.ReadFile(types) //nolint:gosec
+			if err != nil {
+				return fmt.Errorf("unable to read the types of %s: %v", version, err)
+			}
+			marked, err := deprecation.MarkTypes(string(content), kind, warning)
+			if err != nil {
+				return fmt.Errorf("unable to mark %s as deprecated: %v", types, err)
+			}
+			if err := ioutil.WriteFile(types, []byte(marked), 0644); err != nil { //nolint:gosec
+				return err
+			}
+			fmt.Printf("%s: marked as deprecated\n", types)
+			if strings.Contains(marked, "+kubebuilder:storageversion") {
+				fmt.Printf("%s: %s is the storage version, move the +kubebuilder:storageversion marker to %s "+
+					"and migrate the stored objects with \"alpha storage-versions\"\n", types, version, replacement)
+			}
+
+			moved, err := deprecation.MoveSamples(samplesDir, qualifiedGroup, kind, version, replacement)
+			if err != nil {
+				return fmt.Errorf("unable to update the samples: %v", err)
+			}
+			for _, path := range moved {
+				fmt.Printf("%s: updated to %s, review it against the schema of %s\n", path, replacement, replacement)
+			}
+
+			res.API.Deprecation = &modelconfig.Deprecation{
+				Warning:     warning,
+				Replacement: replacement,
+				RemoveAfter: removeAfter,
+			}
+			if err := cfg.Save(); err != nil {
+				return err
+			}
+			fmt.Printf("%s %s deprecated in favor of %s, may be removed after %s: run \"make manifests\" to "+
+				"regenerate the CRD\n", kind, version, replacement, removeAfter)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&group, "group", "", "resource Group")
+	cmd.Flags().StringVar(&version, "version", "", "resource Version to deprecate")
+	cmd.Flags().StringVar(&kind, "kind", "", "resource Kind")
+	cmd.Flags().StringVar(&replacement, "replacement", "",
+		"version replacing the deprecated one, defaults to the version of the kind with the highest priority")
+	cmd.Flags().StringVar(&warning, "warning", "",
+		"warning returned by the API server to the clients of the version, defaults to one naming the replacement")
+	cmd.Flags().StringVar(&removeAfter, "remove-after", "",
+		"date, such as 2006-01-02, after which the version may stop being served, defaults to the deprecation "+
+			"policy of the Kubernetes APIs")
+	cmd.Flags().StringVar(&samplesDir, "samples-dir", filepath.Join("config", "samples"),
+		"directory containing the samples of the APIs")
+
+	return cmd
+}
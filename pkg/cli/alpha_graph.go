@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/graph"
+)
+
+func (c cli) newAlphaGraphCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Render the kinds of the project, the objects they own and their webhooks as a graph",
+		Long: `Render the kinds of the project, the objects they own and their webhooks as a graph.
+
+The graph holds a node for every resource of the PROJECT file, labelled with its group,
+its versions and the types of its webhooks. The controllers are then parsed for the Owns
+calls of their builders, adding an edge from the kind passed to For to every owned kind.
+The kinds that are not APIs of the project, such as the builtin ones, are drawn dashed in
+the DOT language and rounded in mermaid.
+
+Only the types written as composite literals, e.g. Owns(&appsv1.Deployment{}), are found.
+The graph is written to the standard output, in the DOT language of Graphviz or as a
+mermaid flowchart which may be embedded in a markdown document.
+`,
+		Example: fmt.Sprintf(`  # Render the graph as a SVG image with Graphviz
+  %[1]s alpha graph | dot -Tsvg > graph.svg
+
+  # Render the graph as a mermaid flowchart
+  %[1]s alpha graph --format mermaid
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := config.LoadInitialized()
+			if err != nil {
+				return err
+			}
+
+			g, err := graph.Load(cfg.Config, ".")
+			if err != nil {
+				return fmt.Errorf("unable to load the graph of the project: %v", err)
+			}
+			out, err := g.Render(format)
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", graph.FormatDOT,
+		fmt.Sprintf("format of the graph, one of %q, %q", graph.FormatDOT, graph.FormatMermaid))
+
+	return cmd
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/migrate"
+)
+
+func (c cli) newAlphaMigrateCmd() *cobra.Command {
+	var force, dryRun bool
+	var minK8sVersion string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate a go/v2 project to the go/v3 layout in place",
+		Long: fmt.Sprintf(`Migrate a project scaffolded with the %[1]s plugin to the %[2]s layout in place.
+
+The scaffolding of the project is generated twice in a temporary directory, from
+the resources of the PROJECT file and the types, controllers and webhooks found in
+the project: with %[1]s, as the project was initialized, and with %[2]s.
+The changes between both are then merged into the files of the project:
+  - the files the user did not modify are replaced by their %[2]s version;
+  - the files the user modified are merged three ways, keeping the changes of the
+    user, and left with conflict markers where both changed the same lines;
+  - the files moved by %[2]s, such as the webhook patches of config/default, are
+    merged at their new path;
+  - the files %[2]s does not scaffold anymore are removed, or kept if modified;
+  - the requirements of go.mod are raised to the versions of %[2]s;
+  - the PROJECT file is rewritten with the %[2]s layout.
+The files that are not part of the scaffolding, such as the code of the user, are
+left untouched.
+
+The project must be a git repository without uncommitted changes, unless --force is
+set, so that the migration can be reviewed and reverted with git.
+`, migrate.PluginV2, migrate.PluginV3),
+		Example: fmt.Sprintf(`  # Print the changes that would be made
+  %[1]s alpha migrate --dry-run
+
+  # Migrate the project, keeping the CRDs and webhooks of the clusters older than 1.16
+  %[1]s alpha migrate --min-k8s-version 1.15
+`, c.commandName),
+		Args: cobra.NoArgs,
+		// The errors are not caused by the usage once the arguments are parsed
+		SilenceUsage: true,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := config.LoadInitialized()
+			if err != nil {
+				return err
+			}
+			if err := migrate.CanMigrate(cfg.Config); err != nil {
+				return err
+			}
+			if !force && !dryRun {
+				if err := checkCleanWorktree(); err != nil {
+					return err
+				}
+			}
+
+			dir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			binary, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("unable to find the %s binary: %v", c.commandName, err)
+			}
+			resources, err := migrate.Detect(cfg.Config, dir)
+			if err != nil {
+				return fmt.Errorf("unable to detect the scaffolding of the resources: %v", err)
+			}
+
+			tmp, err := ioutil.TempDir("", "kubebuilder-migrate-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(tmp)
+			// The scaffolding is generated in directories named after the project, whose name defaults to it
+			baseDir := filepath.Join(tmp, "base", filepath.Base(dir))
+			targetDir := filepath.Join(tmp, "target", filepath.Base(dir))
+
+			var extraInitArgs []string
+			if minK8sVersion != "" {
+				extraInitArgs = append(extraInitArgs, "--min-k8s-version", minK8sVersion)
+			}
+			for _, scaffolding := range []struct {
+				dir, plugin string
+			}{{baseDir, migrate.PluginV2}, {targetDir, migrate.PluginV3}} {
+				fmt.Printf("Scaffolding the project with %s\n", scaffolding.plugin)
+				if err := os.MkdirAll(scaffolding.dir, 0755); err != nil {
+					return err
+				}
+				commands := migrate.Commands(cfg.Config, resources, scaffolding.plugin, extraInitArgs...)
+				if err := migrate.Scaffold(binary, scaffolding.dir, commands); err != nil {
+					return err
+				}
+				if err := migrate.ReplaceBoilerplate(scaffolding.dir, dir); err != nil {
+					return err
+				}
+			}
+
+			changes, err := migrate.Plan(dir, baseDir, targetDir)
+			if err != nil {
+				return err
+			}
+			conflicts := 0
+			for _, change := range changes {
+				fmt.Println(change)
+				if change.Action == migrate.Conflict {
+					conflicts++
+				}
+			}
+			if dryRun {
+				return nil
+			}
+			if err := migrate.Apply(dir, changes); err != nil {
+				return err
+			}
+
+			fmt.Printf("Migrated the project to %s.\n", migrate.PluginV3)
+			if conflicts != 0 {
+				return fmt.Errorf("%d file(s) have conflicts to resolve, then run go mod tidy and make", conflicts)
+			}
+			fmt.Println("Next: review the changes, then run go mod tidy and make.")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes without making them")
+	cmd.Flags().BoolVar(&force, "force", false,
+		"migrate the project even if it is not a git repository or has uncommitted changes")
+	cmd.Flags().StringVar(&minK8sVersion, "min-k8s-version", "",
+		"oldest Kubernetes version supported by the project, passed to the init of the go/v3 scaffolding, "+
+			"e.g. 1.15 to keep the v1beta1 CRDs and webhooks of go/v2")
+
+	return cmd
+}
+
+// checkCleanWorktree returns an error unless the current directory is in a git repository without
+// uncommitted changes.
+func checkCleanWorktree() error {
+	out, err := exec.Command("git", "status", "--porcelain").Output()
+	if err != nil {
+		return errors.New("the project must be a git repository to review and revert the migration, " +
+			"use --force to migrate it anyway")
+	}
+	if strings.TrimSpace(string(out)) != "" {
+		return errors.New("the project has uncommitted changes, commit them first or use --force " +
+			"to migrate it anyway")
+	}
+	return nil
+}
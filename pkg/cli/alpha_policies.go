@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/policy"
+)
+
+const (
+	policyEngineGatekeeper = "gatekeeper"
+	policyEngineCEL        = "cel"
+)
+
+func (c cli) newAlphaPoliciesCmd() *cobra.Command {
+	var engine, inputDir, outputDir string
+
+	cmd := &cobra.Command{
+		Use:   "policies",
+		Short: "Generate admission policies from the validation markers of the API types",
+		Long: `Generate admission policies from the validation markers of the API types.
+
+The Minimum, Maximum, MinLength, MaxLength, MinItems, MaxItems, Pattern and Enum
++kubebuilder:validation markers of the fields of the API types are translated into
+either Gatekeeper ConstraintTemplates (Rego) or ValidatingAdmissionPolicies (CEL),
+for clusters that centralize validation outside of the webhooks of the operators.
+One file is written per kind, along with a kustomization.yaml listing them.
+`,
+		Example: fmt.Sprintf(`  # Generate Gatekeeper ConstraintTemplates and Constraints in config/policies
+  %[1]s alpha policies --engine gatekeeper
+
+  # Generate ValidatingAdmissionPolicies and their bindings in config/policies
+  %[1]s alpha policies --engine cel
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			generate := map[string]func(policy.Kind) ([]byte, error){
+				policyEngineGatekeeper: policy.Gatekeeper,
+				policyEngineCEL:        policy.CEL,
+			}[engine]
+			if generate == nil {
+				return fmt.Errorf("policy engine (%s) is invalid: may be one of %q, %q",
+					engine, policyEngineGatekeeper, policyEngineCEL)
+			}
+
+			if inputDir == "" {
+				cfg, err := config.LoadInitialized()
+				if err != nil {
+					return err
+				}
+				inputDir = "api"
+				if cfg.MultiGroup {
+					inputDir = "apis"
+				}
+			}
+
+			kinds, err := policy.Load(inputDir)
+			if err != nil {
+				return fmt.Errorf("unable to load the API types: %v", err)
+			}
+			if len(kinds) == 0 {
+				fmt.Printf("No supported validation marker found in %s\n", inputDir)
+				return nil
+			}
+
+			if err := os.MkdirAll(outputDir, 0755); err != nil {
+				return err
+			}
+			resources := make([]string, 0, len(kinds))
+			for _, kind := range kinds {
+				content, err := generate(kind)
+				if err != nil {
+					return fmt.Errorf("unable to generate the policy of %s: %v", kind.Kind, err)
+				}
+				name := fmt.Sprintf("%s_%s_%s.yaml", kind.Group, kind.Version, strings.ToLower(kind.Kind))
+				if err := ioutil.WriteFile(filepath.Join(outputDir, name), content, 0644); err != nil { //nolint:gosec
+					return err
+				}
+				resources = append(resources, "- "+name)
+				fmt.Printf("%s: %d rule(s) written to %s\n", kind.Kind, len(kind.Rules), filepath.Join(outputDir, name))
+			}
+			kustomization := fmt.Sprintf("resources:\n%s\n", strings.Join(resources, "\n"))
+			return ioutil.WriteFile(filepath.Join(outputDir, "kustomization.yaml"), []byte(kustomization), 0644) //nolint:gosec
+		},
+	}
+
+	cmd.Flags().StringVar(&engine, "engine", policyEngineGatekeeper,
+		fmt.Sprintf("policy engine, may be one of %q, %q", policyEngineGatekeeper, policyEngineCEL))
+	cmd.Flags().StringVar(&inputDir, "input-dir", "",
+		"directory containing the API types, defaults to the one of the project layout")
+	cmd.Flags().StringVar(&outputDir, "output-dir", filepath.Join("config", "policies"),
+		"directory where the policies are written")
+
+	return cmd
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/crdlint"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/storageversion"
+)
+
+func (c cli) newAlphaStorageVersionsCmd() *cobra.Command {
+	var crdDir, samplesDir, outputDir string
+
+	cmd := &cobra.Command{
+		Use:   "storage-versions",
+		Short: "Audit the versions of the APIs and generate their storage version migrations",
+		Long: `Audit the versions of the APIs and generate their storage version migrations.
+
+Kinds with several versions and no conversion webhook are served by the API server
+relabelling the stored objects, which only works if every served version shares the
+schema of the storage version, chosen with the +kubebuilder:storageversion marker.
+The following checks are run on the generated CRDs of the PROJECT resources:
+  - every version of the PROJECT file is in the CRD;
+  - the CRD has exactly one storage version;
+  - the served versions have the schema of the storage version, unless the kind has
+    a conversion webhook;
+  - the samples do not use deprecated or unserved versions.
+
+A StorageVersionMigration is then written for every kind with several versions, which
+the kube-storage-version-migrator (https://github.com/kubernetes-sigs/kube-storage-version-migrator)
+runs to rewrite the stored objects in the storage version, along with a kustomization.yaml
+listing them. Once the migrations have completed, the old versions can be removed from the
+status.storedVersions of the CRDs and stop being served.
+
+Run "make manifests" first so that the CRDs are up to date. The command fails if an
+error is found, warnings are only reported.
+`,
+		Example: fmt.Sprintf(`  # Audit the versions and write the migrations in config/storage-migration
+  %[1]s alpha storage-versions
+
+  # Apply the migrations once the kube-storage-version-migrator is installed
+  kustomize build config/storage-migration | kubectl apply -f -
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := config.LoadInitialized()
+			if err != nil {
+				return err
+			}
+
+			kinds, problems, err := storageversion.Load(cfg.Config, crdDir)
+			if err != nil {
+				return fmt.Errorf("unable to load the CRDs: %v", err)
+			}
+			auditProblems, err := storageversion.Audit(kinds, samplesDir)
+			if err != nil {
+				return fmt.Errorf("unable to load the samples: %v", err)
+			}
+			problems = append(problems, auditProblems...)
+
+			errors := 0
+			for _, problem := range problems {
+				fmt.Println(problem)
+				if problem.Severity == crdlint.Error {
+					errors++
+				}
+			}
+			if errors != 0 {
+				return fmt.Errorf("found %d error(s)", errors)
+			}
+
+			var resources []string
+			for _, kind := range kinds {
+				if len(kind.Versions) < 2 {
+					continue
+				}
+				content, err := storageversion.Migration(kind)
+				if err != nil {
+					return err
+				}
+				if err := os.MkdirAll(outputDir, 0755); err != nil {
+					return err
+				}
+				name := fmt.Sprintf("%s_%s.yaml", kind.Group, kind.Plural)
+				if err := ioutil.WriteFile(filepath.Join(outputDir, name), content, 0644); err != nil { //nolint:gosec
+					return err
+				}
+				resources = append(resources, "- "+name)
+				fmt.Printf("%s: migration to %s written to %s\n",
+					kind.CRD, kind.StorageVersion(), filepath.Join(outputDir, name))
+			}
+			if len(resources) == 0 {
+				fmt.Println("No kind with several versions found")
+				return nil
+			}
+			kustomization := fmt.Sprintf("resources:\n%s\n", strings.Join(resources, "\n"))
+			return ioutil.WriteFile(filepath.Join(outputDir, "kustomization.yaml"), []byte(kustomization), 0644) //nolint:gosec
+		},
+	}
+
+	cmd.Flags().StringVar(&crdDir, "crd-dir", filepath.Join("config", "crd", "bases"),
+		"directory containing the generated CRDs")
+	cmd.Flags().StringVar(&samplesDir, "samples-dir", filepath.Join("config", "samples"),
+		"directory containing the samples of the APIs")
+	cmd.Flags().StringVar(&outputDir, "output-dir", filepath.Join("config", "storage-migration"),
+		"directory where the storage version migrations are written")
+
+	return cmd
+}
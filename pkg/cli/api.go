@@ -29,6 +29,7 @@ func (c cli) newCreateAPICmd() *cobra.Command {
 	ctx := c.newAPIContext()
 	cmd := &cobra.Command{
 		Use:     "api",
+		Aliases: []string{"resource"},
 		Short:   "Scaffold a Kubernetes API",
 		Long:    ctx.Description,
 		Example: ctx.Examples,
@@ -37,6 +38,8 @@ func (c cli) newCreateAPICmd() *cobra.Command {
 		),
 	}
 
+	bindPluginsFlag(cmd)
+
 	// Lookup the plugin for projectVersion and bind it to the command.
 	c.bindCreateAPI(ctx, cmd)
 	return cmd
@@ -84,10 +87,7 @@ func (c cli) bindCreateAPI(ctx plugin.Context, cmd *cobra.Command) {
 
 	subcommand := createAPIPlugin.GetCreateAPISubcommand()
 	subcommand.InjectConfig(&cfg.Config)
-	subcommand.BindFlags(cmd.Flags())
-	subcommand.UpdateContext(&ctx)
-	cmd.Long = ctx.Description
-	cmd.Example = ctx.Examples
+	bindSubcommand(cmd, createAPIPlugin, subcommand, ctx)
 	cmd.RunE = runECmdFunc(cfg, subcommand,
 		fmt.Sprintf("failed to create API with %q", plugin.KeyFor(createAPIPlugin)))
 }
@@ -26,6 +26,9 @@ import (
 	"github.com/spf13/pflag"
 
 	internalconfig "sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/conflict"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/debug"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/execution"
 	"sigs.k8s.io/kubebuilder/v2/pkg/internal/validation"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
@@ -34,30 +37,21 @@ import (
 const (
 	noticeColor    = "\033[1;36m%s\033[0m"
 	deprecationFmt = "[Deprecation Notice] %s\n\n"
+	layoutFmt      = "[Layout Notice] %s\n\n"
 
 	projectVersionFlag = "project-version"
 	pluginsFlag        = "plugins"
+	debugFlag          = "debug"
+	verboseFlag        = "verbose"
+	traceFlag          = "trace"
+	yesFlag            = "yes"
+	noExecFlag         = "no-exec"
+	allowExecFlag      = "allow-exec"
+	onConflictFlag     = "on-conflict"
 
 	noPluginError = "invalid config file please verify that the version and layout fields are set and valid"
 )
 
-// equalStringSlice checks if two string slices are equal.
-func equalStringSlice(a, b []string) bool {
-	// Check lengths
-	if len(a) != len(b) {
-		return false
-	}
-
-	// Check elements
-	for i, v := range a {
-		if v != b[i] {
-			return false
-		}
-	}
-
-	return true
-}
-
 // CLI interacts with a command line interface.
 type CLI interface {
 	// Run runs the CLI, usually returning an error if command line configuration
@@ -94,11 +88,30 @@ type cli struct { //nolint:maligned
 
 	// A filtered set of plugins that should be used by command constructors.
 	resolvedPlugins []plugin.Plugin
+	// Notice written when the plugin keys of the flags diverge from the layout of the project config file.
+	layoutNotice string
 
 	// Whether some generic help should be printed, i.e. if the binary
 	// was invoked outside of a project with incorrect flags or -h|--help.
 	doHelp bool
 
+	// Whether debug information, such as the timings of external commands, should be printed.
+	debug bool
+	// Whether file writes, marker insertions and external commands should be logged.
+	verbose bool
+	// Whether template renders should also be logged. Implies verbose.
+	trace bool
+
+	// Whether the external commands of the plugins are executed without confirmation.
+	yes bool
+	// Whether the external commands of the plugins are never executed.
+	noExec bool
+	// Commands the plugins may execute.
+	allowExec []string
+
+	// How the scaffolded files that already exist are handled.
+	onConflict string
+
 	// Root command.
 	cmd *cobra.Command
 }
@@ -116,6 +129,33 @@ func New(opts ...Option) (CLI, error) {
 		return nil, err
 	}
 
+	// Enable debug output and logging as soon as possible so that they cover plugin resolution.
+	debug.SetEnabled(c.debug)
+	switch {
+	case c.trace:
+		debug.SetLevel(debug.LevelTrace)
+	case c.verbose:
+		debug.SetLevel(debug.LevelVerbose)
+	}
+
+	// Decide how the external commands of the plugins are executed.
+	switch {
+	case c.yes && c.noExec:
+		return nil, fmt.Errorf("--%s and --%s are mutually exclusive", yesFlag, noExecFlag)
+	case c.yes:
+		execution.SetMode(execution.ModeYes)
+	case c.noExec:
+		execution.SetMode(execution.ModeNone)
+	}
+	execution.SetAllowed(c.allowExec)
+
+	// Decide how the scaffolded files that already exist are handled.
+	strategy, err := conflict.ParseStrategy(c.onConflict)
+	if err != nil {
+		return nil, err
+	}
+	conflict.SetStrategy(strategy)
+
 	// Resolve plugins for project version and plugin keys.
 	if err := c.resolve(); err != nil {
 		return nil, err
@@ -136,10 +176,13 @@ func New(opts ...Option) (CLI, error) {
 
 	// Write deprecation notices after all commands have been constructed.
 	for _, p := range c.resolvedPlugins {
-		if d, isDeprecated := p.(plugin.Deprecated); isDeprecated {
-			fmt.Printf(noticeColor, fmt.Sprintf(deprecationFmt, d.DeprecationWarning()))
+		if d, isDeprecated := plugin.DeprecationOf(p); isDeprecated {
+			fmt.Printf(noticeColor, fmt.Sprintf(deprecationFmt, d))
 		}
 	}
+	if c.layoutNotice != "" {
+		fmt.Printf(noticeColor, fmt.Sprintf(layoutFmt, c.layoutNotice))
+	}
 
 	return c, nil
 }
@@ -179,6 +222,13 @@ func (c *cli) getInfoFromFlags() (string, []string) {
 	fs.StringVar(&projectVersion, projectVersionFlag, "", "project version")
 	fs.StringVar(&plugins, pluginsFlag, "", "plugins to run")
 	fs.BoolVarP(&help, "help", "h", false, "help flag")
+	fs.BoolVar(&c.debug, debugFlag, false, "debug flag")
+	fs.BoolVarP(&c.verbose, verboseFlag, "v", false, "verbose flag")
+	fs.BoolVar(&c.trace, traceFlag, false, "trace flag")
+	fs.BoolVar(&c.yes, yesFlag, false, "yes flag")
+	fs.BoolVar(&c.noExec, noExecFlag, false, "no-exec flag")
+	fs.StringSliceVar(&c.allowExec, allowExecFlag, execution.DefaultAllowed, "allow-exec flag")
+	fs.StringVar(&c.onConflict, onConflictFlag, string(conflict.StrategyError), "on-conflict flag")
 
 	// Parse the arguments
 	err := fs.Parse(os.Args[1:])
@@ -230,7 +280,9 @@ func getInfoFromConfig(projectConfig *config.Config) (string, []string, error) {
 
 // resolveFlagsAndConfigFileConflicts checks if the provided combined input from flags and
 // the config file is valid and uses default values in case some info was not provided.
-func (c cli) resolveFlagsAndConfigFileConflicts(
+// The plugin keys of the flags prefixed with "-" are removed from the layout of the config file, or from the
+// default plugins, and the other ones replace them, with a notice if they diverge from the layout.
+func (c *cli) resolveFlagsAndConfigFileConflicts(
 	flagProjectVersion, cfgProjectVersion string,
 	flagPlugins, cfgPlugins []string,
 ) (string, []string, error) {
@@ -262,23 +314,38 @@ func (c cli) resolveFlagsAndConfigFileConflicts(
 	}
 
 	// Resolve plugins
+	removedPlugins, err := negatedPluginKeys(flagPlugins)
+	if err != nil {
+		return "", nil, err
+	}
 	var plugins []string
 	switch {
+	// If they are negated, remove them from the layout, or from the default if there is none
+	case len(removedPlugins) != 0:
+		layout := cfgPlugins
+		if len(layout) == 0 {
+			layout = c.defaultPlugins[projectVersion]
+		}
+		if plugins, err = withoutPluginKeys(layout, removedPlugins); err != nil {
+			return "", nil, err
+		}
 	// If they are both blank, use the default
 	case len(flagPlugins) == 0 && len(cfgPlugins) == 0:
 		plugins = c.defaultPlugins[projectVersion]
-	// If they are equal doesn't matter which we choose
-	case equalStringSlice(flagPlugins, cfgPlugins):
+	// If they match doesn't matter which we choose
+	case matchPluginKeys(cfgPlugins, flagPlugins):
 		plugins = flagPlugins
 	// If any is blank, choose the other
 	case len(cfgPlugins) == 0:
 		plugins = flagPlugins
 	case len(flagPlugins) == 0:
 		plugins = cfgPlugins
-	// If none is blank and they are different error out
+	// If none is blank and they diverge, the flags win for this command only
 	default:
-		return "", nil, fmt.Errorf("plugins conflict between command line args (%v) "+
-			"and project configuration file (%v)", flagPlugins, cfgPlugins)
+		plugins = flagPlugins
+		c.layoutNotice = fmt.Sprintf("the plugins of the command line (%s) diverge from the layout of the "+
+			"project configuration file (%s), which the commands run without --%s keep using",
+			strings.Join(flagPlugins, ","), strings.Join(cfgPlugins, ","), pluginsFlag)
 	}
 	// Validate the plugins
 	for _, p := range plugins {
@@ -290,6 +357,71 @@ func (c cli) resolveFlagsAndConfigFileConflicts(
 	return projectVersion, plugins, nil
 }
 
+// negatedPluginKeys returns the plugin keys prefixed with "-", without it, or nil if none is. The plugin keys
+// can not be both negated and not negated.
+func negatedPluginKeys(pluginKeys []string) ([]string, error) {
+	var negated []string
+	for _, key := range pluginKeys {
+		if strings.HasPrefix(key, "-") {
+			negated = append(negated, strings.TrimPrefix(key, "-"))
+		}
+	}
+	if len(negated) != 0 && len(negated) != len(pluginKeys) {
+		return nil, fmt.Errorf("plugins (%s) can not mix the keys prefixed with \"-\", removed from the layout, "+
+			"and the other ones, which replace it", strings.Join(pluginKeys, ","))
+	}
+	return negated, nil
+}
+
+// withoutPluginKeys returns the plugin keys of layout that do not match any of removed, every removed key having
+// to match one of them.
+func withoutPluginKeys(layout, removed []string) ([]string, error) {
+	kept := append([]string(nil), layout...)
+	for _, pattern := range removed {
+		i := 0
+		for _, key := range kept {
+			if !matchesPluginKey(key, pattern) {
+				kept[i] = key
+				i++
+			}
+		}
+		if i == len(kept) {
+			return nil, fmt.Errorf("plugin %q can not be removed: it is not in the layout (%s)",
+				pattern, strings.Join(layout, ","))
+		}
+		kept = kept[:i]
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("plugins (-%s) can not remove every plugin of the layout (%s)",
+			strings.Join(removed, ",-"), strings.Join(layout, ","))
+	}
+	return kept, nil
+}
+
+// matchPluginKeys returns true if every key of patterns matches the key of layout at the same position.
+func matchPluginKeys(layout, patterns []string) bool {
+	if len(layout) != len(patterns) {
+		return false
+	}
+	for i := range layout {
+		if !matchesPluginKey(layout[i], patterns[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesPluginKey returns true if pattern, a plugin key whose name may be short and whose version may be
+// omitted, as accepted by --plugins, designates the plugin of key.
+func matchesPluginKey(key, pattern string) bool {
+	name, version := plugin.SplitKey(key)
+	patternName, patternVersion := plugin.SplitKey(pattern)
+	if patternName != name && patternName != plugin.GetShortName(name) {
+		return false
+	}
+	return patternVersion == "" || patternVersion == version
+}
+
 // getInfo obtains the project version and plugin keys resolving conflicts among flags and the project config file.
 func (c *cli) getInfo() error {
 	// Get project version and plugin info from flags
@@ -404,12 +536,38 @@ func (c *cli) resolve() error {
 func (c cli) buildRootCmd() *cobra.Command {
 	rootCmd := c.defaultCommand()
 
+	// Register --debug for every command so that it shows up in help and does not cause a parse error.
+	rootCmd.PersistentFlags().Bool(debugFlag, false,
+		"print debug information, such as the time taken by each step and external command, to stderr")
+	rootCmd.PersistentFlags().BoolP(verboseFlag, "v", false,
+		"log every file write, marker insertion and external command to stderr")
+	rootCmd.PersistentFlags().Bool(traceFlag, false,
+		"log every template render to stderr, in addition to the messages logged with --verbose")
+	rootCmd.PersistentFlags().Bool(yesFlag, false,
+		"execute the external commands of the plugins, such as make or go get, without asking for a confirmation")
+	rootCmd.PersistentFlags().Bool(noExecFlag, false,
+		"never execute the external commands of the plugins, printing them instead")
+	rootCmd.PersistentFlags().StringSlice(allowExecFlag, execution.DefaultAllowed,
+		"commands the plugins may execute, the other ones are printed instead")
+	rootCmd.PersistentFlags().String(onConflictFlag, string(conflict.StrategyError),
+		"how the scaffolded files that already exist are handled, may be one of 'error', 'skip', 'overwrite', "+
+			"'side-file', 'merge'")
+
+	// kubebuilder alpha
+	rootCmd.AddCommand(c.newAlphaCmd())
+
 	// kubebuilder completion
 	// Only add completion if requested
 	if c.completionCommand {
 		rootCmd.AddCommand(c.newCompletionCmd())
 	}
 
+	// kubebuilder config
+	configCmd := c.newConfigCmd()
+	// kubebuilder config validate
+	configCmd.AddCommand(c.newConfigValidateCmd())
+	rootCmd.AddCommand(configCmd)
+
 	// kubebuilder create
 	createCmd := c.newCreateCmd()
 	// kubebuilder create api
@@ -419,12 +577,53 @@ func (c cli) buildRootCmd() *cobra.Command {
 		rootCmd.AddCommand(createCmd)
 	}
 
+	// kubebuilder doctor
+	rootCmd.AddCommand(c.newDoctorCmd())
+
 	// kubebuilder edit
 	rootCmd.AddCommand(c.newEditCmd())
 
+	// kubebuilder envtest
+	envtestCmd := c.newEnvtestCmd()
+	// kubebuilder envtest install
+	envtestCmd.AddCommand(c.newEnvtestInstallCmd())
+	// kubebuilder envtest list
+	envtestCmd.AddCommand(c.newEnvtestListCmd())
+	// kubebuilder envtest use
+	envtestCmd.AddCommand(c.newEnvtestUseCmd())
+	rootCmd.AddCommand(envtestCmd)
+
+	// kubebuilder explain
+	explainCmd := c.newExplainCmd()
+	// kubebuilder explain marker
+	explainCmd.AddCommand(c.newExplainMarkerCmd())
+	rootCmd.AddCommand(explainCmd)
+
+	// kubebuilder generate
+	generateCmd := c.newGenerateCmd()
+	// kubebuilder generate schema
+	generateCmd.AddCommand(c.newGenerateSchemaCmd())
+	rootCmd.AddCommand(generateCmd)
+
 	// kubebuilder init
 	rootCmd.AddCommand(c.newInitCmd())
 
+	// kubebuilder list
+	listCmd := c.newListCmd()
+	// kubebuilder list apis
+	listCmd.AddCommand(c.newListAPIsCmd())
+	// kubebuilder list plugins
+	listCmd.AddCommand(c.newListPluginsCmd())
+	// kubebuilder list webhooks
+	listCmd.AddCommand(c.newListWebhooksCmd())
+	rootCmd.AddCommand(listCmd)
+
+	// kubebuilder serve
+	rootCmd.AddCommand(c.newServeCmd())
+
+	// kubebuilder undo
+	rootCmd.AddCommand(c.newUndoCmd())
+
 	// kubebuilder version
 	// Only add version if a version string was provided
 	if c.version != "" {
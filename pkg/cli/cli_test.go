@@ -151,6 +151,16 @@ var _ = Describe("CLI", func() {
 			})
 		})
 
+		When(fmt.Sprintf("--%s, --%s and --%s flags are set", debugFlag, verboseFlag, traceFlag), func() {
+			It("should enable the corresponding output", func() {
+				os.Args = append(os.Args, "subcommand", "--"+debugFlag, "-v", "--"+traceFlag)
+				c.getInfoFromFlags()
+				Expect(c.debug).To(BeTrue())
+				Expect(c.verbose).To(BeTrue())
+				Expect(c.trace).To(BeTrue())
+			})
+		})
+
 		When("additional flags are set", func() {
 			It("should not fail", func() {
 				setFlag("extra-flag", "extra-value")
@@ -516,15 +526,30 @@ var _ = Describe("CLI", func() {
 					Expect(plugins[0]).To(Equal(pluginKey1))
 				})
 
-				It("should fail if they are different", func() {
+				It("should success if the plugin keys from flags designate the same plugins", func() {
 					c = &cli{}
-					_, _, err = c.resolveFlagsAndConfigFileConflicts(
+					_, plugins, err = c.resolveFlagsAndConfigFileConflicts(
+						"",
+						"",
+						[]string{"go/v1", "go.kubebuilder.io"},
+						[]string{pluginKey1, pluginKey2},
+					)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(plugins).To(Equal([]string{"go/v1", "go.kubebuilder.io"}))
+					Expect(c.layoutNotice).To(BeEmpty())
+				})
+
+				It("should use the plugin keys from flags with a notice if they are different", func() {
+					c = &cli{}
+					_, plugins, err = c.resolveFlagsAndConfigFileConflicts(
 						"",
 						"",
 						[]string{pluginKey1},
 						[]string{pluginKey2},
 					)
-					Expect(err).To(HaveOccurred())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(plugins).To(Equal([]string{pluginKey1}))
+					Expect(c.layoutNotice).To(ContainSubstring(pluginKey2))
 				})
 			})
 		})
@@ -547,18 +572,86 @@ var _ = Describe("CLI", func() {
 				Expect(plugins[0]).To(Equal(pluginKey2))
 			})
 
-			It("should fail if plugin keys from flags and config file are different", func() {
+			It("should use the plugin keys from flags if they are different from the config file", func() {
 				c = &cli{
 					defaultPlugins: map[string][]string{
 						"": {pluginKey1},
 					},
 				}
-				_, _, err = c.resolveFlagsAndConfigFileConflicts(
+				_, plugins, err = c.resolveFlagsAndConfigFileConflicts(
 					"",
 					"",
 					[]string{pluginKey2},
 					[]string{pluginKey3},
 				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plugins).To(Equal([]string{pluginKey2}))
+				Expect(c.layoutNotice).NotTo(BeEmpty())
+			})
+		})
+
+		When("having negated plugin keys set from flags", func() {
+			const declarativePluginKey = "declarative.go.kubebuilder.io/v1"
+
+			It("should remove them from the config file", func() {
+				c = &cli{}
+				_, plugins, err = c.resolveFlagsAndConfigFileConflicts(
+					"",
+					"",
+					[]string{"-declarative"},
+					[]string{pluginKey3, declarativePluginKey},
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plugins).To(Equal([]string{pluginKey3}))
+				Expect(c.layoutNotice).To(BeEmpty())
+			})
+
+			It("should remove them from the default plugin keys without a config file", func() {
+				c = &cli{
+					defaultPlugins: map[string][]string{
+						"": {pluginKey3, declarativePluginKey},
+					},
+				}
+				_, plugins, err = c.resolveFlagsAndConfigFileConflicts(
+					"",
+					"",
+					[]string{"-declarative.go.kubebuilder.io/v1"},
+					nil,
+				)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(plugins).To(Equal([]string{pluginKey3}))
+			})
+
+			It("should fail if they are not in the config file", func() {
+				c = &cli{}
+				_, _, err = c.resolveFlagsAndConfigFileConflicts(
+					"",
+					"",
+					[]string{"-go/v2"},
+					[]string{pluginKey3, declarativePluginKey},
+				)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should fail if they remove every plugin", func() {
+				c = &cli{}
+				_, _, err = c.resolveFlagsAndConfigFileConflicts(
+					"",
+					"",
+					[]string{"-go", "-declarative"},
+					[]string{pluginKey3, declarativePluginKey},
+				)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("should fail if they are mixed with plugin keys that are not negated", func() {
+				c = &cli{}
+				_, _, err = c.resolveFlagsAndConfigFileConflicts(
+					"",
+					"",
+					[]string{pluginKey2, "-declarative"},
+					[]string{pluginKey3, declarativePluginKey},
+				)
 				Expect(err).To(HaveOccurred())
 			})
 		})
@@ -18,10 +18,14 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
 )
 
@@ -46,16 +50,60 @@ func errCmdFunc(err error) func(*cobra.Command, []string) error {
 }
 
 // runECmdFunc returns a cobra RunE function that runs subcommand and saves the
-// config, which may have been modified by subcommand.
+// config, which may have been modified by subcommand. If subcommand implements
+// plugin.PostCreateHook, its hook is run once the config is saved.
 func runECmdFunc(
 	c *config.Config,
 	subcommand plugin.Subcommand, // nolint:interfacer
 	msg string,
 ) func(*cobra.Command, []string) error {
 	return func(*cobra.Command, []string) error {
-		if err := subcommand.Run(); err != nil {
-			return fmt.Errorf("%s: %v", msg, err)
+		err := recordUndo(c.Path(), func() error {
+			if err := subcommand.Run(); err != nil {
+				return fmt.Errorf("%s: %v", msg, err)
+			}
+			return c.Save()
+		})
+		if err != nil {
+			return err
 		}
-		return c.Save()
+		return runPostCreateHook(subcommand, msg)
 	}
 }
+
+// bindPluginsFlag registers --plugins on the commands run in an existing project, whose plugins default to the
+// layout of the project config file, so that it shows up in help and does not cause a parse error.
+func bindPluginsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSlice(pluginsFlag, nil,
+		"plugins to run instead of the layout of the project configuration file, or plugins of the layout "+
+			"prefixed with '-' to run it without them")
+}
+
+// recordUndo runs f recording the files it changes, starting with the config
+// file at configPath, in the journal reverted by the undo command.
+func recordUndo(configPath string, f func() error) error {
+	journal.Begin()
+	if err := journal.Record(configPath); err != nil {
+		journal.Commit()
+		return err
+	}
+	err := f()
+	journal.Commit()
+
+	// The changes of a failed command are also saved, unless they were rolled back
+	command := strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " ")
+	if saveErr := journal.Save(journal.DefaultPath, command); saveErr != nil && err == nil {
+		err = fmt.Errorf("unable to save the journal of the undo command: %v", saveErr)
+	}
+	return err
+}
+
+// runPostCreateHook runs the hook of subcommand if it implements plugin.PostCreateHook.
+func runPostCreateHook(subcommand plugin.Subcommand, msg string) error {
+	if hook, hasHook := subcommand.(plugin.PostCreateHook); hasHook {
+		if err := hook.PostCreate(); err != nil {
+			return fmt.Errorf("%s: post-create hook failed: %v", msg, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/crdlint"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/projectcheck"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+func (cli) newConfigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "config",
+		Short: "Manage the project configuration",
+		Long:  `Manage the project configuration, stored in the PROJECT file.`,
+	}
+}
+
+func (c cli) newConfigValidateCmd() *cobra.Command {
+	var crdDir string
+	var strict, scaffold bool
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the PROJECT file",
+		Long: `Validate the PROJECT file.
+
+The following checks are run:
+  - the fields of the PROJECT file are valid for its project version;
+  - the plugins of its layout and of its plugin configurations are known;
+  - the types, webhooks and samples of its resources exist;
+  - the types and webhooks of the API directories, and the generated CRDs, are
+    recorded in it.
+
+With --scaffold, the wiring of the project is checked too:
+  - the scaffold markers of main.go and of the test suites, at which the code of
+    the new resources is inserted, exist and are well-formed, the ones of main.go
+    being optional in the projects wired with --wiring ast;
+  - the types and webhooks of its resources are wired in main.go.
+
+The command fails if an error is found. The drifts between the PROJECT file and
+the project are reported as warnings, which fail the command with --strict: run
+"make validate-project" and "make verify-scaffold" in the CI of the project to
+catch them.
+`,
+		Example: fmt.Sprintf(`  # Validate the PROJECT file in the current directory
+  %[1]s config validate
+
+  # Also fail on the drifts between the PROJECT file and the project
+  %[1]s config validate --strict
+
+  # Also fail when a scaffold marker was deleted or a resource is not wired in main.go
+  %[1]s config validate --scaffold --strict
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if _, err := os.Stat(config.DefaultPath); os.IsNotExist(err) {
+				return errors.New("unable to find configuration file, project must be initialized")
+			}
+
+			plugins := make([]plugin.Plugin, 0, len(c.plugins))
+			for _, p := range c.plugins {
+				plugins = append(plugins, p)
+			}
+			problems, err := projectcheck.Check(config.DefaultPath, plugins, crdDir)
+			if err != nil {
+				return fmt.Errorf("unable to validate the project configuration: %v", err)
+			}
+			if scaffold {
+				scaffoldProblems, err := projectcheck.CheckScaffold(config.DefaultPath)
+				if err != nil {
+					return fmt.Errorf("unable to check the scaffold markers: %v", err)
+				}
+				problems = append(problems, scaffoldProblems...)
+			}
+
+			failures := 0
+			for _, problem := range problems {
+				fmt.Println(problem)
+				if strict || problem.Severity == crdlint.Error {
+					failures++
+				}
+			}
+			if failures != 0 {
+				return fmt.Errorf("found %d problem(s)", failures)
+			}
+			if len(problems) == 0 {
+				fmt.Println("The project configuration is valid")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&crdDir, "crd-dir", filepath.Join("config", "crd", "bases"),
+		"directory containing the generated CRDs")
+	cmd.Flags().BoolVar(&strict, "strict", false,
+		"fail on the warnings too, such as the drifts between the PROJECT file and the project")
+	cmd.Flags().BoolVar(&scaffold, "scaffold", false,
+		"also check the scaffold markers of main.go and of the test suites, and the wiring of the resources in main.go")
+
+	return cmd
+}
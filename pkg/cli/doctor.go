@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/crdlint"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/deprecation"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+func (c cli) newDoctorCmd() *cobra.Command {
+	var crdDir string
+	var failOnDeprecated bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the project for common problems",
+		Long: `Check the project for common problems.
+
+The following checks are run:
+  - the PROJECT file can be loaded;
+  - the plugins of the layout of the project are not deprecated, which is an
+    error with --fail-on-deprecated;
+  - the API versions deprecated with "alpha deprecate-version" are not past
+    their removal date, which is an error with --fail-on-deprecated, are
+    deprecated in their CRD and are not its storage version;
+  - the generated CRDs fit in the size limits of the API server and of the
+    last-applied-configuration annotation of kubectl apply;
+  - the x-kubernetes-validations rules of the CRDs do not iterate over unbounded
+    lists, maps or strings, which may exceed the CEL cost budget.
+
+Run "make manifests" first so that the CRDs are up to date. The command fails if
+an error is found, warnings are only reported.
+`,
+		Example: fmt.Sprintf(`  # Check the project in the current directory
+  %s doctor
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			cfg, err := config.LoadInitialized()
+			if err != nil {
+				return fmt.Errorf("unable to load the project configuration: %v", err)
+			}
+
+			problems := c.checkDeprecatedPlugins(failOnDeprecated)
+			severity := crdlint.Warning
+			if failOnDeprecated {
+				severity = crdlint.Error
+			}
+			versionProblems, err := deprecation.Check(cfg.Config, crdDir, time.Now(), severity)
+			if err != nil {
+				return fmt.Errorf("unable to check the deprecated versions: %v", err)
+			}
+			problems = append(problems, versionProblems...)
+			crdProblems, err := crdlint.LintDir(crdDir)
+			if err != nil {
+				return fmt.Errorf("unable to check the CRDs: %v", err)
+			}
+			problems = append(problems, crdProblems...)
+
+			errors := 0
+			for _, problem := range problems {
+				fmt.Println(problem)
+				if problem.Severity == crdlint.Error {
+					errors++
+				}
+			}
+			if errors != 0 {
+				return fmt.Errorf("found %d error(s)", errors)
+			}
+			if len(problems) == 0 {
+				fmt.Println("No problem found")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&crdDir, "crd-dir", filepath.Join("config", "crd", "bases"),
+		"directory containing the generated CRDs")
+	cmd.Flags().BoolVar(&failOnDeprecated, "fail-on-deprecated", false,
+		"report the deprecated plugins of the layout of the project and the API versions past their removal "+
+			"date as errors, e.g. to fail CI jobs")
+
+	return cmd
+}
+
+// checkDeprecatedPlugins returns a problem for each deprecated plugin of the layout of the project, an error
+// if failOnDeprecated is set and a warning otherwise.
+func (c cli) checkDeprecatedPlugins(failOnDeprecated bool) []crdlint.Problem {
+	severity := crdlint.Warning
+	if failOnDeprecated {
+		severity = crdlint.Error
+	}
+	var problems []crdlint.Problem
+	for _, p := range c.resolvedPlugins {
+		if d, isDeprecated := plugin.DeprecationOf(p); isDeprecated {
+			problems = append(problems, crdlint.Problem{CRD: config.DefaultPath, Severity: severity,
+				Message: fmt.Sprintf("the layout uses the deprecated %s plugin: %s", plugin.KeyFor(p), d)})
+		}
+	}
+	return problems
+}
@@ -37,6 +37,8 @@ func (c cli) newEditCmd() *cobra.Command {
 		),
 	}
 
+	bindPluginsFlag(cmd)
+
 	// Lookup the plugin for projectVersion and bind it to the command.
 	c.bindEdit(ctx, cmd)
 	return cmd
@@ -84,10 +86,7 @@ func (c cli) bindEdit(ctx plugin.Context, cmd *cobra.Command) {
 
 	subcommand := editPlugin.GetEditSubcommand()
 	subcommand.InjectConfig(&cfg.Config)
-	subcommand.BindFlags(cmd.Flags())
-	subcommand.UpdateContext(&ctx)
-	cmd.Long = ctx.Description
-	cmd.Example = ctx.Examples
+	bindSubcommand(cmd, editPlugin, subcommand, ctx)
 	cmd.RunE = runECmdFunc(cfg, subcommand,
 		fmt.Sprintf("failed to edit project with %q", plugin.KeyFor(editPlugin)))
 }
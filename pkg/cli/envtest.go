@@ -0,0 +1,355 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/envtest"
+)
+
+func (cli) newEnvtestCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "envtest",
+		Short: "Manage the binaries run by envtest",
+		Long: `Manage the binaries run by the envtest package of controller-runtime: etcd,
+kube-apiserver and kubectl.
+
+The binaries are installed from the kubebuilder-tools archives, downloaded or
+provided offline with --archive, in a directory per Kubernetes version and
+platform: the KUBEBUILDER_ASSETS of the tests. The store of these directories is
+shared by the projects of the user, set --bin-dir to use another one.
+`,
+	}
+}
+
+// envtestOptions are the options shared by the envtest commands.
+type envtestOptions struct {
+	binDir    string
+	remoteURL string
+	platform  envtest.Platform
+}
+
+func (o *envtestOptions) addFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.binDir, "bin-dir", "",
+		"directory storing the installed binaries, defaults to kubebuilder-envtest in the user cache directory")
+	fs.StringVar(&o.remoteURL, "remote-url", envtest.DefaultRemoteURL,
+		"base URL of the kubebuilder-tools archives, e.g. the one of a mirror")
+	fs.StringVar(&o.platform.OS, "os", runtime.GOOS, "operating system of the binaries")
+	fs.StringVar(&o.platform.Arch, "arch", runtime.GOARCH, "architecture of the binaries")
+}
+
+func (o envtestOptions) store() (envtest.Store, error) {
+	if o.binDir != "" {
+		return envtest.Store{Root: o.binDir}, nil
+	}
+	root, err := envtest.DefaultRoot()
+	if err != nil {
+		return envtest.Store{}, fmt.Errorf("unable to find the user cache directory, set --bin-dir: %v", err)
+	}
+	return envtest.Store{Root: root}, nil
+}
+
+func (o envtestOptions) remote() envtest.Remote {
+	return envtest.Remote{URL: o.remoteURL}
+}
+
+// envtestInstall are the options selecting how a version is installed.
+type envtestInstall struct {
+	archive  string
+	checksum string
+}
+
+func (i *envtestInstall) addFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&i.archive, "archive", "",
+		"path of the kubebuilder-tools archive to install instead of downloading it, for offline environments")
+	fs.StringVar(&i.checksum, "sha256", "",
+		"expected sha256 checksum of the archive, verified before installing it if set")
+}
+
+// validate checks that an exact version is selected to install the archive of i, whose version is not
+// known otherwise.
+func (i envtestInstall) validate(selector string) error {
+	if i.archive == "" {
+		return nil
+	}
+	sel, err := envtest.ParseSelector(selector)
+	if err != nil {
+		return err
+	}
+	if _, concrete := sel.Concrete(); !concrete {
+		return fmt.Errorf("an exact version is required to install --archive, got %s", selector)
+	}
+	return nil
+}
+
+// resolve returns the version selected by selector, the newest installed one if any unless force is set,
+// whether it is installed, and the store. The versions that are not installed are resolved against the remote.
+func (o envtestOptions) resolve(
+	selector string, installedOnly, force bool,
+) (envtest.Version, bool, envtest.Store, error) {
+	store, err := o.store()
+	if err != nil {
+		return envtest.Version{}, false, store, err
+	}
+	sel, err := envtest.ParseSelector(selector)
+	if err != nil {
+		return envtest.Version{}, false, store, err
+	}
+
+	if !force {
+		installed, err := store.List(o.platform)
+		if err != nil {
+			return envtest.Version{}, false, store, fmt.Errorf("unable to list the installed versions: %v", err)
+		}
+		if v, found := sel.Latest(installed); found {
+			return v, true, store, nil
+		}
+	}
+	if installedOnly {
+		return envtest.Version{}, false, store, fmt.Errorf("no version matching %s is installed for %s", sel, o.platform)
+	}
+
+	if v, concrete := sel.Concrete(); concrete {
+		return v, false, store, nil
+	}
+	available, err := o.remote().List(o.platform)
+	if err != nil {
+		return envtest.Version{}, false, store, fmt.Errorf("unable to resolve %s, pass an exact version: %v", sel, err)
+	}
+	v, found := sel.Latest(available)
+	if !found {
+		return envtest.Version{}, false, store, fmt.Errorf("no version matching %s is available for %s at %s",
+			sel, o.platform, o.remoteURL)
+	}
+	return v, false, store, nil
+}
+
+// install installs v in store from the archive of i if set, downloading it otherwise. The progress is written
+// to stderr, keeping stdout for the output of the commands.
+func (o envtestOptions) install(store envtest.Store, v envtest.Version, i envtestInstall) error {
+	var archive io.ReadCloser
+	var err error
+	if i.archive != "" {
+		archive, err = os.Open(i.archive)
+	} else {
+		fmt.Fprintf(os.Stderr, "Downloading %s from %s\n", envtest.ArchiveName(v, o.platform), o.remoteURL)
+		archive, err = o.remote().Open(v, o.platform)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to open the archive of %s: %v", v, err)
+	}
+	defer archive.Close()
+
+	sum, err := store.Install(archive, v, o.platform, i.checksum)
+	if err != nil {
+		return fmt.Errorf("unable to install %s: %v", v, err)
+	}
+	fmt.Fprintf(os.Stderr, "Installed %s for %s in %s (archive sha256 %s)\n", v, o.platform, store.Dir(v, o.platform), sum)
+	return nil
+}
+
+func (c cli) newEnvtestInstallCmd() *cobra.Command {
+	var opts envtestOptions
+	var install envtestInstall
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "install VERSION",
+		Short: "Install the envtest binaries of a Kubernetes version",
+		Long: `Install the envtest binaries of the newest Kubernetes version matching VERSION,
+e.g. 1.19.2, 1.19.x or latest, unless one is already installed.
+
+The archive is downloaded from --remote-url, which must list its content as a
+Google Cloud Storage or S3 bucket does to resolve the versions with wildcards,
+or read from --archive in offline environments. Its checksum is printed once
+installed, and verified against --sha256 if set.
+`,
+		Example: fmt.Sprintf(`  # Install the newest patch version of Kubernetes 1.19
+  %[1]s envtest install 1.19.x
+
+  # Install a version from an archive downloaded beforehand, verifying it
+  %[1]s envtest install 1.19.2 --archive kubebuilder-tools-1.19.2-linux-amd64.tar.gz --sha256 <checksum>
+`, c.commandName),
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := install.validate(args[0]); err != nil {
+				return err
+			}
+			// The provided archive is always installed
+			if install.archive != "" {
+				force = true
+			}
+			v, installed, store, err := opts.resolve(args[0], false, force)
+			if err != nil {
+				return err
+			}
+			if installed {
+				fmt.Printf("%s is already installed for %s in %s\n", v, opts.platform, store.Dir(v, opts.platform))
+				return nil
+			}
+			return opts.install(store, v, install)
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	install.addFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&force, "force", false, "install the version even if it is already installed")
+
+	return cmd
+}
+
+func (c cli) newEnvtestListCmd() *cobra.Command {
+	var opts envtestOptions
+	var remote bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the installed envtest binaries",
+		Long: `List the Kubernetes versions whose envtest binaries are installed for the
+platform, and with --remote the versions available at --remote-url too.
+`,
+		Example: fmt.Sprintf(`  # List the installed and the available versions
+  %[1]s envtest list --remote
+`, c.commandName),
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			store, err := opts.store()
+			if err != nil {
+				return err
+			}
+			installed, err := store.List(opts.platform)
+			if err != nil {
+				return fmt.Errorf("unable to list the installed versions: %v", err)
+			}
+			for _, v := range installed {
+				fmt.Printf("%-10s %s installed %s\n", v, opts.platform, store.Dir(v, opts.platform))
+			}
+			if !remote {
+				return nil
+			}
+
+			available, err := opts.remote().List(opts.platform)
+			if err != nil {
+				return fmt.Errorf("unable to list the available versions: %v", err)
+			}
+			isInstalled := make(map[envtest.Version]bool, len(installed))
+			for _, v := range installed {
+				isInstalled[v] = true
+			}
+			for _, v := range available {
+				if !isInstalled[v] {
+					fmt.Printf("%-10s %s available\n", v, opts.platform)
+				}
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&remote, "remote", false, "also list the versions available at --remote-url")
+
+	return cmd
+}
+
+const (
+	printEnv      = "env"
+	printPath     = "path"
+	printOverview = "overview"
+)
+
+func (c cli) newEnvtestUseCmd() *cobra.Command {
+	var opts envtestOptions
+	var install envtestInstall
+	var installedOnly bool
+	var printFormat string
+
+	cmd := &cobra.Command{
+		Use:   "use [VERSION]",
+		Short: "Print the KUBEBUILDER_ASSETS of a Kubernetes version, installing it if necessary",
+		Long: `Print the directory of the envtest binaries of the newest Kubernetes version
+matching VERSION, e.g. 1.19.2, 1.19.x or latest (the default), to be set as the
+KUBEBUILDER_ASSETS of the tests.
+
+The newest installed version matching VERSION is used if any. Otherwise the
+newest one available is installed as "envtest install" does, unless
+--installed-only is set, which never touches the network.
+`,
+		Example: fmt.Sprintf(`  # Run the tests with the newest patch version of Kubernetes 1.19
+  KUBEBUILDER_ASSETS="$(%[1]s envtest use 1.19.x --print=path)" go test ./...
+
+  # Set KUBEBUILDER_ASSETS in the current shell
+  eval "$(%[1]s envtest use 1.19.x)"
+
+  # Use the installed versions only, e.g. offline
+  %[1]s envtest use 1.19.x --installed-only --print=overview
+`, c.commandName),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			switch printFormat {
+			case printEnv, printPath, printOverview:
+			default:
+				return fmt.Errorf("invalid --print %q, must be one of %s, %s and %s",
+					printFormat, printEnv, printPath, printOverview)
+			}
+			selector := "latest"
+			if len(args) == 1 {
+				selector = args[0]
+			}
+			if err := install.validate(selector); err != nil {
+				return err
+			}
+
+			v, installed, store, err := opts.resolve(selector, installedOnly, false)
+			if err != nil {
+				return err
+			}
+			if !installed {
+				if err := opts.install(store, v, install); err != nil {
+					return err
+				}
+			}
+
+			dir := store.Dir(v, opts.platform)
+			switch printFormat {
+			case printEnv:
+				fmt.Printf("export KUBEBUILDER_ASSETS='%s'\n", dir)
+			case printPath:
+				fmt.Println(dir)
+			case printOverview:
+				fmt.Printf("Version: %s\nOS/Arch: %s\nPath: %s\n", v, opts.platform, dir)
+			}
+			return nil
+		},
+	}
+
+	opts.addFlags(cmd.Flags())
+	install.addFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&installedOnly, "installed-only", false,
+		"only use the installed versions, failing if none matches VERSION")
+	cmd.Flags().StringVar(&printFormat, "print", printEnv,
+		fmt.Sprintf("output format: %s to print a shell export of KUBEBUILDER_ASSETS, %s to print its value, "+
+			"or %s to describe the version", printEnv, printPath, printOverview))
+
+	return cmd
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/markers"
+)
+
+func (cli) newExplainCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "explain",
+		Short: "Print the documentation of the elements of a project",
+		Long:  `Print the documentation of the elements of a project.`,
+	}
+}
+
+func (c cli) newExplainMarkerCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "marker [name]",
+		Short: "Print the documentation of a marker",
+		Long: `Print the documentation of a marker of the scaffolded code.
+
+The name may be written with its leading "+" and its arguments, as found in the code.
+Without name, the documented markers are listed.
+`,
+		Example: fmt.Sprintf(`  # List the documented markers
+  %[1]s explain marker
+
+  # Print the documentation of the rbac marker
+  %[1]s explain marker +kubebuilder:rbac:groups=core,resources=events,verbs=create
+`, c.commandName),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				for _, m := range markers.All() {
+					fmt.Printf("%-45s %s\n", m.Name, m.Summary)
+				}
+				return nil
+			}
+
+			m, found := markers.Lookup(args[0])
+			if !found {
+				return fmt.Errorf("unknown marker %q, run \"%s explain marker\" to list the documented markers",
+					args[0], c.commandName)
+			}
+			fmt.Printf("%s\n\n%s\n\nUsage:\n  %s\n\nDocumentation:\n  %s\n", m.Name, m.Description, m.Usage, m.URL)
+			return nil
+		},
+	}
+}
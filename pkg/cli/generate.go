@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/schema"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/storageversion"
+)
+
+func (cli) newGenerateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "generate",
+		Short: "Generate artifacts from the generated manifests of a project",
+		Long:  `Generate artifacts from the generated manifests of a project.`,
+	}
+}
+
+func (c cli) newGenerateSchemaCmd() *cobra.Command {
+	var crdDir, outputDir, format string
+
+	cmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Export the schemas of the APIs",
+		Long: fmt.Sprintf(`Export the schemas of the APIs.
+
+A standalone schema is written for every served version of the kinds of the PROJECT
+file, derived from the structural schema of their generated CRD, so that tools and UIs
+unaware of CRDs can validate or edit the objects. The supported formats are:
+  - %[1]s: a JSON Schema (draft-07) document, closing the objects to the fields
+    the API server would not prune;
+  - %[2]s: an OpenAPI 3.0 document declaring the schema as a component;
+  - %[3]s: a proto3 file declaring the messages of the JSON objects.
+
+Run "make manifests" first so that the CRDs are up to date.
+`, schema.JSONSchema, schema.OpenAPI, schema.Proto),
+		Example: fmt.Sprintf(`  # Write the JSON Schemas of the APIs in docs/schemas
+  %[1]s generate schema
+
+  # Write the protobuf messages of the APIs in api/proto
+  %[1]s generate schema --format proto --output-dir api/proto
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if !isSchemaFormat(schema.Format(format)) {
+				return fmt.Errorf("unknown format %q, the supported formats are %v", format, schema.Formats)
+			}
+
+			cfg, err := config.LoadInitialized()
+			if err != nil {
+				return err
+			}
+
+			kinds, problems, err := storageversion.Load(cfg.Config, crdDir)
+			if err != nil {
+				return fmt.Errorf("unable to load the CRDs: %v", err)
+			}
+			for _, problem := range problems {
+				fmt.Println(problem)
+			}
+			if len(kinds) == 0 {
+				fmt.Printf("No CRD found in %s\n", crdDir)
+				return nil
+			}
+
+			if err := os.MkdirAll(outputDir, 0755); err != nil {
+				return err
+			}
+			for _, kind := range kinds {
+				for _, version := range kind.Versions {
+					if !version.Served {
+						continue
+					}
+					content, err := schema.Generate(kind, version, schema.Format(format))
+					if err != nil {
+						return err
+					}
+					path := filepath.Join(outputDir, schema.FileName(kind, version.Name, schema.Format(format)))
+					if err := ioutil.WriteFile(path, content, 0644); err != nil { //nolint:gosec
+						return err
+					}
+					fmt.Printf("%s: schema of %s written to %s\n", kind.CRD, version.Name, path)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", string(schema.JSONSchema),
+		fmt.Sprintf("format of the schemas, one of %v", schema.Formats))
+	cmd.Flags().StringVar(&crdDir, "crd-dir", filepath.Join("config", "crd", "bases"),
+		"directory containing the generated CRDs")
+	cmd.Flags().StringVar(&outputDir, "output-dir", filepath.Join("docs", "schemas"),
+		"directory where the schemas are written")
+
+	return cmd
+}
+
+func isSchemaFormat(format schema.Format) bool {
+	for _, supported := range schema.Formats {
+		if format == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+const (
+	// pluginAnnotation is the flag annotation holding the key of the plugin that bound the flag
+	pluginAnnotation = "kubebuilder.io/plugin"
+	// pluginDocsAnnotation is the command annotation holding the documentation of the plugin subcommand
+	pluginDocsAnnotation = "kubebuilder.io/plugin-docs"
+)
+
+// pluginUsageTemplate is the default usage template of cobra, with the flags of the CLI and the flags of each
+// plugin listed separately, followed by the documentation of the plugin subcommand.
+const pluginUsageTemplate = `Usage:{{if .Runnable}}
+  {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
+
+Aliases:
+  {{.NameAndAliases}}{{end}}{{if .HasExample}}
+
+Examples:
+{{.Example}}{{end}}{{with cliFlags .LocalFlags}}{{if .HasAvailableFlags}}
+
+Flags:
+{{.FlagUsages | trimTrailingWhitespaces}}{{end}}{{end}}{{range pluginFlags .LocalFlags}}{{if .Flags.HasAvailableFlags}}
+
+Flags of the {{.Key}} plugin:
+{{.Flags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{end}}{{if .HasAvailableInheritedFlags}}
+
+Global Flags:
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{with index .Annotations "kubebuilder.io/plugin-docs"}}
+
+{{. | trimTrailingWhitespaces}}{{end}}
+`
+
+func init() {
+	cobra.AddTemplateFunc("cliFlags", cliFlags)
+	cobra.AddTemplateFunc("pluginFlags", pluginFlags)
+}
+
+// pluginFlagSet is the set of flags bound by a plugin
+type pluginFlagSet struct {
+	Key   string
+	Flags *pflag.FlagSet
+}
+
+// cliFlags returns the flags of fs that were not bound by a plugin
+func cliFlags(fs *pflag.FlagSet) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("cli", pflag.ContinueOnError)
+	fs.VisitAll(func(f *pflag.Flag) {
+		if _, isPluginFlag := f.Annotations[pluginAnnotation]; !isPluginFlag {
+			flags.AddFlag(f)
+		}
+	})
+	return flags
+}
+
+// pluginFlags returns the flags of fs bound by each plugin, sorted by plugin key
+func pluginFlags(fs *pflag.FlagSet) []pluginFlagSet {
+	flagsByKey := make(map[string]*pflag.FlagSet)
+	fs.VisitAll(func(f *pflag.Flag) {
+		keys, isPluginFlag := f.Annotations[pluginAnnotation]
+		if !isPluginFlag || len(keys) == 0 {
+			return
+		}
+		if _, exists := flagsByKey[keys[0]]; !exists {
+			flagsByKey[keys[0]] = pflag.NewFlagSet(keys[0], pflag.ContinueOnError)
+		}
+		flagsByKey[keys[0]].AddFlag(f)
+	})
+
+	flagSets := make([]pluginFlagSet, 0, len(flagsByKey))
+	for key, flags := range flagsByKey {
+		flagSets = append(flagSets, pluginFlagSet{Key: key, Flags: flags})
+	}
+	sort.Slice(flagSets, func(i, j int) bool { return flagSets[i].Key < flagSets[j].Key })
+	return flagSets
+}
+
+// bindSubcommand binds the flags and the help of the subcommand of p to cmd. The flags are annotated with the key
+// of p, so that the help lists them apart from the flags of the CLI and of the other plugins.
+func bindSubcommand(cmd *cobra.Command, p plugin.Plugin, subcommand plugin.Subcommand, ctx plugin.Context) {
+	key := plugin.KeyFor(p)
+	fs := pflag.NewFlagSet(key, pflag.ContinueOnError)
+	subcommand.BindFlags(fs)
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Annotations == nil {
+			f.Annotations = make(map[string][]string)
+		}
+		f.Annotations[pluginAnnotation] = []string{key}
+	})
+	cmd.Flags().AddFlagSet(fs)
+
+	subcommand.UpdateContext(&ctx)
+	cmd.Long = ctx.Description
+	cmd.Example = ctx.Examples
+	if ctx.Docs != "" {
+		if cmd.Annotations == nil {
+			cmd.Annotations = make(map[string]string)
+		}
+		cmd.Annotations[pluginDocsAnnotation] = ctx.Docs
+	}
+	cmd.SetUsageTemplate(pluginUsageTemplate)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+type mockSubcommand struct {
+	kind string
+}
+
+func (s *mockSubcommand) UpdateContext(ctx *plugin.Context) {
+	ctx.Description = "Mock description."
+	ctx.Examples = "  mock example"
+	ctx.Docs = "Documentation of the mock plugin."
+}
+
+func (s *mockSubcommand) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&s.kind, "kind", "", "resource Kind")
+}
+
+func (s *mockSubcommand) Run() error                  { return nil }
+func (s *mockSubcommand) InjectConfig(*config.Config) {}
+
+var _ = Describe("Help", func() {
+	var (
+		cmd        *cobra.Command
+		subcommand *mockSubcommand
+	)
+
+	BeforeEach(func() {
+		cmd = &cobra.Command{Use: "api", Aliases: []string{"resource"}, RunE: errCmdFunc(nil)}
+		cmd.Flags().String(projectVersionFlag, "", "project version")
+		subcommand = &mockSubcommand{}
+		bindSubcommand(cmd, newMockPlugin("mock.kubebuilder.io", "v1", "3"), subcommand, plugin.Context{})
+	})
+
+	It("should bind the help of the subcommand", func() {
+		Expect(cmd.Long).To(Equal("Mock description."))
+		Expect(cmd.Example).To(Equal("  mock example"))
+		Expect(cmd.Annotations).To(HaveKeyWithValue(pluginDocsAnnotation, "Documentation of the mock plugin."))
+	})
+
+	It("should bind the flags of the subcommand", func() {
+		Expect(cmd.Flags().Parse([]string{"--kind", "Frigate"})).To(Succeed())
+		Expect(subcommand.kind).To(Equal("Frigate"))
+	})
+
+	It("should list the flags of the plugin apart from the flags of the CLI", func() {
+		Expect(cliFlags(cmd.Flags()).Lookup(projectVersionFlag)).NotTo(BeNil())
+		Expect(cliFlags(cmd.Flags()).Lookup("kind")).To(BeNil())
+
+		flagSets := pluginFlags(cmd.Flags())
+		Expect(flagSets).To(HaveLen(1))
+		Expect(flagSets[0].Key).To(Equal("mock.kubebuilder.io/v1"))
+		Expect(flagSets[0].Flags.Lookup("kind")).NotTo(BeNil())
+		Expect(flagSets[0].Flags.Lookup(projectVersionFlag)).To(BeNil())
+	})
+
+	It("should display the aliases, the flags of the plugin and its docs in the usage", func() {
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		Expect(cmd.Usage()).To(Succeed())
+		Expect(out.String()).To(ContainSubstring("Aliases:\n  api, resource"))
+		Expect(out.String()).To(ContainSubstring("Flags:\n      --project-version"))
+		Expect(out.String()).To(ContainSubstring("Flags of the mock.kubebuilder.io/v1 plugin:\n      --kind string"))
+		Expect(out.String()).To(HaveSuffix("\n\nDocumentation of the mock plugin.\n"))
+	})
+})
@@ -144,10 +144,7 @@ func (c cli) bindInit(ctx plugin.Context, cmd *cobra.Command) {
 
 	subcommand := initPlugin.GetInitSubcommand()
 	subcommand.InjectConfig(&cfg.Config)
-	subcommand.BindFlags(cmd.Flags())
-	subcommand.UpdateContext(&ctx)
-	cmd.Long = ctx.Description
-	cmd.Example = ctx.Examples
+	bindSubcommand(cmd, initPlugin, subcommand, ctx)
 	cmd.RunE = func(*cobra.Command, []string) error {
 		// Check if a config is initialized in the command runner so the check
 		// doesn't erroneously fail other commands used in initialized projects.
@@ -155,9 +152,29 @@ func (c cli) bindInit(ctx plugin.Context, cmd *cobra.Command) {
 		if err == nil || os.IsExist(err) {
 			log.Fatal("config already initialized")
 		}
-		if err := subcommand.Run(); err != nil {
-			return fmt.Errorf("failed to initialize project with %q: %v", plugin.KeyFor(initPlugin), err)
+		msg := fmt.Sprintf("failed to initialize project with %q", plugin.KeyFor(initPlugin))
+		err = recordUndo(cfg.Path(), func() error {
+			if err := subcommand.Run(); err != nil {
+				return fmt.Errorf("%s: %v", msg, err)
+			}
+			// Pin the plugins of the project, so that the next commands run them without --plugins
+			if len(c.resolvedPlugins) > 1 {
+				cfg.Layout = layoutOf(c.resolvedPlugins)
+			}
+			return cfg.Save()
+		})
+		if err != nil {
+			return err
 		}
-		return cfg.Save()
+		return runPostCreateHook(subcommand, msg)
+	}
+}
+
+// layoutOf returns the layout recorded in the project config file for plugins, their comma-separated keys.
+func layoutOf(plugins []plugin.Plugin) string {
+	keys := make([]string, 0, len(plugins))
+	for _, p := range plugins {
+		keys = append(keys, plugin.KeyFor(p))
 	}
+	return strings.Join(keys, ",")
 }
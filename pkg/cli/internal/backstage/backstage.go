@@ -0,0 +1,307 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backstage generates the Backstage software templates running the commands of the CLI, with a parameter
+// per flag of the commands.
+package backstage
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultAction is the default action of the steps running the commands
+const DefaultAction = "kubebuilder:run"
+
+// skippedFlags are the flags that are not mapped to parameters, whose value is set by the action running the
+// commands or does not apply to the generated projects
+var skippedFlags = map[string]bool{
+	"help":                  true,
+	"fetch-deps":            true,
+	"make":                  true,
+	"force":                 true,
+	"from-file":             true,
+	"skip-go-version-check": true,
+}
+
+// enumRegexps match the values listed by the usage of the flags, e.g. may be one of 'make', 'task', 'just' or
+// Options: [v1, v1beta1]
+var enumRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`may be one of ('[^']+'(?:, '[^']+')*)`),
+	regexp.MustCompile(`Options: \[([^\]]+)\]`),
+}
+
+type object = map[string]interface{}
+
+// Command is a command of the CLI run by a step of the template
+type Command struct {
+	// Args are the words of the command, e.g. create api
+	Args []string
+	// Title is the title of the page of the parameters of the command
+	Title string
+	// Description is the description of the page of the parameters of the command
+	Description string
+	// Flags are the flags of the command, each one mapped to a parameter
+	Flags *pflag.FlagSet
+	// Required are the flags whose parameters are required
+	Required []string
+	// Inherit are the flags set to the parameter of the same flag of a previous command, e.g. the kind of the
+	// webhook of the created API
+	Inherit []string
+	// If are the flags one of whose parameters must be set to run the command, it always runs if empty
+	If []string
+}
+
+// Options are the options of the template
+type Options struct {
+	// Name is the name of the template
+	Name string
+	// Title is the title of the template
+	Title string
+	// Description is the description of the template
+	Description string
+	// Owner is the owner of the template, if any
+	Owner string
+	// Action is the action of the steps running the commands, DefaultAction if empty
+	Action string
+	// AllowedHosts are the hosts of the repositories the projects may be published to
+	AllowedHosts []string
+}
+
+// Template returns a Backstage software template running commands in order, with a page of parameters per
+// command, and publishing the generated project to a GitHub repository registered in the catalog
+func Template(opts Options, commands []Command) ([]byte, error) {
+	action := opts.Action
+	if action == "" {
+		action = DefaultAction
+	}
+
+	names := map[string]bool{"repoUrl": true}
+	// inheritable are the parameters of the flags of the previous commands, by flag
+	inheritable := map[string]string{}
+	pages := make([]interface{}, 0, len(commands)+1)
+	steps := make([]interface{}, 0, len(commands)+3)
+	for _, command := range commands {
+		properties := object{}
+		flags := object{}
+		parameters := map[string]string{}
+		for _, flag := range command.Inherit {
+			name, found := inheritable[flag]
+			if !found {
+				return nil, fmt.Errorf("no previous command of %s has a flag --%s", strings.Join(command.Args, " "), flag)
+			}
+			flags[flag] = fmt.Sprintf("${{ parameters.%s }}", name)
+		}
+		command.Flags.VisitAll(func(f *pflag.Flag) {
+			if skippedFlags[f.Name] || f.Hidden || flags[f.Name] != nil {
+				return
+			}
+			name := camelCase(f.Name)
+			// The parameters of all the pages share the same namespace
+			if names[name] {
+				name = camelCase(command.Args[len(command.Args)-1] + "-" + f.Name)
+			}
+			names[name] = true
+			parameters[f.Name] = name
+			if _, found := inheritable[f.Name]; !found {
+				inheritable[f.Name] = name
+			}
+			properties[name] = property(f)
+			flags[f.Name] = fmt.Sprintf("${{ parameters.%s }}", name)
+		})
+
+		page := object{"title": command.Title, "properties": properties}
+		if command.Description != "" {
+			page["description"] = command.Description
+		}
+		var required []string
+		for _, flag := range command.Required {
+			name, found := parameters[flag]
+			if !found {
+				return nil, fmt.Errorf("%s has no flag --%s", strings.Join(command.Args, " "), flag)
+			}
+			required = append(required, name)
+		}
+		if len(required) != 0 {
+			page["required"] = required
+		}
+		pages = append(pages, page)
+
+		step := object{
+			"id":     strings.Join(command.Args, "-"),
+			"name":   command.Title,
+			"action": action,
+			"input":  object{"command": command.Args, "flags": flags},
+		}
+		if len(command.If) != 0 {
+			conditions := make([]string, 0, len(command.If))
+			for _, flag := range command.If {
+				name, found := parameters[flag]
+				if !found {
+					return nil, fmt.Errorf("%s has no flag --%s", strings.Join(command.Args, " "), flag)
+				}
+				conditions = append(conditions, "parameters."+name)
+			}
+			step["if"] = fmt.Sprintf("${{ %s }}", strings.Join(conditions, " or "))
+		}
+		steps = append(steps, step)
+	}
+
+	pages = append(pages, object{
+		"title":    "Repository",
+		"required": []string{"repoUrl"},
+		"properties": object{
+			"repoUrl": object{
+				"title":      "Repository location",
+				"type":       "string",
+				"ui:field":   "RepoUrlPicker",
+				"ui:options": object{"allowedHosts": opts.AllowedHosts},
+			},
+		},
+	})
+
+	name := "${{ (parameters.repoUrl | parseRepoUrl).repo }}"
+	slug := "${{ (parameters.repoUrl | parseRepoUrl).owner }}/" + name
+	steps = append(steps,
+		object{
+			"id":     "catalog",
+			"name":   "Write the catalog entity",
+			"action": "catalog:write",
+			"input": object{
+				"entity": object{
+					"apiVersion": "backstage.io/v1alpha1",
+					"kind":       "Component",
+					"metadata": object{
+						"name":        name,
+						"annotations": object{"github.com/project-slug": slug},
+						"tags":        []string{"go", "kubernetes", "operator"},
+					},
+					"spec": object{
+						"type":      "service",
+						"lifecycle": "experimental",
+						"owner":     "${{ user.entity.metadata.name }}",
+					},
+				},
+			},
+		},
+		object{
+			"id":     "publish",
+			"name":   "Publish the project",
+			"action": "publish:github",
+			"input": object{
+				"repoUrl":       "${{ parameters.repoUrl }}",
+				"description":   opts.Description,
+				"defaultBranch": "main",
+			},
+		},
+		object{
+			"id":     "register",
+			"name":   "Register the project in the catalog",
+			"action": "catalog:register",
+			"input": object{
+				"repoContentsUrl": "${{ steps.publish.output.repoContentsUrl }}",
+				"catalogInfoPath": "/catalog-info.yaml",
+			},
+		},
+	)
+
+	metadata := object{
+		"name":        opts.Name,
+		"title":       opts.Title,
+		"description": opts.Description,
+		"tags":        []string{"go", "kubernetes", "operator", "kubebuilder"},
+	}
+	spec := object{
+		"type":       "service",
+		"parameters": pages,
+		"steps":      steps,
+		"output": object{
+			"links": []interface{}{
+				object{"title": "Repository", "url": "${{ steps.publish.output.remoteUrl }}"},
+				object{"title": "Open in catalog", "icon": "catalog", "entityRef": "${{ steps.register.output.entityRef }}"},
+			},
+		},
+	}
+	if opts.Owner != "" {
+		spec["owner"] = opts.Owner
+	}
+
+	return yaml.Marshal(object{
+		"apiVersion": "scaffolder.backstage.io/v1beta3",
+		"kind":       "Template",
+		"metadata":   metadata,
+		"spec":       spec,
+	})
+}
+
+// property returns the schema of the parameter of f. The default values of the string flags are only shown as
+// placeholders, so that the flags keep the defaults computed by the commands when their parameter is not set.
+func property(f *pflag.Flag) object {
+	p := object{"title": title(f.Name), "description": f.Usage}
+	switch f.Value.Type() {
+	case "bool":
+		p["type"] = "boolean"
+		if value, err := strconv.ParseBool(f.DefValue); err == nil {
+			p["default"] = value
+		}
+	case "int", "int32", "int64", "uint", "uint32", "uint64":
+		p["type"] = "integer"
+		if value, err := strconv.ParseInt(f.DefValue, 10, 64); err == nil && value != 0 {
+			p["default"] = value
+		}
+	case "stringSlice", "stringArray":
+		p["type"] = "array"
+		p["items"] = object{"type": "string"}
+	default:
+		p["type"] = "string"
+		if f.DefValue != "" {
+			p["ui:placeholder"] = f.DefValue
+		}
+		for _, enumRegexp := range enumRegexps {
+			if match := enumRegexp.FindStringSubmatch(f.Usage); match != nil {
+				var values []string
+				for _, value := range strings.Split(match[1], ", ") {
+					values = append(values, strings.Trim(value, "'"))
+				}
+				p["enum"] = values
+				break
+			}
+		}
+	}
+	return p
+}
+
+// camelCase returns the parameter name of a flag, e.g. cacheLabelSelector for cache-label-selector
+func camelCase(flag string) string {
+	words := strings.Split(flag, "-")
+	for i := 1; i < len(words); i++ {
+		if words[i] != "" {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// title returns the title of the parameter of a flag, e.g. Cache label selector for cache-label-selector
+func title(flag string) string {
+	words := strings.Join(strings.Split(flag, "-"), " ")
+	return strings.ToUpper(words[:1]) + words[1:]
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backstage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+func commands() []Command {
+	initFlags := pflag.NewFlagSet("init", pflag.ContinueOnError)
+	initFlags.String("repo", "", "name to use for go module")
+	initFlags.String("owner", "", "owner to add to the copyright")
+	initFlags.String("task-runner", "make", "task runner, may be one of 'make', 'task', 'just', which scaffold")
+	initFlags.Bool("fetch-deps", true, "ensure dependencies are downloaded")
+
+	apiFlags := pflag.NewFlagSet("api", pflag.ContinueOnError)
+	apiFlags.String("group", "", "resource Group")
+	apiFlags.String("kind", "", "resource Kind")
+	apiFlags.String("owner", "", "owner of the kind")
+	apiFlags.Bool("resource", true, "if set, generate the resource")
+	apiFlags.StringArray("with-child", nil, "kind of the children")
+
+	webhookFlags := pflag.NewFlagSet("webhook", pflag.ContinueOnError)
+	webhookFlags.String("kind", "", "resource Kind")
+	webhookFlags.Bool("defaulting", false, "if set, scaffold the defaulting webhook")
+	webhookFlags.Int("timeout-seconds", 0, "time limit")
+	webhookFlags.String("side-effects", "", "side effects. Options: [None, NoneOnDryRun], and [Some, Unknown]")
+
+	return []Command{
+		{Args: []string{"init"}, Title: "Project", Flags: initFlags, Required: []string{"repo"}},
+		{Args: []string{"create", "api"}, Title: "API", Flags: apiFlags, If: []string{"kind"}},
+		{Args: []string{"create", "webhook"}, Title: "Webhooks", Flags: webhookFlags, Inherit: []string{"kind"},
+			If: []string{"defaulting"}},
+	}
+}
+
+func generate(t *testing.T, opts Options, commands []Command) map[string]interface{} {
+	t.Helper()
+	content, err := Template(opts, commands)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var template map[string]interface{}
+	if err := yaml.Unmarshal(content, &template); err != nil {
+		t.Fatal(err)
+	}
+	return template
+}
+
+func get(t *testing.T, value interface{}, path ...interface{}) interface{} {
+	t.Helper()
+	for _, key := range path {
+		switch key := key.(type) {
+		case string:
+			value = value.(map[string]interface{})[key]
+		case int:
+			value = value.([]interface{})[key]
+		}
+	}
+	return value
+}
+
+func TestTemplateParameters(t *testing.T) {
+	template := generate(t, Options{Name: "operator", AllowedHosts: []string{"github.com"}}, commands())
+
+	project := get(t, template, "spec", "parameters", 0, "properties").(map[string]interface{})
+	if _, found := project["fetchDeps"]; found {
+		t.Error("expected --fetch-deps not to be mapped, the action sets it")
+	}
+	expected := map[string]interface{}{"title": "Task runner", "type": "string", "ui:placeholder": "make",
+		"enum":        []interface{}{"make", "task", "just"},
+		"description": "task runner, may be one of 'make', 'task', 'just', which scaffold"}
+	if !reflect.DeepEqual(project["taskRunner"], expected) {
+		t.Errorf("expected the task runner parameter %v, got %v", expected, project["taskRunner"])
+	}
+	if required := get(t, template, "spec", "parameters", 0, "required"); !reflect.DeepEqual(required,
+		[]interface{}{"repo"}) {
+		t.Errorf("expected the repo to be required, got %v", required)
+	}
+
+	api := get(t, template, "spec", "parameters", 1, "properties").(map[string]interface{})
+	for name, expected := range map[string]interface{}{
+		"apiOwner": map[string]interface{}{"title": "Owner", "type": "string", "description": "owner of the kind"},
+		"resource": map[string]interface{}{"title": "Resource", "type": "boolean", "default": true,
+			"description": "if set, generate the resource"},
+		"withChild": map[string]interface{}{"title": "With child", "type": "array",
+			"items": map[string]interface{}{"type": "string"}, "description": "kind of the children"},
+	} {
+		if !reflect.DeepEqual(api[name], expected) {
+			t.Errorf("expected the %s parameter %v, got %v", name, expected, api[name])
+		}
+	}
+
+	webhooks := get(t, template, "spec", "parameters", 2, "properties").(map[string]interface{})
+	if _, found := webhooks["webhookKind"]; found {
+		t.Error("expected the kind of the webhooks to be inherited from the API")
+	}
+	if enum := get(t, webhooks, "sideEffects", "enum"); !reflect.DeepEqual(enum, []interface{}{"None", "NoneOnDryRun"}) {
+		t.Errorf("expected the options of the side effects, got %v", enum)
+	}
+	hosts := get(t, template, "spec", "parameters", 3, "properties", "repoUrl", "ui:options", "allowedHosts")
+	if !reflect.DeepEqual(hosts, []interface{}{"github.com"}) {
+		t.Errorf("expected the allowed hosts of the repository, got %v", hosts)
+	}
+}
+
+func TestTemplateSteps(t *testing.T) {
+	template := generate(t, Options{Action: "acme:kubebuilder"}, commands())
+
+	steps := get(t, template, "spec", "steps").([]interface{})
+	var ids []interface{}
+	for _, step := range steps {
+		ids = append(ids, get(t, step, "id"))
+	}
+	expected := []interface{}{"init", "create-api", "create-webhook", "catalog", "publish", "register"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected the steps %v, got %v", expected, ids)
+	}
+
+	if action := get(t, steps[0], "action"); action != "acme:kubebuilder" {
+		t.Errorf("expected the commands to be run by the action of the options, got %v", action)
+	}
+	if _, found := get(t, steps[0], "if").(string); found {
+		t.Error("expected init to always run")
+	}
+	if flags := get(t, steps[1], "input", "flags"); !reflect.DeepEqual(flags, map[string]interface{}{
+		"group":      "${{ parameters.group }}",
+		"kind":       "${{ parameters.kind }}",
+		"owner":      "${{ parameters.apiOwner }}",
+		"resource":   "${{ parameters.resource }}",
+		"with-child": "${{ parameters.withChild }}",
+	}) {
+		t.Errorf("expected the flags to be mapped to their parameters, got %v", flags)
+	}
+	if condition := get(t, steps[1], "if"); condition != "${{ parameters.kind }}" {
+		t.Errorf("expected create api to be skipped without a kind, got %v", condition)
+	}
+	if kind := get(t, steps[2], "input", "flags", "kind"); kind != "${{ parameters.kind }}" {
+		t.Errorf("expected the webhooks to be created for the kind of the API, got %v", kind)
+	}
+}
+
+func TestTemplateInvalidCommands(t *testing.T) {
+	for description, mutate := range map[string]func([]Command){
+		"unknown required flag":  func(c []Command) { c[0].Required = []string{"domain"} },
+		"unknown condition flag": func(c []Command) { c[1].If = []string{"plural"} },
+		"not inheritable flag":   func(c []Command) { c[2].Inherit = []string{"defaulting"} },
+	} {
+		c := commands()
+		mutate(c)
+		if _, err := Template(Options{}, c); err == nil {
+			t.Errorf("%s: expected an error", description)
+		}
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	for flag, expected := range map[string]string{
+		"kind":                 "kind",
+		"cache-label-selector": "cacheLabelSelector",
+		"min-k8s-version":      "minK8sVersion",
+	} {
+		if actual := camelCase(flag); actual != expected {
+			t.Errorf("%s: expected %s, got %s", flag, expected, actual)
+		}
+	}
+}
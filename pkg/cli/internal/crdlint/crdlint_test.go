@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crdlint
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const crdTemplate = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: frigates.ship.example.org
+spec:
+  group: ship.example.org
+  names:
+    kind: Frigate
+    plural: frigates
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        description: DESCRIPTION
+        type: object
+        properties:
+          spec:
+            type: object
+            x-kubernetes-validations:
+            - rule: self.crew.all(c, c.size() > 0)
+            properties:
+              crew:
+                type: array
+                items:
+                  type: string
+              labels:
+                type: object
+                maxProperties: 10
+                additionalProperties:
+                  type: string
+              name:
+                type: string
+                x-kubernetes-validations:
+                - rule: self.startsWith('f')
+`
+
+func lintCRD(t *testing.T, description string) []Problem {
+	dir, err := ioutil.TempDir("", "kubebuilder-crdlint-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	content := strings.Replace(crdTemplate, "DESCRIPTION", description, 1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "ship.example.org_frigates.yaml"), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources: []\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := LintDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return problems
+}
+
+func TestLintDir(t *testing.T) {
+	problems := lintCRD(t, "Frigate is the Schema for the frigates API")
+	expected := []string{
+		"warning: frigates.ship.example.org: version v1: the x-kubernetes-validations rules of spec " +
+			"iterate over spec.crew, which has no maxItems",
+		"warning: frigates.ship.example.org: version v1: the x-kubernetes-validations rules of spec.name " +
+			"iterate over spec.name, which has no maxLength",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %v", len(expected), problems)
+	}
+	for i, problem := range problems {
+		if !strings.HasPrefix(problem.String(), expected[i]) {
+			t.Errorf("expected problem %q, got %q", expected[i], problem)
+		}
+	}
+}
+
+func TestLintDirSize(t *testing.T) {
+	for _, tc := range []struct {
+		size     int
+		expected string
+	}{
+		{size: MaxAnnotationsSize, expected: "too large for the 262144 bytes last-applied-configuration annotation"},
+		{size: MaxObjectSize, expected: "more than the 1572864 bytes the API server stores for an object"},
+	} {
+		var errors []Problem
+		for _, problem := range lintCRD(t, strings.Repeat("a", tc.size)) {
+			if problem.Severity == Error {
+				errors = append(errors, problem)
+			}
+		}
+		if len(errors) != 1 || !strings.Contains(errors[0].Message, tc.expected) {
+			t.Errorf("expected an error containing %q for a %d bytes description, got %v", tc.expected, tc.size, errors)
+		}
+	}
+}
+
+func TestLintDirMissing(t *testing.T) {
+	problems, err := LintDir(filepath.Join(os.TempDir(), "kubebuilder-crdlint-missing"))
+	if err != nil || len(problems) != 0 {
+		t.Errorf("expected no problem for a missing directory, got %v, %v", problems, err)
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deprecation deprecates the versions of the APIs of a project: it marks their types with the
+// +kubebuilder:deprecatedversion marker, moves their samples to the versions replacing them, and checks the
+// removal dates recorded in the PROJECT file.
+package deprecation
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/crdlint"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/storageversion"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+const marker = "+kubebuilder:deprecatedversion"
+
+// RemoveAfter returns the earliest date after which version, deprecated at now, may stop being served under
+// the deprecation policy of the Kubernetes APIs: 12 months for the GA versions, 9 months for the beta versions
+// and at once for the alpha versions.
+func RemoveAfter(version string, now time.Time) time.Time {
+	switch stability, _, _ := parseVersion(version); stability {
+	case alpha:
+		return now
+	case beta:
+		return now.AddDate(0, 9, 0)
+	default:
+		return now.AddDate(0, 12, 0)
+	}
+}
+
+// Warning returns the default warning of the deprecated version of kind, e.g.
+// "crew.example.org/v1beta1 Captain is deprecated, unavailable after 2021-10-01; use crew.example.org/v1 Captain".
+func Warning(group, version, kind, replacement, removeAfter string) string {
+	return fmt.Sprintf("%s/%s %s is deprecated, unavailable after %s; use %s/%s %s",
+		group, version, kind, removeAfter, group, replacement, kind)
+}
+
+// Replacement returns the version of versions with the highest priority other than deprecated, with the
+// ordering of the versions of the API server: GA versions first, then beta and alpha versions, the highest
+// numbers first. It returns an empty string if there is no other version.
+func Replacement(versions []string, deprecated string) string {
+	replacement := ""
+	for _, version := range versions {
+		if version != deprecated && (replacement == "" || higherPriority(version, replacement)) {
+			replacement = version
+		}
+	}
+	return replacement
+}
+
+const (
+	alpha = iota
+	beta
+	ga
+)
+
+var versionRegexp = regexp.MustCompile(`^v(\d+)(?:(alpha|beta)(\d+))?$`)
+
+// parseVersion returns the stability, the major and the minor number of version, e.g. beta, 1 and 2 for
+// v1beta2. The versions not following the Kubernetes conventions have the lowest priority.
+func parseVersion(version string) (stability, major, minor int) {
+	matches := versionRegexp.FindStringSubmatch(version)
+	if matches == nil {
+		return -1, 0, 0
+	}
+	major, _ = strconv.Atoi(matches[1])
+	minor, _ = strconv.Atoi(matches[3])
+	switch matches[2] {
+	case "alpha":
+		return alpha, major, minor
+	case "beta":
+		return beta, major, minor
+	default:
+		return ga, major, minor
+	}
+}
+
+// higherPriority returns whether version a has a higher priority than version b.
+func higherPriority(a, b string) bool {
+	stabilityA, majorA, minorA := parseVersion(a)
+	stabilityB, majorB, minorB := parseVersion(b)
+	switch {
+	case stabilityA != stabilityB:
+		return stabilityA > stabilityB
+	case majorA != majorB:
+		return majorA > majorB
+	case minorA != minorB:
+		return minorA > minorB
+	default:
+		return a < b
+	}
+}
+
+// MarkTypes adds the +kubebuilder:deprecatedversion marker with warning to the markers of the type named kind
+// in content, the source of a types file, or replaces the warning of the marker if the type already has it.
+func MarkTypes(content, kind, warning string) (string, error) {
+	lines := strings.Split(content, "\n")
+	declaration := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "type "+kind+" struct") {
+			declaration = i
+			break
+		}
+	}
+	if declaration == -1 {
+		return "", fmt.Errorf("type %s not found", kind)
+	}
+
+	// The markers are in the comments above the declaration, which may be separated from them by blank lines
+	last := -1
+	for i := declaration - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if line != "" && !strings.HasPrefix(line, "//") {
+			break
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(line, "//"))
+		if value == marker || strings.HasPrefix(value, marker+":") {
+			lines[i] = lines[i][:strings.Index(lines[i], "+")] + markerWithWarning(warning)
+			return strings.Join(lines, "\n"), nil
+		}
+		if last == -1 && strings.HasPrefix(value, "+kubebuilder:") {
+			last = i
+		}
+	}
+	if last == -1 {
+		return "", fmt.Errorf("no +kubebuilder marker found above type %s", kind)
+	}
+
+	// Keep the spacing of the markers of the file, e.g. //+kubebuilder or // +kubebuilder
+	prefix := lines[last][:strings.Index(lines[last], "+")]
+	inserted := []string{
+		"// Deprecates the version, whose clients get the warning from the API server.",
+		"// See https://book.kubebuilder.io/reference/markers/crd.html",
+		prefix + markerWithWarning(warning),
+	}
+	lines = append(lines[:last+1], append(inserted, lines[last+1:]...)...)
+	return strings.Join(lines, "\n"), nil
+}
+
+func markerWithWarning(warning string) string {
+	if warning == "" {
+		return marker
+	}
+	return marker + ":warning=" + strconv.Quote(warning)
+}
+
+// MoveSamples sets the apiVersion of the samples of the deprecated version of kind found in the YAML files of
+// dir to the replacement version, and returns the paths of the files it changed.
+func MoveSamples(dir, group, kind, deprecated, replacement string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var moved []string
+	for _, path := range files {
+		content, err := ioutil.ReadFile(path) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+		updated, changed := moveSample(string(content), group, kind, deprecated, replacement)
+		if !changed {
+			continue
+		}
+		if err := ioutil.WriteFile(path, []byte(updated), 0644); err != nil { //nolint:gosec
+			return nil, err
+		}
+		moved = append(moved, path)
+	}
+	return moved, nil
+}
+
+// moveSample rewrites the top-level apiVersion of the documents of content whose kind is kind, keeping the
+// rest of the documents, such as their comments, untouched.
+func moveSample(content, group, kind, deprecated, replacement string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	changed := false
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && strings.TrimSpace(lines[i]) != "---" {
+			continue
+		}
+		apiVersion, isKind := -1, false
+		for j := start; j < i; j++ {
+			switch strings.TrimRight(lines[j], " \r") {
+			case "apiVersion: " + group + "/" + deprecated:
+				apiVersion = j
+			case "kind: " + kind:
+				isKind = true
+			}
+		}
+		if apiVersion != -1 && isKind {
+			lines[apiVersion] = "apiVersion: " + group + "/" + replacement
+			changed = true
+		}
+		start = i + 1
+	}
+	return strings.Join(lines, "\n"), changed
+}
+
+// Check reports the deprecated versions of the PROJECT file whose removal date passed at now, with severity,
+// and, if crdDir exists, the ones that are not deprecated or are still the storage version in their CRD.
+func Check(cfg config.Config, crdDir string, now time.Time, severity crdlint.Severity) ([]crdlint.Problem, error) {
+	crds := map[string]storageversion.Kind{}
+	if _, err := os.Stat(crdDir); err == nil {
+		if crds, err = storageversion.ReadCRDs(crdDir); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems []crdlint.Problem
+	for _, res := range cfg.Resources {
+		if res.API == nil || res.API.Deprecation == nil {
+			continue
+		}
+		deprecation := res.API.Deprecation
+		group := cfg.Domain
+		if res.Group != "" {
+			group = res.Group + "." + cfg.Domain
+		}
+		key := res.Kind + "." + group
+		crd, found := crds[key]
+		subject := key
+		if found {
+			subject = crd.CRD
+		}
+
+		removeAfter, err := time.Parse(config.DeprecationDateLayout, deprecation.RemoveAfter)
+		if err == nil && !now.Before(removeAfter.AddDate(0, 0, 1)) {
+			problems = append(problems, crdlint.Problem{CRD: subject, Severity: severity, Message: fmt.Sprintf(
+				"version %s is deprecated and its removal was scheduled after %s: stop serving it once its "+
+					"objects are migrated to %s", res.Version, deprecation.RemoveAfter, deprecation.Replacement)})
+		}
+		if !found {
+			continue
+		}
+		for _, version := range crd.Versions {
+			if version.Name != res.Version {
+				continue
+			}
+			if !version.Deprecated {
+				problems = append(problems, crdlint.Problem{CRD: subject, Severity: crdlint.Warning,
+					Message: fmt.Sprintf("version %s is deprecated in the PROJECT file but not in the CRD, "+
+						"run \"make manifests\" to generate it", res.Version)})
+			}
+			if version.Storage {
+				problems = append(problems, crdlint.Problem{CRD: subject, Severity: crdlint.Warning,
+					Message: fmt.Sprintf("the deprecated version %s is the storage version, move the "+
+						"+kubebuilder:storageversion marker to %s", res.Version, deprecation.Replacement)})
+			}
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecation
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/crdlint"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+func TestReplacement(t *testing.T) {
+	for _, tc := range []struct {
+		versions    []string
+		deprecated  string
+		replacement string
+	}{
+		{[]string{"v1beta1", "v1"}, "v1beta1", "v1"},
+		{[]string{"v1", "v2alpha1", "v1beta2"}, "v1", "v1beta2"},
+		{[]string{"v1alpha1", "v1alpha2", "v2alpha1"}, "v1alpha1", "v2alpha1"},
+		{[]string{"v1", "v2", "v3"}, "v3", "v2"},
+		{[]string{"v1"}, "v1", ""},
+	} {
+		if replacement := Replacement(tc.versions, tc.deprecated); replacement != tc.replacement {
+			t.Errorf("expected %q to replace %s in %v, got %q", tc.replacement, tc.deprecated, tc.versions, replacement)
+		}
+	}
+}
+
+func TestRemoveAfter(t *testing.T) {
+	now := time.Date(2021, time.January, 15, 0, 0, 0, 0, time.UTC)
+	for version, expected := range map[string]string{
+		"v1alpha1": "2021-01-15",
+		"v1beta1":  "2021-10-15",
+		"v1":       "2022-01-15",
+	} {
+		if removeAfter := RemoveAfter(version, now).Format(config.DeprecationDateLayout); removeAfter != expected {
+			t.Errorf("expected %s to be removed after %s, got %s", version, expected, removeAfter)
+		}
+	}
+}
+
+const types = `package v1beta1
+
+// CaptainStatus defines the observed state of Captain
+type CaptainStatus struct {
+}
+
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
+//+kubebuilder:object:root=true
+// Enables the status subresource of the CRD.
+// See https://book.kubebuilder.io/reference/markers/crd.html
+//+kubebuilder:subresource:status
+
+// Captain is the Schema for the captains API
+type Captain struct {
+}
+`
+
+func TestMarkTypes(t *testing.T) {
+	marked, err := MarkTypes(types, "Captain", `use "v1"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `//+kubebuilder:subresource:status
+// Deprecates the version, whose clients get the warning from the API server.
+// See https://book.kubebuilder.io/reference/markers/crd.html
+//+kubebuilder:deprecatedversion:warning="use \"v1\""
+
+// Captain is the Schema for the captains API`
+	if !strings.Contains(marked, expected) {
+		t.Errorf("expected the marker after the markers of the type, got:\n%s", marked)
+	}
+
+	// The warning of a version already deprecated is replaced
+	remarked, err := MarkTypes(marked, "Captain", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := strings.Replace(marked, `:warning="use \"v1\""`, "", 1); remarked != expected {
+		t.Errorf("expected the warning to be removed, got:\n%s", remarked)
+	}
+
+	// The spacing of the markers of the file is kept
+	spaced, err := MarkTypes(strings.ReplaceAll(types, "//+", "// +"), "Captain", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(spaced, "\n// +kubebuilder:deprecatedversion\n") {
+		t.Errorf("expected the marker to be spaced as the others, got:\n%s", spaced)
+	}
+
+	if _, err := MarkTypes(types, "Admiral", ""); err == nil {
+		t.Error("expected an error for a missing type")
+	}
+	if _, err := MarkTypes("package v1\n\ntype Captain struct {\n}\n", "Captain", ""); err == nil {
+		t.Error("expected an error for a type without markers")
+	}
+}
+
+func TestMoveSamples(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deprecation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"crew_v1beta1_captain.yaml": "apiVersion: crew.example.org/v1beta1\nkind: Captain\nmetadata:\n  name: a\n",
+		"crew_v1beta1_captain_hpa.yaml": "apiVersion: autoscaling/v2\nkind: HorizontalPodAutoscaler\nspec:\n" +
+			"  scaleTargetRef:\n    apiVersion: crew.example.org/v1beta1\n    kind: Captain\n---\n" +
+			"# The Captain scaled\napiVersion: crew.example.org/v1beta1\nkind: Captain\n",
+		"crew_v1beta1_admiral.yaml": "apiVersion: crew.example.org/v1beta1\nkind: Admiral\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	moved, err := MoveSamples(dir, "crew.example.org", "Captain", "v1beta1", "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedMoved := []string{
+		filepath.Join(dir, "crew_v1beta1_captain.yaml"),
+		filepath.Join(dir, "crew_v1beta1_captain_hpa.yaml"),
+	}
+	if !reflect.DeepEqual(moved, expectedMoved) {
+		t.Errorf("expected %v to be moved, got %v", expectedMoved, moved)
+	}
+
+	expected := map[string]string{
+		"crew_v1beta1_captain.yaml": "apiVersion: crew.example.org/v1\nkind: Captain\nmetadata:\n  name: a\n",
+		// Only the top-level apiVersion of the Captain is moved
+		"crew_v1beta1_captain_hpa.yaml": strings.Replace(files["crew_v1beta1_captain_hpa.yaml"],
+			"\napiVersion: crew.example.org/v1beta1\n", "\napiVersion: crew.example.org/v1\n", 1),
+		"crew_v1beta1_admiral.yaml": files["crew_v1beta1_admiral.yaml"],
+	}
+	for name, content := range expected {
+		actual, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(actual) != content {
+			t.Errorf("expected %s to be:\n%s\ngot:\n%s", name, content, actual)
+		}
+	}
+}
+
+const crd = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: captains.crew.example.org
+spec:
+  group: crew.example.org
+  names:
+    kind: Captain
+    plural: captains
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+  - name: v1
+    served: true
+`
+
+func TestCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "deprecation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "crew.example.org_captains.yaml"), []byte(crd), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{Domain: "example.org", Resources: []config.ResourceData{
+		{Group: "crew", Version: "v1beta1", Kind: "Captain", API: &config.API{CRDVersion: "v1",
+			Deprecation: &config.Deprecation{Replacement: "v1", RemoveAfter: "2021-06-30"}}},
+		{Group: "crew", Version: "v1", Kind: "Captain", API: &config.API{CRDVersion: "v1"}},
+	}}
+
+	problems, err := Check(cfg, dir, time.Date(2021, time.June, 30, 12, 0, 0, 0, time.UTC), crdlint.Error)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []crdlint.Problem{
+		{CRD: "captains.crew.example.org", Severity: crdlint.Warning, Message: "version v1beta1 is deprecated " +
+			"in the PROJECT file but not in the CRD, run \"make manifests\" to generate it"},
+		{CRD: "captains.crew.example.org", Severity: crdlint.Warning, Message: "the deprecated version v1beta1 " +
+			"is the storage version, move the +kubebuilder:storageversion marker to v1"},
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected %v, got %v", expected, problems)
+	}
+
+	// The removal date passed, and the CRDs were not generated
+	problems, err = Check(cfg, filepath.Join(dir, "missing"), time.Date(2021, time.July, 1, 0, 0, 0, 0, time.UTC),
+		crdlint.Error)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []crdlint.Problem{{CRD: "Captain.crew.example.org", Severity: crdlint.Error, Message: "version " +
+		"v1beta1 is deprecated and its removal was scheduled after 2021-06-30: stop serving it once its " +
+		"objects are migrated to v1"}}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("expected %v, got %v", expected, problems)
+	}
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package envtest manages the binaries run by the envtest package of controller-runtime: etcd, kube-apiserver
+// and kubectl. They are installed from the kubebuilder-tools archives, downloaded or provided offline, in a
+// store holding a directory per version and platform, whose path is the KUBEBUILDER_ASSETS of the tests.
+package envtest
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is a Kubernetes version, e.g. 1.19.2.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// ParseVersion parses a version such as 1.19.2 or v1.19.2.
+func ParseVersion(s string) (Version, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("invalid version %q, expected MAJOR.MINOR.PATCH", s)
+	}
+	var numbers [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q, expected MAJOR.MINOR.PATCH", s)
+		}
+		numbers[i] = n
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2]}, nil
+}
+
+// String implements fmt.Stringer
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Newer returns true if v is newer than other.
+func (v Version) Newer(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch > other.Patch
+}
+
+// wildcard is the value of the parts of a Selector that match any number.
+const wildcard = -1
+
+// Selector selects versions, e.g. 1.19.x matches the patch versions of 1.19.
+type Selector struct {
+	Major, Minor, Patch int
+}
+
+// ParseSelector parses a version selector: a version whose trailing parts may be x or * to match any number,
+// or omitted, e.g. 1.19.2, 1.19.x, 1.19 and 1.x. The empty selector and latest match every version.
+func ParseSelector(s string) (Selector, error) {
+	selector := Selector{Major: wildcard, Minor: wildcard, Patch: wildcard}
+	if s == "" || s == "latest" {
+		return selector, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, "v"), ".")
+	if len(parts) > 3 {
+		return Selector{}, fmt.Errorf("invalid version selector %q, expected e.g. 1.19.2, 1.19.x or latest", s)
+	}
+	numbers := []*int{&selector.Major, &selector.Minor, &selector.Patch}
+	for i, part := range parts {
+		if part == "x" || part == "*" {
+			// The parts following a wildcard are wildcards too
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return Selector{}, fmt.Errorf("invalid version selector %q, expected e.g. 1.19.2, 1.19.x or latest", s)
+		}
+		*numbers[i] = n
+	}
+	return selector, nil
+}
+
+// String implements fmt.Stringer
+func (s Selector) String() string {
+	if s.Major == wildcard {
+		return "latest"
+	}
+	parts := []string{strconv.Itoa(s.Major), "x", "x"}
+	if s.Minor != wildcard {
+		parts[1] = strconv.Itoa(s.Minor)
+		if s.Patch != wildcard {
+			parts[2] = strconv.Itoa(s.Patch)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// Matches returns true if v is selected by s.
+func (s Selector) Matches(v Version) bool {
+	return (s.Major == wildcard || s.Major == v.Major) &&
+		(s.Minor == wildcard || s.Minor == v.Minor) &&
+		(s.Patch == wildcard || s.Patch == v.Patch)
+}
+
+// Concrete returns the only version selected by s, if s has no wildcard.
+func (s Selector) Concrete() (Version, bool) {
+	if s.Major == wildcard || s.Minor == wildcard || s.Patch == wildcard {
+		return Version{}, false
+	}
+	return Version{Major: s.Major, Minor: s.Minor, Patch: s.Patch}, true
+}
+
+// Latest returns the newest of versions selected by s.
+func (s Selector) Latest(versions []Version) (Version, bool) {
+	var latest Version
+	found := false
+	for _, v := range versions {
+		if s.Matches(v) && (!found || v.Newer(latest)) {
+			latest, found = v, true
+		}
+	}
+	return latest, found
+}
+
+// Platform is the operating system and the architecture the binaries are built for.
+type Platform struct {
+	OS, Arch string
+}
+
+// String implements fmt.Stringer
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// archivePrefix is the prefix of the names of the kubebuilder-tools archives.
+const archivePrefix = "kubebuilder-tools-"
+
+// ArchiveName returns the name of the kubebuilder-tools archive of v for p, e.g.
+// kubebuilder-tools-1.19.2-linux-amd64.tar.gz.
+func ArchiveName(v Version, p Platform) string {
+	return fmt.Sprintf("%s%s-%s-%s.tar.gz", archivePrefix, v, p.OS, p.Arch)
+}
+
+// parseArchiveName returns the version of the archive named name, if it is the archive of a version for p.
+func parseArchiveName(name string, p Platform) (Version, bool) {
+	suffix := fmt.Sprintf("-%s-%s.tar.gz", p.OS, p.Arch)
+	if !strings.HasPrefix(name, archivePrefix) || !strings.HasSuffix(name, suffix) {
+		return Version{}, false
+	}
+	v, err := ParseVersion(strings.TrimSuffix(strings.TrimPrefix(name, archivePrefix), suffix))
+	return v, err == nil
+}
+
+// binaries are the binaries that envtest requires in KUBEBUILDER_ASSETS.
+var binaries = []string{"etcd", "kube-apiserver"}
+
+// Store is a directory holding the installed binaries, in a sub-directory per version and platform.
+type Store struct {
+	Root string
+}
+
+// DefaultRoot returns the root of the store shared by the projects of the user, in the user cache directory.
+func DefaultRoot() (string, error) {
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, "kubebuilder-envtest"), nil
+}
+
+// Dir returns the directory of the binaries of v for p.
+func (s Store) Dir(v Version, p Platform) string {
+	return filepath.Join(s.Root, "k8s", fmt.Sprintf("%s-%s-%s", v, p.OS, p.Arch))
+}
+
+// List returns the versions installed for p, the newest first.
+func (s Store) List(p Platform) ([]Version, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(s.Root, "k8s"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	suffix := fmt.Sprintf("-%s-%s", p.OS, p.Arch)
+	var versions []Version
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasSuffix(entry.Name(), suffix) {
+			continue
+		}
+		if v, err := ParseVersion(strings.TrimSuffix(entry.Name(), suffix)); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	sortNewestFirst(versions)
+	return versions, nil
+}
+
+// Install installs the binaries of v for p from the kubebuilder-tools archive read from archive, replacing the
+// installed ones if any, and returns the sha256 checksum of the archive. If checksum is not empty, the archive
+// is only installed if its checksum matches.
+func (s Store) Install(archive io.Reader, v Version, p Platform, checksum string) (string, error) {
+	if err := os.MkdirAll(filepath.Join(s.Root, "k8s"), 0755); err != nil {
+		return "", err
+	}
+	// The binaries are extracted next to their final directory, which is only replaced once they all are
+	tmp, err := ioutil.TempDir(filepath.Join(s.Root, "k8s"), ".install-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	hash := sha256.New()
+	reader := io.TeeReader(archive, hash)
+	if err := extract(reader, tmp); err != nil {
+		return "", fmt.Errorf("unable to extract the archive: %v", err)
+	}
+	// Hash the padding following the tar archive too
+	if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+		return "", err
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if checksum != "" && !strings.EqualFold(checksum, sum) {
+		return "", fmt.Errorf("the sha256 checksum of the archive is %s, expected %s", sum, checksum)
+	}
+
+	for _, binary := range binaries {
+		if _, err := os.Stat(filepath.Join(tmp, binary)); err != nil {
+			return "", fmt.Errorf("the archive does not contain %s in kubebuilder/bin", binary)
+		}
+	}
+
+	dir := s.Dir(v, p)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+	return sum, nil
+}
+
+// extract extracts the files of the kubebuilder/bin directory of the gzipped tar archive r into dir.
+func extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		// Only the binaries are extracted, flattened, which also keeps the paths of the archive out of dir
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if header.Typeflag != tar.TypeReg || path.Dir(name) != "kubebuilder/bin" {
+			continue
+		}
+		if err := writeFile(filepath.Join(dir, path.Base(name)), archive); err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(path string, r io.Reader) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil { //nolint:gosec
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func sortNewestFirst(versions []Version) {
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Newer(versions[j]) })
+}
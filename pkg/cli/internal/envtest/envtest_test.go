@@ -0,0 +1,236 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var linux = Platform{OS: "linux", Arch: "amd64"}
+
+func versions(t *testing.T, names ...string) []Version {
+	result := make([]Version, 0, len(names))
+	for _, name := range names {
+		v, err := ParseVersion(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+func TestSelector(t *testing.T) {
+	available := versions(t, "1.18.9", "1.19.0", "1.19.2", "1.20.2")
+	for selector, expected := range map[string]string{
+		"1.19.2": "1.19.2",
+		"1.19.x": "1.19.2",
+		"1.19.*": "1.19.2",
+		"v1.19":  "1.19.2",
+		"1.x":    "1.20.2",
+		"latest": "1.20.2",
+		"1.18":   "1.18.9",
+		"1.21.x": "",
+	} {
+		sel, err := ParseSelector(selector)
+		if err != nil {
+			t.Fatalf("%s: %v", selector, err)
+		}
+		v, found := sel.Latest(available)
+		if found != (expected != "") || (found && v.String() != expected) {
+			t.Errorf("%s: expected %q, got %s (found %t)", selector, expected, v, found)
+		}
+	}
+
+	for _, selector := range []string{"1.19.2.1", "1.a", "-1.19"} {
+		if _, err := ParseSelector(selector); err == nil {
+			t.Errorf("%s: expected an error", selector)
+		}
+	}
+	if _, err := ParseVersion("1.19.x"); err == nil {
+		t.Error("expected an error parsing 1.19.x as a version")
+	}
+}
+
+// archive returns a kubebuilder-tools archive containing files, and its sha256 checksum.
+func archive(t *testing.T, files ...string) ([]byte, string) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, name := range files {
+		content := "#!/bin/sh\necho " + name + "\n"
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(content)),
+			Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(sum[:])
+}
+
+var tools = []string{"kubebuilder/bin/etcd", "kubebuilder/bin/kube-apiserver", "kubebuilder/bin/kubectl",
+	"kubebuilder/README.md", "../escape"}
+
+func TestStoreInstall(t *testing.T) {
+	root, err := ioutil.TempDir("", "envtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	store := Store{Root: root}
+	v := versions(t, "1.19.2")[0]
+
+	content, sum := archive(t, tools...)
+	if _, err := store.Install(bytes.NewReader(content), v, linux, strings.Repeat("0", 64)); err == nil ||
+		!strings.Contains(err.Error(), "checksum") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if installed, err := store.List(linux); err != nil || len(installed) != 0 {
+		t.Fatalf("expected no installed version after the mismatch, got %v (%v)", installed, err)
+	}
+
+	got, err := store.Install(bytes.NewReader(content), v, linux, strings.ToUpper(sum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != sum {
+		t.Errorf("expected the checksum %s, got %s", sum, got)
+	}
+	files, err := ioutil.ReadDir(store.Dir(v, linux))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+	if expected := []string{"etcd", "kube-apiserver", "kubectl"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected the binaries %v, got %v", expected, names)
+	}
+
+	incomplete, _ := archive(t, "kubebuilder/bin/etcd")
+	if _, err := store.Install(bytes.NewReader(incomplete), versions(t, "1.20.2")[0], linux, ""); err == nil ||
+		!strings.Contains(err.Error(), "kube-apiserver") {
+		t.Fatalf("expected kube-apiserver to be missing, got %v", err)
+	}
+
+	other := Platform{OS: "darwin", Arch: "amd64"}
+	if _, err := store.Install(bytes.NewReader(content), versions(t, "1.18.9")[0], other, ""); err != nil {
+		t.Fatal(err)
+	}
+	installed, err := store.List(linux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := versions(t, "1.19.2"); !reflect.DeepEqual(installed, expected) {
+		t.Errorf("expected the installed versions %v, got %v", expected, installed)
+	}
+}
+
+func TestRemote(t *testing.T) {
+	content, _ := archive(t, tools...)
+	pages := []string{
+		`<ListBucketResult><IsTruncated>true</IsTruncated>
+<Contents><Key>kubebuilder-tools-1.18.9-linux-amd64.tar.gz</Key></Contents>
+<Contents><Key>kubebuilder-tools-1.19.2-darwin-amd64.tar.gz</Key></Contents>
+</ListBucketResult>`,
+		`<ListBucketResult><IsTruncated>false</IsTruncated>
+<Contents><Key>kubebuilder-tools-1.19.2-linux-amd64.tar.gz</Key></Contents>
+<Contents><Key>kubebuilder-tools-1.19.x-linux-amd64.tar.gz</Key></Contents>
+</ListBucketResult>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/tools/" && r.URL.Query().Get("marker") == "":
+			fmt.Fprint(w, pages[0])
+		case r.URL.Path == "/tools/" && r.URL.Query().Get("marker") == "kubebuilder-tools-1.19.2-darwin-amd64.tar.gz":
+			fmt.Fprint(w, pages[1])
+		case r.URL.Path == "/tools/kubebuilder-tools-1.19.2-linux-amd64.tar.gz":
+			_, _ = w.Write(content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	remote := Remote{URL: server.URL + "/tools"}
+
+	available, err := remote.List(linux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := versions(t, "1.19.2", "1.18.9"); !reflect.DeepEqual(available, expected) {
+		t.Errorf("expected the available versions %v, got %v", expected, available)
+	}
+
+	body, err := remote.Open(available[0], linux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(body)
+	_ = body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("expected the content of the archive")
+	}
+
+	if _, err := remote.Open(available[1], linux); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	if _, err := (Remote{URL: server.URL + "/plain"}).List(linux); err == nil {
+		t.Error("expected an error listing a remote that is not a bucket")
+	}
+}
+
+func TestStoreInstallKeepsArchivePathsOut(t *testing.T) {
+	root, err := ioutil.TempDir("", "envtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	content, _ := archive(t, append(tools, "kubebuilder/bin/../../../escape")...)
+	if _, err := (Store{Root: root}).Install(bytes.NewReader(content), versions(t, "1.19.2")[0], linux, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape")); !os.IsNotExist(err) {
+		t.Errorf("expected no file outside of the store, got %v", err)
+	}
+}
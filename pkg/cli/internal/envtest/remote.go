@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultRemoteURL is the URL of the bucket serving the kubebuilder-tools archives.
+const DefaultRemoteURL = "https://storage.googleapis.com/kubebuilder-tools"
+
+// Remote serves the kubebuilder-tools archives at URL/ARCHIVE, e.g. the upstream bucket or a mirror with the
+// same layout.
+type Remote struct {
+	URL string
+	// Client is the HTTP client of the requests, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// listBucketResult is the response of the listing of a Google Cloud Storage or S3 bucket.
+type listBucketResult struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated bool
+	NextMarker  string
+}
+
+// List returns the versions whose archive for p is served by r, the newest first. r.URL must list its content
+// as a Google Cloud Storage or S3 bucket does.
+func (r Remote) List(p Platform) ([]Version, error) {
+	var versions []Version
+	marker := ""
+	for {
+		query := url.Values{"prefix": {archivePrefix}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		body, err := r.get(strings.TrimSuffix(r.URL, "/") + "/?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(body).Decode(&result)
+		_ = body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to list the archives of %s, which must list its content as a bucket does: %v",
+				r.URL, err)
+		}
+
+		for _, content := range result.Contents {
+			if v, found := parseArchiveName(content.Key, p); found {
+				versions = append(versions, v)
+			}
+		}
+		if !result.IsTruncated || len(result.Contents) == 0 {
+			break
+		}
+		marker = result.NextMarker
+		if marker == "" {
+			marker = result.Contents[len(result.Contents)-1].Key
+		}
+	}
+	sortNewestFirst(versions)
+	return versions, nil
+}
+
+// Open returns the archive of v for p, which the caller must close.
+func (r Remote) Open(v Version, p Platform) (io.ReadCloser, error) {
+	return r.get(strings.TrimSuffix(r.URL, "/") + "/" + ArchiveName(v, p))
+}
+
+func (r Remote) get(u string) (io.ReadCloser, error) {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Get(u) //nolint:noctx
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("unable to get %s: %s", u, resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package graph describes the kinds of a project, the kinds owned by the objects of their controllers and
+// their webhooks as a graph, rendered in the DOT or mermaid language.
+package graph
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/projectcheck"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/projectinfo"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+const (
+	// FormatDOT renders the graph in the DOT language of Graphviz
+	FormatDOT = "dot"
+	// FormatMermaid renders the graph as a mermaid flowchart, e.g. embedded in a markdown document
+	FormatMermaid = "mermaid"
+)
+
+// Kind is a node of the graph: a kind of the project, or a kind owned by the objects of its controllers.
+type Kind struct {
+	// ID is the kind qualified by its group, e.g. Deployment.apps, as the names of the CRDs.
+	ID    string
+	Group string
+	Kind  string
+	// Versions are the versions of the kind in the PROJECT file, empty for the kinds only found in the
+	// controllers.
+	Versions []string
+	// External is true for the kinds that are not APIs of the project, such as the builtin ones.
+	External bool
+	// Webhooks are the types of the webhooks of the kind, e.g. defaulting.
+	Webhooks []string
+}
+
+// Owns is an edge of the graph: the controller of Owner watches the objects of Owned it creates.
+type Owns struct {
+	Owner, Owned string
+}
+
+// Graph holds the kinds of a project and their ownership relations.
+type Graph struct {
+	Kinds []Kind
+	Owns  []Owns
+}
+
+// Load returns the graph of the project rooted at root: the kinds of its PROJECT file, their webhooks, and the
+// kinds passed to the Owns calls of the builders of their controllers.
+func Load(cfg config.Config, root string) (*Graph, error) {
+	apis, err := projectinfo.APIs(cfg, root, filepath.Join(root, "config", "crd", "bases"))
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Graph{}
+	index := map[string]int{}
+	add := func(kind Kind) *Kind {
+		if i, found := index[kind.ID]; found {
+			return &g.Kinds[i]
+		}
+		index[kind.ID] = len(g.Kinds)
+		g.Kinds = append(g.Kinds, kind)
+		return &g.Kinds[len(g.Kinds)-1]
+	}
+
+	// The import paths of the API packages of the project, resolving the kinds of the controllers
+	packages := map[string]string{}
+	var controllers []controller
+	for i, api := range apis {
+		res := cfg.Resources[i]
+		// Version 2 does not record whether the types were scaffolded, they are in every resource
+		external := res.API == nil && !cfg.IsV2()
+		group := api.Group
+		if external {
+			group = builtinGroup(group)
+		} else {
+			packages[path.Join(cfg.Repo, filepath.ToSlash(projectcheck.APIDir(cfg, res)))] = group
+		}
+		kind := add(Kind{ID: api.Kind + "." + group, Group: group, Kind: api.Kind, External: external})
+		kind.Versions = appendUnique(kind.Versions, api.Version)
+		if api.Controller != "" {
+			controllers = append(controllers, controller{path: api.Controller, kind: kind.ID})
+		}
+	}
+	for _, webhook := range projectinfo.Webhooks(cfg, root) {
+		kind := add(Kind{ID: webhook.Kind + "." + webhook.Group, Group: webhook.Group, Kind: webhook.Kind})
+		for _, webhookType := range webhook.Types {
+			kind.Webhooks = appendUnique(kind.Webhooks, webhookType)
+		}
+	}
+
+	seen := map[Owns]bool{}
+	for _, c := range controllers {
+		owns, err := ownedKinds(filepath.Join(root, c.path), packages)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range owns {
+			if o.owner == nil {
+				o.owner = &Kind{ID: c.kind}
+			}
+			owner := add(*o.owner).ID
+			owned := add(*o.owned).ID
+			edge := Owns{Owner: owner, Owned: owned}
+			if !seen[edge] {
+				seen[edge] = true
+				g.Owns = append(g.Owns, edge)
+			}
+		}
+	}
+	return g, nil
+}
+
+// controller is the controller file of a kind of the PROJECT file.
+type controller struct {
+	path string
+	kind string
+}
+
+// ownership is an Owns call of a builder, whose owner is nil if the For call is not in the same chain.
+type ownership struct {
+	owner, owned *Kind
+	pos          token.Pos
+}
+
+// ownedKinds returns the kinds passed to the Owns calls of the builders of the controller file at filename,
+// along with the kind of the For call of their chain. packages maps the import paths of the API packages of
+// the project to their group.
+func ownedKinds(filename string, packages map[string]string) ([]ownership, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", filename, err)
+	}
+	imports := map[string]string{}
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := path.Base(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = importPath
+	}
+	kindOf := func(expr ast.Expr) *Kind {
+		if unary, isUnary := expr.(*ast.UnaryExpr); isUnary {
+			expr = unary.X
+		}
+		lit, isLit := expr.(*ast.CompositeLit)
+		if !isLit {
+			return nil
+		}
+		sel, isSel := lit.Type.(*ast.SelectorExpr)
+		if !isSel {
+			return nil
+		}
+		pkg, isIdent := sel.X.(*ast.Ident)
+		if !isIdent || imports[pkg.Name] == "" {
+			return nil
+		}
+		return kindOfPackage(imports[pkg.Name], sel.Sel.Name, packages)
+	}
+
+	var owns []ownership
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, isCall := node.(*ast.CallExpr)
+		if !isCall || len(call.Args) == 0 {
+			return true
+		}
+		sel, isSel := call.Fun.(*ast.SelectorExpr)
+		if !isSel || sel.Sel.Name != "Owns" {
+			return true
+		}
+		owned := kindOf(call.Args[0])
+		if owned == nil {
+			return true
+		}
+		o := ownership{owned: owned, pos: call.Args[0].Pos()}
+		// Look for the For call in the receivers of the chain, e.g. For(&v1.Owner{}).Owns(&v1.Owned{})
+		for receiver := sel.X; ; {
+			receiverCall, isCall := receiver.(*ast.CallExpr)
+			if !isCall {
+				break
+			}
+			receiverSel, isSel := receiverCall.Fun.(*ast.SelectorExpr)
+			if !isSel {
+				break
+			}
+			if receiverSel.Sel.Name == "For" && len(receiverCall.Args) != 0 {
+				o.owner = kindOf(receiverCall.Args[0])
+				break
+			}
+			receiver = receiverSel.X
+		}
+		owns = append(owns, o)
+		return true
+	})
+	// The outermost calls of a chain are inspected first, keep the order of the source instead
+	sort.Slice(owns, func(i, j int) bool { return owns[i].pos < owns[j].pos })
+	return owns, nil
+}
+
+// kindOfPackage returns the kind of the type named kind of the package importPath.
+func kindOfPackage(importPath, kind string, packages map[string]string) *Kind {
+	if group, found := packages[importPath]; found {
+		return &Kind{ID: kind + "." + group, Group: group, Kind: kind}
+	}
+	group := importPath
+	// The builtin types are in k8s.io/api/<group>/<version>
+	if parts := strings.Split(importPath, "/"); len(parts) == 4 && parts[0] == "k8s.io" && parts[1] == "api" {
+		group = builtinGroup(parts[2])
+	}
+	return &Kind{ID: kind + "." + group, Group: group, Kind: kind, External: true}
+}
+
+// builtinGroup returns the fully qualified group of the builtin group named group in the packages of
+// k8s.io/api, or in the PROJECT file, e.g. networking.k8s.io for networking.
+func builtinGroup(group string) string {
+	switch group {
+	case "", "core":
+		return "core"
+	case "apps", "batch", "policy", "autoscaling", "extensions":
+		return group
+	case "rbac":
+		return "rbac.authorization.k8s.io"
+	}
+	if strings.Contains(group, ".") {
+		return group
+	}
+	return group + ".k8s.io"
+}
+
+// appendUnique appends value to values if it is not in it yet.
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// Render returns the graph in format, which may be dot or mermaid.
+func (g *Graph) Render(format string) (string, error) {
+	switch format {
+	case FormatDOT:
+		return g.dot(), nil
+	case FormatMermaid:
+		return g.mermaid(), nil
+	default:
+		return "", fmt.Errorf("format (%s) is invalid: may be one of %q, %q", format, FormatDOT, FormatMermaid)
+	}
+}
+
+// label returns the lines of the label of kind: its kind, group and versions, and its webhooks.
+func (k Kind) label() []string {
+	lines := []string{k.Kind, k.Group}
+	if len(k.Versions) != 0 {
+		lines[1] += " " + strings.Join(k.Versions, ", ")
+	}
+	if len(k.Webhooks) != 0 {
+		lines = append(lines, "webhooks: "+strings.Join(k.Webhooks, ", "))
+	}
+	return lines
+}
+
+// sortedKinds returns the kinds of the project first, then the external ones, each sorted by ID.
+func (g *Graph) sortedKinds() []Kind {
+	kinds := append([]Kind(nil), g.Kinds...)
+	sort.SliceStable(kinds, func(i, j int) bool {
+		if kinds[i].External != kinds[j].External {
+			return !kinds[i].External
+		}
+		return kinds[i].ID < kinds[j].ID
+	})
+	return kinds
+}
+
+func (g *Graph) dot() string {
+	var sb strings.Builder
+	sb.WriteString("digraph project {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+	for _, kind := range g.sortedKinds() {
+		style := ""
+		if kind.External {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(&sb, "  %q [label=%q%s];\n", kind.ID, strings.Join(kind.label(), "\n"), style)
+	}
+	for _, edge := range g.Owns {
+		fmt.Fprintf(&sb, "  %q -> %q [label=\"owns\"];\n", edge.Owner, edge.Owned)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (g *Graph) mermaid() string {
+	ids := map[string]string{}
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for i, kind := range g.sortedKinds() {
+		// The IDs of mermaid nodes can not hold dots
+		id := fmt.Sprintf("k%d", i)
+		ids[kind.ID] = id
+		open, closing := "[", "]"
+		if kind.External {
+			open, closing = "([", "])"
+		}
+		fmt.Fprintf(&sb, "  %s%s\"%s\"%s\n", id, open, strings.Join(kind.label(), "<br/>"), closing)
+	}
+	for _, edge := range g.Owns {
+		fmt.Fprintf(&sb, "  %s -->|owns| %s\n", ids[edge.Owner], ids[edge.Owned])
+	}
+	return sb.String()
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package graph
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+const project = `domain: example.org
+layout: go.kubebuilder.io/v3
+multigroup: true
+projectName: ship
+repo: example.org/ship
+resources:
+- api:
+    crdVersion: v1
+  group: crew
+  kind: Captain
+  version: v1
+  webhooks:
+    defaulting: true
+    webhookVersion: v1
+- api:
+    crdVersion: v1
+  group: crew
+  kind: Sailor
+  version: v1
+- group: apps
+  kind: Deployment
+  version: v1
+version: 3-alpha
+`
+
+const captainController = `package crew
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	crewv1 "example.org/ship/apis/crew/v1"
+)
+
+func (r *CaptainReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&crewv1.Captain{}).
+		Owns(&crewv1.Sailor{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&networkingv1.Ingress{}).
+		Complete(r)
+}
+`
+
+const deploymentController = `package apps
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func (r *DeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	b := ctrl.NewControllerManagedBy(mgr)
+	return b.Owns(&corev1.ConfigMap{}).Complete(r)
+}
+`
+
+func newProject(t *testing.T) (config.Config, string) {
+	root, err := ioutil.TempDir("", "graph")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	files := map[string]string{
+		"apis/crew/v1/captain_webhook.go":           "package v1\n",
+		"controllers/crew/captain_controller.go":    captainController,
+		"controllers/apps/deployment_controller.go": deploymentController,
+	}
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cfg config.Config
+	if err := cfg.Unmarshal([]byte(project)); err != nil {
+		t.Fatal(err)
+	}
+	return cfg, root
+}
+
+func TestLoad(t *testing.T) {
+	cfg, root := newProject(t)
+	g, err := Load(cfg, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Graph{
+		Kinds: []Kind{
+			{ID: "Captain.crew.example.org", Group: "crew.example.org", Kind: "Captain", Versions: []string{"v1"},
+				Webhooks: []string{"defaulting"}},
+			{ID: "Sailor.crew.example.org", Group: "crew.example.org", Kind: "Sailor", Versions: []string{"v1"}},
+			{ID: "Deployment.apps", Group: "apps", Kind: "Deployment", Versions: []string{"v1"}, External: true},
+			{ID: "ConfigMap.core", Group: "core", Kind: "ConfigMap", External: true},
+			{ID: "Ingress.networking.k8s.io", Group: "networking.k8s.io", Kind: "Ingress", External: true},
+		},
+		Owns: []Owns{
+			{Owner: "Captain.crew.example.org", Owned: "Sailor.crew.example.org"},
+			{Owner: "Captain.crew.example.org", Owned: "ConfigMap.core"},
+			{Owner: "Captain.crew.example.org", Owned: "Deployment.apps"},
+			{Owner: "Captain.crew.example.org", Owned: "Ingress.networking.k8s.io"},
+			{Owner: "Deployment.apps", Owned: "ConfigMap.core"},
+		},
+	}
+	if !reflect.DeepEqual(g, expected) {
+		t.Errorf("expected %+v, got %+v", expected, g)
+	}
+}
+
+func TestRender(t *testing.T) {
+	g := &Graph{
+		Kinds: []Kind{
+			{ID: "Secret.core", Group: "core", Kind: "Secret", External: true},
+			{ID: "Captain.crew.example.org", Group: "crew.example.org", Kind: "Captain", Versions: []string{"v1"},
+				Webhooks: []string{"defaulting", "validation"}},
+		},
+		Owns: []Owns{{Owner: "Captain.crew.example.org", Owned: "Secret.core"}},
+	}
+
+	dot, err := g.Render(FormatDOT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedDOT := `digraph project {
+  rankdir=LR;
+  node [shape=box];
+  "Captain.crew.example.org" [label="Captain\ncrew.example.org v1\nwebhooks: defaulting, validation"];
+  "Secret.core" [label="Secret\ncore", style=dashed];
+  "Captain.crew.example.org" -> "Secret.core" [label="owns"];
+}
+`
+	if dot != expectedDOT {
+		t.Errorf("expected %q, got %q", expectedDOT, dot)
+	}
+
+	mermaid, err := g.Render(FormatMermaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedMermaid := `flowchart LR
+  k0["Captain<br/>crew.example.org v1<br/>webhooks: defaulting, validation"]
+  k1(["Secret<br/>core"])
+  k0 -->|owns| k1
+`
+	if mermaid != expectedMermaid {
+		t.Errorf("expected %q, got %q", expectedMermaid, mermaid)
+	}
+
+	if _, err := g.Render("svg"); err == nil {
+		t.Error("expected an error for an invalid format")
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// MergeGoMod merges the requirements of the go.mod file scaffolded by go/v3, target, into the one of the
+// project, current: the modules are required in the version of target unless current requires a newer one,
+// and the Go version of target is set if go/v3 changed it. The requirements of base, the go.mod file
+// scaffolded by go/v2, that go/v3 dropped are left to go mod tidy.
+func MergeGoMod(current, base, target []byte) ([]byte, error) {
+	currentFile, err := modfile.Parse(goModFile, current, nil)
+	if err != nil {
+		return nil, err
+	}
+	baseFile, err := modfile.Parse(goModFile, base, nil)
+	if err != nil {
+		return nil, err
+	}
+	targetFile, err := modfile.Parse(goModFile, target, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if targetFile.Go != nil && (baseFile.Go == nil || baseFile.Go.Version != targetFile.Go.Version) &&
+		(currentFile.Go == nil || semver.Compare("v"+currentFile.Go.Version, "v"+targetFile.Go.Version) < 0) {
+		if err := currentFile.AddGoStmt(targetFile.Go.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	required := make(map[string]string, len(currentFile.Require))
+	for _, req := range currentFile.Require {
+		required[req.Mod.Path] = req.Mod.Version
+	}
+	for _, req := range targetFile.Require {
+		version, found := required[req.Mod.Path]
+		if found && semver.Compare(version, req.Mod.Version) >= 0 {
+			continue
+		}
+		if err := currentFile.AddRequire(req.Mod.Path, req.Mod.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	currentFile.Cleanup()
+	return currentFile.Format()
+}
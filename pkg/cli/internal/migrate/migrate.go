@@ -0,0 +1,511 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migrate migrates a project scaffolded with the go/v2 plugin to the go/v3 layout in place.
+//
+// The scaffolding of the project is generated twice from its PROJECT file and the files found in the
+// project, with the go/v2 plugin it was created with, the base, and with the go/v3 plugin, the target.
+// The changes from the base to the target are then merged into the files of the project, which keeps the
+// changes of the user: the files the user did not modify are replaced, the others are merged three ways
+// and left with conflict markers where the user and go/v3 changed the same lines.
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+const (
+	// PluginV2 is the plugin the projects are migrated from.
+	PluginV2 = "go.kubebuilder.io/v2"
+	// PluginV3 is the plugin the projects are migrated to.
+	PluginV3 = "go.kubebuilder.io/v3"
+
+	projectFile     = "PROJECT"
+	goModFile       = "go.mod"
+	boilerplateFile = "hack/boilerplate.go.txt"
+)
+
+// movedPaths maps the paths of the files scaffolded by go/v2 to their path in the go/v3 layout.
+var movedPaths = map[string]string{
+	"config/default/manager_webhook_patch.yaml":    "config/components/webhook/manager_webhook_patch.yaml",
+	"config/default/webhookcainjection_patch.yaml": "config/components/certmanager/webhookcainjection_patch.yaml",
+}
+
+// skippedPaths are the files of the scaffolding that are never migrated.
+var skippedPaths = map[string]bool{
+	"go.sum": true,
+}
+
+// CanMigrate returns an error if the project of cfg was not scaffolded with the go/v2 plugin.
+func CanMigrate(cfg config.Config) error {
+	if cfg.IsV2() || (cfg.IsV3() && cfg.Layout == PluginV2) {
+		return nil
+	}
+	return fmt.Errorf("only the projects scaffolded with the %s plugin can be migrated, this one has the version %s "+
+		"and the layout %q", PluginV2, cfg.Version, cfg.Layout)
+}
+
+// Resource is a resource of the project, with the parts of its scaffolding found in the project.
+type Resource struct {
+	Group, Version, Kind string
+	// Resource is set if the API types are defined in the project, Namespaced if they are namespaced.
+	Resource, Namespaced bool
+	// Controller is set if the project has a controller for the resource.
+	Controller bool
+	// Defaulting, Validation and Conversion are the webhooks of the resource.
+	Defaulting, Validation, Conversion bool
+}
+
+// hasWebhook returns true if r has at least one webhook.
+func (r Resource) hasWebhook() bool {
+	return r.Defaulting || r.Validation || r.Conversion
+}
+
+// Detect returns the resources of cfg, with the parts of their scaffolding found in the project in dir.
+func Detect(cfg config.Config, dir string) ([]Resource, error) {
+	crdKustomization, err := readIfExists(filepath.Join(dir, "config", "crd", "kustomization.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]Resource, 0, len(cfg.Resources))
+	for _, gvk := range cfg.Resources {
+		r := Resource{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind, Namespaced: true}
+		lowerKind := strings.ToLower(gvk.Kind)
+
+		apiDir := filepath.Join(dir, "api", gvk.Version)
+		controllersDir := filepath.Join(dir, "controllers")
+		if cfg.MultiGroup {
+			apiDir = filepath.Join(dir, "apis", gvk.Group, gvk.Version)
+			controllersDir = filepath.Join(controllersDir, gvk.Group)
+		}
+
+		types, err := readIfExists(filepath.Join(apiDir, lowerKind+"_types.go"))
+		if err != nil {
+			return nil, err
+		}
+		r.Resource = types != nil
+		r.Namespaced = !bytes.Contains(types, []byte("scope=Cluster"))
+
+		controller, err := readIfExists(filepath.Join(controllersDir, lowerKind+"_controller.go"))
+		if err != nil {
+			return nil, err
+		}
+		r.Controller = controller != nil
+
+		webhook, err := readIfExists(filepath.Join(apiDir, lowerKind+"_webhook.go"))
+		if err != nil {
+			return nil, err
+		}
+		r.Defaulting = bytes.Contains(webhook, []byte("webhook.Defaulter"))
+		r.Validation = bytes.Contains(webhook, []byte("webhook.Validator"))
+		plural := strings.ToLower(flect.Pluralize(gvk.Kind))
+		r.Conversion = hasLine(crdKustomization, "- patches/webhook_in_"+plural+".yaml")
+
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// Commands returns the arguments of the kubebuilder commands scaffolding the project of cfg, whose resources
+// are resources, with plugin, PluginV2 or PluginV3. The go/v3 commands get extraInitArgs too.
+func Commands(cfg config.Config, resources []Resource, plugin string, extraInitArgs ...string) [][]string {
+	v3 := plugin == PluginV3
+
+	init := []string{"init", "--domain", cfg.Domain, "--repo", cfg.Repo,
+		"--fetch-deps=false", "--skip-go-version-check"}
+	if cfg.IsV2() && !v3 {
+		init = append(init, "--project-version", config.Version2)
+	} else {
+		init = append(init, "--project-version", config.Version3Alpha, "--plugins", plugin)
+		if cfg.ProjectName != "" {
+			init = append(init, "--project-name", cfg.ProjectName)
+		}
+	}
+	if v3 {
+		init = append(init, extraInitArgs...)
+	}
+	commands := [][]string{init}
+
+	if cfg.MultiGroup {
+		commands = append(commands, []string{"edit", "--multigroup"})
+	}
+	for _, r := range resources {
+		commands = append(commands, []string{"create", "api",
+			"--group", r.Group, "--version", r.Version, "--kind", r.Kind,
+			fmt.Sprintf("--resource=%t", r.Resource), fmt.Sprintf("--controller=%t", r.Controller),
+			fmt.Sprintf("--namespaced=%t", r.Namespaced), "--make=false"})
+	}
+	for _, r := range resources {
+		if !r.hasWebhook() {
+			continue
+		}
+		webhook := []string{"create", "webhook", "--group", r.Group, "--version", r.Version, "--kind", r.Kind,
+			fmt.Sprintf("--defaulting=%t", r.Defaulting),
+			fmt.Sprintf("--programmatic-validation=%t", r.Validation),
+			fmt.Sprintf("--conversion=%t", r.Conversion)}
+		if v3 {
+			webhook = append(webhook, "--make=false")
+		}
+		commands = append(commands, webhook)
+	}
+	return commands
+}
+
+// Scaffold runs the commands with the kubebuilder binary in dir, which must be empty.
+func Scaffold(binary, dir string, commands [][]string) error {
+	for _, args := range commands {
+		cmd := exec.Command(binary, args...) //nolint:gosec
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("unable to run %s %s: %v\n%s", filepath.Base(binary), strings.Join(args, " "), err, out)
+		}
+	}
+	return nil
+}
+
+// ReplaceBoilerplate replaces the boilerplate of the files scaffolded in dir with the boilerplate of the
+// project in projectDir, if any, so that the headers of the files do not differ from the ones of the project,
+// e.g. by their year.
+func ReplaceBoilerplate(dir, projectDir string) error {
+	boilerplate, err := readIfExists(filepath.Join(projectDir, boilerplateFile))
+	if err != nil || boilerplate == nil {
+		return err
+	}
+	scaffolded, err := ioutil.ReadFile(filepath.Join(dir, boilerplateFile))
+	if err != nil {
+		return err
+	}
+	if len(bytes.TrimSpace(scaffolded)) == 0 {
+		return nil
+	}
+
+	files, err := listFiles(dir)
+	if err != nil {
+		return err
+	}
+	for _, path := range files {
+		content, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			return err
+		}
+		var replaced []byte
+		if path == boilerplateFile {
+			replaced = boilerplate
+		} else {
+			replaced = bytes.Replace(content, bytes.TrimSpace(scaffolded), bytes.TrimSpace(boilerplate), 1)
+		}
+		if !bytes.Equal(replaced, content) {
+			if err := ioutil.WriteFile(filepath.Join(dir, path), replaced, 0644); err != nil { //nolint:gosec
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Action is the change made to a file of the project.
+type Action string
+
+const (
+	// Add adds a file scaffolded by go/v3 only.
+	Add Action = "add"
+	// Update replaces a file the user did not modify with its go/v3 version.
+	Update Action = "update"
+	// Merge merges the changes of go/v3 into a file modified by the user.
+	Merge Action = "merge"
+	// Conflict merges the changes of go/v3 into a file modified by the user, leaving conflict markers
+	// where both changed the same lines.
+	Conflict Action = "conflict"
+	// Remove removes a file that go/v3 does not scaffold anymore, which the user did not modify.
+	Remove Action = "remove"
+	// Keep keeps a file that go/v3 does not scaffold anymore, which the user modified.
+	Keep Action = "keep"
+)
+
+// Change is a change made to a file of the project.
+type Change struct {
+	// Path is the path of the file, relative to the project.
+	Path string
+	// From is the path of the file before the migration, if it is moved.
+	From   string
+	Action Action
+	// Content is the content of the file after the migration.
+	Content []byte
+	// Conflicts is the number of conflicts of a Conflict.
+	Conflicts int
+}
+
+// String implements fmt.Stringer
+func (c Change) String() string {
+	description := fmt.Sprintf("%-8s %s", c.Action, c.Path)
+	if c.From != "" {
+		description += " (moved from " + c.From + ")"
+	}
+	switch c.Action {
+	case Conflict:
+		description += fmt.Sprintf(": %d conflict(s) to resolve", c.Conflicts)
+	case Keep:
+		description += ": not scaffolded by go/v3 anymore, but modified"
+	}
+	return description
+}
+
+// Plan returns the changes migrating the project in dir, given the scaffolding of go/v2 in baseDir and the
+// one of go/v3 in targetDir. The files of the project that are not part of the scaffolding are untouched.
+func Plan(dir, baseDir, targetDir string) ([]Change, error) {
+	baseFiles, err := listFiles(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	targetFiles, err := listFiles(targetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The base files, keyed by their path in the go/v3 layout
+	basePaths := make(map[string]string, len(baseFiles))
+	for _, path := range baseFiles {
+		if moved, isMoved := movedPaths[path]; isMoved {
+			basePaths[moved] = path
+		} else {
+			basePaths[path] = path
+		}
+	}
+
+	var changes []Change
+	for _, path := range targetFiles {
+		if skippedPaths[path] {
+			continue
+		}
+		from := basePaths[path]
+		delete(basePaths, path)
+
+		change, err := planFile(dir, baseDir, targetDir, path, from)
+		if err != nil {
+			return nil, fmt.Errorf("unable to migrate %s: %v", path, err)
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+
+	// The remaining base files are not scaffolded by go/v3 anymore
+	for _, from := range basePaths {
+		if skippedPaths[from] {
+			continue
+		}
+		base, err := ioutil.ReadFile(filepath.Join(baseDir, from))
+		if err != nil {
+			return nil, err
+		}
+		current, err := readIfExists(filepath.Join(dir, from))
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case current == nil:
+		case bytes.Equal(current, base):
+			changes = append(changes, Change{Path: from, Action: Remove})
+		default:
+			changes = append(changes, Change{Path: from, Action: Keep})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// planFile returns the change migrating the file of the project whose go/v3 scaffolding is path in targetDir,
+// and go/v2 scaffolding, if any, from in baseDir, or nil if the file is unchanged.
+func planFile(dir, baseDir, targetDir, path, from string) (*Change, error) {
+	target, err := ioutil.ReadFile(filepath.Join(targetDir, path))
+	if err != nil {
+		return nil, err
+	}
+	if from == "" {
+		// Scaffolded by go/v3 only, unless the user already added it
+		current, err := readIfExists(filepath.Join(dir, path))
+		if err != nil || current != nil {
+			return nil, err
+		}
+		return &Change{Path: path, Action: Add, Content: target}, nil
+	}
+
+	base, err := ioutil.ReadFile(filepath.Join(baseDir, from))
+	if err != nil {
+		return nil, err
+	}
+	current, err := readIfExists(filepath.Join(dir, from))
+	if err != nil {
+		return nil, err
+	}
+	change := &Change{Path: path}
+	if from != path {
+		change.From = from
+	}
+
+	switch {
+	case current == nil:
+		// Deleted by the user
+		return nil, nil
+	case path == projectFile:
+		// The PROJECT file is rewritten in the go/v3 format
+		change.Action, change.Content = Update, target
+	case path == goModFile:
+		content, err := MergeGoMod(current, base, target)
+		if err != nil {
+			return nil, err
+		}
+		change.Action, change.Content = Merge, content
+	case bytes.Equal(current, base):
+		change.Action, change.Content = Update, target
+	case bytes.Equal(base, target):
+		// Only modified by the user
+		change.Action, change.Content = Merge, current
+	default:
+		content, conflicts, err := Merge3(current, base, target, path)
+		if err != nil {
+			return nil, err
+		}
+		change.Action, change.Content, change.Conflicts = Merge, content, conflicts
+		if conflicts != 0 {
+			change.Action = Conflict
+		}
+	}
+
+	if change.From == "" && bytes.Equal(change.Content, current) {
+		return nil, nil
+	}
+	return change, nil
+}
+
+// Apply writes the changes to the project in dir.
+func Apply(dir string, changes []Change) error {
+	for _, change := range changes {
+		switch change.Action {
+		case Keep:
+			continue
+		case Remove:
+			if err := os.Remove(filepath.Join(dir, change.Path)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		path := filepath.Join(dir, change.Path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, change.Content, 0644); err != nil { //nolint:gosec
+			return err
+		}
+		if change.From != "" {
+			if err := os.Remove(filepath.Join(dir, change.From)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Merge3 merges the changes from base to theirs into ours with git merge-file, and returns the result with
+// the number of conflicts, which are surrounded by conflict markers in the result, showing the base too.
+func Merge3(ours, base, theirs []byte, path string) ([]byte, int, error) {
+	dir, err := ioutil.TempDir("", "kubebuilder-migrate-")
+	if err != nil {
+		return nil, 0, err
+	}
+	defer os.RemoveAll(dir)
+
+	files := make([]string, 0, 3)
+	for i, content := range [][]byte{ours, base, theirs} {
+		file := filepath.Join(dir, fmt.Sprint(i))
+		if err := ioutil.WriteFile(file, content, 0600); err != nil {
+			return nil, 0, err
+		}
+		files = append(files, file)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"merge-file", "-p", "--diff3", //nolint:gosec
+		"-L", path, "-L", path + " (" + PluginV2 + ")", "-L", path + " (" + PluginV3 + ")"}, files...)...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err = cmd.Run()
+	// git merge-file exits with the number of conflicts, and a negative value on errors
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return stdout.Bytes(), exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to run git merge-file: %v %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), 0, nil
+}
+
+// listFiles returns the paths of the files of dir, relative to dir and slash separated.
+func listFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" || info.Name() == "bin" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+// readIfExists returns the content of the file of path, or nil if it does not exist.
+func readIfExists(path string) ([]byte, error) {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// hasLine returns true if content has a line equal to line, ignoring the surrounding spaces.
+func hasLine(content []byte, line string) bool {
+	for _, l := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+// writeTree writes files, keyed by their slash separated path, in a new temporary directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestCanMigrate(t *testing.T) {
+	for _, cfg := range []config.Config{
+		{Version: config.Version2},
+		{Version: config.Version3Alpha, Layout: PluginV2},
+	} {
+		if err := CanMigrate(cfg); err != nil {
+			t.Errorf("expected %s %s to be migratable, got %v", cfg.Version, cfg.Layout, err)
+		}
+	}
+	if err := CanMigrate(config.Config{Version: config.Version3Alpha, Layout: PluginV3}); err == nil {
+		t.Error("expected a go/v3 project not to be migratable")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"api/v1/captain_types.go":              "package v1\n",
+		"api/v1/captain_webhook.go":            "var _ webhook.Defaulter = &Captain{}\n",
+		"api/v1/frigate_types.go":              "// +kubebuilder:resource:scope=Cluster\n",
+		"api/v1/frigate_webhook.go":            "var _ webhook.Validator = &Frigate{}\n",
+		"controllers/captain_controller.go":    "package controllers\n",
+		"controllers/deployment_controller.go": "package controllers\n",
+		"config/crd/kustomization.yaml":        "patchesStrategicMerge:\n- patches/webhook_in_frigates.yaml\n#- patches/webhook_in_captains.yaml\n",
+	})
+	defer os.RemoveAll(dir)
+
+	cfg := config.Config{Version: config.Version2, Resources: []config.ResourceData{
+		{Group: "crew", Version: "v1", Kind: "Captain"},
+		{Group: "crew", Version: "v1", Kind: "Frigate"},
+		{Group: "apps", Version: "v1", Kind: "Deployment"},
+	}}
+	resources, err := Detect(cfg, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Resource{
+		{Group: "crew", Version: "v1", Kind: "Captain", Resource: true, Namespaced: true, Controller: true,
+			Defaulting: true},
+		{Group: "crew", Version: "v1", Kind: "Frigate", Resource: true, Validation: true, Conversion: true},
+		{Group: "apps", Version: "v1", Kind: "Deployment", Namespaced: true, Controller: true},
+	}
+	if !reflect.DeepEqual(resources, expected) {
+		t.Errorf("expected %+v, got %+v", expected, resources)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	cfg := config.Config{Version: config.Version2, Domain: "example.com", Repo: "example.com/crew", MultiGroup: true}
+	resources := []Resource{{Group: "crew", Version: "v1", Kind: "Captain", Resource: true, Controller: true,
+		Namespaced: true, Defaulting: true}}
+
+	base := Commands(cfg, resources, PluginV2, "--min-k8s-version", "1.15")
+	target := Commands(cfg, resources, PluginV3, "--min-k8s-version", "1.15")
+	if len(base) != 4 || len(target) != 4 {
+		t.Fatalf("expected init, edit, create api and create webhook, got %v and %v", base, target)
+	}
+	if init := strings.Join(base[0], " "); !strings.Contains(init, "--project-version 2") ||
+		strings.Contains(init, "--min-k8s-version") {
+		t.Errorf("expected a version 2 init without the extra arguments, got %s", init)
+	}
+	if init := strings.Join(target[0], " "); !strings.Contains(init, "--plugins "+PluginV3) ||
+		!strings.HasSuffix(init, "--min-k8s-version 1.15") {
+		t.Errorf("expected a go/v3 init with the extra arguments, got %s", init)
+	}
+	if webhook := strings.Join(base[3], " "); strings.Contains(webhook, "--make") ||
+		!strings.Contains(webhook, "--defaulting=true") {
+		t.Errorf("expected a go/v2 defaulting webhook, got %s", webhook)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	base := writeTree(t, map[string]string{
+		"PROJECT":    "version: \"2\"\n",
+		"Makefile":   "a\nb\nc\n",
+		"main.go":    "a\nb\nc\nd\ne\n",
+		"Dockerfile": "a\n",
+		"config/default/manager_webhook_patch.yaml": "a\nb\nc\n",
+		"config/default/removed.yaml":               "a\n",
+		"config/default/modified.yaml":              "a\n",
+		"config/samples/sample.yaml":                "a\n",
+	})
+	defer os.RemoveAll(base)
+	target := writeTree(t, map[string]string{
+		"PROJECT":    "version: 3-alpha\n",
+		"Makefile":   "a\nb\nc\nd\n",
+		"main.go":    "A\nb\nc\nd\nE\n",
+		"Dockerfile": "b\n",
+		"config/components/webhook/manager_webhook_patch.yaml": "a\nb\nC\n",
+		"config/samples/sample.yaml":                           "b\n",
+		"hack/new.go":                                          "a\n",
+		"go.sum":                                               "a\n",
+	})
+	defer os.RemoveAll(target)
+	dir := writeTree(t, map[string]string{
+		"PROJECT":    "version: \"2\"\nresources: []\n",
+		"Makefile":   "a\nb\nc\n",
+		"main.go":    "a\nb\nC\nd\ne\n",
+		"Dockerfile": "c\n",
+		"config/default/manager_webhook_patch.yaml": "A\nb\nc\n",
+		"config/default/removed.yaml":               "a\n",
+		"config/default/modified.yaml":              "b\n",
+		"controllers/user.go":                       "a\n",
+	})
+	defer os.RemoveAll(dir)
+
+	changes, err := Plan(dir, base, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := map[string]Action{}
+	for _, change := range changes {
+		actions[change.Path] = change.Action
+	}
+	expected := map[string]Action{
+		"PROJECT":    Update,
+		"Makefile":   Update,
+		"main.go":    Merge,
+		"Dockerfile": Conflict,
+		"config/components/webhook/manager_webhook_patch.yaml": Merge,
+		"config/default/removed.yaml":                          Remove,
+		"config/default/modified.yaml":                         Keep,
+		"hack/new.go":                                          Add,
+	}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected the actions %v, got %v", expected, actions)
+	}
+
+	if err := Apply(dir, changes); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		"Makefile": "a\nb\nc\nd\n",
+		"main.go":  "A\nb\nC\nd\nE\n",
+		"config/components/webhook/manager_webhook_patch.yaml": "A\nb\nC\n",
+		"config/default/modified.yaml":                         "b\n",
+		"controllers/user.go":                                  "a\n",
+	} {
+		got, err := ioutil.ReadFile(filepath.Join(dir, path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("%s: expected %q, got %q", path, content, got)
+		}
+	}
+	dockerfile, err := ioutil.ReadFile(filepath.Join(dir, "Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dockerfile), "<<<<<<< Dockerfile\nc\n") {
+		t.Errorf("expected conflict markers keeping the changes of the user, got %q", dockerfile)
+	}
+	for _, path := range []string{"config/default/manager_webhook_patch.yaml", "config/default/removed.yaml",
+		"config/samples/sample.yaml", "go.sum"} {
+		if _, err := os.Stat(filepath.Join(dir, path)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to exist, got %v", path, err)
+		}
+	}
+}
+
+func TestMergeGoMod(t *testing.T) {
+	base := "module example.com/crew\n\ngo 1.13\n\nrequire (\n\tk8s.io/api v0.18.6\n" +
+		"\tsigs.k8s.io/controller-runtime v0.6.4\n)\n"
+	target := "module example.com/crew\n\ngo 1.15\n\nrequire (\n\tk8s.io/api v0.19.2\n" +
+		"\tsigs.k8s.io/controller-runtime v0.7.0\n\tsigs.k8s.io/yaml v1.2.0\n)\n"
+	current := "module example.com/crew\n\ngo 1.13\n\nrequire (\n\tgithub.com/pkg/errors v0.9.1\n" +
+		"\tk8s.io/api v0.20.0\n\tsigs.k8s.io/controller-runtime v0.6.4\n)\n"
+
+	merged, err := MergeGoMod([]byte(current), []byte(base), []byte(target))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"go 1.15", "github.com/pkg/errors v0.9.1", "k8s.io/api v0.20.0",
+		"sigs.k8s.io/controller-runtime v0.7.0", "sigs.k8s.io/yaml v1.2.0"} {
+		if !strings.Contains(string(merged), expected) {
+			t.Errorf("expected %q in the merged go.mod, got:\n%s", expected, merged)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// celReservedWords are the CEL keywords that must be escaped when used as field names
+var celReservedWords = map[string]bool{
+	"true": true, "false": true, "null": true, "in": true, "as": true, "break": true, "const": true,
+	"continue": true, "else": true, "for": true, "function": true, "if": true, "import": true, "let": true,
+	"loop": true, "package": true, "namespace": true, "return": true, "var": true, "void": true, "while": true,
+}
+
+// CEL returns a ValidatingAdmissionPolicy, with the CEL expressions enforcing the rules of kind, followed by
+// a ValidatingAdmissionPolicyBinding applying it to the objects of kind.
+func CEL(kind Kind) ([]byte, error) {
+	name := strings.ToLower(kind.Kind) + "-validation." + kind.Group
+
+	validations := make([]interface{}, 0, len(kind.Rules))
+	for _, rule := range kind.Rules {
+		validations = append(validations, map[string]interface{}{
+			"expression": celExpression("object", rule.Path, 0, func(value string) string {
+				return celCondition(rule, value)
+			}),
+			"message": message(rule),
+		})
+	}
+
+	policy := map[string]interface{}{
+		"apiVersion": "admissionregistration.k8s.io/v1",
+		"kind":       "ValidatingAdmissionPolicy",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"failurePolicy": "Fail",
+			"matchConstraints": map[string]interface{}{
+				"resourceRules": []interface{}{
+					map[string]interface{}{
+						"apiGroups":   []string{kind.Group},
+						"apiVersions": []string{kind.Version},
+						"operations":  []string{"CREATE", "UPDATE"},
+						"resources":   []string{kind.Plural},
+					},
+				},
+			},
+			"validations": validations,
+		},
+	}
+	binding := map[string]interface{}{
+		"apiVersion": "admissionregistration.k8s.io/v1",
+		"kind":       "ValidatingAdmissionPolicyBinding",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec": map[string]interface{}{
+			"policyName":        name,
+			"validationActions": []string{"Deny"},
+		},
+	}
+	return marshalDocuments(policy, binding)
+}
+
+// celExpression returns the CEL expression that holds when the field at path of base is absent or satisfies
+// the condition, iterating over lists with all().
+func celExpression(base string, path []string, depth int, condition func(string) string) string {
+	if len(path) == 0 {
+		return condition(base)
+	}
+	if path[0] == listItems {
+		item := fmt.Sprintf("i%d", depth)
+		return fmt.Sprintf("%s.all(%s, %s)", base, item, celExpression(item, path[1:], depth+1, condition))
+	}
+	field := base + "." + celFieldName(path[0])
+	return fmt.Sprintf("!has(%s) || %s", field, celExpression(field, path[1:], depth, condition))
+}
+
+// celFieldName escapes a field name as required by the CEL integration of Kubernetes.
+func celFieldName(name string) string {
+	if celReservedWords[name] {
+		return "__" + name + "__"
+	}
+	if identifierRegex.MatchString(name) && !strings.Contains(name, "__") {
+		return name
+	}
+	return strings.NewReplacer("__", "__underscores__", ".", "__dot__", "-", "__dash__", "/", "__slash__").
+		Replace(name)
+}
+
+// celCondition returns the CEL expression that holds when value satisfies rule.
+func celCondition(rule Rule, value string) string {
+	switch rule.Marker {
+	case "Minimum":
+		return value + " >= " + rule.Value
+	case "Maximum":
+		return value + " <= " + rule.Value
+	case "MinLength", "MinItems":
+		return fmt.Sprintf("size(%s) >= %s", value, rule.Value)
+	case "MaxLength", "MaxItems":
+		return fmt.Sprintf("size(%s) <= %s", value, rule.Value)
+	case "Pattern":
+		return fmt.Sprintf("%s.matches(%s)", value, strconv.Quote(rule.Value))
+	case "Enum":
+		return fmt.Sprintf("%s in [%s]", value, literals(rule.Value))
+	}
+	return "true"
+}
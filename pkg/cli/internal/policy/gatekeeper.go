@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Gatekeeper returns a Gatekeeper ConstraintTemplate, with the Rego enforcing the rules of kind, followed by
+// a Constraint applying it to the objects of kind.
+func Gatekeeper(kind Kind) ([]byte, error) {
+	constraintKind := kind.Kind + "Validation"
+	pkg := strings.ToLower(constraintKind)
+
+	rego := []string{fmt.Sprintf("package %s\n", pkg)}
+	for _, rule := range kind.Rules {
+		rego = append(rego, fmt.Sprintf(`violation[{"msg": msg}] {
+  value := %s
+  %s
+  msg := sprintf("%%s, got %%v", [%s, value])
+}
+`, regoPath(rule.Path), regoViolation(rule), strconv.Quote(message(rule))))
+	}
+
+	template := map[string]interface{}{
+		"apiVersion": "templates.gatekeeper.sh/v1beta1",
+		"kind":       "ConstraintTemplate",
+		"metadata":   map[string]interface{}{"name": pkg},
+		"spec": map[string]interface{}{
+			"crd": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"names": map[string]interface{}{"kind": constraintKind},
+				},
+			},
+			"targets": []interface{}{
+				map[string]interface{}{
+					"target": "admission.k8s.gatekeeper.sh",
+					"rego":   strings.Join(rego, "\n"),
+				},
+			},
+		},
+	}
+	constraint := map[string]interface{}{
+		"apiVersion": "constraints.gatekeeper.sh/v1beta1",
+		"kind":       constraintKind,
+		"metadata":   map[string]interface{}{"name": strings.ToLower(kind.Kind) + "-validation"},
+		"spec": map[string]interface{}{
+			"match": map[string]interface{}{
+				"kinds": []interface{}{
+					map[string]interface{}{
+						"apiGroups": []string{kind.Group},
+						"kinds":     []string{kind.Kind},
+					},
+				},
+			},
+		},
+	}
+	return marshalDocuments(template, constraint)
+}
+
+// regoPath returns the Rego reference to the field at path of the reviewed object, iterating over lists.
+func regoPath(path []string) string {
+	ref := "input.review.object"
+	for _, segment := range path {
+		switch {
+		case segment == listItems:
+			ref += "[_]"
+		case identifierRegex.MatchString(segment):
+			ref += "." + segment
+		default:
+			ref += "[" + strconv.Quote(segment) + "]"
+		}
+	}
+	return ref
+}
+
+// regoViolation returns the Rego expression that holds when value violates rule.
+func regoViolation(rule Rule) string {
+	switch rule.Marker {
+	case "Minimum":
+		return "value < " + rule.Value
+	case "Maximum":
+		return "value > " + rule.Value
+	case "MinLength", "MinItems":
+		return "count(value) < " + rule.Value
+	case "MaxLength", "MaxItems":
+		return "count(value) > " + rule.Value
+	case "Pattern":
+		return fmt.Sprintf("not re_match(%s, value)", strconv.Quote(rule.Value))
+	case "Enum":
+		return fmt.Sprintf("not {%s}[value]", literals(rule.Value))
+	}
+	return "false"
+}
+
+// marshalDocuments returns the YAML documents of objects, separated by ---.
+func marshalDocuments(objects ...interface{}) ([]byte, error) {
+	var documents []string
+	for _, object := range objects {
+		document, err := yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, string(document))
+	}
+	return []byte(strings.Join(documents, "---\n")), nil
+}
@@ -0,0 +1,364 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package policy translates the +kubebuilder:validation markers of the API types of a project into
+// admission policies, for clusters that enforce validation outside of the webhooks of the operators.
+package policy
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+)
+
+const (
+	validationMarkerPrefix = "+kubebuilder:validation:"
+	groupNameMarkerPrefix  = "+groupName="
+	rootMarker             = "+kubebuilder:object:root=true"
+	resourceMarkerPrefix   = "+kubebuilder:resource:"
+
+	// listItems is the path segment denoting the items of a list
+	listItems = "[]"
+)
+
+// supportedMarkers are the validation markers translated into policies
+var supportedMarkers = map[string]bool{
+	"Minimum":   true,
+	"Maximum":   true,
+	"MinLength": true,
+	"MaxLength": true,
+	"MinItems":  true,
+	"MaxItems":  true,
+	"Pattern":   true,
+	"Enum":      true,
+}
+
+// Rule is a validation declared by a +kubebuilder:validation marker.
+type Rule struct {
+	// Path is the path of the validated field from the root of the object, listItems denoting the items of a list.
+	Path []string
+	// Marker is the name of the validation marker, e.g. Minimum.
+	Marker string
+	// Value is the argument of the validation marker, e.g. 1.
+	Value string
+}
+
+// FieldPath returns the path of the validated field, e.g. spec.containers[].name.
+func (r Rule) FieldPath() string {
+	return strings.Replace(strings.Join(r.Path, "."), "."+listItems, listItems, -1)
+}
+
+// Kind holds the validation rules declared on the types of an API kind.
+type Kind struct {
+	// Group is the fully qualified API group.
+	Group string
+	// Version is the API version.
+	Version string
+	// Kind is the API kind.
+	Kind string
+	// Plural is the plural form of the kind used in the resource paths.
+	Plural string
+	// Rules are the validation rules of the fields of the kind.
+	Rules []Rule
+}
+
+// Load parses the Go packages found in dir and its subdirectories and returns the kinds with validation rules.
+func Load(dir string) ([]Kind, error) {
+	var kinds []Kind
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return err
+		}
+		fset := token.NewFileSet()
+		pkgs, err := parser.ParseDir(fset, path, func(info os.FileInfo) bool {
+			return !strings.HasSuffix(info.Name(), "_test.go") && !strings.HasPrefix(info.Name(), "zz_generated")
+		}, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range pkgs {
+			pkgKinds, err := loadPackage(fset, pkg, filepath.Base(path))
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			kinds = append(kinds, pkgKinds...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(kinds, func(i, j int) bool {
+		if kinds[i].Group != kinds[j].Group {
+			return kinds[i].Group < kinds[j].Group
+		}
+		if kinds[i].Version != kinds[j].Version {
+			return kinds[i].Version < kinds[j].Version
+		}
+		return kinds[i].Kind < kinds[j].Kind
+	})
+	return kinds, nil
+}
+
+// typeDecl is a type declared in an API package with the lines of the comments documenting it
+type typeDecl struct {
+	spec    *ast.TypeSpec
+	markers []string
+}
+
+// loadPackage returns the kinds with validation rules declared in pkg.
+func loadPackage(fset *token.FileSet, pkg *ast.Package, version string) ([]Kind, error) {
+	group := ""
+	types := make(map[string]typeDecl)
+	for _, f := range pkg.Files {
+		for _, comments := range f.Comments {
+			for _, line := range commentLines(comments) {
+				if strings.HasPrefix(line, groupNameMarkerPrefix) {
+					group = strings.TrimPrefix(line, groupNameMarkerPrefix)
+				}
+			}
+		}
+		for _, decl := range f.Decls {
+			genDecl, isGenDecl := decl.(*ast.GenDecl)
+			if !isGenDecl || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				doc := typeSpec.Doc
+				if doc == nil {
+					doc = genDecl.Doc
+				}
+				types[typeSpec.Name.Name] = typeDecl{spec: typeSpec, markers: typeMarkers(fset, f, genDecl, doc)}
+			}
+		}
+	}
+	if group == "" {
+		return nil, nil
+	}
+
+	var kinds []Kind
+	for name, decl := range types {
+		if strings.HasSuffix(name, "List") || !hasMarker(decl.markers, rootMarker) {
+			continue
+		}
+		kind := Kind{Group: group, Version: version, Kind: name, Plural: flect.Pluralize(strings.ToLower(name))}
+		for _, line := range decl.markers {
+			if strings.HasPrefix(line, resourceMarkerPrefix) {
+				if plural := markerArgument(strings.TrimPrefix(line, resourceMarkerPrefix), "path"); plural != "" {
+					kind.Plural = plural
+				}
+			}
+		}
+
+		w := walker{types: types, visiting: make(map[string]bool)}
+		if err := w.walk(decl.spec.Type, nil); err != nil {
+			return nil, fmt.Errorf("kind %s: %v", name, err)
+		}
+		if len(w.rules) != 0 {
+			kind.Rules = w.rules
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, nil
+}
+
+// walker collects the validation rules of the fields of a type
+type walker struct {
+	types    map[string]typeDecl
+	visiting map[string]bool
+	rules    []Rule
+}
+
+func (w *walker) walk(expr ast.Expr, path []string) error {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		decl, found := w.types[t.Name]
+		if !found || w.visiting[t.Name] {
+			return nil
+		}
+		w.visiting[t.Name] = true
+		defer delete(w.visiting, t.Name)
+		if len(path) != 0 {
+			if err := w.addRules(decl.markers, path); err != nil {
+				return err
+			}
+		}
+		return w.walk(decl.spec.Type, path)
+	case *ast.StarExpr:
+		return w.walk(t.X, path)
+	case *ast.ArrayType:
+		return w.walk(t.Elt, append(path[:len(path):len(path)], listItems))
+	case *ast.StructType:
+		for _, field := range t.Fields.List {
+			name, inline := jsonName(field)
+			if name == "-" {
+				continue
+			}
+			fieldPath := path
+			if !inline {
+				// The status is validated when it is updated through its own subresource.
+				if len(path) == 0 && name == "status" {
+					continue
+				}
+				fieldPath = append(path[:len(path):len(path)], name)
+				if err := w.addRules(commentLines(field.Doc), fieldPath); err != nil {
+					return err
+				}
+			}
+			if err := w.walk(field.Type, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	// Types of other packages, such as metav1.ObjectMeta, and maps are not validated.
+	return nil
+}
+
+// addRules adds the rules declared by the validation markers found in lines for the field at path.
+func (w *walker) addRules(lines []string, path []string) error {
+	for _, line := range lines {
+		if !strings.HasPrefix(line, validationMarkerPrefix) {
+			continue
+		}
+		marker := strings.SplitN(strings.TrimPrefix(line, validationMarkerPrefix), "=", 2)
+		if len(marker) != 2 || !supportedMarkers[marker[0]] {
+			continue
+		}
+		value, err := markerValue(marker[0], marker[1])
+		if err != nil {
+			return fmt.Errorf("invalid %s marker on %s: %v", marker[0], strings.Join(path, "."), err)
+		}
+		w.rules = append(w.rules, Rule{Path: path, Marker: marker[0], Value: value})
+	}
+	return nil
+}
+
+// markerValue returns the unquoted value of a validation marker, checking that it is valid.
+func markerValue(marker, value string) (string, error) {
+	switch marker {
+	case "Minimum", "Maximum":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("expected a number, got %q", value)
+		}
+	case "MinLength", "MaxLength", "MinItems", "MaxItems":
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("expected an integer, got %q", value)
+		}
+	case "Pattern":
+		if strings.HasPrefix(value, "`") || strings.HasPrefix(value, `"`) {
+			return strconv.Unquote(value)
+		}
+	}
+	return value, nil
+}
+
+// enumValues returns the values of an Enum marker.
+func enumValues(value string) []string {
+	values := strings.Split(value, ";")
+	for i, v := range values {
+		if unquoted, err := strconv.Unquote(v); err == nil {
+			v = unquoted
+		}
+		values[i] = v
+	}
+	return values
+}
+
+// jsonName returns the JSON name of a field, and whether its fields are inlined in the parent object.
+func jsonName(field *ast.Field) (string, bool) {
+	tag := ""
+	if field.Tag != nil {
+		if unquoted, err := strconv.Unquote(field.Tag.Value); err == nil {
+			tag = reflect.StructTag(unquoted).Get("json")
+		}
+	}
+	options := strings.Split(tag, ",")
+	for _, option := range options[1:] {
+		if option == "inline" {
+			return "", true
+		}
+	}
+	if options[0] != "" {
+		return options[0], false
+	}
+	if len(field.Names) == 0 {
+		// Embedded fields without a JSON name are inlined.
+		return "", true
+	}
+	return field.Names[0].Name, false
+}
+
+// commentLines returns the trimmed text of the lines of a comment group.
+func commentLines(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var lines []string
+	for _, comment := range doc.List {
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(comment.Text, "//")))
+	}
+	return lines
+}
+
+// typeMarkers returns the lines of the comments documenting a type. As in the scaffolded types, markers
+// may also be declared in the comment group separated from the doc comment by a blank line.
+func typeMarkers(fset *token.FileSet, f *ast.File, genDecl *ast.GenDecl, doc *ast.CommentGroup) []string {
+	start := genDecl.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+	lines := commentLines(doc)
+	for _, comments := range f.Comments {
+		if comments.End() >= start {
+			break
+		}
+		if fset.Position(start).Line-fset.Position(comments.End()).Line == 2 {
+			lines = append(commentLines(comments), lines...)
+		}
+	}
+	return lines
+}
+
+// hasMarker returns true if lines contain the given marker.
+func hasMarker(lines []string, marker string) bool {
+	for _, line := range lines {
+		if line == marker {
+			return true
+		}
+	}
+	return false
+}
+
+// markerArgument returns the value of the named argument of a marker, e.g. path in path=frigates,scope=Cluster.
+func markerArgument(arguments, name string) string {
+	for _, argument := range strings.Split(arguments, ",") {
+		if strings.HasPrefix(argument, name+"=") {
+			return strings.TrimPrefix(argument, name+"=")
+		}
+	}
+	return ""
+}
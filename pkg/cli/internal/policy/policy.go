@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// identifierRegex matches the field names that can be selected with a dot in Rego and CEL
+var identifierRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// message returns the description of the violation of rule, e.g. spec.replicas must be at least 1.
+func message(rule Rule) string {
+	var description string
+	switch rule.Marker {
+	case "Minimum":
+		description = "must be greater than or equal to " + rule.Value
+	case "Maximum":
+		description = "must be less than or equal to " + rule.Value
+	case "MinLength":
+		description = "must be at least " + rule.Value + " characters long"
+	case "MaxLength":
+		description = "must be at most " + rule.Value + " characters long"
+	case "MinItems":
+		description = "must have at least " + rule.Value + " items"
+	case "MaxItems":
+		description = "must have at most " + rule.Value + " items"
+	case "Pattern":
+		description = "must match " + rule.Value
+	case "Enum":
+		description = "must be one of " + strings.Join(enumValues(rule.Value), ", ")
+	}
+	return fmt.Sprintf("%s %s", rule.FieldPath(), description)
+}
+
+// literal returns the Rego or CEL literal of an enum value, which is a number or a string.
+func literal(value string) string {
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// literals returns the Rego or CEL literals of the values of an Enum marker.
+func literals(value string) string {
+	values := enumValues(value)
+	for i, v := range values {
+		values[i] = literal(v)
+	}
+	return strings.Join(values, ", ")
+}
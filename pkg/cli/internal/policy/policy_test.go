@@ -0,0 +1,200 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+)
+
+const typesFile = `// +groupName=ship.example.org
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:validation:Pattern=` + "`^[a-z-]+$`" + `
+type Name string
+
+type FrigateSpec struct {
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	Replicas *int32 ` + "`json:\"replicas,omitempty\"`" + `
+
+	// +kubebuilder:validation:Enum=Small;Large
+	Size string ` + "`json:\"size\"`" + `
+
+	// +kubebuilder:validation:MaxItems=3
+	Crew []CrewMember ` + "`json:\"crew-members,omitempty\"`" + `
+
+	// +kubebuilder:validation:Required
+	Captain string ` + "`json:\"captain\"`" + `
+}
+
+type CrewMember struct {
+	Name Name ` + "`json:\"name\"`" + `
+}
+
+type FrigateStatus struct {
+	// +kubebuilder:validation:Minimum=0
+	Ready int32 ` + "`json:\"ready\"`" + `
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=frigateships
+
+// Frigate is the Schema for the frigates API
+type Frigate struct {
+	metav1.TypeMeta   ` + "`json:\",inline\"`" + `
+	metav1.ObjectMeta ` + "`json:\"metadata,omitempty\"`" + `
+
+	Spec   FrigateSpec   ` + "`json:\"spec,omitempty\"`" + `
+	Status FrigateStatus ` + "`json:\"status,omitempty\"`" + `
+}
+
+// +kubebuilder:object:root=true
+
+// FrigateList contains a list of Frigate
+type FrigateList struct {
+	metav1.TypeMeta ` + "`json:\",inline\"`" + `
+	metav1.ListMeta ` + "`json:\"metadata,omitempty\"`" + `
+	Items           []Frigate ` + "`json:\"items\"`" + `
+}
+`
+
+func loadTestKinds(t *testing.T) []Kind {
+	dir, err := ioutil.TempDir("", "kubebuilder-policy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+	if err := os.MkdirAll(filepath.Join(dir, "v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "v1", "frigate_types.go"), []byte(typesFile), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	kinds, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kinds
+}
+
+func TestLoad(t *testing.T) {
+	kinds := loadTestKinds(t)
+	if len(kinds) != 1 {
+		t.Fatalf("expected 1 kind, got %d", len(kinds))
+	}
+
+	kind := kinds[0]
+	if kind.Group != "ship.example.org" || kind.Version != "v1" || kind.Kind != "Frigate" || kind.Plural != "frigateships" {
+		t.Errorf("unexpected kind %s/%s, Kind=%s, Plural=%s", kind.Group, kind.Version, kind.Kind, kind.Plural)
+	}
+
+	expected := []Rule{
+		{Path: []string{"spec", "replicas"}, Marker: "Minimum", Value: "1"},
+		{Path: []string{"spec", "replicas"}, Marker: "Maximum", Value: "10"},
+		{Path: []string{"spec", "size"}, Marker: "Enum", Value: "Small;Large"},
+		{Path: []string{"spec", "crew-members"}, Marker: "MaxItems", Value: "3"},
+		{Path: []string{"spec", "crew-members", "[]", "name"}, Marker: "Pattern", Value: "^[a-z-]+$"},
+	}
+	if !reflect.DeepEqual(kind.Rules, expected) {
+		t.Errorf("expected rules %v, got %v", expected, kind.Rules)
+	}
+}
+
+func TestGatekeeper(t *testing.T) {
+	content, err := Gatekeeper(loadTestKinds(t)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"kind: ConstraintTemplate",
+		"name: frigatevalidation",
+		"value := input.review.object.spec.replicas\n",
+		"value < 1\n",
+		`not {"Small", "Large"}[value]`,
+		`value := input.review.object.spec["crew-members"][_].name`,
+		`not re_match("^[a-z-]+$", value)`,
+		"kind: FrigateValidation",
+		"- ship.example.org",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("expected the policy to contain %q, got:\n%s", expected, content)
+		}
+	}
+}
+
+func TestCEL(t *testing.T) {
+	content, err := CEL(loadTestKinds(t)[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	documents := strings.Split(string(content), "\n---\n")
+	if len(documents) != 2 {
+		t.Fatalf("expected a policy and its binding, got:\n%s", content)
+	}
+
+	var policy struct {
+		Kind string `json:"kind"`
+		Spec struct {
+			Validations []struct {
+				Expression string `json:"expression"`
+				Message    string `json:"message"`
+			} `json:"validations"`
+		} `json:"spec"`
+	}
+	if err := yaml.Unmarshal([]byte(documents[0]), &policy); err != nil {
+		t.Fatal(err)
+	}
+	if policy.Kind != "ValidatingAdmissionPolicy" {
+		t.Errorf("expected a ValidatingAdmissionPolicy, got %s", policy.Kind)
+	}
+
+	expected := []string{
+		"!has(object.spec) || !has(object.spec.replicas) || object.spec.replicas >= 1",
+		"!has(object.spec) || !has(object.spec.replicas) || object.spec.replicas <= 10",
+		`!has(object.spec) || !has(object.spec.size) || object.spec.size in ["Small", "Large"]`,
+		"!has(object.spec) || !has(object.spec.crew__dash__members) || size(object.spec.crew__dash__members) <= 3",
+		"!has(object.spec) || !has(object.spec.crew__dash__members) || " +
+			`object.spec.crew__dash__members.all(i0, !has(i0.name) || i0.name.matches("^[a-z-]+$"))`,
+	}
+	if len(policy.Spec.Validations) != len(expected) {
+		t.Fatalf("expected %d validations, got:\n%s", len(expected), content)
+	}
+	for i, validation := range policy.Spec.Validations {
+		if validation.Expression != expected[i] {
+			t.Errorf("expected expression %q, got %q", expected[i], validation.Expression)
+		}
+	}
+	if message := policy.Spec.Validations[4].Message; message != "spec.crew-members[].name must match ^[a-z-]+$" {
+		t.Errorf("unexpected message %q", message)
+	}
+
+	if !strings.Contains(documents[1], "kind: ValidatingAdmissionPolicyBinding") {
+		t.Errorf("expected a ValidatingAdmissionPolicyBinding, got:\n%s", documents[1])
+	}
+}
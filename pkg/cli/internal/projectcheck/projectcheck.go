@@ -0,0 +1,377 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package projectcheck checks that the PROJECT file is valid for its project version and that it is in sync
+// with the files of the project: that the files of its resources exist, and that the APIs, webhooks and CRDs
+// found in the project are recorded in it.
+package projectcheck
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/crdlint"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/storageversion"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/validation"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+// Problem is an issue found in the project.
+type Problem struct {
+	// Subject is the field of the PROJECT file, the resource or the file the problem is about.
+	Subject string
+	// Severity is the severity of the problem: errors are invalid PROJECT files and missing files,
+	// warnings are drifts between the PROJECT file and the project.
+	Severity crdlint.Severity
+	// Message describes the problem and how to fix it.
+	Message string
+}
+
+// String implements fmt.Stringer
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Severity, p.Subject, p.Message)
+}
+
+// Check checks the PROJECT file at path, whose project is rooted in its directory: its fields against its
+// project version, its layout and plugin configurations against the plugins known by the CLI, the files
+// of its resources and the CRDs of crdDir. The CRDs are only checked if crdDir exists.
+func Check(path string, plugins []plugin.Plugin, crdDir string) ([]Problem, error) {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config.Config
+	if err := cfg.Unmarshal(content); err != nil {
+		return []Problem{{Subject: path, Severity: crdlint.Error, Message: err.Error()}}, nil
+	}
+
+	problems := checkFields(cfg)
+	if cfg.Version != config.Version2 && !cfg.IsV3() {
+		// The other checks depend on the schema of the project version
+		return problems, nil
+	}
+	problems = append(problems, checkPlugins(cfg, plugins)...)
+
+	root := filepath.Dir(path)
+	fileProblems, err := checkFiles(cfg, root)
+	if err != nil {
+		return nil, err
+	}
+	problems = append(problems, fileProblems...)
+
+	if _, err := os.Stat(crdDir); err == nil {
+		crdProblems, err := checkCRDs(cfg, crdDir)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, crdProblems...)
+	}
+	return problems, nil
+}
+
+// checkFields checks the fields of the PROJECT file against the schema of its project version.
+func checkFields(cfg config.Config) []Problem {
+	var problems []Problem
+	report := func(subject, format string, args ...interface{}) {
+		problems = append(problems, Problem{Subject: subject, Severity: crdlint.Error,
+			Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch {
+	case cfg.Version == "":
+		report("version", "the project version is required")
+		return problems
+	case cfg.Version != config.Version2 && !cfg.IsV3():
+		report("version", "unknown project version %q, the supported versions are %q and %q",
+			cfg.Version, config.Version2, config.Version3Alpha)
+		return problems
+	}
+
+	if cfg.Domain == "" {
+		report("domain", "the domain is required")
+	} else if errs := validation.IsDNS1123Subdomain(cfg.Domain); len(errs) != 0 {
+		report("domain", "invalid domain %q: %s", cfg.Domain, strings.Join(errs, ", "))
+	}
+	if cfg.Repo == "" && !cfg.ManifestsOnly {
+		report("repo", "the Go module of the project is required")
+	}
+
+	if cfg.IsV2() {
+		if cfg.Layout != "" {
+			report("layout", "the layout is not supported by project version %q", cfg.Version)
+		}
+	} else {
+		if cfg.Layout == "" {
+			report("layout", "the layout is required by project version %q", cfg.Version)
+		}
+		if cfg.ProjectName == "" {
+			report("projectName", "the project name is required by project version %q", cfg.Version)
+		} else if errs := validation.IsDNS1123Label(cfg.ProjectName); len(errs) != 0 {
+			report("projectName", "invalid project name %q: %s", cfg.ProjectName, strings.Join(errs, ", "))
+		}
+		if cfg.Namespace != "" {
+			if errs := validation.IsDNS1123Label(cfg.Namespace); len(errs) != 0 {
+				report("namespace", "invalid namespace %q: %s", cfg.Namespace, strings.Join(errs, ", "))
+			}
+		}
+		if cfg.GroupRegistration && !cfg.MultiGroup {
+			report("groupRegistration", "the registration packages of the groups require the multigroup layout")
+		}
+	}
+
+	seen := make(map[string]bool, len(cfg.Resources))
+	for _, res := range cfg.Resources {
+		subject := resourceName(res)
+		if seen[subject] {
+			report(subject, "the resource is listed more than once")
+			continue
+		}
+		seen[subject] = true
+
+		opts := resource.Options{Group: res.Group, GroupPackage: res.GroupPackage, Version: res.Version, Kind: res.Kind}
+		if res.API != nil {
+			opts.API = *res.API
+		}
+		if res.Webhooks != nil {
+			opts.Webhooks = *res.Webhooks
+		}
+		validate := opts.Validate
+		if cfg.IsV2() {
+			validate = opts.ValidateV2
+		}
+		if err := validate(); err != nil {
+			report(subject, "invalid resource: %v", err)
+		}
+		if res.API != nil && res.API.Deprecation != nil {
+			deprecation := res.API.Deprecation
+			if _, err := time.Parse(config.DeprecationDateLayout, deprecation.RemoveAfter); err != nil {
+				report(subject, "invalid removal date %q of the deprecated version, expected a date such as %s",
+					deprecation.RemoveAfter, config.DeprecationDateLayout)
+			}
+			if !hasOtherVersion(cfg, res, deprecation.Replacement) {
+				report(subject, "the replacement %q of the deprecated version is not another version of the kind",
+					deprecation.Replacement)
+			}
+		}
+	}
+	return problems
+}
+
+// hasOtherVersion returns true if version is another version of the kind of res with an API.
+func hasOtherVersion(cfg config.Config, res config.ResourceData, version string) bool {
+	for _, other := range cfg.Resources {
+		if other.Group == res.Group && other.Kind == res.Kind && other.Version == version &&
+			version != res.Version && other.API != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPlugins checks that the plugins of the layout, and the ones whose configuration is stored in the
+// PROJECT file, are known by the CLI and support the project version.
+func checkPlugins(cfg config.Config, plugins []plugin.Plugin) []Problem {
+	var problems []Problem
+	check := func(subject, key string) {
+		if err := plugin.ValidateKey(key); err != nil {
+			problems = append(problems, Problem{Subject: subject, Severity: crdlint.Error, Message: err.Error()})
+			return
+		}
+		if !IsKnownPlugin(key, cfg.Version, plugins) {
+			problems = append(problems, Problem{Subject: subject, Severity: crdlint.Error, Message: fmt.Sprintf(
+				"no plugin supporting project version %q is known for the key %q", cfg.Version, key)})
+		}
+	}
+
+	if cfg.Layout != "" {
+		for _, key := range strings.Split(cfg.Layout, ",") {
+			check("layout", strings.TrimSpace(key))
+		}
+	}
+	keys := make([]string, 0, len(cfg.Plugins))
+	for key := range cfg.Plugins {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		check("plugins", key)
+	}
+	return problems
+}
+
+// IsKnownPlugin returns true if one of plugins matches key, by name or short name and by version if
+// provided, and supports projectVersion.
+func IsKnownPlugin(key, projectVersion string, plugins []plugin.Plugin) bool {
+	name, version := plugin.SplitKey(key)
+	for _, p := range plugins {
+		if p.Name() != name && plugin.GetShortName(p.Name()) != name {
+			continue
+		}
+		if version != "" && p.Version().String() != version {
+			continue
+		}
+		if plugin.SupportsVersion(p, projectVersion) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFiles checks that the Go files and the samples of the resources exist, and reports the types and
+// webhooks of the API directories that are not recorded in the PROJECT file.
+func checkFiles(cfg config.Config, root string) ([]Problem, error) {
+	// The Go code of the manifests-only projects lives in another repository
+	if cfg.ManifestsOnly {
+		return nil, nil
+	}
+
+	var problems []Problem
+	missing := func(subject, path string, severity crdlint.Severity, what string) {
+		if _, err := os.Stat(filepath.Join(root, path)); os.IsNotExist(err) {
+			problems = append(problems, Problem{Subject: subject, Severity: severity,
+				Message: fmt.Sprintf("%s %s not found", what, filepath.ToSlash(path))})
+		}
+	}
+
+	expected := make(map[string]bool)
+	for _, res := range cfg.Resources {
+		subject := resourceName(res)
+		dir := APIDir(cfg, res)
+		kind := strings.ToLower(res.Kind)
+
+		// Version 2 does not record whether the types were scaffolded, they are in every resource
+		if res.API != nil || cfg.IsV2() {
+			types := filepath.Join(dir, kind+"_types.go")
+			expected[types] = true
+			missing(subject, types, crdlint.Error, "the types")
+			missing(subject, filepath.Join("config", "samples", fmt.Sprintf("%s_%s_%s.yaml", res.Group, res.Version, kind)),
+				crdlint.Warning, "the sample")
+		}
+		if webhooks := res.Webhooks; webhooks != nil {
+			if webhooks.Defaulting || webhooks.Validation || webhooks.Conversion || webhooks.IsEmpty() {
+				webhook := filepath.Join(dir, kind+"_webhook.go")
+				expected[webhook] = true
+				missing(subject, webhook, crdlint.Error, "the webhook")
+			}
+			if webhooks.OwnerLabels {
+				webhook := filepath.Join(dir, kind+"_owner_labels_webhook.go")
+				expected[webhook] = true
+				missing(subject, webhook, crdlint.Error, "the owner labels webhook")
+			}
+			for _, subresource := range webhooks.Subresources {
+				webhook := filepath.Join(dir, kind+"_"+subresource+"_webhook.go")
+				expected[webhook] = true
+				missing(subject, webhook, crdlint.Error, "the "+subresource+" webhook")
+			}
+		}
+	}
+
+	for _, dir := range []string{"api", "apis"} {
+		err := filepath.Walk(filepath.Join(root, dir), func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			switch {
+			case expected[rel]:
+			case strings.HasSuffix(rel, "_types.go"):
+				problems = append(problems, Problem{Subject: filepath.ToSlash(rel), Severity: crdlint.Warning,
+					Message: "the types are not recorded in the PROJECT file"})
+			// Version 2 does not record the webhooks
+			case strings.HasSuffix(rel, "_webhook.go") && !cfg.IsV2():
+				problems = append(problems, Problem{Subject: filepath.ToSlash(rel), Severity: crdlint.Warning,
+					Message: "the webhook is not recorded in the PROJECT file"})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return problems, nil
+}
+
+// checkCRDs reports the resources whose CRD or version was not generated in crdDir, and the CRDs of
+// crdDir whose kind is not recorded in the PROJECT file.
+func checkCRDs(cfg config.Config, crdDir string) ([]Problem, error) {
+	_, crdProblems, err := storageversion.Load(cfg, crdDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the CRDs: %v", err)
+	}
+	problems := make([]Problem, 0, len(crdProblems))
+	for _, problem := range crdProblems {
+		problems = append(problems, Problem{Subject: problem.CRD, Severity: problem.Severity, Message: problem.Message})
+	}
+
+	crds, err := storageversion.ReadCRDs(crdDir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the CRDs: %v", err)
+	}
+	tracked := make(map[string]bool, len(cfg.Resources))
+	for _, res := range cfg.Resources {
+		group := cfg.Domain
+		if res.Group != "" {
+			group = res.Group + "." + cfg.Domain
+		}
+		tracked[res.Kind+"."+group] = true
+	}
+	keys := make([]string, 0, len(crds))
+	for key := range crds {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !tracked[key] {
+			problems = append(problems, Problem{Subject: crds[key].CRD, Severity: crdlint.Warning, Message: fmt.Sprintf(
+				"the CRD of %s is not recorded in the PROJECT file", key)})
+		}
+	}
+	return problems, nil
+}
+
+// APIDir returns the directory of the API package of res.
+func APIDir(cfg config.Config, res config.ResourceData) string {
+	if !cfg.MultiGroup {
+		return filepath.Join("api", res.Version)
+	}
+	if res.Group == "" {
+		return filepath.Join("apis", res.Version)
+	}
+	group := res.Group
+	if res.GroupPackage != "" {
+		group = res.GroupPackage
+	}
+	return filepath.Join("apis", group, res.Version)
+}
+
+// resourceName returns the name of res used as subject of its problems, e.g. ship/v1, Kind=Frigate.
+func resourceName(res config.ResourceData) string {
+	return fmt.Sprintf("%s/%s, Kind=%s", res.Group, res.Version, res.Kind)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projectcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+type fakePlugin struct{}
+
+func (fakePlugin) Name() string                       { return "go.kubebuilder.io" }
+func (fakePlugin) Version() plugin.Version            { return plugin.Version{Number: 3} }
+func (fakePlugin) SupportedProjectVersions() []string { return []string{"3-alpha"} }
+
+const project = `domain: example.org
+layout: go.kubebuilder.io/v3
+projectName: ship
+repo: example.org/ship
+resources:
+- api:
+    crdVersion: v1
+  group: crew
+  kind: Captain
+  version: v1
+  webhooks:
+    defaulting: true
+    webhookVersion: v1
+version: 3-alpha
+`
+
+const crd = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: PLURAL.crew.example.org
+spec:
+  group: crew.example.org
+  names:
+    kind: KIND
+  versions:
+  - name: v1
+`
+
+func crdFor(kind, plural string) string {
+	return strings.NewReplacer("KIND", kind, "PLURAL", plural).Replace(crd)
+}
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func check(t *testing.T, files map[string]string) []string {
+	root, err := ioutil.TempDir("", "projectcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	writeFiles(t, root, files)
+	problems, err := Check(filepath.Join(root, "PROJECT"), []plugin.Plugin{fakePlugin{}},
+		filepath.Join(root, "config", "crd", "bases"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+	return messages
+}
+
+func TestCheckValid(t *testing.T) {
+	problems := check(t, map[string]string{
+		"PROJECT":                                         project,
+		"api/v1/captain_types.go":                         "package v1\n",
+		"api/v1/captain_webhook.go":                       "package v1\n",
+		"api/v1/groupversion_info.go":                     "package v1\n",
+		"config/samples/crew_v1_captain.yaml":             "kind: Captain\n",
+		"config/crd/bases/crew.example.org_captains.yaml": crdFor("Captain", "captains"),
+	})
+	if len(problems) != 0 {
+		t.Errorf("expected no problem, got %v", problems)
+	}
+}
+
+func TestCheckFields(t *testing.T) {
+	content := strings.Replace(project, "domain: example.org", "domain: Example_Org", 1)
+	content = strings.Replace(content, "layout: go.kubebuilder.io/v3", "layout: go.kubebuilder.io/v2", 1)
+	content = strings.Replace(content, "kind: Captain", "kind: captain", 1)
+	content = strings.Replace(content, "    crdVersion: v1\n",
+		"    crdVersion: v1\n    deprecation:\n      removeAfter: soon\n      replacement: v2\n", 1)
+	content += "groupRegistration: true\nnamespace: Ship_System\nplugins:\n  unknown.example.org/v1: {}\n"
+
+	problems := strings.Join(check(t, map[string]string{"PROJECT": content}), "\n")
+	for _, expected := range []string{
+		`error: domain: invalid domain "Example_Org"`,
+		`error: namespace: invalid namespace "Ship_System"`,
+		`error: groupRegistration: the registration packages of the groups require the multigroup layout`,
+		`error: layout: no plugin supporting project version "3-alpha" is known for the key "go.kubebuilder.io/v2"`,
+		`error: plugins: no plugin supporting project version "3-alpha" is known for the key "unknown.example.org/v1"`,
+		`error: crew/v1, Kind=captain: invalid resource: invalid Kind`,
+		`error: crew/v1, Kind=captain: invalid removal date "soon" of the deprecated version`,
+		`error: crew/v1, Kind=captain: the replacement "v2" of the deprecated version is not another version`,
+	} {
+		if !strings.Contains(problems, expected) {
+			t.Errorf("expected the problem %q, got:\n%s", expected, problems)
+		}
+	}
+
+	problems = strings.Join(check(t, map[string]string{"PROJECT": "version: \"4\"\n"}), "\n")
+	if expected := `error: version: unknown project version "4"`; !strings.HasPrefix(problems, expected) {
+		t.Errorf("expected the problem %q, got:\n%s", expected, problems)
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	problems := check(t, map[string]string{
+		"PROJECT":                   project,
+		"api/v1/captain_types.go":   "package v1\n",
+		"api/v1/firstmate_types.go": "package v1\n",
+		"config/crd/bases/crew.example.org_firstmates.yaml": crdFor("FirstMate", "firstmates"),
+	})
+	expected := []string{
+		"warning: crew/v1, Kind=Captain: the sample config/samples/crew_v1_captain.yaml not found",
+		"error: crew/v1, Kind=Captain: the webhook api/v1/captain_webhook.go not found",
+		"warning: api/v1/firstmate_types.go: the types are not recorded in the PROJECT file",
+		`warning: Captain.crew.example.org: no CRD found in`,
+		"warning: firstmates.crew.example.org: the CRD of FirstMate.crew.example.org is not recorded in the PROJECT file",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got:\n%s", len(expected), strings.Join(problems, "\n"))
+	}
+	for i := range expected {
+		if !strings.HasPrefix(problems[i], expected[i]) {
+			t.Errorf("expected the problem %q, got %q", expected[i], problems[i])
+		}
+	}
+}
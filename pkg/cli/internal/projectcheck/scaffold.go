@@ -0,0 +1,275 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projectcheck
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/crdlint"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+const scaffoldMarkerPrefix = "+kubebuilder:scaffold:"
+
+// scaffoldedFile is a Go file in which the kubebuilder CLI inserts code at the scaffold markers.
+type scaffoldedFile struct {
+	// pattern is the path of the file, relative to the root of the project, or a glob matching them.
+	pattern string
+	// markers are the values of the scaffold markers of the file, e.g. imports.
+	markers []string
+	// optional are the values of the scaffold markers only scaffolded by some resources, e.g. cache.
+	optional []string
+}
+
+// scaffoldedFiles are the files wired by "create api" and "create webhook". The test suites only exist once
+// a controller or a webhook was scaffolded, and the registration packages of the groups once enabled.
+var scaffoldedFiles = []scaffoldedFile{
+	{pattern: "main.go", markers: []string{"imports", "scheme", "builder"}, optional: []string{"cache"}},
+	{pattern: filepath.Join("controllers", "suite_test.go"), markers: []string{"imports", "scheme"}},
+	{pattern: filepath.Join("controllers", "*", "suite_test.go"), markers: []string{"imports", "scheme"}},
+	{pattern: filepath.Join("api*", "*", "webhook_suite_test.go"), markers: []string{"imports", "scheme", "webhook"}},
+	{pattern: filepath.Join("apis", "*", "*", "webhook_suite_test.go"),
+		markers: []string{"imports", "scheme", "webhook"}},
+	{pattern: filepath.Join("apis", "*", "register.go"), markers: []string{"imports", "versions"}},
+}
+
+// CheckScaffold checks the wiring of the project rooted in the directory of the PROJECT file at path: that the
+// scaffold markers of main.go and of the test suites exist and are well-formed, and that the types and
+// webhooks of the resources of the PROJECT file are wired in main.go. Without their markers, "create api" and
+// "create webhook" silently stop wiring the new resources.
+func CheckScaffold(path string) ([]Problem, error) {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	var cfg config.Config
+	if err := cfg.Unmarshal(content); err != nil {
+		return []Problem{{Subject: path, Severity: crdlint.Error, Message: err.Error()}}, nil
+	}
+	// The Go code of the manifests-only projects lives in another repository, and the resources of the
+	// aggregated API servers are not wired in main.go
+	if cfg.ManifestsOnly || cfg.Pattern != "" {
+		return nil, nil
+	}
+
+	root := filepath.Dir(path)
+	var problems []Problem
+	for _, scaffolded := range scaffoldedFiles {
+		paths, err := filepath.Glob(filepath.Join(root, scaffolded.pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			content, err := ioutil.ReadFile(p) //nolint:gosec
+			if err != nil {
+				return nil, err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return nil, err
+			}
+			markers, optional := scaffolded.markers, scaffolded.optional
+			// The code of the new resources is inserted at the positions found in the syntax tree of main.go
+			if scaffolded.pattern == "main.go" && cfg.Wiring == config.WiringAST {
+				markers, optional = nil, append(append([]string(nil), markers...), optional...)
+			}
+			problems = append(problems, checkMarkers(filepath.ToSlash(rel), string(content), markers, optional)...)
+		}
+	}
+
+	mainProblems, err := checkMainWiring(cfg, filepath.Join(root, "main.go"))
+	if err != nil {
+		return nil, err
+	}
+	return append(problems, mainProblems...), nil
+}
+
+// checkMarkers checks that each of the markers is found once in the content of the file at path, and the
+// optional ones at most once, and reports the scaffold markers that are not recognized, e.g. because they are
+// not on a line of their own.
+func checkMarkers(path, content string, markers, optional []string) []Problem {
+	var problems []Problem
+	all := append(append(make([]string, 0, len(markers)+len(optional)), markers...), optional...)
+	found := make(map[string]int, len(all))
+	for i, line := range strings.Split(content, "\n") {
+		if !strings.Contains(line, "kubebuilder:scaffold:") {
+			continue
+		}
+		value := strings.TrimSpace(line)
+		if strings.HasPrefix(value, "//") {
+			value = strings.TrimSpace(strings.TrimPrefix(value, "//"))
+		}
+		known := false
+		for _, marker := range all {
+			if value == scaffoldMarkerPrefix+marker {
+				found[marker]++
+				known = true
+			}
+		}
+		if !known {
+			problems = append(problems, Problem{Subject: fmt.Sprintf("%s:%d", path, i+1), Severity: crdlint.Error,
+				Message: fmt.Sprintf("malformed scaffold marker %q, expected a line comment such as //%s%s",
+					strings.TrimSpace(line), scaffoldMarkerPrefix, all[0])})
+		}
+	}
+	for _, marker := range all {
+		switch found[marker] {
+		case 0:
+			if !isOptional(marker, optional) {
+				problems = append(problems, Problem{Subject: path, Severity: crdlint.Error,
+					Message: fmt.Sprintf("the //%s%s marker was not found, restore it where the kubebuilder CLI "+
+						"should insert the code of the new resources", scaffoldMarkerPrefix, marker)})
+			}
+		case 1:
+		default:
+			problems = append(problems, Problem{Subject: path, Severity: crdlint.Warning,
+				Message: fmt.Sprintf("the //%s%s marker was found %d times, the code of the new resources "+
+					"is inserted at each of them", scaffoldMarkerPrefix, marker, found[marker])})
+		}
+	}
+	return problems
+}
+
+// isOptional returns true if marker is one of the optional markers.
+func isOptional(marker string, optional []string) bool {
+	for _, value := range optional {
+		if marker == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMainWiring checks that the types of the resources of cfg are added to the scheme of the manager by
+// the main.go file at path, and that their webhooks are set up with it.
+func checkMainWiring(cfg config.Config, path string) ([]Problem, error) {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if os.IsNotExist(err) {
+		return []Problem{{Subject: "main.go", Severity: crdlint.Error, Message: "main.go not found"}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), path, content, parser.ImportsOnly)
+	if err != nil {
+		return []Problem{{Subject: "main.go", Severity: crdlint.Error, Message: err.Error()}}, nil
+	}
+	aliases := make(map[string]string, len(f.Imports))
+	for _, spec := range f.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		if spec.Name != nil {
+			aliases[importPath] = spec.Name.Name
+		} else {
+			aliases[importPath] = importPath[strings.LastIndex(importPath, "/")+1:]
+		}
+	}
+
+	var problems []Problem
+	wired := func(res config.ResourceData, fragment, what string) {
+		if !strings.Contains(string(content), fragment) {
+			problems = append(problems, Problem{Subject: resourceName(res), Severity: crdlint.Warning,
+				Message: fmt.Sprintf("%s not found in main.go, expected %s", what, fragment)})
+		}
+	}
+	for _, res := range cfg.Resources {
+		hasWebhooks := res.Webhooks != nil && !res.Webhooks.IsEmpty()
+		if res.API == nil && !hasWebhooks {
+			continue
+		}
+		pkg := cfg.Repo + "/" + filepath.ToSlash(APIDir(cfg, res))
+
+		// The registration package of the group adds the version to the scheme, main.go only imports the
+		// version to set up its webhooks
+		groupRegistration := cfg.GroupRegistration && cfg.MultiGroup && res.Group != ""
+		if res.API != nil && groupRegistration {
+			groupPkg := cfg.Repo + "/" + filepath.ToSlash(filepath.Dir(APIDir(cfg, res)))
+			if groupAlias, imported := aliases[groupPkg]; imported {
+				wired(res, groupAlias+".AddToScheme(scheme)", "the registration of the group in the scheme")
+			} else {
+				problems = append(problems, Problem{Subject: resourceName(res), Severity: crdlint.Warning,
+					Message: fmt.Sprintf("the registration package %s of the group is not imported by main.go",
+						groupPkg)})
+			}
+			groupProblems, err := checkGroupRegistration(cfg, res, filepath.Dir(path))
+			if err != nil {
+				return nil, err
+			}
+			problems = append(problems, groupProblems...)
+		}
+		if groupRegistration && !hasWebhooks {
+			continue
+		}
+
+		alias, imported := aliases[pkg]
+		if !imported {
+			problems = append(problems, Problem{Subject: resourceName(res), Severity: crdlint.Warning,
+				Message: fmt.Sprintf("the package %s of the resource is not imported by main.go", pkg)})
+			continue
+		}
+
+		if res.API != nil && !groupRegistration {
+			wired(res, alias+".AddToScheme(scheme)", "the registration of the types in the scheme")
+		}
+		if !hasWebhooks {
+			continue
+		}
+		webhooks := res.Webhooks
+		if webhooks.Defaulting || webhooks.Validation || webhooks.Conversion {
+			wired(res, fmt.Sprintf("(&%s.%s{}).SetupWebhookWithManager(mgr)", alias, res.Kind), "the setup of the webhook")
+		}
+		if webhooks.OwnerLabels {
+			wired(res, fmt.Sprintf("(&%s.%s{}).SetupOwnerLabelsWebhookWithManager(mgr)", alias, res.Kind),
+				"the setup of the owner labels webhook")
+		}
+		for _, subresource := range webhooks.Subresources {
+			wired(res, fmt.Sprintf("(&%s.%s{}).Setup%sWebhookWithManager(mgr)", alias, res.Kind,
+				strings.Title(subresource)), "the setup of the "+subresource+" webhook")
+		}
+	}
+	return problems, nil
+}
+
+// checkGroupRegistration checks that the registration package of the group of res, in the project rooted in
+// root, adds the version of res to the scheme.
+func checkGroupRegistration(cfg config.Config, res config.ResourceData, root string) ([]Problem, error) {
+	register := filepath.Join(filepath.Dir(APIDir(cfg, res)), "register.go")
+	content, err := ioutil.ReadFile(filepath.Join(root, register)) //nolint:gosec
+	if os.IsNotExist(err) {
+		return []Problem{{Subject: resourceName(res), Severity: crdlint.Warning,
+			Message: fmt.Sprintf("%s not found, the group is not added to the scheme", filepath.ToSlash(register))}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pkg := cfg.Repo + "/" + filepath.ToSlash(APIDir(cfg, res))
+	if !strings.Contains(string(content), strconv.Quote(pkg)) {
+		return []Problem{{Subject: resourceName(res), Severity: crdlint.Warning,
+			Message: fmt.Sprintf("the package %s of the resource is not imported by %s", pkg,
+				filepath.ToSlash(register))}}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projectcheck
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const wiredMain = `package main
+
+import (
+	crewv1 "example.org/ship/api/v1"
+	//+kubebuilder:scaffold:imports
+)
+
+func init() {
+	utilruntime.Must(crewv1.AddToScheme(scheme))
+	//+kubebuilder:scaffold:scheme
+}
+
+func main() {
+	if err = (&crewv1.Captain{}).SetupWebhookWithManager(mgr); err != nil {
+		os.Exit(1)
+	}
+	// +kubebuilder:scaffold:builder
+}
+`
+
+const suite = `package controllers
+
+import (
+	//+kubebuilder:scaffold:imports
+)
+
+func init() {
+	//+kubebuilder:scaffold:scheme
+}
+`
+
+func checkScaffold(t *testing.T, files map[string]string) []string {
+	root, err := ioutil.TempDir("", "scaffoldcheck")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	writeFiles(t, root, files)
+	problems, err := CheckScaffold(filepath.Join(root, "PROJECT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make([]string, 0, len(problems))
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+	return messages
+}
+
+func TestCheckScaffoldValid(t *testing.T) {
+	problems := checkScaffold(t, map[string]string{
+		"PROJECT":                   project,
+		"main.go":                   wiredMain,
+		"controllers/suite_test.go": suite,
+	})
+	if len(problems) != 0 {
+		t.Errorf("expected no problem, got %v", problems)
+	}
+
+	// The cache marker is only scaffolded with the cache selectors
+	problems = checkScaffold(t, map[string]string{
+		"PROJECT": project,
+		"main.go": wiredMain + "\nvar cacheSelectors = cacheselector.Selectors{\n\t//+kubebuilder:scaffold:cache\n}\n",
+	})
+	if len(problems) != 0 {
+		t.Errorf("expected no problem, got %v", problems)
+	}
+}
+
+func TestCheckScaffoldMarkers(t *testing.T) {
+	content := strings.Replace(wiredMain, "\t//+kubebuilder:scaffold:scheme\n", "", 1)
+	content = strings.Replace(content, "//+kubebuilder:scaffold:imports", "//+kubebuilder:scaffold:imports )", 1)
+	content = strings.Replace(content, "utilruntime.Must(crewv1.AddToScheme(scheme))", "", 1)
+	problems := checkScaffold(t, map[string]string{
+		"PROJECT":                   project,
+		"main.go":                   content,
+		"controllers/suite_test.go": suite + "//+kubebuilder:scaffold:scheme\n",
+	})
+	expected := []string{
+		`error: main.go:5: malformed scaffold marker "//+kubebuilder:scaffold:imports )"`,
+		"error: main.go: the //+kubebuilder:scaffold:imports marker was not found",
+		"error: main.go: the //+kubebuilder:scaffold:scheme marker was not found",
+		"warning: controllers/suite_test.go: the //+kubebuilder:scaffold:scheme marker was found 2 times",
+		"warning: crew/v1, Kind=Captain: the registration of the types in the scheme not found in main.go",
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got:\n%s", len(expected), strings.Join(problems, "\n"))
+	}
+	for i := range expected {
+		if !strings.HasPrefix(problems[i], expected[i]) {
+			t.Errorf("expected the problem %q, got %q", expected[i], problems[i])
+		}
+	}
+}
+
+func TestCheckScaffoldASTWiring(t *testing.T) {
+	// The markers of main.go are not needed, the ones of the test suites still are
+	content := strings.Replace(wiredMain, "\t//+kubebuilder:scaffold:scheme\n", "", 1)
+	problems := checkScaffold(t, map[string]string{
+		"PROJECT":                   project + "wiring: ast\n",
+		"main.go":                   strings.Replace(content, "\t// +kubebuilder:scaffold:builder\n", "", 1),
+		"controllers/suite_test.go": strings.Replace(suite, "\t//+kubebuilder:scaffold:scheme\n", "", 1),
+	})
+	expected := "error: controllers/suite_test.go: the //+kubebuilder:scaffold:scheme marker was not found"
+	if len(problems) != 1 || !strings.HasPrefix(problems[0], expected) {
+		t.Errorf("expected the problem %q, got:\n%s", expected, strings.Join(problems, "\n"))
+	}
+}
+
+func TestCheckScaffoldWebhooks(t *testing.T) {
+	content := strings.Replace(project, "    defaulting: true\n", "    defaulting: true\n    ownerLabels: true\n", 1)
+	problems := strings.Join(checkScaffold(t, map[string]string{
+		"PROJECT": content,
+		"main.go": wiredMain,
+	}), "\n")
+	if expected := "warning: crew/v1, Kind=Captain: the setup of the owner labels webhook not found in main.go, " +
+		"expected (&crewv1.Captain{}).SetupOwnerLabelsWebhookWithManager(mgr)"; problems != expected {
+		t.Errorf("expected the problem %q, got:\n%s", expected, problems)
+	}
+}
+
+func TestCheckScaffoldGroupRegistration(t *testing.T) {
+	content := strings.Replace(project, "layout:", "groupRegistration: true\nlayout:", 1)
+	content = strings.Replace(content, "projectName:", "multigroup: true\nprojectName:", 1)
+	main := strings.Replace(wiredMain, `crewv1 "example.org/ship/api/v1"`,
+		"crew \"example.org/ship/apis/crew\"\n\tcrewv1 \"example.org/ship/apis/crew/v1\"", 1)
+	main = strings.Replace(main, "crewv1.AddToScheme(scheme)", "crew.AddToScheme(scheme)", 1)
+	register := `package crew
+
+import (
+	//+kubebuilder:scaffold:imports
+)
+
+var SchemeBuilder = runtime.NewSchemeBuilder(
+	//+kubebuilder:scaffold:versions
+)
+`
+
+	problems := strings.Join(checkScaffold(t, map[string]string{
+		"PROJECT":               content,
+		"main.go":               main,
+		"apis/crew/register.go": register,
+	}), "\n")
+	if expected := "warning: crew/v1, Kind=Captain: the package example.org/ship/apis/crew/v1 of the resource " +
+		"is not imported by apis/crew/register.go"; problems != expected {
+		t.Errorf("expected the problem %q, got:\n%s", expected, problems)
+	}
+
+	register = strings.Replace(register, "import (\n", "import (\n\tcrewv1 \"example.org/ship/apis/crew/v1\"\n", 1)
+	if problems := checkScaffold(t, map[string]string{
+		"PROJECT":               content,
+		"main.go":               main,
+		"apis/crew/register.go": register,
+	}); len(problems) != 0 {
+		t.Errorf("expected no problem, got %v", problems)
+	}
+}
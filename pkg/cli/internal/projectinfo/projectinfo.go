@@ -0,0 +1,222 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package projectinfo describes the resources and the plugins recorded in the PROJECT file, along with the
+// files and the CRDs they were scaffolded into.
+package projectinfo
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/projectcheck"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/storageversion"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+// API is a resource of the PROJECT file.
+type API struct {
+	// Group is the fully qualified API group of the resources with an API, and the group of the builtin or
+	// external resources reconciled by a controller of the project.
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Plural and Scope, Namespaced or Cluster, are read from the CRD, empty if it was not generated.
+	Plural string `json:"plural,omitempty"`
+	Scope  string `json:"scope,omitempty"`
+	// CRDVersion is the version of the CustomResourceDefinition API of the CRD, empty without API.
+	CRDVersion string `json:"crdVersion,omitempty"`
+	// Storage is true if the version is the storage version of the CRD.
+	Storage bool `json:"storage,omitempty"`
+	// Types, Sample and Controller are the paths of the files of the resource, empty if they do not exist.
+	Types      string `json:"types,omitempty"`
+	Sample     string `json:"sample,omitempty"`
+	Controller string `json:"controller,omitempty"`
+}
+
+// Webhook holds the webhooks of a resource of the PROJECT file.
+type Webhook struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Types are the types of the webhooks: defaulting, validation, conversion, owner-labels and the
+	// validation of the status or scale subresource, e.g. status-validation.
+	Types []string `json:"types"`
+	// WebhookVersion is the version of the {Mutating,Validating}WebhookConfiguration API.
+	WebhookVersion string `json:"webhookVersion,omitempty"`
+	// WasmPolicy is the directory of the wasm policy compiling the validation, if any.
+	WasmPolicy string `json:"wasmPolicy,omitempty"`
+	// Paths are the paths of the webhook files.
+	Paths []string `json:"paths"`
+}
+
+// Plugin is a plugin of the layout of the PROJECT file, or whose configuration is stored in it.
+type Plugin struct {
+	Key string `json:"key"`
+	// Layout is true if the plugin is in the layout, which scaffolds the project.
+	Layout bool `json:"layout"`
+	// Config is true if the PROJECT file stores the configuration of the plugin.
+	Config bool `json:"config"`
+	// Known is true if a plugin of the CLI supporting the project version matches the key.
+	Known bool `json:"known"`
+}
+
+// APIs returns the resources of the project rooted at root, in the order of the PROJECT file, along with
+// the CRDs found in crdDir.
+func APIs(cfg config.Config, root, crdDir string) ([]API, error) {
+	crds := map[string]storageversion.Kind{}
+	if _, err := os.Stat(crdDir); err == nil {
+		if crds, err = storageversion.ReadCRDs(crdDir); err != nil {
+			return nil, err
+		}
+	}
+
+	apis := make([]API, 0, len(cfg.Resources))
+	for _, res := range cfg.Resources {
+		kind := strings.ToLower(res.Kind)
+		api := API{Group: res.Group, Version: res.Version, Kind: res.Kind}
+		// Version 2 does not record whether the types were scaffolded, they are in every resource
+		if res.API != nil || cfg.IsV2() {
+			api.Group = qualifiedGroup(cfg, res)
+			if res.API != nil {
+				api.CRDVersion = res.API.CRDVersion
+			}
+			if crd, found := crds[res.Kind+"."+api.Group]; found {
+				api.Plural, api.Scope = crd.Plural, crd.Scope
+				api.Storage = crd.StorageVersion() == res.Version
+			}
+			api.Types = existing(root, filepath.Join(projectcheck.APIDir(cfg, res), kind+"_types.go"))
+			api.Sample = existing(root,
+				filepath.Join("config", "samples", res.Group+"_"+res.Version+"_"+kind+".yaml"))
+		} else if api.Group == "" {
+			api.Group = "core"
+		}
+		controller := filepath.Join("controllers", kind+"_controller.go")
+		if cfg.MultiGroup && res.Group != "" {
+			controller = filepath.Join("controllers", res.Group, kind+"_controller.go")
+		}
+		api.Controller = existing(root, controller)
+		apis = append(apis, api)
+	}
+	return apis, nil
+}
+
+// Webhooks returns the webhooks of the resources of the project rooted at root, in the order of the
+// PROJECT file.
+func Webhooks(cfg config.Config, root string) []Webhook {
+	var webhooks []Webhook
+	for _, res := range cfg.Resources {
+		if res.Webhooks == nil {
+			continue
+		}
+		dir := projectcheck.APIDir(cfg, res)
+		kind := strings.ToLower(res.Kind)
+		webhook := Webhook{
+			Group:          qualifiedGroup(cfg, res),
+			Version:        res.Version,
+			Kind:           res.Kind,
+			Types:          []string{},
+			WebhookVersion: res.Webhooks.WebhookVersion,
+			Paths:          []string{},
+		}
+		addType := func(enabled bool, webhookType string) {
+			if enabled {
+				webhook.Types = append(webhook.Types, webhookType)
+			}
+		}
+		addType(res.Webhooks.Defaulting, "defaulting")
+		addType(res.Webhooks.Validation, "validation")
+		addType(res.Webhooks.Conversion, "conversion")
+		addType(res.Webhooks.OwnerLabels, "owner-labels")
+		for _, subresource := range res.Webhooks.Subresources {
+			addType(true, subresource+"-validation")
+		}
+
+		addPath := func(path string) {
+			if path = existing(root, path); path != "" {
+				webhook.Paths = append(webhook.Paths, path)
+			}
+		}
+		if res.Webhooks.Defaulting || res.Webhooks.Validation || res.Webhooks.Conversion || res.Webhooks.IsEmpty() {
+			addPath(filepath.Join(dir, kind+"_webhook.go"))
+		}
+		if res.Webhooks.WasmPolicy {
+			addPath(filepath.Join(dir, kind+"_validation.go"))
+			name := strings.ToLower(res.Version + "-" + res.Kind)
+			if res.Group != "" {
+				name = strings.ToLower(res.Group) + "-" + name
+			}
+			webhook.WasmPolicy = existing(root, filepath.Join("policies", name))
+		}
+		if res.Webhooks.OwnerLabels {
+			addPath(filepath.Join(dir, kind+"_owner_labels_webhook.go"))
+		}
+		for _, subresource := range res.Webhooks.Subresources {
+			addPath(filepath.Join(dir, kind+"_"+subresource+"_webhook.go"))
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks
+}
+
+// Plugins returns the plugins of the layout of the PROJECT file followed by the other ones whose
+// configuration is stored in it, sorted by key.
+func Plugins(cfg config.Config, plugins []plugin.Plugin) []Plugin {
+	var result []Plugin
+	index := map[string]int{}
+	add := func(key string) *Plugin {
+		if i, found := index[key]; found {
+			return &result[i]
+		}
+		index[key] = len(result)
+		result = append(result, Plugin{Key: key, Known: projectcheck.IsKnownPlugin(key, cfg.Version, plugins)})
+		return &result[len(result)-1]
+	}
+
+	if cfg.Layout != "" {
+		for _, key := range strings.Split(cfg.Layout, ",") {
+			add(strings.TrimSpace(key)).Layout = true
+		}
+	}
+	keys := make([]string, 0, len(cfg.Plugins))
+	for key := range cfg.Plugins {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		add(key).Config = true
+	}
+	return result
+}
+
+// qualifiedGroup returns the fully qualified API group of res.
+func qualifiedGroup(cfg config.Config, res config.ResourceData) string {
+	if res.Group == "" {
+		return cfg.Domain
+	}
+	return res.Group + "." + cfg.Domain
+}
+
+// existing returns path, relative to root, if it exists.
+func existing(root, path string) string {
+	if _, err := os.Stat(filepath.Join(root, path)); err != nil {
+		return ""
+	}
+	return filepath.ToSlash(path)
+}
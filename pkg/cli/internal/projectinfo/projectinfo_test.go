@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projectinfo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+type fakePlugin struct{}
+
+func (fakePlugin) Name() string                       { return "go.kubebuilder.io" }
+func (fakePlugin) Version() plugin.Version            { return plugin.Version{Number: 3} }
+func (fakePlugin) SupportedProjectVersions() []string { return []string{"3-alpha"} }
+
+const project = `domain: example.org
+layout: go.kubebuilder.io/v3
+multigroup: true
+projectName: ship
+repo: example.org/ship
+resources:
+- api:
+    crdVersion: v1
+  group: crew
+  kind: Captain
+  version: v1
+  webhooks:
+    defaulting: true
+    validation: true
+    subresources:
+    - status
+    webhookVersion: v1
+- group: apps
+  kind: Deployment
+  version: v1
+version: 3-alpha
+plugins:
+  unknown.example.org/v1: {}
+`
+
+const crd = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: captains.crew.example.org
+spec:
+  group: crew.example.org
+  names:
+    kind: Captain
+    plural: captains
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+
+func newProject(t *testing.T) (config.Config, string) {
+	root, err := ioutil.TempDir("", "projectinfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(root) })
+
+	files := map[string]string{
+		"apis/crew/v1/captain_types.go":                   "package v1\n",
+		"apis/crew/v1/captain_webhook.go":                 "package v1\n",
+		"apis/crew/v1/captain_status_webhook.go":          "package v1\n",
+		"config/samples/crew_v1_captain.yaml":             "kind: Captain\n",
+		"config/crd/bases/crew.example.org_captains.yaml": crd,
+		"controllers/apps/deployment_controller.go":       "package apps\n",
+	}
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cfg config.Config
+	if err := cfg.Unmarshal([]byte(project)); err != nil {
+		t.Fatal(err)
+	}
+	return cfg, root
+}
+
+func TestAPIs(t *testing.T) {
+	cfg, root := newProject(t)
+	apis, err := APIs(cfg, root, filepath.Join(root, "config", "crd", "bases"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []API{
+		{Group: "crew.example.org", Version: "v1", Kind: "Captain", Plural: "captains", Scope: "Cluster",
+			CRDVersion: "v1", Storage: true, Types: "apis/crew/v1/captain_types.go",
+			Sample: "config/samples/crew_v1_captain.yaml"},
+		{Group: "apps", Version: "v1", Kind: "Deployment", Controller: "controllers/apps/deployment_controller.go"},
+	}
+	if !reflect.DeepEqual(apis, expected) {
+		t.Errorf("expected %+v, got %+v", expected, apis)
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	cfg, root := newProject(t)
+	expected := []Webhook{{
+		Group: "crew.example.org", Version: "v1", Kind: "Captain",
+		Types:          []string{"defaulting", "validation", "status-validation"},
+		WebhookVersion: "v1",
+		Paths:          []string{"apis/crew/v1/captain_webhook.go", "apis/crew/v1/captain_status_webhook.go"},
+	}}
+	if webhooks := Webhooks(cfg, root); !reflect.DeepEqual(webhooks, expected) {
+		t.Errorf("expected %+v, got %+v", expected, webhooks)
+	}
+}
+
+func TestPlugins(t *testing.T) {
+	cfg, _ := newProject(t)
+	expected := []Plugin{
+		{Key: "go.kubebuilder.io/v3", Layout: true, Known: true},
+		{Key: "unknown.example.org/v1", Config: true},
+	}
+	if plugins := Plugins(cfg, []plugin.Plugin{fakePlugin{}}); !reflect.DeepEqual(plugins, expected) {
+		t.Errorf("expected %+v, got %+v", expected, plugins)
+	}
+}
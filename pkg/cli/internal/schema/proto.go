@@ -0,0 +1,209 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/storageversion"
+)
+
+const (
+	protoStruct    = "google.protobuf.Struct"
+	protoListValue = "google.protobuf.ListValue"
+	protoValue     = "google.protobuf.Value"
+)
+
+var (
+	protoIdentifier  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	protoInvalidChar = regexp.MustCompile(`[^A-Za-z0-9_]`)
+)
+
+// protoMessage is a message describing an object of the schema. The objects nested in its fields are
+// declared as nested messages.
+type protoMessage struct {
+	name        string
+	description string
+	nested      []*protoMessage
+	fields      []protoField
+}
+
+type protoField struct {
+	description string
+	repeated    bool
+	typ         string
+	name        string
+	// jsonName is the name of the field in the JSON object, if it is not a valid identifier.
+	jsonName string
+}
+
+// protoFile returns the proto3 file declaring the message of version of kind. The messages describe the
+// JSON objects: the field numbers follow the alphabetical order of the fields and change with the schema.
+func protoFile(kind storageversion.Kind, version storageversion.Version) []byte {
+	root := newProtoMessage(kind.Kind, version.Schema)
+	var messages strings.Builder
+	root.render(&messages, "")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by kubebuilder from the %s CRD. DO NOT EDIT.\n", kind.CRD)
+	b.WriteString("// The messages describe the JSON objects: their field numbers follow the alphabetical order of the\n")
+	b.WriteString("// fields and are not kept when the schema changes, do not use them for the binary encoding.\n\n")
+	b.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&b, "package %s;\n\n", protoPackage(kind.Group, version.Name))
+	if strings.Contains(messages.String(), "google.protobuf.") {
+		b.WriteString("import \"google/protobuf/struct.proto\";\n\n")
+	}
+	b.WriteString(messages.String())
+	return []byte(b.String())
+}
+
+// protoPackage returns the package of the messages of version of group, such as example.org.v1.
+func protoPackage(group, version string) string {
+	segments := strings.Split(group+"."+version, ".")
+	for i, segment := range segments {
+		segments[i] = protoName(segment)
+	}
+	return strings.Join(segments, ".")
+}
+
+// protoName returns name with its characters that are not allowed in identifiers replaced.
+func protoName(name string) string {
+	name = protoInvalidChar.ReplaceAllString(name, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// protoMessageName returns the name of the message of the objects of the field named name.
+func protoMessageName(name string) string {
+	name = protoName(name)
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func newProtoMessage(name string, schema object) *protoMessage {
+	message := &protoMessage{name: name, description: stringField(schema, "description")}
+
+	properties := field(schema, "properties")
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property := toObject(properties[name])
+		typ, repeated := message.fieldType(protoMessageName(name), property)
+		f := protoField{
+			description: stringField(property, "description"),
+			repeated:    repeated,
+			typ:         typ,
+			name:        name,
+		}
+		if !protoIdentifier.MatchString(name) {
+			f.name, f.jsonName = protoName(name), name
+		}
+		message.fields = append(message.fields, f)
+	}
+	return message
+}
+
+// fieldType returns the type of a field of schema, declaring the nested message named messageName if the
+// field holds objects with known fields.
+func (m *protoMessage) fieldType(messageName string, schema object) (typ string, repeated bool) {
+	if intOrString, _ := schema["x-kubernetes-int-or-string"].(bool); intOrString {
+		return protoValue, false
+	}
+
+	switch stringField(schema, "type") {
+	case "string":
+		return "string", false
+	case "boolean":
+		return "bool", false
+	case "integer":
+		if stringField(schema, "format") == "int32" {
+			return "int32", false
+		}
+		return "int64", false
+	case "number":
+		return "double", false
+	case "array":
+		typ, repeated := m.fieldType(messageName+"Item", field(schema, "items"))
+		// Lists of lists and of maps can not be declared as repeated fields
+		if repeated || strings.HasPrefix(typ, "map<") {
+			return protoListValue, false
+		}
+		return typ, true
+	case "object":
+		if len(field(schema, "properties")) != 0 {
+			m.nested = append(m.nested, newProtoMessage(messageName, schema))
+			return messageName, false
+		}
+		if valueSchema := field(schema, "additionalProperties"); valueSchema != nil {
+			typ, repeated := m.fieldType(messageName+"Value", valueSchema)
+			// Maps of lists and of maps can not be declared as map fields
+			if repeated || strings.HasPrefix(typ, "map<") {
+				return protoStruct, false
+			}
+			return fmt.Sprintf("map<string, %s>", typ), false
+		}
+		return protoStruct, false
+	default:
+		return protoValue, false
+	}
+}
+
+func (m *protoMessage) render(b *strings.Builder, indent string) {
+	writeProtoComment(b, indent, m.description)
+	fmt.Fprintf(b, "%smessage %s {\n", indent, m.name)
+	for _, nested := range m.nested {
+		nested.render(b, indent+"  ")
+		b.WriteString("\n")
+	}
+	for i, f := range m.fields {
+		if i != 0 && f.description != "" {
+			b.WriteString("\n")
+		}
+		writeProtoComment(b, indent+"  ", f.description)
+		label := ""
+		if f.repeated {
+			label = "repeated "
+		}
+		option := ""
+		if f.jsonName != "" {
+			option = fmt.Sprintf(" [json_name = %q]", f.jsonName)
+		}
+		fmt.Fprintf(b, "%s  %s%s %s = %d%s;\n", indent, label, f.typ, f.name, i+1, option)
+	}
+	fmt.Fprintf(b, "%s}\n", indent)
+}
+
+func writeProtoComment(b *strings.Builder, indent, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			fmt.Fprintf(b, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(b, "%s// %s\n", indent, line)
+	}
+}
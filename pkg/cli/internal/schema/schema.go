@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schema exports the structural schemas of the CRDs of a project as standalone JSON Schemas,
+// OpenAPI documents or protobuf messages, which lets tools and UIs unaware of CRDs validate or edit objects.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/storageversion"
+)
+
+// Format is a format the schemas can be exported in.
+type Format string
+
+const (
+	// JSONSchema exports a JSON Schema (draft-07) document.
+	JSONSchema Format = "jsonschema"
+	// OpenAPI exports an OpenAPI 3.0 document declaring the schema as a component.
+	OpenAPI Format = "openapi"
+	// Proto exports a proto3 file declaring the schema as a message.
+	Proto Format = "proto"
+)
+
+// Formats are the supported formats.
+var Formats = []Format{JSONSchema, OpenAPI, Proto}
+
+type object = map[string]interface{}
+
+// FileName returns the name of the file holding the schema of version of kind in format.
+func FileName(kind storageversion.Kind, version string, format Format) string {
+	name := fmt.Sprintf("%s_%s_%s", kind.Group, version, strings.ToLower(kind.Kind))
+	switch format {
+	case OpenAPI:
+		return name + ".openapi.json"
+	case Proto:
+		return name + ".proto"
+	default:
+		return name + ".schema.json"
+	}
+}
+
+// Generate returns the schema of version of kind in format.
+func Generate(kind storageversion.Kind, version storageversion.Version, format Format) ([]byte, error) {
+	if len(version.Schema) == 0 {
+		return nil, fmt.Errorf("version %s of %s has no schema", version.Name, kind.CRD)
+	}
+
+	switch format {
+	case JSONSchema:
+		schema := jsonSchema(version.Schema)
+		schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+		schema["title"] = kind.Kind
+		properties := field(schema, "properties")
+		if apiVersion := field(properties, "apiVersion"); apiVersion != nil {
+			apiVersion["enum"] = []interface{}{kind.Group + "/" + version.Name}
+		}
+		if kindSchema := field(properties, "kind"); kindSchema != nil {
+			kindSchema["enum"] = []interface{}{kind.Kind}
+		}
+		return marshal(schema)
+	case OpenAPI:
+		return marshal(object{
+			"openapi": "3.0.0",
+			"info": object{
+				"title":   fmt.Sprintf("%s/%s %s", kind.Group, version.Name, kind.Kind),
+				"version": version.Name,
+			},
+			"paths": object{},
+			"components": object{
+				"schemas": object{
+					fmt.Sprintf("%s.%s.%s", kind.Group, version.Name, kind.Kind): version.Schema,
+				},
+			},
+		})
+	case Proto:
+		return protoFile(kind, version), nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func marshal(obj object) ([]byte, error) {
+	content, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// jsonSchema returns schema without its OpenAPI and Kubernetes extensions. The fields of the objects not
+// preserving unknown fields are closed, as the API server would prune the other ones.
+func jsonSchema(schema object) object {
+	result := object{}
+	for key, value := range schema {
+		if key == "nullable" || strings.HasPrefix(key, "x-kubernetes-") {
+			continue
+		}
+		switch key {
+		case "properties":
+			properties := object{}
+			for name, property := range toObject(value) {
+				properties[name] = jsonSchema(toObject(property))
+			}
+			result[key] = properties
+		case "items", "not":
+			result[key] = jsonSchema(toObject(value))
+		case "additionalProperties":
+			if valueSchema, isSchema := value.(object); isSchema {
+				result[key] = jsonSchema(valueSchema)
+			} else {
+				result[key] = value
+			}
+		case "allOf", "anyOf", "oneOf":
+			values, _ := value.([]interface{})
+			schemas := make([]interface{}, 0, len(values))
+			for _, value := range values {
+				schemas = append(schemas, jsonSchema(toObject(value)))
+			}
+			result[key] = schemas
+		default:
+			result[key] = value
+		}
+	}
+
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		if typ, isString := result["type"].(string); isString {
+			result["type"] = []interface{}{typ, "null"}
+		}
+	}
+	_, hasAdditionalProperties := schema["additionalProperties"]
+	preserveUnknownFields, _ := schema["x-kubernetes-preserve-unknown-fields"].(bool)
+	if len(field(schema, "properties")) != 0 && !hasAdditionalProperties && !preserveUnknownFields {
+		result["additionalProperties"] = false
+	}
+	return result
+}
+
+func toObject(value interface{}) object {
+	obj, _ := value.(object)
+	return obj
+}
+
+func field(obj object, name string) object {
+	return toObject(obj[name])
+}
+
+func stringField(obj object, name string) string {
+	value, _ := obj[name].(string)
+	return value
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/storageversion"
+)
+
+const openAPIV3Schema = `description: Frigate is the Schema for the frigates API
+type: object
+properties:
+  apiVersion:
+    type: string
+  kind:
+    type: string
+  metadata:
+    type: object
+  spec:
+    description: FrigateSpec defines the desired state of Frigate
+    type: object
+    properties:
+      crew:
+        type: integer
+        format: int32
+      port:
+        anyOf:
+        - type: integer
+        - type: string
+        x-kubernetes-int-or-string: true
+      captain:
+        type: string
+        nullable: true
+      cargo:
+        type: array
+        items:
+          type: object
+          properties:
+            weight:
+              type: number
+      labels:
+        type: object
+        additionalProperties:
+          type: string
+      routes:
+        type: array
+        items:
+          type: array
+          items:
+            type: string
+      x-ray:
+        type: boolean
+      extra:
+        type: object
+        x-kubernetes-preserve-unknown-fields: true
+`
+
+func frigate(t *testing.T) (storageversion.Kind, storageversion.Version) {
+	var schema map[string]interface{}
+	if err := yaml.Unmarshal([]byte(openAPIV3Schema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	version := storageversion.Version{Name: "v1", Served: true, Storage: true, Schema: schema}
+	kind := storageversion.Kind{
+		CRD:      "frigates.ship.example.org",
+		Group:    "ship.example.org",
+		Kind:     "Frigate",
+		Plural:   "frigates",
+		Versions: []storageversion.Version{version},
+	}
+	return kind, version
+}
+
+func TestJSONSchema(t *testing.T) {
+	kind, version := frigate(t)
+	content, err := Generate(kind, version, JSONSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	properties := field(schema, "properties")
+	spec := field(properties, "spec")
+	specProperties := field(spec, "properties")
+	for _, tc := range []struct {
+		description      string
+		actual, expected interface{}
+	}{
+		{"draft", schema["$schema"], "http://json-schema.org/draft-07/schema#"},
+		{"title", schema["title"], "Frigate"},
+		{"apiVersion", field(properties, "apiVersion")["enum"], []interface{}{"ship.example.org/v1"}},
+		{"kind", field(properties, "kind")["enum"], []interface{}{"Frigate"}},
+		{"closed root", schema["additionalProperties"], false},
+		{"closed spec", spec["additionalProperties"], false},
+		{"open metadata", field(properties, "metadata")["additionalProperties"], nil},
+		{"open extra", field(specProperties, "extra")["additionalProperties"], nil},
+		{"extension", field(specProperties, "extra")["x-kubernetes-preserve-unknown-fields"], nil},
+		{"nullable", field(specProperties, "captain")["type"], []interface{}{"string", "null"}},
+		{"map", field(specProperties, "labels")["additionalProperties"], map[string]interface{}{"type": "string"}},
+	} {
+		if !reflect.DeepEqual(tc.actual, tc.expected) {
+			t.Errorf("%s: expected %v, got %v", tc.description, tc.expected, tc.actual)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	kind, version := frigate(t)
+	content, err := Generate(kind, version, OpenAPI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(content, &document); err != nil {
+		t.Fatal(err)
+	}
+	schema := field(field(field(document, "components"), "schemas"), "ship.example.org.v1.Frigate")
+	if !reflect.DeepEqual(schema, version.Schema) {
+		t.Errorf("expected the CRD schema as component, got %v", schema)
+	}
+}
+
+func TestProto(t *testing.T) {
+	kind, version := frigate(t)
+	content, err := Generate(kind, version, Proto)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `syntax = "proto3";
+
+package ship.example.org.v1;
+
+import "google/protobuf/struct.proto";
+
+// Frigate is the Schema for the frigates API
+message Frigate {
+  // FrigateSpec defines the desired state of Frigate
+  message Spec {
+    message CargoItem {
+      double weight = 1;
+    }
+
+    string captain = 1;
+    repeated CargoItem cargo = 2;
+    int32 crew = 3;
+    google.protobuf.Struct extra = 4;
+    map<string, string> labels = 5;
+    google.protobuf.Value port = 6;
+    google.protobuf.ListValue routes = 7;
+    bool x_ray = 8 [json_name = "x-ray"];
+  }
+
+  string apiVersion = 1;
+  string kind = 2;
+  google.protobuf.Struct metadata = 3;
+
+  // FrigateSpec defines the desired state of Frigate
+  Spec spec = 4;
+}
+`
+	if !strings.HasSuffix(string(content), expected) {
+		t.Errorf("expected the proto file to end with:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	kind, _ := frigate(t)
+	for format, expected := range map[Format]string{
+		JSONSchema: "ship.example.org_v1_frigate.schema.json",
+		OpenAPI:    "ship.example.org_v1_frigate.openapi.json",
+		Proto:      "ship.example.org_v1_frigate.proto",
+	} {
+		if name := FileName(kind, "v1", format); name != expected {
+			t.Errorf("expected %s for %s, got %s", expected, format, name)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serve
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Extract extracts the regular files and directories of the gzipped tar archive r into dir, rejecting the entries
+// out of dir, the links, and the archives whose files hold more than maxBytes once decompressed
+func Extract(r io.Reader, dir string, maxBytes int64) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	archive := tar.NewReader(gz)
+	remaining := maxBytes
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("entry %s is out of the project", header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			// The sizes of the headers are checked again while writing, as they may not match the contents
+			if header.Size > remaining {
+				return fmt.Errorf("project exceeds %d bytes once extracted", maxBytes)
+			}
+			written, err := writeFile(target, io.LimitReader(archive, remaining+1), os.FileMode(header.Mode).Perm())
+			if err != nil {
+				return err
+			}
+			if remaining -= written; remaining < 0 {
+				return fmt.Errorf("project exceeds %d bytes once extracted", maxBytes)
+			}
+		default:
+			return fmt.Errorf("entry %s is not a regular file or a directory", header.Name)
+		}
+	}
+}
+
+// writeFile writes the content of r to the file name, returning the number of bytes written
+func writeFile(name string, r io.Reader, mode os.FileMode) (int64, error) {
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(f, r)
+	if err != nil {
+		_ = f.Close()
+		return written, err
+	}
+	return written, f.Close()
+}
+
+// Checksums returns the SHA-256 checksums of the files of dir, by their slash-separated path relative to dir
+func Checksums(dir string) (map[string]string, error) {
+	checksums := map[string]string{}
+	err := walkFiles(dir, func(name, file string, _ os.FileInfo) error {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		checksums[name] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	return checksums, err
+}
+
+// Archive writes the files of dir to w as a gzipped tar archive
+func Archive(w io.Writer, dir string) error {
+	_, err := ArchiveDiff(w, dir, nil)
+	return err
+}
+
+// ArchiveDiff writes the files of dir that are not in before, or whose checksum differs, to w as a gzipped tar
+// archive, returning the files of before removed from dir
+func ArchiveDiff(w io.Writer, dir string, before map[string]string) ([]string, error) {
+	after, err := Checksums(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+	err = walkFiles(dir, func(name, file string, info os.FileInfo) error {
+		if sum, found := before[name]; found && sum == after[name] {
+			return nil
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(archive, f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for name := range before {
+		if _, found := after[name]; !found {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// walkFiles calls fn for each regular file of dir in lexical order, with its slash-separated path relative to dir
+func walkFiles(dir string, fn func(name, file string, info os.FileInfo) error) error {
+	return filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(name), file, info)
+	})
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package serve serves the scaffolding commands of the CLI over HTTP, so that platform portals generate projects
+// through the CLI instead of templating around it.
+package serve
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+)
+
+const (
+	// ScaffoldPath is the path of the endpoint running the commands
+	ScaffoldPath = "/v1/scaffold"
+	// HealthPath is the path of the endpoint answering the health checks
+	HealthPath = "/healthz"
+
+	// RemovedFilesHeader lists the files of the project removed by the commands, separated by commas, when a diff
+	// is requested
+	RemovedFilesHeader = "Kubebuilder-Removed-Files"
+
+	// DefaultMaxRequestBytes is the default size limit of the requests, including the project they carry
+	DefaultMaxRequestBytes = 32 << 20
+	// DefaultMaxProjectBytes is the default size limit of the files of the project carried by a request, once
+	// extracted
+	DefaultMaxProjectBytes = 256 << 20
+	// DefaultTimeout is the default time limit of the commands of a request
+	DefaultTimeout = 2 * time.Minute
+)
+
+// allowedCommands are the commands that may be run, which only write the project
+var allowedCommands = [][]string{{"init"}, {"create", "api"}, {"create", "webhook"}, {"edit"}}
+
+// Request is the body of a scaffold request
+type Request struct {
+	// Commands are the arguments of the commands to run in order, without the name of the CLI, e.g.
+	// ["init", "--domain", "example.com"]
+	Commands [][]string `json:"commands"`
+	// Project is an optional gzipped tar archive of the project to run the commands in, which starts empty
+	// otherwise
+	Project []byte `json:"project,omitempty"`
+	// Diff only returns the files created or modified by the commands, instead of the whole project
+	Diff bool `json:"diff,omitempty"`
+}
+
+// Error is the body of the responses of the failed requests
+type Error struct {
+	// Error describes the failure
+	Error string `json:"error"`
+	// Output is the output of the failed command, if any
+	Output string `json:"output,omitempty"`
+}
+
+// Runner runs the CLI with the arguments args in the directory dir, returning its combined output
+type Runner func(ctx context.Context, dir string, args []string) ([]byte, error)
+
+// ExecRunner returns a Runner executing the binary path, which never executes the external commands of the
+// plugins, such as make or go mod tidy: the generated project is returned as is
+func ExecRunner(path string) Runner {
+	return func(ctx context.Context, dir string, args []string) ([]byte, error) {
+		cmd := exec.CommandContext(ctx, path, append(args, "--no-exec")...)
+		cmd.Dir = dir
+		return cmd.CombinedOutput()
+	}
+}
+
+// Handler serves the scaffold requests
+type Handler struct {
+	// Run runs the commands of the requests
+	Run Runner
+	// MaxRequestBytes is the size limit of the requests, DefaultMaxRequestBytes if zero
+	MaxRequestBytes int64
+	// MaxProjectBytes is the size limit of the files of the project carried by a request, once extracted,
+	// DefaultMaxProjectBytes if zero
+	MaxProjectBytes int64
+	// Timeout is the time limit of the commands of a request, DefaultTimeout if zero
+	Timeout time.Duration
+	// Log logs the failed requests, the standard logger if nil
+	Log *log.Logger
+}
+
+var _ http.Handler = &Handler{}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case HealthPath:
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	case ScaffoldPath:
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			h.fail(w, http.StatusMethodNotAllowed, Error{Error: "only POST is allowed"})
+			return
+		}
+		h.scaffold(w, r)
+	default:
+		h.fail(w, http.StatusNotFound, Error{Error: fmt.Sprintf("no endpoint %s", r.URL.Path)})
+	}
+}
+
+func (h *Handler) scaffold(w http.ResponseWriter, r *http.Request) {
+	maxBytes := h.MaxRequestBytes
+	if maxBytes == 0 {
+		maxBytes = DefaultMaxRequestBytes
+	}
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes)).Decode(&req); err != nil {
+		h.fail(w, http.StatusBadRequest, Error{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		h.fail(w, http.StatusBadRequest, Error{Error: err.Error()})
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "kubebuilder-serve-")
+	if err != nil {
+		h.fail(w, http.StatusInternalServerError, Error{Error: err.Error()})
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	var before map[string]string
+	if len(req.Project) != 0 {
+		maxProjectBytes := h.MaxProjectBytes
+		if maxProjectBytes == 0 {
+			maxProjectBytes = DefaultMaxProjectBytes
+		}
+		if err := Extract(bytes.NewReader(req.Project), dir, maxProjectBytes); err != nil {
+			h.fail(w, http.StatusBadRequest, Error{Error: fmt.Sprintf("invalid project: %v", err)})
+			return
+		}
+	}
+	// The journal of kubebuilder undo is local to each copy of a project
+	removeJournal(dir)
+	if req.Diff {
+		if before, err = Checksums(dir); err != nil {
+			h.fail(w, http.StatusInternalServerError, Error{Error: err.Error()})
+			return
+		}
+	}
+
+	timeout := h.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	for _, args := range req.Commands {
+		if output, err := h.Run(ctx, dir, args); err != nil {
+			if ctx.Err() != nil {
+				err = ctx.Err()
+			}
+			h.fail(w, http.StatusUnprocessableEntity, Error{
+				Error:  fmt.Sprintf("%s failed: %v", strings.Join(args, " "), err),
+				Output: string(output),
+			})
+			return
+		}
+	}
+
+	removeJournal(dir)
+
+	var archive bytes.Buffer
+	var removed []string
+	if req.Diff {
+		removed, err = ArchiveDiff(&archive, dir, before)
+	} else {
+		err = Archive(&archive, dir)
+	}
+	if err != nil {
+		h.fail(w, http.StatusInternalServerError, Error{Error: err.Error()})
+		return
+	}
+	if len(removed) != 0 {
+		w.Header().Set(RemovedFilesHeader, strings.Join(removed, ","))
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(archive.Bytes())
+}
+
+// removeJournal removes the journal of kubebuilder undo of the project in dir, if any
+func removeJournal(dir string) {
+	_ = os.Remove(filepath.Join(dir, journal.DefaultPath))
+}
+
+func (h *Handler) fail(w http.ResponseWriter, status int, body Error) {
+	logger := h.Log
+	if logger == nil {
+		logger = log.New(os.Stderr, "", log.LstdFlags)
+	}
+	if status != http.StatusNotFound {
+		logger.Printf("%d: %s", status, body.Error)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// Validate checks that the request only runs the allowed commands, with arguments that do not refer to paths out
+// of the project
+func (req Request) Validate() error {
+	if len(req.Commands) == 0 {
+		return fmt.Errorf("no command to run")
+	}
+	for _, args := range req.Commands {
+		if !isAllowed(args) {
+			return fmt.Errorf("command %q is not allowed, only init, create api, create webhook and edit are",
+				strings.Join(args, " "))
+		}
+		for _, arg := range args {
+			// Flag values may nest paths, as in --replace=module=path or the lists of the slice flags
+			for _, value := range strings.FieldsFunc(arg, isValueSeparator) {
+				if filepath.IsAbs(value) || hasParentElement(value) {
+					return fmt.Errorf("argument %q of %q refers to a path out of the project", arg,
+						strings.Join(args, " "))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isAllowed(args []string) bool {
+	for _, allowed := range allowedCommands {
+		if len(args) < len(allowed) {
+			continue
+		}
+		matches := true
+		for i, word := range allowed {
+			if args[i] != word {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+func isValueSeparator(r rune) bool {
+	return r == '=' || r == ','
+}
+
+func hasParentElement(path string) bool {
+	for _, element := range strings.Split(filepath.ToSlash(path), "/") {
+		if element == ".." {
+			return true
+		}
+	}
+	return false
+}
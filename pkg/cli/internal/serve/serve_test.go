@@ -0,0 +1,249 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serve
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeRun writes the files named by the arguments of the commands after "write", and removes the ones after
+// "remove", failing on "fail"
+func fakeRun(_ context.Context, dir string, args []string) ([]byte, error) {
+	for i, arg := range args {
+		switch {
+		case arg == "fail":
+			return []byte("something went wrong"), errors.New("exit status 1")
+		case i > 0 && args[i-1] == "write":
+			if err := ioutil.WriteFile(filepath.Join(dir, arg), []byte(strings.Join(args, " ")), 0644); err != nil {
+				return nil, err
+			}
+		case i > 0 && args[i-1] == "remove":
+			if err := os.Remove(filepath.Join(dir, arg)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return nil, nil
+}
+
+func archive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)),
+			Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func files(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	result := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return result
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result[header.Name] = string(content)
+	}
+}
+
+func post(t *testing.T, req Request) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &Handler{Run: fakeRun, Log: log.New(ioutil.Discard, "", 0)}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, ScaffoldPath, bytes.NewReader(body)))
+	return w
+}
+
+func TestScaffold(t *testing.T) {
+	w := post(t, Request{Commands: [][]string{
+		{"init", "write", "PROJECT"},
+		{"create", "api", "write", "api.go"},
+	}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	expected := map[string]string{"PROJECT": "init write PROJECT", "api.go": "create api write api.go"}
+	if actual := files(t, w.Body); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestScaffoldDiff(t *testing.T) {
+	w := post(t, Request{
+		Commands: [][]string{{"edit", "write", "PROJECT", "write", "new.go", "remove", "old.go"}},
+		Project:  archive(t, map[string]string{"PROJECT": "v3", "main.go": "package main", "old.go": "package old"}),
+		Diff:     true,
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	content := "edit write PROJECT write new.go remove old.go"
+	expected := map[string]string{"PROJECT": content, "new.go": content}
+	if actual := files(t, w.Body); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected the created and modified files %v, got %v", expected, actual)
+	}
+	if removed := w.Header().Get(RemovedFilesHeader); removed != "old.go" {
+		t.Errorf("expected old.go to be removed, got %q", removed)
+	}
+}
+
+func TestScaffoldFailure(t *testing.T) {
+	w := post(t, Request{Commands: [][]string{{"init", "write", "PROJECT"}, {"create", "api", "fail"}}})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected %d, got %d: %s", http.StatusUnprocessableEntity, w.Code, w.Body)
+	}
+	var body Error
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error != "create api fail failed: exit status 1" || body.Output != "something went wrong" {
+		t.Errorf("expected the error and the output of the failed command, got %+v", body)
+	}
+}
+
+func TestScaffoldInvalidRequests(t *testing.T) {
+	for description, req := range map[string]Request{
+		"no command":           {},
+		"other command":        {Commands: [][]string{{"alpha", "migrate"}}},
+		"create only":          {Commands: [][]string{{"create"}}},
+		"absolute path":        {Commands: [][]string{{"init", "--license-file", "/etc/passwd"}}},
+		"absolute flag value":  {Commands: [][]string{{"create", "api", "--from-file=/etc/gvks.yaml"}}},
+		"parent path":          {Commands: [][]string{{"create", "api", "--from-file", "../gvks.yaml"}}},
+		"nested absolute path": {Commands: [][]string{{"edit", "--replace=k8s.io/api=/etc/api"}}},
+		"nested parent path":   {Commands: [][]string{{"edit", "--replace=k8s.io/api=../../api"}}},
+		"nested separate path": {Commands: [][]string{{"edit", "--replace", "k8s.io/api=/etc/api"}}},
+		"listed absolute path": {Commands: [][]string{{"edit", "--replace=k8s.io/api=./api,k8s.io/apimachinery=/x"}}},
+		"project out of dir":   {Commands: [][]string{{"edit"}}, Project: archive(t, map[string]string{"../x": ""})},
+		"invalid project":      {Commands: [][]string{{"edit"}}, Project: []byte("not an archive")},
+	} {
+		if w := post(t, req); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected %d, got %d: %s", description, http.StatusBadRequest, w.Code, w.Body)
+		}
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	for _, args := range [][]string{
+		{"edit", "--replace=k8s.io/api=./api"},
+		{"edit", "--replace", "k8s.io/api=k8s.io/api@v0.19.2,k8s.io/apimachinery=./apimachinery"},
+		{"create", "api", "--group=ship", "--version=v1"},
+	} {
+		if err := (Request{Commands: [][]string{args}}).Validate(); err != nil {
+			t.Errorf("%v: %v", args, err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"edit", "--replace=k8s.io/api=/etc/api"},
+		{"edit", "--replace=k8s.io/api=../../api"},
+		{"edit", "--replace", "k8s.io/api=/etc/api"},
+		{"edit", "--replace", "k8s.io/api=./api,k8s.io/apimachinery=../x"},
+	} {
+		if err := (Request{Commands: [][]string{args}}).Validate(); err == nil {
+			t.Errorf("expected %v to be rejected", args)
+		}
+	}
+}
+
+func TestExtractMaxBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubebuilder-serve-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	project := archive(t, map[string]string{"PROJECT": "version: \"3\"\n", "main.go": "package main\n"})
+	if err := Extract(bytes.NewReader(project), dir, 26); err != nil {
+		t.Errorf("expected the project of 26 bytes to be extracted: %v", err)
+	}
+	if err := Extract(bytes.NewReader(project), dir, 25); err == nil {
+		t.Errorf("expected the project of 26 bytes to exceed 25 bytes")
+	}
+
+	// The limit applies to the decompressed files, not to the archive
+	bomb := archive(t, map[string]string{"zeros": strings.Repeat("\x00", 1<<20)})
+	if len(bomb) >= 1<<12 {
+		t.Fatalf("expected the archive to be compressed below 4KiB, got %d bytes", len(bomb))
+	}
+	if err := Extract(bytes.NewReader(bomb), dir, 1<<16); err == nil {
+		t.Errorf("expected the file of 1MiB to exceed 64KiB")
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	h := &Handler{Run: fakeRun, Log: log.New(ioutil.Discard, "", 0)}
+	for _, c := range []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, HealthPath, http.StatusOK},
+		{http.MethodGet, ScaffoldPath, http.StatusMethodNotAllowed},
+		{http.MethodPost, "/v1/other", http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(c.method, c.path, nil))
+		if w.Code != c.code {
+			t.Errorf("%s %s: expected %d, got %d", c.method, c.path, c.code, w.Code)
+		}
+	}
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package storageversion audits the served and storage versions of the kinds of a project and generates the
+// StorageVersionMigrations that make the kube-storage-version-migrator rewrite their stored objects, which
+// lets the kinds evolve without a conversion webhook as long as their versions share the same schema.
+package storageversion
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/crdlint"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+// Version is a version of a Kind as generated in its CRD.
+type Version struct {
+	Name       string
+	Served     bool
+	Storage    bool
+	Deprecated bool
+	// Schema is the openAPIV3Schema of the version.
+	Schema map[string]interface{}
+}
+
+// Kind is a kind of the project along with the versions of its CRD.
+type Kind struct {
+	// CRD is the name of the CRD.
+	CRD string
+	// Group is the fully qualified API group.
+	Group  string
+	Kind   string
+	Plural string
+	// Scope is the scope of the objects of the kind, Namespaced or Cluster.
+	Scope string
+
+	Versions []Version
+
+	// ConversionWebhook is true if a conversion webhook was scaffolded for the kind.
+	ConversionWebhook bool
+}
+
+// StorageVersion returns the name of the storage version, or an empty string if there is not exactly one.
+func (k Kind) StorageVersion() string {
+	storage := ""
+	for _, version := range k.Versions {
+		if version.Storage {
+			if storage != "" {
+				return ""
+			}
+			storage = version.Name
+		}
+	}
+	return storage
+}
+
+type object = map[string]interface{}
+
+// Load returns the kinds of the project resources found in the CRDs of crdDir, reporting the resources
+// whose CRD or versions were not generated.
+func Load(cfg config.Config, crdDir string) ([]Kind, []crdlint.Problem, error) {
+	crds, err := ReadCRDs(crdDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var keys []string
+	var problems []crdlint.Problem
+	seen := map[string]bool{}
+	for _, res := range cfg.Resources {
+		group := cfg.Domain
+		if res.Group != "" {
+			group = res.Group + "." + cfg.Domain
+		}
+		key := res.Kind + "." + group
+		first := !seen[key]
+		seen[key] = true
+
+		kind, found := crds[key]
+		if !found {
+			// Resources without API, such as core types with controllers, have no CRD in the project.
+			if res.API != nil && first {
+				problems = append(problems, crdlint.Problem{CRD: key, Severity: crdlint.Warning, Message: fmt.Sprintf(
+					"no CRD found in %s, run \"make manifests\" to generate it", crdDir)})
+			}
+			continue
+		}
+		if first {
+			keys = append(keys, key)
+		}
+
+		if !kind.hasVersion(res.Version) {
+			problems = append(problems, crdlint.Problem{CRD: kind.CRD, Severity: crdlint.Warning, Message: fmt.Sprintf(
+				"version %s is in the PROJECT file but not in the CRD, run \"make manifests\" to generate it",
+				res.Version)})
+		}
+		if res.Webhooks != nil && res.Webhooks.Conversion {
+			kind.ConversionWebhook = true
+			crds[key] = kind
+		}
+	}
+
+	kinds := make([]Kind, 0, len(keys))
+	for _, key := range keys {
+		kinds = append(kinds, crds[key])
+	}
+	return kinds, problems, nil
+}
+
+func (k Kind) hasVersion(name string) bool {
+	for _, version := range k.Versions {
+		if version.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadCRDs returns the kinds of the CRDs found in the YAML files of dir, indexed by kind and group.
+func ReadCRDs(dir string) (map[string]Kind, error) {
+	docs, err := readDocuments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	kinds := map[string]Kind{}
+	for _, crd := range docs {
+		if crd["kind"] != "CustomResourceDefinition" {
+			continue
+		}
+		metadata, spec := field(crd, "metadata"), field(crd, "spec")
+		names := field(spec, "names")
+		kind := Kind{
+			CRD:    stringField(metadata, "name"),
+			Group:  stringField(spec, "group"),
+			Kind:   stringField(names, "kind"),
+			Plural: stringField(names, "plural"),
+			Scope:  stringField(spec, "scope"),
+		}
+		// v1beta1 CRDs may declare a single schema for every version
+		topLevelSchema := field(field(spec, "validation"), "openAPIV3Schema")
+		rawVersions, _ := spec["versions"].([]interface{})
+		for _, rawVersion := range rawVersions {
+			version, _ := rawVersion.(object)
+			schema := field(field(version, "schema"), "openAPIV3Schema")
+			if len(schema) == 0 {
+				schema = topLevelSchema
+			}
+			served, _ := version["served"].(bool)
+			storage, _ := version["storage"].(bool)
+			deprecated, _ := version["deprecated"].(bool)
+			kind.Versions = append(kind.Versions, Version{
+				Name:       stringField(version, "name"),
+				Served:     served,
+				Storage:    storage,
+				Deprecated: deprecated,
+				Schema:     schema,
+			})
+		}
+		kinds[kind.Kind+"."+kind.Group] = kind
+	}
+	return kinds, nil
+}
+
+// Audit checks the versions of the kinds and reports the deprecated and unserved versions still used by the
+// samples found in samplesDir.
+func Audit(kinds []Kind, samplesDir string) ([]crdlint.Problem, error) {
+	samples, err := readDocuments(samplesDir)
+	if err != nil {
+		return nil, err
+	}
+	used := map[string]bool{}
+	for _, sample := range samples {
+		apiVersion, kind := stringField(sample, "apiVersion"), stringField(sample, "kind")
+		used[kind+"."+apiVersion] = true
+	}
+
+	var problems []crdlint.Problem
+	for _, kind := range kinds {
+		storage := kind.StorageVersion()
+		if storage == "" && len(kind.Versions) != 0 {
+			problems = append(problems, crdlint.Problem{CRD: kind.CRD, Severity: crdlint.Error, Message: "the CRD " +
+				"must have exactly one storage version, mark the type of one version with the " +
+				"+kubebuilder:storageversion marker"})
+		}
+
+		if !kind.ConversionWebhook && storage != "" {
+			storageVersion := kind.version(storage)
+			for _, version := range kind.Versions {
+				if version.Name == storage || !version.Served {
+					continue
+				}
+				if !reflect.DeepEqual(withoutDescriptions(version.Schema), withoutDescriptions(storageVersion.Schema)) {
+					problems = append(problems, crdlint.Problem{CRD: kind.CRD, Severity: crdlint.Warning,
+						Message: fmt.Sprintf("versions %s and %s have different schemas but the kind has no "+
+							"conversion webhook: the API server only rewrites the apiVersion of the objects and "+
+							"prunes the fields unknown to the other version, keep the schemas identical or "+
+							"create a conversion webhook with --conversion", version.Name, storage)})
+				}
+			}
+		}
+
+		for _, version := range kind.Versions {
+			if !used[kind.Kind+"."+kind.Group+"/"+version.Name] {
+				continue
+			}
+			switch {
+			case !version.Served:
+				problems = append(problems, crdlint.Problem{CRD: kind.CRD, Severity: crdlint.Warning,
+					Message: fmt.Sprintf("version %s is not served but is still used by the samples in %s, "+
+						"update them to %s", version.Name, samplesDir, storage)})
+			case version.Deprecated:
+				problems = append(problems, crdlint.Problem{CRD: kind.CRD, Severity: crdlint.Warning,
+					Message: fmt.Sprintf("version %s is deprecated but is still used by the samples in %s, "+
+						"update them to %s", version.Name, samplesDir, storage)})
+			}
+		}
+	}
+	return problems, nil
+}
+
+func (k Kind) version(name string) Version {
+	for _, version := range k.Versions {
+		if version.Name == name {
+			return version
+		}
+	}
+	return Version{}
+}
+
+// Migration returns the StorageVersionMigration of the kube-storage-version-migrator that rewrites the
+// stored objects of kind in its storage version.
+func Migration(kind Kind) ([]byte, error) {
+	storage := kind.StorageVersion()
+	if storage == "" {
+		return nil, fmt.Errorf("%s has no single storage version", kind.CRD)
+	}
+	return yaml.Marshal(object{
+		"apiVersion": "migration.k8s.io/v1alpha1",
+		"kind":       "StorageVersionMigration",
+		"metadata": object{
+			"name": fmt.Sprintf("%s-%s.%s", kind.Plural, storage, kind.Group),
+		},
+		"spec": object{
+			"resource": object{
+				"group":    kind.Group,
+				"version":  storage,
+				"resource": kind.Plural,
+			},
+		},
+	})
+}
+
+// readDocuments returns the objects found in the YAML files of dir. A missing dir holds no object.
+func readDocuments(dir string) ([]object, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []object
+	for _, f := range files {
+		if filepath.Ext(f.Name()) != ".yaml" {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range strings.Split(string(content), "\n---") {
+			var obj object
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				return nil, fmt.Errorf("unable to parse %s: %v", f.Name(), err)
+			}
+			if obj != nil {
+				objects = append(objects, obj)
+			}
+		}
+	}
+	return objects, nil
+}
+
+// withoutDescriptions returns a copy of schema without its descriptions, which do not change how the
+// objects are stored. Properties named description are kept as their value is not a string.
+func withoutDescriptions(value interface{}) interface{} {
+	switch value := value.(type) {
+	case object:
+		result := object{}
+		for key, child := range value {
+			if _, isText := child.(string); key != "description" || !isText {
+				result[key] = withoutDescriptions(child)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(value))
+		for _, child := range value {
+			result = append(result, withoutDescriptions(child))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+func field(obj object, name string) object {
+	value, _ := obj[name].(object)
+	return value
+}
+
+func stringField(obj object, name string) string {
+	value, _ := obj[name].(string)
+	return value
+}
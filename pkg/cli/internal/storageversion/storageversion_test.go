@@ -0,0 +1,198 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversion
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+const crd = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: frigates.ship.example.org
+spec:
+  group: ship.example.org
+  names:
+    kind: Frigate
+    plural: frigates
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        description: Frigate is the Schema for the frigates API
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              crew:
+                type: integer
+  - name: v1beta1
+    served: true
+    storage: false
+    deprecated: true
+    schema:
+      openAPIV3Schema:
+        description: Frigate is the Schema for the frigates API (deprecated)
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              crew:
+                type: CREW_TYPE
+`
+
+const sample = `apiVersion: ship.example.org/v1beta1
+kind: Frigate
+metadata:
+  name: frigate-sample
+`
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func audit(t *testing.T, crewType string, conversion bool) ([]Kind, []string) {
+	dir, err := ioutil.TempDir("", "kubebuilder-storageversion-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+
+	crdDir, samplesDir := filepath.Join(dir, "crd"), filepath.Join(dir, "samples")
+	writeFile(t, filepath.Join(crdDir, "ship.example.org_frigates.yaml"), strings.Replace(crd, "CREW_TYPE", crewType, 1))
+	writeFile(t, filepath.Join(samplesDir, "ship_v1beta1_frigate.yaml"), sample)
+	writeFile(t, filepath.Join(samplesDir, "kustomization.yaml"), "resources:\n- ship_v1beta1_frigate.yaml\n")
+
+	cfg := config.Config{
+		Domain: "example.org",
+		Resources: []config.ResourceData{
+			{Group: "ship", Version: "v1beta1", Kind: "Frigate", API: &config.API{CRDVersion: "v1"}},
+			{Group: "ship", Version: "v1", Kind: "Frigate", API: &config.API{CRDVersion: "v1"},
+				Webhooks: &config.Webhooks{Conversion: conversion}},
+			{Group: "ship", Version: "v2", Kind: "Frigate", API: &config.API{CRDVersion: "v1"}},
+			{Group: "sea", Version: "v1", Kind: "Destroyer", API: &config.API{CRDVersion: "v1"}},
+			{Group: "apps", Version: "v1", Kind: "Deployment"},
+		},
+	}
+	kinds, loadProblems, err := Load(cfg, crdDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditProblems, err := Audit(kinds, samplesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var problems []string
+	for _, problem := range append(loadProblems, auditProblems...) {
+		problems = append(problems, problem.String())
+	}
+	return kinds, problems
+}
+
+func checkProblems(t *testing.T, problems, expected []string) {
+	if len(problems) != len(expected) {
+		t.Fatalf("expected %d problems, got %q", len(expected), problems)
+	}
+	for i, problem := range problems {
+		if !strings.HasPrefix(problem, expected[i]) {
+			t.Errorf("expected problem %q, got %q", expected[i], problem)
+		}
+	}
+}
+
+func TestAudit(t *testing.T) {
+	kinds, problems := audit(t, "integer", false)
+	if len(kinds) != 1 || kinds[0].StorageVersion() != "v1" || kinds[0].Plural != "frigates" {
+		t.Fatalf("expected the Frigate kind stored in v1, got %+v", kinds)
+	}
+	checkProblems(t, problems, []string{
+		"warning: frigates.ship.example.org: version v2 is in the PROJECT file but not in the CRD",
+		"warning: Destroyer.sea.example.org: no CRD found",
+		"warning: frigates.ship.example.org: version v1beta1 is deprecated but is still used by the samples",
+	})
+}
+
+func TestAuditSchemas(t *testing.T) {
+	_, problems := audit(t, "string", false)
+	checkProblems(t, problems, []string{
+		"warning: frigates.ship.example.org: version v2 is in the PROJECT file but not in the CRD",
+		"warning: Destroyer.sea.example.org: no CRD found",
+		"warning: frigates.ship.example.org: versions v1beta1 and v1 have different schemas but the kind has no " +
+			"conversion webhook",
+		"warning: frigates.ship.example.org: version v1beta1 is deprecated but is still used by the samples",
+	})
+
+	kinds, problems := audit(t, "string", true)
+	if len(kinds) != 1 || !kinds[0].ConversionWebhook {
+		t.Fatalf("expected the Frigate kind to have a conversion webhook, got %+v", kinds)
+	}
+	checkProblems(t, problems, []string{
+		"warning: frigates.ship.example.org: version v2 is in the PROJECT file but not in the CRD",
+		"warning: Destroyer.sea.example.org: no CRD found",
+		"warning: frigates.ship.example.org: version v1beta1 is deprecated but is still used by the samples",
+	})
+}
+
+func TestMigration(t *testing.T) {
+	kinds, _ := audit(t, "integer", false)
+	content, err := Migration(kinds[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `apiVersion: migration.k8s.io/v1alpha1
+kind: StorageVersionMigration
+metadata:
+  name: frigates-v1.ship.example.org
+spec:
+  resource:
+    group: ship.example.org
+    resource: frigates
+    version: v1
+`
+	if string(content) != expected {
+		t.Errorf("expected migration:\n%s\ngot:\n%s", expected, content)
+	}
+
+	kinds[0].Versions[0].Storage = false
+	if _, err := Migration(kinds[0]); err == nil {
+		t.Error("expected an error for a kind without storage version")
+	}
+	problems, err := Audit(kinds, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "+kubebuilder:storageversion") {
+		t.Errorf("expected a missing storage version error, got %v", problems)
+	}
+}
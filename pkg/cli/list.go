@@ -0,0 +1,229 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/projectinfo"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+const (
+	listOutputTable = "table"
+	listOutputJSON  = "json"
+)
+
+func (cli) newListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the resources and plugins of the project",
+		Long: `List the APIs, webhooks and plugins recorded in the PROJECT file, along with the
+files and the CRDs they were scaffolded into.
+`,
+	}
+}
+
+func (c cli) newListAPIsCmd() *cobra.Command {
+	var crdDir, output string
+
+	cmd := &cobra.Command{
+		Use:   "apis",
+		Short: "List the APIs of the project",
+		Long: `List the resources of the PROJECT file.
+
+The scope of the resources and their storage version are read from the CRDs
+generated in --crd-dir, they are empty if the CRDs were not generated yet. The
+resources without an API, reconciled by a controller of the project, are listed
+with their group, "core" for the core group.
+`,
+		Example: fmt.Sprintf(`  # List the APIs of the project in the current directory
+  %[1]s list apis
+
+  # Print the APIs as JSON, e.g. to read them with jq
+  %[1]s list apis -o json | jq -r '.[].kind'
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := validateListOutput(output); err != nil {
+				return err
+			}
+			cfg, err := config.LoadInitialized()
+			if err != nil {
+				return err
+			}
+			apis, err := projectinfo.APIs(cfg.Config, ".", crdDir)
+			if err != nil {
+				return fmt.Errorf("unable to list the APIs: %v", err)
+			}
+			if output == listOutputJSON {
+				return printJSON(apis)
+			}
+
+			rows := make([][]string, 0, len(apis))
+			for _, api := range apis {
+				storage := ""
+				if api.Storage {
+					storage = "true"
+				}
+				rows = append(rows, []string{api.Group, api.Version, api.Kind, api.Scope, api.CRDVersion, storage,
+					api.Types, api.Controller})
+			}
+			return printTable([]string{"GROUP", "VERSION", "KIND", "SCOPE", "CRD-VERSION", "STORAGE", "TYPES",
+				"CONTROLLER"}, rows)
+		},
+	}
+
+	cmd.Flags().StringVar(&crdDir, "crd-dir", filepath.Join("config", "crd", "bases"),
+		"directory containing the generated CRDs")
+	addListOutputFlag(cmd, &output)
+
+	return cmd
+}
+
+func (c cli) newListWebhooksCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "webhooks",
+		Short: "List the webhooks of the project",
+		Long: `List the webhooks of the resources of the PROJECT file, along with their types
+and the files they were scaffolded into.
+`,
+		Example: fmt.Sprintf(`  # List the webhooks of the project in the current directory
+  %[1]s list webhooks
+
+  # Print the webhooks as JSON
+  %[1]s list webhooks -o json
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := validateListOutput(output); err != nil {
+				return err
+			}
+			cfg, err := config.LoadInitialized()
+			if err != nil {
+				return err
+			}
+			webhooks := projectinfo.Webhooks(cfg.Config, ".")
+			if output == listOutputJSON {
+				return printJSON(webhooks)
+			}
+
+			rows := make([][]string, 0, len(webhooks))
+			for _, webhook := range webhooks {
+				rows = append(rows, []string{webhook.Group, webhook.Version, webhook.Kind,
+					strings.Join(webhook.Types, ","), webhook.WebhookVersion, strings.Join(webhook.Paths, ",")})
+			}
+			return printTable([]string{"GROUP", "VERSION", "KIND", "TYPES", "WEBHOOK-VERSION", "PATHS"}, rows)
+		},
+	}
+
+	addListOutputFlag(cmd, &output)
+
+	return cmd
+}
+
+func (c cli) newListPluginsCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "plugins",
+		Short: "List the plugins of the project",
+		Long: `List the plugins of the layout of the PROJECT file, and the plugins whose
+configuration is stored in it. A plugin is known if a plugin of this CLI
+supporting the project version matches its key.
+`,
+		Example: fmt.Sprintf(`  # List the plugins of the project in the current directory
+  %[1]s list plugins
+`, c.commandName),
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if err := validateListOutput(output); err != nil {
+				return err
+			}
+			cfg, err := config.LoadInitialized()
+			if err != nil {
+				return err
+			}
+			plugins := make([]plugin.Plugin, 0, len(c.plugins))
+			for _, p := range c.plugins {
+				plugins = append(plugins, p)
+			}
+			infos := projectinfo.Plugins(cfg.Config, plugins)
+			if output == listOutputJSON {
+				return printJSON(infos)
+			}
+
+			rows := make([][]string, 0, len(infos))
+			for _, info := range infos {
+				rows = append(rows, []string{info.Key, fmt.Sprint(info.Layout), fmt.Sprint(info.Config),
+					fmt.Sprint(info.Known)})
+			}
+			return printTable([]string{"KEY", "LAYOUT", "CONFIG", "KNOWN"}, rows)
+		},
+	}
+
+	addListOutputFlag(cmd, &output)
+
+	return cmd
+}
+
+func addListOutputFlag(cmd *cobra.Command, output *string) {
+	cmd.Flags().StringVarP(output, "output", "o", listOutputTable,
+		fmt.Sprintf("output format, one of '%s' or '%s'", listOutputTable, listOutputJSON))
+}
+
+func validateListOutput(output string) error {
+	if output != listOutputTable && output != listOutputJSON {
+		return fmt.Errorf("invalid output format %q, must be one of '%s' or '%s'",
+			output, listOutputTable, listOutputJSON)
+	}
+	return nil
+}
+
+// printTable prints the rows aligned in columns under the header, with "-" for the empty cells.
+func printTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			if cell == "" {
+				cell = "-"
+			}
+			cells[i] = cell
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+	return w.Flush()
+}
+
+// printJSON prints v as indented JSON.
+func printJSON(v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(content))
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/cli/internal/serve"
+)
+
+func (c cli) newServeCmd() *cobra.Command {
+	var address string
+	var timeout time.Duration
+	var maxRequestBytes, maxProjectBytes int64
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the scaffolding commands over HTTP",
+		Long: fmt.Sprintf(`Serve the scaffolding commands over HTTP, so that platform portals, e.g.
+Backstage, generate projects through %[1]s instead of templating around it.
+
+A POST request to %[2]s runs the init, create api, create webhook and edit
+commands of its JSON body in an empty directory, or in the project of the
+request, and answers with the project as a gzipped tar archive:
+
+  {
+    "commands": [
+      ["init", "--domain", "example.com", "--repo", "example.com/fleet"],
+      ["create", "api", "--group", "ship", "--version", "v1", "--kind", "Frigate",
+       "--resource", "--controller"]
+    ],
+    "project": "<base64 of a gzipped tar archive, optional>",
+    "diff": false
+  }
+
+With "diff", the archive only holds the files created or modified by the
+commands, and the %[3]s header lists the removed ones.
+
+The commands cannot prompt, set --resource and --controller of create api, and
+never execute the external commands of the plugins, such as make or go mod tidy:
+run them on the generated project. The paths of their arguments must be
+relative to the project. A failed command is answered with a JSON error holding
+its output.
+
+%[4]s answers the health checks.
+`, c.commandName, serve.ScaffoldPath, serve.RemovedFilesHeader, serve.HealthPath),
+		Example: fmt.Sprintf(`  # Serve the scaffolding commands on port 8080 of the loopback interface
+  %[1]s serve
+
+  # Serve them on port 8080 of all the interfaces, e.g. in a pod behind a NetworkPolicy
+  %[1]s serve --address :8080
+
+  # Generate a project
+  curl -sf localhost:8080%[2]s \
+      -d '{"commands": [["init", "--domain", "example.com", "--repo", "example.com/fleet"]]}' | tar xz
+`, c.commandName, serve.ScaffoldPath),
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("unable to find the path of %s: %v", c.commandName, err)
+			}
+
+			server := &http.Server{
+				Addr: address,
+				Handler: &serve.Handler{
+					Run:             serve.ExecRunner(executable),
+					MaxRequestBytes: maxRequestBytes,
+					MaxProjectBytes: maxProjectBytes,
+					Timeout:         timeout,
+				},
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			stop := make(chan os.Signal, 1)
+			signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+			go func() {
+				<-stop
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				defer cancel()
+				_ = server.Shutdown(ctx)
+			}()
+
+			log.Printf("Serving the scaffolding commands on %s", address)
+			if err := server.ListenAndServe(); err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&address, "address", "localhost:8080",
+		"address to listen on, only reachable from the local host by default")
+	cmd.Flags().DurationVar(&timeout, "timeout", serve.DefaultTimeout,
+		"time limit of the commands of a request")
+	cmd.Flags().Int64Var(&maxRequestBytes, "max-request-bytes", serve.DefaultMaxRequestBytes,
+		"size limit of the requests, including the project they carry")
+	cmd.Flags().Int64Var(&maxProjectBytes, "max-project-bytes", serve.DefaultMaxProjectBytes,
+		"size limit of the files of the project carried by a request, once extracted")
+
+	return cmd
+}
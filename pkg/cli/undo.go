@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+)
+
+func (c cli) newUndoCmd() *cobra.Command {
+	var force, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Revert the files changed by the last init, create or edit command",
+		Long: fmt.Sprintf(`Revert the files changed by the last init, create or edit command.
+
+The files scaffolded by the command are removed, and the files it changed, such
+as the PROJECT file and the files updated at the +kubebuilder:scaffold markers,
+are restored. The changes are recorded in %[1]s, which only keeps the last
+command: undo can not be run twice in a row.
+
+The files generated by the external commands run by the command, such as the
+deep copy functions and the CRDs generated by make, are not recorded: run make
+again once the command is undone.
+
+Undo fails if a file was changed since the command, unless --force is set.
+`, journal.DefaultPath),
+		Example: fmt.Sprintf(`  # Revert the API created with the wrong kind
+  %[1]s create api --group ship --version v1 --kind Frigat
+  %[1]s undo
+
+  # Print the files that undo would revert
+  %[1]s undo --dry-run
+`, c.commandName),
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			j, err := journal.Load(journal.DefaultPath)
+			if os.IsNotExist(err) {
+				return fmt.Errorf("no command to undo: %s does not exist", journal.DefaultPath)
+			}
+			if err != nil {
+				return err
+			}
+
+			modified, err := j.Modified()
+			if err != nil {
+				return err
+			}
+			if len(modified) != 0 && !force {
+				return fmt.Errorf("files were changed since %q: %s, set --force to discard their changes",
+					j.Command, strings.Join(modified, ", "))
+			}
+
+			fmt.Printf("Undoing %q\n", j.Command)
+			for _, f := range j.Files {
+				if f.Existed {
+					fmt.Printf("Restore %s\n", f.Path)
+				} else {
+					fmt.Printf("Remove %s\n", f.Path)
+				}
+			}
+			if dryRun {
+				return nil
+			}
+
+			if err := j.Undo(); err != nil {
+				return err
+			}
+			if err := os.Remove(journal.DefaultPath); err != nil {
+				return err
+			}
+			// The directory of the journal is removed if empty, e.g. once init is undone
+			_ = os.Remove(filepath.Dir(journal.DefaultPath))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "revert the files even if they were changed since the command")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the files that would be reverted without reverting them")
+
+	return cmd
+}
@@ -18,19 +18,43 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
 )
 
 func (c cli) newVersionCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:     "version",
-		Short:   fmt.Sprintf("Print the %s version", c.commandName),
-		Long:    fmt.Sprintf("Print the %s version", c.commandName),
+		Use:   "version",
+		Short: fmt.Sprintf("Print the %s version", c.commandName),
+		Long: fmt.Sprintf(`Print the %s version, and the deprecated plugins it provides with their
+replacement, e.g. to let tooling fail on the projects using them.
+`, c.commandName),
 		Example: fmt.Sprintf("%s version", c.commandName),
 		RunE: func(_ *cobra.Command, _ []string) error {
 			fmt.Println(c.version)
+			if deprecated := c.deprecatedPlugins(); len(deprecated) != 0 {
+				fmt.Println("Deprecated plugins:")
+				for _, line := range deprecated {
+					fmt.Printf("  %s\n", line)
+				}
+			}
 			return nil
 		},
 	}
 }
+
+// deprecatedPlugins returns the keys of the deprecated plugins registered in the cli followed by their
+// deprecation warning, sorted by key.
+func (c cli) deprecatedPlugins() []string {
+	var deprecated []string
+	for key, p := range c.plugins {
+		if d, isDeprecated := plugin.DeprecationOf(p); isDeprecated {
+			deprecated = append(deprecated, fmt.Sprintf("%s: %s", key, d))
+		}
+	}
+	sort.Strings(deprecated)
+	return deprecated
+}
@@ -37,6 +37,8 @@ func (c cli) newCreateWebhookCmd() *cobra.Command {
 		),
 	}
 
+	bindPluginsFlag(cmd)
+
 	// Lookup the plugin for projectVersion and bind it to the command.
 	c.bindCreateWebhook(ctx, cmd)
 	return cmd
@@ -84,10 +86,7 @@ func (c cli) bindCreateWebhook(ctx plugin.Context, cmd *cobra.Command) {
 
 	subcommand := createWebhookPlugin.GetCreateWebhookSubcommand()
 	subcommand.InjectConfig(&cfg.Config)
-	subcommand.BindFlags(cmd.Flags())
-	subcommand.UpdateContext(&ctx)
-	cmd.Long = ctx.Description
-	cmd.Example = ctx.Examples
+	bindSubcommand(cmd, createWebhookPlugin, subcommand, ctx)
 	cmd.RunE = runECmdFunc(cfg, subcommand,
 		fmt.Sprintf("failed to create webhook with %q", plugin.KeyFor(createWebhookPlugin)))
 }
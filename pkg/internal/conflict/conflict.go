@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conflict decides how the scaffolded files that already exist are handled, and merges them.
+package conflict
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Strategy determines how a scaffolded file is handled when it already exists
+type Strategy string
+
+const (
+	// StrategyError fails before writing any file of the scaffold
+	StrategyError Strategy = "error"
+	// StrategySkip keeps the existing file
+	StrategySkip Strategy = "skip"
+	// StrategyOverwrite replaces the existing file
+	StrategyOverwrite Strategy = "overwrite"
+	// StrategySideFile keeps the existing file and writes the scaffolded one next to it, with the SideFileSuffix
+	StrategySideFile Strategy = "side-file"
+	// StrategyMerge merges the changes of the existing file and of the scaffolded one since the previous
+	// scaffold, recorded in the scaffold lock
+	StrategyMerge Strategy = "merge"
+)
+
+// SideFileSuffix is the suffix of the scaffolded files written next to the existing ones
+const SideFileSuffix = ".new"
+
+// Strategies are the supported strategies
+var Strategies = []Strategy{StrategyError, StrategySkip, StrategyOverwrite, StrategySideFile, StrategyMerge}
+
+var strategy = StrategyError
+
+// SetStrategy sets how the scaffolded files that already exist are handled
+func SetStrategy(s Strategy) {
+	strategy = s
+}
+
+// CurrentStrategy returns how the scaffolded files that already exist are handled
+func CurrentStrategy() Strategy {
+	return strategy
+}
+
+// ParseStrategy returns the strategy named s
+func ParseStrategy(s string) (Strategy, error) {
+	names := make([]string, 0, len(Strategies))
+	for _, supported := range Strategies {
+		if Strategy(s) == supported {
+			return supported, nil
+		}
+		names = append(names, fmt.Sprintf("%q", supported))
+	}
+	return "", fmt.Errorf("conflict strategy (%s) is invalid: may be one of %s", s, strings.Join(names, ", "))
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conflict
+
+import (
+	"strings"
+)
+
+// The labels of the sides of the conflicts
+const (
+	currentLabel  = "current"
+	baseLabel     = "previous scaffold"
+	scaffoldLabel = "new scaffold"
+)
+
+// Merge merges, line by line, the changes of current and of scaffolded since base, returning the merged content
+// and the number of conflicts. Each conflict is delimited by markers in the diff3 style of git, showing the lines
+// of current, of base and of scaffolded.
+func Merge(base, current, scaffolded string) (string, int) {
+	o, a, b := splitLines(base), splitLines(current), splitLines(scaffolded)
+	ma, mb := match(o, a), match(o, b)
+
+	var merged strings.Builder
+	conflicts := 0
+	i, ia, ib := 0, 0, 0
+	for i < len(o) || ia < len(a) || ib < len(b) {
+		// The lines of base kept by both sides are stable
+		if i < len(o) && ma[i] == ia && mb[i] == ib {
+			merged.WriteString(o[i])
+			i, ia, ib = i+1, ia+1, ib+1
+			continue
+		}
+
+		// The unstable chunk ends at the next stable line
+		j, ja, jb := i, len(a), len(b)
+		for ; j < len(o); j++ {
+			if ma[j] != -1 && mb[j] != -1 {
+				ja, jb = ma[j], mb[j]
+				break
+			}
+		}
+		chunkO, chunkA, chunkB := o[i:j], a[ia:ja], b[ib:jb]
+		switch {
+		case equal(chunkA, chunkO):
+			writeLines(&merged, chunkB)
+		case equal(chunkB, chunkO), equal(chunkA, chunkB):
+			writeLines(&merged, chunkA)
+		default:
+			conflicts++
+			writeMarker(&merged, "<<<<<<< ", currentLabel)
+			writeSide(&merged, chunkA)
+			writeMarker(&merged, "||||||| ", baseLabel)
+			writeSide(&merged, chunkO)
+			writeMarker(&merged, "=======", "")
+			writeSide(&merged, chunkB)
+			writeMarker(&merged, ">>>>>>> ", scaffoldLabel)
+		}
+		i, ia, ib = j, ja, jb
+	}
+	return merged.String(), conflicts
+}
+
+// splitLines splits s into lines, keeping their line feed
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	// The last line of s is empty if s ends with a line feed
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// match returns the index of the line of other matched by each line of base in their longest common subsequence,
+// -1 for the lines of base that are not in other
+func match(base, other []string) []int {
+	// lengths[i][j] is the length of the longest common subsequence of base[i:] and other[j:]
+	lengths := make([][]int, len(base)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(other)+1)
+	}
+	for i := len(base) - 1; i >= 0; i-- {
+		for j := len(other) - 1; j >= 0; j-- {
+			switch {
+			case base[i] == other[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	matches := make([]int, len(base))
+	i, j := 0, 0
+	for i < len(base) {
+		switch {
+		case j < len(other) && base[i] == other[j]:
+			matches[i] = j
+			i, j = i+1, j+1
+		case j < len(other) && lengths[i+1][j] < lengths[i][j+1]:
+			j++
+		default:
+			matches[i] = -1
+			i++
+		}
+	}
+	return matches
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(w *strings.Builder, lines []string) {
+	for _, line := range lines {
+		w.WriteString(line)
+	}
+}
+
+// writeSide writes the lines of a side of a conflict, ending the last one with the line feed the marker following
+// it needs
+func writeSide(w *strings.Builder, lines []string) {
+	writeLines(w, lines)
+	if n := len(lines); n != 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		w.WriteString("\n")
+	}
+}
+
+func writeMarker(w *strings.Builder, marker, label string) {
+	w.WriteString(marker)
+	w.WriteString(label)
+	w.WriteString("\n")
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conflict
+
+import (
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\t// TODO(user): print\n}\n"
+
+	for _, tc := range []struct {
+		name       string
+		base       string
+		current    string
+		scaffolded string
+		merged     string
+		conflicts  int
+	}{
+		{
+			name:       "unchanged file",
+			current:    base,
+			scaffolded: "package main\n\nimport \"os\"\n\nfunc main() {\n\t// TODO(user): print\n}\n",
+			merged:     "package main\n\nimport \"os\"\n\nfunc main() {\n\t// TODO(user): print\n}\n",
+		},
+		{
+			name:       "unchanged scaffold",
+			current:    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println()\n}\n",
+			scaffolded: base,
+			merged:     "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println()\n}\n",
+		},
+		{
+			name:       "changes of both sides",
+			current:    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println()\n}\n",
+			scaffolded: "// Package main\npackage main\n\nimport \"fmt\"\n\nfunc main() {\n\t// TODO(user): print\n}\n",
+			merged:     "// Package main\npackage main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println()\n}\n",
+		},
+		{
+			name:       "same change of both sides",
+			current:    "package main\n\nimport \"os\"\n\nfunc main() {\n\t// TODO(user): print\n}\n",
+			scaffolded: "package main\n\nimport \"os\"\n\nfunc main() {\n\t// TODO(user): print\n}\n",
+			merged:     "package main\n\nimport \"os\"\n\nfunc main() {\n\t// TODO(user): print\n}\n",
+		},
+		{
+			name:       "conflicting changes",
+			current:    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println()\n}\n",
+			scaffolded: "package main\n\nimport \"fmt\"\n\nfunc main() {\n\t// TODO(user): print the greeting\n}\n",
+			merged: "package main\n\nimport \"fmt\"\n\nfunc main() {\n" +
+				"<<<<<<< current\n\tfmt.Println()\n" +
+				"||||||| previous scaffold\n\t// TODO(user): print\n" +
+				"=======\n\t// TODO(user): print the greeting\n" +
+				">>>>>>> new scaffold\n}\n",
+			conflicts: 1,
+		},
+		{
+			name:       "missing final line feed",
+			base:       "a\n",
+			current:    "a\nb",
+			scaffolded: "a\nc\n",
+			merged:     "a\n<<<<<<< current\nb\n||||||| previous scaffold\n=======\nc\n>>>>>>> new scaffold\n",
+			conflicts:  1,
+		},
+	} {
+		b := tc.base
+		if b == "" {
+			b = base
+		}
+		merged, conflicts := Merge(b, tc.current, tc.scaffolded)
+		if merged != tc.merged || conflicts != tc.conflicts {
+			t.Errorf("%s: expected %d conflict(s) and\n%s\ngot %d and\n%s", tc.name, tc.conflicts, tc.merged,
+				conflicts, merged)
+		}
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	for _, s := range Strategies {
+		if parsed, err := ParseStrategy(string(s)); err != nil || parsed != s {
+			t.Errorf("%s: expected the strategy to be parsed, got %q and %v", s, parsed, err)
+		}
+	}
+	if _, err := ParseStrategy("theirs"); err == nil {
+		t.Error("expected an unknown strategy to be rejected")
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package debug provides diagnostic output for the CLI, written to stderr only when enabled.
+package debug
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// Level determines which log messages are written
+type Level int
+
+const (
+	// LevelOff disables log messages
+	LevelOff Level = iota
+	// LevelVerbose writes messages about file writes, marker insertions and external commands
+	LevelVerbose
+	// LevelTrace additionally writes messages about every template render
+	LevelTrace
+)
+
+var (
+	enabled bool
+	level             = LevelOff
+	output  io.Writer = os.Stderr
+)
+
+// SetEnabled turns the debug output on or off
+func SetEnabled(flag bool) {
+	enabled = flag
+}
+
+// Enabled returns true if the debug output is on
+func Enabled() bool {
+	return enabled
+}
+
+// SetLevel sets the level of the log messages that are written
+func SetLevel(l Level) {
+	level = l
+}
+
+// Printf writes a debug message if the debug output is on
+func Printf(format string, args ...interface{}) {
+	if !enabled {
+		return
+	}
+	_, _ = fmt.Fprintf(output, "[debug] "+format+"\n", args...)
+}
+
+// Since writes a debug message with the time elapsed since start, usually called as
+// `defer debug.Since(time.Now(), "operation")`
+func Since(start time.Time, operation string) {
+	Printf("%s took %v", operation, time.Since(start).Round(time.Millisecond))
+}
+
+// Verbosef writes a log message if the level is LevelVerbose or higher
+func Verbosef(format string, args ...interface{}) {
+	if level < LevelVerbose {
+		return
+	}
+	_, _ = fmt.Fprintf(output, "[verbose] "+format+"\n", args...)
+}
+
+// Tracef writes a log message if the level is LevelTrace
+func Tracef(format string, args ...interface{}) {
+	if level < LevelTrace {
+		return
+	}
+	_, _ = fmt.Fprintf(output, "[trace] "+format+"\n", args...)
+}
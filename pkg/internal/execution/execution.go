@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package execution decides whether the CLI executes the external commands of the plugins, such as make or go get.
+package execution
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Mode determines how the execution of the external commands is decided
+type Mode int
+
+const (
+	// ModeConfirm asks for a confirmation before executing a command when stdin is a terminal
+	ModeConfirm Mode = iota
+	// ModeYes executes the commands without asking for a confirmation
+	ModeYes
+	// ModeNone never executes the commands
+	ModeNone
+)
+
+// DefaultAllowed are the commands the CLI may execute unless another allowlist is set
+var DefaultAllowed = []string{"go", "make", "task", "just"}
+
+var (
+	mode                  = ModeConfirm
+	allowed               = DefaultAllowed
+	interactive           = isTerminal(os.Stdin)
+	input       io.Reader = os.Stdin
+	output      io.Writer = os.Stdout
+)
+
+// SetMode sets how the execution of the external commands is decided
+func SetMode(m Mode) {
+	mode = m
+}
+
+// SetAllowed sets the commands the CLI may execute, matched by the name of their binary
+func SetAllowed(commands []string) {
+	allowed = commands
+}
+
+// Decision is whether an external command is executed, and why
+type Decision struct {
+	Execute bool
+	Reason  string
+}
+
+// Decide returns whether the command is executed, asking for a confirmation in ModeConfirm if stdin is a terminal.
+// The commands that are not allowed are never executed.
+func Decide(command string, args ...string) Decision {
+	if mode == ModeNone {
+		return Decision{Reason: "--no-exec is set"}
+	}
+	if !isAllowed(command) {
+		return Decision{Reason: fmt.Sprintf("%q is not one of the allowed commands (%s), allow it with --allow-exec",
+			command, strings.Join(allowed, ", "))}
+	}
+	if mode == ModeYes {
+		return Decision{Execute: true, Reason: "--yes is set"}
+	}
+	if !interactive {
+		return Decision{Execute: true, Reason: "stdin is not a terminal"}
+	}
+	if confirm(strings.Join(append([]string{command}, args...), " ")) {
+		return Decision{Execute: true, Reason: "confirmed"}
+	}
+	return Decision{Reason: "declined"}
+}
+
+// isAllowed returns true if the binary of command is one of the allowed commands
+func isAllowed(command string) bool {
+	for _, a := range allowed {
+		if a == command {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm asks whether to execute command until the answer is yes or no, which is assumed at the end of the input
+func confirm(command string) bool {
+	reader := bufio.NewReader(input)
+	for {
+		_, _ = fmt.Fprintf(output, "Execute %q? [y/n] ", command)
+		text, err := reader.ReadString('\n')
+		switch strings.TrimSpace(text) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+		if err != nil {
+			_, _ = fmt.Fprintln(output)
+			return false
+		}
+	}
+}
+
+// isTerminal returns true if f is a character device, i.e. a terminal rather than a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package execution
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestDecide(t *testing.T) {
+	defer func(m Mode, a []string, i bool) {
+		mode, allowed, interactive = m, a, i
+	}(mode, allowed, interactive)
+	output = ioutil.Discard
+
+	for _, tc := range []struct {
+		name        string
+		mode        Mode
+		allowed     []string
+		interactive bool
+		answer      string
+		command     string
+		execute     bool
+	}{
+		{name: "non-interactive", mode: ModeConfirm, command: "make", execute: true},
+		{name: "yes", mode: ModeYes, interactive: true, command: "make", execute: true},
+		{name: "no-exec", mode: ModeNone, command: "make"},
+		{name: "not allowed", mode: ModeYes, command: "curl"},
+		{name: "not allowed by the allowlist", mode: ModeYes, allowed: []string{"go"}, command: "make"},
+		{name: "confirmed", mode: ModeConfirm, interactive: true, answer: "maybe\ny\n", command: "make", execute: true},
+		{name: "declined", mode: ModeConfirm, interactive: true, answer: "no\n", command: "make"},
+		{name: "end of input", mode: ModeConfirm, interactive: true, command: "make"},
+	} {
+		mode, allowed, interactive = tc.mode, DefaultAllowed, tc.interactive
+		if tc.allowed != nil {
+			allowed = tc.allowed
+		}
+		input = strings.NewReader(tc.answer)
+		if decision := Decide(tc.command); decision.Execute != tc.execute || decision.Reason == "" {
+			t.Errorf("%s: expected the execution of %q to be %t, got %+v", tc.name, tc.command, tc.execute, decision)
+		}
+	}
+}
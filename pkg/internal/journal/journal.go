@@ -0,0 +1,169 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package journal records the files changed by a command, so that they can be restored when it fails, or later
+// by kubebuilder undo.
+package journal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// File is the state of a file before its first change since Begin
+type File struct {
+	// Path is the path of the file, absolute while recording and relative to the project once saved
+	Path string `json:"path"`
+	// Existed is true if the file existed, with Contents and Mode
+	Existed  bool        `json:"existed,omitempty"`
+	Contents []byte      `json:"contents,omitempty"`
+	Mode     os.FileMode `json:"mode,omitempty"`
+	// Dirs are the directories created for the file, the deepest first
+	Dirs []string `json:"dirs,omitempty"`
+	// Checksum is the checksum of the file once changed, empty if it was removed
+	Checksum string `json:"checksum,omitempty"`
+}
+
+var (
+	mu sync.Mutex
+	// depth is the number of transactions begun and not committed yet, the files are recorded while it is not 0
+	depth    int
+	files    []File
+	recorded map[string]bool
+)
+
+// Begin starts a transaction recording the changed files. The transactions begun by the outermost one are part
+// of it: the files are recorded until it is committed, and restored by any rollback.
+func Begin() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if depth == 0 {
+		files = nil
+		recorded = map[string]bool{}
+	}
+	depth++
+}
+
+// Commit commits the last transaction begun, keeping the changes. The files recorded by the outermost one are
+// kept until the next Begin, to be saved.
+func Commit() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if depth > 0 {
+		depth--
+	}
+}
+
+// Record records the state of path before it is changed. It does nothing outside of a transaction, or if path
+// was already recorded by it.
+func Record(path string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if depth == 0 {
+		return nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if recorded[abs] {
+		return nil
+	}
+
+	f := File{Path: abs}
+	info, err := os.Stat(abs)
+	switch {
+	case err == nil:
+		contents, err := ioutil.ReadFile(abs)
+		if err != nil {
+			return fmt.Errorf("error recording %s: %v", path, err)
+		}
+		f.Existed, f.Contents, f.Mode = true, contents, info.Mode()
+	case os.IsNotExist(err):
+		for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+			if _, err := os.Stat(dir); err == nil || dir == filepath.Dir(dir) {
+				break
+			}
+			f.Dirs = append(f.Dirs, dir)
+		}
+	default:
+		return fmt.Errorf("error recording %s: %v", path, err)
+	}
+
+	files = append(files, f)
+	recorded[abs] = true
+	return nil
+}
+
+// WriteFile records path and writes data to it, like ioutil.WriteFile
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if err := Record(path); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, perm)
+}
+
+// Remove records path and removes it, like os.Remove
+func Remove(path string) error {
+	if err := Record(path); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Rollback restores the files recorded by the outermost transaction, removing the created ones with their
+// directories, and ends it. It does nothing outside of a transaction.
+func Rollback() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if depth == 0 {
+		return nil
+	}
+	depth = 0
+	err := restore(files)
+	files = nil
+	return err
+}
+
+// restore restores files, in reverse order, with their absolute paths. It restores as many files as possible,
+// returning the first error.
+func restore(files []File) error {
+	var firstErr error
+	for i := len(files) - 1; i >= 0; i-- {
+		f := files[i]
+		var err error
+		if f.Existed {
+			err = ioutil.WriteFile(f.Path, f.Contents, f.Mode)
+		} else if err = os.Remove(f.Path); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("error restoring %s: %v", f.Path, err)
+		}
+		// The directories are only removed if empty, they may hold the other created files
+		for _, dir := range f.Dirs {
+			_ = os.Remove(dir)
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package journal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "main.go")
+	removed := filepath.Join(dir, "PROJECT")
+	created := filepath.Join(dir, "api", "v1", "types.go")
+	for path, contents := range map[string]string{existing: "package main\n", removed: "version: 3\n"} {
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	Begin()
+	if err := WriteFile(existing, []byte("package changed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// Only the state before the first change is recorded
+	if err := WriteFile(existing, []byte("package changed again\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+	if err := Record(created); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(created), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(created, []byte("package v1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, expected := range map[string]string{existing: "package main\n", removed: "version: 3\n"} {
+		if contents, err := ioutil.ReadFile(path); err != nil || string(contents) != expected {
+			t.Errorf("expected %s to be restored to %q, got %q (%v)", path, expected, contents, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "api")); !os.IsNotExist(err) {
+		t.Errorf("expected the created file and directories to be removed, got %v", err)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+
+	Begin()
+	if err := WriteFile(path, []byte("package main\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	Commit()
+	// The changes are not recorded once committed
+	if err := WriteFile(path, []byte("package changed\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if contents, err := ioutil.ReadFile(path); err != nil || string(contents) != "package changed\n" {
+		t.Errorf("expected the committed changes to be kept, got %q (%v)", contents, err)
+	}
+}
+
+func TestNestedTransactions(t *testing.T) {
+	dir := t.TempDir()
+	outer, inner := filepath.Join(dir, "PROJECT"), filepath.Join(dir, "main.go")
+
+	Begin()
+	if err := WriteFile(outer, []byte("version: 3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	Begin()
+	if err := WriteFile(inner, []byte("package main\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	// The inner transaction is part of the outer one, which still records the files
+	Commit()
+	if err := Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{outer, inner} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed by the rollback of the outer transaction, got %v", path, err)
+		}
+	}
+}
+
+func TestUndo(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+	if err := ioutil.WriteFile("PROJECT", []byte("version: 3\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile("main.go", []byte("package main\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	Begin()
+	for path, contents := range map[string]string{
+		"PROJECT":                 "version: 3\nresources: []\n",
+		"api/v1/frigat_types.go":  "package v1\n",
+		"main.go":                 "package main\n",
+		filepath.Join("api", "x"): "",
+	} {
+		if err := Record(path); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	Commit()
+	if err := Save(DefaultPath, "kubebuilder create api"); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := Load(DefaultPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Command != "kubebuilder create api" {
+		t.Errorf("expected the command to be saved, got %q", j.Command)
+	}
+	// The files that were written but not changed are not saved
+	if len(j.Files) != 3 {
+		t.Errorf("expected the 3 changed files to be saved, got %v", j.Files)
+	}
+	if modified, err := j.Modified(); err != nil || len(modified) != 0 {
+		t.Errorf("expected no file to be modified since the command, got %v (%v)", modified, err)
+	}
+	if err := ioutil.WriteFile("PROJECT", []byte("version: 3\nresources: null\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if modified, err := j.Modified(); err != nil || len(modified) != 1 || modified[0] != "PROJECT" {
+		t.Errorf("expected the PROJECT file to be modified since the command, got %v (%v)", modified, err)
+	}
+
+	if err := j.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if contents, err := ioutil.ReadFile("PROJECT"); err != nil || string(contents) != "version: 3\n" {
+		t.Errorf("expected the PROJECT file to be restored, got %q (%v)", contents, err)
+	}
+	if _, err := os.Stat("api"); !os.IsNotExist(err) {
+		t.Errorf("expected the created files and directories to be removed, got %v", err)
+	}
+}
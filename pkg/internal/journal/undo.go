@@ -0,0 +1,158 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// DefaultPath is the path of the journal of the last command of a project
+var DefaultPath = filepath.Join(".kubebuilder", "undo.json")
+
+// Journal is the saved record of the files changed by a command
+type Journal struct {
+	// Command is the command that changed the files
+	Command string `json:"command"`
+	// Files are the states of the files before the command, with their paths relative to the project
+	Files []File `json:"files"`
+}
+
+// Save saves the files changed since the last transaction began to path, as the journal of command, replacing
+// the previous one. It does nothing if no file was changed, e.g. because the transaction was rolled back, so that
+// the journal of the previous command is kept.
+func Save(path, command string) error {
+	mu.Lock()
+	recordedFiles := files
+	mu.Unlock()
+
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	saved := make([]File, 0, len(recordedFiles))
+	for _, f := range recordedFiles {
+		sum, err := checksum(f.Path)
+		if err != nil {
+			return err
+		}
+		if sum == previousChecksum(f) {
+			continue
+		}
+		f.Checksum = sum
+		if f.Path, err = filepath.Rel(dir, f.Path); err != nil {
+			return err
+		}
+		dirs := make([]string, 0, len(f.Dirs))
+		for _, d := range f.Dirs {
+			if d, err = filepath.Rel(dir, d); err != nil {
+				return err
+			}
+			dirs = append(dirs, d)
+		}
+		f.Dirs = dirs
+		saved = append(saved, f)
+	}
+	if len(saved) == 0 {
+		return nil
+	}
+
+	content, err := json.Marshal(Journal{Command: command, Files: saved})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+// Load loads the journal saved to path
+func Load(path string) (*Journal, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var j Journal
+	if err := json.Unmarshal(content, &j); err != nil {
+		return nil, fmt.Errorf("error reading the journal %s: %v", path, err)
+	}
+	return &j, nil
+}
+
+// Modified returns the paths of the files changed since the command
+func (j Journal) Modified() ([]string, error) {
+	var modified []string
+	for _, f := range j.Files {
+		sum, err := checksum(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		if sum != f.Checksum {
+			modified = append(modified, f.Path)
+		}
+	}
+	return modified, nil
+}
+
+// Undo restores the files to their state before the command, relative to the current directory
+func (j Journal) Undo() error {
+	abs := make([]File, 0, len(j.Files))
+	for _, f := range j.Files {
+		var err error
+		if f.Path, err = filepath.Abs(f.Path); err != nil {
+			return err
+		}
+		dirs := make([]string, 0, len(f.Dirs))
+		for _, d := range f.Dirs {
+			if d, err = filepath.Abs(d); err != nil {
+				return err
+			}
+			dirs = append(dirs, d)
+		}
+		f.Dirs = dirs
+		abs = append(abs, f)
+	}
+	return restore(abs)
+}
+
+// previousChecksum returns the checksum of the contents of f before the command, empty if it did not exist
+func previousChecksum(f File) string {
+	if !f.Existed {
+		return ""
+	}
+	sum := sha256.Sum256(f.Contents)
+	return hex.EncodeToString(sum[:])
+}
+
+// checksum returns the checksum of the contents of path, empty if it does not exist
+func checksum(path string) (string, error) {
+	contents, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,313 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package markers holds the documentation of the markers used in the scaffolded code, which is used both
+// to print it with "kubebuilder explain marker" and to comment the markers emitted by the templates.
+package markers
+
+import (
+	"sort"
+	"strings"
+)
+
+const bookURL = "https://book.kubebuilder.io/reference/markers"
+
+// Marker documents a marker processed by controller-gen or kubebuilder.
+type Marker struct {
+	// Name is the name of the marker without its leading "+" nor its arguments, e.g. kubebuilder:rbac.
+	Name string
+	// Usage is an example of the marker with its arguments.
+	Usage string
+	// Summary is a one line description of the marker, used to comment the scaffolded markers.
+	Summary string
+	// Description details what the marker does.
+	Description string
+	// URL is the canonical documentation of the marker.
+	URL string
+}
+
+var catalog = []Marker{
+	{
+		Name:        "groupName",
+		Usage:       "+groupName=ship.example.org",
+		Summary:     "Sets the API group of the types of the package.",
+		Description: "Package-level marker setting the API group of the CRDs generated from the types of the package.",
+		URL:         bookURL + "/crd.html",
+	},
+	{
+		Name:    "kubebuilder:object:generate",
+		Usage:   "+kubebuilder:object:generate=true",
+		Summary: "Generates the DeepCopy methods of the types.",
+		Description: "Package or type-level marker enabling or disabling the generation of the DeepCopy, " +
+			"DeepCopyInto and DeepCopyObject methods by controller-gen object.",
+		URL: bookURL + "/object.html",
+	},
+	{
+		Name:    "kubebuilder:object:root",
+		Usage:   "+kubebuilder:object:root=true",
+		Summary: "Marks the type as a root object, which implements runtime.Object.",
+		Description: "Type-level marker generating the DeepCopyObject method of the type so that it implements " +
+			"runtime.Object. It is required on the type of a kind and on its List type.",
+		URL: bookURL + "/object.html",
+	},
+	{
+		Name:    "kubebuilder:subresource:status",
+		Usage:   "+kubebuilder:subresource:status",
+		Summary: "Enables the status subresource of the CRD.",
+		Description: "Type-level marker enabling the status subresource: the status is then ignored by the " +
+			"updates of the object and updated with the status client of the controllers.",
+		URL: bookURL + "/crd.html",
+	},
+	{
+		Name: "kubebuilder:subresource:scale",
+		Usage: "+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas," +
+			"selectorpath=.status.selector",
+		Summary: "Enables the scale subresource of the CRD.",
+		Description: "Type-level marker enabling the scale subresource, used by kubectl scale and the " +
+			"HorizontalPodAutoscaler, from the replicas and selector fields of the kind.",
+		URL: bookURL + "/crd.html",
+	},
+	{
+		Name:    "kubebuilder:resource",
+		Usage:   "+kubebuilder:resource:scope=Cluster,shortName=fr,categories=fleet",
+		Summary: "Configures the resource of the CRD, e.g. its scope.",
+		Description: "Type-level marker configuring the resource of the CRD: its scope (Namespaced or Cluster), " +
+			"path (plural), singular name, short names and categories.",
+		URL: bookURL + "/crd.html",
+	},
+	{
+		Name:    "kubebuilder:printcolumn",
+		Usage:   `+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=".status.conditions[?(@.type=='Ready')].status"`,
+		Summary: "Adds a column to the output of kubectl get.",
+		Description: "Type-level marker adding an additional printer column to the CRD, displayed by " +
+			"kubectl get.",
+		URL: bookURL + "/crd.html",
+	},
+	{
+		Name:    "kubebuilder:storageversion",
+		Usage:   "+kubebuilder:storageversion",
+		Summary: "Marks the version as the storage version of the CRD.",
+		Description: "Type-level marker selecting the version in which the objects are stored, required when a " +
+			"kind has several versions. Run \"kubebuilder alpha storage-versions\" to audit the versions.",
+		URL: bookURL + "/crd.html",
+	},
+	{
+		Name:        "kubebuilder:unservedversion",
+		Usage:       "+kubebuilder:unservedversion",
+		Summary:     "Stops serving the version of the CRD.",
+		Description: "Type-level marker keeping the version in the CRD without serving it.",
+		URL:         bookURL + "/crd.html",
+	},
+	{
+		Name:    "kubebuilder:deprecatedversion",
+		Usage:   `+kubebuilder:deprecatedversion:warning="ship.example.org/v1beta1 Frigate is deprecated"`,
+		Summary: "Marks the version of the CRD as deprecated.",
+		Description: "Type-level marker deprecating the version: the API server returns a warning to the " +
+			"clients using it.",
+		URL: bookURL + "/crd.html",
+	},
+	{
+		Name:    "kubebuilder:validation:Optional",
+		Usage:   "+kubebuilder:validation:Optional",
+		Summary: "Makes the field optional.",
+		Description: "Field or package-level marker making the fields optional, which they are by default " +
+			"when their json tag has omitempty.",
+		URL: bookURL + "/crd-validation.html",
+	},
+	{
+		Name:        "kubebuilder:validation:Required",
+		Usage:       "+kubebuilder:validation:Required",
+		Summary:     "Makes the field required.",
+		Description: "Field or package-level marker making the fields required.",
+		URL:         bookURL + "/crd-validation.html",
+	},
+	{
+		Name:        "kubebuilder:validation:Minimum",
+		Usage:       "+kubebuilder:validation:Minimum=1",
+		Summary:     "Sets the minimum value of a number.",
+		Description: "Field-level marker setting the minimum value of a numeric field.",
+		URL:         bookURL + "/crd-validation.html",
+	},
+	{
+		Name:        "kubebuilder:validation:Maximum",
+		Usage:       "+kubebuilder:validation:Maximum=10",
+		Summary:     "Sets the maximum value of a number.",
+		Description: "Field-level marker setting the maximum value of a numeric field.",
+		URL:         bookURL + "/crd-validation.html",
+	},
+	{
+		Name:        "kubebuilder:validation:MinLength",
+		Usage:       "+kubebuilder:validation:MinLength=1",
+		Summary:     "Sets the minimum length of a string.",
+		Description: "Field-level marker setting the minimum length of a string field.",
+		URL:         bookURL + "/crd-validation.html",
+	},
+	{
+		Name:    "kubebuilder:validation:MaxLength",
+		Usage:   "+kubebuilder:validation:MaxLength=63",
+		Summary: "Sets the maximum length of a string.",
+		Description: "Field-level marker setting the maximum length of a string field, which bounds the cost " +
+			"of the validation rules using it.",
+		URL: bookURL + "/crd-validation.html",
+	},
+	{
+		Name:        "kubebuilder:validation:MinItems",
+		Usage:       "+kubebuilder:validation:MinItems=1",
+		Summary:     "Sets the minimum number of items of a list.",
+		Description: "Field-level marker setting the minimum number of items of a list field.",
+		URL:         bookURL + "/crd-validation.html",
+	},
+	{
+		Name:    "kubebuilder:validation:MaxItems",
+		Usage:   "+kubebuilder:validation:MaxItems=10",
+		Summary: "Sets the maximum number of items of a list.",
+		Description: "Field-level marker setting the maximum number of items of a list field, which bounds " +
+			"the cost of the validation rules iterating over it.",
+		URL: bookURL + "/crd-validation.html",
+	},
+	{
+		Name:    "kubebuilder:validation:MaxProperties",
+		Usage:   "+kubebuilder:validation:MaxProperties=10",
+		Summary: "Sets the maximum number of entries of a map.",
+		Description: "Field-level marker setting the maximum number of entries of a map field, which bounds " +
+			"the cost of the validation rules iterating over it.",
+		URL: bookURL + "/crd-validation.html",
+	},
+	{
+		Name:        "kubebuilder:validation:Pattern",
+		Usage:       `+kubebuilder:validation:Pattern="^[a-z]+$"`,
+		Summary:     "Sets the regular expression a string must match.",
+		Description: "Field-level marker setting the regular expression the value of a string field must match.",
+		URL:         bookURL + "/crd-validation.html",
+	},
+	{
+		Name:    "kubebuilder:validation:Enum",
+		Usage:   "+kubebuilder:validation:Enum=Always;Never",
+		Summary: "Sets the values a field may have.",
+		Description: "Field or type-level marker listing the values allowed for the field, separated by " +
+			"semicolons.",
+		URL: bookURL + "/crd-validation.html",
+	},
+	{
+		Name: "kubebuilder:validation:XValidation",
+		Usage: `+kubebuilder:validation:XValidation:rule="self.minReplicas <= self.maxReplicas",` +
+			`message="minReplicas must not exceed maxReplicas"`,
+		Summary: "Adds a CEL validation rule.",
+		Description: "Field or type-level marker adding a CEL rule to the x-kubernetes-validations of the " +
+			"schema, evaluated by the API server. Bound the lists, maps and strings the rule iterates over " +
+			"to stay within the CEL cost budget.",
+		URL: bookURL + "/crd-validation.html",
+	},
+	{
+		Name:        "kubebuilder:default",
+		Usage:       "+kubebuilder:default=1",
+		Summary:     "Sets the default value of the field.",
+		Description: "Field-level marker setting the value the API server defaults the field to.",
+		URL:         bookURL + "/crd-validation.html",
+	},
+	{
+		Name:    "kubebuilder:pruning:PreserveUnknownFields",
+		Usage:   "+kubebuilder:pruning:PreserveUnknownFields",
+		Summary: "Keeps the fields unknown to the schema.",
+		Description: "Field or type-level marker disabling the pruning of the fields that are not in the " +
+			"schema, e.g. for embedded objects of arbitrary types.",
+		URL: bookURL + "/crd-processing.html",
+	},
+	{
+		Name:    "kubebuilder:validation:Schemaless",
+		Usage:   "+kubebuilder:validation:Schemaless",
+		Summary: "Generates no schema for the field.",
+		Description: "Field-level marker skipping the schema of the field, which reduces the size of the CRD " +
+			"for large embedded types.",
+		URL: bookURL + "/crd-processing.html",
+	},
+	{
+		Name:    "kubebuilder:rbac",
+		Usage:   "+kubebuilder:rbac:groups=ship.example.org,resources=frigates,verbs=get;list;watch",
+		Summary: "Grants the manager a permission.",
+		Description: "Package-level marker adding a rule to the ClusterRole of the manager, or to a Role " +
+			"with the namespace argument.",
+		URL: bookURL + "/rbac.html",
+	},
+	{
+		Name: "kubebuilder:webhook",
+		Usage: "+kubebuilder:webhook:path=/mutate-ship-example-org-v1-frigate,mutating=true,failurePolicy=fail," +
+			"groups=ship.example.org,resources=frigates,verbs=create;update,versions=v1," +
+			"name=mfrigate.kb.io,admissionReviewVersions={v1,v1beta1}",
+		Summary: "Registers an admission webhook.",
+		Description: "Package-level marker adding a webhook to the MutatingWebhookConfiguration or " +
+			"ValidatingWebhookConfiguration of the manager.",
+		URL: bookURL + "/webhook.html",
+	},
+	{
+		Name:    "kubebuilder:scaffold",
+		Usage:   "+kubebuilder:scaffold:imports",
+		Summary: "Marks where kubebuilder inserts scaffolded code.",
+		Description: "Marker used by kubebuilder itself to insert the code of the new APIs and webhooks in " +
+			"the existing files. Keep it in place.",
+		URL: "https://book.kubebuilder.io/reference/markers.html",
+	},
+}
+
+// All returns the markers of the catalog sorted by name.
+func All() []Marker {
+	markers := append([]Marker(nil), catalog...)
+	sort.Slice(markers, func(i, j int) bool { return markers[i].Name < markers[j].Name })
+	return markers
+}
+
+// Lookup returns the documentation of the marker, which may be written with its leading "+" and its
+// arguments, e.g. +kubebuilder:rbac:groups=core,resources=events,verbs=create.
+func Lookup(marker string) (Marker, bool) {
+	name := strings.TrimPrefix(strings.TrimSpace(marker), "+")
+	if i := strings.Index(name, "="); i != -1 {
+		name = name[:i]
+	}
+	// The arguments of some markers are separated from their name by a colon, e.g. kubebuilder:resource:scope
+	for name != "" {
+		for _, m := range catalog {
+			if strings.EqualFold(m.Name, name) {
+				return m, true
+			}
+		}
+		i := strings.LastIndex(name, ":")
+		if i == -1 {
+			break
+		}
+		name = name[:i]
+	}
+	return Marker{}, false
+}
+
+// Format returns the Go comments of the markers, e.g. kubebuilder:object:root=true, one per line. When docs
+// is true, the first marker of each name is preceded by the summary and the URL of its documentation, so that
+// their meaning is a click away in editors and language servers. The empty values are skipped, so that the
+// templates pass their optional markers unconditionally.
+func Format(docs bool, values ...string) string {
+	lines := make([]string, 0, len(values))
+	documented := map[string]bool{}
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		if m, found := Lookup(value); docs && found && !documented[m.Name] {
+			lines = append(lines, "// "+m.Summary, "// See "+m.URL)
+			documented[m.Name] = true
+		}
+		lines = append(lines, "//+"+value)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package markers
+
+import (
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for _, tc := range []struct {
+		marker, expected string
+	}{
+		{marker: "kubebuilder:object:root", expected: "kubebuilder:object:root"},
+		{marker: "+kubebuilder:object:root=true", expected: "kubebuilder:object:root"},
+		{marker: "+kubebuilder:resource:scope=Cluster", expected: "kubebuilder:resource"},
+		{marker: "kubebuilder:rbac:groups=core,resources=events,verbs=create", expected: "kubebuilder:rbac"},
+		{marker: "+kubebuilder:validation:maxitems=3", expected: "kubebuilder:validation:MaxItems"},
+		{marker: "+kubebuilder:validation:Format=date-time"},
+		{marker: "+optional"},
+	} {
+		m, found := Lookup(tc.marker)
+		if found != (tc.expected != "") || m.Name != tc.expected {
+			t.Errorf("expected %q to be found as %q, got %q (found: %t)", tc.marker, tc.expected, m.Name, found)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	values := []string{
+		"kubebuilder:rbac:groups=core,resources=events,verbs=create;patch",
+		"kubebuilder:rbac:groups=core,resources=configmaps,verbs=get",
+		"",
+		"kubebuilder:validation:Format=date-time",
+	}
+
+	expected := `//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get
+//+kubebuilder:validation:Format=date-time`
+	if formatted := Format(false, values...); formatted != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, formatted)
+	}
+
+	expected = `// Grants the manager a permission.
+// See https://book.kubebuilder.io/reference/markers/rbac.html
+` + expected
+	if formatted := Format(true, values...); formatted != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, formatted)
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	names := map[string]bool{}
+	for _, m := range All() {
+		if names[m.Name] {
+			t.Errorf("marker %q is documented twice", m.Name)
+		}
+		names[m.Name] = true
+		if m.Usage == "" || m.Summary == "" || m.Description == "" || m.URL == "" {
+			t.Errorf("marker %q is not fully documented: %+v", m.Name, m)
+		}
+		if found, _ := Lookup(m.Usage); found.Name != m.Name {
+			t.Errorf("usage %q of marker %q is found as %q", m.Usage, m.Name, found.Name)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package config
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"sigs.k8s.io/yaml"
@@ -29,6 +30,26 @@ const (
 	Version3Alpha = "3-alpha"
 )
 
+// IP families of the clusters the manager is deployed to
+const (
+	// IPFamilyDualStack binds the manager to the addresses of both families, the default
+	IPFamilyDualStack = "dual-stack"
+	// IPFamilyIPv6 binds the manager to the IPv6 addresses, for the IPv6-only clusters
+	IPFamilyIPv6 = "ipv6"
+)
+
+// Ways main.go is wired by create api and create webhook
+const (
+	// WiringMarkers inserts the code of the new resources at the scaffold markers of main.go, the default
+	WiringMarkers = "markers"
+	// WiringAST inserts it at the positions found in the syntax tree of main.go, which may be reformatted
+	// or refactored without its markers
+	WiringAST = "ast"
+)
+
+// DeprecationDateLayout is the layout of the removal dates of the deprecated versions
+const DeprecationDateLayout = "2006-01-02"
+
 // Config is the unmarshalled representation of the configuration file
 type Config struct {
 	// Version is the project version, defaults to "1" (backwards compatibility)
@@ -50,10 +71,74 @@ type Config struct {
 	// Multigroup tracks if the project has more than one group
 	MultiGroup bool `json:"multigroup,omitempty"`
 
+	// GroupRegistration tracks if the versions of each group of a multigroup
+	// project are added to the scheme by a registration package of the group
+	GroupRegistration bool `json:"groupRegistration,omitempty"`
+
 	// ComponentConfig tracks if the project uses a config file for configuring
 	// the ctrl.Manager
 	ComponentConfig bool `json:"componentConfig,omitempty"`
 
+	// FeatureGates tracks if the project gates its features behind the
+	// --feature-gates flag of the manager
+	FeatureGates bool `json:"featureGates,omitempty"`
+
+	// MarkerDocs tracks if the markers of the scaffolded code are preceded
+	// by a comment linking to their documentation
+	MarkerDocs bool `json:"markerDocs,omitempty"`
+
+	// MultiCluster tracks if the controllers reconcile the objects of the remote
+	// clusters listed in the cluster registry of the manager
+	MultiCluster bool `json:"multiCluster,omitempty"`
+
+	// Sharding tracks if the objects of the resources are spread across several
+	// managers, the shards, each one reconciling the objects of its own shard
+	Sharding bool `json:"sharding,omitempty"`
+
+	// Agent tracks if the manager can run as an agent reconciling the objects of
+	// its own namespace, and the workload of the agent, deployment or daemonset
+	Agent string `json:"agent,omitempty"`
+
+	// CertProvider tracks the provider of the serving certificates of the webhooks,
+	// cert-manager if empty
+	CertProvider string `json:"certProvider,omitempty"`
+
+	// IPFamily tracks the IP family of the clusters the manager is deployed to, such as ipv6 for the
+	// IPv6-only clusters, dual-stack if empty
+	IPFamily string `json:"ipFamily,omitempty"`
+
+	// Wiring tracks how the code of the new resources is inserted in main.go, such as ast to locate its
+	// positions in the syntax tree of the file (experimental), at the scaffold markers if empty
+	Wiring string `json:"wiring,omitempty"`
+
+	// Namespace tracks the namespace the manager is installed in, the name of
+	// the project suffixed with -system if empty
+	Namespace string `json:"namespace,omitempty"`
+
+	// ManifestsOnly tracks if the project only holds the manifests of an operator
+	// whose Go code lives in another repository
+	ManifestsOnly bool `json:"manifestsOnly,omitempty"`
+
+	// Pattern tracks the alternative layout the project was initialized with,
+	// such as aggregated-apiserver, the operator layout if empty
+	Pattern string `json:"pattern,omitempty"`
+
+	// TaskRunner tracks the task runner defining the targets of the project, such as
+	// task or just, make if empty
+	TaskRunner string `json:"taskRunner,omitempty"`
+
+	// Replace tracks the replace directives of go.mod, written as the -replace flag of go mod
+	// edit, old[@v]=new[@v], which are applied to go.mod whenever it is scaffolded
+	Replace []string `json:"replace,omitempty"`
+
+	// CreateAPI tracks the default answers of the prompts of create api, which
+	// only prompts for the ones that are not set
+	CreateAPI *CreateAPIDefaults `json:"createAPI,omitempty"`
+
+	// MinKubernetesVersion tracks the oldest Kubernetes version, e.g. 1.15, supported
+	// by the project, whose manifests and webhooks are compatible with it
+	MinKubernetesVersion string `json:"minKubernetesVersion,omitempty"`
+
 	// Layout contains a key specifying which plugin created a project.
 	Layout string `json:"layout,omitempty"`
 
@@ -62,6 +147,15 @@ type Config struct {
 	Plugins PluginConfigs `json:"plugins,omitempty"`
 }
 
+// CreateAPIDefaults holds the default answers of the prompts of create api, overridden by its flags
+type CreateAPIDefaults struct {
+	// Resource answers whether to scaffold the resource, prompted if nil
+	Resource *bool `json:"resource,omitempty"`
+
+	// Controller answers whether to scaffold the controller, prompted if nil
+	Controller *bool `json:"controller,omitempty"`
+}
+
 // PluginConfigs holds a set of arbitrary plugin configuration objects mapped by plugin key.
 type PluginConfigs map[string]pluginConfig
 
@@ -78,6 +172,19 @@ func (c Config) IsV3() bool {
 	return c.Version == Version3Alpha
 }
 
+// GetNamespace returns the namespace the manager is installed in
+func (c Config) GetNamespace() string {
+	if c.Namespace != "" {
+		return c.Namespace
+	}
+	return DefaultNamespace(c.ProjectName)
+}
+
+// DefaultNamespace returns the namespace the manager of the project named projectName is installed in by default
+func DefaultNamespace(projectName string) string {
+	return projectName + "-system"
+}
+
 // GetResource returns the GKV if the resource is found
 func (c Config) GetResource(target ResourceData) *ResourceData {
 	// Return true if the target resource is found in the tracked resources
@@ -117,6 +224,17 @@ func (c Config) HasGroup(group string) bool {
 	return false
 }
 
+// GetGroupPackage returns the package name provided for the group by any of the tracked resources
+func (c Config) GetGroupPackage(group string) string {
+	for _, r := range c.Resources {
+		if r.Group == group && r.GroupPackage != "" {
+			return r.GroupPackage
+		}
+	}
+
+	return ""
+}
+
 // HasWebhook returns true if webhook is already present
 func (c Config) HasWebhook(resource ResourceData) bool {
 	for _, r := range c.Resources {
@@ -128,6 +246,36 @@ func (c Config) HasWebhook(resource ResourceData) bool {
 	return false
 }
 
+// ParseKubernetesVersion parses a Kubernetes version such as 1.15, v1.15 or 1.15.3 into its major and minor versions
+func ParseKubernetesVersion(version string) (major, minor int, err error) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, 0, fmt.Errorf("invalid Kubernetes version %q, expected MAJOR.MINOR", version)
+	}
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, 0, fmt.Errorf("invalid Kubernetes version %q, expected MAJOR.MINOR", version)
+		}
+		numbers[i] = n
+	}
+	return numbers[0], numbers[1], nil
+}
+
+// SupportsKubernetesBefore returns true if the minimum Kubernetes version of the project is set and older
+// than major.minor. The projects that do not set it, or set an invalid one, target the current versions.
+func (c Config) SupportsKubernetesBefore(major, minor int) bool {
+	if c.MinKubernetesVersion == "" {
+		return false
+	}
+	minMajor, minMinor, err := ParseKubernetesVersion(c.MinKubernetesVersion)
+	if err != nil {
+		return false
+	}
+	return minMajor < major || (minMajor == major && minMinor < minor)
+}
+
 // IsCRDVersionCompatible returns true if crdVersion can be added to the existing set of CRD versions.
 func (c Config) IsCRDVersionCompatible(crdVersion string) bool {
 	return c.resourceAPIVersionCompatible("crd", crdVersion)
@@ -166,6 +314,9 @@ type ResourceData struct {
 	Version string `json:"version,omitempty"`
 	Kind    string `json:"kind,omitempty"`
 
+	// GroupPackage holds the package name of the Group if it was provided by the user
+	GroupPackage string `json:"groupPackage,omitempty"`
+
 	// API holds the API data
 	API *API `json:"api,omitempty"`
 
@@ -177,12 +328,54 @@ type ResourceData struct {
 type API struct {
 	// CRDVersion holds the CustomResourceDefinition API version used for the ResourceData.
 	CRDVersion string `json:"crdVersion,omitempty"`
+	// Deprecation holds the deprecation of the version, nil if it is not deprecated.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+}
+
+// Deprecation contains information about the deprecation of a version of an API, recorded by the
+// alpha deprecate-version command.
+type Deprecation struct {
+	// Warning is the warning returned by the API server to the clients of the version.
+	Warning string `json:"warning,omitempty"`
+	// Replacement is the version of the kind replacing the deprecated one.
+	Replacement string `json:"replacement,omitempty"`
+	// RemoveAfter is the date, formatted with DeprecationDateLayout, after which the version may stop being
+	// served.
+	RemoveAfter string `json:"removeAfter,omitempty"`
 }
 
 // Webhooks contains information about scaffolded webhooks
 type Webhooks struct {
 	// WebhookVersion holds the {Validating,Mutating}WebhookConfiguration API version used for the Options.
 	WebhookVersion string `json:"webhookVersion,omitempty"`
+
+	// Defaulting is true if the defaulting webhook was scaffolded.
+	Defaulting bool `json:"defaulting,omitempty"`
+	// Validation is true if the validating webhook was scaffolded.
+	Validation bool `json:"validation,omitempty"`
+	// Conversion is true if the conversion webhook was scaffolded.
+	Conversion bool `json:"conversion,omitempty"`
+	// OwnerLabels is true if the webhook labeling the objects controlled by the resource was scaffolded.
+	OwnerLabels bool `json:"ownerLabels,omitempty"`
+	// Subresources are the subresources, status or scale, whose validating webhooks were scaffolded.
+	Subresources []string `json:"subresources,omitempty"`
+	// WasmPolicy is true if the validation of the validating webhook is also compiled to a wasm policy.
+	WasmPolicy bool `json:"wasmPolicy,omitempty"`
+}
+
+// IsEmpty returns true if no webhook type was recorded, e.g. for resources scaffolded before they were tracked.
+func (w Webhooks) IsEmpty() bool {
+	return !w.Defaulting && !w.Validation && !w.Conversion && !w.OwnerLabels && len(w.Subresources) == 0
+}
+
+// HasSubresource returns true if the validating webhook of the subresource was scaffolded.
+func (w Webhooks) HasSubresource(subresource string) bool {
+	for _, scaffolded := range w.Subresources {
+		if scaffolded == subresource {
+			return true
+		}
+	}
+	return false
 }
 
 // isGVKEqualTo compares it with another resource
@@ -195,6 +388,10 @@ func (r ResourceData) isGVKEqualTo(other ResourceData) bool {
 // merge combines fields of two GVKs that have matching group, version, and kind,
 // favoring the receiver's values.
 func (r *ResourceData) merge(other ResourceData) {
+	if r.GroupPackage == "" && other.GroupPackage != "" {
+		r.GroupPackage = other.GroupPackage
+	}
+
 	if other.Webhooks != nil {
 		if r.Webhooks == nil {
 			r.Webhooks = other.Webhooks
@@ -218,6 +415,17 @@ func (w *Webhooks) merge(other *Webhooks) {
 	if w.WebhookVersion == "" && other.WebhookVersion != "" {
 		w.WebhookVersion = other.WebhookVersion
 	}
+
+	w.Defaulting = w.Defaulting || other.Defaulting
+	w.Validation = w.Validation || other.Validation
+	w.Conversion = w.Conversion || other.Conversion
+	w.OwnerLabels = w.OwnerLabels || other.OwnerLabels
+	w.WasmPolicy = w.WasmPolicy || other.WasmPolicy
+	for _, subresource := range other.Subresources {
+		if !w.HasSubresource(subresource) {
+			w.Subresources = append(w.Subresources, subresource)
+		}
+	}
 }
 
 // merge compares it with another api by setting each api type individually so existing values are
@@ -226,6 +434,9 @@ func (a *API) merge(other *API) {
 	if a.CRDVersion == "" && other.CRDVersion != "" {
 		a.CRDVersion = other.CRDVersion
 	}
+	if a.Deprecation == nil && other.Deprecation != nil {
+		a.Deprecation = other.Deprecation
+	}
 }
 
 // Marshal returns the bytes of c.
@@ -33,7 +33,7 @@ var _ = Describe("PluginConfig", func() {
 	const defaultWebhookVersion = "v1"
 
 	resource := ResourceData{Group: "Foo", Kind: "Baz", Version: "v1"}
-	resource.Webhooks = &Webhooks{defaultWebhookVersion}
+	resource.Webhooks = &Webhooks{WebhookVersion: defaultWebhookVersion}
 
 	It("should return true when has the ResourceData is equals", func() {
 		Expect(resource.isGVKEqualTo(ResourceData{Group: "Foo", Kind: "Baz", Version: "v1"})).To(BeTrue())
@@ -281,6 +281,32 @@ var _ = Describe("Config", func() {
 			c.UpdateResources(gvk)
 			Expect(c.Resources).To(Equal([]ResourceData{gvk, gvk2}))
 		})
+		It("Adds the webhook types of an existing resource", func() {
+			c.UpdateResources(ResourceData{Group: gvk1.Group, Version: gvk1.Version, Kind: gvk1.Kind,
+				Webhooks: &Webhooks{WebhookVersion: v1beta1, Defaulting: true}})
+			c.UpdateResources(ResourceData{Group: gvk1.Group, Version: gvk1.Version, Kind: gvk1.Kind,
+				Webhooks: &Webhooks{WebhookVersion: v1beta1, Validation: true}})
+			Expect(c.Resources).To(HaveLen(1))
+			Expect(*c.Resources[0].Webhooks).To(Equal(Webhooks{WebhookVersion: v1beta1, Defaulting: true, Validation: true}))
+		})
+		It("Adds the owner labels webhook of an existing resource", func() {
+			c.UpdateResources(ResourceData{Group: gvk1.Group, Version: gvk1.Version, Kind: gvk1.Kind,
+				Webhooks: &Webhooks{WebhookVersion: v1beta1, Defaulting: true}})
+			c.UpdateResources(ResourceData{Group: gvk1.Group, Version: gvk1.Version, Kind: gvk1.Kind,
+				Webhooks: &Webhooks{WebhookVersion: v1beta1, OwnerLabels: true}})
+			Expect(c.Resources).To(HaveLen(1))
+			Expect(*c.Resources[0].Webhooks).To(Equal(Webhooks{WebhookVersion: v1beta1, Defaulting: true, OwnerLabels: true}))
+		})
+		It("Adds the subresource webhooks of an existing resource", func() {
+			c.UpdateResources(ResourceData{Group: gvk1.Group, Version: gvk1.Version, Kind: gvk1.Kind,
+				Webhooks: &Webhooks{WebhookVersion: v1beta1, Subresources: []string{"status"}}})
+			c.UpdateResources(ResourceData{Group: gvk1.Group, Version: gvk1.Version, Kind: gvk1.Kind,
+				Webhooks: &Webhooks{WebhookVersion: v1beta1, Subresources: []string{"status", "scale"}}})
+			Expect(c.Resources).To(HaveLen(1))
+			Expect(*c.Resources[0].Webhooks).To(Equal(Webhooks{WebhookVersion: v1beta1,
+				Subresources: []string{"status", "scale"}}))
+			Expect(c.Resources[0].Webhooks.IsEmpty()).To(BeFalse())
+		})
 	})
 
 	Context("HasGroup", func() {
@@ -309,4 +335,32 @@ var _ = Describe("Config", func() {
 			Expect(c.HasWebhook(gvk1)).To(BeFalse())
 		})
 	})
+
+	Context("GetNamespace", func() {
+		It("should default to the name of the project suffixed with -system", func() {
+			Expect(Config{ProjectName: "fleet"}.GetNamespace()).To(Equal("fleet-system"))
+		})
+		It("should return the namespace set in the config", func() {
+			Expect(Config{ProjectName: "fleet", Namespace: "ships"}.GetNamespace()).To(Equal("ships"))
+		})
+	})
+
+	Context("SupportsKubernetesBefore", func() {
+		It("should return false when no minimum Kubernetes version is set", func() {
+			Expect(c.SupportsKubernetesBefore(1, 16)).To(BeFalse())
+		})
+		It("should compare the minimum Kubernetes version", func() {
+			c.MinKubernetesVersion = "1.15"
+			Expect(c.SupportsKubernetesBefore(1, 16)).To(BeTrue())
+			c.MinKubernetesVersion = "v1.16.2"
+			Expect(c.SupportsKubernetesBefore(1, 16)).To(BeFalse())
+			Expect(c.SupportsKubernetesBefore(1, 17)).To(BeTrue())
+		})
+		It("should reject the invalid Kubernetes versions", func() {
+			for _, version := range []string{"1", "1.x", "1.15.2.1", "-1.15"} {
+				_, _, err := ParseKubernetesVersion(version)
+				Expect(err).To(HaveOccurred(), version)
+			}
+		})
+	})
 })
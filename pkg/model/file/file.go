@@ -23,7 +23,7 @@ const (
 	// Skip skips the file and moves to the next one
 	Skip IfExistsAction = iota
 
-	// Error returns an error and stops processing
+	// Error returns an error and stops processing, unless another conflict strategy is chosen with --on-conflict
 	Error
 
 	// Overwrite truncates and overwrites the existing file
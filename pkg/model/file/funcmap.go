@@ -21,6 +21,8 @@ import (
 	"hash/fnv"
 	"strings"
 	"text/template"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/markers"
 )
 
 // DefaultFuncMap returns the default template.FuncMap for rendering the template.
@@ -29,6 +31,7 @@ func DefaultFuncMap() template.FuncMap {
 		"title":   strings.Title,
 		"lower":   strings.ToLower,
 		"hashFNV": hashFNV,
+		"markers": markers.Format,
 	}
 }
 
@@ -55,6 +55,14 @@ type Inserter interface {
 	GetCodeFragments() CodeFragmentsMap
 }
 
+// AnchoredInserter is an Inserter of a Go file able to locate its markers in the syntax tree of the file,
+// so that the code fragments are inserted even if the markers were moved or removed
+type AnchoredInserter interface {
+	Inserter
+	// GetAnchors returns the anchors locating the markers, nil to insert the code fragments at the markers
+	GetAnchors() map[Marker]Anchor
+}
+
 // HasDomain allows the domain to be used on a template
 type HasDomain interface {
 	// InjectDomain sets the template domain
@@ -79,6 +87,36 @@ type HasComponentConfig interface {
 	InjectComponentConfig(bool)
 }
 
+// HasFeatureGates allows the feature-gates flag to be used on a template
+type HasFeatureGates interface {
+	// InjectFeatureGates sets the template feature-gates flag
+	InjectFeatureGates(bool)
+}
+
+// HasMarkerDocs allows the marker-docs flag to be used on a template
+type HasMarkerDocs interface {
+	// InjectMarkerDocs sets the template marker-docs flag
+	InjectMarkerDocs(bool)
+}
+
+// HasMultiCluster allows the multi-cluster flag to be used on a template
+type HasMultiCluster interface {
+	// InjectMultiCluster sets the template multi-cluster flag
+	InjectMultiCluster(bool)
+}
+
+// HasASTWiring allows the ast wiring flag to be used on a template
+type HasASTWiring interface {
+	// InjectASTWiring sets the template ast wiring flag
+	InjectASTWiring(bool)
+}
+
+// HasIPv6 allows the ipv6 flag to be used on a template
+type HasIPv6 interface {
+	// InjectIPv6 sets the template ipv6 flag
+	InjectIPv6(bool)
+}
+
 // HasBoilerplate allows a boilerplate to be used on a template
 type HasBoilerplate interface {
 	// InjectBoilerplate sets the template boilerplate
@@ -97,6 +135,12 @@ type HasProjectName interface {
 	InjectProjectName(string)
 }
 
+// HasNamespace allows the install namespace of the project to be used on a template.
+type HasNamespace interface {
+	// InjectNamespace sets the template install namespace.
+	InjectNamespace(string)
+}
+
 // UseCustomFuncMap allows a template to use a custom template.FuncMap instead of the default FuncMap.
 type UseCustomFuncMap interface {
 	// GetFuncMap returns a custom FuncMap.
@@ -18,17 +18,43 @@ package file
 
 import (
 	"fmt"
+	"go/ast"
+	"go/token"
 	"path/filepath"
 	"strings"
 )
 
-const prefix = "+kubebuilder:scaffold:"
+const (
+	prefix = "+kubebuilder:scaffold:"
+
+	// ownerPrefix is the prefix of the value of the markers that record the plugin owning a file
+	ownerPrefix = "owned-by="
+)
 
 var commentsByExt = map[string]string{
 	".go":   "//",
 	".yaml": "#",
 	".yml":  "#",
-	// When adding additional file extensions, update also the NewMarkerFor documentation and error
+	".py":   "#",
+	".rs":   "//",
+	".toml": "#",
+	".sh":   "#",
+	// When adding additional file extensions, update also the NewMarkerFor documentation
+}
+
+// RegisterCommentPrefix registers the prefix of the line comments of the files with extension ext, so
+// that plugins scaffolding files in other languages can use markers in them. It must be called before
+// the markers are created, e.g. from an init function, and returns an error if ext is already registered
+// with a different prefix.
+func RegisterCommentPrefix(ext, comment string) error {
+	if !strings.HasPrefix(ext, ".") || comment == "" {
+		return fmt.Errorf("invalid comment prefix %q for extension %q", comment, ext)
+	}
+	if registered, found := commentsByExt[ext]; found && registered != comment {
+		return fmt.Errorf("extension %q is already registered with the comment prefix %q", ext, registered)
+	}
+	commentsByExt[ext] = comment
+	return nil
 }
 
 // Marker represents a machine-readable comment that will be used for scaffolding purposes
@@ -38,14 +64,47 @@ type Marker struct {
 }
 
 // NewMarkerFor creates a new marker customized for the specific file
-// Supported file extensions: .go, .yaml, .yml
+// Supported file extensions: .go, .yaml, .yml, .py, .rs, .toml, .sh and the ones registered
+// with RegisterCommentPrefix
 func NewMarkerFor(path string, value string) Marker {
 	ext := filepath.Ext(path)
 	if comment, found := commentsByExt[ext]; found {
 		return Marker{comment, value}
 	}
 
-	panic(fmt.Errorf("unknown file extension: '%s', expected '.go', '.yaml' or '.yml'", ext))
+	panic(fmt.Errorf("unknown file extension: '%s', register its comment prefix with RegisterCommentPrefix", ext))
+}
+
+// NewOwnerMarkerFor creates a marker recording that the specific file is owned by the plugin with the given key.
+//
+// A file owned by a plugin is entirely generated by it: the plugin may rewrite it, e.g. when the project is
+// upgraded, and users should not edit it. Files without an owner marker belong to the user, plugins only
+// insert code fragments in them at the markers created with NewMarkerFor. The owner marker is a line
+// comment, usually the first line of the file after its boilerplate, such as:
+//
+//	# +kubebuilder:scaffold:owned-by=sample.kubebuilder.io/v1-alpha
+func NewOwnerMarkerFor(path string, pluginKey string) Marker {
+	return NewMarkerFor(path, ownerPrefix+pluginKey)
+}
+
+// FindOwner returns the key of the plugin owning the file with the given path and content,
+// as recorded by a marker created with NewOwnerMarkerFor, if any.
+func FindOwner(path string, content []byte) (string, bool) {
+	comment, found := commentsByExt[filepath.Ext(path)]
+	if !found {
+		return "", false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, comment) {
+			continue
+		}
+		value := strings.TrimSpace(strings.TrimPrefix(line, comment))
+		if strings.HasPrefix(value, prefix+ownerPrefix) {
+			return strings.TrimPrefix(value, prefix+ownerPrefix), true
+		}
+	}
+	return "", false
 }
 
 // String implements Stringer
@@ -59,6 +118,10 @@ func (m Marker) EqualsLine(line string) bool {
 	return line == prefix+m.value
 }
 
+// Anchor locates a marker in the syntax tree of a Go file: its code fragments are inserted at the start of the
+// line of the returned position. It returns an error if the position is not found.
+type Anchor func(fset *token.FileSet, f *ast.File) (token.Pos, error)
+
 // CodeFragments represents a set of code fragments
 // A code fragment is a piece of code provided as a Go string, it may have multiple lines
 type CodeFragments []string
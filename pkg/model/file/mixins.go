@@ -115,6 +115,62 @@ func (m *ComponentConfigMixin) InjectComponentConfig(flag bool) {
 	m.ComponentConfig = flag
 }
 
+// FeatureGatesMixin provides templates with a injectable feature-gates flag field
+type FeatureGatesMixin struct {
+	// FeatureGates is the feature-gates flag
+	FeatureGates bool
+}
+
+// InjectFeatureGates implements HasFeatureGates
+func (m *FeatureGatesMixin) InjectFeatureGates(flag bool) {
+	m.FeatureGates = flag
+}
+
+// MarkerDocsMixin provides templates with a injectable marker-docs flag field
+type MarkerDocsMixin struct {
+	// MarkerDocs is the marker-docs flag
+	MarkerDocs bool
+}
+
+// InjectMarkerDocs implements HasMarkerDocs
+func (m *MarkerDocsMixin) InjectMarkerDocs(flag bool) {
+	m.MarkerDocs = flag
+}
+
+// MultiClusterMixin provides templates with a injectable multi-cluster flag field
+type MultiClusterMixin struct {
+	// MultiCluster is the multi-cluster flag
+	MultiCluster bool
+}
+
+// InjectMultiCluster implements HasMultiCluster
+func (m *MultiClusterMixin) InjectMultiCluster(flag bool) {
+	m.MultiCluster = flag
+}
+
+// ASTWiringMixin provides templates with a injectable ast wiring flag field
+type ASTWiringMixin struct {
+	// ASTWiring is the ast wiring flag, set if the code fragments are inserted at the positions found in the
+	// syntax tree of the file rather than at its markers
+	ASTWiring bool
+}
+
+// InjectASTWiring implements HasASTWiring
+func (m *ASTWiringMixin) InjectASTWiring(flag bool) {
+	m.ASTWiring = flag
+}
+
+// IPv6Mixin provides templates with a injectable ipv6 flag field
+type IPv6Mixin struct {
+	// IPv6 is the ipv6 flag, set if the manager is deployed to IPv6-only clusters
+	IPv6 bool
+}
+
+// InjectIPv6 implements HasIPv6
+func (m *IPv6Mixin) InjectIPv6(flag bool) {
+	m.IPv6 = flag
+}
+
 // BoilerplateMixin provides templates with a injectable boilerplate field
 type BoilerplateMixin struct {
 	// Boilerplate is the contents of a Boilerplate go header file
@@ -151,3 +207,15 @@ func (m *ProjectNameMixin) InjectProjectName(projectName string) {
 		m.ProjectName = projectName
 	}
 }
+
+// NamespaceMixin provides templates with an injectable install namespace field.
+type NamespaceMixin struct {
+	Namespace string
+}
+
+// InjectNamespace implements HasNamespace.
+func (m *NamespaceMixin) InjectNamespace(namespace string) {
+	if m.Namespace == "" {
+		m.Namespace = namespace
+	}
+}
@@ -30,6 +30,7 @@ import (
 
 const (
 	versionPattern  = "^v\\d+(?:alpha\\d+|beta\\d+)?$"
+	packagePattern  = "^[a-z][a-z0-9]*$"
 	groupRequired   = "group cannot be empty"
 	versionRequired = "version cannot be empty"
 	kindRequired    = "kind cannot be empty"
@@ -37,6 +38,7 @@ const (
 
 var (
 	versionRegex = regexp.MustCompile(versionPattern)
+	packageRegex = regexp.MustCompile(packagePattern)
 
 	coreGroups = map[string]string{
 		"admission":             "k8s.io",
@@ -70,6 +72,10 @@ type Options struct {
 	// Group is the API Group. Does not contain the domain.
 	Group string
 
+	// GroupPackage is the package name of the API Group.
+	// Optional, defaults to the one already used by the Group or to the Group cleaned to be used as a package name
+	GroupPackage string
+
 	// Version is the API version.
 	Version string
 
@@ -171,6 +177,16 @@ func (opts *Options) Validate() error {
 		}
 	}
 
+	// Check if the Group package, if provided, is a valid package name for a Group
+	if len(opts.GroupPackage) != 0 {
+		if len(opts.Group) == 0 {
+			return fmt.Errorf("group package cannot be provided without a group")
+		}
+		if !packageRegex.MatchString(opts.GroupPackage) {
+			return fmt.Errorf("group package must match %s (was %s)", packagePattern, opts.GroupPackage)
+		}
+	}
+
 	// Check if the version follows the valid pattern
 	if !versionRegex.MatchString(opts.Version) {
 		return fmt.Errorf("version must match %s (was %s)", versionPattern, opts.Version)
@@ -209,11 +225,12 @@ func (opts *Options) Validate() error {
 // Data returns the ResourceData information to check against tracked resources in the configuration file
 func (opts *Options) Data() config.ResourceData {
 	return config.ResourceData{
-		Group:    opts.Group,
-		Version:  opts.Version,
-		Kind:     opts.Kind,
-		API:      &opts.API,
-		Webhooks: &opts.Webhooks,
+		Group:        opts.Group,
+		GroupPackage: opts.GroupPackage,
+		Version:      opts.Version,
+		Kind:         opts.Kind,
+		API:          &opts.API,
+		Webhooks:     &opts.Webhooks,
 	}
 }
 
@@ -232,12 +249,23 @@ func (opts *Options) safeImport(unsafe string) string {
 func (opts *Options) NewResource(c *config.Config, doResource bool) *Resource {
 	res := opts.newResource()
 
+	// The package name of the Group, once chosen, is shared by all the versions of the Group
+	groupPackage := opts.GroupPackage
+	if groupPackage == "" && opts.Group != "" {
+		groupPackage = c.GetGroupPackage(opts.Group)
+	}
+	if groupPackage != "" {
+		res.GroupPackage = groupPackage
+		res.GroupPackageName = groupPackage
+		res.ImportAlias = groupPackage + opts.Version
+	}
+
 	replacer := res.Replacer()
 
 	pkg := replacer.Replace(path.Join(c.Repo, "api", "%[version]"))
 	if c.MultiGroup {
 		if opts.Group != "" {
-			pkg = replacer.Replace(path.Join(c.Repo, "apis", "%[group]", "%[version]"))
+			pkg = replacer.Replace(path.Join(c.Repo, "apis", "%[group-path]", "%[version]"))
 		} else {
 			pkg = replacer.Replace(path.Join(c.Repo, "apis", "%[version]"))
 		}
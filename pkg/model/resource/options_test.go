@@ -36,6 +36,23 @@ var _ = Describe("Resource Options", func() {
 				"([a DNS-1123 subdomain must consist of lower case alphanumeric characters"))
 		})
 
+		It("should succeed if the GroupPackage is a valid package name", func() {
+			options := &Options{Group: "sea-creatures", GroupPackage: "sea", Version: "v1", Kind: "Kraken"}
+			Expect(options.Validate()).To(Succeed())
+		})
+
+		It("should fail if the GroupPackage is not a valid package name", func() {
+			options := &Options{Group: "sea-creatures", GroupPackage: "sea-creatures", Version: "v1", Kind: "Kraken"}
+			Expect(options.Validate()).NotTo(Succeed())
+			Expect(options.Validate().Error()).To(ContainSubstring("group package must match"))
+		})
+
+		It("should fail if the GroupPackage is provided without a Group", func() {
+			options := &Options{GroupPackage: "sea", Version: "v1", Kind: "Kraken"}
+			Expect(options.Validate()).NotTo(Succeed())
+			Expect(options.Validate().Error()).To(ContainSubstring("group package cannot be provided without a group"))
+		})
+
 		It("should fail if the Version is not specified", func() {
 			options := &Options{Group: "crew", Kind: "FirstMate"}
 			Expect(options.Validate()).NotTo(Succeed())
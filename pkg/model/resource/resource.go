@@ -31,6 +31,10 @@ type Resource struct {
 	// GroupPackageName is the API Group cleaned to be used as the package name.
 	GroupPackageName string `json:"-"`
 
+	// GroupPackage is the user provided package name of the API Group, used instead of the
+	// Group for the package names, directories and import aliases.
+	GroupPackage string `json:"groupPackage,omitempty"`
+
 	// Version is the API version.
 	Version string `json:"version,omitempty"`
 
@@ -62,12 +66,21 @@ type Resource struct {
 // Data returns the ResourceData information to check against tracked resources in the configuration file
 func (r *Resource) Data() config.ResourceData {
 	return config.ResourceData{
-		Group:    r.Group,
-		Version:  r.Version,
-		Kind:     r.Kind,
-		API:      &r.API,
-		Webhooks: &r.Webhooks,
+		Group:        r.Group,
+		GroupPackage: r.GroupPackage,
+		Version:      r.Version,
+		Kind:         r.Kind,
+		API:          &r.API,
+		Webhooks:     &r.Webhooks,
+	}
+}
+
+// GroupPath returns the name of the directories holding the API Group packages.
+func (r Resource) GroupPath() string {
+	if r.GroupPackage != "" {
+		return r.GroupPackage
 	}
+	return r.Group
 }
 
 func wrapKey(key string) string {
@@ -80,6 +93,7 @@ func (r Resource) Replacer() *strings.Replacer {
 
 	replacements = append(replacements, wrapKey("group"), r.Group)
 	replacements = append(replacements, wrapKey("group-package-name"), r.GroupPackageName)
+	replacements = append(replacements, wrapKey("group-path"), r.GroupPath())
 	replacements = append(replacements, wrapKey("version"), r.Version)
 	replacements = append(replacements, wrapKey("kind"), strings.ToLower(r.Kind))
 	replacements = append(replacements, wrapKey("plural"), strings.ToLower(r.Plural))
@@ -178,6 +178,35 @@ var _ = Describe("Resource", func() {
 			Expect(resource.Domain).To(Equal("my.project.test.io"))
 		})
 
+		It("should use the group package if provided", func() {
+			multiGroupConfig := &config.Config{
+				Version:    config.Version3Alpha,
+				Domain:     "test.io",
+				Repo:       "test",
+				MultiGroup: true,
+			}
+
+			options := &Options{Group: "sea-creatures", GroupPackage: "sea", Version: "v1", Kind: "Kraken"}
+			Expect(options.Validate()).To(Succeed())
+
+			resource := options.NewResource(multiGroupConfig, true)
+			Expect(resource.Group).To(Equal(options.Group))
+			Expect(resource.GroupPackage).To(Equal("sea"))
+			Expect(resource.GroupPackageName).To(Equal("sea"))
+			Expect(resource.GroupPath()).To(Equal("sea"))
+			Expect(resource.ImportAlias).To(Equal("seav1"))
+			Expect(resource.Package).To(Equal(path.Join("test", "apis", "sea", "v1")))
+			Expect(resource.Domain).To(Equal("sea-creatures.test.io"))
+
+			By("reusing the group package tracked for the group")
+			multiGroupConfig.UpdateResources(resource.Data())
+			options = &Options{Group: "sea-creatures", Version: "v2", Kind: "Leviathan"}
+			resource = options.NewResource(multiGroupConfig, true)
+			Expect(resource.GroupPackageName).To(Equal("sea"))
+			Expect(resource.ImportAlias).To(Equal("seav2"))
+			Expect(resource.Package).To(Equal(path.Join("test", "apis", "sea", "v2")))
+		})
+
 		It("should not append '.' if provided an empty domain", func() {
 			options := &Options{Group: "crew", Version: "v1", Kind: "FirstMate"}
 			Expect(options.Validate()).To(Succeed())
@@ -94,9 +94,27 @@ func (u Universe) InjectInto(builder file.Builder) {
 		if builderWithComponentConfig, hasComponentConfig := builder.(file.HasComponentConfig); hasComponentConfig {
 			builderWithComponentConfig.InjectComponentConfig(u.Config.ComponentConfig)
 		}
+		if builderWithFeatureGates, hasFeatureGates := builder.(file.HasFeatureGates); hasFeatureGates {
+			builderWithFeatureGates.InjectFeatureGates(u.Config.FeatureGates)
+		}
+		if builderWithMarkerDocs, hasMarkerDocs := builder.(file.HasMarkerDocs); hasMarkerDocs {
+			builderWithMarkerDocs.InjectMarkerDocs(u.Config.MarkerDocs)
+		}
+		if builderWithMultiCluster, hasMultiCluster := builder.(file.HasMultiCluster); hasMultiCluster {
+			builderWithMultiCluster.InjectMultiCluster(u.Config.MultiCluster)
+		}
+		if builderWithASTWiring, hasASTWiring := builder.(file.HasASTWiring); hasASTWiring {
+			builderWithASTWiring.InjectASTWiring(u.Config.Wiring == config.WiringAST)
+		}
+		if builderWithIPv6, hasIPv6 := builder.(file.HasIPv6); hasIPv6 {
+			builderWithIPv6.InjectIPv6(u.Config.IPFamily == config.IPFamilyIPv6)
+		}
 		if builderWithProjectName, hasProjectName := builder.(file.HasProjectName); hasProjectName {
 			builderWithProjectName.InjectProjectName(u.Config.ProjectName)
 		}
+		if builderWithNamespace, hasNamespace := builder.(file.HasNamespace); hasNamespace {
+			builderWithNamespace.InjectNamespace(u.Config.GetNamespace())
+		}
 	}
 	// Inject boilerplate
 	if builderWithBoilerplate, hasBoilerplate := builder.(file.HasBoilerplate); hasBoilerplate {
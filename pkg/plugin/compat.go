@@ -0,0 +1,35 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+// This file contains the former names of the identifiers of the plugin SDK, kept so that the plugins written
+// against them still build. They are removed by the next major version of the SDK, see SDKVersion.
+
+// Base is the former name of Plugin.
+//
+// Deprecated: use Plugin instead.
+type Base = Plugin
+
+// GenericSubcommand is the former name of Subcommand.
+//
+// Deprecated: use Subcommand instead.
+type GenericSubcommand = Subcommand
+
+// PluginContext is the former name of Context.
+//
+// Deprecated: use Context instead.
+type PluginContext = Context //nolint:golint
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Deprecation describes the deprecation of a plugin.
+type Deprecation struct {
+	// Message explains why the plugin is deprecated.
+	Message string
+	// Replacement is the key of the plugin replacing the deprecated one, if any.
+	Replacement string
+	// MigrationURL points to the documentation of the migration of the projects to Replacement, if any.
+	MigrationURL string
+	// RemovalVersion is the release of the CLI removing the plugin, if planned.
+	RemovalVersion string
+}
+
+// String returns the deprecation warning: the message followed by the replacement, the migration pointer and
+// the removal version, if set.
+func (d Deprecation) String() string {
+	parts := []string{d.Message}
+	if d.Replacement != "" {
+		parts = append(parts, fmt.Sprintf("Use the %s plugin instead.", d.Replacement))
+	}
+	if d.MigrationURL != "" {
+		parts = append(parts, fmt.Sprintf("See %s to migrate.", d.MigrationURL))
+	}
+	if d.RemovalVersion != "" {
+		parts = append(parts, fmt.Sprintf("It will be removed in %s.", d.RemovalVersion))
+	}
+	return strings.Join(parts, " ")
+}
+
+// DeprecationOf returns the deprecation of p, and whether p is deprecated. The plugins that do not implement
+// DeprecationDetails are described by their deprecation warning only.
+func DeprecationOf(p Plugin) (Deprecation, bool) {
+	switch d := p.(type) {
+	case DeprecationDetails:
+		return d.Deprecation(), true
+	case Deprecated:
+		return Deprecation{Message: d.DeprecationWarning()}, true
+	default:
+		return Deprecation{}, false
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	g "github.com/onsi/ginkgo" // An alias is required because Context is defined elsewhere in this package.
+	. "github.com/onsi/gomega"
+)
+
+type mockPlugin struct{}
+
+func (mockPlugin) Name() string                       { return "mock.kubebuilder.io" }
+func (mockPlugin) Version() Version                   { return Version{Number: 1} }
+func (mockPlugin) SupportedProjectVersions() []string { return []string{"3-alpha"} }
+
+type mockDeprecatedPlugin struct{ mockPlugin }
+
+func (mockDeprecatedPlugin) DeprecationWarning() string { return "mock is deprecated." }
+
+type mockDeprecationDetailsPlugin struct{ mockDeprecatedPlugin }
+
+func (mockDeprecationDetailsPlugin) Deprecation() Deprecation {
+	return Deprecation{Message: "mock is deprecated.", Replacement: "mock.kubebuilder.io/v2"}
+}
+
+var _ = g.Describe("Deprecation", func() {
+	g.Context("String", func() {
+		g.It("should return the message only", func() {
+			Expect(Deprecation{Message: "mock is deprecated."}.String()).To(Equal("mock is deprecated."))
+		})
+
+		g.It("should append the replacement, the migration URL and the removal version", func() {
+			d := Deprecation{
+				Message:        "mock is deprecated.",
+				Replacement:    "mock.kubebuilder.io/v2",
+				MigrationURL:   "https://book.kubebuilder.io",
+				RemovalVersion: "v4.0.0",
+			}
+			Expect(d.String()).To(Equal("mock is deprecated. Use the mock.kubebuilder.io/v2 plugin instead. " +
+				"See https://book.kubebuilder.io to migrate. It will be removed in v4.0.0."))
+		})
+	})
+
+	g.Context("DeprecationOf", func() {
+		g.It("should not report plugins that are not deprecated", func() {
+			_, isDeprecated := DeprecationOf(mockPlugin{})
+			Expect(isDeprecated).To(BeFalse())
+		})
+
+		g.It("should describe deprecated plugins by their warning", func() {
+			d, isDeprecated := DeprecationOf(mockDeprecatedPlugin{})
+			Expect(isDeprecated).To(BeTrue())
+			Expect(d).To(Equal(Deprecation{Message: "mock is deprecated."}))
+		})
+
+		g.It("should return the deprecation details of the plugins providing them", func() {
+			d, isDeprecated := DeprecationOf(mockDeprecationDetailsPlugin{})
+			Expect(isDeprecated).To(BeTrue())
+			Expect(d.Replacement).To(Equal("mock.kubebuilder.io/v2"))
+		})
+	})
+})
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sample
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+type createAPISubcommand struct {
+	config *config.Config
+
+	options *resource.Options
+
+	// apiPath is the path of the description of the created API, set once scaffolded
+	apiPath string
+}
+
+var (
+	_ plugin.CreateAPISubcommand = &createAPISubcommand{}
+	_ plugin.PostCreateHook      = &createAPISubcommand{}
+)
+
+func (p *createAPISubcommand) UpdateContext(ctx *plugin.Context) {
+	ctx.Description = `Scaffold the language-neutral description of a Kubernetes API.
+
+Writes the description of the API in apis/<group>/<version>/<kind>.yaml and adds it to apis.yaml.
+`
+	ctx.Examples = fmt.Sprintf(`  # Create a frigates API with Group: ship, Version: v1beta1 and Kind: Frigate
+  %s create api --group ship --version v1beta1 --kind Frigate
+`,
+		ctx.CommandName)
+}
+
+func (p *createAPISubcommand) BindFlags(fs *pflag.FlagSet) {
+	p.options = &resource.Options{}
+	fs.StringVar(&p.options.Group, "group", "", "resource Group")
+	fs.StringVar(&p.options.Version, "version", "", "resource Version")
+	fs.StringVar(&p.options.Kind, "kind", "", "resource Kind")
+	fs.StringVar(&p.options.Plural, "resource", "", "resource Resource")
+	fs.BoolVar(&p.options.Namespaced, "namespaced", true, "resource is namespaced")
+}
+
+func (p *createAPISubcommand) InjectConfig(c *config.Config) {
+	p.config = c
+}
+
+func (p *createAPISubcommand) Run() error {
+	if err := p.options.Validate(); err != nil {
+		return err
+	}
+	if p.config.GetResource(p.options.Data()) != nil {
+		return fmt.Errorf("API %s/%s, Kind=%s already exists", p.options.Group, p.options.Version, p.options.Kind)
+	}
+
+	res := plugin.NewResource(p.options.NewResource(p.config, true))
+	p.apiPath = path.Join("apis", res.Path()+".yaml")
+
+	// The description is entirely generated, so the plugin records that it owns the file.
+	description, err := yaml.Marshal(res)
+	if err != nil {
+		return err
+	}
+	content := append([]byte(file.NewOwnerMarkerFor(p.apiPath, plugin.KeyFor(Plugin{})).String()+"\n"), description...)
+	if err := writeFile(p.apiPath, content); err != nil {
+		return err
+	}
+
+	// The index belongs to the user, so the plugin only inserts a fragment at its marker.
+	if err := insertFragment(apisIndexPath, file.NewMarkerFor(apisIndexPath, apisMarker),
+		fmt.Sprintf("- %s\n", p.apiPath)); err != nil {
+		return err
+	}
+
+	p.config.UpdateResources(p.options.Data())
+	return nil
+}
+
+// PostCreate implements plugin.PostCreateHook
+func (p *createAPISubcommand) PostCreate() error {
+	fmt.Printf("Next: implement the controller of the API described in %s\n", p.apiPath)
+	return nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sample
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+const (
+	apisIndexPath = "apis.yaml"
+	apisMarker    = "apis"
+)
+
+// writeFile creates the file at path with the given content, failing if it already exists
+func writeFile(path string, content []byte) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644) //nolint:gosec
+}
+
+// insertFragment inserts fragment before the marker of the file at path
+func insertFragment(path string, marker file.Marker, fragment string) error {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return err
+	}
+
+	var out bytes.Buffer
+	found := false
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if !found && marker.EqualsLine(line) {
+			out.WriteString(fragment)
+			found = true
+		}
+		out.WriteString(line)
+	}
+	if !found {
+		return fmt.Errorf("marker %q not found in %s", marker, path)
+	}
+
+	return ioutil.WriteFile(path, out.Bytes(), 0644) //nolint:gosec
+}
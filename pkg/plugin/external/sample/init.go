@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sample
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/validation"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+type initSubcommand struct {
+	config *config.Config
+}
+
+var _ plugin.InitSubcommand = &initSubcommand{}
+
+func (p *initSubcommand) UpdateContext(ctx *plugin.Context) {
+	ctx.Description = `Initialize a new project with a language-neutral layout.
+
+Writes the following files:
+- a PROJECT file with the domain
+- an apis.yaml file indexing the APIs of the project
+`
+	ctx.Examples = fmt.Sprintf(`  # Scaffold a project with the sample plugin
+  %s init --plugins %s --domain example.org
+`,
+		ctx.CommandName, plugin.KeyFor(Plugin{}))
+}
+
+func (p *initSubcommand) BindFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&p.config.Domain, "domain", "my.domain", "domain for groups")
+	fs.StringVar(&p.config.ProjectName, "project-name", "", "name of this project")
+}
+
+func (p *initSubcommand) InjectConfig(c *config.Config) {
+	c.Layout = plugin.KeyFor(Plugin{})
+	p.config = c
+}
+
+func (p *initSubcommand) Run() error {
+	if p.config.Domain == "" {
+		return errors.New("domain is required")
+	}
+
+	// Check if the project name is a valid k8s namespace (DNS 1123 label).
+	if p.config.ProjectName == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("error getting current directory: %v", err)
+		}
+		p.config.ProjectName = strings.ToLower(filepath.Base(dir))
+	}
+	if err := validation.IsDNS1123Label(p.config.ProjectName); err != nil {
+		return fmt.Errorf("project name (%s) is invalid: %v", p.config.ProjectName, err)
+	}
+
+	return writeFile(apisIndexPath, []byte(fmt.Sprintf(`# APIs of the project, with the path of their description.
+apis:
+%s
+`, file.NewMarkerFor(apisIndexPath, apisMarker))))
+}
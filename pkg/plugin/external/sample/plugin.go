@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Package sample is a reference implementation of a plugin scaffolding projects in a language other than
+// Go. It only relies on the language-agnostic parts of the plugin SDK:
+//   - plugin.Resource, the language-agnostic description of the APIs of the project,
+//   - the markers of the model/file package, both to insert fragments in the files owned by the user and
+//     to record the files owned by the plugin,
+//   - plugin.PostCreateHook, to run the tooling of the language once the project configuration is saved.
+//
+// It scaffolds the following language-neutral layout, which a real plugin would complement with the
+// sources of the operator in its language:
+//
+//	apis.yaml                              index of the APIs of the project, owned by the user
+//	apis/<group>/<version>/<kind>.yaml     description of each API, owned by the plugin
+package sample
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins"
+)
+
+const pluginName = "sample" + plugins.DefaultNameQualifier
+
+var (
+	supportedProjectVersions = []string{config.Version3Alpha}
+	pluginVersion            = plugin.Version{Number: 1, Stage: plugin.AlphaStage}
+)
+
+var (
+	_ plugin.Init      = Plugin{}
+	_ plugin.CreateAPI = Plugin{}
+)
+
+// Plugin implements the plugin.Init and plugin.CreateAPI interfaces
+type Plugin struct {
+	initSubcommand
+	createAPISubcommand
+}
+
+// Name returns the name of the plugin
+func (Plugin) Name() string { return pluginName }
+
+// Version returns the version of the plugin
+func (Plugin) Version() plugin.Version { return pluginVersion }
+
+// SupportedProjectVersions returns an array with all project versions supported by the plugin
+func (Plugin) SupportedProjectVersions() []string { return supportedProjectVersions }
+
+// GetInitSubcommand will return the subcommand which is responsible for initializing and common scaffolding
+func (p Plugin) GetInitSubcommand() plugin.InitSubcommand { return &p.initSubcommand }
+
+// GetCreateAPISubcommand will return the subcommand which is responsible for scaffolding apis
+func (p Plugin) GetCreateAPISubcommand() plugin.CreateAPISubcommand { return &p.createAPISubcommand }
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sample
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin/plugintest"
+)
+
+func TestScaffold(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "kubebuilder-sample-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd) //nolint:errcheck
+
+	c := &config.Config{Version: config.Version3Alpha, Domain: "example.org", ProjectName: "sample"}
+	p := Plugin{}
+
+	initCmd := p.GetInitSubcommand()
+	initCmd.InjectConfig(c)
+	if err := initCmd.Run(); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	if c.Layout != plugin.KeyFor(p) {
+		t.Errorf("expected layout %q, got %q", plugin.KeyFor(p), c.Layout)
+	}
+
+	createAPI := func(kind string) (*createAPISubcommand, error) {
+		apiCmd := &createAPISubcommand{
+			options: &resource.Options{Group: "ship", Version: "v1", Kind: kind, Namespaced: true},
+		}
+		apiCmd.InjectConfig(c)
+		return apiCmd, apiCmd.Run()
+	}
+
+	apiCmd, err := createAPI("Frigate")
+	if err != nil {
+		t.Fatalf("create api failed: %v", err)
+	}
+	if expected := "apis/ship/v1/frigate.yaml"; apiCmd.apiPath != expected {
+		t.Errorf("expected the API to be described in %q, got %q", expected, apiCmd.apiPath)
+	}
+
+	description, err := ioutil.ReadFile(apiCmd.apiPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner, found := file.FindOwner(apiCmd.apiPath, description); !found || owner != plugin.KeyFor(p) {
+		t.Errorf("expected %s to be owned by %q, got %q", apiCmd.apiPath, plugin.KeyFor(p), owner)
+	}
+	for _, field := range []string{"qualifiedGroup: ship.example.org", "kind: Frigate", "plural: frigates"} {
+		if !strings.Contains(string(description), field) {
+			t.Errorf("expected %s to contain %q, got:\n%s", apiCmd.apiPath, field, description)
+		}
+	}
+
+	index, err := ioutil.ReadFile(apisIndexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, found := file.FindOwner(apisIndexPath, index); found {
+		t.Errorf("expected %s to be owned by the user", apisIndexPath)
+	}
+	plugintest.AssertInsertedBefore(t, string(index), file.NewMarkerFor(apisIndexPath, apisMarker),
+		"- apis/ship/v1/frigate.yaml\n")
+
+	if c.GetResource(config.ResourceData{Group: "ship", Version: "v1", Kind: "Frigate"}) == nil {
+		t.Errorf("expected the API to be tracked in the project configuration")
+	}
+	if _, err := createAPI("Frigate"); err == nil {
+		t.Errorf("expected an error when creating an existing API")
+	}
+}
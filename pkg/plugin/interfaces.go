@@ -43,6 +43,22 @@ type Deprecated interface {
 	DeprecationWarning() string
 }
 
+// DeprecationDetails is an optional interface for deprecated plugins that describe their replacement and their
+// removal, which the CLI prints along with the deprecation warning and lists in the version command.
+type DeprecationDetails interface {
+	Deprecated
+	// Deprecation returns the details of the deprecation of the plugin.
+	Deprecation() Deprecation
+}
+
+// PostCreateHook is an optional interface for subcommands that need to run once the project configuration
+// has been saved, e.g. to format the scaffolded files or to fetch dependencies with the tooling of the
+// language of the project.
+type PostCreateHook interface {
+	// PostCreate is called after the subcommand ran successfully and the project configuration was saved.
+	PostCreate() error
+}
+
 // Subcommand is an interface that defines the common base for subcommands returned by plugins
 type Subcommand interface {
 	// UpdateContext updates a Context with subcommand-specific help text, like description and examples. It also serves
@@ -67,6 +83,9 @@ type Context struct {
 	Description string
 	// Examples are one or more examples of the command-line usage of this subcommand. It is used to display help.
 	Examples string
+	// Docs is the long-form documentation of this subcommand, e.g. the references to the documentation of its
+	// flags. It is displayed at the end of the help, after the flags of the plugin.
+	Docs string
 }
 
 // Init is an interface for plugins that provide an `init` subcommand
@@ -0,0 +1,144 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugintest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable that makes AssertGolden and AssertGoldenDir rewrite the golden
+// files with the scaffolded content instead of comparing them, as make generate does for the testdata projects
+// of kubebuilder:
+//
+//	KUBEBUILDER_UPDATE_GOLDEN=1 go test ./...
+const UpdateGoldenEnv = "KUBEBUILDER_UPDATE_GOLDEN"
+
+// updateGolden returns whether the golden files are rewritten
+func updateGolden() bool {
+	return os.Getenv(UpdateGoldenEnv) != ""
+}
+
+// AssertGolden checks that got is the content of the golden file at path, reporting the first line that
+// differs. Review the changes of the golden files rewritten with UpdateGoldenEnv as the ones of the scaffolds.
+func AssertGolden(t testing.TB, path string, got []byte) {
+	t.Helper()
+
+	if updateGolden() {
+		if err := writeGolden(path, got); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		t.Fatalf("reading the golden file, set %s=1 to create it: %v", UpdateGoldenEnv, err)
+		return
+	}
+	if diff := firstDiff(string(expected), string(got)); diff != "" {
+		t.Errorf("%s differs from the golden file, set %s=1 to update it: %s", path, UpdateGoldenEnv, diff)
+	}
+}
+
+// AssertGoldenDir checks that files, the contents by path such as the ones returned by MemFS.Files, are the
+// files of the golden directory dir: each file is compared as AssertGolden does, and the golden files that
+// were not scaffolded are reported. With UpdateGoldenEnv, dir is replaced by files.
+func AssertGoldenDir(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+
+	if updateGolden() {
+		if err := os.RemoveAll(dir); err != nil {
+			t.Fatal(err)
+		}
+		for path, content := range files {
+			if err := writeGolden(filepath.Join(dir, path), []byte(content)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return
+	}
+
+	var golden []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		golden = append(golden, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("reading the golden directory, set %s=1 to create it: %v", UpdateGoldenEnv, err)
+		return
+	}
+	for _, path := range golden {
+		if _, found := files[path]; !found {
+			t.Errorf("%s is a golden file but was not scaffolded, set %s=1 to remove it", path, UpdateGoldenEnv)
+		}
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		expected, err := ioutil.ReadFile(filepath.Join(dir, path)) //nolint:gosec
+		if err != nil {
+			t.Errorf("%s was scaffolded but is not a golden file, set %s=1 to add it", path, UpdateGoldenEnv)
+			continue
+		}
+		if diff := firstDiff(string(expected), files[path]); diff != "" {
+			t.Errorf("%s differs from the golden file, set %s=1 to update it: %s", path, UpdateGoldenEnv, diff)
+		}
+	}
+}
+
+// writeGolden writes content to the golden file at path, creating its directory
+func writeGolden(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644) //nolint:gosec
+}
+
+// firstDiff describes the first line that differs between expected and got, or returns "" if they are equal
+func firstDiff(expected, got string) string {
+	if expected == got {
+		return ""
+	}
+	expectedLines, gotLines := strings.Split(expected, "\n"), strings.Split(got, "\n")
+	for i := 0; ; i++ {
+		switch {
+		case i >= len(expectedLines):
+			return fmt.Sprintf("line %d: unexpected %q", i+1, gotLines[i])
+		case i >= len(gotLines):
+			return fmt.Sprintf("line %d: missing %q", i+1, expectedLines[i])
+		case expectedLines[i] != gotLines[i]:
+			return fmt.Sprintf("line %d: expected %q, got %q", i+1, expectedLines[i], gotLines[i])
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugintest
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+// AssertMarker checks that content has exactly one line with marker, so that the fragments inserted at it by
+// later scaffolds are not lost nor duplicated
+func AssertMarker(t testing.TB, content string, marker file.Marker) {
+	t.Helper()
+
+	if count := len(markerLines(content, marker)); count != 1 {
+		t.Errorf("expected a line with the marker %q, found %d in:\n%s", marker, count, content)
+	}
+}
+
+// AssertInsertedBefore checks that fragment was inserted right before the line with marker in content, as
+// machinery does for the code fragments of a file.Inserter, and that the marker was kept
+func AssertInsertedBefore(t testing.TB, content string, marker file.Marker, fragment string) {
+	t.Helper()
+
+	lines := markerLines(content, marker)
+	if len(lines) != 1 {
+		t.Errorf("expected a line with the marker %q, found %d in:\n%s", marker, len(lines), content)
+		return
+	}
+	if !strings.HasSuffix(content[:lines[0]], fragment) {
+		t.Errorf("expected %q to be inserted before the marker %q in:\n%s", fragment, marker, content)
+	}
+}
+
+// AssertInsertedOnce checks that fragment appears once in content, e.g. that it was not inserted twice when
+// the same scaffold runs again
+func AssertInsertedOnce(t testing.TB, content string, fragment string) {
+	t.Helper()
+
+	if count := strings.Count(content, fragment); count != 1 {
+		t.Errorf("expected %q to be inserted once, found %d times in:\n%s", fragment, count, content)
+	}
+}
+
+// markerLines returns the offsets in content of the lines with marker
+func markerLines(content string, marker file.Marker) []int {
+	var offsets []int
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line != "" && marker.EqualsLine(line) {
+			offsets = append(offsets, offset)
+		}
+		offset += len(line)
+	}
+	return offsets
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugintest
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemFS is an in-memory file system, which lets the tests of a plugin run its scaffolds without a project on
+// disk when passed to machinery.NewScaffoldWithFileSystem:
+//
+//	fs := plugintest.NewMemFS(map[string]string{"main.go": mainGo})
+//	err := machinery.NewScaffoldWithFileSystem(fs).Execute(universe, &templates.Main{})
+//
+// The paths are cleaned, so "./main.go" and "main.go" are the same file.
+type MemFS struct {
+	mu    sync.Mutex
+	files map[string]string
+}
+
+// NewMemFS returns a MemFS containing files, their contents by path
+func NewMemFS(files map[string]string) *MemFS {
+	fs := &MemFS{files: make(map[string]string, len(files))}
+	for path, content := range files {
+		fs.files[filepath.Clean(path)] = content
+	}
+	return fs
+}
+
+// Exists implements machinery.FileSystem. A path is a directory if a file exists under it.
+func (fs *MemFS) Exists(path string) (bool, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if _, found := fs.files[path]; found {
+		return true, nil
+	}
+	for name := range fs.files {
+		rel, err := filepath.Rel(path, name)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Open implements machinery.FileSystem
+func (fs *MemFS) Open(path string) (io.ReadCloser, error) {
+	content, found := fs.Get(path)
+	if !found {
+		return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+	}
+	return ioutil.NopCloser(bytes.NewBufferString(content)), nil
+}
+
+// Create implements machinery.FileSystem, truncating the file if it exists
+func (fs *MemFS) Create(path string) (io.Writer, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	path = filepath.Clean(path)
+	fs.files[path] = ""
+	return &memFile{fs: fs, path: path}, nil
+}
+
+// Get returns the content of the file at path, and whether it exists
+func (fs *MemFS) Get(path string) (string, bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	content, found := fs.files[filepath.Clean(path)]
+	return content, found
+}
+
+// Paths returns the sorted paths of the files
+func (fs *MemFS) Paths() []string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	paths := make([]string, 0, len(fs.files))
+	for path := range fs.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Files returns a copy of the files, their contents by path
+func (fs *MemFS) Files() map[string]string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	files := make(map[string]string, len(fs.files))
+	for path, content := range fs.files {
+		files[path] = content
+	}
+	return files
+}
+
+// memFile appends the content written to it to a file of a MemFS
+type memFile struct {
+	fs   *MemFS
+	path string
+}
+
+// Write implements io.Writer
+func (f *memFile) Write(content []byte) (int, error) {
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	f.fs.files[f.path] += string(content)
+	return len(content), nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugintest checks that a plugin follows the contract of the plugin SDK that the CLI relies on, so
+// that the authors of the plugins outside of this repository find the violations in their tests rather than
+// in the CLI of their users:
+//
+//	func TestConformance(t *testing.T) {
+//		plugintest.TestPlugin(t, myplugin.Plugin{})
+//	}
+//
+// It also provides the helpers to test the scaffolds of a plugin as kubebuilder tests its own: MemFS runs them
+// in memory, AssertGolden and AssertGoldenDir compare their files to golden files, and AssertMarker,
+// AssertInsertedBefore and AssertInsertedOnce check the code fragments inserted at the markers.
+package plugintest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+)
+
+// globalFlags are the flags of the CLI, which the flags of the subcommands can not redefine
+var globalFlags = []string{
+	"help", "project-version", "plugins", "debug", "verbose", "trace", "yes", "no-exec", "allow-exec",
+}
+
+// globalShorthands are the shorthands of the flags of the CLI
+var globalShorthands = []string{"h", "v"}
+
+// TestPlugin checks that p is a valid plugin providing at least one subcommand, and that its subcommands
+// can be bound to the CLI: each subcommand is checked in a subtest named after the command it implements.
+// The subcommands are not run, test their behavior in the tests of the plugin.
+func TestPlugin(t *testing.T, p plugin.Plugin) {
+	t.Helper()
+
+	if err := plugin.Validate(p); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(p.Name(), ".") {
+		t.Errorf("plugin name %q is not fully qualified, add a domain suffix such as .example.com", p.Name())
+	}
+	if d, isDeprecated := plugin.DeprecationOf(p); isDeprecated {
+		if d.Message == "" {
+			t.Error("deprecated plugin has no deprecation message")
+		}
+		if d.Replacement != "" {
+			if err := plugin.ValidateKey(d.Replacement); err != nil {
+				t.Errorf("invalid replacement of the deprecated plugin: %v", err)
+			}
+		}
+	}
+
+	subcommands := Subcommands(p)
+	if len(subcommands) == 0 {
+		t.Fatalf("plugin %q implements none of plugin.Init, plugin.CreateAPI, plugin.CreateWebhook "+
+			"and plugin.Edit", plugin.KeyFor(p))
+	}
+	for _, command := range []string{"init", "create api", "create webhook", "edit"} {
+		getSubcommand, found := subcommands[command]
+		if !found {
+			continue
+		}
+		t.Run(command, func(t *testing.T) {
+			testSubcommand(t, p, getSubcommand)
+		})
+	}
+}
+
+// Subcommands returns the functions getting the subcommands of p, by the command they implement
+func Subcommands(p plugin.Plugin) map[string]func() plugin.Subcommand {
+	subcommands := map[string]func() plugin.Subcommand{}
+	if init, isInit := p.(plugin.Init); isInit {
+		subcommands["init"] = func() plugin.Subcommand { return init.GetInitSubcommand() }
+	}
+	if createAPI, isCreateAPI := p.(plugin.CreateAPI); isCreateAPI {
+		subcommands["create api"] = func() plugin.Subcommand { return createAPI.GetCreateAPISubcommand() }
+	}
+	if createWebhook, isCreateWebhook := p.(plugin.CreateWebhook); isCreateWebhook {
+		subcommands["create webhook"] = func() plugin.Subcommand { return createWebhook.GetCreateWebhookSubcommand() }
+	}
+	if edit, isEdit := p.(plugin.Edit); isEdit {
+		subcommands["edit"] = func() plugin.Subcommand { return edit.GetEditSubcommand() }
+	}
+	return subcommands
+}
+
+// testSubcommand checks the subcommands returned by getSubcommand as the CLI uses them, for every project
+// version supported by p: the CLI injects the configuration of the project, binds the flags and updates the
+// context of the help of a new subcommand for every command it builds.
+func testSubcommand(t *testing.T, p plugin.Plugin, getSubcommand func() plugin.Subcommand) {
+	for _, version := range p.SupportedProjectVersions() {
+		subcommand := getSubcommand()
+		if subcommand == nil {
+			t.Fatal("the getter of the subcommand returned nil")
+		}
+
+		c := &config.Config{Version: version, Layout: plugin.KeyFor(p)}
+		if err := recovered(func() { subcommand.InjectConfig(c) }); err != nil {
+			t.Fatalf("InjectConfig of a project version %s configuration: %v", version, err)
+		}
+
+		fs := pflag.NewFlagSet(plugin.KeyFor(p), pflag.ContinueOnError)
+		if err := recovered(func() { subcommand.BindFlags(fs) }); err != nil {
+			t.Fatalf("BindFlags: %v", err)
+		}
+		for _, name := range globalFlags {
+			if fs.Lookup(name) != nil {
+				t.Errorf("BindFlags defines the --%s flag of the CLI", name)
+			}
+		}
+		for _, shorthand := range globalShorthands {
+			if f := fs.ShorthandLookup(shorthand); f != nil {
+				t.Errorf("BindFlags defines the -%s shorthand of the CLI for --%s", shorthand, f.Name)
+			}
+		}
+
+		ctx := &plugin.Context{CommandName: "kubebuilder"}
+		if err := recovered(func() { subcommand.UpdateContext(ctx) }); err != nil {
+			t.Fatalf("UpdateContext: %v", err)
+		}
+		if ctx.CommandName != "kubebuilder" {
+			t.Errorf("UpdateContext changed the command name to %q, which is set by the CLI", ctx.CommandName)
+		}
+	}
+}
+
+// recovered calls f and returns the value it panicked with as an error, if any
+func recovered(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	f()
+	return nil
+}
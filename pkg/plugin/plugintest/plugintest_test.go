@@ -0,0 +1,43 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugintest_test
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin/external/sample"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin/plugintest"
+	pluginv2 "sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v2"
+	pluginv3 "sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3"
+)
+
+// TestPlugins checks that the plugins of this repository conform to the plugin SDK
+func TestPlugins(t *testing.T) {
+	for _, p := range []plugin.Plugin{pluginv2.Plugin{}, pluginv3.Plugin{}, sample.Plugin{}} {
+		t.Run(plugin.KeyFor(p), func(t *testing.T) {
+			plugintest.TestPlugin(t, p)
+		})
+	}
+}
+
+func TestSubcommands(t *testing.T) {
+	subcommands := plugintest.Subcommands(sample.Plugin{})
+	if len(subcommands) != 2 || subcommands["init"] == nil || subcommands["create api"] == nil {
+		t.Errorf("expected the init and create api subcommands of the sample plugin, got %v", subcommands)
+	}
+}
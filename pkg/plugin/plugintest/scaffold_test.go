@@ -0,0 +1,164 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugintest_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugin/plugintest"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/machinery"
+)
+
+var (
+	kustomizationPath = filepath.Join("config", "kustomization.yaml")
+	resourcesMarker   = file.NewMarkerFor(kustomizationPath, "resources")
+)
+
+// kustomization scaffolds a kustomization.yaml with a marker
+type kustomization struct {
+	file.TemplateMixin
+}
+
+func (f *kustomization) SetTemplateDefaults() error {
+	f.Path = kustomizationPath
+	f.TemplateBody = fmt.Sprintf("resources:\n%s\n", resourcesMarker)
+	return nil
+}
+
+// resourceInserter inserts a resource at the marker of the kustomization.yaml
+type resourceInserter struct {
+	file.InserterMixin
+	Resource string
+}
+
+func (f *resourceInserter) GetMarkers() []file.Marker {
+	return []file.Marker{resourcesMarker}
+}
+
+func (f *resourceInserter) GetCodeFragments() file.CodeFragmentsMap {
+	return file.CodeFragmentsMap{resourcesMarker: {fmt.Sprintf("- %s\n", f.Resource)}}
+}
+
+func TestScaffold(t *testing.T) {
+	fs := plugintest.NewMemFS(map[string]string{"README.md": "# project\n"})
+	if err := machinery.NewScaffoldWithFileSystem(fs).Execute(model.NewUniverse(), &kustomization{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, resource := range []string{"role.yaml", "manager.yaml", "role.yaml"} {
+		inserter := &resourceInserter{Resource: resource}
+		inserter.Path = kustomizationPath
+		if err := machinery.NewScaffoldWithFileSystem(fs).Execute(model.NewUniverse(), inserter); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if paths := fs.Paths(); len(paths) != 2 || paths[0] != "README.md" || paths[1] != kustomizationPath {
+		t.Errorf("expected README.md and %s, got %v", kustomizationPath, paths)
+	}
+	content, _ := fs.Get(kustomizationPath)
+	plugintest.AssertMarker(t, content, resourcesMarker)
+	plugintest.AssertInsertedBefore(t, content, resourcesMarker, "- manager.yaml\n")
+	plugintest.AssertInsertedOnce(t, content, "- role.yaml\n")
+	plugintest.AssertGolden(t, filepath.Join("testdata", "kustomization.yaml.golden"), []byte(content))
+	plugintest.AssertGoldenDir(t, filepath.Join("testdata", "project"), fs.Files())
+}
+
+func TestMemFS(t *testing.T) {
+	fs := plugintest.NewMemFS(map[string]string{"./config/rbac/role.yaml": "rules: []\n"})
+
+	for path, expected := range map[string]bool{
+		"config/rbac/role.yaml": true, "config/rbac": true, "config": true, "conf": false, "role.yaml": false,
+	} {
+		if exists, err := fs.Exists(path); err != nil || exists != expected {
+			t.Errorf("expected %s to exist: %t, got %t (%v)", path, expected, exists, err)
+		}
+	}
+	if _, err := fs.Open("config/manager.yaml"); err == nil {
+		t.Error("expected an error opening a missing file")
+	}
+
+	w, err := fs.Create("config/rbac/role.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte("rules:\n"))
+	_, _ = w.Write([]byte("- verbs: [get]\n"))
+	if content, _ := fs.Get("config/rbac/role.yaml"); content != "rules:\n- verbs: [get]\n" {
+		t.Errorf("expected the file to be truncated and written, got %q", content)
+	}
+}
+
+// recorder records the failures of the assertions
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.Errorf(format, args...)
+}
+
+func TestAssertions(t *testing.T) {
+	content := fmt.Sprintf("resources:\n- role.yaml\n%s\n", resourcesMarker)
+
+	for _, tc := range []struct {
+		name   string
+		assert func(testing.TB)
+		failed bool
+	}{
+		{"marker", func(t testing.TB) { plugintest.AssertMarker(t, content, resourcesMarker) }, false},
+		{"missing marker", func(t testing.TB) { plugintest.AssertMarker(t, "resources: []\n", resourcesMarker) }, true},
+		{"duplicated marker", func(t testing.TB) {
+			plugintest.AssertMarker(t, content+content, resourcesMarker)
+		}, true},
+		{"inserted", func(t testing.TB) {
+			plugintest.AssertInsertedBefore(t, content, resourcesMarker, "- role.yaml\n")
+		}, false},
+		{"not inserted", func(t testing.TB) {
+			plugintest.AssertInsertedBefore(t, content, resourcesMarker, "- manager.yaml\n")
+		}, true},
+		{"inserted once", func(t testing.TB) { plugintest.AssertInsertedOnce(t, content, "- role.yaml\n") }, false},
+		{"inserted twice", func(t testing.TB) {
+			plugintest.AssertInsertedOnce(t, content+content, "- role.yaml\n")
+		}, true},
+		{"golden", func(t testing.TB) {
+			plugintest.AssertGolden(t, filepath.Join("testdata", "kustomization.yaml.golden"), []byte("resources: []\n"))
+		}, true},
+		{"missing golden", func(t testing.TB) {
+			plugintest.AssertGolden(t, filepath.Join("testdata", "missing.golden"), []byte(content))
+		}, true},
+		{"golden dir", func(t testing.TB) {
+			plugintest.AssertGoldenDir(t, filepath.Join("testdata", "project"), map[string]string{"README.md": "# project\n"})
+		}, true},
+	} {
+		r := &recorder{TB: t}
+		tc.assert(r)
+		if failed := len(r.failures) != 0; failed != tc.failed {
+			t.Errorf("%s: expected a failure: %t, got %v", tc.name, tc.failed, r.failures)
+		}
+	}
+}
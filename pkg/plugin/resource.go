@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+)
+
+// Resource is the language-agnostic description of a Kubernetes API. Unlike resource.Resource, it holds no
+// information specific to the Go scaffolding, such as packages or import aliases, so that it can be used by
+// plugins scaffolding projects in other languages.
+type Resource struct {
+	// Group is the API group, without the domain. It is empty for the core group.
+	Group string `json:"group,omitempty"`
+	// QualifiedGroup is the API group followed by the domain of the project.
+	QualifiedGroup string `json:"qualifiedGroup,omitempty"`
+	// Version is the API version.
+	Version string `json:"version"`
+	// Kind is the API kind.
+	Kind string `json:"kind"`
+	// Plural is the plural form of the kind, as used in the resource paths.
+	Plural string `json:"plural"`
+	// Namespaced is true if the resource is namespaced.
+	Namespaced bool `json:"namespaced,omitempty"`
+}
+
+// NewResource returns the language-agnostic description of res.
+func NewResource(res *resource.Resource) Resource {
+	return Resource{
+		Group:          res.Group,
+		QualifiedGroup: res.Domain,
+		Version:        res.Version,
+		Kind:           res.Kind,
+		Plural:         res.Plural,
+		Namespaced:     res.Namespaced,
+	}
+}
+
+// APIVersion returns the apiVersion of the objects of the resource, e.g. ship.example.com/v1.
+func (r Resource) APIVersion() string {
+	if r.QualifiedGroup == "" {
+		return r.Version
+	}
+	return r.QualifiedGroup + "/" + r.Version
+}
+
+// Path returns a language-neutral relative path for the files of the resource, e.g. ship/v1/frigate.
+func (r Resource) Path() string {
+	kind := strings.ToLower(r.Kind)
+	if r.Group == "" {
+		return strings.Join([]string{r.Version, kind}, "/")
+	}
+	return strings.Join([]string{r.Group, r.Version, kind}, "/")
+}
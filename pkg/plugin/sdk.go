@@ -0,0 +1,37 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+// SDKVersion is the semantic version of the plugin SDK, the API of the packages used by the plugins outside of
+// this repository, listed in SDKPackages. It is versioned apart from the releases of the CLI:
+//   - the major version is bumped when an exported identifier of the SDK is removed or changed incompatibly,
+//     after being deprecated for at least two minor versions;
+//   - the minor version is bumped when an identifier is added or deprecated;
+//   - the patch version is bumped when the behavior of the SDK is fixed.
+const SDKVersion = "1.1.0"
+
+// SDKPackages are the import paths of the packages of the plugin SDK, relative to the module of kubebuilder.
+// The other packages, e.g. the ones of the go.kubebuilder.io plugins, may change in any release.
+var SDKPackages = []string{
+	"pkg/model",
+	"pkg/model/config",
+	"pkg/model/file",
+	"pkg/model/resource",
+	"pkg/plugin",
+	"pkg/plugin/plugintest",
+	"pkg/plugins/machinery",
+}
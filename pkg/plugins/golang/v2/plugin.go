@@ -27,9 +27,19 @@ const pluginName = "go" + plugins.DefaultNameQualifier
 var (
 	supportedProjectVersions = []string{config.Version2, config.Version3Alpha}
 	pluginVersion            = plugin.Version{Number: 2}
+
+	deprecation = plugin.Deprecation{
+		Message: "The go.kubebuilder.io/v2 plugin is deprecated: it scaffolds the v1beta1 CRDs and webhook " +
+			"configurations removed in Kubernetes 1.22.",
+		Replacement:  "go.kubebuilder.io/v3",
+		MigrationURL: "https://book.kubebuilder.io/migration/plugin/v2_v3.html",
+	}
 )
 
-var _ plugin.Full = Plugin{}
+var (
+	_ plugin.Full               = Plugin{}
+	_ plugin.DeprecationDetails = Plugin{}
+)
 
 // Plugin implements the plugin.Full interface
 type Plugin struct {
@@ -48,6 +58,12 @@ func (Plugin) Version() plugin.Version { return pluginVersion }
 // SupportedProjectVersions returns an array with all project versions supported by the plugin
 func (Plugin) SupportedProjectVersions() []string { return supportedProjectVersions }
 
+// DeprecationWarning implements plugin.Deprecated
+func (Plugin) DeprecationWarning() string { return deprecation.String() }
+
+// Deprecation implements plugin.DeprecationDetails
+func (Plugin) Deprecation() plugin.Deprecation { return deprecation }
+
 // GetInitSubcommand will return the subcommand which is responsible for initializing and common scaffolding
 func (p Plugin) GetInitSubcommand() plugin.InitSubcommand { return &p.initSubcommand }
 
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"strings"
 
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/cmdutil"
 )
@@ -74,7 +75,7 @@ func (s *editScaffolder) Scaffold() error {
 	if str != "" {
 		// false positive
 		// nolint:gosec
-		return ioutil.WriteFile(filename, []byte(str), 0644)
+		return journal.WriteFile(filename, []byte(str), 0644)
 	}
 
 	return nil
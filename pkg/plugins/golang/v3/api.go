@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 
@@ -57,6 +58,8 @@ type createAPISubcommand struct {
 	pattern string
 
 	resource *resource.Options
+	// crdVersionFlag is checked to default the CRD version to v1beta1 for the clusters older than 1.16
+	crdVersionFlag *pflag.Flag
 
 	// Check if we have to scaffold resource and/or controller
 	resourceFlag   *pflag.Flag
@@ -67,8 +70,87 @@ type createAPISubcommand struct {
 	// force indicates that the resource should be created even if it already exists
 	force bool
 
+	// ownerIndex indicates that the objects owned by the controller should be indexed by their owner
+	ownerIndex bool
+
+	// adoption indicates that the controller should adopt the unmanaged objects labeled for its objects and
+	// prune the objects they no longer need
+	adoption bool
+
+	// expectations indicates that the controller should wait for its cache to observe the objects it created
+	// and deleted before reconciling their owner again
+	expectations bool
+
+	// defaultsConfigMap indicates that the controller should load the operator-wide defaults from a ConfigMap,
+	// reconciling its objects again when it changes
+	defaultsConfigMap bool
+
+	// metadataOnlyWatches indicates that the secondary objects of the controller should be watched and cached
+	// as metadata only
+	metadataOnlyWatches bool
+
+	// apiDocs indicates that the reference of the fields of the kind should be documented in docs/api
+	apiDocs bool
+
+	// benchmark indicates that a benchmark of the reconciler against envtest should be scaffolded
+	benchmark bool
+
+	// commonTypes indicates that the spec of the kind should reuse the spec fragments of the common types of
+	// the project, scaffolded once in the common package of its APIs
+	commonTypes bool
+
+	// union indicates that an example discriminated union should be added to the spec of the kind
+	union bool
+
+	// readinessMetrics indicates that the controller should set the Ready condition of the objects of the kind
+	// and record their time to ready, with an SLO rule stub
+	readinessMetrics bool
+
+	// pausable indicates that the controller should skip the reconciliation of the objects of the kind annotated
+	// as paused, setting their Paused condition
+	pausable bool
+
+	// crossNamespaceChildren indicates that the controller should create objects in other namespaces than the one
+	// of the reconciled object, tracked by a label and deleted by a finalizer instead of owner references
+	crossNamespaceChildren bool
+
+	// mocks indicates that the external interactions of the controller should go through an interface, with a
+	// fake generated by counterfeiter and an example unit test using it with the fake client and an interceptor
+	mocks bool
+
+	// sampleTests indicates that tests applying the sample of the kind to envtest should be scaffolded, checking
+	// its server-side defaulting and validation
+	sampleTests bool
+
+	// scaleSubresource holds the specReplicasPath:statusReplicasPath[:labelSelectorPath] of the scale
+	// subresource of the kind, parsed into scale
+	scaleSubresource string
+	scale            *scaffolds.ScaleSubresource
+
+	// rawExtensionFieldsFlag holds the name[:type] of the fields of the spec holding arbitrary JSON objects,
+	// preserved as is by the API server, parsed into rawExtensionFields
+	rawExtensionFieldsFlag []string
+	rawExtensionFields     []scaffolds.RawExtensionField
+
+	// cacheSelector narrows the objects of the kind cached by the manager to a namespace or to a label selector
+	cacheSelector scaffolds.CacheSelector
+
+	// withChildren are the kinds of the objects created or patched by the controller, parsed into children
+	withChildren []string
+	children     []scaffolds.Child
+
+	// resyncPeriodFlag holds the period of the reconciliations of the objects of the kind even if nothing
+	// changed, parsed into resyncPeriod
+	resyncPeriodFlag string
+	resyncPeriod     time.Duration
+
 	// runMake indicates whether to run make or not after scaffolding APIs
 	runMake bool
+
+	// fromFile is the path of a file listing the APIs to scaffold at once
+	fromFile string
+	// batch holds the subcommands scaffolding the APIs of fromFile
+	batch []*createAPISubcommand
 }
 
 var (
@@ -82,6 +164,9 @@ func (p createAPISubcommand) UpdateContext(ctx *plugin.Context) {
 create resource will prompt the user for if it should scaffold the Resource and / or Controller.  To only
 scaffold a Controller for an existing Resource, select "n" for Resource.  To only define
 the schema for a Resource without writing a Controller, select "n" for Controller.
+The --resource and --controller flags answer the prompts, and the default answers of the
+project, set by edit --default-resource and --default-controller, answer the ones the flags
+do not.
 
 After the scaffold is written, api will run make on the project.
 `
@@ -102,12 +187,97 @@ After the scaffold is written, api will run make on the project.
 
   # Regenerate code and run against the Kubernetes cluster configured by ~/.kube/config
   make run
+
+  # Create a frigates API with a scale subresource scaled by a HorizontalPodAutoscaler
+  # through the .spec.replicas, .status.replicas and .status.selector fields
+  %s create api --group ship --version v1beta1 --kind Frigate \
+      --scale-subresource=.spec.replicas:.status.replicas:.status.selector
+
+  # Create a frigates API whose controller only caches and reconciles the frigates of the fleet
+  # namespace labeled with tier=production
+  %s create api --group ship --version v1beta1 --kind Frigate \
+      --cache-namespace fleet --cache-label-selector tier=production
+
+  # Create a frigates API whose controller creates or patches a Deployment and a Service
+  # controlled by each frigate
+  %s create api --group ship --version v1beta1 --kind Frigate \
+      --with-child Deployment --with-child Service
+
+  # Create a frigates API whose spec has an example discriminated union, validated by CEL
+  # validation rules of the CRD and by its validating webhook
+  %s create api --group ship --version v1beta1 --kind Frigate --union
+  %s create webhook --group ship --version v1beta1 --kind Frigate --programmatic-validation
+
+  # Create a frigates API whose spec has a values field holding an arbitrary JSON object and a
+  # template field holding a Kubernetes object, both preserved as is by the API server
+  %s create api --group ship --version v1beta1 --kind Frigate \
+      --raw-extension-field values --raw-extension-field template:resource
+
+  # Create a certificates API whose controller reconciles each certificate again every 12 hours,
+  # recording the time of the next reconciliation in its status
+  %s create api --group security --version v1 --kind Certificate --resync-period 12h
+
+  # Create a clusters API whose controller sets the Ready condition of each cluster and records
+  # its time to ready, with a PrometheusRule stub of its SLO
+  %s create api --group infra --version v1 --kind Cluster --readiness-metrics
+
+  # Create a databases API whose controller skips the reconciliation of the databases annotated
+  # with <domain>/paused=true, e.g. during a maintenance window, setting their Paused condition
+  %s create api --group storage --version v1 --kind Database --pausable
+
+  # Create a tenants API whose controller creates ConfigMaps in other namespaces than the one of
+  # each tenant, tracked by a label and deleted by a finalizer of the tenant
+  %s create api --group platform --version v1 --kind Tenant --cross-namespace-children
+
+  # Create a buckets API whose controller calls the external storage through an interface, with
+  # its fake and an example unit test using the fake client instead of envtest
+  %s create api --group storage --version v1 --kind Bucket --with-mocks
+
+  # Create a frigates API with tests applying its sample to envtest, checking that it is
+  # defaulted the same way as the objects created from its Go type and that the invalid
+  # objects are rejected
+  %s create api --group ship --version v1beta1 --kind Frigate --sample-tests
+
+  # Create the APIs listed in gvks.yaml, running make once at the end. The options
+  # of the flags apply to the APIs that do not set them:
+  #   - group: ship
+  #     version: v1beta1
+  #     kind: Frigate
+  #   - group: ship
+  #     version: v1
+  #     kind: Destroyer
+  #     namespaced: false
+  #     controller: false
+  %s create api --from-file gvks.yaml
 	`,
-		ctx.CommandName)
+		ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName,
+		ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName,
+		ctx.CommandName, ctx.CommandName)
+	ctx.Docs = `Documentation of the options of the scaffolded controllers:
+  --adoption                       https://book.kubebuilder.io/reference/adoption.html
+  --owner-index, --expectations    https://book.kubebuilder.io/reference/expectations.html
+  --defaults-configmap             https://book.kubebuilder.io/reference/defaults-configmap.html
+  --metadata-only-watches          https://book.kubebuilder.io/reference/metadata-only-watches.html
+  --api-docs                       https://book.kubebuilder.io/reference/api-docs.html
+  --benchmark                      https://book.kubebuilder.io/reference/benchmarks.html
+  --common-types                   https://book.kubebuilder.io/reference/common-types.html
+  --union                          https://book.kubebuilder.io/reference/unions.html
+  --scale-subresource              https://book.kubebuilder.io/reference/scale-subresource.html
+  --raw-extension-field            https://book.kubebuilder.io/reference/raw-extension-fields.html
+  --cache-namespace,               https://book.kubebuilder.io/reference/cache-selectors.html
+  --cache-label-selector
+  --with-child                     https://book.kubebuilder.io/reference/child-objects.html
+  --resync-period                  https://book.kubebuilder.io/reference/periodic-reconciliations.html
+  --readiness-metrics              https://book.kubebuilder.io/reference/time-to-ready.html
+  --pausable                       https://book.kubebuilder.io/reference/paused-reconciliations.html
+  --cross-namespace-children       https://book.kubebuilder.io/reference/cross-namespace-children.html
+  --with-mocks                     https://book.kubebuilder.io/reference/controller-unit-tests.html
+  --sample-tests                   https://book.kubebuilder.io/reference/sample-tests.html
+`
 }
 
 func (p *createAPISubcommand) BindFlags(fs *pflag.FlagSet) {
-	fs.BoolVar(&p.runMake, "make", true, "if true, run make after generating files")
+	fs.BoolVar(&p.runMake, "make", true, "if true, run make, or the task runner of the project, after generating files")
 
 	fs.BoolVar(&p.doResource, "resource", true,
 		"if set, generate the resource without prompting the user")
@@ -124,13 +294,81 @@ func (p *createAPISubcommand) BindFlags(fs *pflag.FlagSet) {
 
 	fs.BoolVar(&p.force, "force", false,
 		"attempt to create resource even if it already exists")
+	fs.BoolVar(&p.ownerIndex, "owner-index", false,
+		"if set, index the objects owned by the controller by their owner and list them with a field selector")
+	fs.BoolVar(&p.adoption, "adoption", false,
+		"if set, adopt the unmanaged objects labeled for the reconciled object and prune the objects it no longer needs")
+	fs.BoolVar(&p.expectations, "expectations", false,
+		"if set, wait for the cache to observe the objects created and deleted by the controller before reconciling again")
+	fs.BoolVar(&p.defaultsConfigMap, "defaults-configmap", false,
+		"if set, load the operator-wide defaults of the controller from a ConfigMap of the namespace of the manager, "+
+			"watched to reconcile the objects again when it changes")
+	fs.BoolVar(&p.metadataOnlyWatches, "metadata-only-watches", false,
+		"if set, watch and cache the Secrets owned by the controller as metadata only, saving the memory of their data")
+	fs.BoolVar(&p.apiDocs, "api-docs", false,
+		"if set, document the fields of the kind in docs/api/<kind>.md, with their defaults and validation "+
+			"generated from the markers by make api-docs")
+	fs.BoolVar(&p.benchmark, "benchmark", false,
+		"if set, scaffold a benchmark measuring the throughput and the latency of the reconciler against envtest, "+
+			"run by make bench")
+	fs.BoolVar(&p.commonTypes, "common-types", false,
+		"if set, reuse the common types of the project, e.g. the image, resource requirements and references, "+
+			"in the spec of the kind, scaffolding them once in api/common (apis/common for multigroup projects)")
+	fs.BoolVar(&p.union, "union", false,
+		"if set, add an example discriminated union to the spec of the kind, of which exactly one member is set, "+
+			"validated by CEL validation rules of the CRD and by the validating webhook")
+	fs.StringVar(&p.scaleSubresource, "scale-subresource", "",
+		"enable the scale subresource of the kind with specReplicasPath:statusReplicasPath[:labelSelectorPath], "+
+			"adding these fields to its types and scaffolding a sample HorizontalPodAutoscaler. "+
+			"Without a value, defaults to "+scaffolds.DefaultScaleSubresource)
+	fs.Lookup("scale-subresource").NoOptDefVal = scaffolds.DefaultScaleSubresource
+	fs.StringArrayVar(&p.rawExtensionFieldsFlag, "raw-extension-field", nil,
+		"name[:type] of a field of the spec holding an arbitrary JSON object, type object and the default, or a "+
+			"Kubernetes object, type resource, whose fields are preserved as is by the API server instead of "+
+			"being pruned, e.g. values or template:resource. May be set more than once")
+	fs.StringVar(&p.cacheSelector.Namespace, "cache-namespace", "",
+		"if set, only cache the objects of the kind of this namespace in the manager")
+	fs.StringVar(&p.cacheSelector.Labels, "cache-label-selector", "",
+		"if set, only cache the objects of the kind matching this label selector in the manager, e.g. tier=production")
+	fs.StringArrayVar(&p.withChildren, "with-child", nil,
+		"kind of the objects created or patched by the controller, which controls them, applying their desired "+
+			"state when the spec changes, e.g. Deployment. May be set more than once")
+	fs.StringVar(&p.resyncPeriodFlag, "resync-period", "",
+		"if set, reconcile each object of the kind again after this period, spread by a jitter, even if nothing "+
+			"changed, recording the time of its next reconciliation in its status, e.g. 12h")
+	fs.BoolVar(&p.readinessMetrics, "readiness-metrics", false,
+		"if set, set the Ready condition of the objects of the kind in the controller and record their time to "+
+			"ready as a histogram, scaffolding a PrometheusRule stub of its SLO in config/prometheus")
+	fs.BoolVar(&p.pausable, "pausable", false,
+		"if set, skip the reconciliation of the objects of the kind annotated with <domain>/paused=true in the "+
+			"controller, setting their Paused condition until the annotation is removed")
+	fs.BoolVar(&p.crossNamespaceChildren, "cross-namespace-children", false,
+		"if set, create ConfigMaps in other namespaces than the one of the reconciled object in the controller, "+
+			"tracked by a label since owner references can not cross namespaces, and deleted by a finalizer")
+	fs.BoolVar(&p.mocks, "with-mocks", false,
+		"if set, call the external systems through an interface in the controller, and scaffold its fake generated "+
+			"by counterfeiter and an example unit test using it with the fake client and an interceptor")
+	fs.BoolVar(&p.sampleTests, "sample-tests", false,
+		"if set, scaffold tests applying the sample of the kind to envtest, checking that it is defaulted the same "+
+			"way as the objects created from its Go type, and that the invalid objects are rejected")
 	p.resource = &resource.Options{}
 	fs.StringVar(&p.resource.Kind, "kind", "", "resource Kind")
 	fs.StringVar(&p.resource.Group, "group", "", "resource Group")
+	fs.StringVar(&p.resource.GroupPackage, "group-package", "",
+		"Go package name of the resource Group, defaults to the Group without dashes and dots")
 	fs.StringVar(&p.resource.Version, "version", "", "resource Version")
 	fs.BoolVar(&p.resource.Namespaced, "namespaced", true, "resource is namespaced")
 	fs.StringVar(&p.resource.API.CRDVersion, "crd-version", defaultCRDVersion,
-		"version of CustomResourceDefinition to scaffold. Options: [v1, v1beta1]")
+		"version of CustomResourceDefinition to scaffold. Options: [v1, v1beta1], "+
+			"defaults to v1beta1 if the minimum Kubernetes version of the project is older than 1.16")
+	p.crdVersionFlag = fs.Lookup("crd-version")
+
+	fs.StringVar(&p.fromFile, "from-file", "",
+		"path of a YAML file listing the APIs to create, with their group, version, kind and optionally "+
+			"groupPackage, crdVersion, namespaced, resource, controller, ownerIndex, adoption, expectations, "+
+			"defaultsConfigMap, metadataOnlyWatches, apiDocs, benchmark, commonTypes, union, scaleSubresource, "+
+			"rawExtensionFields, cacheNamespace, cacheLabelSelector, withChildren, resyncPeriod, readinessMetrics, "+
+			"pausable, crossNamespaceChildren, withMocks and sampleTests, whose defaults are the flags")
 }
 
 func (p *createAPISubcommand) InjectConfig(c *config.Config) {
@@ -142,12 +380,9 @@ func (p *createAPISubcommand) Run() error {
 }
 
 func (p *createAPISubcommand) Validate() error {
-	if err := p.resource.Validate(); err != nil {
-		return err
-	}
-
-	if p.resource.Group == "" && p.config.Domain == "" {
-		return fmt.Errorf("can not have group and domain both empty")
+	if p.config.ManifestsOnly {
+		return errors.New("create api is not supported by projects initialized with --manifests-only, " +
+			"whose Go code lives in another repository")
 	}
 
 	// check if main.go is present in the root directory
@@ -155,18 +390,211 @@ func (p *createAPISubcommand) Validate() error {
 		return fmt.Errorf("%s file should present in the root directory", DefaultMainPath)
 	}
 
+	// The default CRD version is served by the minimum Kubernetes version of the project
+	if !p.crdVersionFlag.Changed {
+		p.resource.API.CRDVersion = scaffolds.KubernetesProfileFor(p.config).CRDVersion
+	}
+
+	// The default answers of the PROJECT file are used when the flags are not provided, including as the
+	// defaults of the APIs of the file
+	promptResource, promptController := !p.resourceFlag.Changed, !p.controllerFlag.Changed
+	if promptController && p.config.Pattern == scaffolds.PatternAggregatedAPIServer {
+		p.doController, promptController = false, false
+	}
+	if defaults := p.config.CreateAPI; defaults != nil {
+		if promptResource && defaults.Resource != nil {
+			p.doResource, promptResource = *defaults.Resource, false
+		}
+		if promptController && defaults.Controller != nil {
+			p.doController, promptController = *defaults.Controller, false
+		}
+	}
+
+	// The APIs of the file are scaffolded without prompting the user
+	if p.fromFile != "" {
+		return p.validateBatch()
+	}
+
+	if err := p.validateResource(); err != nil {
+		return err
+	}
+
 	// TODO: re-evaluate whether y/n input still makes sense. We should probably always
 	// scaffold the resource and controller.
 	reader := bufio.NewReader(os.Stdin)
-	if !p.resourceFlag.Changed {
+	if promptResource {
 		fmt.Println("Create Resource [y/n]")
 		p.doResource = util.YesNo(reader)
 	}
-	if !p.controllerFlag.Changed {
+	if promptController {
 		fmt.Println("Create Controller [y/n]")
 		p.doController = util.YesNo(reader)
 	}
 
+	return p.validateScaffold()
+}
+
+// validateResource checks that the resource options are valid and compatible with the project.
+func (p *createAPISubcommand) validateResource() error {
+	if err := p.resource.Validate(); err != nil {
+		return err
+	}
+
+	if p.resource.Group == "" && p.config.Domain == "" {
+		return fmt.Errorf("can not have group and domain both empty")
+	}
+
+	// Check that the group package, if provided, matches the one already used by the group
+	if p.resource.GroupPackage != "" {
+		if groupPackage := p.config.GetGroupPackage(p.resource.Group); groupPackage != "" &&
+			groupPackage != p.resource.GroupPackage {
+			return fmt.Errorf("group %q already uses the package %q", p.resource.Group, groupPackage)
+		}
+		if p.config.HasGroup(p.resource.Group) && p.config.GetGroupPackage(p.resource.Group) == "" {
+			return fmt.Errorf("group %q already uses the default package", p.resource.Group)
+		}
+	}
+
+	return nil
+}
+
+// validateScaffold checks that the resource and controller can be scaffolded in the project.
+func (p *createAPISubcommand) validateScaffold() error {
+	if p.ownerIndex && !(p.doResource && p.doController) {
+		return errors.New("--owner-index requires scaffolding both the resource and the controller")
+	}
+	if p.adoption && !(p.doResource && p.doController) {
+		return errors.New("--adoption requires scaffolding both the resource and the controller")
+	}
+	if p.defaultsConfigMap && !(p.doResource && p.doController) {
+		return errors.New("--defaults-configmap requires scaffolding both the resource and the controller")
+	}
+	if p.metadataOnlyWatches && !(p.doResource && p.doController) {
+		return errors.New("--metadata-only-watches requires scaffolding both the resource and the controller")
+	}
+	if p.apiDocs && !p.doResource {
+		return errors.New("--api-docs requires scaffolding the resource")
+	}
+	if p.commonTypes && !p.doResource {
+		return errors.New("--common-types requires scaffolding the resource")
+	}
+	if p.union && !p.doResource {
+		return errors.New("--union requires scaffolding the resource")
+	}
+	if p.scaleSubresource != "" {
+		if !p.doResource {
+			return errors.New("--scale-subresource requires scaffolding the resource")
+		}
+		scale, err := scaffolds.ParseScaleSubresource(p.scaleSubresource)
+		if err != nil {
+			return err
+		}
+		// The union is added to the spec as the source field
+		if p.union && scale.SpecReplicas().JSONName == "source" {
+			return errors.New("--union adds the source field to the spec, which the spec replicas path can not use")
+		}
+		p.scale = scale
+	}
+	if len(p.rawExtensionFieldsFlag) != 0 {
+		if err := p.validateRawExtensionFields(); err != nil {
+			return err
+		}
+	}
+	if !p.cacheSelector.IsEmpty() {
+		if !p.doController {
+			return errors.New("--cache-namespace and --cache-label-selector require scaffolding the controller")
+		}
+		if p.cacheSelector.Namespace != "" && p.doResource && !p.resource.Namespaced {
+			return errors.New("--cache-namespace requires a namespaced resource")
+		}
+		if err := p.cacheSelector.Validate(); err != nil {
+			return err
+		}
+	}
+	if len(p.withChildren) != 0 {
+		if err := p.validateChildren(); err != nil {
+			return err
+		}
+	}
+	if p.resyncPeriodFlag != "" {
+		if !(p.doResource && p.doController) {
+			return errors.New("--resync-period requires scaffolding both the resource and the controller")
+		}
+		period, err := scaffolds.ParseResyncPeriod(p.resyncPeriodFlag)
+		if err != nil {
+			return err
+		}
+		if p.scale != nil && (p.scale.StatusReplicasPath == ".status.nextReconcileTime" ||
+			p.scale.LabelSelectorPath == ".status.nextReconcileTime") {
+			return errors.New("--resync-period adds the nextReconcileTime field to the status, " +
+				"which the --scale-subresource paths can not use")
+		}
+		p.resyncPeriod = period
+	}
+	if p.readinessMetrics {
+		if !(p.doResource && p.doController) {
+			return errors.New("--readiness-metrics requires scaffolding both the resource and the controller")
+		}
+		if p.scale != nil && (p.scale.StatusReplicasPath == ".status.conditions" ||
+			p.scale.LabelSelectorPath == ".status.conditions") {
+			return errors.New("--readiness-metrics adds the conditions field to the status, " +
+				"which the --scale-subresource paths can not use")
+		}
+	}
+	if p.pausable {
+		if !(p.doResource && p.doController) {
+			return errors.New("--pausable requires scaffolding both the resource and the controller")
+		}
+		if p.scale != nil && (p.scale.StatusReplicasPath == ".status.conditions" ||
+			p.scale.LabelSelectorPath == ".status.conditions") {
+			return errors.New("--pausable adds the conditions field to the status, " +
+				"which the --scale-subresource paths can not use")
+		}
+	}
+	if p.crossNamespaceChildren {
+		if !(p.doResource && p.doController) {
+			return errors.New("--cross-namespace-children requires scaffolding both the resource and the controller")
+		}
+		// The objects of a cluster-scoped owner in any namespace can be owned by it
+		if !p.resource.Namespaced {
+			return errors.New("--cross-namespace-children requires a namespaced resource, the objects of a " +
+				"cluster-scoped resource can be owned by it in any namespace, use --with-child instead")
+		}
+	}
+	if p.mocks && !(p.doResource && p.doController) {
+		return errors.New("--with-mocks requires scaffolding both the resource and the controller")
+	}
+	if p.sampleTests && !(p.doResource && p.doController) {
+		return errors.New("--sample-tests requires scaffolding both the resource and the controller")
+	}
+	if p.benchmark && !(p.doResource && p.doController) {
+		return errors.New("--benchmark requires scaffolding both the resource and the controller")
+	}
+	if p.expectations {
+		if !(p.doResource && p.doController) {
+			return errors.New("--expectations requires scaffolding both the resource and the controller")
+		}
+		// Both scaffold another way to manage the ConfigMaps of the example controller
+		if p.ownerIndex || p.adoption {
+			return errors.New("--expectations can not be combined with --owner-index or --adoption")
+		}
+	}
+
+	// The aggregated API servers only serve the resources, which are reconciled by the controllers of operators
+	if p.config.Pattern == scaffolds.PatternAggregatedAPIServer {
+		if p.doController || p.pattern != "" || p.apiDocs || p.commonTypes || p.union || p.scaleSubresource != "" ||
+			len(p.rawExtensionFieldsFlag) != 0 {
+			return fmt.Errorf("projects initialized with --pattern=%s only scaffold resources, "+
+				"reconcile them with the controllers of an operator project", scaffolds.PatternAggregatedAPIServer)
+		}
+		for _, res := range p.config.Resources {
+			if res.Group == p.resource.Group && res.Kind == p.resource.Kind && res.Version != p.resource.Version {
+				return fmt.Errorf("kind %s is already served in version %s, "+
+					"the aggregated API server serves a kind in a single version", res.Kind, res.Version)
+			}
+		}
+	}
+
 	// In case we want to scaffold a resource API we need to do some checks
 	if p.doResource {
 		// Check that resource doesn't exist or flag force was set
@@ -181,6 +609,11 @@ func (p *createAPISubcommand) Validate() error {
 				"to enable multi-group visit kubebuilder.io/migration/multi-group.html")
 		}
 
+		if scaffolds.SupportsPreV1Kubernetes(p.config) && p.resource.API.CRDVersion == "v1" {
+			return fmt.Errorf("v1 CRDs require Kubernetes 1.16 or later, the minimum Kubernetes version "+
+				"of the project is %s, use --crd-version=v1beta1", p.config.MinKubernetesVersion)
+		}
+
 		// Check CRDVersion against all other CRDVersions in p.config for compatibility.
 		if !p.config.IsCRDVersionCompatible(p.resource.API.CRDVersion) {
 			return fmt.Errorf("only one CRD version can be used for all resources, cannot add %q",
@@ -191,6 +624,61 @@ func (p *createAPISubcommand) Validate() error {
 	return nil
 }
 
+// validateRawExtensionFields checks that the raw extension fields can be added to the spec along with the other
+// fields of the options.
+func (p *createAPISubcommand) validateRawExtensionFields() error {
+	if !p.doResource {
+		return errors.New("--raw-extension-field requires scaffolding the resource")
+	}
+	fields, err := scaffolds.ParseRawExtensionFields(p.rawExtensionFieldsFlag)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if p.commonTypes && field.JSONName == "container" {
+			return errors.New("--common-types adds the container field to the spec, " +
+				"which --raw-extension-field can not use")
+		}
+		if p.union && field.JSONName == "source" {
+			return errors.New("--union adds the source field to the spec, which --raw-extension-field can not use")
+		}
+		if p.scale != nil && field.JSONName == p.scale.SpecReplicas().JSONName {
+			return fmt.Errorf("--scale-subresource adds the %s field to the spec, "+
+				"which --raw-extension-field can not use", field.JSONName)
+		}
+	}
+	p.rawExtensionFields = fields
+	return nil
+}
+
+// validateChildren checks that the children can be scaffolded along with the other options of the controller.
+func (p *createAPISubcommand) validateChildren() error {
+	if !(p.doResource && p.doController) {
+		return errors.New("--with-child requires scaffolding both the resource and the controller")
+	}
+	children, err := scaffolds.ParseChildren(p.withChildren)
+	if err != nil {
+		return err
+	}
+	// The benchmark and the expectations test reconcile against envtest, which rejects the children until the
+	// user sets their desired state
+	if p.benchmark || p.expectations {
+		return errors.New("--with-child can not be combined with --benchmark or --expectations")
+	}
+	for _, child := range children {
+		// Both scaffold another way to manage the ConfigMaps and the Secrets of the example controller
+		if child.Kind == "ConfigMap" && (p.ownerIndex || p.adoption || p.crossNamespaceChildren) {
+			return errors.New("--with-child ConfigMap can not be combined with --owner-index, --adoption or " +
+				"--cross-namespace-children")
+		}
+		if child.Kind == "Secret" && p.metadataOnlyWatches {
+			return errors.New("--with-child Secret can not be combined with --metadata-only-watches")
+		}
+	}
+	p.children = children
+	return nil
+}
+
 func (p *createAPISubcommand) GetScaffolder() (cmdutil.Scaffolder, error) {
 	// Load the boilerplate
 	bp, err := ioutil.ReadFile(filepath.Join("hack", "boilerplate.go.txt")) // nolint:gosec
@@ -209,9 +697,47 @@ func (p *createAPISubcommand) GetScaffolder() (cmdutil.Scaffolder, error) {
 		return nil, fmt.Errorf("unknown pattern %q", p.pattern)
 	}
 
+	if p.fromFile != "" {
+		scaffolders := make(batchScaffolder, 0, len(p.batch))
+		for _, sub := range p.batch {
+			res := sub.resource.NewResource(p.config, sub.doResource)
+			scaffolders = append(scaffolders, scaffolds.NewAPIScaffolder(p.config, string(bp), res, sub.apiOptions(),
+				plugins))
+		}
+		return scaffolders, nil
+	}
+
 	// Create the actual resource from the resource options
 	res := p.resource.NewResource(p.config, p.doResource)
-	return scaffolds.NewAPIScaffolder(p.config, string(bp), res, p.doResource, p.doController, p.force, plugins), nil
+	return scaffolds.NewAPIScaffolder(p.config, string(bp), res, p.apiOptions(), plugins), nil
+}
+
+// apiOptions returns the options of the files scaffolded for the resource of the subcommand
+func (p *createAPISubcommand) apiOptions() scaffolds.APIOptions {
+	return scaffolds.APIOptions{
+		DoResource:             p.doResource,
+		DoController:           p.doController,
+		Force:                  p.force,
+		OwnerIndex:             p.ownerIndex,
+		Adoption:               p.adoption,
+		Expectations:           p.expectations,
+		DefaultsConfigMap:      p.defaultsConfigMap,
+		MetadataOnlyWatches:    p.metadataOnlyWatches,
+		APIDocs:                p.apiDocs,
+		Benchmark:              p.benchmark,
+		CommonTypes:            p.commonTypes,
+		Union:                  p.union,
+		ReadinessMetrics:       p.readinessMetrics,
+		Pausable:               p.pausable,
+		CrossNamespaceChildren: p.crossNamespaceChildren,
+		Mocks:                  p.mocks,
+		SampleTests:            p.sampleTests,
+		Scale:                  p.scale,
+		RawExtensionFields:     p.rawExtensionFields,
+		CacheSelector:          p.cacheSelector,
+		Children:               p.children,
+		ResyncPeriod:           p.resyncPeriod,
+	}
 }
 
 func (p *createAPISubcommand) PostScaffold() error {
@@ -232,7 +758,22 @@ func (p *createAPISubcommand) PostScaffold() error {
 	}
 
 	if p.runMake {
-		return util.RunCmd("Running make", "make")
+		if err := runTargets(p.config); err != nil {
+			return err
+		}
+		if p.apiDocs || p.batchHasAPIDocs() {
+			return runTargets(p.config, "api-docs")
+		}
 	}
 	return nil
 }
+
+// batchHasAPIDocs returns whether one of the APIs of the file provided with --from-file is documented
+func (p *createAPISubcommand) batchHasAPIDocs() bool {
+	for _, sub := range p.batch {
+		if sub.apiDocs {
+			return true
+		}
+	}
+	return false
+}
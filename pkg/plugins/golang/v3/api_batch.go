@@ -0,0 +1,211 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/cmdutil"
+)
+
+// apiEntry is an API of the file provided with --from-file. The options it does not set default to the
+// flags of the command.
+type apiEntry struct {
+	Group                  string   `json:"group,omitempty"`
+	Version                string   `json:"version"`
+	Kind                   string   `json:"kind"`
+	GroupPackage           string   `json:"groupPackage,omitempty"`
+	CRDVersion             string   `json:"crdVersion,omitempty"`
+	Namespaced             *bool    `json:"namespaced,omitempty"`
+	Resource               *bool    `json:"resource,omitempty"`
+	Controller             *bool    `json:"controller,omitempty"`
+	OwnerIndex             *bool    `json:"ownerIndex,omitempty"`
+	Adoption               *bool    `json:"adoption,omitempty"`
+	Expectations           *bool    `json:"expectations,omitempty"`
+	DefaultsConfigMap      *bool    `json:"defaultsConfigMap,omitempty"`
+	MetadataOnlyWatches    *bool    `json:"metadataOnlyWatches,omitempty"`
+	APIDocs                *bool    `json:"apiDocs,omitempty"`
+	Benchmark              *bool    `json:"benchmark,omitempty"`
+	CommonTypes            *bool    `json:"commonTypes,omitempty"`
+	Union                  *bool    `json:"union,omitempty"`
+	ScaleSubresource       string   `json:"scaleSubresource,omitempty"`
+	RawExtensionFields     []string `json:"rawExtensionFields,omitempty"`
+	CacheNamespace         string   `json:"cacheNamespace,omitempty"`
+	CacheLabelSelector     string   `json:"cacheLabelSelector,omitempty"`
+	WithChildren           []string `json:"withChildren,omitempty"`
+	ResyncPeriod           string   `json:"resyncPeriod,omitempty"`
+	ReadinessMetrics       *bool    `json:"readinessMetrics,omitempty"`
+	Pausable               *bool    `json:"pausable,omitempty"`
+	CrossNamespaceChildren *bool    `json:"crossNamespaceChildren,omitempty"`
+	WithMocks              *bool    `json:"withMocks,omitempty"`
+	SampleTests            *bool    `json:"sampleTests,omitempty"`
+}
+
+// String implements fmt.Stringer
+func (e apiEntry) String() string {
+	return fmt.Sprintf("group %q, version %q, kind %q", e.Group, e.Version, e.Kind)
+}
+
+// readAPIEntries reads the list of APIs of path.
+func readAPIEntries(path string) ([]apiEntry, error) {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	var entries []apiEntry
+	if err := yaml.UnmarshalStrict(content, &entries); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s does not contain any API", path)
+	}
+	return entries, nil
+}
+
+// forEntry returns the subcommand scaffolding the API of entry, with the flags of p as default options.
+func (p *createAPISubcommand) forEntry(entry apiEntry) *createAPISubcommand {
+	sub := *p
+	sub.fromFile = ""
+	sub.batch = nil
+	sub.resource = &resource.Options{
+		Group:        entry.Group,
+		GroupPackage: entry.GroupPackage,
+		Version:      entry.Version,
+		Kind:         entry.Kind,
+		Namespaced:   p.resource.Namespaced,
+		API:          p.resource.API,
+	}
+	if entry.CRDVersion != "" {
+		sub.resource.API.CRDVersion = entry.CRDVersion
+	}
+	if entry.Namespaced != nil {
+		sub.resource.Namespaced = *entry.Namespaced
+	}
+	if entry.Resource != nil {
+		sub.doResource = *entry.Resource
+	}
+	if entry.Controller != nil {
+		sub.doController = *entry.Controller
+	}
+	if entry.OwnerIndex != nil {
+		sub.ownerIndex = *entry.OwnerIndex
+	}
+	if entry.Adoption != nil {
+		sub.adoption = *entry.Adoption
+	}
+	if entry.Expectations != nil {
+		sub.expectations = *entry.Expectations
+	}
+	if entry.DefaultsConfigMap != nil {
+		sub.defaultsConfigMap = *entry.DefaultsConfigMap
+	}
+	if entry.MetadataOnlyWatches != nil {
+		sub.metadataOnlyWatches = *entry.MetadataOnlyWatches
+	}
+	if entry.APIDocs != nil {
+		sub.apiDocs = *entry.APIDocs
+	}
+	if entry.Benchmark != nil {
+		sub.benchmark = *entry.Benchmark
+	}
+	if entry.CommonTypes != nil {
+		sub.commonTypes = *entry.CommonTypes
+	}
+	if entry.Union != nil {
+		sub.union = *entry.Union
+	}
+	if entry.ScaleSubresource != "" {
+		sub.scaleSubresource = entry.ScaleSubresource
+	}
+	if entry.RawExtensionFields != nil {
+		sub.rawExtensionFieldsFlag = entry.RawExtensionFields
+	}
+	if entry.CacheNamespace != "" {
+		sub.cacheSelector.Namespace = entry.CacheNamespace
+	}
+	if entry.CacheLabelSelector != "" {
+		sub.cacheSelector.Labels = entry.CacheLabelSelector
+	}
+	if entry.WithChildren != nil {
+		sub.withChildren = entry.WithChildren
+	}
+	if entry.ResyncPeriod != "" {
+		sub.resyncPeriodFlag = entry.ResyncPeriod
+	}
+	if entry.ReadinessMetrics != nil {
+		sub.readinessMetrics = *entry.ReadinessMetrics
+	}
+	if entry.Pausable != nil {
+		sub.pausable = *entry.Pausable
+	}
+	if entry.CrossNamespaceChildren != nil {
+		sub.crossNamespaceChildren = *entry.CrossNamespaceChildren
+	}
+	if entry.WithMocks != nil {
+		sub.mocks = *entry.WithMocks
+	}
+	if entry.SampleTests != nil {
+		sub.sampleTests = *entry.SampleTests
+	}
+	return &sub
+}
+
+// validateBatch validates the APIs of the file provided with --from-file. Each API is validated against the
+// project including the previous ones, so that they are scaffolded as if the command was run once per API.
+func (p *createAPISubcommand) validateBatch() error {
+	if p.resource.Group != "" || p.resource.Version != "" || p.resource.Kind != "" ||
+		p.resource.GroupPackage != "" {
+		return errors.New("--group, --version, --kind and --group-package can not be used with --from-file")
+	}
+
+	entries, err := readAPIEntries(p.fromFile)
+	if err != nil {
+		return err
+	}
+	for i, entry := range entries {
+		sub := p.forEntry(entry)
+		if err := sub.validateResource(); err != nil {
+			return fmt.Errorf("%s: API %d (%s): %v", p.fromFile, i+1, entry, err)
+		}
+		if err := sub.validateScaffold(); err != nil {
+			return fmt.Errorf("%s: API %d (%s): %v", p.fromFile, i+1, entry, err)
+		}
+		if sub.doResource {
+			p.config.UpdateResources(sub.resource.NewResource(p.config, true).Data())
+		}
+		p.batch = append(p.batch, sub)
+	}
+	return nil
+}
+
+// batchScaffolder runs the scaffolders of the APIs of the file provided with --from-file.
+type batchScaffolder []cmdutil.Scaffolder
+
+// Scaffold implements cmdutil.Scaffolder
+func (s batchScaffolder) Scaffold() error {
+	for _, scaffolder := range s {
+		if err := scaffolder.Scaffold(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
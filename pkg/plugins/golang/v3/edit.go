@@ -18,9 +18,12 @@ package v3
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/pflag"
 
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/validation"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds"
@@ -31,6 +34,48 @@ type editSubcommand struct {
 	config *config.Config
 
 	multigroup bool
+
+	// groupRegistration adds the versions of each group to the scheme through a registration package of the group
+	groupRegistration bool
+
+	// projectName, domain and namespace rename the project, the domain of its API groups and the namespace
+	// it is installed in, when set
+	projectName string
+	domain      string
+	namespace   string
+	dryRun      bool
+
+	// fixHeaders applies the license header of the boilerplate to the Go files, after replacing the year and
+	// the owner of its copyright line when set
+	fixHeaders bool
+	year       string
+	owner      string
+
+	// minKubernetesVersion sets the oldest Kubernetes version supported by the project, when the flag is provided
+	minKubernetesVersion string
+
+	// metricsExposure exposes the metrics endpoint outside of the cluster, or changes its exposure, when set
+	metricsExposure scaffolds.MetricsExposure
+
+	// agent adds the agent mode to the manager, deployed by config/agent with a Deployment or a DaemonSet, when set
+	agent string
+
+	// webhookDev scaffolds hack/webhook-dev, serving the webhooks of the manager run by the developer to a cluster
+	webhookDev bool
+
+	// replace and dropReplace add and remove the replace directives of go.mod recorded in the PROJECT file
+	replace     []string
+	dropReplace []string
+
+	// defaultResource and defaultController set the default answers of the resource and controller prompts of
+	// create api, yes, no or prompt, when set
+	defaultResource   string
+	defaultController string
+
+	// wiring changes how create api and create webhook insert the code of the new resources in main.go, when set
+	wiring string
+
+	flagSet *pflag.FlagSet
 }
 
 var (
@@ -39,18 +84,138 @@ var (
 )
 
 func (p *editSubcommand) UpdateContext(ctx *plugin.Context) {
-	ctx.Description = `This command will edit the project configuration. You can have single or multi group project.`
+	ctx.Description = `This command will edit the project configuration. You can have single or multi group project.
+
+The project and the domain of its API groups can be renamed: the PROJECT file and the files of
+the project referring to them are rewritten, such as the kustomize namePrefix and namespace,
+the names of the RBAC objects, the image of the Makefile, the API groups of the Go types,
+markers and manifests, and the paths of the webhooks. The diff of every file is printed, and
+--dry-run only prints it. The Go module of the project is not renamed.
+
+The namespace the manager is installed in, the name of the project suffixed with -system by
+default, can be changed: it is rewritten in the kustomize configuration of config/default, in
+the manifests whose namespace is not set by kustomize and in the defaults package, and recorded
+in the PROJECT file.
+
+The versions of each group of a multigroup project can be added to the scheme by a registration
+package of the group, apis/<group>/register.go, so that main.go imports one package per group
+instead of one per version. The registration packages of the existing groups are scaffolded
+and added to main.go, where the versions added one by one can then be removed.
+
+The license header of hack/boilerplate.go.txt can be applied to the Go files of the project: the
+files without one are prefixed with it, and the ones with a different header, e.g. of another
+year or owner, get it. The year and the owner of the copyright line of the boilerplate can be
+replaced first.
+
+The oldest Kubernetes version supported by the project sets the versions of the CRDs, webhook
+configurations and AdmissionReviews of the APIs and webhooks scaffolded afterwards: the
+scaffolded ones are not modified, and must be compatible with it.
+
+The metrics endpoint of the manager can be exposed outside of the cluster with an Ingress or a
+Gateway API HTTPRoute, by the metrics-exposure kustomize component. Exposing it again rewrites
+the files of the component, e.g. to change its host name.
+
+The manager can run as an agent reconciling the objects of its own namespace, without leader
+election: the --mode flag of the agent package is added to main.go, and config/agent deploys
+the agent in a namespace with a Deployment, or on every node with a DaemonSet, granting it the
+RBAC rules of the manager in this namespace only.
+
+The webhooks of the manager run by make run can be served to a development cluster by the
+hack/webhook-dev tool, which generates a local serving certificate and applies a Service
+forwarding the requests of the API server to the machine, and the webhook configurations
+calling it.
+
+The modules required by the project can be replaced, e.g. by the forks of controller-runtime or
+of the Kubernetes libraries maintained by a company: the replace directives are recorded in the
+PROJECT file and applied to go.mod. A directive replaces the one of the same module, and can be
+dropped with --drop-replace.
+
+The resource and controller prompts of create api can be answered by default, e.g. to script
+the scaffolding of APIs without repeating the --resource and --controller flags, which still
+override the default answers. The default answers are recorded in the PROJECT file.
+
+The code of the new resources can be inserted in main.go at the positions found in its syntax
+tree rather than at its scaffold markers (experimental), so that main.go can be reformatted or
+refactored, e.g. by moving the setup of the reconcilers to a function creating the manager.
+`
 
 	ctx.Examples = fmt.Sprintf(`# Enable the multigroup layout
-        %s edit --multigroup
+        %[1]s edit --multigroup
 
         # Disable the multigroup layout
-        %s edit --multigroup=false
-	`, ctx.CommandName, ctx.CommandName)
+        %[1]s edit --multigroup=false
+
+        # Add the versions of each group to the scheme through a registration package of the group
+        %[1]s edit --group-registration
+
+        # Preview the renaming of the project and of its domain
+        %[1]s edit --project-name fleet --domain example.org --dry-run
+
+        # Apply the license header of the boilerplate, of the current year, to the Go files
+        %[1]s edit --fix-headers --year $(date +%%Y)
+
+        # Install the manager in the fleet-operators namespace
+        %[1]s edit --namespace fleet-operators
+
+        # Support the clusters running Kubernetes 1.15 or later
+        %[1]s edit --min-k8s-version 1.15
+
+        # Expose the metrics endpoint on metrics.example.com with an HTTPRoute
+        %[1]s edit --expose-metrics httproute --metrics-hostname metrics.example.com
+
+        # Run the manager as an agent of a single namespace, deployed by config/agent
+        %[1]s edit --agent deployment
+
+        # Serve the webhooks of the manager run by make run to a kind cluster
+        %[1]s edit --webhook-dev
+
+        # Build the project with a fork of controller-runtime, then drop the replace directive
+        %[1]s edit --replace sigs.k8s.io/controller-runtime=example.com/controller-runtime@v0.7.0-1
+        %[1]s edit --drop-replace sigs.k8s.io/controller-runtime
+
+        # Scaffold both the resource and the controller of the APIs without prompting
+        %[1]s edit --default-resource yes --default-controller yes
+
+        # Skip the controller of the APIs by default, as a --skip-controller flag recorded in the PROJECT file would
+        %[1]s edit --default-resource yes --default-controller no
+
+        # Wire main.go at the positions found in its syntax tree rather than at its markers
+        %[1]s edit --wiring ast
+	`, ctx.CommandName)
 }
 
 func (p *editSubcommand) BindFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&p.multigroup, "multigroup", false, "enable or disable multigroup layout")
+	fs.BoolVar(&p.groupRegistration, "group-registration", false,
+		"add the versions of each group to the scheme through a registration package of the group, "+
+			"or stop scaffolding it if false (multigroup only)")
+	fs.StringVar(&p.projectName, "project-name", "", "rename the project")
+	fs.StringVar(&p.domain, "domain", "", "rename the domain of the API groups")
+	fs.StringVar(&p.namespace, "namespace", "", "change the namespace the manager is installed in")
+	fs.BoolVar(&p.dryRun, "dry-run", false,
+		"print the diff of the renaming and the files whose license header is fixed without modifying them")
+	fs.BoolVar(&p.fixHeaders, "fix-headers", false,
+		"apply the license header of hack/boilerplate.go.txt to the Go files missing it or with a different one")
+	fs.StringVar(&p.year, "year", "", "replace the year of the copyright of the license header (requires --fix-headers)")
+	fs.StringVar(&p.owner, "owner", "",
+		"replace the owner of the copyright of the license header (requires --fix-headers)")
+	fs.StringVar(&p.minKubernetesVersion, "min-k8s-version", "",
+		"set the oldest Kubernetes version supported by the project, e.g. 1.15, or unset it if empty")
+	bindMetricsExposureFlags(fs, &p.metricsExposure)
+	bindAgentFlag(fs, &p.agent)
+	bindWebhookDevFlag(fs, &p.webhookDev)
+	bindReplaceFlag(fs, &p.replace)
+	fs.StringArrayVar(&p.dropReplace, "drop-replace", nil,
+		"remove the replace directives of the module, old[@v], from the PROJECT file and go.mod, the ones of "+
+			"every version of old if no version is given, can be repeated")
+	fs.StringVar(&p.defaultResource, "default-resource", "",
+		"answer the resource prompt of create api by default: yes, no to skip the resource, or prompt to prompt "+
+			"for it again")
+	fs.StringVar(&p.defaultController, "default-controller", "",
+		"answer the controller prompt of create api by default: yes, no to skip the controller, or prompt to "+
+			"prompt for it again")
+	bindWiringFlag(fs, &p.wiring)
+	p.flagSet = fs
 }
 
 func (p *editSubcommand) InjectConfig(c *config.Config) {
@@ -62,13 +227,293 @@ func (p *editSubcommand) Run() error {
 }
 
 func (p *editSubcommand) Validate() error {
+	rename := p.projectName != "" || p.domain != "" || p.namespace != ""
+	setMinKubernetesVersion := p.flagSet.Changed("min-k8s-version")
+	exposeMetrics := p.metricsExposure.Kind != ""
+	setGroupRegistration := p.flagSet.Changed("group-registration")
+	setCreateAPIDefaults := p.defaultResource != "" || p.defaultController != ""
+	addAgent := p.agent != ""
+	replaceModules := len(p.replace) != 0 || len(p.dropReplace) != 0
+	changeWiring := p.wiring != ""
+
+	// A renaming, a fix of the license headers, a change of the minimum Kubernetes version, an exposure of the
+	// metrics, a change of the group registration or of the default answers of create api, or the addition of
+	// the agent or of hack/webhook-dev, or a change of the replace directives or of the wiring keeps the layout,
+	// unless --multigroup is provided too
+	if (rename || p.fixHeaders || setMinKubernetesVersion || exposeMetrics || setGroupRegistration ||
+		setCreateAPIDefaults || addAgent || p.webhookDev || replaceModules || changeWiring) &&
+		!p.flagSet.Changed("multigroup") {
+		p.multigroup = p.config.MultiGroup
+	}
+
+	if setGroupRegistration {
+		if p.groupRegistration && !p.multigroup {
+			return fmt.Errorf("--group-registration requires the multigroup layout")
+		}
+		// Disabling it keeps the scaffolded registration packages
+		if !p.groupRegistration {
+			p.config.GroupRegistration = false
+		}
+		// The registration packages are only scaffolded when enabling it
+		p.groupRegistration = p.groupRegistration && !p.config.GroupRegistration
+	} else if !p.multigroup {
+		// The group registration is only available in the multigroup layout
+		p.config.GroupRegistration = false
+	}
+
+	if p.projectName != "" {
+		if err := validation.IsDNS1123Label(p.projectName); err != nil {
+			return fmt.Errorf("project name (%s) is invalid: %v", p.projectName, err)
+		}
+	}
+	if p.domain != "" {
+		if err := validation.IsDNS1123Subdomain(p.domain); err != nil {
+			return fmt.Errorf("domain (%s) is invalid: %v", p.domain, err)
+		}
+	}
+	if p.namespace != "" {
+		if err := validation.IsDNS1123Label(p.namespace); err != nil {
+			return fmt.Errorf("namespace (%s) is invalid: %v", p.namespace, err)
+		}
+		// The names prefixed with the project name are renamed with the project
+		if p.projectName != "" && p.projectName != p.config.ProjectName &&
+			strings.HasPrefix(p.namespace, p.config.ProjectName+"-") {
+			return fmt.Errorf("namespace (%s) can not be prefixed by the name of the renamed project, %s",
+				p.namespace, p.config.ProjectName)
+		}
+	}
+
+	if (p.year != "" || p.owner != "") && !p.fixHeaders {
+		return fmt.Errorf("--year and --owner require --fix-headers")
+	}
+	if p.year != "" {
+		if _, err := strconv.Atoi(p.year); err != nil {
+			return fmt.Errorf("year (%s) is invalid, expected a number", p.year)
+		}
+	}
+
+	if p.dryRun {
+		if !rename && !p.fixHeaders {
+			return fmt.Errorf("--dry-run requires --project-name, --domain, --namespace or --fix-headers")
+		}
+		if p.multigroup != p.config.MultiGroup {
+			return fmt.Errorf("--dry-run can not preview a change of --multigroup")
+		}
+		if setMinKubernetesVersion {
+			return fmt.Errorf("--dry-run can not preview a change of --min-k8s-version")
+		}
+		if exposeMetrics {
+			return fmt.Errorf("--dry-run can not preview an exposure of the metrics")
+		}
+		if setGroupRegistration {
+			return fmt.Errorf("--dry-run can not preview a change of --group-registration")
+		}
+		if setCreateAPIDefaults {
+			return fmt.Errorf("--dry-run can not preview a change of --default-resource or --default-controller")
+		}
+		if addAgent {
+			return fmt.Errorf("--dry-run can not preview the addition of the agent")
+		}
+		if p.webhookDev {
+			return fmt.Errorf("--dry-run can not preview the addition of hack/webhook-dev")
+		}
+		if replaceModules {
+			return fmt.Errorf("--dry-run can not preview a change of --replace or --drop-replace")
+		}
+		if changeWiring {
+			return fmt.Errorf("--dry-run can not preview a change of --wiring")
+		}
+	}
+
+	if setMinKubernetesVersion {
+		if err := p.validateMinKubernetesVersion(); err != nil {
+			return err
+		}
+	}
+
+	if err := validateMetricsExposure(p.config, p.metricsExposure); err != nil {
+		return err
+	}
+
+	if setCreateAPIDefaults {
+		if err := p.setCreateAPIDefaults(); err != nil {
+			return err
+		}
+	}
+
+	if addAgent {
+		if err := p.validateAgent(); err != nil {
+			return err
+		}
+	}
+
+	if p.webhookDev && (p.config.ManifestsOnly || p.config.Pattern == scaffolds.PatternAggregatedAPIServer) {
+		return fmt.Errorf("--webhook-dev can only be used in the projects whose manager serves webhooks, not in the "+
+			"projects initialized with --manifests-only or --pattern=%s", scaffolds.PatternAggregatedAPIServer)
+	}
+
+	if replaceModules {
+		if err := p.validateModuleReplaces(); err != nil {
+			return err
+		}
+	}
+
+	if changeWiring {
+		if err := setWiring(p.config, p.wiring); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateModuleReplaces checks that the replace directives are valid and that the dropped ones are recorded in
+// the PROJECT file
+func (p *editSubcommand) validateModuleReplaces() error {
+	if p.config.ManifestsOnly {
+		return fmt.Errorf("--replace and --drop-replace can not be used in the projects initialized with " +
+			"--manifests-only, which have no go.mod")
+	}
+
+	replace, err := parseModuleReplaces(p.replace)
+	if err != nil {
+		return err
+	}
+	p.replace = replace
+
+	recorded := make([]scaffolds.ModuleReplace, 0, len(p.config.Replace))
+	for _, value := range p.config.Replace {
+		r, err := scaffolds.ParseModuleReplace(value)
+		if err != nil {
+			return fmt.Errorf("error reading the replace directives of the PROJECT file: %v", err)
+		}
+		recorded = append(recorded, r)
+	}
+	for _, module := range p.dropReplace {
+		found := false
+		for _, r := range recorded {
+			found = found || r.DroppedBy(module)
+		}
+		if !found {
+			return fmt.Errorf("no replace directive of %s is recorded in the PROJECT file", module)
+		}
+	}
+	return nil
+}
+
+// validateAgent checks that the agent can be added to the project, whose manager is the operator of main.go
+func (p *editSubcommand) validateAgent() error {
+	if err := validateAgent(p.agent); err != nil {
+		return err
+	}
+	if p.config.ManifestsOnly {
+		return fmt.Errorf("--agent can not be used in the projects initialized with --manifests-only")
+	}
+	if p.config.Pattern == scaffolds.PatternAggregatedAPIServer {
+		return fmt.Errorf("--agent can not be used in the projects initialized with --pattern=%s",
+			scaffolds.PatternAggregatedAPIServer)
+	}
+	if p.config.Agent != "" {
+		return fmt.Errorf("the agent is already scaffolded in config/agent with a %s, remove it and the agent "+
+			"field of the PROJECT file to scaffold it again", p.config.Agent)
+	}
+	return nil
+}
+
+// setCreateAPIDefaults sets the default answers of the prompts of create api provided by the flags in the config
+func (p *editSubcommand) setCreateAPIDefaults() error {
+	defaults := config.CreateAPIDefaults{}
+	if p.config.CreateAPI != nil {
+		defaults = *p.config.CreateAPI
+	}
+	if p.defaultResource != "" {
+		answer, err := parseDefaultAnswer("--default-resource", p.defaultResource)
+		if err != nil {
+			return err
+		}
+		defaults.Resource = answer
+	}
+	if p.defaultController != "" {
+		answer, err := parseDefaultAnswer("--default-controller", p.defaultController)
+		if err != nil {
+			return err
+		}
+		// The aggregated API servers only serve the resources
+		if answer != nil && *answer && p.config.Pattern == scaffolds.PatternAggregatedAPIServer {
+			return fmt.Errorf("projects initialized with --pattern=%s do not scaffold controllers",
+				scaffolds.PatternAggregatedAPIServer)
+		}
+		defaults.Controller = answer
+	}
+
+	if defaults.Resource == nil && defaults.Controller == nil {
+		p.config.CreateAPI = nil
+	} else {
+		p.config.CreateAPI = &defaults
+	}
+	return nil
+}
+
+// parseDefaultAnswer parses the value of the flag setting the default answer of a prompt, nil to prompt for it
+func parseDefaultAnswer(flag, value string) (*bool, error) {
+	var answer bool
+	switch strings.ToLower(value) {
+	case "yes":
+		answer = true
+	case "no":
+		answer = false
+	case "prompt":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("invalid %s %q, may be one of yes, no, prompt", flag, value)
+	}
+	return &answer, nil
+}
+
+// validateMinKubernetesVersion checks that the scaffolded CRDs and webhook configurations are served by the
+// minimum Kubernetes version, and sets it in the config as MAJOR.MINOR
+func (p *editSubcommand) validateMinKubernetesVersion() error {
+	if p.minKubernetesVersion == "" {
+		p.config.MinKubernetesVersion = ""
+		return nil
+	}
+	major, minor, err := config.ParseKubernetesVersion(p.minKubernetesVersion)
+	if err != nil {
+		return err
+	}
+
+	cfg := *p.config
+	cfg.MinKubernetesVersion = fmt.Sprintf("%d.%d", major, minor)
+	if scaffolds.SupportsPreV1Kubernetes(&cfg) {
+		// Only one CRD and one webhook version can be used by the resources, v1beta1 is not compatible with v1
+		if !cfg.IsCRDVersionCompatible("v1beta1") || !cfg.IsWebhookVersionCompatible("v1beta1") {
+			return fmt.Errorf("the project scaffolded v1 CRDs or webhook configurations, "+
+				"which require Kubernetes 1.16 or later, not %s", cfg.MinKubernetesVersion)
+		}
+	}
+	p.config.MinKubernetesVersion = cfg.MinKubernetesVersion
 	return nil
 }
 
 func (p *editSubcommand) GetScaffolder() (cmdutil.Scaffolder, error) {
-	return scaffolds.NewEditScaffolder(p.config, p.multigroup), nil
+	return scaffolds.NewEditScaffolder(p.config, p.multigroup, p.groupRegistration, scaffolds.RenameOptions{
+		ProjectName: p.projectName,
+		Domain:      p.domain,
+		Namespace:   p.namespace,
+		DryRun:      p.dryRun,
+	}, scaffolds.HeaderOptions{
+		Fix:   p.fixHeaders,
+		Year:  p.year,
+		Owner: p.owner,
+	}, p.metricsExposure, p.agent, p.webhookDev, scaffolds.ReplaceOptions{
+		Replace: p.replace,
+		Drop:    p.dropReplace,
+	}), nil
 }
 
 func (p *editSubcommand) PostScaffold() error {
+	if len(p.replace) != 0 || len(p.dropReplace) != 0 {
+		fmt.Println("Next: fetch the modules of the replace directives and update go.sum with:\n$ go mod tidy")
+	}
 	return nil
 }
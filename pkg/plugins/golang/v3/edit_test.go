@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds"
+)
+
+func TestParseDefaultAnswer(t *testing.T) {
+	yes, no := true, false
+	for value, expected := range map[string]*bool{
+		"yes":    &yes,
+		"YES":    &yes,
+		"no":     &no,
+		"No":     &no,
+		"prompt": nil,
+	} {
+		answer, err := parseDefaultAnswer("--default-resource", value)
+		if err != nil {
+			t.Errorf("%s: %v", value, err)
+		} else if !reflect.DeepEqual(answer, expected) {
+			t.Errorf("%s: expected the answer %v, got %v", value, expected, answer)
+		}
+	}
+
+	for _, value := range []string{"y", "n", "true", "skip"} {
+		if _, err := parseDefaultAnswer("--default-resource", value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestSetCreateAPIDefaults(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name                string
+		current             *config.CreateAPIDefaults
+		resource            string
+		controller          string
+		expected            *config.CreateAPIDefaults
+		expectedProjectFile string
+	}{
+		{
+			name:                "both answered",
+			resource:            "yes",
+			controller:          "yes",
+			expected:            &config.CreateAPIDefaults{Resource: &yes, Controller: &yes},
+			expectedProjectFile: "createAPI:\n  controller: true\n  resource: true\n",
+		},
+		{
+			name:                "controller skipped",
+			resource:            "yes",
+			controller:          "no",
+			expected:            &config.CreateAPIDefaults{Resource: &yes, Controller: &no},
+			expectedProjectFile: "createAPI:\n  controller: false\n  resource: true\n",
+		},
+		{
+			name:                "controller kept",
+			current:             &config.CreateAPIDefaults{Resource: &yes, Controller: &no},
+			resource:            "no",
+			expected:            &config.CreateAPIDefaults{Resource: &no, Controller: &no},
+			expectedProjectFile: "createAPI:\n  controller: false\n  resource: false\n",
+		},
+		{
+			name:                "resource prompted again",
+			current:             &config.CreateAPIDefaults{Resource: &yes, Controller: &no},
+			resource:            "prompt",
+			expected:            &config.CreateAPIDefaults{Controller: &no},
+			expectedProjectFile: "createAPI:\n  controller: false\n",
+		},
+		{
+			name:       "both prompted again",
+			current:    &config.CreateAPIDefaults{Resource: &yes, Controller: &no},
+			resource:   "prompt",
+			controller: "prompt",
+		},
+	}
+
+	for _, test := range tests {
+		p := &editSubcommand{
+			config:            &config.Config{Version: config.Version3Alpha, CreateAPI: test.current},
+			defaultResource:   test.resource,
+			defaultController: test.controller,
+		}
+		if err := p.setCreateAPIDefaults(); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(p.config.CreateAPI, test.expected) {
+			t.Errorf("%s: expected the defaults %+v, got %+v", test.name, test.expected, p.config.CreateAPI)
+		}
+
+		// The defaults are read back from the PROJECT file by the next create api
+		content, err := p.config.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.expectedProjectFile != "" && !strings.Contains(string(content), test.expectedProjectFile) {
+			t.Errorf("%s: expected the PROJECT file to contain\n%s\ngot\n%s", test.name, test.expectedProjectFile,
+				content)
+		}
+		read := &config.Config{}
+		if err := read.Unmarshal(content); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read.CreateAPI, test.expected) {
+			t.Errorf("%s: expected the defaults %+v to be read back, got %+v", test.name, test.expected,
+				read.CreateAPI)
+		}
+	}
+
+	p := &editSubcommand{
+		config:            &config.Config{Version: config.Version3Alpha, Pattern: scaffolds.PatternAggregatedAPIServer},
+		defaultController: "yes",
+	}
+	if err := p.setCreateAPIDefaults(); err == nil {
+		t.Errorf("expected the controllers of the aggregated API servers to be rejected")
+	}
+	for _, invalid := range []string{"--default-resource", "--default-controller"} {
+		p := &editSubcommand{config: &config.Config{Version: config.Version3Alpha}}
+		if invalid == "--default-resource" {
+			p.defaultResource = "always"
+		} else {
+			p.defaultController = "always"
+		}
+		if err := p.setCreateAPIDefaults(); err == nil {
+			t.Errorf("expected the invalid %s to be rejected", invalid)
+		}
+	}
+}
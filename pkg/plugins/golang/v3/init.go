@@ -19,6 +19,7 @@ package v3
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -30,6 +31,7 @@ import (
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/cmdutil"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/machinery"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/util"
 )
 
@@ -45,6 +47,53 @@ type initSubcommand struct {
 	// flags
 	fetchDeps          bool
 	skipGoVersionCheck bool
+
+	// toolMirror is the default base URL used by the Makefile to download tooling
+	toolMirror string
+
+	// supply chain options
+	sbom         bool
+	imageSigning string
+
+	// image options
+	imageRepo       string
+	imagePullSecret string
+
+	// go module options
+	goProxy   string
+	goPrivate string
+	goNoSumDB string
+	replace   []string
+
+	// podSecurity is the Pod Security Standards profile the manager complies with
+	podSecurity string
+
+	// certProvider is the provider of the serving certificates of the webhooks
+	certProvider string
+
+	// metricsExposure exposes the metrics endpoint outside of the cluster
+	metricsExposure scaffolds.MetricsExposure
+
+	// overlays scaffolds the kustomize overlays of the dev, staging and prod environments
+	overlays bool
+
+	// webhookDev scaffolds hack/webhook-dev, serving the webhooks of the manager run by the developer to a cluster
+	webhookDev bool
+
+	// taskRunner is the task runner defining the targets of the project
+	taskRunner string
+
+	// ipFamily is the IP family of the clusters the manager is deployed to
+	ipFamily string
+
+	// wiring is how create api and create webhook insert the code of the new resources in main.go
+	wiring string
+
+	// dependencyUpdates is the bot updating the dependencies of the project, if any
+	dependencyUpdates string
+
+	// scaffoldLock records the scaffolded files, so that the later scaffolds can merge their changes
+	scaffoldLock bool
 }
 
 var (
@@ -58,15 +107,50 @@ func (p *initSubcommand) UpdateContext(ctx *plugin.Context) {
 Writes the following files:
 - a boilerplate license file
 - a PROJECT file with the domain and repo
-- a Makefile to build the project
-- a go.mod with project dependencies
+- a Makefile to build the project, or a Taskfile.yaml or a Justfile with the same targets
+  if --task-runner is set to task or just
+- a go.mod with project dependencies, and the replace directives of --replace
 - a Kustomization.yaml for customizating manifests
 - a Patch file for customizing image for manager manifests
 - a Patch file for enabling prometheus metrics
 - a main.go to run
+
+With --manifests-only, only the PROJECT file, the kustomize manifests and a Makefile to
+deploy them are written.
+- a .go-env with the Go module configuration, if --go-proxy, --go-private or --go-nosumdb are set
+- a renovate.json or a .github/dependabot.yml updating the dependencies, if --dependency-updates is set
+
+With --pattern=aggregated-apiserver, main.go runs an aggregated API server, defined in the
+apiserver package, which serves the APIs of the project instead of CRDs and keeps their objects
+with the storage of the internal/storage package instead of etcd.
 `
 	ctx.Examples = fmt.Sprintf(`  # Scaffold a project using the apache2 license with "The Kubernetes authors" as owners
-  %s init --project-version=2 --domain example.org --license apache2 --owner "The Kubernetes authors"
+  %[1]s init --project-version=2 --domain example.org --license apache2 --owner "The Kubernetes authors"
+
+  # Scaffold an aggregated API server
+  %[1]s init --domain example.org --pattern aggregated-apiserver
+
+  # Scaffold the kustomize overlays of the dev, staging and prod environments, deployed with make deploy-dev,
+  # make deploy-staging and make deploy-prod
+  %[1]s init --domain example.org --overlays
+
+  # Scaffold a manager that can also run as an agent on every node, reconciling the objects of its namespace
+  %[1]s init --domain example.org --agent daemonset
+
+  # Build the project with the fork of controller-runtime of the company, recorded in the PROJECT file
+  %[1]s init --domain example.org --replace sigs.k8s.io/controller-runtime=example.com/controller-runtime@v0.7.0-1
+
+  # Scaffold a Taskfile.yaml run by go-task instead of a Makefile, e.g. for Windows users
+  %[1]s init --domain example.org --task-runner task
+
+  # Scaffold a Renovate configuration updating the Kubernetes libraries with controller-runtime
+  %[1]s init --domain example.org --dependency-updates renovate
+
+  # Scaffold a manager deployed to IPv6-only clusters
+  %[1]s init --domain example.org --ip-family ipv6
+
+  # Locate where create api inserts the code of the new resources in the syntax tree of main.go
+  %[1]s init --domain example.org --wiring ast
 `,
 		ctx.CommandName)
 
@@ -79,6 +163,59 @@ func (p *initSubcommand) BindFlags(fs *pflag.FlagSet) {
 
 	// dependency args
 	fs.BoolVar(&p.fetchDeps, "fetch-deps", true, "ensure dependencies are downloaded")
+	fs.StringVar(&p.taskRunner, "task-runner", scaffolds.TaskRunnerMake,
+		"task runner defining the targets of the project, may be one of 'make', 'task', 'just', which scaffold "+
+			"a Makefile, a Taskfile.yaml run by go-task or a Justfile run by just with the same targets")
+	fs.StringVar(&p.toolMirror, "tool-mirror", "",
+		"base URL of a mirror used by the Makefile to download tooling (e.g., https://mirror.example.com), "+
+			"defaults to the upstream download locations")
+
+	// go module args
+	fs.StringVar(&p.goProxy, "go-proxy", "",
+		"comma-separated list of module proxies (GOPROXY) used by the go commands of the project, "+
+			"e.g. https://proxy.example.com,direct")
+	fs.StringVar(&p.goPrivate, "go-private", "",
+		"comma-separated list of glob patterns of private modules (GOPRIVATE), "+
+			"which are neither downloaded from the proxy nor verified against the checksum database")
+	fs.StringVar(&p.goNoSumDB, "go-nosumdb", "",
+		"comma-separated list of glob patterns of modules not verified against the checksum database (GONOSUMDB)")
+	bindReplaceFlag(fs, &p.replace)
+
+	// image args
+	fs.StringVar(&p.imageRepo, "image-repo", "",
+		"default repository of the manager image (e.g., registry.example.com/team/project), "+
+			"defaults to 'controller'")
+	fs.StringVar(&p.imagePullSecret, "image-pull-secret", "",
+		"name of the secret used to pull the manager image from a private registry")
+
+	// security args
+	fs.StringVar(&p.podSecurity, "pod-security", scaffolds.PodSecurityRestricted,
+		"Pod Security Standards profile the manager Deployment complies with, may be one of 'restricted', "+
+			"'baseline', the latter allows the manager to run as root")
+	fs.StringVar(&p.certProvider, "cert-provider", scaffolds.CertProviderCertManager,
+		"provider of the serving certificates of the webhooks, may be one of 'cert-manager', 'vault', "+
+			"the latter retrieves them from Vault with the Vault agent injector")
+	bindMetricsExposureFlags(fs, &p.metricsExposure)
+
+	// deployment args
+	fs.BoolVar(&p.overlays, "overlays", false,
+		"if set, scaffold the kustomize overlays of the dev, staging and prod environments in config/overlays, "+
+			"setting the image tag, the number of replicas and the log level of the manager, and the Makefile "+
+			"targets deploying them, e.g. deploy-staging")
+	bindWebhookDevFlag(fs, &p.webhookDev)
+
+	fs.BoolVar(&p.scaffoldLock, "scaffold-lock", false,
+		"if set, record the content of every scaffolded file in "+machinery.LockDir+", so that the files "+
+			"scaffolded again can be merged with their changes with --on-conflict=merge")
+
+	// supply chain args
+	fs.BoolVar(&p.sbom, "sbom", false,
+		"if set, scaffold a Makefile target to generate an SPDX SBOM of the manager image with syft")
+	fs.StringVar(&p.imageSigning, "image-signing", "",
+		"if set, scaffold a Makefile target to sign the manager image with cosign, may be one of 'keyless', 'key'")
+	fs.StringVar(&p.dependencyUpdates, "dependency-updates", "",
+		"if set, scaffold the configuration of a bot updating the Go modules and the base images of the project, "+
+			"which bumps the Kubernetes libraries with controller-runtime, may be one of 'renovate', 'dependabot'")
 
 	// boilerplate args
 	fs.StringVar(&p.license, "license", "apache2",
@@ -86,10 +223,41 @@ func (p *initSubcommand) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&p.owner, "owner", "", "owner to add to the copyright")
 	fs.BoolVar(&p.config.ComponentConfig, "component-config", false,
 		"create a versioned ComponentConfig file, may be 'true' or 'false'")
+	fs.BoolVar(&p.config.ManifestsOnly, "manifests-only", false,
+		"scaffold only the kustomize manifests and the Makefile to deploy them, without any Go code, "+
+			"for projects that hold the manifests of an operator developed in another repository")
+	fs.StringVar(&p.config.Pattern, "pattern", "",
+		"scaffold a project following an alternative pattern, may be 'aggregated-apiserver', which serves "+
+			"the APIs from an aggregated API server for the types that can not be CRDs")
+	fs.BoolVar(&p.config.FeatureGates, "feature-gates", false,
+		"create a featuregate package to ship features behind the --feature-gates flag of the manager, "+
+			"may be 'true' or 'false'")
+	fs.BoolVar(&p.config.MarkerDocs, "marker-docs", false,
+		"precede the markers of the scaffolded types and controllers with a comment linking to their "+
+			"documentation, may be 'true' or 'false'")
+	fs.BoolVar(&p.config.MultiCluster, "multi-cluster", false,
+		"[experimental] create a clusters package connecting the manager to the remote clusters listed in "+
+			"its --clusters-config file, and scaffold controllers reconciling the objects of every cluster, "+
+			"may be 'true' or 'false'")
+	fs.BoolVar(&p.config.Sharding, "sharding", false,
+		"[experimental] create a sharding package spreading the objects of the resources across several "+
+			"managers selected by their --shard-id and --shard-count flags, for very large fleets, "+
+			"may be 'true' or 'false'")
+	bindAgentFlag(fs, &p.config.Agent)
+	bindWiringFlag(fs, &p.wiring)
+	fs.StringVar(&p.ipFamily, "ip-family", config.IPFamilyDualStack,
+		"IP family of the clusters the manager is deployed to, may be one of 'dual-stack', 'ipv6', the latter "+
+			"binds the endpoints only reached from the manager Pod, such as the metrics served behind "+
+			"kube-rbac-proxy, to the IPv6 loopback address for the IPv6-only clusters")
+	fs.StringVar(&p.config.MinKubernetesVersion, "min-k8s-version", "",
+		"oldest Kubernetes version supported by the project, e.g. 1.25, which selects the API versions and the "+
+			"fields of the scaffolded manifests, such as the CRD, webhook, PodDisruptionBudget and cert-manager "+
+			"versions, and the Kubernetes version of the envtest binaries. Defaults to the current versions")
 
 	// project args
 	fs.StringVar(&p.config.Repo, "repo", "", "name to use for go module (e.g., github.com/user/repo), "+
-		"defaults to the go package of the current working directory.")
+		"defaults to the go package of the current working directory. The URLs of repositories are accepted "+
+		"(e.g., https://github.com/user/repo.git), and must match the module of an existing go.mod.")
 	fs.StringVar(&p.config.Domain, "domain", "my.domain", "domain for groups")
 	fs.StringVar(&p.config.ProjectName, "project-name", "", "name of this project")
 }
@@ -104,8 +272,43 @@ func (p *initSubcommand) Run() error {
 }
 
 func (p *initSubcommand) Validate() error {
+	// Check that the options that only apply to the Go code are not used without it.
+	if p.config.ManifestsOnly {
+		if p.config.ComponentConfig || p.config.FeatureGates || p.config.MarkerDocs || p.config.MultiCluster ||
+			p.config.Sharding || p.config.Agent != "" || p.toolMirror != "" || p.sbom || p.imageSigning != "" ||
+			p.overlays || p.webhookDev || len(p.replace) != 0 {
+			return errors.New("--component-config, --feature-gates, --marker-docs, --multi-cluster, --sharding, " +
+				"--agent, --tool-mirror, --sbom, --image-signing, --overlays, --webhook-dev and --replace can not " +
+				"be used with --manifests-only")
+		}
+	}
+
+	// Check that the pattern is supported and that the options of the operators are not used with it.
+	switch p.config.Pattern {
+	case "":
+	case scaffolds.PatternAggregatedAPIServer:
+		if p.config.ManifestsOnly || p.config.ComponentConfig || p.config.FeatureGates || p.config.MultiCluster ||
+			p.config.Sharding || p.config.Agent != "" || p.certProvider != scaffolds.CertProviderCertManager ||
+			p.overlays || p.webhookDev {
+			return fmt.Errorf("--manifests-only, --component-config, --feature-gates, --multi-cluster, --sharding, "+
+				"--agent, --cert-provider, --overlays and --webhook-dev can not be used with --pattern=%s",
+				scaffolds.PatternAggregatedAPIServer)
+		}
+	default:
+		return fmt.Errorf("pattern (%s) is invalid: may be %q", p.config.Pattern, scaffolds.PatternAggregatedAPIServer)
+	}
+
+	// Check that the minimum Kubernetes version, if provided, is valid and store it as MAJOR.MINOR.
+	if p.config.MinKubernetesVersion != "" {
+		major, minor, err := config.ParseKubernetesVersion(p.config.MinKubernetesVersion)
+		if err != nil {
+			return err
+		}
+		p.config.MinKubernetesVersion = fmt.Sprintf("%d.%d", major, minor)
+	}
+
 	// Requires go1.11+
-	if !p.skipGoVersionCheck {
+	if !p.config.ManifestsOnly && !p.skipGoVersionCheck {
 		if err := util.ValidateGoVersion(); err != nil {
 			return err
 		}
@@ -128,6 +331,150 @@ func (p *initSubcommand) Validate() error {
 		return fmt.Errorf("project name (%s) is invalid: %v", p.config.ProjectName, err)
 	}
 
+	// Check that the tool mirror, if provided, is an absolute http(s) URL.
+	if p.toolMirror != "" {
+		u, err := url.Parse(p.toolMirror)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("tool mirror (%s) is invalid: must be an absolute http or https URL", p.toolMirror)
+		}
+		p.toolMirror = strings.TrimSuffix(p.toolMirror, "/")
+	}
+
+	// Check that the module proxies, if provided, are absolute http(s) URLs or the keywords understood by go.
+	if p.goProxy != "" {
+		for _, proxy := range strings.FieldsFunc(p.goProxy, func(r rune) bool { return r == ',' || r == '|' }) {
+			if proxy == "direct" || proxy == "off" {
+				continue
+			}
+			if u, err := url.Parse(proxy); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("go proxy (%s) is invalid: must be an absolute http or https URL, 'direct' or 'off'",
+					proxy)
+			}
+		}
+	}
+
+	// Check that the replace directives, if provided, are valid and record them.
+	if len(p.replace) != 0 {
+		replace, err := parseModuleReplaces(p.replace)
+		if err != nil {
+			return err
+		}
+		p.config.Replace = replace
+	}
+
+	// Check that the module patterns do not contain spaces, which the .go-env file can not hold.
+	for flag, patterns := range map[string]string{"go-private": p.goPrivate, "go-nosumdb": p.goNoSumDB} {
+		if strings.ContainsAny(patterns, " \t\n") {
+			return fmt.Errorf("--%s (%s) is invalid: must be a comma-separated list of glob patterns", flag, patterns)
+		}
+	}
+
+	// Check that the image repository does not include a tag or a digest.
+	if p.imageRepo != "" {
+		if strings.Contains(p.imageRepo, "@") || strings.Contains(p.imageRepo[strings.LastIndex(p.imageRepo, "/")+1:], ":") {
+			return fmt.Errorf("image repository (%s) is invalid: must not include a tag or a digest", p.imageRepo)
+		}
+	}
+
+	// Check if the image pull secret name is a valid k8s object name (DNS 1123 subdomain).
+	if p.imagePullSecret != "" {
+		if err := validation.IsDNS1123Subdomain(p.imagePullSecret); err != nil {
+			return fmt.Errorf("image pull secret name (%s) is invalid: %v", p.imagePullSecret, err)
+		}
+	}
+
+	// Check that the image signing mode is supported.
+	switch p.imageSigning {
+	case "", scaffolds.ImageSigningKeyless, scaffolds.ImageSigningKey:
+	default:
+		return fmt.Errorf("image signing mode (%s) is invalid: may be one of %q, %q",
+			p.imageSigning, scaffolds.ImageSigningKeyless, scaffolds.ImageSigningKey)
+	}
+
+	// Check that the dependency update bot is supported and that there are Go modules to update.
+	switch p.dependencyUpdates {
+	case "":
+	case scaffolds.DependencyUpdatesRenovate, scaffolds.DependencyUpdatesDependabot:
+		if p.config.ManifestsOnly {
+			return errors.New("--dependency-updates can not be used with --manifests-only")
+		}
+	default:
+		return fmt.Errorf("dependency update bot (%s) is invalid: may be one of %q, %q", p.dependencyUpdates,
+			scaffolds.DependencyUpdatesRenovate, scaffolds.DependencyUpdatesDependabot)
+	}
+
+	// Check that the Pod Security Standards profile is supported.
+	switch p.podSecurity {
+	case scaffolds.PodSecurityRestricted, scaffolds.PodSecurityBaseline:
+	default:
+		return fmt.Errorf("pod security profile (%s) is invalid: may be one of %q, %q",
+			p.podSecurity, scaffolds.PodSecurityRestricted, scaffolds.PodSecurityBaseline)
+	}
+
+	// Check that the certificate provider is supported, only Vault is recorded in the PROJECT file.
+	switch p.certProvider {
+	case scaffolds.CertProviderCertManager:
+	case scaffolds.CertProviderVault:
+		if p.config.ManifestsOnly {
+			return errors.New("--cert-provider can not be used with --manifests-only")
+		}
+		p.config.CertProvider = scaffolds.CertProviderVault
+	default:
+		return fmt.Errorf("certificate provider (%s) is invalid: may be one of %q, %q",
+			p.certProvider, scaffolds.CertProviderCertManager, scaffolds.CertProviderVault)
+	}
+
+	// Check that the IP family is supported, only ipv6 is recorded in the PROJECT file.
+	switch p.ipFamily {
+	case config.IPFamilyDualStack:
+	case config.IPFamilyIPv6:
+		p.config.IPFamily = config.IPFamilyIPv6
+	default:
+		return fmt.Errorf("IP family (%s) is invalid: may be one of %q, %q",
+			p.ipFamily, config.IPFamilyDualStack, config.IPFamilyIPv6)
+	}
+
+	if p.wiring != "" {
+		if err := setWiring(p.config, p.wiring); err != nil {
+			return err
+		}
+	}
+
+	// Check that the task runner is supported, only task and just are recorded in the PROJECT file.
+	switch p.taskRunner {
+	case scaffolds.TaskRunnerMake:
+	case scaffolds.TaskRunnerTask, scaffolds.TaskRunnerJust:
+		if p.config.ManifestsOnly {
+			return errors.New("--task-runner can not be used with --manifests-only")
+		}
+		p.config.TaskRunner = p.taskRunner
+	default:
+		return fmt.Errorf("task runner (%s) is invalid: may be one of %q, %q, %q", p.taskRunner,
+			scaffolds.TaskRunnerMake, scaffolds.TaskRunnerTask, scaffolds.TaskRunnerJust)
+	}
+
+	// Check that the workload of the agent, if any, is supported.
+	if err := validateAgent(p.config.Agent); err != nil {
+		return err
+	}
+
+	// Check that the metrics endpoint, if exposed, is exposed on a valid host name.
+	if err := validateMetricsExposure(p.config, p.metricsExposure); err != nil {
+		return err
+	}
+
+	if !p.config.ManifestsOnly {
+		if err := p.validateRepo(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateRepo normalizes and validates the go module path, guessed from the current directory if the repo flag
+// is not set, and checks that it matches the module of the go.mod file that the scaffolded one replaces.
+func (p *initSubcommand) validateRepo() error {
 	// Try to guess repository if flag is not set.
 	if p.config.Repo == "" {
 		repoPath, err := util.FindCurrentRepo()
@@ -135,16 +482,62 @@ func (p *initSubcommand) Validate() error {
 			return fmt.Errorf("error finding current repository: %v", err)
 		}
 		p.config.Repo = repoPath
+	} else {
+		repo := util.NormalizeModulePath(p.config.Repo)
+		if repo != p.config.Repo {
+			fmt.Printf("Using the module path %q for --repo %q\n", repo, p.config.Repo)
+			p.config.Repo = repo
+		}
+		if existing, err := util.ReadGoModulePath(); err == nil && existing != repo {
+			return fmt.Errorf("repo (%s) does not match the module path of go.mod (%s): "+
+				"rerun without --repo or with --repo %s to adopt it, or remove go.mod", repo, existing, existing)
+		}
 	}
 
+	if err := util.ValidateModulePath(p.config.Repo); err != nil {
+		return fmt.Errorf("repo (%s) is invalid: %v", p.config.Repo, err)
+	}
+	if !strings.Contains(strings.SplitN(p.config.Repo, "/", 2)[0], ".") {
+		fmt.Printf("The module path %q does not start with a domain: the project builds, but can not be "+
+			"fetched with go get, use e.g. --repo github.com/<user>/%s to publish it\n",
+			p.config.Repo, p.config.Repo)
+	}
 	return nil
 }
 
 func (p *initSubcommand) GetScaffolder() (cmdutil.Scaffolder, error) {
-	return scaffolds.NewInitScaffolder(p.config, p.license, p.owner), nil
+	// The files are recorded by the scaffolds run once the scaffold lock exists
+	if p.scaffoldLock {
+		if err := os.MkdirAll(machinery.LockDir, 0755); err != nil {
+			return nil, fmt.Errorf("error creating the scaffold lock: %v", err)
+		}
+	}
+	return scaffolds.NewInitScaffolder(p.config, scaffolds.InitOptions{
+		License:           p.license,
+		Owner:             p.owner,
+		ToolMirror:        p.toolMirror,
+		SBOM:              p.sbom,
+		ImageSigning:      p.imageSigning,
+		ImageRepo:         p.imageRepo,
+		ImagePullSecret:   p.imagePullSecret,
+		GoProxy:           p.goProxy,
+		GoPrivate:         p.goPrivate,
+		GoNoSumDB:         p.goNoSumDB,
+		PodSecurity:       p.podSecurity,
+		MetricsExposure:   p.metricsExposure,
+		Overlays:          p.overlays,
+		WebhookDev:        p.webhookDev,
+		DependencyUpdates: p.dependencyUpdates,
+	}), nil
 }
 
 func (p *initSubcommand) PostScaffold() error {
+	if p.config.ManifestsOnly {
+		fmt.Println("Next: copy the CRDs and the RBAC rules of the operator to config/crd and config/rbac, " +
+			"then render the manifests with:\n$ make build")
+		return nil
+	}
+
 	if !p.fetchDeps {
 		fmt.Println("Skipping fetching dependencies.")
 		return nil
@@ -158,13 +551,20 @@ func (p *initSubcommand) PostScaffold() error {
 		return err
 	}
 
+	if p.config.Pattern == scaffolds.PatternAggregatedAPIServer {
+		err = util.RunCmd("Get apiserver", "go", "get", "k8s.io/apiserver@"+scaffolds.APIServerVersion)
+		if err != nil {
+			return err
+		}
+	}
+
 	err = util.RunCmd("Update go.mod", "go", "mod", "tidy")
 	if err != nil {
 		return err
 	}
 
 	// TODO: make this conditional with a '--make' flag, like in 'create api'.
-	err = util.RunCmd("Running make", "make")
+	err = runTargets(p.config)
 	if err != nil {
 		return err
 	}
@@ -195,3 +595,135 @@ func checkDir() error {
 	}
 	return nil
 }
+
+// runTargets runs the targets, or the default one if none, with the task runner of the project
+func runTargets(c *config.Config, targets ...string) error {
+	runner := scaffolds.TaskRunnerFor(c)
+	return util.RunCmd("Running "+strings.Join(append([]string{runner}, targets...), " "), runner, targets...)
+}
+
+// bindMetricsExposureFlags binds the flags exposing the metrics endpoint outside of the cluster, shared by
+// init and edit
+func bindMetricsExposureFlags(fs *pflag.FlagSet, exposure *scaffolds.MetricsExposure) {
+	fs.StringVar(&exposure.Kind, "expose-metrics", "",
+		"if set, expose the metrics endpoint of the manager outside of the cluster, behind auth, for the "+
+			"Prometheus servers running outside of it, may be one of 'ingress', 'httproute'")
+	fs.StringVar(&exposure.Hostname, "metrics-hostname", "",
+		"host name of the metrics endpoint exposed with --expose-metrics")
+}
+
+// bindAgentFlag binds the flag scaffolding the agent mode of the manager, shared by init and edit
+func bindAgentFlag(fs *pflag.FlagSet, agent *string) {
+	fs.StringVar(agent, "agent", "",
+		"if set, add a --mode=agent flag to main.go running the manager as an agent reconciling the objects of "+
+			"its own namespace without leader election, and scaffold config/agent deploying it with the RBAC rules "+
+			"of the manager granted in this namespace, may be one of 'deployment', 'daemonset', the latter "+
+			"running an agent on every node")
+}
+
+// bindWebhookDevFlag binds the flag scaffolding hack/webhook-dev, shared by init and edit
+func bindWebhookDevFlag(fs *pflag.FlagSet, webhookDev *bool) {
+	fs.BoolVar(webhookDev, "webhook-dev", false,
+		"if set, scaffold hack/webhook-dev, which serves the webhooks of the manager run by make run to a "+
+			"development cluster, through a Service forwarding the requests of the API server to the machine "+
+			"running it, with a local serving certificate")
+}
+
+// bindReplaceFlag binds the flag recording the replace directives of go.mod, shared by init and edit
+func bindReplaceFlag(fs *pflag.FlagSet, replace *[]string) {
+	fs.StringArrayVar(replace, "replace", nil,
+		"replace directive of go.mod, written as the -replace flag of go mod edit, old[@v]=new[@v], e.g. "+
+			"sigs.k8s.io/controller-runtime=example.com/forks/controller-runtime@v0.7.0-fork.1 to build the "+
+			"project with the fork of a module, recorded in the PROJECT file and applied to go.mod whenever it "+
+			"is scaffolded, can be repeated")
+}
+
+// bindWiringFlag binds the flag selecting how create api and create webhook wire main.go, shared by init and edit
+func bindWiringFlag(fs *pflag.FlagSet, wiring *string) {
+	fs.StringVar(wiring, "wiring", "",
+		"[experimental] how create api and create webhook insert the code of the new resources in main.go, may "+
+			"be one of 'markers', the default, 'ast', the latter locating the imports, the function adding the "+
+			"types to the scheme and the setup of the reconcilers in the syntax tree of main.go, which may then be "+
+			"reformatted or refactored without its scaffold markers")
+}
+
+// setWiring checks that the wiring of main.go is supported and records it in the PROJECT file, only ast is
+// recorded
+func setWiring(c *config.Config, wiring string) error {
+	switch wiring {
+	case config.WiringMarkers:
+		c.Wiring = ""
+	case config.WiringAST:
+		if c.ManifestsOnly || c.Pattern != "" {
+			return fmt.Errorf("--wiring can only be used in the projects whose main.go runs the reconcilers, not in "+
+				"the projects initialized with --manifests-only or --pattern=%s", scaffolds.PatternAggregatedAPIServer)
+		}
+		c.Wiring = config.WiringAST
+	default:
+		return fmt.Errorf("wiring (%s) is invalid: may be one of %q, %q", wiring, config.WiringMarkers, config.WiringAST)
+	}
+	return nil
+}
+
+// parseModuleReplaces checks the replace directives of the --replace flags and returns them as recorded in the
+// PROJECT file
+func parseModuleReplaces(values []string) ([]string, error) {
+	replace := make([]string, 0, len(values))
+	modules := make(map[string]bool, len(values))
+	for _, value := range values {
+		r, err := scaffolds.ParseModuleReplace(value)
+		if err != nil {
+			return nil, err
+		}
+		if modules[r.Module()] {
+			return nil, fmt.Errorf("%s is replaced by several --replace flags", r.Module())
+		}
+		modules[r.Module()] = true
+		replace = append(replace, r.String())
+	}
+	return replace, nil
+}
+
+// validateAgent checks that the workload of the agent, if any, is supported
+func validateAgent(agent string) error {
+	switch agent {
+	case "", scaffolds.AgentDeployment, scaffolds.AgentDaemonSet:
+		return nil
+	default:
+		return fmt.Errorf("agent (%s) is invalid: may be one of %q, %q",
+			agent, scaffolds.AgentDeployment, scaffolds.AgentDaemonSet)
+	}
+}
+
+// validateMetricsExposure checks the options exposing the metrics endpoint outside of the cluster
+func validateMetricsExposure(cfg *config.Config, exposure scaffolds.MetricsExposure) error {
+	switch exposure.Kind {
+	case "":
+		if exposure.Hostname != "" {
+			return errors.New("--metrics-hostname requires --expose-metrics")
+		}
+		return nil
+	case scaffolds.MetricsExposureIngress:
+		// The networking.k8s.io/v1 Ingresses are served by Kubernetes 1.19 or later
+		if cfg.SupportsKubernetesBefore(1, 19) {
+			return fmt.Errorf("--expose-metrics=%s requires Kubernetes 1.19 or later, the minimum Kubernetes "+
+				"version of the project is %s", exposure.Kind, cfg.MinKubernetesVersion)
+		}
+	case scaffolds.MetricsExposureHTTPRoute:
+	default:
+		return fmt.Errorf("metrics exposure (%s) is invalid: may be one of %q, %q",
+			exposure.Kind, scaffolds.MetricsExposureIngress, scaffolds.MetricsExposureHTTPRoute)
+	}
+
+	if cfg.Pattern == scaffolds.PatternAggregatedAPIServer {
+		return fmt.Errorf("--expose-metrics can not be used with --pattern=%s, whose API server protects its "+
+			"/metrics endpoint itself", scaffolds.PatternAggregatedAPIServer)
+	}
+	if exposure.Hostname == "" {
+		return errors.New("--expose-metrics requires --metrics-hostname")
+	}
+	if err := validation.IsDNS1123Subdomain(exposure.Hostname); err != nil {
+		return fmt.Errorf("metrics hostname (%s) is invalid: %v", exposure.Hostname, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+// validateInit validates the init subcommand run with args in an empty directory
+func validateInit(t *testing.T, args ...string) (*initSubcommand, error) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "init")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	p := &initSubcommand{}
+	p.InjectConfig(&config.Config{Version: config.Version3Alpha})
+	fs := pflag.NewFlagSet("init", pflag.ContinueOnError)
+	p.BindFlags(fs)
+	args = append([]string{"--skip-go-version-check", "--repo", "example.com/operator", "--project-name", "operator"},
+		args...)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return p, p.Validate()
+}
+
+func TestValidateSupplyChainFlags(t *testing.T) {
+	p, err := validateInit(t, "--sbom", "--image-signing", "key", "--image-repo", "registry.example.com/team/operator",
+		"--image-pull-secret", "registry-credentials", "--tool-mirror", "https://mirror.example.com/")
+	if err != nil {
+		t.Fatalf("expected the flags to be valid, got %v", err)
+	}
+	if p.toolMirror != "https://mirror.example.com" {
+		t.Errorf("expected the trailing slash of the tool mirror to be trimmed, got %s", p.toolMirror)
+	}
+
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"--image-signing", "gpg"}, "image signing mode (gpg) is invalid"},
+		{[]string{"--image-repo", "registry.example.com/operator:v1"}, "must not include a tag or a digest"},
+		{[]string{"--image-repo", "registry.example.com/operator@sha256:0123"}, "must not include a tag or a digest"},
+		{[]string{"--image-repo", "registry.example.com:5000/operator"}, ""},
+		{[]string{"--image-pull-secret", "Registry_Credentials"}, "image pull secret name (Registry_Credentials)"},
+		{[]string{"--tool-mirror", "ftp://mirror.example.com"}, "tool mirror (ftp://mirror.example.com) is invalid"},
+		{[]string{"--tool-mirror", "mirror.example.com"}, "tool mirror (mirror.example.com) is invalid"},
+		{[]string{"--manifests-only", "--sbom"}, "can not be used with --manifests-only"},
+		{[]string{"--manifests-only", "--image-signing", "keyless"}, "can not be used with --manifests-only"},
+	} {
+		_, err := validateInit(t, tc.args...)
+		switch {
+		case tc.expected == "" && err != nil:
+			t.Errorf("%v: expected the flags to be valid, got %v", tc.args, err)
+		case tc.expected != "" && (err == nil || !strings.Contains(err.Error(), tc.expected)):
+			t.Errorf("%v: expected an error containing %q, got %v", tc.args, tc.expected, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"go/format"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/agent"
+)
+
+const (
+	// AgentDeployment runs the agent of a namespace with a Deployment
+	AgentDeployment = "deployment"
+	// AgentDaemonSet runs an agent on every node with a DaemonSet, each one reconciling the objects of its node
+	AgentDaemonSet = "daemonset"
+)
+
+// agentFiles returns the files of the agent package and of the kustomize configuration of config/agent, whose
+// agents comply with the Pod Security Standards profile podSecurity
+func agentFiles(c *config.Config, podSecurity string) []file.Builder {
+	daemonSet := c.Agent == AgentDaemonSet
+	files := []file.Builder{
+		&templates.Agent{},
+		&templates.AgentTest{},
+		&agent.Kustomization{DaemonSet: daemonSet},
+		&agent.RoleBinding{},
+		&agent.DeletePatch{DaemonSet: daemonSet},
+	}
+	if daemonSet {
+		return append(files, &agent.DaemonSet{Image: imageName, PodSecurity: podSecurity})
+	}
+	return append(files, &agent.ManagerPatch{})
+}
+
+// podSecurityOf returns the Pod Security Standards profile enforced in the namespace of config/manager
+func podSecurityOf() string {
+	content, err := ioutil.ReadFile(filepath.Join("config", "manager", "manager.yaml"))
+	if err == nil && strings.Contains(string(content), "pod-security.kubernetes.io/enforce: "+PodSecurityBaseline) {
+		return PodSecurityBaseline
+	}
+	return PodSecurityRestricted
+}
+
+// enableAgentMode adds the --mode flag of the agent package to main.go, and passes the options of the manager
+// through it, and returns false if main.go does not create the manager as scaffolded
+func enableAgentMode(repo string) (bool, error) {
+	const (
+		mainPath   = "main.go"
+		parse      = "\tflag.Parse()\n"
+		newManager = "ctrl.NewManager("
+	)
+	importMarker := file.NewMarkerFor(mainPath, "imports").String()
+
+	content, err := ioutil.ReadFile(mainPath)
+	if err != nil {
+		return false, err
+	}
+	main := string(content)
+	if strings.Contains(main, "mode.AddFlag(") {
+		return true, nil
+	}
+	if strings.Count(main, parse) != 1 || strings.Count(main, newManager) != 1 ||
+		strings.Count(main, importMarker) != 1 {
+		return false, nil
+	}
+	// The options are the second argument of ctrl.NewManager, up to its closing parenthesis
+	start := strings.Index(main, newManager) + len(newManager)
+	comma, end, depth := -1, -1, 0
+	for i := start; i < len(main) && end == -1; i++ {
+		switch main[i] {
+		case '(', '{', '[':
+			depth++
+		case ')', '}', ']':
+			if depth == 0 {
+				end = i
+			}
+			depth--
+		case ',':
+			if depth == 0 && comma == -1 {
+				comma = i
+			}
+		}
+	}
+	if comma == -1 || end == -1 {
+		return false, nil
+	}
+	options := strings.TrimSpace(main[comma+1 : end])
+	main = main[:comma+1] + " mode.Options(" + strings.TrimSuffix(options, ",") + ")" + main[end:]
+
+	main = strings.Replace(main, parse, "\tvar mode agent.Mode\n\tmode.AddFlag(flag.CommandLine)\n"+parse, 1)
+	// The import is sorted with the other packages of the project, or in a group of its own
+	agentImport := "\"" + repo + "/internal/agent\"\n\t"
+	if !strings.Contains(main, "\""+repo+"/") {
+		agentImport = "\n\t" + agentImport
+	}
+	main = strings.Replace(main, importMarker, agentImport+importMarker, 1)
+	formatted, err := format.Source([]byte(main))
+	if err != nil {
+		return false, err
+	}
+	// false positive
+	// nolint:gosec
+	return true, journal.WriteFile(mainPath, formatted, 0644)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const agentMain = `package main
+
+import (
+	"flag"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	//+kubebuilder:scaffold:imports
+)
+
+func main() {
+	flag.Parse()
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		LeaderElection:   true,
+		LeaderElectionID: "a.b",
+	})
+	_, _ = mgr, err
+}
+`
+
+func TestEnableAgentMode(t *testing.T) {
+	dir := filepath.Dir(writeTempFile(t, "main.go", agentMain))
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	if enabled, err := enableAgentMode("example.com/fleet"); err != nil || !enabled {
+		t.Fatalf("expected the agent mode to be enabled, got %v (%v)", enabled, err)
+	}
+	expected := `package main
+
+import (
+	"flag"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"example.com/fleet/internal/agent"
+	//+kubebuilder:scaffold:imports
+)
+
+func main() {
+	var mode agent.Mode
+	mode.AddFlag(flag.CommandLine)
+	flag.Parse()
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), mode.Options(ctrl.Options{
+		LeaderElection:   true,
+		LeaderElectionID: "a.b",
+	}))
+	_, _ = mgr, err
+}
+`
+	if content := readFile(t, "main.go"); content != expected {
+		t.Errorf("expected the --mode flag and the options of the agent to be added, got:\n%s", content)
+	}
+
+	// The agent mode is not added twice
+	if enabled, err := enableAgentMode("example.com/fleet"); err != nil || !enabled {
+		t.Fatalf("expected the agent mode to be enabled, got %v (%v)", enabled, err)
+	}
+	if content := readFile(t, "main.go"); content != expected {
+		t.Errorf("expected the agent mode not to be added twice, got:\n%s", content)
+	}
+}
+
+func TestEnableAgentModeCustomMain(t *testing.T) {
+	path := writeTempFile(t, "main.go", "package main\n\nfunc main() {\n\trun()\n}\n")
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(filepath.Dir(path)); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	if enabled, err := enableAgentMode("example.com/fleet"); err != nil || enabled {
+		t.Errorf("expected the agent mode not to be enabled in a main.go without ctrl.NewManager, got %v (%v)",
+			enabled, err)
+	}
+}
@@ -17,22 +17,89 @@ limitations under the License.
 package scaffolds
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/model"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/api"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/apiserver"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/apiservice"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/components"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/crd"
-	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/crd/patches"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/prometheus"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/rbac"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/samples"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/controllers"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/docs"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/hack"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/cmdutil"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/machinery"
 )
 
+// APIOptions are the options of the files scaffolded for a resource by create api
+type APIOptions struct {
+	// DoResource indicates whether to scaffold API Resource or not
+	DoResource bool
+	// DoController indicates whether to scaffold controller files or not
+	DoController bool
+	// Force indicates whether to scaffold controller files even if it exists or not
+	Force bool
+	// OwnerIndex indicates whether to index the objects owned by the controller by their owner or not
+	OwnerIndex bool
+	// Adoption indicates whether to adopt the labeled objects and prune the objects no longer desired or not
+	Adoption bool
+	// Expectations indicates whether to wait for the cache to observe the created and deleted objects or not
+	Expectations bool
+	// DefaultsConfigMap indicates whether to load the operator-wide defaults from a ConfigMap or not
+	DefaultsConfigMap bool
+	// MetadataOnlyWatches indicates whether to watch and cache the secondary objects as metadata only or not
+	MetadataOnlyWatches bool
+	// APIDocs indicates whether to document the fields of the kind in docs/api or not
+	APIDocs bool
+	// Benchmark indicates whether to scaffold the benchmark of the reconciler or not
+	Benchmark bool
+	// CommonTypes indicates whether to reuse the common types of the project in the spec of the kind or not
+	CommonTypes bool
+	// Union indicates whether to add an example discriminated union to the spec of the kind or not
+	Union bool
+	// ReadinessMetrics indicates whether to set the Ready condition of the objects and record their time to ready
+	// or not
+	ReadinessMetrics bool
+	// Pausable indicates whether to skip the reconciliation of the objects annotated as paused or not
+	Pausable bool
+	// CrossNamespaceChildren indicates whether the controller creates objects in other namespaces, tracked by a
+	// label and deleted by a finalizer, or not
+	CrossNamespaceChildren bool
+	// Mocks indicates whether the controller calls the external systems through an interface with a fake, tested
+	// with the fake client, or not
+	Mocks bool
+	// SampleTests indicates whether to test the server-side defaulting and validation of the sample of the kind
+	// against envtest or not
+	SampleTests bool
+	// Scale enables the scale subresource of the kind and scaffolds a sample HorizontalPodAutoscaler, if not nil
+	Scale *ScaleSubresource
+	// RawExtensionFields are the fields of the spec holding arbitrary JSON objects preserved by the API server
+	RawExtensionFields []RawExtensionField
+
+	// CacheSelector narrows the objects of the resource cached by the manager, if not empty
+	CacheSelector CacheSelector
+
+	// Children are the kinds of the objects created or patched by the controller
+	Children []Child
+
+	// ResyncPeriod is the period of the reconciliations of the objects even if nothing changed, if not zero
+	ResyncPeriod time.Duration
+}
+
 var _ cmdutil.Scaffolder = &apiScaffolder{}
 
 // apiScaffolder contains configuration for generating scaffolding for Go type
@@ -43,12 +110,63 @@ type apiScaffolder struct {
 	resource    *resource.Resource
 	// plugins is the list of plugins we should allow to transform our generated scaffolding
 	plugins []model.Plugin
-	// doResource indicates whether to scaffold API Resource or not
-	doResource bool
-	// doController indicates whether to scaffold controller files or not
-	doController bool
-	// force indicates whether to scaffold controller files even if it exists or not
-	force bool
+	// options are the options of the files scaffolded for the resource
+	options APIOptions
+}
+
+// ScaleSubresource holds the paths of the replicas and label selector fields of the scale subresource of a kind
+type ScaleSubresource = api.ScaleSubresource
+
+// DefaultScaleSubresource are the scale subresource paths used when --scale-subresource has no value
+const DefaultScaleSubresource = ".spec.replicas:.status.replicas:.status.selector"
+
+var (
+	specFieldPathRegexp   = regexp.MustCompile(`^\.spec\.[a-z][a-zA-Z0-9]*$`)
+	statusFieldPathRegexp = regexp.MustCompile(`^\.status\.[a-z][a-zA-Z0-9]*$`)
+)
+
+// ParseScaleSubresource parses the specReplicasPath:statusReplicasPath[:labelSelectorPath] value of the
+// --scale-subresource flag. The paths are fields of the spec and of the status, added to the types of the kind.
+func ParseScaleSubresource(value string) (*ScaleSubresource, error) {
+	paths := strings.Split(value, ":")
+	if len(paths) != 2 && len(paths) != 3 {
+		return nil, fmt.Errorf("invalid --scale-subresource %q, expected "+
+			"specReplicasPath:statusReplicasPath[:labelSelectorPath], e.g. %s", value, DefaultScaleSubresource)
+	}
+	scale := &ScaleSubresource{SpecReplicasPath: paths[0], StatusReplicasPath: paths[1]}
+	if len(paths) == 3 {
+		scale.LabelSelectorPath = paths[2]
+	}
+
+	if !specFieldPathRegexp.MatchString(scale.SpecReplicasPath) {
+		return nil, fmt.Errorf("invalid spec replicas path %q, expected a field of the spec, e.g. .spec.replicas",
+			scale.SpecReplicasPath)
+	}
+	// The spec of the kind is scaffolded with the foo and container fields
+	if name := scale.SpecReplicas().JSONName; name == "foo" || name == "container" {
+		return nil, fmt.Errorf("invalid spec replicas path %q, the %s field is already scaffolded in the spec",
+			scale.SpecReplicasPath, name)
+	}
+	if !statusFieldPathRegexp.MatchString(scale.StatusReplicasPath) {
+		return nil, fmt.Errorf("invalid status replicas path %q, expected a field of the status, "+
+			"e.g. .status.replicas", scale.StatusReplicasPath)
+	}
+	if scale.LabelSelectorPath != "" {
+		if !statusFieldPathRegexp.MatchString(scale.LabelSelectorPath) {
+			return nil, fmt.Errorf("invalid label selector path %q, expected a field of the status, "+
+				"e.g. .status.selector", scale.LabelSelectorPath)
+		}
+		if scale.LabelSelectorPath == scale.StatusReplicasPath {
+			return nil, errors.New("the status replicas and the label selector paths must be different fields")
+		}
+	}
+	return scale, nil
+}
+
+// SupportsUnionRules returns whether the CEL validation rules of the unions of the resource are enforced, which
+// requires Kubernetes 1.25 and a v1 CRD
+func SupportsUnionRules(cfg *config.Config, res *resource.Resource) bool {
+	return !cfg.SupportsKubernetesBefore(1, 25) && res.API.CRDVersion == "v1"
 }
 
 // NewAPIScaffolder returns a new Scaffolder for API/controller creation operations
@@ -56,17 +174,15 @@ func NewAPIScaffolder(
 	config *config.Config,
 	boilerplate string,
 	res *resource.Resource,
-	doResource, doController, force bool,
+	options APIOptions,
 	plugins []model.Plugin,
 ) cmdutil.Scaffolder {
 	return &apiScaffolder{
-		config:       config,
-		boilerplate:  boilerplate,
-		resource:     res,
-		plugins:      plugins,
-		doResource:   doResource,
-		doController: doController,
-		force:        force,
+		config:      config,
+		boilerplate: boilerplate,
+		resource:    res,
+		plugins:     plugins,
+		options:     options,
 	}
 }
 
@@ -86,23 +202,79 @@ func (s *apiScaffolder) newUniverse() *model.Universe {
 
 // TODO: re-use universe created by s.newUniverse() if possible.
 func (s *apiScaffolder) scaffold() error {
-	if s.doResource {
+	if s.config.Pattern == PatternAggregatedAPIServer {
+		return s.scaffoldAggregatedResource()
+	}
+
+	if s.options.DoResource {
+		// The kind gains a new version if it already has others
+		others := otherVersions(s.config, s.resource)
 
 		s.config.UpdateResources(s.resource.Data())
 
 		if err := machinery.NewScaffold(s.plugins...).Execute(
 			s.newUniverse(),
-			&api.Types{Force: s.force},
+			&api.Types{CommonTypes: s.options.CommonTypes, Scale: s.options.Scale,
+				NextReconcileTime: s.options.ResyncPeriod != 0, Union: s.options.Union,
+				UnionRules: SupportsUnionRules(s.config, s.resource), Conditions: s.options.ReadinessMetrics,
+				Paused: s.options.Pausable, RawExtensionFields: s.options.RawExtensionFields, Force: s.options.Force},
 			&api.Group{},
-			&samples.CRDSample{Force: s.force},
+			&samples.CRDSample{RawExtensionFields: s.options.RawExtensionFields, Force: s.options.Force},
 			&rbac.CRDEditorRole{},
 			&rbac.CRDViewerRole{},
-			&patches.EnableWebhookPatch{CRDVersion: s.resource.API.CRDVersion},
-			&patches.EnableCAInjectionPatch{CRDVersion: s.resource.API.CRDVersion},
 		); err != nil {
 			return fmt.Errorf("error scaffolding APIs: %v", err)
 		}
 
+		if s.options.Union {
+			if SupportsUnionRules(s.config, s.resource) {
+				fmt.Println("The example union of the spec is validated by the CEL validation rules of the CRD, " +
+					"validate it on the clusters older than Kubernetes 1.25 with the validating webhook " +
+					"scaffolded by create webhook --programmatic-validation")
+				version := controllerGenVersion(s.config)
+				if version != "" && semver.Compare(version, celControllerGenVersion) < 0 {
+					fmt.Printf("The CEL validation rules are generated by controller-gen %s or later, update the "+
+						"controller-gen %s installed by the %s to add them to the CRD\n",
+						celControllerGenVersion, version, TaskRunnerPath(s.config))
+				}
+			} else {
+				fmt.Println("The example union of the spec is validated by the validating webhook " +
+					"scaffolded by create webhook --programmatic-validation, the CEL validation rules of the CRD " +
+					"require Kubernetes 1.25 and a v1 CRD")
+			}
+		}
+
+		if s.options.Scale != nil {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&samples.HPASample{APIVersion: KubernetesProfileFor(s.config).HPAVersion, Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding HorizontalPodAutoscaler sample: %v", err)
+			}
+		}
+
+		// The registration package of the group is scaffolded by its first version
+		if s.config.MultiGroup && s.config.GroupRegistration && s.resource.Group != "" {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&api.GroupDoc{},
+				&api.GroupRegistration{},
+				&api.GroupRegistrationUpdater{},
+			); err != nil {
+				return fmt.Errorf("error scaffolding group registration: %v", err)
+			}
+		}
+
+		// The common types are scaffolded once, by the first kind reusing them
+		if s.options.CommonTypes {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&api.CommonTypes{},
+			); err != nil {
+				return fmt.Errorf("error scaffolding common types: %v", err)
+			}
+		}
+
 		if err := machinery.NewScaffold().Execute(
 			s.newUniverse(),
 			&crd.Kustomization{},
@@ -111,25 +283,264 @@ func (s *apiScaffolder) scaffold() error {
 			return fmt.Errorf("error scaffolding kustomization: %v", err)
 		}
 
+		if s.options.APIDocs {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&docs.APIReference{},
+				&hack.APIDocs{},
+			); err != nil {
+				return fmt.Errorf("error scaffolding API reference: %v", err)
+			}
+		}
+
+		if len(others) != 0 {
+			if err := ensureStorageVersion(s.config, s.resource, others); err != nil {
+				return fmt.Errorf("error marking the storage version: %v", err)
+			}
+			if err := enableConversion(s.config, s.resource); err != nil {
+				return fmt.Errorf("error enabling the conversion webhook: %v", err)
+			}
+		}
+
 	}
 
-	if s.doController {
+	if s.options.DoController {
 		if err := machinery.NewScaffold(s.plugins...).Execute(
 			s.newUniverse(),
-			&controllers.SuiteTest{WireResource: s.doResource, Force: s.force},
-			&controllers.Controller{ControllerRuntimeVersion: ControllerRuntimeVersion, WireResource: s.doResource,
-				Force: s.force},
+			&controllers.SuiteTest{WireResource: s.options.DoResource, Force: s.options.Force},
+			&controllers.Controller{ControllerRuntimeVersion: ControllerRuntimeVersion, WireResource: s.options.DoResource,
+				OwnerIndex: s.options.OwnerIndex, Adoption: s.options.Adoption, Expectations: s.options.Expectations,
+				DefaultsConfigMap: s.options.DefaultsConfigMap, MetadataOnlyWatches: s.options.MetadataOnlyWatches,
+				Children: s.options.Children, ResyncPeriod: s.options.ResyncPeriod, ReadinessMetrics: s.options.ReadinessMetrics,
+				Pausable: s.options.Pausable, CrossNamespaceChildren: s.options.CrossNamespaceChildren, Mocks: s.options.Mocks,
+				Force: s.options.Force},
 		); err != nil {
 			return fmt.Errorf("error scaffolding controller: %v", err)
 		}
+
+		if s.options.DoResource {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Events{},
+				&templates.EventsTest{},
+			); err != nil {
+				return fmt.Errorf("error scaffolding events: %v", err)
+			}
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Errors{},
+				&templates.ErrorsTest{},
+			); err != nil {
+				return fmt.Errorf("error scaffolding errors: %v", err)
+			}
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Status{},
+				&templates.StatusTest{},
+			); err != nil {
+				return fmt.Errorf("error scaffolding status: %v", err)
+			}
+		}
+
+		if len(s.options.Children) != 0 {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Children{},
+				&templates.ChildrenTest{},
+			); err != nil {
+				return fmt.Errorf("error scaffolding children: %v", err)
+			}
+		}
+
+		if s.options.ResyncPeriod != 0 {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Resync{},
+				&templates.ResyncTest{},
+				&controllers.ResyncTest{Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding resync: %v", err)
+			}
+		}
+
+		if s.options.SampleTests {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&controllers.SampleTest{Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding sample tests: %v", err)
+			}
+		}
+
+		if s.options.ReadinessMetrics {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Readiness{},
+				&templates.ReadinessTest{},
+				&prometheus.SLO{Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding readiness metrics: %v", err)
+			}
+			if err := addResource(filepath.Join("config", "prometheus", "kustomization.yaml"),
+				s.resource.Replacer().Replace("%[group]_%[kind]_slo.yaml")); err != nil {
+				return fmt.Errorf("error adding the SLO to the prometheus kustomization: %v", err)
+			}
+		}
+
+		if s.options.Pausable {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Pause{},
+				&templates.PauseTest{},
+				&controllers.PauseTest{Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding pause: %v", err)
+			}
+		}
+
+		if s.options.CrossNamespaceChildren {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.CrossNamespace{},
+				&templates.CrossNamespaceTest{},
+				&controllers.CrossNamespaceTest{Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding cross-namespace children: %v", err)
+			}
+		}
+
+		if s.options.Mocks {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Interceptor{},
+				&templates.InterceptorTest{},
+				&controllers.External{Force: s.options.Force},
+				&controllers.ExternalFake{Force: s.options.Force},
+				&controllers.ExternalTest{Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding mocks: %v", err)
+			}
+		}
+
+		if s.options.OwnerIndex {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Indexer{},
+				&controllers.OwnerIndexTest{Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding owner index: %v", err)
+			}
+		}
+
+		if s.options.Adoption {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Adoption{},
+				&controllers.AdoptionTest{Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding adoption: %v", err)
+			}
+		}
+
+		if s.options.Expectations {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Expectations{},
+				&controllers.ExpectationsTest{Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding expectations: %v", err)
+			}
+		}
+
+		if s.options.DefaultsConfigMap {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&templates.Defaults{},
+				&templates.DefaultsTest{},
+				&components.DefaultsKustomization{},
+				&components.DefaultsConfigMap{},
+				&components.DefaultsRole{},
+			); err != nil {
+				return fmt.Errorf("error scaffolding defaults: %v", err)
+			}
+			if err := addComponent(filepath.Join("config", "default", "kustomization.yaml"), "defaults",
+				"# [DEFAULTS] The ConfigMap of the operator-wide defaults of the controllers."); err != nil {
+				return fmt.Errorf("error enabling the defaults: %v", err)
+			}
+		}
+
+		if s.options.Benchmark {
+			if err := machinery.NewScaffold().Execute(
+				s.newUniverse(),
+				&controllers.BenchmarkTest{OwnerIndex: s.options.OwnerIndex, Expectations: s.options.Expectations,
+					Force: s.options.Force},
+			); err != nil {
+				return fmt.Errorf("error scaffolding benchmark: %v", err)
+			}
+		}
+	}
+
+	mainUpdater := &templates.MainUpdater{WireResource: s.options.DoResource, WireController: s.options.DoController,
+		GroupRegistration: s.config.GroupRegistration}
+	if !s.options.CacheSelector.IsEmpty() {
+		if err := machinery.NewScaffold().Execute(
+			s.newUniverse(),
+			&templates.CacheSelector{},
+			&templates.CacheSelectorTest{},
+		); err != nil {
+			return fmt.Errorf("error scaffolding cache selectors: %v", err)
+		}
+		enabled, err := enableCacheSelectors()
+		if err != nil {
+			return fmt.Errorf("error enabling the cache selectors: %v", err)
+		}
+		if enabled {
+			mainUpdater.CacheNamespace = s.options.CacheSelector.Namespace
+			mainUpdater.CacheLabelSelector = s.options.CacheSelector.Labels
+		} else {
+			fmt.Println("main.go does not create the manager with ctrl.NewManager(ctrl.GetConfigOrDie(), ...): " +
+				"pass the config returned by the Config method of cacheselector.Selectors to it to narrow the " +
+				"cached objects")
+		}
 	}
 
 	if err := machinery.NewScaffold(s.plugins...).Execute(
 		s.newUniverse(),
-		&templates.MainUpdater{WireResource: s.doResource, WireController: s.doController},
+		mainUpdater,
 	); err != nil {
 		return fmt.Errorf("error updating main.go: %v", err)
 	}
 
 	return nil
 }
+
+// scaffoldAggregatedResource scaffolds a resource served by the aggregated API server of the project
+func (s *apiScaffolder) scaffoldAggregatedResource() error {
+	if !s.options.DoResource {
+		return nil
+	}
+
+	s.config.UpdateResources(s.resource.Data())
+
+	if err := machinery.NewScaffold(s.plugins...).Execute(
+		s.newUniverse(),
+		&api.Types{Force: s.options.Force},
+		&api.Group{},
+		&samples.CRDSample{Force: s.options.Force},
+		&rbac.CRDEditorRole{},
+		&rbac.CRDViewerRole{},
+		&apiservice.APIService{},
+	); err != nil {
+		return fmt.Errorf("error scaffolding APIs: %v", err)
+	}
+
+	if err := machinery.NewScaffold().Execute(
+		s.newUniverse(),
+		&apiservice.Kustomization{},
+		&apiserver.APIServerUpdater{},
+	); err != nil {
+		return fmt.Errorf("error updating the API server: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"testing"
+)
+
+func TestParseScaleSubresource(t *testing.T) {
+	scale, err := ParseScaleSubresource(DefaultScaleSubresource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas," +
+		"selectorpath=.status.selector"; scale.Marker() != expected {
+		t.Errorf("expected the marker %q, got %q", expected, scale.Marker())
+	}
+	if field := scale.LabelSelector(); field.Name != "Selector" || field.JSONName != "selector" {
+		t.Errorf("expected the Selector field, got %+v", field)
+	}
+
+	scale, err = ParseScaleSubresource(".spec.size:.status.currentSize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scale.LabelSelectorPath != "" || scale.StatusReplicas().Name != "CurrentSize" {
+		t.Errorf("expected the CurrentSize status field without selector, got %+v", scale)
+	}
+
+	for _, invalid := range []string{
+		".spec.replicas",
+		".spec.replicas:.status.replicas:.status.selector:.status.other",
+		".status.replicas:.status.replicas",
+		".spec.template.replicas:.status.replicas",
+		".spec.foo:.status.replicas",
+		".spec.replicas:.status.replicas:.status.replicas",
+		".spec.replicas:.status.replicas:.spec.selector",
+	} {
+		if _, err := ParseScaleSubresource(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/validation"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates"
+)
+
+// CacheSelector narrows the objects of a resource cached by the manager, which caches all of them otherwise
+type CacheSelector struct {
+	// Namespace narrows them to the objects of a namespace, if not empty.
+	Namespace string
+	// Labels narrows them to the objects matching a label selector, e.g. app=fleet,tier!=test, if not empty.
+	Labels string
+}
+
+// IsEmpty returns true if s narrows no object
+func (s CacheSelector) IsEmpty() bool {
+	return s.Namespace == "" && s.Labels == ""
+}
+
+const (
+	labelKeyRegexp   = `([a-z0-9]([-a-z0-9.]*[a-z0-9])?/)?[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?`
+	labelValueRegexp = `([A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?)?`
+)
+
+var (
+	// labelRequirementRegexp matches the requirements of a label selector: key, !key, key=value, key==value,
+	// key!=value, key in (values) and key notin (values)
+	labelRequirementRegexp = regexp.MustCompile(`^(!?` + labelKeyRegexp + `|` + labelKeyRegexp +
+		` *(=|==|!=) *` + labelValueRegexp + `|` + labelKeyRegexp + ` +(in|notin) +\( *` + labelValueRegexp +
+		`( *, *` + labelValueRegexp + `)* *\))$`)
+)
+
+// Validate checks that the namespace is a DNS label and the label selector is well-formed
+func (s CacheSelector) Validate() error {
+	if s.Namespace != "" {
+		if errs := validation.IsDNS1123Label(s.Namespace); len(errs) != 0 {
+			return fmt.Errorf("namespace (%s) is invalid: %s", s.Namespace, strings.Join(errs, ", "))
+		}
+	}
+	if s.Labels == "" {
+		return nil
+	}
+
+	for _, requirement := range labelRequirements(s.Labels) {
+		if !labelRequirementRegexp.MatchString(requirement) {
+			return fmt.Errorf("label selector (%s) is invalid: %q is not a requirement such as key=value, "+
+				"key!=value, key in (value1,value2) or !key", s.Labels, requirement)
+		}
+	}
+	return nil
+}
+
+// labelRequirements splits a label selector into its requirements
+func labelRequirements(selector string) []string {
+	var requirements []string
+	// The values of the in and notin requirements are separated by commas too
+	start, depth := 0, 0
+	for i := 0; i <= len(selector); i++ {
+		switch {
+		case i < len(selector) && selector[i] == '(':
+			depth++
+		case i < len(selector) && selector[i] == ')':
+			depth--
+		case i == len(selector) || selector[i] == ',' && depth == 0:
+			requirements = append(requirements, strings.TrimSpace(selector[start:i]))
+			start = i + 1
+		}
+	}
+	return requirements
+}
+
+// enableCacheSelectors adds the cache selectors to main.go, whose cache marker is only scaffolded by the first
+// resource whose cached objects are narrowed, and returns false if main.go does not create the manager as
+// scaffolded
+func enableCacheSelectors() (bool, error) {
+	const (
+		mainPath   = "main.go"
+		mainFunc   = "\nfunc main() {\n"
+		newManager = "ctrl.NewManager(ctrl.GetConfigOrDie(), "
+	)
+	content, err := ioutil.ReadFile(mainPath)
+	if err != nil {
+		return false, err
+	}
+	main := string(content)
+	if strings.Contains(main, "+kubebuilder:scaffold:"+templates.CacheMarker) {
+		return true, nil
+	}
+	if !strings.Contains(main, mainFunc) || strings.Count(main, newManager) != 1 {
+		return false, nil
+	}
+
+	main = strings.Replace(main, mainFunc, `
+// cacheSelectors narrow the objects of the watched types cached by the manager, which lists and watches all
+// of them otherwise, to the ones of a namespace or to the ones matching a label selector.
+var cacheSelectors = cacheselector.Selectors{
+	//+kubebuilder:scaffold:`+templates.CacheMarker+`
+}
+`+mainFunc, 1)
+	main = strings.Replace(main, newManager, "ctrl.NewManager(cacheSelectors.Config(ctrl.GetConfigOrDie()), ", 1)
+	// false positive
+	// nolint:gosec
+	return true, journal.WriteFile(mainPath, []byte(main), 0644)
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/controllers"
+)
+
+// Child is a kind of the objects controlled by the reconciled objects, created or patched by their controller
+type Child = controllers.Child
+
+// childKinds are the kinds of the builtin resources that may be the children of the reconciled objects
+var childKinds = map[string]Child{
+	"ConfigMap":             builtinChild("core", "v1", "ConfigMap", "configmaps"),
+	"DaemonSet":             builtinChild("apps", "v1", "DaemonSet", "daemonsets"),
+	"Deployment":            builtinChild("apps", "v1", "Deployment", "deployments"),
+	"Job":                   builtinChild("batch", "v1", "Job", "jobs"),
+	"PersistentVolumeClaim": builtinChild("core", "v1", "PersistentVolumeClaim", "persistentvolumeclaims"),
+	"Secret":                builtinChild("core", "v1", "Secret", "secrets"),
+	"Service":               builtinChild("core", "v1", "Service", "services"),
+	"ServiceAccount":        builtinChild("core", "v1", "ServiceAccount", "serviceaccounts"),
+	"StatefulSet":           builtinChild("apps", "v1", "StatefulSet", "statefulsets"),
+}
+
+// builtinChild returns the child kind of a builtin resource, whose Go package is k8s.io/api/<group>/<version>
+func builtinChild(group, version, kind, resource string) Child {
+	return Child{
+		Kind:        kind,
+		ImportAlias: group + version,
+		Package:     fmt.Sprintf("k8s.io/api/%s/%s", group, version),
+		Group:       group,
+		Resource:    resource,
+	}
+}
+
+// ParseChildren returns the child kinds of the values of the --with-child flag, which name builtin kinds
+func ParseChildren(kinds []string) ([]Child, error) {
+	children := make([]Child, 0, len(kinds))
+	parsed := map[string]bool{}
+	for _, kind := range kinds {
+		child, found := childKinds[kind]
+		if !found {
+			supported := make([]string, 0, len(childKinds))
+			for supportedKind := range childKinds {
+				supported = append(supported, supportedKind)
+			}
+			sort.Strings(supported)
+			return nil, fmt.Errorf("unsupported child kind %q, must be one of %s", kind, strings.Join(supported, ", "))
+		}
+		if parsed[kind] {
+			return nil, fmt.Errorf("child kind %q is set more than once", kind)
+		}
+		parsed[kind] = true
+		children = append(children, child)
+	}
+	return children, nil
+}
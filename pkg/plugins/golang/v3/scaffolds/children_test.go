@@ -0,0 +1,45 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"testing"
+)
+
+func TestParseChildren(t *testing.T) {
+	children, err := ParseChildren([]string{"Deployment", "Service"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 2 {
+		t.Fatalf("expected 2 children, got %+v", children)
+	}
+	if deployment := children[0]; deployment.ImportAlias != "appsv1" || deployment.Package != "k8s.io/api/apps/v1" ||
+		deployment.RBAC() != "kubebuilder:rbac:groups=apps,resources=deployments,"+
+			"verbs=get;list;watch;create;update;patch;delete" {
+		t.Errorf("unexpected Deployment child %+v", deployment)
+	}
+	if service := children[1]; service.ImportAlias != "corev1" || service.Group != "core" {
+		t.Errorf("unexpected Service child %+v", service)
+	}
+
+	for _, invalid := range [][]string{{"Pod"}, {"deployment"}, {"Service", "Service"}} {
+		if _, err := ParseChildren(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
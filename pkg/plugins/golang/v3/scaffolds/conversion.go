@@ -0,0 +1,230 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/markers"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/crd"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/crd/patches"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/machinery"
+)
+
+const storageVersionMarker = "kubebuilder:storageversion"
+
+// otherVersions returns the versions of the kind of res, other than the version of res, of the API resources of cfg
+func otherVersions(cfg *config.Config, res *resource.Resource) []config.ResourceData {
+	var versions []config.ResourceData
+	for _, r := range cfg.Resources {
+		if r.Group == res.Group && r.Kind == res.Kind && r.Version != res.Version && r.API != nil {
+			versions = append(versions, r)
+		}
+	}
+	return versions
+}
+
+// hasConversionWebhook returns whether a conversion webhook of the kind of res is recorded in cfg, and the CRD
+// version of its API resources
+func hasConversionWebhook(cfg *config.Config, res *resource.Resource) (bool, string) {
+	conversion, crdVersion := res.Webhooks.Conversion, res.API.CRDVersion
+	for _, r := range cfg.Resources {
+		if r.Group != res.Group || r.Kind != res.Kind {
+			continue
+		}
+		if r.Webhooks != nil && r.Webhooks.Conversion {
+			conversion = true
+		}
+		if r.API != nil && r.API.CRDVersion != "" {
+			crdVersion = r.API.CRDVersion
+		}
+	}
+	return conversion, crdVersion
+}
+
+// enableConversion enables the conversion webhook of the CRD of res, once its kind has several versions and a
+// conversion webhook recorded in the PROJECT file: it scaffolds the patches of the CRD setting its conversion
+// strategy and injecting the CA of the webhook, adds them to config/crd/kustomization.yaml, and enables the
+// components of the webhook and of its certificate in config/default/kustomization.yaml.
+func enableConversion(cfg *config.Config, res *resource.Resource) error {
+	conversion, crdVersion := hasConversionWebhook(cfg, res)
+	if !conversion {
+		fmt.Printf("The %s kind has several versions: run \"create webhook --group %s --version %s --kind %s "+
+			"--conversion\" to serve their conversion\n", res.Kind, res.Group, res.Version, res.Kind)
+		return nil
+	}
+
+	vault := cfg.CertProvider == CertProviderVault
+	crdKustomization := filepath.Join("config", "crd", "kustomization.yaml")
+	crdLines := []string{fmt.Sprintf("- patches/webhook_in_%s.yaml", res.Plural)}
+	defaultLines := []string{"- ../components/webhook"}
+	files := []file.Builder{&patches.EnableWebhookPatch{CRDVersion: crdVersion}}
+	if vault {
+		defaultLines = append(defaultLines, "- ../components/vault")
+	} else {
+		crdLines = append(crdLines, fmt.Sprintf("- patches/cainjection_in_%s.yaml", res.Plural))
+		defaultLines = append(defaultLines, "- ../components/certmanager")
+		files = append(files, &patches.EnableCAInjectionPatch{CRDVersion: crdVersion})
+	}
+	files = append(files, &crd.Kustomization{ConversionWebhook: true, CAInjection: !vault})
+
+	// The projects scaffolded before the patches were added on demand list them commented out
+	commented, err := commentedLines(crdKustomization, crdLines)
+	if err != nil {
+		return err
+	}
+	if err := uncommentLines(crdKustomization, commented); err != nil {
+		return err
+	}
+	if err := machinery.NewScaffold().Execute(
+		model.NewUniverse(model.WithConfig(cfg), model.WithResource(res)),
+		files...,
+	); err != nil {
+		return err
+	}
+	if len(commented) != len(crdLines) {
+		fmt.Printf("Enabled the conversion webhook in %s\n", crdKustomization)
+	}
+	if err := uncommentLines(filepath.Join("config", "default", "kustomization.yaml"), defaultLines); err != nil {
+		return err
+	}
+	if vault {
+		fmt.Printf("Set the caBundle of the conversion webhook of the %s CRD to the CA of Vault, "+
+			"see config/components/vault/kustomization.yaml\n", res.Kind)
+	}
+	return nil
+}
+
+// commentedLines returns the lines that are commented out in the file of path
+func commentedLines(path string, lines []string) ([]string, error) {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	var commented []string
+	for _, fileLine := range strings.Split(string(content), "\n") {
+		for _, line := range lines {
+			if strings.TrimSpace(fileLine) == "#"+line {
+				commented = append(commented, line)
+			}
+		}
+	}
+	return commented, nil
+}
+
+// uncommentLines uncomments the commented lines of the file of path, printing a warning for the lines not found
+func uncommentLines(path string, lines []string) error {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	fileLines := strings.Split(string(content), "\n")
+	changed := false
+	for _, line := range lines {
+		found := false
+		for i, fileLine := range fileLines {
+			switch strings.TrimSpace(fileLine) {
+			case line:
+				found = true
+			case "#" + line:
+				fileLines[i] = strings.Replace(fileLine, "#"+line, line, 1)
+				found, changed = true, true
+			}
+		}
+		if !found {
+			fmt.Printf("Unable to find %q in %s, add it to enable the conversion webhook\n", line, path)
+		}
+	}
+	if !changed {
+		return nil
+	}
+	fmt.Printf("Enabled the conversion webhook in %s\n", path)
+	return journal.WriteFile(path, []byte(strings.Join(fileLines, "\n")), 0644) //nolint:gosec
+}
+
+// markStorageVersion marks the version of the types file of path as the storage version of kind, adding the
+// storage version marker after the root object marker of the kind.
+func markStorageVersion(path, kind string, markerDocs bool) error {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(content), "\n")
+
+	// The markers of the kind are in the comment blocks above its declaration
+	for i, line := range lines {
+		if strings.TrimSpace(line) != fmt.Sprintf("type %s struct {", kind) {
+			continue
+		}
+		rootMarker := -1
+		for j := i - 1; j >= 0; j-- {
+			trimmed := strings.TrimSpace(lines[j])
+			if trimmed != "" && !strings.HasPrefix(trimmed, "//") {
+				break
+			}
+			if strings.Contains(trimmed, storageVersionMarker) {
+				return nil
+			}
+			if strings.Contains(trimmed, "kubebuilder:object:root=true") {
+				rootMarker = j
+			}
+		}
+		if rootMarker == -1 {
+			break
+		}
+		marker := strings.Split(markers.Format(markerDocs, storageVersionMarker), "\n")
+		lines = append(lines[:rootMarker+1], append(marker, lines[rootMarker+1:]...)...)
+		fmt.Printf("Marked the version of %s as the storage version of %s\n", path, kind)
+		return journal.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644) //nolint:gosec
+	}
+	fmt.Printf("Unable to find the markers of %s in %s, add the //+%s marker to its stored version\n",
+		kind, path, storageVersionMarker)
+	return nil
+}
+
+// ensureStorageVersion marks the first of the other versions of the kind of res as its storage version, the
+// version its objects are already stored in, unless one of its versions is already marked.
+func ensureStorageVersion(cfg *config.Config, res *resource.Resource, others []config.ResourceData) error {
+	paths := []string{TypesPath(cfg, res)}
+	for _, other := range others {
+		otherRes := *res
+		otherRes.Version = other.Version
+		paths = append(paths, TypesPath(cfg, &otherRes))
+	}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path) //nolint:gosec
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if strings.Contains(string(content), "+"+storageVersionMarker) {
+			return nil
+		}
+	}
+	if _, err := os.Stat(paths[1]); os.IsNotExist(err) {
+		return nil
+	}
+	return markStorageVersion(paths[1], res.Kind, cfg.MarkerDocs)
+}
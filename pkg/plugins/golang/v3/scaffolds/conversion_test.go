@@ -0,0 +1,163 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+)
+
+func writeTempFile(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "conversion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestUncommentLines(t *testing.T) {
+	path := writeTempFile(t, "kustomization.yaml", `patchesStrategicMerge:
+#- patches/webhook_in_frigates.yaml
+#- patches/webhook_in_destroyers.yaml
+- patches/cainjection_in_frigates.yaml
+`)
+
+	if err := uncommentLines(path, []string{
+		"- patches/webhook_in_frigates.yaml",
+		"- patches/cainjection_in_frigates.yaml",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `patchesStrategicMerge:
+- patches/webhook_in_frigates.yaml
+#- patches/webhook_in_destroyers.yaml
+- patches/cainjection_in_frigates.yaml
+`
+	if content := readFile(t, path); content != expected {
+		t.Errorf("expected the patch of frigates only to be uncommented, got:\n%s", content)
+	}
+}
+
+func TestCommentedLines(t *testing.T) {
+	path := writeTempFile(t, "kustomization.yaml", `patchesStrategicMerge:
+#- patches/webhook_in_frigates.yaml
+- patches/cainjection_in_frigates.yaml
+#+kubebuilder:scaffold:crdkustomizewebhookpatch
+`)
+
+	commented, err := commentedLines(path, []string{
+		"- patches/webhook_in_frigates.yaml",
+		"- patches/cainjection_in_frigates.yaml",
+		"- patches/webhook_in_destroyers.yaml",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commented) != 1 || commented[0] != "- patches/webhook_in_frigates.yaml" {
+		t.Errorf("expected the patch of frigates only to be commented out, got %v", commented)
+	}
+}
+
+func TestHasConversionWebhook(t *testing.T) {
+	cfg := &config.Config{Resources: []config.ResourceData{
+		{Group: "ship", Version: "v1", Kind: "Frigate", API: &config.API{CRDVersion: "v1beta1"},
+			Webhooks: &config.Webhooks{Conversion: true}},
+		{Group: "ship", Version: "v2", Kind: "Frigate", API: &config.API{CRDVersion: "v1beta1"}},
+		{Group: "ship", Version: "v1", Kind: "Destroyer", API: &config.API{CRDVersion: "v1"}},
+	}}
+
+	conversion, crdVersion := hasConversionWebhook(cfg, &resource.Resource{Group: "ship", Version: "v2", Kind: "Frigate"})
+	if !conversion || crdVersion != "v1beta1" {
+		t.Errorf("expected the conversion webhook of another version of the kind and its CRD version, got %t, %q",
+			conversion, crdVersion)
+	}
+	if conversion, _ := hasConversionWebhook(cfg, &resource.Resource{Group: "ship", Version: "v2",
+		Kind: "Destroyer"}); conversion {
+		t.Error("expected the kind without a conversion webhook not to have one")
+	}
+}
+
+const frigateTypes = `package v1
+
+type FrigateStatus struct {
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// Frigate is the Schema for the frigates API
+type Frigate struct {
+}
+
+//+kubebuilder:object:root=true
+
+// FrigateList contains a list of Frigate
+type FrigateList struct {
+}
+`
+
+func TestMarkStorageVersion(t *testing.T) {
+	path := writeTempFile(t, "frigate_types.go", frigateTypes)
+
+	if err := markStorageVersion(path, "Frigate", false); err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(frigateTypes, "//+kubebuilder:object:root=true\n//+kubebuilder:subresource",
+		"//+kubebuilder:object:root=true\n//+kubebuilder:storageversion\n//+kubebuilder:subresource", 1)
+	if content := readFile(t, path); content != expected {
+		t.Errorf("expected the kind to be marked as the storage version, got:\n%s", content)
+	}
+
+	// The marker is not added twice
+	if err := markStorageVersion(path, "Frigate", false); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, path); content != expected {
+		t.Errorf("expected the storage version marker not to be added twice, got:\n%s", content)
+	}
+}
+
+func TestMarkStorageVersionDocs(t *testing.T) {
+	path := writeTempFile(t, "frigate_types.go", frigateTypes)
+
+	if err := markStorageVersion(path, "Frigate", true); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, path); !strings.Contains(content, "//+kubebuilder:object:root=true\n"+
+		"// Marks the version as the storage version of the CRD.\n") {
+		t.Errorf("expected the storage version marker to be documented, got:\n%s", content)
+	}
+}
@@ -19,29 +19,246 @@ package scaffolds
 import (
 	"fmt"
 	"io/ioutil"
+	"path/filepath"
 	"strings"
 
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/rename"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/api"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/hack"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/cmdutil"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/machinery"
 )
 
 var _ cmdutil.Scaffolder = &editScaffolder{}
 
+// RenameOptions rename the project, the domain of its API groups or the namespace it is installed in
+type RenameOptions struct {
+	// ProjectName is the new name of the project, if not empty.
+	ProjectName string
+	// Domain is the new domain of the API groups, if not empty.
+	Domain string
+	// Namespace is the new namespace the manager is installed in, if not empty.
+	Namespace string
+	// DryRun prints the diff of the renaming without modifying the files.
+	DryRun bool
+}
+
+// HeaderOptions apply the license header of hack/boilerplate.go.txt to the Go files of the project
+type HeaderOptions struct {
+	// Fix applies the license header to the Go files missing it or with a different one.
+	Fix bool
+	// Year and Owner replace the year and the owner of the copyright line of the license header, if not empty.
+	Year, Owner string
+}
+
+// ReplaceOptions change the replace directives of go.mod recorded in the PROJECT file
+type ReplaceOptions struct {
+	// Replace adds the replace directives, old[@v]=new[@v], replacing the ones of the same modules.
+	Replace []string
+	// Drop removes the replace directives of the modules, old[@v].
+	Drop []string
+}
+
 type editScaffolder struct {
-	config     *config.Config
-	multigroup bool
+	config            *config.Config
+	multigroup        bool
+	groupRegistration bool
+	rename            RenameOptions
+	headers           HeaderOptions
+	metricsExposure   MetricsExposure
+	agent             string
+	webhookDev        bool
+	replace           ReplaceOptions
 }
 
 // NewEditScaffolder returns a new Scaffolder for configuration edit operations
-func NewEditScaffolder(config *config.Config, multigroup bool) cmdutil.Scaffolder {
+func NewEditScaffolder(config *config.Config, multigroup, groupRegistration bool, rename RenameOptions,
+	headers HeaderOptions, metricsExposure MetricsExposure, agent string, webhookDev bool,
+	replace ReplaceOptions) cmdutil.Scaffolder {
 	return &editScaffolder{
-		config:     config,
-		multigroup: multigroup,
+		config:            config,
+		multigroup:        multigroup,
+		groupRegistration: groupRegistration,
+		rename:            rename,
+		headers:           headers,
+		metricsExposure:   metricsExposure,
+		agent:             agent,
+		webhookDev:        webhookDev,
+		replace:           replace,
 	}
 }
 
 // Scaffold implements Scaffolder
 func (s *editScaffolder) Scaffold() error {
+	if err := s.renameProject(); err != nil {
+		return err
+	}
+	if s.headers.Fix {
+		if err := s.fixHeaders(); err != nil {
+			return err
+		}
+	}
+	if s.rename.DryRun {
+		return nil
+	}
+
+	if s.metricsExposure.Kind != "" {
+		if err := machinery.NewScaffold().Execute(
+			model.NewUniverse(model.WithConfig(s.config)),
+			s.metricsExposure.files()...,
+		); err != nil {
+			return fmt.Errorf("error scaffolding the metrics exposure: %v", err)
+		}
+		if err := enableMetricsExposure(); err != nil {
+			return fmt.Errorf("error enabling the metrics exposure: %v", err)
+		}
+	}
+
+	if s.agent != "" {
+		if err := s.enableAgent(); err != nil {
+			return err
+		}
+	}
+
+	if s.webhookDev {
+		if err := s.scaffoldWebhookDev(); err != nil {
+			return err
+		}
+	}
+
+	if len(s.replace.Replace) != 0 || len(s.replace.Drop) != 0 {
+		if err := s.replaceModules(); err != nil {
+			return fmt.Errorf("error applying the replace directives to go.mod: %v", err)
+		}
+	}
+
+	if err := s.updateLayout(); err != nil {
+		return err
+	}
+
+	if s.groupRegistration {
+		return s.enableGroupRegistration()
+	}
+	return nil
+}
+
+// renameProject rewrites the files of the project referring to its name, to its domain or to its namespace,
+// printing their diff
+func (s *editScaffolder) renameProject() error {
+	var replacers []rename.Replacer
+	renamePath := func(path string) string { return path }
+	projectName, domain, namespace := s.config.ProjectName, s.config.Domain, s.config.GetNamespace()
+
+	// The namespace is renamed first, the names prefixed with the project name, including the namespace, are
+	// then renamed with the project
+	newNamespace := namespace
+	if s.rename.Namespace != "" {
+		newNamespace = s.rename.Namespace
+	}
+	if s.rename.ProjectName != "" && s.rename.ProjectName != projectName &&
+		strings.HasPrefix(newNamespace, projectName+"-") {
+		newNamespace = s.rename.ProjectName + strings.TrimPrefix(newNamespace, projectName)
+	}
+	if newNamespace != namespace {
+		replacers = append(replacers, rename.Namespace(namespace, newNamespace))
+		namespace = newNamespace
+	}
+	if s.rename.ProjectName != "" && s.rename.ProjectName != projectName {
+		replacers = append(replacers, rename.ProjectName(projectName, s.rename.ProjectName))
+		projectName = s.rename.ProjectName
+	}
+	if s.rename.Domain != "" && s.rename.Domain != domain {
+		replacers = append(replacers, rename.Domain(domain, s.rename.Domain, s.config.Repo))
+		renamePath = rename.DomainPath(domain, s.rename.Domain)
+		domain = s.rename.Domain
+	}
+	if len(replacers) == 0 {
+		return nil
+	}
+
+	changes, err := rename.Plan(".", rename.Chain(replacers...), renamePath)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		fmt.Print(rename.Diff(change))
+	}
+	if s.rename.DryRun {
+		fmt.Printf("Dry run: %d file(s) would be modified, along with the PROJECT file\n", len(changes))
+		return nil
+	}
+
+	if err := rename.Apply(".", changes); err != nil {
+		return err
+	}
+	if domain != s.config.Domain {
+		fmt.Println("The API groups of the resources changed: run \"make generate manifests\" to regenerate " +
+			"the manifests, and migrate the objects of the previous API groups of the clusters")
+	}
+	if namespace != s.config.GetNamespace() {
+		fmt.Printf("The namespace of the manager changed: undeploy it from %s before deploying it in %s, "+
+			"and move the objects of the previous namespace\n", s.config.GetNamespace(), namespace)
+	}
+	s.config.ProjectName = projectName
+	s.config.Domain = domain
+	// The default namespace is not recorded, so that it follows the name of the project
+	s.config.Namespace = namespace
+	if namespace == config.DefaultNamespace(projectName) {
+		s.config.Namespace = ""
+	}
+	return nil
+}
+
+// fixHeaders applies the license header of the boilerplate, after updating its copyright line, to the Go
+// files of the project, printing their paths
+func (s *editScaffolder) fixHeaders() error {
+	path := filepath.Join("hack", "boilerplate.go.txt")
+	bs, err := ioutil.ReadFile(path) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("unable to load boilerplate: %v", err)
+	}
+	boilerplate := string(bs)
+	if s.headers.Year != "" || s.headers.Owner != "" {
+		if boilerplate, err = rename.Copyright(boilerplate, s.headers.Year, s.headers.Owner); err != nil {
+			return fmt.Errorf("unable to update the copyright of %s: %v", path, err)
+		}
+	}
+	if strings.TrimSpace(boilerplate) == "" {
+		return fmt.Errorf("%s is empty", path)
+	}
+
+	changes, err := rename.Plan(".", rename.Header(boilerplate), func(path string) string { return path })
+	if err != nil {
+		return err
+	}
+	if boilerplate != string(bs) {
+		fmt.Printf("%s: copyright updated\n", filepath.ToSlash(path))
+	}
+	for _, change := range changes {
+		fmt.Printf("%s: license header fixed\n", change.Path)
+	}
+	if s.rename.DryRun {
+		fmt.Printf("Dry run: the license header of %d file(s) would be fixed\n", len(changes))
+		return nil
+	}
+
+	if boilerplate != string(bs) {
+		// false positive
+		// nolint:gosec
+		if err := journal.WriteFile(path, []byte(boilerplate), 0644); err != nil {
+			return err
+		}
+	}
+	return rename.Apply(".", changes)
+}
+
+// updateLayout switches the Dockerfile to the single or multi group layout
+func (s *editScaffolder) updateLayout() error {
 	filename := "Dockerfile"
 	bs, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -75,12 +292,136 @@ func (s *editScaffolder) Scaffold() error {
 	if str != "" {
 		// false positive
 		// nolint:gosec
-		return ioutil.WriteFile(filename, []byte(str), 0644)
+		return journal.WriteFile(filename, []byte(str), 0644)
+	}
+
+	return nil
+}
+
+// enableGroupRegistration scaffolds the registration package of the groups of the resources, and adds the groups
+// to the scheme in main.go
+func (s *editScaffolder) enableGroupRegistration() error {
+	s.config.GroupRegistration = true
+
+	bp, err := ioutil.ReadFile(filepath.Join("hack", "boilerplate.go.txt")) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("unable to load boilerplate: %v", err)
+	}
+
+	registered := false
+	for _, data := range s.config.Resources {
+		// Only the types of the project are registered, not the ones of the core groups
+		if data.API == nil || data.Group == "" {
+			continue
+		}
+		opts := resource.Options{Group: data.Group, GroupPackage: data.GroupPackage, Version: data.Version,
+			Kind: data.Kind}
+		res := opts.NewResource(s.config, true)
+		if err := machinery.NewScaffold().Execute(
+			model.NewUniverse(
+				model.WithConfig(s.config),
+				model.WithBoilerplate(string(bp)),
+				model.WithResource(res),
+			),
+			&api.GroupDoc{},
+			&api.GroupRegistration{},
+			&api.GroupRegistrationUpdater{},
+			&templates.MainUpdater{WireResource: true, GroupRegistration: true},
+		); err != nil {
+			return fmt.Errorf("error scaffolding the registration package of group %s: %v", data.Group, err)
+		}
+		registered = true
+	}
+
+	if registered {
+		fmt.Println("The groups are added to the scheme in main.go: the versions added to it one by one can be " +
+			"removed, along with their imports when they set up no webhook")
+	}
+	return nil
+}
+
+// enableAgent scaffolds the agent package and the kustomize configuration of config/agent, and adds the --mode
+// flag of the agent to main.go
+func (s *editScaffolder) enableAgent() error {
+	s.config.Agent = s.agent
+
+	bp, err := ioutil.ReadFile(filepath.Join("hack", "boilerplate.go.txt")) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("unable to load boilerplate: %v", err)
+	}
+	if err := machinery.NewScaffold().Execute(
+		model.NewUniverse(model.WithConfig(s.config), model.WithBoilerplate(string(bp))),
+		agentFiles(s.config, podSecurityOf())...,
+	); err != nil {
+		return fmt.Errorf("error scaffolding the agent: %v", err)
 	}
 
+	enabled, err := enableAgentMode(s.config.Repo)
+	if err != nil {
+		return fmt.Errorf("error adding the agent mode to main.go: %v", err)
+	}
+	if !enabled {
+		fmt.Println("main.go does not create the manager with ctrl.NewManager as scaffolded: add the --mode flag " +
+			"with the AddFlag method of agent.Mode, and pass the options of the manager through its Options method")
+	}
 	return nil
 }
 
+// scaffoldWebhookDev scaffolds the tool serving the webhooks of the manager run by the developer to a development
+// cluster
+func (s *editScaffolder) scaffoldWebhookDev() error {
+	bp, err := ioutil.ReadFile(filepath.Join("hack", "boilerplate.go.txt")) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("unable to load boilerplate: %v", err)
+	}
+	if err := machinery.NewScaffold().Execute(
+		model.NewUniverse(model.WithConfig(s.config), model.WithBoilerplate(string(bp))),
+		&hack.WebhookDev{},
+	); err != nil {
+		return fmt.Errorf("error scaffolding hack/webhook-dev: %v", err)
+	}
+	return nil
+}
+
+// replaceModules records the replace directives in the PROJECT file, dropping the ones of the dropped modules and
+// the ones replaced, and applies them to go.mod
+func (s *editScaffolder) replaceModules() error {
+	replaced := make(map[string]bool, len(s.replace.Replace))
+	for _, value := range s.replace.Replace {
+		r, err := ParseModuleReplace(value)
+		if err != nil {
+			return err
+		}
+		replaced[r.Module()] = true
+	}
+
+	var kept, removed []string
+	for _, value := range s.config.Replace {
+		r, err := ParseModuleReplace(value)
+		if err != nil {
+			return err
+		}
+		if replaced[r.Module()] || droppedBy(r, s.replace.Drop) {
+			removed = append(removed, value)
+		} else {
+			kept = append(kept, value)
+		}
+	}
+	s.config.Replace = append(kept, s.replace.Replace...)
+
+	return applyModuleReplaces(s.config.Replace, removed)
+}
+
+// droppedBy returns whether the directive r is removed by any of the modules of --drop-replace, drop
+func droppedBy(r ModuleReplace, drop []string) bool {
+	for _, module := range drop {
+		if r.DroppedBy(module) {
+			return true
+		}
+	}
+	return false
+}
+
 func ensureExistAndReplace(input, match, replace string) (string, error) {
 	if !strings.Contains(input, match) {
 		return "", fmt.Errorf("can't find %q", match)
@@ -23,8 +23,14 @@ import (
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/model"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/apiserver"
+	configapiserver "sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/apiserver"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/apiservice"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/certmanager"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/components"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/crd"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/kdefault"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/manager"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/prometheus"
@@ -41,26 +47,106 @@ const (
 	ControllerToolsVersion = "v0.4.1"
 	// KustomizeVersion is the kubernetes-sigs/kustomize version to be used in the project
 	KustomizeVersion = "v3.8.7"
+	// SyftVersion is the anchore/syft release downloaded by the project to generate SBOMs
+	SyftVersion = "v0.59.0"
+	// CosignVersion is the sigstore/cosign release downloaded by the project to sign images
+	CosignVersion = "v1.13.1"
+	// APIServerVersion is the kubernetes/apiserver version to be used in the aggregated API server projects
+	APIServerVersion = "v0.19.2"
+	// GnosticVersion is the googleapis/gnostic version pinned in the aggregated API server projects, the one
+	// k8s.io/apiserver is built with: controller-runtime requires a later one, whose API is incompatible with the
+	// k8s.io/kube-openapi version of k8s.io/apiserver
+	GnosticVersion = "v0.4.1"
+	// EnvtestK8sVersion is the Kubernetes version of the envtest binaries run by the tests of the projects
+	// that do not set a minimum Kubernetes version
+	EnvtestK8sVersion = "1.19.2"
+
+	// PatternAggregatedAPIServer scaffolds an aggregated API server serving the APIs of the project, whose
+	// types can not be CRDs, instead of an operator
+	PatternAggregatedAPIServer = "aggregated-apiserver"
+
+	// ImageSigningKeyless signs images with cosign using keyless (OIDC) signing
+	ImageSigningKeyless = templates.ImageSigningKeyless
+	// ImageSigningKey signs images with cosign using a private key
+	ImageSigningKey = templates.ImageSigningKey
+
+	// PodSecurityRestricted makes the manager comply with the restricted Pod Security Standards profile
+	PodSecurityRestricted = "restricted"
+	// PodSecurityBaseline makes the manager comply with the baseline Pod Security Standards profile,
+	// which allows it to run as root
+	PodSecurityBaseline = "baseline"
+
+	// CertProviderCertManager issues the serving certificates of the webhooks with cert-manager
+	CertProviderCertManager = "cert-manager"
+	// CertProviderVault retrieves the serving certificates of the webhooks from the PKI secrets engine of
+	// Vault with the Vault agent injector
+	CertProviderVault = "vault"
+
+	// DependencyUpdatesRenovate updates the dependencies of the project with Renovate
+	DependencyUpdatesRenovate = "renovate"
+	// DependencyUpdatesDependabot updates the dependencies of the project with Dependabot
+	DependencyUpdatesDependabot = "dependabot"
+
+	// vaultCertDir is the directory where the Vault agent injector writes the secrets
+	vaultCertDir = "/vault/secrets"
 
 	imageName = "controller:latest"
+	imageTag  = "latest"
 )
 
+// InitOptions are the options of the project scaffolded by init
+type InitOptions struct {
+	// License and Owner are the license and the copyright owner of the boilerplate header
+	License, Owner string
+
+	// ToolMirror is the base URL of a mirror serving the envtest binaries, if any
+	ToolMirror string
+
+	// SBOM generates the SBOM of the manager image with syft
+	SBOM bool
+	// ImageSigning is the cosign signing mode of the manager image, ImageSigningKeyless or ImageSigningKey,
+	// empty if the image is not signed
+	ImageSigning string
+
+	// ImageRepo is the repository of the manager image, and ImagePullSecret the Secret pulling it, if any
+	ImageRepo, ImagePullSecret string
+
+	// GoProxy, GoPrivate and GoNoSumDB are the GOPROXY, GOPRIVATE and GONOSUMDB of the builds of the project
+	GoProxy, GoPrivate, GoNoSumDB string
+
+	// PodSecurity is the Pod Security Standard the manager complies with, PodSecurityRestricted or
+	// PodSecurityBaseline
+	PodSecurity string
+
+	// MetricsExposure exposes the metrics endpoint outside of the cluster, if its kind is set
+	MetricsExposure MetricsExposure
+
+	// Overlays scaffolds the dev, staging and prod kustomize overlays
+	Overlays bool
+
+	// WebhookDev scaffolds hack/webhook-dev, which serves the webhooks of make run to a development cluster
+	WebhookDev bool
+
+	// DependencyUpdates is the bot updating the dependencies of the project, renovate or dependabot, if any
+	DependencyUpdates string
+}
+
 var _ cmdutil.Scaffolder = &initScaffolder{}
 
 type initScaffolder struct {
 	config          *config.Config
 	boilerplatePath string
-	license         string
-	owner           string
+
+	// options are the options of the scaffolded project
+	options InitOptions
 }
 
 // NewInitScaffolder returns a new Scaffolder for project initialization operations
-func NewInitScaffolder(config *config.Config, license, owner string) cmdutil.Scaffolder {
+func NewInitScaffolder(config *config.Config, options InitOptions) cmdutil.Scaffolder {
 	return &initScaffolder{
 		config:          config,
 		boilerplatePath: filepath.Join("hack", "boilerplate.go.txt"),
-		license:         license,
-		owner:           owner,
+		options:         options,
 	}
 }
 
@@ -79,24 +165,176 @@ func (s *initScaffolder) Scaffold() error {
 
 // TODO: re-use universe created by s.newUniverse() if possible.
 func (s *initScaffolder) scaffold() error {
+	if s.config.ManifestsOnly {
+		return s.scaffoldManifests()
+	}
+	if s.config.Pattern == PatternAggregatedAPIServer {
+		return s.scaffoldAggregatedAPIServer()
+	}
+
+	boilerplate, err := s.scaffoldBoilerplate()
+	if err != nil {
+		return err
+	}
+	replace, err := goModReplaces(s.config.Replace)
+	if err != nil {
+		return err
+	}
+
+	files := append(s.configFiles(),
+		&templates.Main{
+			WebhookCertDir: s.webhookCertDir(),
+			WebhookDev:     s.options.WebhookDev,
+			Sharding:       s.config.Sharding,
+			Agent:          s.config.Agent != "",
+		},
+		&templates.Checkpoint{},
+		&templates.GoMod{ControllerRuntimeVersion: ControllerRuntimeVersion, Replace: replace},
+		&templates.GitIgnore{},
+		taskRunnerFile(s.config, templates.Makefile{
+			Image:                  s.image(),
+			BoilerplatePath:        s.boilerplatePath,
+			ControllerToolsVersion: ControllerToolsVersion,
+			KustomizeVersion:       KustomizeVersion,
+			ToolMirror:             s.options.ToolMirror,
+			EnvtestK8sVersion:      KubernetesProfileFor(s.config).EnvtestK8sVersion,
+			SBOM:                   s.options.SBOM,
+			SyftVersion:            SyftVersion,
+			ImageSigning:           s.options.ImageSigning,
+			CosignVersion:          CosignVersion,
+			GoEnv:                  s.hasGoEnv(),
+			Overlays:               s.overlayEnvironments(),
+		}),
+		&templates.Dockerfile{
+			SupplyChain: s.options.SBOM || s.options.ImageSigning != "",
+			RunAsRoot:   s.options.PodSecurity == PodSecurityBaseline,
+		},
+		&hack.CRDCompat{},
+		&templates.CRDCompatWorkflow{TaskRunner: TaskRunnerFor(s.config)},
+		&hack.CRDLint{},
+		&hack.ManifestsHash{},
+		&hack.APIDocs{},
+		&hack.UpgradeTest{},
+		&hack.RBACAudit{},
+		&templates.DockerIgnore{},
+	)
+
+	if s.config.FeatureGates {
+		files = append(files, &templates.FeatureGate{})
+	}
+	if s.config.MultiCluster {
+		files = append(files, &templates.Clusters{})
+	}
+	if s.config.Sharding {
+		files = append(files, &templates.Sharding{}, &templates.ShardingTest{})
+	}
+	if s.config.Agent != "" {
+		files = append(files, agentFiles(s.config, s.options.PodSecurity)...)
+	}
+	if s.options.WebhookDev {
+		files = append(files, &hack.WebhookDev{})
+	}
+	files = append(files, s.dependencyUpdatesFiles()...)
+
+	return machinery.NewScaffold().Execute(s.newUniverse(string(boilerplate)), files...)
+}
+
+// scaffoldBoilerplate scaffolds the boilerplate file and returns its content
+func (s *initScaffolder) scaffoldBoilerplate() ([]byte, error) {
 	bpFile := &hack.Boilerplate{}
 	bpFile.Path = s.boilerplatePath
-	bpFile.License = s.license
-	bpFile.Owner = s.owner
+	bpFile.License = s.options.License
+	bpFile.Owner = s.options.Owner
 	if err := machinery.NewScaffold().Execute(
 		s.newUniverse(""),
 		bpFile,
 	); err != nil {
-		return err
+		return nil, err
 	}
 
-	boilerplate, err := ioutil.ReadFile(s.boilerplatePath) //nolint:gosec
+	return ioutil.ReadFile(s.boilerplatePath) //nolint:gosec
+}
+
+// scaffoldAggregatedAPIServer scaffolds an aggregated API server, whose resources are served by the apiserver
+// package instead of CRDs, with the kustomize tree and the targets of the operators
+func (s *initScaffolder) scaffoldAggregatedAPIServer() error {
+	boilerplate, err := s.scaffoldBoilerplate()
 	if err != nil {
 		return err
 	}
+	replace, err := goModReplaces(s.config.Replace)
+	if err != nil {
+		return err
+	}
+	if !replacesModule(s.config.Replace, gnosticModule) {
+		gnostic := ModuleReplace{OldPath: gnosticModule, NewPath: gnosticModule, NewVersion: GnosticVersion}
+		replace = append([]string{gnostic.GoModLine()}, replace...)
+	}
 
-	return machinery.NewScaffold().Execute(
-		s.newUniverse(string(boilerplate)),
+	files := append(s.configFiles(),
+		&apiserver.APIServer{},
+		&templates.APIServerMain{},
+		&templates.Storage{},
+		&configapiserver.Kustomization{},
+		&configapiserver.Service{},
+		&configapiserver.AuthDelegator{},
+		&apiservice.Kustomization{},
+		&apiservice.AuthReader{},
+		&templates.GoMod{
+			ControllerRuntimeVersion: ControllerRuntimeVersion,
+			APIServerVersion:         APIServerVersion,
+			Replace:                  replace,
+		},
+		&templates.GitIgnore{},
+		taskRunnerFile(s.config, templates.Makefile{
+			Image:                  s.image(),
+			BoilerplatePath:        s.boilerplatePath,
+			ControllerToolsVersion: ControllerToolsVersion,
+			KustomizeVersion:       KustomizeVersion,
+			ToolMirror:             s.options.ToolMirror,
+			EnvtestK8sVersion:      KubernetesProfileFor(s.config).EnvtestK8sVersion,
+			SBOM:                   s.options.SBOM,
+			SyftVersion:            SyftVersion,
+			ImageSigning:           s.options.ImageSigning,
+			CosignVersion:          CosignVersion,
+			GoEnv:                  s.hasGoEnv(),
+			AggregatedAPIServer:    true,
+		}),
+		&templates.Dockerfile{
+			SupplyChain:         s.options.SBOM || s.options.ImageSigning != "",
+			RunAsRoot:           s.options.PodSecurity == PodSecurityBaseline,
+			AggregatedAPIServer: true,
+		},
+		&hack.ManifestsHash{},
+		&templates.DockerIgnore{},
+	)
+	files = append(files, s.dependencyUpdatesFiles()...)
+
+	return machinery.NewScaffold().Execute(s.newUniverse(string(boilerplate)), files...)
+}
+
+// scaffoldManifests scaffolds a project that only holds the manifests of an operator whose Go code,
+// CRDs and RBAC rules are generated in another repository
+func (s *initScaffolder) scaffoldManifests() error {
+	files := append(s.configFiles(),
+		&rbac.Role{},
+		&crd.ManifestsKustomization{},
+		&templates.ManifestsMakefile{
+			Image:            s.image(),
+			KustomizeVersion: KustomizeVersion,
+			GoEnv:            s.hasGoEnv(),
+		},
+	)
+
+	return machinery.NewScaffold().Execute(s.newUniverse(""), files...)
+}
+
+// configFiles returns the files scaffolded for every project: the kustomize tree in config/ and
+// the optional files requested by the init flags
+func (s *initScaffolder) configFiles() []file.Builder {
+	aggregatedAPIServer := s.config.Pattern == PatternAggregatedAPIServer
+	profile := KubernetesProfileFor(s.config)
+	files := []file.Builder{
 		&rbac.Kustomization{},
 		&rbac.AuthProxyRole{},
 		&rbac.AuthProxyRoleBinding{},
@@ -105,28 +343,124 @@ func (s *initScaffolder) scaffold() error {
 		&rbac.RoleBinding{},
 		&rbac.LeaderElectionRole{},
 		&rbac.LeaderElectionRoleBinding{},
-		&manager.Kustomization{},
-		&manager.Config{Image: imageName},
-		&manager.ControllerManagerConfig{},
-		&templates.Main{},
-		&templates.GoMod{ControllerRuntimeVersion: ControllerRuntimeVersion},
-		&templates.GitIgnore{},
-		&templates.Makefile{
-			Image:                    imageName,
-			BoilerplatePath:          s.boilerplatePath,
-			ControllerToolsVersion:   ControllerToolsVersion,
-			KustomizeVersion:         KustomizeVersion,
-			ControllerRuntimeVersion: ControllerRuntimeVersion,
+		&manager.Kustomization{ImageRepo: s.options.ImageRepo, ImageTag: imageTag},
+		&manager.Config{
+			Image:               imageName,
+			PodSecurity:         s.options.PodSecurity,
+			SeccompAnnotation:   profile.SeccompAnnotation,
+			AggregatedAPIServer: aggregatedAPIServer,
 		},
-		&templates.Dockerfile{},
-		&templates.DockerIgnore{},
-		&kdefault.Kustomization{},
-		&kdefault.ManagerAuthProxyPatch{},
-		&kdefault.ManagerConfigPatch{},
+		&kdefault.Kustomization{
+			ImagePullSecret:     s.options.ImagePullSecret != "",
+			Vault:               s.config.CertProvider == CertProviderVault,
+			AggregatedAPIServer: aggregatedAPIServer,
+			PodSecurityPolicy:   profile.PodSecurityPolicy,
+			MetricsExposure:     s.options.MetricsExposure.Kind != "",
+		},
+		&components.PrometheusKustomization{},
 		&prometheus.Kustomization{},
 		&prometheus.Monitor{},
-		&certmanager.Certificate{},
-		&certmanager.Kustomization{},
-		&certmanager.KustomizeConfig{},
-	)
+	}
+
+	// The aggregated API servers serve their /metrics endpoint behind auth themselves and keep their objects
+	// in memory, which prevents running several replicas
+	if !aggregatedAPIServer {
+		files = append(files,
+			&manager.ControllerManagerConfig{WebhookCertDir: s.webhookCertDir()},
+			&kdefault.ManagerAuthProxyPatch{PodSecurity: s.options.PodSecurity},
+			&kdefault.ManagerConfigPatch{},
+			&components.HAKustomization{},
+			&components.HAManagerPatch{},
+			&components.HAPodDisruptionBudget{APIVersion: profile.PDBVersion},
+		)
+	}
+
+	if s.config.CertProvider != CertProviderVault && !aggregatedAPIServer {
+		files = append(files,
+			&certmanager.Certificate{APIVersion: profile.CertManagerVersion},
+			&certmanager.Kustomization{},
+			&certmanager.KustomizeConfig{},
+		)
+	}
+
+	if profile.PodSecurityPolicy {
+		files = append(files,
+			&components.PSPKustomization{},
+			&components.PSPKustomizeConfig{},
+			&components.PSPPolicy{PodSecurity: s.options.PodSecurity},
+			&components.PSPRole{},
+		)
+	}
+
+	if s.options.MetricsExposure.Kind != "" {
+		files = append(files, s.options.MetricsExposure.files()...)
+	}
+
+	if s.options.Overlays {
+		files = append(files, overlayFiles(s.imageRepoOrDefault())...)
+	}
+
+	if s.hasGoEnv() {
+		files = append(files, &templates.GoEnv{
+			GoProxy:   s.options.GoProxy,
+			GoPrivate: s.options.GoPrivate,
+			GoNoSumDB: s.options.GoNoSumDB,
+		})
+	}
+
+	if s.options.ImagePullSecret != "" {
+		files = append(files, &kdefault.ManagerImagePullSecretPatch{ImagePullSecret: s.options.ImagePullSecret})
+	}
+
+	return files
+}
+
+// dependencyUpdatesFiles returns the configuration of the bot updating the dependencies of the project, if any
+func (s *initScaffolder) dependencyUpdatesFiles() []file.Builder {
+	switch s.options.DependencyUpdates {
+	case DependencyUpdatesRenovate:
+		return []file.Builder{&templates.Renovate{}}
+	case DependencyUpdatesDependabot:
+		return []file.Builder{&templates.Dependabot{}}
+	}
+	return nil
+}
+
+// image returns the default image of the manager used by the Makefile
+func (s *initScaffolder) image() string {
+	return s.imageRepoOrDefault() + ":" + imageTag
+}
+
+// imageRepoOrDefault returns the repository of the manager image of config/manager
+func (s *initScaffolder) imageRepoOrDefault() string {
+	if s.options.ImageRepo != "" {
+		return s.options.ImageRepo
+	}
+	return "controller"
+}
+
+// webhookCertDir returns the directory of the serving certificates of the webhooks, or an empty string
+// for the default directory of controller-runtime
+func (s *initScaffolder) webhookCertDir() string {
+	if s.config.CertProvider == CertProviderVault {
+		return vaultCertDir
+	}
+	return ""
+}
+
+// overlayEnvironments returns the environments with a kustomize overlay, if the overlays are scaffolded
+func (s *initScaffolder) overlayEnvironments() []string {
+	if !s.options.Overlays {
+		return nil
+	}
+	names := make([]string, 0, len(environments))
+	for _, env := range environments {
+		names = append(names, env.name)
+	}
+	return names
+}
+
+// hasGoEnv returns true if a Go module configuration was provided for the project
+func (s *initScaffolder) hasGoEnv() bool {
+	return s.options.GoProxy != "" || s.options.GoPrivate != "" || s.options.GoNoSumDB != ""
 }
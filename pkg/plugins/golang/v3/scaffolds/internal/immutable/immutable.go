@@ -0,0 +1,220 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package immutable finds the fields of the spec of a resource in its types file, and marks them immutable
+// with CEL validation rules. It also finds the unions of the spec, validated by the validating webhook.
+package immutable
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+)
+
+// Field is a field of the spec of a resource.
+type Field struct {
+	// Name is the name of the Go field, and JSONName the name of the serialized one.
+	Name, JSONName string
+	// typeName is the name of the type of the field, or of the type it points to, declared in the types file.
+	typeName string
+	// line is the line of the field in the types file.
+	line int
+}
+
+// SpecFields returns the fields of the spec of kind, the <kind>Spec struct declared in the types file of path.
+// The fields that are not serialized or are inlined are skipped.
+func SpecFields(path, kind string) ([]Field, error) {
+	fields, _, err := specFields(path, kind)
+	return fields, err
+}
+
+// Unions returns the fields of the spec of kind whose type, or the type they point to, is a union declared in
+// the types file of path: a struct marked with +union that has a Validate method, which the validating webhook
+// calls on the clusters that do not enforce the CEL validation rules of the union.
+func Unions(path, kind string) ([]Field, error) {
+	fields, f, err := specFields(path, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	unionTypes := map[string]bool{}
+	validated := map[string]bool{}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				typeSpec, isTypeSpec := spec.(*ast.TypeSpec)
+				if !isTypeSpec {
+					continue
+				}
+				doc := typeSpec.Doc
+				if doc == nil && len(decl.Specs) == 1 {
+					doc = decl.Doc
+				}
+				if _, isStruct := typeSpec.Type.(*ast.StructType); isStruct && hasMarker(doc, "+union") {
+					unionTypes[typeSpec.Name.Name] = true
+				}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv != nil && len(decl.Recv.List) == 1 && decl.Name.Name == "Validate" {
+				validated[typeName(decl.Recv.List[0].Type)] = true
+			}
+		}
+	}
+
+	var unions []Field
+	for _, field := range fields {
+		if name := field.typeName; unionTypes[name] && validated[name] {
+			unions = append(unions, field)
+		}
+	}
+	return unions, nil
+}
+
+// specFields returns the fields of the spec of kind and the parsed types file of path.
+func specFields(path, kind string) ([]Field, *ast.File, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	specName := kind + "Spec"
+	var spec *ast.StructType
+	ast.Inspect(f, func(n ast.Node) bool {
+		if typeSpec, isTypeSpec := n.(*ast.TypeSpec); isTypeSpec && typeSpec.Name.Name == specName {
+			spec, _ = typeSpec.Type.(*ast.StructType)
+		}
+		return spec == nil
+	})
+	if spec == nil {
+		return nil, nil, fmt.Errorf("unable to find the %s struct in %s", specName, path)
+	}
+
+	var fields []Field
+	for _, field := range spec.Fields.List {
+		jsonName := ""
+		if field.Tag != nil {
+			jsonName = strings.Split(reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("json"), ",")[0]
+		}
+		for _, name := range field.Names {
+			if jsonName == "-" || jsonName == "" || !name.IsExported() {
+				continue
+			}
+			fields = append(fields, Field{Name: name.Name, JSONName: jsonName, typeName: typeName(field.Type),
+				line: fset.Position(field.Pos()).Line})
+		}
+	}
+	return fields, f, nil
+}
+
+// typeName returns the name of the type declared in the same file that expr is or points to, if any.
+func typeName(expr ast.Expr) string {
+	if star, isStar := expr.(*ast.StarExpr); isStar {
+		expr = star.X
+	}
+	if ident, isIdent := expr.(*ast.Ident); isIdent {
+		return ident.Name
+	}
+	return ""
+}
+
+// hasMarker returns true if doc holds marker, written with or without a space after the slashes.
+func hasMarker(doc *ast.CommentGroup, marker string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		if text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//")); text == marker {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the fields named names, by their Go or JSON name, in the order of names.
+func Select(fields []Field, names []string) ([]Field, error) {
+	selected := make([]Field, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, field := range fields {
+			if field.Name == name || field.JSONName == name {
+				selected = append(selected, field)
+				found = true
+				break
+			}
+		}
+		if !found {
+			available := make([]string, 0, len(fields))
+			for _, field := range fields {
+				available = append(available, field.JSONName)
+			}
+			return nil, fmt.Errorf("unknown spec field %q, the fields are: %s", name, strings.Join(available, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// Rule returns the CEL validation rule marker making field immutable.
+func Rule(field Field) string {
+	return fmt.Sprintf(`//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="%s is immutable"`,
+		field.JSONName)
+}
+
+// AddRules adds the CEL validation rule making each of fields immutable above it in the types file of path,
+// unless the field already has it.
+func AddRules(path string, fields []Field) error {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(content), "\n")
+
+	rules := make(map[int]string, len(fields))
+	for _, field := range fields {
+		rules[field.line] = Rule(field)
+	}
+	updated := make([]string, 0, len(lines)+len(fields))
+	for i, line := range lines {
+		if rule, found := rules[i+1]; found && !hasRule(lines[:i], rule) {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			updated = append(updated, indent+rule)
+		}
+		updated = append(updated, line)
+	}
+
+	return journal.WriteFile(path, []byte(strings.Join(updated, "\n")), 0644) //nolint:gosec
+}
+
+// hasRule returns true if the doc comment ending lines holds rule.
+func hasRule(lines []string, rule string) bool {
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "//") {
+			return false
+		}
+		if strings.Replace(line, "// +", "//+", 1) == rule {
+			return true
+		}
+	}
+	return false
+}
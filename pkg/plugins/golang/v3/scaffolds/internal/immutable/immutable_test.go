@@ -0,0 +1,182 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package immutable
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const types = `package v1
+
+type FrigateSpec struct {
+	// Foo is an example field of Frigate.
+	Foo string ` + "`" + `json:"foo,omitempty"` + "`" + `
+
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="class is immutable"
+	Class string ` + "`" + `json:"class"` + "`" + `
+
+	Internal   string ` + "`" + `json:"-"` + "`" + `
+	Min, Max   int    ` + "`" + `json:"bounds"` + "`" + `
+	unexported string
+
+	Source  *FrigateSource ` + "`" + `json:"source,omitempty"` + "`" + `
+	Target  FrigateTarget  ` + "`" + `json:"target"` + "`" + `
+	Channel FrigateChannel ` + "`" + `json:"channel"` + "`" + `
+}
+
+// FrigateSource is a union.
+// +union
+type FrigateSource struct {
+	Git *string ` + "`" + `json:"git,omitempty"` + "`" + `
+}
+
+func (s *FrigateSource) Validate() {}
+
+//+union
+type FrigateTarget struct {
+	Git *string ` + "`" + `json:"git,omitempty"` + "`" + `
+}
+
+func (t FrigateTarget) Validate() {}
+
+// FrigateChannel is a union without Validate method.
+//+union
+type FrigateChannel struct {
+	Git *string ` + "`" + `json:"git,omitempty"` + "`" + `
+}
+
+type FrigateStatus struct {
+	Ready bool ` + "`" + `json:"ready"` + "`" + `
+}
+`
+
+func writeTypes(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "immutable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	path := filepath.Join(dir, "frigate_types.go")
+	if err := ioutil.WriteFile(path, []byte(types), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func names(fields []Field) []string {
+	result := make([]string, 0, len(fields))
+	for _, field := range fields {
+		result = append(result, field.Name+"/"+field.JSONName)
+	}
+	return result
+}
+
+func TestSpecFields(t *testing.T) {
+	path := writeTypes(t)
+
+	fields, err := SpecFields(path, "Frigate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"Foo/foo", "Class/class", "Min/bounds", "Max/bounds", "Source/source", "Target/target",
+		"Channel/channel"}
+	if !reflect.DeepEqual(names(fields), expected) {
+		t.Errorf("expected the fields %v, got %v", expected, names(fields))
+	}
+
+	if _, err := SpecFields(path, "Captain"); err == nil {
+		t.Error("expected an error for a kind without spec")
+	}
+}
+
+func TestSelect(t *testing.T) {
+	fields, err := SpecFields(writeTypes(t), "Frigate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	selected, err := Select(fields, []string{"class", "Foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"Class/class", "Foo/foo"}; !reflect.DeepEqual(names(selected), expected) {
+		t.Errorf("expected the fields %v, got %v", expected, names(selected))
+	}
+
+	_, err = Select(fields, []string{"ready"})
+	if err == nil || !strings.Contains(err.Error(), "foo, class") {
+		t.Errorf("expected an error listing the fields, got %v", err)
+	}
+}
+
+func TestAddRules(t *testing.T) {
+	path := writeTypes(t)
+	fields, err := SpecFields(path, "Frigate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected, err := Select(fields, []string{"foo", "class"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddRules(path, selected); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := strings.Replace(types, "\tFoo string", "\t"+Rule(selected[0])+"\n\tFoo string", 1)
+	if string(content) != expected {
+		t.Errorf("expected the rule of foo only to be added, got:\n%s", content)
+	}
+
+	// The rules are not added twice
+	fields, err = SpecFields(path, "Frigate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected, err = Select(fields, []string{"foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := AddRules(path, selected); err != nil {
+		t.Fatal(err)
+	}
+	if content, err = ioutil.ReadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Errorf("expected the rule of foo not to be added twice, got:\n%s", content)
+	}
+}
+
+func TestUnions(t *testing.T) {
+	unions, err := Unions(writeTypes(t), "Frigate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"Source/source", "Target/target"}; !reflect.DeepEqual(names(unions), expected) {
+		t.Errorf("expected the unions %v, got %v", expected, names(unions))
+	}
+}
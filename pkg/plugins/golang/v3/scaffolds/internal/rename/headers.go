@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rename
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Header returns the replacer applying the license header boilerplate to the Go files: the leading comment
+// mentioning a copyright, after the build constraints, is replaced with boilerplate, and the files without
+// one are prefixed with it.
+func Header(boilerplate string) Replacer {
+	boilerplate = strings.TrimSpace(boilerplate)
+	return func(path, content string) string {
+		if !isGo(path) {
+			return content
+		}
+
+		// The build constraints precede the header
+		start := 0
+		for start < len(content) {
+			end := strings.IndexByte(content[start:], '\n')
+			if end == -1 {
+				end = len(content) - start
+			}
+			line := strings.TrimSpace(content[start : start+end])
+			if line != "" && !strings.HasPrefix(line, "//go:build") && !strings.HasPrefix(line, "// +build") {
+				break
+			}
+			start += end + 1
+		}
+		if start > len(content) {
+			start = len(content)
+		}
+		rest := content[start:]
+		if strings.HasPrefix(rest, boilerplate) {
+			return content
+		}
+
+		if header := leadingComment(rest); strings.Contains(header, "Copyright") {
+			return content[:start] + boilerplate + rest[len(header):]
+		}
+		return content[:start] + boilerplate + "\n\n" + rest
+	}
+}
+
+// leadingComment returns the comment content starts with: a block comment, or the line comments up to the
+// first line that is not one.
+func leadingComment(content string) string {
+	if strings.HasPrefix(content, "/*") {
+		if end := strings.Index(content, "*/"); end != -1 {
+			return content[:end+2]
+		}
+		return ""
+	}
+
+	end := 0
+	for strings.HasPrefix(content[end:], "//") {
+		next := strings.IndexByte(content[end:], '\n')
+		if next == -1 {
+			return content
+		}
+		end += next + 1
+	}
+	return strings.TrimSuffix(content[:end], "\n")
+}
+
+// Copyright returns boilerplate with the year and, if not empty, the owner of its copyright line replaced
+// with year and owner, the empty ones being kept.
+func Copyright(boilerplate, year, owner string) (string, error) {
+	lines := strings.Split(boilerplate, "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "Copyright ") {
+			continue
+		}
+		fields := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(line, "Copyright "), "."), " ", 2)
+		if year == "" {
+			year = fields[0]
+		}
+		if owner == "" && len(fields) == 2 {
+			owner = fields[1]
+		}
+		lines[i] = "Copyright " + year
+		if owner != "" {
+			lines[i] += " " + owner
+		}
+		lines[i] += "."
+		return strings.Join(lines, "\n"), nil
+	}
+	return "", fmt.Errorf("no line starting with \"Copyright \" found")
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rename renames the project or its domain in the files of a project, or refreshes their license
+// headers.
+package rename
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+)
+
+// Replacer returns the content of the file of path, relative to the project root, after the renaming.
+type Replacer func(path, content string) string
+
+// Change is the renaming of a file.
+type Change struct {
+	// Path is the path of the file, relative to the project root, and NewPath its path after the renaming.
+	Path, NewPath string
+	// Old is the content of the file, and New its content after the renaming.
+	Old, New string
+}
+
+// Plan returns the changes of the files of root made by replace and, for their paths, by rename. The
+// hidden directories and the directories holding binaries and dependencies are skipped.
+func Plan(root string, replace Replacer, rename func(path string) string) ([]Change, error) {
+	var changes []Change
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if rel != "." && (strings.HasPrefix(name, ".") || name == "bin" || name == "testbin" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		content, err := ioutil.ReadFile(path) //nolint:gosec
+		if err != nil {
+			return err
+		}
+		// Binary files are not renamed
+		if bytes.IndexByte(content, 0) != -1 {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		change := Change{Path: rel, NewPath: rename(rel), Old: string(content)}
+		change.New = replace(rel, change.Old)
+		if change.New != change.Old || change.NewPath != change.Path {
+			changes = append(changes, change)
+		}
+		return nil
+	})
+	return changes, err
+}
+
+// Apply writes the changes in the files of root.
+func Apply(root string, changes []Change) error {
+	for _, change := range changes {
+		path := filepath.Join(root, filepath.FromSlash(change.Path))
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		newPath := filepath.Join(root, filepath.FromSlash(change.NewPath))
+		if err := journal.WriteFile(newPath, []byte(change.New), info.Mode()); err != nil {
+			return err
+		}
+		if newPath != path {
+			if err := journal.Remove(path); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Diff returns the diff of change, in the unified format with one line of context. The renaming
+// replaces words within lines, so that the lines of the old and new content match one to one.
+func Diff(change Change) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", change.Path, change.NewPath)
+
+	oldLines := strings.Split(strings.TrimSuffix(change.Old, "\n"), "\n")
+	newLines := strings.Split(strings.TrimSuffix(change.New, "\n"), "\n")
+	if len(oldLines) != len(newLines) {
+		fmt.Fprintf(&b, "@@ -1,%d +1,%d @@\n", len(oldLines), len(newLines))
+		for _, line := range oldLines {
+			fmt.Fprintf(&b, "-%s\n", line)
+		}
+		for _, line := range newLines {
+			fmt.Fprintf(&b, "+%s\n", line)
+		}
+		return b.String()
+	}
+
+	for i := 0; i < len(oldLines); {
+		if oldLines[i] == newLines[i] {
+			i++
+			continue
+		}
+		// The hunk spans the changed lines separated by less than two unchanged lines, and their context
+		last := i
+		for j := i + 1; j < len(oldLines) && j-last <= 2; j++ {
+			if oldLines[j] != newLines[j] {
+				last = j
+			}
+		}
+		start, stop := i, last+1
+		if start > 0 {
+			start--
+		}
+		if stop < len(oldLines) {
+			stop++
+		}
+		fmt.Fprintf(&b, "@@ -%[1]d,%[2]d +%[1]d,%[2]d @@\n", start+1, stop-start)
+		for j := start; j < stop; {
+			if oldLines[j] == newLines[j] {
+				fmt.Fprintf(&b, " %s\n", oldLines[j])
+				j++
+				continue
+			}
+			k := j
+			for k < stop && oldLines[k] != newLines[k] {
+				k++
+			}
+			for _, line := range oldLines[j:k] {
+				fmt.Fprintf(&b, "-%s\n", line)
+			}
+			for _, line := range newLines[j:k] {
+				fmt.Fprintf(&b, "+%s\n", line)
+			}
+			j = k
+		}
+		i = stop
+	}
+	return b.String()
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rename
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDomain(t *testing.T) {
+	replace := Domain("my.domain", "example.org", "my.domain/ship")
+	for _, tc := range []struct {
+		path, content, expected string
+	}{
+		{"api/v1/groupversion_info.go", `Group: "crew.my.domain"`, `Group: "crew.example.org"`},
+		{"main.go", `import "my.domain/ship/api/v1"`, `import "my.domain/ship/api/v1"`},
+		{"main.go", `LeaderElectionID: "1a2b.my.domain"`, `LeaderElectionID: "1a2b.example.org"`},
+		{"main.go", `host: crew.my.domains`, `host: crew.my.domains`},
+		{"main.go", `host: crew.amy.domain`, `host: crew.amy.domain`},
+		{"config/rbac/role.yaml", "- crew.my.domain\n", "- crew.example.org\n"},
+		{"api/v1/captain_webhook.go", "path=/mutate-crew-my-domain-v1-captain",
+			"path=/mutate-crew-example-org-v1-captain"},
+		{"README.md", "crew.my.domain", "crew.my.domain"},
+	} {
+		if actual := replace(tc.path, tc.content); actual != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.path, tc.expected, actual)
+		}
+	}
+
+	rename := DomainPath("my.domain", "example.org")
+	for path, expected := range map[string]string{
+		"config/crd/bases/crew.my.domain_captains.yaml": "config/crd/bases/crew.example.org_captains.yaml",
+		"config/samples/crew.my.domain_captains.yaml":   "config/samples/crew.my.domain_captains.yaml",
+	} {
+		if actual := rename(path); actual != expected {
+			t.Errorf("expected %s, got %s", expected, actual)
+		}
+	}
+}
+
+func TestProjectName(t *testing.T) {
+	replace := ProjectName("ship", "fleet")
+	for _, tc := range []struct {
+		path, content, expected string
+	}{
+		{"config/default/kustomization.yaml", "namespace: ship-system\nnamePrefix: ship-\n",
+			"namespace: fleet-system\nnamePrefix: fleet-\n"},
+		{"config/rbac/role.yaml", "name: spaceship-role", "name: spaceship-role"},
+		{"Makefile", "IMG ?= example.org/ship:latest\nship-test:", "IMG ?= example.org/fleet:latest\nship-test:"},
+		{"Taskfile.yaml", "vars:\n  IMG: ship:latest\n", "vars:\n  IMG: fleet:latest\n"},
+		{"Justfile", `IMG := env_var_or_default("IMG", "ship:latest")`, `IMG := env_var_or_default("IMG", "fleet:latest")`},
+		{"internal/ownerlabels/ownerlabels.go", `ManagedBy = "ship"`, `ManagedBy = "fleet"`},
+		{"main.go", `"ship-"`, `"ship-"`},
+	} {
+		if actual := replace(tc.path, tc.content); actual != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.path, tc.expected, actual)
+		}
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	replace := Namespace("ship-system", "fleet")
+	for _, tc := range []struct {
+		path, content, expected string
+	}{
+		{"config/default/kustomization.yaml", "namespace: ship-system\nnamePrefix: ship-\n",
+			"namespace: fleet\nnamePrefix: ship-\n"},
+		{"config/apiservice/apiservice.yaml", "  service:\n    namespace: ship-system\n",
+			"  service:\n    namespace: fleet\n"},
+		{"config/components/vault/manager_vault_patch.yaml", `"common_name=ship-webhook-service.ship-system.svc"`,
+			`"common_name=ship-webhook-service.fleet.svc"`},
+		{"config/rbac/role.yaml", "name: ship-system-role\nnamespace: ship-system-2",
+			"name: ship-system-role\nnamespace: ship-system-2"},
+		{"config/webhook/patches/mutating_in_frigates.yaml",
+			"      values:\n      - \"kube-system\"\n      - \"ship-system\"\n",
+			"      values:\n      - \"kube-system\"\n      - \"fleet\"\n"},
+		{"internal/defaults/defaults.go", `defaultNamespace = "ship-system"`, `defaultNamespace = "fleet"`},
+		{"main.go", `"ship-system"`, `"ship-system"`},
+	} {
+		if actual := replace(tc.path, tc.content); actual != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.path, tc.expected, actual)
+		}
+	}
+}
+
+func TestPlanApply(t *testing.T) {
+	root, err := ioutil.TempDir("", "rename")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		"config/crd/bases/crew.my.domain_captains.yaml": "group: crew.my.domain\n",
+		"bin/manager.yaml": "group: crew.my.domain\n",
+		"main.go":          "package main\n",
+	}
+	for path, content := range files {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes, err := Plan(root, Domain("my.domain", "example.org", ""), DomainPath("my.domain", "example.org"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %v", changes)
+	}
+	expected := `--- a/config/crd/bases/crew.my.domain_captains.yaml
++++ b/config/crd/bases/crew.example.org_captains.yaml
+@@ -1,1 +1,1 @@
+-group: crew.my.domain
++group: crew.example.org
+`
+	if diff := Diff(changes[0]); diff != expected {
+		t.Errorf("expected the diff:\n%s\ngot:\n%s", expected, diff)
+	}
+
+	if err := Apply(root, changes); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "config/crd/bases/crew.my.domain_captains.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected the old CRD manifest to be removed, got %v", err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(root, "config/crd/bases/crew.example.org_captains.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "group: crew.example.org\n" {
+		t.Errorf("unexpected content of the renamed CRD manifest: %s", content)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	change := Change{
+		Path:    "a.yaml",
+		NewPath: "a.yaml",
+		Old:     "1\n2\nx\n4\n5\n6\nx\n8\n9\n10\n11\nx",
+		New:     "1\n2\ny\n4\n5\n6\ny\n8\n9\n10\n11\ny",
+	}
+	expected := `--- a/a.yaml
++++ b/a.yaml
+@@ -2,3 +2,3 @@
+ 2
+-x
++y
+ 4
+@@ -6,3 +6,3 @@
+ 6
+-x
++y
+ 8
+@@ -11,2 +11,2 @@
+ 11
+-x
++y
+`
+	if diff := Diff(change); diff != expected {
+		t.Errorf("expected the diff:\n%s\ngot:\n%s", expected, diff)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	const boilerplate = "/*\nCopyright 2021 The Fleet Authors.\n*/\n"
+	replace := Header(boilerplate)
+	for _, tc := range []struct {
+		path, content, expected string
+	}{
+		{"main.go", "package main\n", "/*\nCopyright 2021 The Fleet Authors.\n*/\n\npackage main\n"},
+		{"main.go", "/*\nCopyright 2021 The Fleet Authors.\n*/\n\npackage main\n",
+			"/*\nCopyright 2021 The Fleet Authors.\n*/\n\npackage main\n"},
+		{"main.go", "/*\nCopyright 2019 The Ship Authors.\n*/\n\n// Package main runs the manager\npackage main\n",
+			"/*\nCopyright 2021 The Fleet Authors.\n*/\n\n// Package main runs the manager\npackage main\n"},
+		{"main.go", "// Copyright 2019 The Ship Authors.\n// SPDX-License-Identifier: MIT\n\npackage main\n",
+			"/*\nCopyright 2021 The Fleet Authors.\n*/\n\npackage main\n"},
+		{"main.go", "// Package main runs the manager\npackage main\n",
+			"/*\nCopyright 2021 The Fleet Authors.\n*/\n\n// Package main runs the manager\npackage main\n"},
+		{"api/v1/zz_generated.deepcopy.go", "// +build !ignore_autogenerated\n\n/*\nCopyright 2019.\n*/\n\npackage v1\n",
+			"// +build !ignore_autogenerated\n\n/*\nCopyright 2021 The Fleet Authors.\n*/\n\npackage v1\n"},
+		{"README.md", "# Ship\n", "# Ship\n"},
+	} {
+		if actual := replace(tc.path, tc.content); actual != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.path, tc.expected, actual)
+		}
+	}
+}
+
+func TestCopyright(t *testing.T) {
+	const boilerplate = "/*\nCopyright 2019 The Ship Authors.\n\nLicensed under the MIT license.\n*/"
+	for _, tc := range []struct {
+		year, owner, expected string
+	}{
+		{"2021", "", "/*\nCopyright 2021 The Ship Authors.\n\nLicensed under the MIT license.\n*/"},
+		{"", "The Fleet Authors", "/*\nCopyright 2019 The Fleet Authors.\n\nLicensed under the MIT license.\n*/"},
+	} {
+		actual, err := Copyright(boilerplate, tc.year, tc.owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, actual)
+		}
+	}
+
+	if _, err := Copyright("/*\nLicensed under the MIT license.\n*/", "2021", ""); err == nil {
+		t.Errorf("expected an error without copyright line")
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rename
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Chain returns the replacer applying replacers in order.
+func Chain(replacers ...Replacer) Replacer {
+	return func(path, content string) string {
+		for _, replace := range replacers {
+			content = replace(path, content)
+		}
+		return content
+	}
+}
+
+// Domain returns the replacer renaming the domain of the API groups from oldDomain to newDomain in the Go
+// and YAML files: the occurrences of oldDomain that are not part of a longer name, such as in
+// crew.my.domain but not in crew.my.domains, and its dashed form in the paths of the webhooks. The
+// occurrences within repo, the Go module of the project, are kept.
+func Domain(oldDomain, newDomain, repo string) Replacer {
+	oldDashed := "-" + strings.Replace(oldDomain, ".", "-", -1) + "-v"
+	newDashed := "-" + strings.Replace(newDomain, ".", "-", -1) + "-v"
+	return func(path, content string) string {
+		if !isGo(path) && !isYAML(path) {
+			return content
+		}
+		parts := []string{content}
+		if repo != "" {
+			parts = strings.Split(content, repo)
+		}
+		for i, part := range parts {
+			part = replaceBounded(part, oldDomain, newDomain, isDomainBoundary)
+			parts[i] = replaceBounded(part, oldDashed, newDashed, func(byte, byte) bool { return true })
+		}
+		return strings.Join(parts, repo)
+	}
+}
+
+// DomainPath returns the renaming of the paths of the CRD manifests of config/crd/bases, which are named
+// after their API group, from oldDomain to newDomain.
+func DomainPath(oldDomain, newDomain string) func(string) string {
+	return func(p string) string {
+		if dir, name := path.Split(p); dir == "config/crd/bases/" {
+			return dir + replaceBounded(name, oldDomain, newDomain, isDomainBoundary)
+		}
+		return p
+	}
+}
+
+// ProjectName returns the replacer renaming the project from oldName to newName: the names prefixed with
+// the project name in the manifests of config, such as the kustomize namePrefix and namespace or the
+// names of the RBAC objects, the image of the IMG variable of the Makefile, Taskfile.yaml or Justfile and
+// the ManagedBy constant of the ownerlabels package.
+func ProjectName(oldName, newName string) Replacer {
+	return func(p, content string) string {
+		switch {
+		case strings.HasPrefix(p, "config/") && isYAML(p):
+			return replaceBounded(content, oldName+"-", newName+"-", func(prev, _ byte) bool {
+				return !isNameChar(prev)
+			})
+		case p == "Makefile" || p == "Taskfile.yaml" || p == "Justfile":
+			lines := strings.Split(content, "\n")
+			for i, line := range lines {
+				if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "IMG ?=") ||
+					strings.HasPrefix(trimmed, "IMG:") || strings.HasPrefix(trimmed, "IMG :=") {
+					lines[i] = replaceBounded(line, oldName, newName, func(prev, next byte) bool {
+						return (prev == ' ' || prev == '/' || prev == '"') && (next == ':' || next == ' ' || next == 0)
+					})
+				}
+			}
+			return strings.Join(lines, "\n")
+		case isGo(p):
+			return strings.Replace(content,
+				fmt.Sprintf("ManagedBy = %q", oldName), fmt.Sprintf("ManagedBy = %q", newName), -1)
+		}
+		return content
+	}
+}
+
+// Namespace returns the replacer renaming the namespace the manager is installed in from oldNamespace to
+// newNamespace: the kustomize namespace of config/default, the namespace fields and the service DNS names
+// of the manifests of config that kustomize does not set, such as the services of the APIServices, the
+// namespaces skipped by the namespace selectors of the webhooks, and the defaultNamespace constant of the
+// defaults package.
+func Namespace(oldNamespace, newNamespace string) Replacer {
+	return func(p, content string) string {
+		switch {
+		case strings.HasPrefix(p, "config/") && isYAML(p):
+			lines := strings.Split(content, "\n")
+			for i, line := range lines {
+				switch strings.TrimSpace(line) {
+				case "namespace: " + oldNamespace:
+					line = strings.TrimSuffix(line, oldNamespace) + newNamespace
+				case "- " + strconv.Quote(oldNamespace):
+					line = strings.TrimSuffix(line, strconv.Quote(oldNamespace)) + strconv.Quote(newNamespace)
+				}
+				lines[i] = strings.Replace(line, "."+oldNamespace+".svc", "."+newNamespace+".svc", -1)
+			}
+			return strings.Join(lines, "\n")
+		case isGo(p):
+			return strings.Replace(content, fmt.Sprintf("defaultNamespace = %q", oldNamespace),
+				fmt.Sprintf("defaultNamespace = %q", newNamespace), -1)
+		}
+		return content
+	}
+}
+
+// replaceBounded replaces the occurrences of old in s whose previous and next bytes, 0 at the boundaries
+// of s, are accepted by bounded.
+func replaceBounded(s, old, new string, bounded func(prev, next byte) bool) string {
+	if old == "" {
+		return s
+	}
+	var b strings.Builder
+	done := 0
+	for from := 0; ; {
+		i := strings.Index(s[from:], old)
+		if i == -1 {
+			break
+		}
+		i += from
+		prev, next := byte(0), byte(0)
+		if i > 0 {
+			prev = s[i-1]
+		}
+		if end := i + len(old); end < len(s) {
+			next = s[end]
+		}
+		if bounded(prev, next) {
+			b.WriteString(s[done:i])
+			b.WriteString(new)
+			done = i + len(old)
+			from = done
+		} else {
+			from = i + 1
+		}
+	}
+	b.WriteString(s[done:])
+	return b.String()
+}
+
+// isDomainBoundary is true when a domain name is neither preceded nor followed by a character of a label.
+func isDomainBoundary(prev, next byte) bool {
+	return !isNameChar(prev) && !isNameChar(next)
+}
+
+func isNameChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-'
+}
+
+func isGo(p string) bool {
+	return strings.HasSuffix(p, ".go")
+}
+
+func isYAML(p string) bool {
+	return strings.HasSuffix(p, ".yaml") || strings.HasSuffix(p, ".yml")
+}
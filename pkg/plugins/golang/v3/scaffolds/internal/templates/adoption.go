@@ -0,0 +1,166 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Adoption{}
+
+// Adoption scaffolds a package that adopts the unmanaged objects labeled for an owner and prunes the objects
+// an owner no longer needs
+type Adoption struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Adoption) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "adoption", "adoption.go")
+	}
+
+	f.TemplateBody = adoptionTemplate
+
+	// The package is shared by all the controllers that use it
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const adoptionTemplate = `{{ .Boilerplate }}
+
+// Package adoption manages the objects controlled by an owner that are selected by a label
+// naming the owner:
+//   - the objects carrying the label but no controller reference, created before the owner or
+//     by another tool, are adopted by setting the owner as their controller;
+//   - the objects controlled by the owner that its spec no longer requires, e.g. after a list
+//     of the spec shrank, are pruned.
+//
+// Objects controlled by another owner are never adopted nor pruned.
+package adoption
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Adopt sets owner as the controller of the objects of list that have no controller, and returns
+// them. list holds the objects selected by the label naming owner, in its namespace.
+func Adopt(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner client.Object,
+	list client.ObjectList) ([]client.Object, error) {
+	objs, err := items(list)
+	if err != nil {
+		return nil, err
+	}
+
+	var adopted []client.Object
+	for _, obj := range objs {
+		if metav1.GetControllerOf(obj) != nil {
+			continue
+		}
+		// The patch fails rather than overwriting the owner references if they changed meanwhile,
+		// e.g. because another controller adopted the object first.
+		patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+		if err := controllerutil.SetControllerReference(owner, obj, scheme); err != nil {
+			return adopted, err
+		}
+		if err := c.Patch(ctx, obj, patch); err != nil {
+			return adopted, fmt.Errorf("unable to adopt %s: %w", obj.GetName(), err)
+		}
+		adopted = append(adopted, obj)
+	}
+	return adopted, nil
+}
+
+// Prune deletes the objects of list controlled by owner whose name is not in keep, i.e. the objects
+// that the spec of owner no longer requires, and returns them.
+func Prune(ctx context.Context, c client.Client, owner client.Object, list client.ObjectList,
+	keep ...string) ([]client.Object, error) {
+	objs, err := items(list)
+	if err != nil {
+		return nil, err
+	}
+	kept := make(map[string]bool, len(keep))
+	for _, name := range keep {
+		kept[name] = true
+	}
+
+	var pruned []client.Object
+	for _, obj := range objs {
+		if kept[obj.GetName()] || !metav1.IsControlledBy(obj, owner) {
+			continue
+		}
+		// The precondition protects a new object with the same name, created meanwhile.
+		uid := obj.GetUID()
+		err := c.Delete(ctx, obj, client.Preconditions{UID: &uid},
+			client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return pruned, fmt.Errorf("unable to prune %s: %w", obj.GetName(), err)
+		}
+		pruned = append(pruned, obj)
+	}
+	return pruned, nil
+}
+
+// EnqueueOwner returns an event handler requesting the reconciliation of the owner named by the
+// label key of the objects, so that the owner adopts them as soon as they are created. The owner
+// is looked up in the namespace of the objects if namespaced, at the cluster scope otherwise.
+func EnqueueOwner(key string, namespaced bool) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		name := obj.GetLabels()[key]
+		if name == "" {
+			return nil
+		}
+		owner := types.NamespacedName{Name: name}
+		if namespaced {
+			owner.Namespace = obj.GetNamespace()
+		}
+		return []reconcile.Request{ {NamespacedName: owner} }
+	})
+}
+
+// items returns the objects of list.
+func items(list client.ObjectList) ([]client.Object, error) {
+	runtimeObjs, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	objs := make([]client.Object, 0, len(runtimeObjs))
+	for _, runtimeObj := range runtimeObjs {
+		obj, ok := runtimeObj.(client.Object)
+		if !ok {
+			return nil, fmt.Errorf("%T is not a client.Object", runtimeObj)
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+`
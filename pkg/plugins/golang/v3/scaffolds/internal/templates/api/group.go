@@ -37,7 +37,7 @@ func (f *Group) SetTemplateDefaults() error {
 	if f.Path == "" {
 		if f.MultiGroup {
 			if f.Resource.Group != "" {
-				f.Path = filepath.Join("apis", "%[group]", "%[version]", "groupversion_info.go")
+				f.Path = filepath.Join("apis", "%[group-path]", "%[version]", "groupversion_info.go")
 			} else {
 				f.Path = filepath.Join("apis", "%[version]", "groupversion_info.go")
 			}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &GroupDoc{}
+
+// GroupDoc scaffolds the file that documents the registration package of a group of a multigroup project
+type GroupDoc struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *GroupDoc) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("apis", "%[group-path]", "doc.go")
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = groupDocTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+//nolint:lll
+const groupDocTemplate = `{{ .Boilerplate }}
+
+// Package {{ .Resource.GroupPackageName }} registers every version of the {{ .Resource.Group }} API group, so that
+// they are added to a scheme at once
+package {{ .Resource.GroupPackageName }}
+`
+
+var _ file.Template = &GroupRegistration{}
+
+// GroupRegistration scaffolds the file that adds every version of a group of a multigroup project to a scheme
+type GroupRegistration struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *GroupRegistration) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("apis", "%[group-path]", "register.go")
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = fmt.Sprintf(groupRegistrationTemplate,
+		file.NewMarkerFor(f.Path, importMarker),
+		file.NewMarkerFor(f.Path, versionsMarker),
+	)
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const versionsMarker = "versions"
+
+var _ file.Inserter = &GroupRegistrationUpdater{}
+
+// GroupRegistrationUpdater adds the version of the resource to the registration package of its group
+type GroupRegistrationUpdater struct {
+	file.ResourceMixin
+}
+
+// GetPath implements file.Builder
+func (f *GroupRegistrationUpdater) GetPath() string {
+	return f.Resource.Replacer().Replace(filepath.Join("apis", "%[group-path]", "register.go"))
+}
+
+// GetIfExistsAction implements file.Builder
+func (*GroupRegistrationUpdater) GetIfExistsAction() file.IfExistsAction {
+	return file.Overwrite
+}
+
+// GetMarkers implements file.Inserter
+func (f *GroupRegistrationUpdater) GetMarkers() []file.Marker {
+	return []file.Marker{
+		file.NewMarkerFor(f.GetPath(), importMarker),
+		file.NewMarkerFor(f.GetPath(), versionsMarker),
+	}
+}
+
+const (
+	versionAddToSchemeCodeFragment = `%s.AddToScheme,
+`
+)
+
+// GetCodeFragments implements file.Inserter
+func (f *GroupRegistrationUpdater) GetCodeFragments() file.CodeFragmentsMap {
+	return file.CodeFragmentsMap{
+		file.NewMarkerFor(f.GetPath(), importMarker): []string{
+			fmt.Sprintf(apiImportCodeFragment, f.Resource.ImportAlias, f.Resource.Package),
+		},
+		file.NewMarkerFor(f.GetPath(), versionsMarker): []string{
+			fmt.Sprintf(versionAddToSchemeCodeFragment, f.Resource.ImportAlias),
+		},
+	}
+}
+
+const groupRegistrationTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.GroupPackageName }}
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+
+	%s
+)
+
+var (
+	// SchemeBuilder adds the types of every version of the group to a scheme
+	SchemeBuilder = runtime.NewSchemeBuilder(
+		%s
+	)
+
+	// AddToScheme adds the types of every version of the group to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+`
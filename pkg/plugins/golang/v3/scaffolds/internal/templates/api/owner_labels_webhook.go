@@ -0,0 +1,104 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &OwnerLabelsWebhook{}
+
+// OwnerLabelsWebhook scaffolds the file that defines the webhook labeling the objects controlled by a resource
+type OwnerLabelsWebhook struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ResourceMixin
+
+	// Version of webhook marker to scaffold
+	WebhookVersion string
+
+	// Path is the path of the webhook in the webhook server
+	WebhookPath string
+	// Versions is the webhookVersions option of the marker
+	Versions string
+	// AdmissionReviewVersions are the AdmissionReview versions accepted by the webhook
+	AdmissionReviewVersions string
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *OwnerLabelsWebhook) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = strings.TrimSuffix(webhookPath(f.MultiGroup, f.Resource), "_webhook.go") + "_owner_labels_webhook.go"
+	}
+	fmt.Println(f.Path)
+
+	f.TemplateBody = ownerLabelsWebhookTemplate
+
+	f.WebhookPath = fmt.Sprintf("/mutate-owner-labels-%s-%s-%s", strings.Replace(f.Resource.Domain, ".", "-", -1),
+		f.Resource.Version, strings.ToLower(f.Resource.Kind))
+	if f.WebhookVersion != "" && f.WebhookVersion != "v1" {
+		f.Versions = fmt.Sprintf("webhookVersions={%s},", f.WebhookVersion)
+	}
+	if f.AdmissionReviewVersions == "" {
+		f.AdmissionReviewVersions = DefaultAdmissionReviewVersions
+	}
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+// The failure policy is ignore because the webhook receives the requests for all the objects of the
+// resources of the marker, the objects that are not controlled by the resource included.
+//nolint:lll
+const ownerLabelsWebhookTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"{{ .Repo }}/internal/ownerlabels"
+)
+
+// TODO(user): replace configmaps with the resources of the objects controlled by the {{ .Resource.Kind }}.
+// The webhook receives the requests for all the objects of these resources, and only labels the
+// objects controlled by a {{ .Resource.Kind }}: its failure policy is ignore so that it never blocks
+// the other objects.
+//+kubebuilder:webhook:{{ .Versions }}path={{ .WebhookPath }},mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=configmaps,verbs=create;update,versions=v1,name=m{{ lower .Resource.Kind }}-owner-labels.kb.io,admissionReviewVersions={{ printf "{%s}" .AdmissionReviewVersions }}
+
+// SetupOwnerLabelsWebhookWithManager registers the webhook stamping the app.kubernetes.io labels
+// and the managed-by annotation on the objects controlled by a {{ .Resource.Kind }}.
+func (r *{{ .Resource.Kind }}) SetupOwnerLabelsWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register("{{ .WebhookPath }}", &webhook.Admission{
+		Handler: &ownerlabels.Handler{Owner: GroupVersion.WithKind("{{ .Resource.Kind }}").GroupKind()},
+	})
+	return nil
+}
+`
@@ -0,0 +1,363 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+)
+
+const (
+	// SubresourceStatus is the status subresource, whose updates are written by the controllers
+	SubresourceStatus = "status"
+	// SubresourceScale is the scale subresource, whose updates are written by kubectl scale and the autoscalers
+	SubresourceScale = "scale"
+)
+
+var _ file.Template = &SubresourceWebhook{}
+
+// SubresourceWebhook scaffolds the file that defines the webhook validating the updates of a subresource of a
+// resource, which the validating webhook of the resource does not receive
+type SubresourceWebhook struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+
+	// Subresource is the validated subresource, status or scale
+	Subresource string
+
+	// Version of webhook marker to scaffold
+	WebhookVersion string
+
+	// WebhookPath is the path of the webhook in the webhook server
+	WebhookPath string
+	// Versions is the webhookVersions option of the marker
+	Versions string
+	// AdmissionReviewVersions are the AdmissionReview versions accepted by the webhook
+	AdmissionReviewVersions string
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *SubresourceWebhook) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = subresourceWebhookPath(f.MultiGroup, f.Resource, f.Subresource, ".go")
+	}
+	fmt.Println(f.Path)
+
+	switch f.Subresource {
+	case SubresourceStatus:
+		f.TemplateBody = statusWebhookTemplate
+	case SubresourceScale:
+		f.TemplateBody = scaleWebhookTemplate
+	default:
+		return fmt.Errorf("unknown subresource %q", f.Subresource)
+	}
+
+	f.WebhookPath = SubresourceWebhookServerPath(f.Resource.Domain, f.Resource.Version, f.Resource.Kind,
+		f.Subresource)
+	if f.WebhookVersion != "" && f.WebhookVersion != "v1" {
+		f.Versions = fmt.Sprintf("webhookVersions={%s},", f.WebhookVersion)
+	}
+	if f.AdmissionReviewVersions == "" {
+		f.AdmissionReviewVersions = DefaultAdmissionReviewVersions
+	}
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+// subresourceWebhookPath returns the path of the file of the webhook of a subresource of a resource, or of its
+// test file with the _test.go suffix
+func subresourceWebhookPath(multiGroup bool, res *resource.Resource, subresource, suffix string) string {
+	return strings.TrimSuffix(webhookPath(multiGroup, res), "_webhook.go") + "_" + subresource + "_webhook" + suffix
+}
+
+// SubresourceWebhookServerPath returns the path in the webhook server of the webhook of a subresource
+func SubresourceWebhookServerPath(domain, version, kind, subresource string) string {
+	return fmt.Sprintf("/validate-%s-%s-%s-%s", subresource, strings.Replace(domain, ".", "-", -1), version,
+		strings.ToLower(kind))
+}
+
+var _ file.Template = &SubresourceWebhookTest{}
+
+// SubresourceWebhookTest scaffolds the file that tests the webhook of a subresource of a resource
+type SubresourceWebhookTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+
+	// Subresource is the validated subresource, status or scale
+	Subresource string
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *SubresourceWebhookTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = subresourceWebhookPath(f.MultiGroup, f.Resource, f.Subresource, "_test.go")
+	}
+
+	switch f.Subresource {
+	case SubresourceStatus:
+		f.TemplateBody = statusWebhookTestTemplate
+	case SubresourceScale:
+		f.TemplateBody = scaleWebhookTestTemplate
+	default:
+		return fmt.Errorf("unknown subresource %q", f.Subresource)
+	}
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+//nolint:lll
+const statusWebhookTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// The updates of the status subresource are not received by the validating webhook of the {{ .Resource.Kind }},
+// this webhook validates them, e.g. to enforce the invariants of the status written by other controllers.
+//+kubebuilder:webhook:{{ .Versions }}path={{ .WebhookPath }},mutating=false,failurePolicy=fail,sideEffects=None,groups={{ .Resource.Domain }},resources={{ .Resource.Plural }}/status,verbs=update,versions={{ .Resource.Version }},name=v{{ lower .Resource.Kind }}-status.kb.io,admissionReviewVersions={{ printf "{%s}" .AdmissionReviewVersions }}
+
+// SetupStatusWebhookWithManager registers the webhook validating the updates of the status of the
+// {{ .Resource.Kind }} objects.
+func (r *{{ .Resource.Kind }}) SetupStatusWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register("{{ .WebhookPath }}", &webhook.Admission{
+		Handler: &{{ lower .Resource.Kind }}StatusValidator{},
+	})
+	return nil
+}
+
+// {{ lower .Resource.Kind }}StatusValidator validates the updates of the status subresource of the {{ .Resource.Kind }} objects
+type {{ lower .Resource.Kind }}StatusValidator struct{}
+
+var _ admission.Handler = &{{ lower .Resource.Kind }}StatusValidator{}
+
+// Handle implements admission.Handler
+func (v *{{ lower .Resource.Kind }}StatusValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	obj, old := &{{ .Resource.Kind }}{}, &{{ .Resource.Kind }}{}
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := obj.ValidateStatusUpdate(old); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// ValidateStatusUpdate validates the update of the status of the {{ .Resource.Kind }} from the status of old.
+func (r *{{ .Resource.Kind }}) ValidateStatusUpdate(old *{{ .Resource.Kind }}) error {
+	// TODO(user): check the invariants of the status, e.g. that a counter never decreases or that a
+	// terminal phase is never left, and return an error describing the violated one.
+	return nil
+}
+`
+
+//nolint:lll
+const scaleWebhookTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// The updates of the scale subresource, e.g. by kubectl scale or the HorizontalPodAutoscaler, are not received
+// by the validating webhook of the {{ .Resource.Kind }}, this webhook validates them.
+//+kubebuilder:webhook:{{ .Versions }}path={{ .WebhookPath }},mutating=false,failurePolicy=fail,sideEffects=None,groups={{ .Resource.Domain }},resources={{ .Resource.Plural }}/scale,verbs=update,versions={{ .Resource.Version }},name=v{{ lower .Resource.Kind }}-scale.kb.io,admissionReviewVersions={{ printf "{%s}" .AdmissionReviewVersions }}
+
+// SetupScaleWebhookWithManager registers the webhook validating the updates of the scale of the
+// {{ .Resource.Kind }} objects.
+func (r *{{ .Resource.Kind }}) SetupScaleWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register("{{ .WebhookPath }}", &webhook.Admission{
+		Handler: &{{ lower .Resource.Kind }}ScaleValidator{},
+	})
+	return nil
+}
+
+// {{ lower .Resource.Kind }}ScaleValidator validates the updates of the scale subresource of the {{ .Resource.Kind }} objects
+type {{ lower .Resource.Kind }}ScaleValidator struct{}
+
+var _ admission.Handler = &{{ lower .Resource.Kind }}ScaleValidator{}
+
+// Handle implements admission.Handler
+func (v *{{ lower .Resource.Kind }}ScaleValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	scale, old := &autoscalingv1.Scale{}, &autoscalingv1.Scale{}
+	if err := json.Unmarshal(req.Object.Raw, scale); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := validate{{ .Resource.Kind }}Scale(scale, old); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// validate{{ .Resource.Kind }}Scale validates the scaling of a {{ .Resource.Kind }} from the replicas of old to the replicas of scale.
+func validate{{ .Resource.Kind }}Scale(scale, old *autoscalingv1.Scale) error {
+	// TODO(user): bound the replicas, e.g. reject scaling to zero or by more than a step at once, and return
+	// an error describing the rejected scaling.
+	return nil
+}
+`
+
+//nolint:lll
+const statusWebhookTestTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func Test{{ .Resource.Kind }}StatusValidator(t *testing.T) {
+	old := &{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Name: "{{ lower .Resource.Kind }}", Namespace: "default"}}
+	obj := old.DeepCopy()
+	// TODO(user): update the status of obj, and test the updates rejected by ValidateStatusUpdate.
+
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldRaw, err := json.Marshal(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := &{{ lower .Resource.Kind }}StatusValidator{}
+	response := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		SubResource: "status",
+		Object:      runtime.RawExtension{Raw: raw},
+		OldObject:   runtime.RawExtension{Raw: oldRaw},
+	}})
+	if !response.Allowed {
+		t.Errorf("expected the update of the status to be allowed, got %v", response.Result)
+	}
+
+	response = validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		SubResource: "status",
+		Object:      runtime.RawExtension{Raw: []byte("{")},
+		OldObject:   runtime.RawExtension{Raw: oldRaw},
+	}})
+	if response.Allowed || response.Result.Code != http.StatusBadRequest {
+		t.Errorf("expected a malformed object to be rejected as a bad request, got %v", response.Result)
+	}
+}
+`
+
+//nolint:lll
+const scaleWebhookTestTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func Test{{ .Resource.Kind }}ScaleValidator(t *testing.T) {
+	old := &autoscalingv1.Scale{
+		ObjectMeta: metav1.ObjectMeta{Name: "{{ lower .Resource.Kind }}", Namespace: "default"},
+		Spec:       autoscalingv1.ScaleSpec{Replicas: 1},
+	}
+	scale := old.DeepCopy()
+	scale.Spec.Replicas = 3
+	// TODO(user): test the scalings rejected by validate{{ .Resource.Kind }}Scale.
+
+	raw, err := json.Marshal(scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldRaw, err := json.Marshal(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := &{{ lower .Resource.Kind }}ScaleValidator{}
+	response := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		SubResource: "scale",
+		Object:      runtime.RawExtension{Raw: raw},
+		OldObject:   runtime.RawExtension{Raw: oldRaw},
+	}})
+	if !response.Allowed {
+		t.Errorf("expected the scaling to be allowed, got %v", response.Result)
+	}
+
+	response = validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		SubResource: "scale",
+		Object:      runtime.RawExtension{Raw: []byte("{")},
+		OldObject:   runtime.RawExtension{Raw: oldRaw},
+	}})
+	if response.Allowed || response.Result.Code != http.StatusBadRequest {
+		t.Errorf("expected a malformed Scale to be rejected as a bad request, got %v", response.Result)
+	}
+}
+`
@@ -19,6 +19,7 @@ package api
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
 )
@@ -30,8 +31,39 @@ var _ file.Template = &Types{}
 type Types struct {
 	file.TemplateMixin
 	file.MultiGroupMixin
+	file.MarkerDocsMixin
 	file.BoilerplateMixin
 	file.ResourceMixin
+	file.RepositoryMixin
+
+	// CommonTypes adds an example field of the common types of the project to the spec
+	CommonTypes bool
+
+	// Scale enables the scale subresource of the kind, and adds its replicas and selector fields, if not nil
+	Scale *ScaleSubresource
+	// ScaleMarker is the marker enabling the scale subresource, if any
+	ScaleMarker string
+
+	// NextReconcileTime adds the time of the next periodic reconciliation of the kind to the status
+	NextReconcileTime bool
+
+	// Union adds an example discriminated union to the spec, validated by its Validate method and, if
+	// UnionRules, by the CEL validation rules of the CRD
+	Union      bool
+	UnionRules bool
+
+	// Conditions adds the conditions of the kind to its status, and a Ready column to kubectl get
+	Conditions bool
+	// Paused adds the conditions of the kind to its status, whose Paused condition is set while its
+	// reconciliation is paused, and a Paused column to kubectl get -o wide
+	Paused bool
+
+	// RawExtensionFields are the fields of the spec whose content is preserved as is by the API server
+	RawExtensionFields []RawExtensionField
+	// ReadyColumnMarker is the marker of the Ready column, if any
+	ReadyColumnMarker string
+	// PausedColumnMarker is the marker of the Paused column, if any
+	PausedColumnMarker string
 
 	Force bool
 }
@@ -41,7 +73,7 @@ func (f *Types) SetTemplateDefaults() error {
 	if f.Path == "" {
 		if f.MultiGroup {
 			if f.Resource.Group != "" {
-				f.Path = filepath.Join("apis", "%[group]", "%[version]", "%[kind]_types.go")
+				f.Path = filepath.Join("apis", "%[group-path]", "%[version]", "%[kind]_types.go")
 			} else {
 				f.Path = filepath.Join("apis", "%[version]", "%[kind]_types.go")
 			}
@@ -54,6 +86,18 @@ func (f *Types) SetTemplateDefaults() error {
 
 	f.TemplateBody = typesTemplate
 
+	if f.Scale != nil {
+		f.ScaleMarker = f.Scale.Marker()
+	}
+	if f.Conditions {
+		f.ReadyColumnMarker = `kubebuilder:printcolumn:name="Ready",type=string,` +
+			`JSONPath=".status.conditions[?(@.type==\"Ready\")].status"`
+	}
+	if f.Paused {
+		f.PausedColumnMarker = `kubebuilder:printcolumn:name="Paused",type=string,priority=1,` +
+			`JSONPath=".status.conditions[?(@.type==\"Paused\")].status"`
+	}
+
 	if f.Force {
 		f.IfExistsAction = file.Overwrite
 	} else {
@@ -63,12 +107,97 @@ func (f *Types) SetTemplateDefaults() error {
 	return nil
 }
 
+// ScaleSubresource holds the fields of the scale subresource of a kind, each one a field of the spec or the
+// status, e.g. .spec.replicas
+type ScaleSubresource struct {
+	SpecReplicasPath   string
+	StatusReplicasPath string
+	// LabelSelectorPath is optional, the HorizontalPodAutoscaler requires it to scale on the metrics of the pods
+	LabelSelectorPath string
+}
+
+// Marker returns the marker enabling the scale subresource
+func (s ScaleSubresource) Marker() string {
+	marker := fmt.Sprintf("kubebuilder:subresource:scale:specpath=%s,statuspath=%s",
+		s.SpecReplicasPath, s.StatusReplicasPath)
+	if s.LabelSelectorPath != "" {
+		marker += ",selectorpath=" + s.LabelSelectorPath
+	}
+	return marker
+}
+
+// SpecReplicas returns the Go and JSON names of the spec replicas field
+func (s ScaleSubresource) SpecReplicas() ScaleField {
+	return newScaleField(s.SpecReplicasPath)
+}
+
+// StatusReplicas returns the Go and JSON names of the status replicas field
+func (s ScaleSubresource) StatusReplicas() ScaleField {
+	return newScaleField(s.StatusReplicasPath)
+}
+
+// LabelSelector returns the Go and JSON names of the status label selector field
+func (s ScaleSubresource) LabelSelector() ScaleField {
+	return newScaleField(s.LabelSelectorPath)
+}
+
+// RawExtensionField is a field of the spec holding arbitrary JSON objects, whose unknown fields are preserved
+// instead of being pruned by the API server
+type RawExtensionField struct {
+	Name, JSONName string
+	// EmbeddedResource requires the field to hold a Kubernetes object, whose apiVersion, kind and metadata are
+	// validated by the API server
+	EmbeddedResource bool
+}
+
+// HasRawExtensionFields returns whether the spec has fields holding arbitrary JSON objects
+func (f *Types) HasRawExtensionFields() bool {
+	return len(f.RawExtensionFields) != 0
+}
+
+// HasEmbeddedResources returns whether the spec has fields holding Kubernetes objects
+func (f *Types) HasEmbeddedResources() bool {
+	for _, field := range f.RawExtensionFields {
+		if field.EmbeddedResource {
+			return true
+		}
+	}
+	return false
+}
+
+// ScaleField is a field of the scale subresource
+type ScaleField struct {
+	Name, JSONName string
+}
+
+func newScaleField(path string) ScaleField {
+	jsonName := path[strings.LastIndex(path, ".")+1:]
+	return ScaleField{Name: strings.ToUpper(jsonName[:1]) + jsonName[1:], JSONName: jsonName}
+}
+
 const typesTemplate = `{{ .Boilerplate }}
 
 package {{ .Resource.Version }}
 
 import (
+{{- if .HasRawExtensionFields }}
+	"encoding/json"
+
+{{ end }}
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+{{- if .HasEmbeddedResources }}
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+{{- end }}
+{{- if .HasRawExtensionFields }}
+	"k8s.io/apimachinery/pkg/runtime"
+{{- end }}
+{{- if .Union }}
+	"k8s.io/apimachinery/pkg/util/validation/field"
+{{- end }}
+{{- if .CommonTypes }}
+
+	"{{ .Repo }}/{{ if .MultiGroup }}apis{{ else }}api{{ end }}/common"
+{{- end }}
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -81,17 +210,212 @@ type {{ .Resource.Kind }}Spec struct {
 
 	// Foo is an example field of {{ .Resource.Kind }}. Edit {{ lower .Resource.Kind }}_types.go to remove/update
 	Foo string ` + "`" + `json:"foo,omitempty"` + "`" + `
+{{- if .CommonTypes }}
+
+	// Container is an example field of {{ .Resource.Kind }} reusing the common types of the project, which
+	// validate the image and the compute resources of a container the same way for all the kinds.
+	//+optional
+	Container *common.ContainerSpec ` + "`" + `json:"container,omitempty"` + "`" + `
+{{- end }}
+{{- if .Scale }}
+
+	// {{ .Scale.SpecReplicas.Name }} is the desired number of replicas of the {{ .Resource.Kind }}, set by kubectl scale and the
+	// HorizontalPodAutoscaler through the scale subresource.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	{{ .Scale.SpecReplicas.Name }} *int32 ` + "`" + `json:"{{ .Scale.SpecReplicas.JSONName }},omitempty"` + "`" + `
+{{- end }}
+{{- if .Union }}
+
+	// Source is an example discriminated union of {{ .Resource.Kind }}, whose type names the only one of
+	// its members that is set.
+	//+optional
+	Source *{{ .Resource.Kind }}Source ` + "`" + `json:"source,omitempty"` + "`" + `
+{{- end }}
+{{- range .RawExtensionFields }}
+{{- if .EmbeddedResource }}
+
+	// {{ .Name }} is an example field of {{ $.Resource.Kind }} holding a Kubernetes object, e.g. the template of
+	// an object created by the controller. The API server validates its apiVersion, kind and metadata, and
+	// preserves its other fields as is: they are neither pruned, validated nor defaulted by the CRD, validate
+	// them in the validating webhook. Read it with {{ .Name }}Object.
+	//+kubebuilder:validation:EmbeddedResource
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+optional
+	{{ .Name }} *runtime.RawExtension ` + "`" + `json:"{{ .JSONName }},omitempty"` + "`" + `
+{{- else }}
+
+	// {{ .Name }} is an example field of {{ $.Resource.Kind }} holding an arbitrary JSON object, e.g. the values
+	// passed through to a template. The API server preserves its fields as is: they are neither pruned,
+	// validated nor defaulted by the CRD, validate them in the validating webhook. Read it with Decode{{ .Name }}.
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+optional
+	{{ .Name }} *runtime.RawExtension ` + "`" + `json:"{{ .JSONName }},omitempty"` + "`" + `
+{{- end }}
+{{- end }}
+}
+{{- range .RawExtensionFields }}
+{{- if .EmbeddedResource }}
+
+// {{ .Name }}Object returns the object of the {{ .JSONName }} field, nil if it is not set.
+func (s *{{ $.Resource.Kind }}Spec) {{ .Name }}Object() (*unstructured.Unstructured, error) {
+	if s.{{ .Name }} == nil || len(s.{{ .Name }}.Raw) == 0 {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(s.{{ .Name }}.Raw, &obj.Object); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+{{- else }}
+
+// Decode{{ .Name }} decodes the object of the {{ .JSONName }} field into the value pointed to by v, leaving it
+// unchanged if the field is not set.
+func (s *{{ $.Resource.Kind }}Spec) Decode{{ .Name }}(v interface{}) error {
+	if s.{{ .Name }} == nil || len(s.{{ .Name }}.Raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(s.{{ .Name }}.Raw, v)
+}
+{{- end }}
+{{- end }}
+{{- if .Union }}
+
+// {{ .Resource.Kind }}Source is an example discriminated union: exactly one of its members is set, the one
+// named by its type.
+{{- if .UnionRules }} It is validated by the CEL validation rules of the CRD, and by the Validate method
+// which the validating webhook calls on the clusters that do not enforce them.
+//+union
+//+kubebuilder:validation:XValidation:rule="[has(self.git), has(self.configMap)].exists_one(m, m)",message="exactly one of git and configMap must be set"
+//+kubebuilder:validation:XValidation:rule="self.type == 'Git' ? has(self.git) : has(self.configMap)",message="type must name the member that is set"
+{{- else }} It is validated by the Validate method, which the validating webhook calls: the CEL
+// validation rules of the CRD require Kubernetes 1.25 and a v1 CRD.
+//+union
+{{- end }}
+type {{ .Resource.Kind }}Source struct {
+	// Type names the member of the union that is set.
+	//+unionDiscriminator
+	//+kubebuilder:validation:Enum=Git;ConfigMap
+	Type {{ .Resource.Kind }}SourceType ` + "`" + `json:"type"` + "`" + `
+
+	// Git is the member of the union set when the type is Git.
+	//+optional
+	Git *{{ .Resource.Kind }}GitSource ` + "`" + `json:"git,omitempty"` + "`" + `
+
+	// ConfigMap is the member of the union set when the type is ConfigMap.
+	//+optional
+	ConfigMap *{{ .Resource.Kind }}ConfigMapSource ` + "`" + `json:"configMap,omitempty"` + "`" + `
+}
+
+// {{ .Resource.Kind }}SourceType names a member of {{ .Resource.Kind }}Source.
+type {{ .Resource.Kind }}SourceType string
+
+const (
+	// {{ .Resource.Kind }}SourceTypeGit names the Git member of {{ .Resource.Kind }}Source.
+	{{ .Resource.Kind }}SourceTypeGit {{ .Resource.Kind }}SourceType = "Git"
+	// {{ .Resource.Kind }}SourceTypeConfigMap names the ConfigMap member of {{ .Resource.Kind }}Source.
+	{{ .Resource.Kind }}SourceTypeConfigMap {{ .Resource.Kind }}SourceType = "ConfigMap"
+)
+
+// {{ .Resource.Kind }}GitSource is a Git repository.
+type {{ .Resource.Kind }}GitSource struct {
+	// URL is the URL of the repository.
+	//+kubebuilder:validation:MinLength=1
+	URL string ` + "`" + `json:"url"` + "`" + `
+}
+
+// {{ .Resource.Kind }}ConfigMapSource is a ConfigMap of the namespace of the {{ .Resource.Kind }}.
+type {{ .Resource.Kind }}ConfigMapSource struct {
+	// Name is the name of the ConfigMap.
+	//+kubebuilder:validation:MinLength=1
+	Name string ` + "`" + `json:"name"` + "`" + `
 }
 
+// Validate returns the errors of the union at path, the ones of its CEL validation rules: exactly one of its
+// members must be set, the one named by its type. Keep them in sync when adding members.
+func (s *{{ .Resource.Kind }}Source) Validate(path *field.Path) field.ErrorList {
+	if s == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	members := map[{{ .Resource.Kind }}SourceType]bool{
+		{{ .Resource.Kind }}SourceTypeGit:       s.Git != nil,
+		{{ .Resource.Kind }}SourceTypeConfigMap: s.ConfigMap != nil,
+	}
+	set := 0
+	for _, isSet := range members {
+		if isSet {
+			set++
+		}
+	}
+	switch {
+	case set == 0:
+		allErrs = append(allErrs, field.Required(path, "exactly one of git and configMap must be set"))
+	case set > 1:
+		allErrs = append(allErrs, field.Forbidden(path, "exactly one of git and configMap must be set"))
+	}
+	if isSet, known := members[s.Type]; !known {
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), s.Type,
+			[]string{string({{ .Resource.Kind }}SourceTypeGit), string({{ .Resource.Kind }}SourceTypeConfigMap)}))
+	} else if !isSet && set != 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("type"), s.Type, "type must name the member that is set"))
+	}
+	return allErrs
+}
+{{- end }}
+
 // {{ .Resource.Kind }}Status defines the observed state of {{ .Resource.Kind }}
 type {{ .Resource.Kind }}Status struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+{{- if .Scale }}
+
+	// {{ .Scale.StatusReplicas.Name }} is the observed number of replicas of the {{ .Resource.Kind }}.
+	{{ .Scale.StatusReplicas.Name }} int32 ` + "`" + `json:"{{ .Scale.StatusReplicas.JSONName }},omitempty"` + "`" + `
+{{- if .Scale.LabelSelectorPath }}
+
+	// {{ .Scale.LabelSelector.Name }} is the label selector of the pods of the {{ .Resource.Kind }} in its string form, which the
+	// HorizontalPodAutoscaler uses to find their metrics. Set it with Set{{ .Scale.LabelSelector.Name }}.
+	{{ .Scale.LabelSelector.Name }} string ` + "`" + `json:"{{ .Scale.LabelSelector.JSONName }},omitempty"` + "`" + `
+{{- end }}
+{{- end }}
+{{- if .NextReconcileTime }}
+
+	// NextReconcileTime is the time of the next periodic reconciliation of the {{ .Resource.Kind }}, which
+	// its controller reconciles again after a period even if nothing changed.
+	//+optional
+	NextReconcileTime *metav1.Time ` + "`" + `json:"nextReconcileTime,omitempty"` + "`" + `
+{{- end }}
+{{- if and .Conditions .Paused }}
+
+	// Conditions are the observations of the state of the {{ .Resource.Kind }}, whose Ready and Paused
+	// conditions are maintained by the internal/readiness and internal/pause packages.
+{{- else if .Conditions }}
+
+	// Conditions are the observations of the state of the {{ .Resource.Kind }}, whose Ready condition is
+	// maintained by the internal/readiness package.
+{{- else if .Paused }}
+
+	// Conditions are the observations of the state of the {{ .Resource.Kind }}, whose Paused condition is
+	// maintained by the internal/pause package.
+{{- end }}
+{{- if or .Conditions .Paused }}
+	//+listType=map
+	//+listMapKey=type
+	//+patchStrategy=merge
+	//+patchMergeKey=type
+	//+optional
+	Conditions []metav1.Condition ` + "`" + `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"` + "`" + `
+{{- end }}
 }
 
-//+kubebuilder:object:root=true
-//+kubebuilder:subresource:status
-{{ if not .Resource.Namespaced }} //+kubebuilder:resource:scope=Cluster {{ end }}
+{{ if .Resource.Namespaced -}}
+{{ markers .MarkerDocs "kubebuilder:object:root=true" "kubebuilder:subresource:status" .ScaleMarker .ReadyColumnMarker .PausedColumnMarker }}
+{{- else -}}
+{{ markers .MarkerDocs "kubebuilder:object:root=true" "kubebuilder:subresource:status" .ScaleMarker .ReadyColumnMarker .PausedColumnMarker "kubebuilder:resource:scope=Cluster" }}
+{{- end }}
 
 // {{ .Resource.Kind }} is the Schema for the {{ .Resource.Plural }} API
 type {{ .Resource.Kind }} struct {
@@ -102,7 +426,7 @@ type {{ .Resource.Kind }} struct {
 	Status {{ .Resource.Kind }}Status ` + "`" + `json:"status,omitempty"` + "`" + `
 }
 
-//+kubebuilder:object:root=true
+{{ markers .MarkerDocs "kubebuilder:object:root=true" }}
 
 // {{ .Resource.Kind }}List contains a list of {{ .Resource.Kind }}
 type {{ .Resource.Kind }}List struct {
@@ -110,6 +434,19 @@ type {{ .Resource.Kind }}List struct {
 	metav1.ListMeta ` + "`" + `json:"metadata,omitempty"` + "`" + `
 	Items           []{{ .Resource.Kind }} ` + "`" + `json:"items"` + "`" + `
 }
+{{- if and .Scale .Scale.LabelSelectorPath }}
+
+// Set{{ .Scale.LabelSelector.Name }} records the label selector of the pods of the {{ .Resource.Kind }} in its status, in the string
+// form served by the scale subresource.
+func (r *{{ .Resource.Kind }}) Set{{ .Scale.LabelSelector.Name }}(selector *metav1.LabelSelector) error {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return err
+	}
+	r.Status.{{ .Scale.LabelSelector.Name }} = s.String()
+	return nil
+}
+{{- end }}
 
 func init() {
 	SchemeBuilder.Register(&{{ .Resource.Kind }}{}, &{{ .Resource.Kind }}List{})
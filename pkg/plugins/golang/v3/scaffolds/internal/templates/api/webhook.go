@@ -22,26 +22,46 @@ import (
 	"strings"
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/immutable"
 )
 
-var _ file.Template = &Webhook{}
+var (
+	_ file.Template = &Webhook{}
+	_ file.Inserter = &WebhookUpdater{}
+)
 
 // Webhook scaffolds the file that defines a webhook for a CRD or a builtin resource
 type Webhook struct { // nolint:maligned
 	file.TemplateMixin
 	file.MultiGroupMixin
 	file.BoilerplateMixin
+	file.RepositoryMixin
 	file.ResourceMixin
 
-	// Is the Group domain for the Resource replacing '.' with '-'
-	GroupDomainWithDash string
-
 	// Version of webhook marker to scaffold
 	WebhookVersion string
 	// If scaffold the defaulting webhook
 	Defaulting bool
 	// If scaffold the validating webhook
 	Validating bool
+	// ImmutableFields are the spec fields whose updates are rejected by the validating webhook
+	ImmutableFields []immutable.Field
+	// Unions are the spec fields of union types, validated by the validating webhook
+	Unions []immutable.Field
+	// WasmPolicy indicates that the validation methods live in the validation file of the resource, which is
+	// also compiled to a wasm policy
+	WasmPolicy bool
+
+	// FailurePolicy, SideEffects and MatchPolicy are the options of the defaulting and validating webhooks
+	FailurePolicy, SideEffects, MatchPolicy string
+	// AdmissionReviewVersions are the AdmissionReview versions accepted by the webhooks, e.g. v1,v1beta1
+	AdmissionReviewVersions string
+
+	// Metrics indicates that the defaulting and validating webhooks are instrumented by the webhookmetrics
+	// package, which sheds their requests over MaxInFlight concurrent ones unless it is zero
+	Metrics     bool
+	MaxInFlight int
 
 	Force bool
 }
@@ -49,27 +69,18 @@ type Webhook struct { // nolint:maligned
 // SetTemplateDefaults implements file.Template
 func (f *Webhook) SetTemplateDefaults() error {
 	if f.Path == "" {
-		if f.MultiGroup {
-			if f.Resource.Group != "" {
-				f.Path = filepath.Join("apis", "%[group]", "%[version]", "%[kind]_webhook.go")
-			} else {
-				f.Path = filepath.Join("apis", "%[version]", "%[kind]_webhook.go")
-			}
-		} else {
-			f.Path = filepath.Join("api", "%[version]", "%[kind]_webhook.go")
-		}
+		f.Path = webhookPath(f.MultiGroup, f.Resource)
 	}
-	f.Path = f.Resource.Replacer().Replace(f.Path)
 	fmt.Println(f.Path)
 
-	webhookTemplate := webhookTemplate
-	if f.Defaulting {
-		webhookTemplate = webhookTemplate + defaultingWebhookTemplate
-	}
-	if f.Validating {
-		webhookTemplate = webhookTemplate + validatingWebhookTemplate
-	}
-	f.TemplateBody = webhookTemplate
+	f.TemplateBody = fmt.Sprintf(webhookTemplate,
+		strings.Join(webhookImportCodeFragments(f.Defaulting, f.Validating, f.WasmPolicy,
+			len(f.ImmutableFields) != 0, len(f.Unions) != 0), ""),
+		file.NewMarkerFor(f.Path, importMarker),
+		strings.Join(webhookCodeFragments(f.Resource, f.WebhookVersion, f.Defaulting, f.Validating, f.WasmPolicy,
+			f.FailurePolicy, f.SideEffects, f.MatchPolicy, f.AdmissionReviewVersions, f.ImmutableFields, f.Unions), ""),
+		file.NewMarkerFor(f.Path, webhookMarker),
+	)
 
 	if f.Force {
 		f.IfExistsAction = file.Overwrite
@@ -77,13 +88,191 @@ func (f *Webhook) SetTemplateDefaults() error {
 		f.IfExistsAction = file.Error
 	}
 
-	f.GroupDomainWithDash = strings.Replace(f.Resource.Domain, ".", "-", -1)
+	return nil
+}
+
+// WebhookUpdater adds defaulting and validating webhooks to the already scaffolded webhook file of a resource
+type WebhookUpdater struct { //nolint:maligned
+	file.MultiGroupMixin
+	file.ResourceMixin
 
-	if f.WebhookVersion == "" {
-		f.WebhookVersion = "v1"
+	// Version of webhook marker to scaffold
+	WebhookVersion string
+	// If add the defaulting webhook
+	Defaulting bool
+	// If add the validating webhook
+	Validating bool
+	// ImmutableFields are the spec fields whose updates are rejected by the added validating webhook
+	ImmutableFields []immutable.Field
+	// Unions are the spec fields of union types, validated by the added validating webhook
+	Unions []immutable.Field
+	// WasmPolicy indicates that the validation methods of the added validating webhook live in the validation
+	// file of the resource
+	WasmPolicy bool
+
+	// FailurePolicy, SideEffects and MatchPolicy are the options of the added webhooks
+	FailurePolicy, SideEffects, MatchPolicy string
+	// AdmissionReviewVersions are the AdmissionReview versions accepted by the added webhooks
+	AdmissionReviewVersions string
+}
+
+// GetPath implements file.Builder
+func (f *WebhookUpdater) GetPath() string {
+	return webhookPath(f.MultiGroup, f.Resource)
+}
+
+// GetIfExistsAction implements file.Builder
+func (*WebhookUpdater) GetIfExistsAction() file.IfExistsAction {
+	return file.Overwrite
+}
+
+// DefaultAdmissionReviewVersions are the AdmissionReview versions accepted by the webhooks unless the project
+// only supports Kubernetes 1.16 or later: the older clusters only send v1beta1 ones, which the decoder of
+// controller-runtime handles as the v1 ones.
+const DefaultAdmissionReviewVersions = "v1,v1beta1"
+
+// webhookMarker is the marker after which the webhooks are added to the webhook file
+const webhookMarker = "webhooks"
+
+// GetMarkers implements file.Inserter
+func (f *WebhookUpdater) GetMarkers() []file.Marker {
+	return []file.Marker{
+		file.NewMarkerFor(f.GetPath(), importMarker),
+		file.NewMarkerFor(f.GetPath(), webhookMarker),
 	}
+}
 
-	return nil
+// GetCodeFragments implements file.Inserter
+func (f *WebhookUpdater) GetCodeFragments() file.CodeFragmentsMap {
+	fragments := make(file.CodeFragmentsMap, 2)
+
+	imports := webhookImportCodeFragments(f.Defaulting, f.Validating, f.WasmPolicy,
+		len(f.ImmutableFields) != 0, len(f.Unions) != 0)
+	if len(imports) != 0 {
+		fragments[file.NewMarkerFor(f.GetPath(), importMarker)] = imports
+	}
+	code := webhookCodeFragments(f.Resource, f.WebhookVersion, f.Defaulting, f.Validating, f.WasmPolicy,
+		f.FailurePolicy, f.SideEffects, f.MatchPolicy, f.AdmissionReviewVersions, f.ImmutableFields, f.Unions)
+	if len(code) != 0 {
+		fragments[file.NewMarkerFor(f.GetPath(), webhookMarker)] = code
+	}
+
+	return fragments
+}
+
+// webhookPath returns the path of the webhook file of a resource
+func webhookPath(multiGroup bool, res *resource.Resource) string {
+	path := filepath.Join("api", "%[version]", "%[kind]_webhook.go")
+	if multiGroup {
+		if res.Group != "" {
+			path = filepath.Join("apis", "%[group-path]", "%[version]", "%[kind]_webhook.go")
+		} else {
+			path = filepath.Join("apis", "%[version]", "%[kind]_webhook.go")
+		}
+	}
+	return res.Replacer().Replace(path)
+}
+
+// webhookImportCodeFragments returns the imports required by the defaulting and validating webhooks, the
+// validation methods being in the webhook file unless wasmPolicy is set
+func webhookImportCodeFragments(defaulting, validating, wasmPolicy, immutableFields, unions bool) []string {
+	imports := make([]string, 0, 5)
+	if validating && !wasmPolicy {
+		imports = append(imports, validationImportCodeFragments(immutableFields, unions)...)
+	}
+	if defaulting || validating {
+		imports = append(imports, fmt.Sprintf(importCodeFragment, "sigs.k8s.io/controller-runtime/pkg/webhook"))
+	}
+	return imports
+}
+
+// validationImportCodeFragments returns the imports required by the validation methods of a resource
+func validationImportCodeFragments(immutableFields, unions bool) []string {
+	imports := make([]string, 0, 4)
+	if immutableFields || unions {
+		imports = append(imports,
+			fmt.Sprintf(aliasedImportCodeFragment, "apierrors", "k8s.io/apimachinery/pkg/api/errors"))
+		if immutableFields {
+			imports = append(imports,
+				fmt.Sprintf(aliasedImportCodeFragment, "apivalidation", "k8s.io/apimachinery/pkg/api/validation"))
+		}
+		imports = append(imports, fmt.Sprintf(importCodeFragment, "k8s.io/apimachinery/pkg/util/validation/field"))
+	}
+	return append(imports, fmt.Sprintf(importCodeFragment, "k8s.io/apimachinery/pkg/runtime"))
+}
+
+// webhookCodeFragments returns the code of the defaulting and validating webhooks of a resource, the validation
+// methods being in the validation file of the resource if wasmPolicy is set
+func webhookCodeFragments(res *resource.Resource, webhookVersion string, defaulting, validating, wasmPolicy bool,
+	failurePolicy, sideEffects, matchPolicy, admissionReviewVersions string,
+	immutableFields, unions []immutable.Field) []string {
+	versions := ""
+	if webhookVersion != "" && webhookVersion != "v1" {
+		versions = fmt.Sprintf("webhookVersions={%s},", webhookVersion)
+	}
+	if failurePolicy == "" {
+		failurePolicy = "fail"
+	}
+	if sideEffects == "" {
+		sideEffects = "None"
+	}
+	options := fmt.Sprintf("failurePolicy=%s,sideEffects=%s,", failurePolicy, sideEffects)
+	if matchPolicy != "" {
+		options += fmt.Sprintf("matchPolicy=%s,", matchPolicy)
+	}
+	if admissionReviewVersions == "" {
+		admissionReviewVersions = DefaultAdmissionReviewVersions
+	}
+	groupDomainWithDash := strings.Replace(res.Domain, ".", "-", -1)
+
+	code := make([]string, 0, 2)
+	if defaulting {
+		code = append(code, fmt.Sprintf(defaultingWebhookCodeFragment, versions, groupDomainWithDash,
+			res.Version, strings.ToLower(res.Kind), res.Domain, res.Plural, res.Kind, options, admissionReviewVersions))
+	}
+	if validating && wasmPolicy {
+		code = append(code, fmt.Sprintf(wasmValidatingWebhookCodeFragment, versions, groupDomainWithDash,
+			res.Version, strings.ToLower(res.Kind), res.Domain, res.Plural, res.Kind, options, admissionReviewVersions,
+			filepath.Base(validationPath(false, res))))
+	} else if validating {
+		validateCreate, validateUpdate, helpers := validationCodeFragments(res, immutableFields, unions)
+		code = append(code, fmt.Sprintf(validatingWebhookCodeFragment, versions, groupDomainWithDash,
+			res.Version, strings.ToLower(res.Kind), res.Domain, res.Plural, res.Kind, options, admissionReviewVersions,
+			validateUpdate, helpers, validateCreate))
+	}
+	return code
+}
+
+// validationCodeFragments returns the bodies of the ValidateCreate and ValidateUpdate methods of a resource, and
+// the helpers validating its immutable fields and unions
+func validationCodeFragments(res *resource.Resource, immutableFields, unions []immutable.Field) (
+	validateCreate, validateUpdate, helpers string) {
+	validateCreate, validateUpdate = defaultValidateCreateCodeFragment, defaultValidateUpdateCodeFragment
+	switch {
+	case len(unions) != 0 && len(immutableFields) != 0:
+		validateCreate = unionsValidateCreateCodeFragment
+		validateUpdate = fmt.Sprintf(unionsImmutableValidateUpdateCodeFragment, res.Kind)
+	case len(unions) != 0:
+		validateCreate = unionsValidateCreateCodeFragment
+		validateUpdate = unionsValidateUpdateCodeFragment
+	case len(immutableFields) != 0:
+		validateUpdate = fmt.Sprintf(immutableValidateUpdateCodeFragment, res.Kind)
+	}
+	if len(unions) != 0 {
+		checks := make([]string, 0, len(unions))
+		for _, field := range unions {
+			checks = append(checks, fmt.Sprintf(unionCheckCodeFragment, field.Name, field.JSONName))
+		}
+		helpers += fmt.Sprintf(validateUnionsCodeFragment, res.Kind, strings.Join(checks, ""))
+	}
+	if len(immutableFields) != 0 {
+		checks := make([]string, 0, len(immutableFields))
+		for _, field := range immutableFields {
+			checks = append(checks, fmt.Sprintf(immutableFieldCheckCodeFragment, field.Name, field.JSONName))
+		}
+		helpers += fmt.Sprintf(validateImmutableFieldsCodeFragment, res.Kind, strings.Join(checks, ""))
+	}
+	return validateCreate, validateUpdate, helpers
 }
 
 const (
@@ -94,71 +283,156 @@ package {{ .Resource.Version }}
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
-	{{- if .Validating }}
-	"k8s.io/apimachinery/pkg/runtime"
-	{{- end }}
-	{{- if or .Validating .Defaulting }}
-	"sigs.k8s.io/controller-runtime/pkg/webhook"
-	{{- end }}
+%s	%s
+{{- if .Metrics }}
+
+	"{{ .Repo }}/internal/webhookmetrics"
+{{- end }}
 )
 
 // log is for logging in this package.
 var {{ lower .Resource.Kind }}log = logf.Log.WithName("{{ lower .Resource.Kind }}-resource")
 
 func (r *{{ .Resource.Kind }}) SetupWebhookWithManager(mgr ctrl.Manager) error {
+{{- if .Metrics }}
+	// The defaulting and validating webhooks are registered with their metrics first, the builder skips them.
+{{- if .MaxInFlight }}
+	// The admission requests over MaxInFlight concurrent ones are rejected, and handled by the API server
+	// according to the failure policy of the webhooks.
+	opts := webhookmetrics.Options{MaxInFlight: {{ .MaxInFlight }}}
+{{- else }}
+	// TODO(user): set MaxInFlight to reject the admission requests over a number of concurrent ones.
+	opts := webhookmetrics.Options{}
+{{- end }}
+	if err := webhookmetrics.Register(mgr, r, opts); err != nil {
+		return err
+	}
+{{- end }}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
 }
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+%s
+%s
+`
+
+	importCodeFragment = `"%s"
+`
+	aliasedImportCodeFragment = `%s "%s"
 `
 
-	// TODO(estroz): update admissionReviewVersions to include v1 when controller-runtime supports that version.
 	//nolint:lll
-	defaultingWebhookTemplate = `
-//+kubebuilder:webhook:{{ if ne .WebhookVersion "v1" }}webhookVersions={{"{"}}{{ .WebhookVersion }}{{"}"}},{{ end }}path=/mutate-{{ .GroupDomainWithDash }}-{{ .Resource.Version }}-{{ lower .Resource.Kind }},mutating=true,failurePolicy=fail,sideEffects=None,groups={{ .Resource.Domain }},resources={{ .Resource.Plural }},verbs=create;update,versions={{ .Resource.Version }},name=m{{ lower .Resource.Kind }}.kb.io,admissionReviewVersions={v1,v1beta1}
+	defaultingWebhookCodeFragment = `
+//+kubebuilder:webhook:%[1]spath=/mutate-%[2]s-%[3]s-%[4]s,mutating=true,%[8]sgroups=%[5]s,resources=%[6]s,verbs=create;update,versions=%[3]s,name=m%[4]s.kb.io,admissionReviewVersions={%[9]s}
 
-var _ webhook.Defaulter = &{{ .Resource.Kind }}{}
+var _ webhook.Defaulter = &%[7]s{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
-func (r *{{ .Resource.Kind }}) Default() {
-	{{ lower .Resource.Kind }}log.Info("default", "name", r.Name)
+func (r *%[7]s) Default() {
+	%[4]slog.Info("default", "name", r.Name)
 
 	// TODO(user): fill in your defaulting logic.
 }
 `
 
-	// TODO(estroz): update admissionReviewVersions to include v1 when controller-runtime supports that version.
 	//nolint:lll
-	validatingWebhookTemplate = `
+	validatingWebhookCodeFragment = `
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
-//+kubebuilder:webhook:{{ if ne .WebhookVersion "v1" }}webhookVersions={{"{"}}{{ .WebhookVersion }}{{"}"}},{{ end }}path=/validate-{{ .GroupDomainWithDash }}-{{ .Resource.Version }}-{{ lower .Resource.Kind }},mutating=false,failurePolicy=fail,sideEffects=None,groups={{ .Resource.Domain }},resources={{ .Resource.Plural }},verbs=create;update,versions={{ .Resource.Version }},name=v{{ lower .Resource.Kind }}.kb.io,admissionReviewVersions={v1,v1beta1}
+//+kubebuilder:webhook:%[1]spath=/validate-%[2]s-%[3]s-%[4]s,mutating=false,%[8]sgroups=%[5]s,resources=%[6]s,verbs=create;update,versions=%[3]s,name=v%[4]s.kb.io,admissionReviewVersions={%[9]s}
 
-var _ webhook.Validator = &{{ .Resource.Kind }}{}
+var _ webhook.Validator = &%[7]s{}
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
-func (r *{{ .Resource.Kind }}) ValidateCreate() error {
-	{{ lower .Resource.Kind }}log.Info("validate create", "name", r.Name)
+func (r *%[7]s) ValidateCreate() error {
+	%[4]slog.Info("validate create", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object creation.
-	return nil
-}
+%[12]s}
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
-func (r *{{ .Resource.Kind }}) ValidateUpdate(old runtime.Object) error {
-	{{ lower .Resource.Kind }}log.Info("validate update", "name", r.Name)
+func (r *%[7]s) ValidateUpdate(old runtime.Object) error {
+	%[4]slog.Info("validate update", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object update.
-	return nil
-}
+%[10]s}
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
-func (r *{{ .Resource.Kind }}) ValidateDelete() error {
-	{{ lower .Resource.Kind }}log.Info("validate delete", "name", r.Name)
+func (r *%[7]s) ValidateDelete() error {
+	%[4]slog.Info("validate delete", "name", r.Name)
 
 	// TODO(user): fill in your validation logic upon object deletion.
 	return nil
 }
+%[11]s`
+
+	//nolint:lll
+	wasmValidatingWebhookCodeFragment = `
+// TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
+//+kubebuilder:webhook:%[1]spath=/validate-%[2]s-%[3]s-%[4]s,mutating=false,%[8]sgroups=%[5]s,resources=%[6]s,verbs=create;update,versions=%[3]s,name=v%[4]s.kb.io,admissionReviewVersions={%[9]s}
+
+// The ValidateCreate, ValidateUpdate and ValidateDelete methods implementing webhook.Validator are in
+// %[10]s, which is also compiled to the wasm policy of the resource in policies/.
+var _ webhook.Validator = &%[7]s{}
+`
+
+	defaultValidateCreateCodeFragment = `	// TODO(user): fill in your validation logic upon object creation.
+	return nil
+`
+
+	unionsValidateCreateCodeFragment = `	// TODO(user): fill in your validation logic upon object creation.
+	return r.validateUnions()
+`
+
+	defaultValidateUpdateCodeFragment = `	// TODO(user): fill in your validation logic upon object update.
+	return nil
+`
+
+	unionsValidateUpdateCodeFragment = `	// TODO(user): fill in your validation logic upon object update.
+	return r.validateUnions()
+`
+
+	unionsImmutableValidateUpdateCodeFragment = `	// TODO(user): fill in your validation logic upon object update.
+	if err := r.validateUnions(); err != nil {
+		return err
+	}
+	return r.validateImmutableFields(old.(*%s))
+`
+
+	immutableValidateUpdateCodeFragment = `	// TODO(user): fill in your validation logic upon object update.
+	return r.validateImmutableFields(old.(*%s))
+`
+
+	validateImmutableFieldsCodeFragment = `
+// validateImmutableFields rejects the updates of the immutable fields of the spec
+func (r *%[1]s) validateImmutableFields(old *%[1]s) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+%[2]s
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("%[1]s").GroupKind(), r.Name, allErrs)
+}
+`
+
+	validateUnionsCodeFragment = `
+// validateUnions rejects the specs whose unions do not set exactly one member, which the CEL validation rules
+// of the CRD only reject on Kubernetes 1.25 or later
+func (r *%[1]s) validateUnions() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+%[2]s
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("%[1]s").GroupKind(), r.Name, allErrs)
+}
+`
+
+	unionCheckCodeFragment = `	allErrs = append(allErrs, r.Spec.%[1]s.Validate(specPath.Child(%[2]q))...)
+`
+
+	immutableFieldCheckCodeFragment = `	allErrs = append(allErrs,
+		apivalidation.ValidateImmutableField(r.Spec.%[1]s, old.Spec.%[1]s, specPath.Child(%[2]q))...)
 `
 )
@@ -26,7 +26,7 @@ func (f *WebhookSuite) SetTemplateDefaults() error {
 	if f.Path == "" {
 		if f.MultiGroup {
 			if f.Resource.Group != "" {
-				f.Path = filepath.Join("apis", "%[group]", "%[version]", "webhook_suite_test.go")
+				f.Path = filepath.Join("apis", "%[group-path]", "%[version]", "webhook_suite_test.go")
 			} else {
 				f.Path = filepath.Join("apis", "%[version]", "webhook_suite_test.go")
 			}
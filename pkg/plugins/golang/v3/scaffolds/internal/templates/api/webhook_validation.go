@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/immutable"
+)
+
+var _ file.Template = &WebhookValidation{}
+
+// WebhookValidation scaffolds the file that defines the validation methods of the validating webhook of a
+// resource apart from the webhook file, so that they are also compiled to a wasm policy
+type WebhookValidation struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+
+	// ImmutableFields are the spec fields whose updates are rejected by the validation
+	ImmutableFields []immutable.Field
+	// Unions are the spec fields of union types, validated by the validation
+	Unions []immutable.Field
+
+	// PolicyPath is the directory of the wasm policy compiling the validation
+	PolicyPath string
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *WebhookValidation) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = validationPath(f.MultiGroup, f.Resource)
+	}
+	fmt.Println(f.Path)
+
+	validateCreate, validateUpdate, helpers := validationCodeFragments(f.Resource, f.ImmutableFields, f.Unions)
+	f.TemplateBody = fmt.Sprintf(webhookValidationTemplate,
+		strings.Join(validationImportCodeFragments(len(f.ImmutableFields) != 0, len(f.Unions) != 0), ""),
+		validateCreate, validateUpdate, helpers,
+	)
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+// validationPath returns the path of the file of the validation methods of a resource
+func validationPath(multiGroup bool, res *resource.Resource) string {
+	return strings.TrimSuffix(webhookPath(multiGroup, res), "_webhook.go") + "_validation.go"
+}
+
+const webhookValidationTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+%s)
+
+// The validation of the {{ .Resource.Kind }} objects is run by the validating webhook of the manager, and by
+// the wasm policy of {{ .PolicyPath }} in the clusters disallowing custom admission webhooks.
+// It must only depend on the API types and on the apimachinery packages: the files importing
+// controller-runtime are not compiled to wasm.
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *{{ .Resource.Kind }}) ValidateCreate() error {
+%s}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *{{ .Resource.Kind }}) ValidateUpdate(old runtime.Object) error {
+%s}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *{{ .Resource.Kind }}) ValidateDelete() error {
+	// TODO(user): fill in your validation logic upon object deletion.
+	return nil
+}
+%s`
@@ -0,0 +1,250 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+const defaultAPIServerPath = "apiserver/apiserver.go"
+
+var _ file.Template = &APIServer{}
+
+// APIServer scaffolds a package that serves the resources of the project from an aggregated API server
+type APIServer struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ProjectNameMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *APIServer) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.FromSlash(defaultAPIServerPath)
+	}
+
+	f.TemplateBody = fmt.Sprintf(apiServerTemplate,
+		file.NewMarkerFor(f.Path, importMarker),
+		file.NewMarkerFor(f.Path, resourceMarker),
+	)
+
+	return nil
+}
+
+var _ file.Inserter = &APIServerUpdater{}
+
+// APIServerUpdater updates the aggregated API server to serve a resource
+type APIServerUpdater struct {
+	file.ResourceMixin
+}
+
+// GetPath implements file.Builder
+func (*APIServerUpdater) GetPath() string {
+	return filepath.FromSlash(defaultAPIServerPath)
+}
+
+// GetIfExistsAction implements file.Builder
+func (*APIServerUpdater) GetIfExistsAction() file.IfExistsAction {
+	return file.Overwrite
+}
+
+const (
+	importMarker   = "imports"
+	resourceMarker = "resource"
+)
+
+// GetMarkers implements file.Inserter
+func (f *APIServerUpdater) GetMarkers() []file.Marker {
+	return []file.Marker{
+		file.NewMarkerFor(f.GetPath(), importMarker),
+		file.NewMarkerFor(f.GetPath(), resourceMarker),
+	}
+}
+
+const (
+	apiImportCodeFragment = `%s "%s"
+`
+	resourceCodeFragment = `{GroupVersion: %[1]s.GroupVersion, Resource: "%[2]s", Object: &%[1]s.%[3]s{}, List: &%[1]s.%[3]sList{}, Namespaced: %[4]t},
+`
+)
+
+// GetCodeFragments implements file.Inserter
+func (f *APIServerUpdater) GetCodeFragments() file.CodeFragmentsMap {
+	fragments := make(file.CodeFragmentsMap, 2)
+
+	// If resource is not being provided we are creating the file, not updating it
+	if f.Resource == nil {
+		return fragments
+	}
+
+	fragments[file.NewMarkerFor(f.GetPath(), importMarker)] = []string{
+		fmt.Sprintf(apiImportCodeFragment, f.Resource.ImportAlias, f.Resource.Package),
+	}
+	fragments[file.NewMarkerFor(f.GetPath(), resourceMarker)] = []string{
+		fmt.Sprintf(resourceCodeFragment,
+			f.Resource.ImportAlias, f.Resource.Plural, f.Resource.Kind, f.Resource.Namespaced),
+	}
+
+	return fragments
+}
+
+//nolint:lll
+var apiServerTemplate = `{{ .Boilerplate }}
+
+// Package apiserver serves the resources of the project from an aggregated API server: the
+// kube-apiserver proxies the requests for their API groups, registered by the APIService objects
+// of config/apiservice, to this server, which delegates the authentication and the authorization
+// of the requests to the kube-apiserver. The objects are not stored in etcd but by the storage
+// of the internal/storage package.
+package apiserver
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+
+	"{{ .Repo }}/internal/storage"
+	%s
+)
+
+// The API server looks up the namespaces and the admission webhooks to admit the requests.
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;list;watch
+
+var (
+	// Scheme registers the types of the served resources.
+	Scheme = runtime.NewScheme()
+	// Codecs encodes and decodes the objects of the served resources.
+	Codecs = serializer.NewCodecFactory(Scheme)
+)
+
+// Resource is a resource served by the API server.
+type Resource struct {
+	GroupVersion schema.GroupVersion
+	// Resource is the plural name of the resource.
+	Resource string
+	// Object and List are empty objects of the types of the objects and of the lists of the resource.
+	Object, List runtime.Object
+	Namespaced   bool
+}
+
+// Resources are the resources served by the API server.
+var Resources = []Resource{
+	%s
+}
+
+func init() {
+	// The types of the discovery and of the errors are unversioned
+	metav1.AddToGroupVersion(Scheme, schema.GroupVersion{Version: "v1"})
+	Scheme.AddUnversionedTypes(schema.GroupVersion{Version: "v1"},
+		&metav1.Status{}, &metav1.APIVersions{}, &metav1.APIGroupList{}, &metav1.APIGroup{}, &metav1.APIResourceList{})
+
+	// A kind is served in a single version, which is also its internal version: the API server converts
+	// the objects to the internal version of their group to handle them.
+	for _, resource := range Resources {
+		internal := schema.GroupVersion{Group: resource.GroupVersion.Group, Version: runtime.APIVersionInternal}
+		Scheme.AddKnownTypes(resource.GroupVersion, resource.Object, resource.List)
+		Scheme.AddKnownTypes(internal, resource.Object, resource.List)
+		metav1.AddToGroupVersion(Scheme, resource.GroupVersion)
+	}
+}
+
+// Options are the options of the API server, set by the flags of the command line.
+type Options struct {
+	// RecommendedOptions are the options of a generic API server, without the options of etcd.
+	RecommendedOptions *genericoptions.RecommendedOptions
+}
+
+// NewOptions returns the default options of the API server, which serves on the port 8443 with a
+// self-signed certificate, kept in memory, unless a certificate is provided.
+func NewOptions() *Options {
+	options := genericoptions.NewRecommendedOptions("", Codecs.LegacyCodec())
+	options.Etcd = nil
+	options.SecureServing.BindPort = 8443
+	options.SecureServing.ServerCert.CertDirectory = ""
+	return &Options{RecommendedOptions: options}
+}
+
+// AddFlags adds the flags of the options to fs.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.RecommendedOptions.AddFlags(fs)
+}
+
+// Run runs the API server until stopCh is closed.
+func (o *Options) Run(stopCh <-chan struct{}) error {
+	if errs := o.RecommendedOptions.Validate(); len(errs) != 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	if err := o.RecommendedOptions.SecureServing.MaybeDefaultWithSelfSignedCerts(
+		"localhost", nil, []net.IP{net.ParseIP("127.0.0.1")}); err != nil {
+		return fmt.Errorf("unable to create the self-signed certificate: %%w", err)
+	}
+
+	config := genericapiserver.NewRecommendedConfig(Codecs)
+	if err := o.RecommendedOptions.ApplyTo(config); err != nil {
+		return err
+	}
+	server, err := config.Complete().New("{{ .ProjectName }}-apiserver", genericapiserver.NewEmptyDelegate())
+	if err != nil {
+		return err
+	}
+
+	for _, group := range apiGroups() {
+		if err := server.InstallAPIGroup(group); err != nil {
+			return err
+		}
+	}
+	return server.PrepareRun().Run(stopCh)
+}
+
+// apiGroups returns the API groups of the served resources, with the storage of their resources.
+func apiGroups() []*genericapiserver.APIGroupInfo {
+	var groups []*genericapiserver.APIGroupInfo
+	groupsByName := make(map[string]*genericapiserver.APIGroupInfo)
+	for _, resource := range Resources {
+		group, found := groupsByName[resource.GroupVersion.Group]
+		if !found {
+			info := genericapiserver.NewDefaultAPIGroupInfo(resource.GroupVersion.Group, Scheme, metav1.ParameterCodec, Codecs)
+			group = &info
+			groupsByName[resource.GroupVersion.Group] = group
+			groups = append(groups, group)
+		}
+
+		version := resource.GroupVersion.Version
+		if group.VersionedResourcesStorageMap[version] == nil {
+			group.VersionedResourcesStorageMap[version] = make(map[string]rest.Storage)
+		}
+		groupResource := schema.GroupResource{Group: resource.GroupVersion.Group, Resource: resource.Resource}
+		group.VersionedResourcesStorageMap[version][resource.Resource] =
+			storage.NewMemory(groupResource, resource.Object, resource.List, resource.Namespaced)
+	}
+	return groups
+}
+`
@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &APIServerMain{}
+
+// APIServerMain scaffolds a file that defines the aggregated API server entry point
+type APIServerMain struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *APIServerMain) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = defaultMainPath
+	}
+
+	f.TemplateBody = apiServerMainTemplate
+
+	return nil
+}
+
+const apiServerMainTemplate = `{{ .Boilerplate }}
+
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/spf13/pflag"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/klog/v2"
+
+	"{{ .Repo }}/apiserver"
+)
+
+func main() {
+	options := apiserver.NewOptions()
+	options.AddFlags(pflag.CommandLine)
+	klog.InitFlags(flag.CommandLine)
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+
+	if err := options.Run(genericapiserver.SetupSignalHandler()); err != nil {
+		klog.Errorf("problem running the API server: %v", err)
+		os.Exit(1)
+	}
+}
+`
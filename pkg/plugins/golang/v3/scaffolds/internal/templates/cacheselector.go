@@ -0,0 +1,167 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &CacheSelector{}
+
+// CacheSelector scaffolds a package that narrows the objects of the watched types cached by the manager
+type CacheSelector struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *CacheSelector) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "cacheselector", "cacheselector.go")
+	}
+
+	f.TemplateBody = cacheSelectorTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const cacheSelectorTemplate = `{{ .Boilerplate }}
+
+// Package cacheselector narrows the objects of the watched types cached by the manager, whose cache
+// lists and watches all the objects of a type otherwise, to the ones of a namespace or to the ones
+// matching a label selector.
+//
+// The cache of controller-runtime does not select the objects it lists and watches, the selectors are
+// added to its requests instead. The objects that are not selected are not found by the client of the
+// manager, which reads them from the cache, nor by its API reader.
+package cacheselector
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// Selector narrows the objects of Resource cached by the manager to the ones of Namespace, if not
+// empty, matching the label selector Labels, if not empty.
+type Selector struct {
+	Resource  schema.GroupVersionResource
+	Namespace string
+	Labels    string
+}
+
+// Selectors narrow the objects of the watched types cached by the manager.
+type Selectors []Selector
+
+// Config returns a copy of config whose list and watch requests of the resources of s only return the
+// objects selected by their selectors. It panics if a label selector is invalid.
+func (s Selectors) Config(config *rest.Config) *rest.Config {
+	if len(s) == 0 {
+		return config
+	}
+	for _, selector := range s {
+		if _, err := labels.Parse(selector.Labels); err != nil {
+			panic(fmt.Sprintf("invalid label selector of %s: %v", selector.Resource, err))
+		}
+	}
+	config = rest.CopyConfig(config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &selectingRoundTripper{selectors: s, next: rt}
+	})
+	return config
+}
+
+// selectingRoundTripper adds the namespace and the label selector of its selectors to the list and
+// watch requests of their resources.
+type selectingRoundTripper struct {
+	selectors Selectors
+	next      http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (rt *selectingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return rt.next.RoundTrip(req)
+	}
+	resource, isCollection := collection(req.URL.Path)
+	if !isCollection {
+		return rt.next.RoundTrip(req)
+	}
+
+	var fieldSelectors, labelSelectors []string
+	for _, selector := range rt.selectors {
+		if selector.Resource != resource {
+			continue
+		}
+		if selector.Namespace != "" {
+			fieldSelectors = append(fieldSelectors, "metadata.namespace="+selector.Namespace)
+		}
+		if selector.Labels != "" {
+			labelSelectors = append(labelSelectors, selector.Labels)
+		}
+	}
+	if len(fieldSelectors) == 0 && len(labelSelectors) == 0 {
+		return rt.next.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	query := req.URL.Query()
+	for key, selectors := range map[string][]string{"fieldSelector": fieldSelectors, "labelSelector": labelSelectors} {
+		if len(selectors) == 0 {
+			continue
+		}
+		if existing := query.Get(key); existing != "" {
+			selectors = append([]string{existing}, selectors...)
+		}
+		query.Set(key, strings.Join(selectors, ","))
+	}
+	req.URL.RawQuery = query.Encode()
+	return rt.next.RoundTrip(req)
+}
+
+// collection returns the resource of path and true if path is a collection, which is listed and watched
+// with /api/VERSION/[namespaces/NAMESPACE/]RESOURCE for the core group, and with
+// /apis/GROUP/VERSION/[namespaces/NAMESPACE/]RESOURCE for the other ones.
+func collection(path string) (schema.GroupVersionResource, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	var resource schema.GroupVersionResource
+	switch {
+	case len(parts) >= 3 && parts[0] == "api":
+		resource.Version, parts = parts[1], parts[2:]
+	case len(parts) >= 4 && parts[0] == "apis":
+		resource.Group, resource.Version, parts = parts[1], parts[2], parts[3:]
+	default:
+		return resource, false
+	}
+	if len(parts) == 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	if len(parts) != 1 {
+		return resource, false
+	}
+	resource.Resource = parts[0]
+	return resource, true
+}
+`
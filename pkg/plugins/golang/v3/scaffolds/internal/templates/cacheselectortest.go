@@ -0,0 +1,108 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &CacheSelectorTest{}
+
+// CacheSelectorTest scaffolds the file that tests the cacheselector package
+type CacheSelectorTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *CacheSelectorTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "cacheselector", "cacheselector_test.go")
+	}
+
+	f.TemplateBody = cacheSelectorTestTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const cacheSelectorTestTemplate = `{{ .Boilerplate }}
+
+package cacheselector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+func TestSelectingRoundTripper(t *testing.T) {
+	var fieldSelector, labelSelector string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fieldSelector = r.URL.Query().Get("fieldSelector")
+		labelSelector = r.URL.Query().Get("labelSelector")
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &selectingRoundTripper{selectors: Selectors{
+		{Resource: schema.GroupVersionResource{Group: "crew.example.org", Version: "v1", Resource: "captains"},
+			Namespace: "fleet", Labels: "app=fleet"},
+		{Resource: schema.GroupVersionResource{Version: "v1", Resource: "pods"}, Labels: "tier=frontend"},
+	}, next: http.DefaultTransport}}
+
+	for path, expected := range map[string][2]string{
+		"/apis/crew.example.org/v1/captains":                              {"metadata.namespace=fleet", "app=fleet"},
+		"/apis/crew.example.org/v1/namespaces/fleet/captains?watch=true":  {"metadata.namespace=fleet", "app=fleet"},
+		"/apis/crew.example.org/v1/captains?labelSelector=team%3Dblue":    {"metadata.namespace=fleet", "team=blue,app=fleet"},
+		"/api/v1/pods":                                                    {"", "tier=frontend"},
+		"/api/v1/namespaces/default/pods":                                 {"", "tier=frontend"},
+		"/apis/crew.example.org/v1/namespaces/fleet/captains/captain":     {"", ""},
+		"/apis/crew.example.org/v1/namespaces/fleet/captains/c/status":    {"", ""},
+		"/apis/crew.example.org/v2/captains":                              {"", ""},
+		"/api/v1/services":                                                {"", ""},
+	} {
+		fieldSelector, labelSelector = "", ""
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if fieldSelector != expected[0] || labelSelector != expected[1] {
+			t.Errorf("%s: expected the selectors %q, got %q", path, expected, [2]string{fieldSelector, labelSelector})
+		}
+	}
+}
+
+func TestSelectorsConfig(t *testing.T) {
+	config := &rest.Config{Host: "https://example.org"}
+	if Selectors(nil).Config(config) != config {
+		t.Error("expected the config to be kept without selectors")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected an invalid label selector to panic")
+		}
+	}()
+	Selectors{ {Labels: "app in fleet"} }.Config(config)
+}
+`
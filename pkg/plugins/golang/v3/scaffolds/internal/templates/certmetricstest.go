@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &CertMetricsTest{}
+
+// CertMetricsTest scaffolds the file that tests the certmetrics package
+type CertMetricsTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *CertMetricsTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "certmetrics", "certmetrics_test.go")
+	}
+
+	f.TemplateBody = certMetricsTestTemplate
+
+	// The package is shared by all the webhooks, which have the same serving certificate
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+//nolint:lll
+const certMetricsTestTemplate = `{{ .Boilerplate }}
+
+package certmetrics
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// writeCertificate writes a self-signed certificate valid from notBefore to notAfter at path
+func writeCertificate(t *testing.T, path string, notBefore, notAfter time.Time) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "webhook-service.system.svc"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "certmetrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tls.crt")
+
+	collector := NewCollector(WebhookCertificate, path)
+	if count := testutil.CollectAndCount(collector); count != 0 {
+		t.Errorf("expected no metric without certificate, got %d", count)
+	}
+
+	notBefore := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := notBefore.Add(90 * 24 * time.Hour)
+	writeCertificate(t, path, notBefore, notAfter)
+	if err := testutil.CollectAndCompare(collector, expectedMetrics(notBefore, notAfter)); err != nil {
+		t.Error(err)
+	}
+
+	// The rotated certificate is read on the next collection
+	notBefore, notAfter = notBefore.Add(60*24*time.Hour), notAfter.Add(60*24*time.Hour)
+	writeCertificate(t, path, notBefore, notAfter)
+	if err := testutil.CollectAndCompare(collector, expectedMetrics(notBefore, notAfter)); err != nil {
+		t.Error(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if count := testutil.CollectAndCount(collector); count != 0 {
+		t.Errorf("expected no metric for an invalid certificate, got %d", count)
+	}
+}
+
+// expectedMetrics returns the metrics of the webhook certificate valid from notBefore to notAfter
+func expectedMetrics(notBefore, notAfter time.Time) io.Reader {
+	return strings.NewReader(fmt.Sprintf(` + "`" + `
+# HELP serving_certificate_expiration_timestamp_seconds Expiration time of the serving certificate, as a Unix timestamp
+# TYPE serving_certificate_expiration_timestamp_seconds gauge
+serving_certificate_expiration_timestamp_seconds{certificate="webhook"} %d
+# HELP serving_certificate_not_before_timestamp_seconds Time from which the serving certificate is valid, as a Unix timestamp
+# TYPE serving_certificate_not_before_timestamp_seconds gauge
+serving_certificate_not_before_timestamp_seconds{certificate="webhook"} %d
+` + "`" + `, notAfter.Unix(), notBefore.Unix()))
+}
+
+func TestWebhookCertPath(t *testing.T) {
+	expected := filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs", "tls.crt")
+	if path := webhookCertPath(&webhook.Server{}); path != expected {
+		t.Errorf("expected the default path %s, got %s", expected, path)
+	}
+	server := &webhook.Server{CertDir: "/certs", CertName: "webhook.crt"}
+	if path := webhookCertPath(server); path != filepath.Join("/certs", "webhook.crt") {
+		t.Errorf("expected the path of the server, got %s", path)
+	}
+}
+`
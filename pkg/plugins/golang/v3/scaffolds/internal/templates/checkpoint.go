@@ -0,0 +1,125 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Checkpoint{}
+
+// Checkpoint scaffolds a package that helps splitting long-running reconcile work into
+// steps that honor the cancellation of the context
+type Checkpoint struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Checkpoint) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "checkpoint", "checkpoint.go")
+	}
+
+	f.TemplateBody = checkpointTemplate
+
+	return nil
+}
+
+const checkpointTemplate = `{{ .Boilerplate }}
+
+// Package checkpoint helps splitting long-running reconcile work into steps that
+// honor the cancellation of the context, which happens when the manager is shutting
+// down. Completed steps are recorded in a Checkpoint, usually backed by the status of
+// the reconciled object, so that an interrupted reconciliation is resumed from the
+// last completed step instead of starting over:
+//
+//	err := checkpoint.Run(ctx, checkpoints,
+//		checkpoint.Step{Name: "Provisioned", Run: r.provision},
+//		checkpoint.Step{Name: "Configured", Run: r.configure},
+//	)
+//	if checkpoint.IsInterrupted(err) {
+//		// The manager is shutting down, the remaining steps run in the next reconciliation.
+//		return ctrl.Result{Requeue: true}, nil
+//	}
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Step is a unit of reconcile work.
+type Step struct {
+	// Name identifies the step in the Checkpoint.
+	Name string
+	// Run performs the work of the step. It must return promptly once ctx is done.
+	Run func(ctx context.Context) error
+}
+
+// Checkpoint records the steps that have already been completed.
+type Checkpoint interface {
+	// IsCompleted returns true if the step was already completed.
+	IsCompleted(step string) bool
+	// Complete records that the step was completed.
+	Complete(ctx context.Context, step string) error
+}
+
+// Run executes in order the steps that are not completed yet, recording each of them in
+// the checkpoint once completed. It stops before the next step as soon as ctx is done
+// and returns an error for which IsInterrupted returns true.
+func Run(ctx context.Context, checkpoint Checkpoint, steps ...Step) error {
+	for _, step := range steps {
+		if checkpoint.IsCompleted(step.Name) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("interrupted before step %s: %w", step.Name, err)
+		}
+		if err := step.Run(ctx); err != nil {
+			return fmt.Errorf("step %s failed: %w", step.Name, err)
+		}
+		if err := checkpoint.Complete(ctx, step.Name); err != nil {
+			return fmt.Errorf("unable to record step %s: %w", step.Name, err)
+		}
+	}
+	return nil
+}
+
+// IsInterrupted returns true if err was caused by the cancellation of the context.
+func IsInterrupted(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Set is an in-memory Checkpoint, for instance to be stored in the status of the
+// reconciled object and persisted by the caller once Run returns.
+type Set map[string]bool
+
+// IsCompleted implements Checkpoint.
+func (s Set) IsCompleted(step string) bool {
+	return s[step]
+}
+
+// Complete implements Checkpoint.
+func (s Set) Complete(_ context.Context, step string) error {
+	s[step] = true
+	return nil
+}
+`
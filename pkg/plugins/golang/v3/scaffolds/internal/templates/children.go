@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Children{}
+
+// Children scaffolds a package that creates or patches the objects controlled by the reconciled objects,
+// applying their desired state only when its hash changes
+type Children struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+	file.DomainMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Children) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "children", "children.go")
+	}
+
+	f.TemplateBody = childrenTemplate
+
+	// The package is shared by all the controllers that use it
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const childrenTemplate = `{{ .Boilerplate }}
+
+// Package children creates or patches the objects controlled by the reconciled objects, their
+// children, with controllerutil.CreateOrPatch.
+//
+// The desired state of a child is only applied again when the hash of the input it is computed
+// from, e.g. the spec of its owner, changes. The hash is recorded in an annotation of the child,
+// so that the fields defaulted by the API server or set by other controllers are not reverted by
+// every reconciliation, and a reconciliation whose input did not change sends no request.
+package children
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// HashAnnotation is the annotation of a child holding the hash of the input its desired state was
+// last computed from.
+const HashAnnotation = "{{ .Domain }}/desired-hash"
+
+// CreateOrPatch creates child, or patches it if it exists, so that owner controls it and its state is
+// the one set by mutate. The name and the namespace of child must be set.
+//
+// mutate computes the desired state of child from desired, e.g. the spec of owner, and is only called
+// when child is created or when the hash of desired differs from the one of its HashAnnotation: the
+// changes made to the fields set by mutate are not reverted either until desired changes.
+func CreateOrPatch(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner, child client.Object,
+	desired interface{}, mutate controllerutil.MutateFn) (controllerutil.OperationResult, error) {
+	hash, err := Hash(desired)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	return controllerutil.CreateOrPatch(ctx, c, child, func() error {
+		if err := controllerutil.SetControllerReference(owner, child, scheme); err != nil {
+			return err
+		}
+		if child.GetAnnotations()[HashAnnotation] == hash {
+			return nil
+		}
+		if err := mutate(); err != nil {
+			return err
+		}
+		annotations := child.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[HashAnnotation] = hash
+		child.SetAnnotations(annotations)
+		return nil
+	})
+}
+
+// Hash returns the hash of the JSON representation of desired.
+func Hash(desired interface{}) (string, error) {
+	content, err := json.Marshal(desired)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:16]), nil
+}
+`
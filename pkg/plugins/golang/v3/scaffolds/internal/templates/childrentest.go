@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ChildrenTest{}
+
+// ChildrenTest scaffolds the file that tests the children package
+type ChildrenTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ChildrenTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "children", "children_test.go")
+	}
+
+	f.TemplateBody = childrenTestTemplate
+
+	// The package is shared by all the controllers that use it
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const childrenTestTemplate = `{{ .Boilerplate }}
+
+package children
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func TestCreateOrPatch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	ctx := context.Background()
+	owner := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "default", UID: "uid"}}
+
+	mutations := 0
+	reconcile := func(desired map[string]string) controllerutil.OperationResult {
+		child := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "child", Namespace: "default"}}
+		result, err := CreateOrPatch(ctx, c, scheme, owner, child, desired, func() error {
+			mutations++
+			child.Data = desired
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	for _, step := range []struct {
+		desired   map[string]string
+		expected  controllerutil.OperationResult
+		mutations int
+	}{
+		{map[string]string{"key": "value"}, controllerutil.OperationResultCreated, 1},
+		{map[string]string{"key": "value"}, controllerutil.OperationResultNone, 1},
+		{map[string]string{"key": "other"}, controllerutil.OperationResultUpdated, 2},
+	} {
+		if result := reconcile(step.desired); result != step.expected || mutations != step.mutations {
+			t.Errorf("%v: expected %q with %d mutations, got %q with %d", step.desired, step.expected,
+				step.mutations, result, mutations)
+		}
+	}
+
+	child := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "child", Namespace: "default"}, child); err != nil {
+		t.Fatal(err)
+	}
+	if child.Data["key"] != "other" {
+		t.Errorf("expected the child to hold the last desired state, got %v", child.Data)
+	}
+	if !metav1.IsControlledBy(child, owner) {
+		t.Error("expected the child to be controlled by its owner")
+	}
+	if hash, _ := Hash(map[string]string{"key": "other"}); child.Annotations[HashAnnotation] != hash {
+		t.Errorf("expected the %s annotation %q, got %q", HashAnnotation, hash, child.Annotations[HashAnnotation])
+	}
+}
+`
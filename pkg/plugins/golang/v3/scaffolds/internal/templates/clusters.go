@@ -0,0 +1,159 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Clusters{}
+
+// Clusters scaffolds a package that connects the manager to the remote clusters listed in its cluster registry
+type Clusters struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Clusters) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "clusters", "clusters.go")
+	}
+
+	f.TemplateBody = clustersTemplate
+
+	return nil
+}
+
+const clustersTemplate = `{{ .Boilerplate }}
+
+// Package clusters connects the manager to the remote clusters listed in the cluster registry
+// file provided with its --clusters-config flag, so that the controllers reconcile the objects
+// of every cluster. Each remote cluster gets a cache, started by the manager, and a client
+// reading from this cache.
+//
+// Multi-cluster support is experimental: the credentials of the remote clusters are read from
+// kubeconfig files that have to be mounted in the manager Pod, and the events are recorded in
+// the cluster of the manager.
+package clusters
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/yaml"
+)
+
+// Registry is the content of the cluster registry file, such as:
+//
+//	clusters:
+//	- name: east
+//	  kubeconfig: /etc/clusters/east/kubeconfig
+type Registry struct {
+	Clusters []Entry ` + "`" + `json:"clusters"` + "`" + `
+}
+
+// Entry is a remote cluster of the registry.
+type Entry struct {
+	// Name identifies the cluster in the logs and in the names of its controllers.
+	Name string ` + "`" + `json:"name"` + "`" + `
+	// Kubeconfig is the path of the kubeconfig file giving access to the cluster.
+	Kubeconfig string ` + "`" + `json:"kubeconfig"` + "`" + `
+}
+
+// Cluster is a remote cluster.
+type Cluster struct {
+	Name string
+	// Client reads the objects from Cache and writes them to the API server of the cluster.
+	Client client.Client
+	Cache  cache.Cache
+}
+
+// Clusters are the remote clusters of the registry.
+type Clusters []Cluster
+
+// Names returns the names of the clusters.
+func (c Clusters) Names() []string {
+	names := make([]string, 0, len(c))
+	for _, cluster := range c {
+		names = append(names, cluster.Name)
+	}
+	return names
+}
+
+// Load reads the registry file of path and connects to its clusters, whose caches are started by
+// mgr. An empty path lists no cluster.
+func Load(path string, mgr manager.Manager) (Clusters, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var registry Registry
+	if err := yaml.UnmarshalStrict(content, &registry); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	clusters := make(Clusters, 0, len(registry.Clusters))
+	for _, entry := range registry.Clusters {
+		cluster, err := connect(entry, mgr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to connect to cluster %s: %w", entry.Name, err)
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+func connect(entry Entry, mgr manager.Manager) (Cluster, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", entry.Kubeconfig)
+	if err != nil {
+		return Cluster{}, err
+	}
+
+	clusterCache, err := cache.New(config, cache.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return Cluster{}, err
+	}
+	if err := mgr.Add(clusterCache); err != nil {
+		return Cluster{}, err
+	}
+
+	writer, err := client.New(config, client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return Cluster{}, err
+	}
+	clusterClient, err := client.NewDelegatingClient(client.NewDelegatingClientInput{
+		CacheReader: clusterCache,
+		Client:      writer,
+	})
+	if err != nil {
+		return Cluster{}, err
+	}
+
+	return Cluster{Name: entry.Name, Client: clusterClient, Cache: clusterCache}, nil
+}
+`
@@ -0,0 +1,133 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &DaemonSet{}
+
+// DaemonSet scaffolds a file that defines the DaemonSet running an agent on every node
+type DaemonSet struct {
+	file.TemplateMixin
+	file.ComponentConfigMixin
+
+	// Image is controller manager image name
+	Image string
+
+	// PodSecurity is the Pod Security Standards profile the agents comply with, either restricted or baseline
+	PodSecurity string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *DaemonSet) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "agent", "daemonset.yaml")
+	}
+
+	f.TemplateBody = agentDaemonSetTemplate
+
+	if f.PodSecurity == "" {
+		f.PodSecurity = "restricted"
+	}
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+//nolint:lll
+const agentDaemonSetTemplate = `# The agents run on every node, each one reconciling the objects of its node among the objects of
+# the namespace of its Pod, without leader election nor webhooks. The image of the manager is set
+# by config/manager for the Deployment only, set the image of the agents with:
+#   cd config/agent && kustomize edit set image controller=<image>
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: controller-agent
+  namespace: system
+  labels:
+    control-plane: controller-agent
+spec:
+  selector:
+    matchLabels:
+      control-plane: controller-agent
+  template:
+    metadata:
+      labels:
+        control-plane: controller-agent
+    spec:
+      securityContext:
+{{- if eq .PodSecurity "restricted" }}
+        runAsNonRoot: true
+        runAsUser: 65532
+{{- end }}
+        seccompProfile:
+          type: RuntimeDefault
+      containers:
+      - command:
+        - /manager
+        args:
+{{- if not .ComponentConfig }}
+        - "--health-probe-bind-address=:8081"
+{{- end }}
+        - "--mode=agent"
+        env:
+        - name: POD_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+        - name: NODE_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: spec.nodeName
+        - name: ENABLE_WEBHOOKS
+          value: "false"
+        image: {{ .Image }}
+        name: manager
+        securityContext:
+          allowPrivilegeEscalation: false
+{{- if eq .PodSecurity "restricted" }}
+          capabilities:
+            drop:
+            - ALL
+{{- end }}
+          readOnlyRootFilesystem: true
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: 8081
+          initialDelaySeconds: 15
+          periodSeconds: 20
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: 8081
+          initialDelaySeconds: 5
+          periodSeconds: 10
+        resources:
+          limits:
+            cpu: 100m
+            memory: 30Mi
+          requests:
+            cpu: 100m
+            memory: 20Mi
+      terminationGracePeriodSeconds: 10
+`
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &DeletePatch{}
+
+// DeletePatch scaffolds a file that defines the patch removing the objects of config/rbac and config/manager
+// that are not deployed with the agent: the cluster-wide bindings, the leader election and metrics objects, and
+// the namespace of the manager
+type DeletePatch struct {
+	file.TemplateMixin
+
+	// DaemonSet removes the Deployment of the manager, replaced by the DaemonSet of the agents
+	DaemonSet bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *DeletePatch) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "agent", "delete_patch.yaml")
+	}
+
+	f.TemplateBody = agentDeletePatchTemplate
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+//nolint:lll
+const agentDeletePatchTemplate = `# The agent is deployed in an existing namespace, and only granted the rules of the manager in it:
+# the namespace of the manager, its cluster-wide bindings, and the objects of the leader election
+# and of the auth proxy protecting its metrics are removed.
+$patch: delete
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: system
+---
+$patch: delete
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: manager-rolebinding
+---
+$patch: delete
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: leader-election-role
+---
+$patch: delete
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: leader-election-rolebinding
+---
+$patch: delete
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: proxy-role
+---
+$patch: delete
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: proxy-rolebinding
+---
+$patch: delete
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: metrics-reader
+---
+$patch: delete
+apiVersion: v1
+kind: Service
+metadata:
+  name: controller-manager-metrics-service
+  namespace: system
+{{- if .DaemonSet }}
+---
+# The agents are run by the DaemonSet of daemonset.yaml.
+$patch: delete
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+{{- end }}
+`
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Kustomization{}
+
+// Kustomization scaffolds a file that defines the kustomize configuration deploying the manager as an agent
+// reconciling the objects of a single namespace, with the RBAC rules of the manager granted in this namespace
+type Kustomization struct {
+	file.TemplateMixin
+	file.ProjectNameMixin
+
+	// DaemonSet runs an agent on every node with a DaemonSet instead of a single one with a Deployment
+	DaemonSet bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Kustomization) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "agent", "kustomization.yaml")
+	}
+
+	f.TemplateBody = agentKustomizationTemplate
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+//nolint:lll
+const agentKustomizationTemplate = `# Deploys the manager as an agent reconciling the objects of a single namespace, which must exist,
+# without leader election and with the permissions of the manager granted in this namespace only.
+# Set the namespace of each deployment of the agent with:
+#   cd config/agent && kustomize edit set namespace <namespace>
+namespace: {{ .ProjectName }}-agent
+
+# The ClusterRole of the manager, which is only bound in the namespace of the agent, is shared by
+# the agents of every namespace.
+namePrefix: {{ .ProjectName }}-agent-
+
+bases:
+- ../rbac
+- ../manager
+
+resources:
+- role_binding.yaml
+{{- if .DaemonSet }}
+- daemonset.yaml
+{{- end }}
+
+patchesStrategicMerge:
+- delete_patch.yaml
+{{- if not .DaemonSet }}
+- manager_patch.yaml
+{{- end }}
+`
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiservice
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &APIService{}
+
+// APIService scaffolds a file that registers the group version of a resource in the kube-apiserver
+type APIService struct {
+	file.TemplateMixin
+	file.ResourceMixin
+	file.ProjectNameMixin
+	file.NamespaceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *APIService) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "apiservice", "%[group]_%[version].yaml")
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = apiServiceTemplate
+
+	// The group version is already registered by the previous resources of the group version
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const apiServiceTemplate = `# The kube-apiserver does not verify the self-signed certificate of the aggregated API server.
+# To verify it, provide the certificate with the --tls-cert-file and --tls-private-key-file flags
+# of the API server, and its CA bundle in caBundle instead of insecureSkipTLSVerify.
+apiVersion: apiregistration.k8s.io/v1
+kind: APIService
+metadata:
+  name: {{ .Resource.Version }}.{{ .Resource.Domain }}
+spec:
+  group: {{ .Resource.Domain }}
+  version: {{ .Resource.Version }}
+  groupPriorityMinimum: 1000
+  versionPriority: 15
+  insecureSkipTLSVerify: true
+  service:
+    name: {{ .ProjectName }}-apiserver-service
+    namespace: {{ .Namespace }}
+`
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiservice
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &AuthReader{}
+
+// AuthReader scaffolds a file that defines the binding allowing the aggregated API server to read the
+// configuration of the authentication of the requests proxied by the kube-apiserver
+type AuthReader struct {
+	file.TemplateMixin
+	file.ProjectNameMixin
+	file.NamespaceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *AuthReader) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "apiservice", "auth_reader.yaml")
+	}
+
+	f.TemplateBody = authReaderTemplate
+
+	return nil
+}
+
+const authReaderTemplate = `apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: {{ .ProjectName }}-apiserver-auth-reader
+  namespace: kube-system
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: extension-apiserver-authentication-reader
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: {{ .Namespace }}
+`
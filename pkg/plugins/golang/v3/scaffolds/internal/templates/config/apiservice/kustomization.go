@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiservice
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Kustomization{}
+var _ file.Inserter = &Kustomization{}
+
+// Kustomization scaffolds a file that defines the kustomization scheme for the apiservice folder
+type Kustomization struct {
+	file.TemplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Kustomization) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "apiservice", "kustomization.yaml")
+	}
+
+	f.TemplateBody = fmt.Sprintf(kustomizationTemplate,
+		file.NewMarkerFor(f.Path, resourceMarker),
+	)
+
+	return nil
+}
+
+const resourceMarker = "apiservice"
+
+// GetMarkers implements file.Inserter
+func (f *Kustomization) GetMarkers() []file.Marker {
+	return []file.Marker{
+		file.NewMarkerFor(f.Path, resourceMarker),
+	}
+}
+
+const resourceCodeFragment = `- %s_%s.yaml
+`
+
+// GetCodeFragments implements file.Inserter
+func (f *Kustomization) GetCodeFragments() file.CodeFragmentsMap {
+	fragments := make(file.CodeFragmentsMap, 1)
+
+	// If resource is not being provided we are creating the file, not updating it
+	if f.Resource == nil {
+		return fragments
+	}
+
+	fragments[file.NewMarkerFor(f.Path, resourceMarker)] = []string{
+		fmt.Sprintf(resourceCodeFragment, f.Resource.Group, f.Resource.Version),
+	}
+
+	return fragments
+}
+
+var kustomizationTemplate = `# The objects whose names and namespaces are fixed, which the name prefix and the namespace of
+# config/default would override: this kustomization is deployed on its own by "make deploy".
+# The APIService objects register the API groups served by the aggregated API server in the
+# kube-apiserver, which proxies their requests to the apiserver-service.
+resources:
+- auth_reader.yaml
+%s
+`
@@ -27,6 +27,10 @@ var _ file.Template = &Certificate{}
 // Certificate scaffolds a file that defines the issuer CR and the certificate CR
 type Certificate struct {
 	file.TemplateMixin
+
+	// APIVersion is the API version of the issuer and the certificate, cert-manager.io/v1 requires cert-manager
+	// v1.0 and Kubernetes 1.16
+	APIVersion string
 }
 
 // SetTemplateDefaults implements file.Template
@@ -37,13 +41,22 @@ func (f *Certificate) SetTemplateDefaults() error {
 
 	f.TemplateBody = certManagerTemplate
 
+	if f.APIVersion == "" {
+		f.APIVersion = "cert-manager.io/v1"
+	}
+
 	return nil
 }
 
 const certManagerTemplate = `# The following manifests contain a self-signed issuer CR and a certificate CR.
 # More document can be found at https://docs.cert-manager.io
+{{- if eq .APIVersion "cert-manager.io/v1" }}
 # WARNING: Targets CertManager v1.0. Check https://cert-manager.io/docs/installation/upgrading/ for breaking changes.
-apiVersion: cert-manager.io/v1
+{{- else }}
+# WARNING: Targets the legacy releases of CertManager v0.11 to v1.6 for the clusters older than 1.16.
+# Check https://cert-manager.io/docs/installation/upgrading/ for breaking changes.
+{{- end }}
+apiVersion: {{ .APIVersion }}
 kind: Issuer
 metadata:
   name: selfsigned-issuer
@@ -51,7 +64,7 @@ metadata:
 spec:
   selfSigned: {}
 ---
-apiVersion: cert-manager.io/v1
+apiVersion: {{ .APIVersion }}
 kind: Certificate
 metadata:
   name: serving-cert  # this name should match the one appeared in kustomizeconfig.yaml
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &DefaultsKustomization{}
+
+// DefaultsKustomization scaffolds a file that defines the kustomize component that deploys the ConfigMap of the
+// operator-wide defaults of the controllers
+type DefaultsKustomization struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *DefaultsKustomization) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "defaults", "kustomization.yaml")
+	}
+
+	f.TemplateBody = defaultsKustomizationTemplate
+
+	// If file exists (ex. because another controller already loads the defaults), skip creation.
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+//nolint:lll
+const defaultsKustomizationTemplate = `# This component deploys the ConfigMap of the operator-wide defaults of the controllers, loaded by
+# the internal/defaults package, and allows the manager to read the ConfigMaps of its namespace.
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+
+resources:
+- defaults.yaml
+- role.yaml
+`
+
+var _ file.Template = &DefaultsConfigMap{}
+
+// DefaultsConfigMap scaffolds a file that defines the ConfigMap of the operator-wide defaults of the controllers
+type DefaultsConfigMap struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *DefaultsConfigMap) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "defaults", "defaults.yaml")
+	}
+
+	f.TemplateBody = defaultsConfigMapTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+//nolint:lll
+const defaultsConfigMapTemplate = `# The operator-wide defaults of the controllers, whose fields are those of the Defaults type of
+# internal/defaults/defaults.go. The manager loads the changes without restarting.
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: defaults
+  namespace: system
+data:
+  defaults.yaml: |
+    replicas: 1
+`
+
+var _ file.Template = &DefaultsRole{}
+
+// DefaultsRole scaffolds a file that defines the role granting the manager the read of the ConfigMaps of its namespace
+type DefaultsRole struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *DefaultsRole) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "defaults", "role.yaml")
+	}
+
+	f.TemplateBody = defaultsRoleTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const defaultsRoleTemplate = `# The defaults are read from a cache of the ConfigMaps of the namespace of the manager.
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: defaults-reader-role
+  namespace: system
+rules:
+- apiGroups:
+  - ""
+  resources:
+  - configmaps
+  verbs:
+  - get
+  - list
+  - watch
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: defaults-reader-rolebinding
+  namespace: system
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: defaults-reader-role
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: system
+`
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package kdefault
+package components
 
 import (
 	"path/filepath"
@@ -35,7 +35,7 @@ type WebhookCAInjectionPatch struct {
 // SetTemplateDefaults implements file.Template
 func (f *WebhookCAInjectionPatch) SetTemplateDefaults() error {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "default", "webhookcainjection_patch.yaml")
+		f.Path = filepath.Join("config", "components", "certmanager", "webhookcainjection_patch.yaml")
 	}
 
 	f.TemplateBody = injectCAPatchTemplate
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package components
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &HAPodDisruptionBudget{}
+
+// HAPodDisruptionBudget scaffolds a file that defines the PodDisruptionBudget of the manager
+type HAPodDisruptionBudget struct {
+	file.TemplateMixin
+
+	// APIVersion is the API version of the PodDisruptionBudget, policy/v1 requires Kubernetes 1.21
+	APIVersion string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *HAPodDisruptionBudget) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "ha", "pdb.yaml")
+	}
+
+	f.TemplateBody = haPodDisruptionBudgetTemplate
+
+	if f.APIVersion == "" {
+		f.APIVersion = "policy/v1beta1"
+	}
+
+	return nil
+}
+
+const haPodDisruptionBudgetTemplate = `apiVersion: {{ .APIVersion }}
+kind: PodDisruptionBudget
+metadata:
+  name: controller-manager
+  namespace: system
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      control-plane: controller-manager
+`
@@ -0,0 +1,249 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var (
+	_ file.Template = &MetricsExposureKustomization{}
+	_ file.Template = &MetricsExposureKustomizeConfig{}
+	_ file.Template = &MetricsIngress{}
+	_ file.Template = &MetricsHTTPRoute{}
+	_ file.Template = &MetricsReader{}
+)
+
+// The files of the component are generated from the options of init or edit, and scaffolded again by
+// edit to change them.
+func metricsExposurePath(name string) string {
+	return filepath.Join("config", "components", "metrics-exposure", name)
+}
+
+// MetricsExposureKustomization scaffolds a file that defines the kustomize component that exposes the
+// metrics endpoint of the manager outside of the cluster
+type MetricsExposureKustomization struct {
+	file.TemplateMixin
+
+	// HTTPRoute exposes the metrics endpoint with a Gateway API HTTPRoute instead of an Ingress
+	HTTPRoute bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *MetricsExposureKustomization) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = metricsExposurePath("kustomization.yaml")
+	}
+
+	f.TemplateBody = metricsExposureKustomizationTemplate
+
+	f.IfExistsAction = file.Overwrite
+
+	return nil
+}
+
+const metricsExposureKustomizationTemplate = `# This component exposes the metrics endpoint of the manager outside of the cluster, for the
+# Prometheus servers running outside of it. The endpoint stays behind the auth of kube-rbac-proxy:
+# they scrape it with the token of the metrics-reader service account.
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+
+resources:
+{{- if .HTTPRoute }}
+- httproute.yaml
+{{- else }}
+- ingress.yaml
+{{- end }}
+- metrics_reader.yaml
+
+configurations:
+- kustomizeconfig.yaml
+`
+
+// MetricsExposureKustomizeConfig scaffolds a file that configures the name references of the metrics
+// exposure component
+type MetricsExposureKustomizeConfig struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *MetricsExposureKustomizeConfig) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = metricsExposurePath("kustomizeconfig.yaml")
+	}
+
+	f.TemplateBody = metricsExposureKustomizeConfigTemplate
+
+	f.IfExistsAction = file.Overwrite
+
+	return nil
+}
+
+const metricsExposureKustomizeConfigTemplate = `# This file is for teaching kustomize how to substitute the name of the metrics service
+# in the backends of the Ingress and of the HTTPRoute
+nameReference:
+- kind: Service
+  version: v1
+  fieldSpecs:
+  - kind: Ingress
+    group: networking.k8s.io
+    path: spec/rules/http/paths/backend/service/name
+  - kind: HTTPRoute
+    group: gateway.networking.k8s.io
+    path: spec/rules/backendRefs/name
+`
+
+// MetricsIngress scaffolds a file that defines the Ingress exposing the metrics endpoint of the manager
+type MetricsIngress struct {
+	file.TemplateMixin
+
+	// Hostname is the host name of the metrics endpoint
+	Hostname string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *MetricsIngress) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = metricsExposurePath("ingress.yaml")
+	}
+
+	f.TemplateBody = metricsIngressTemplate
+
+	f.IfExistsAction = file.Overwrite
+
+	return nil
+}
+
+const metricsIngressTemplate = `# The metrics service serves HTTPS, with the self-signed certificate of kube-rbac-proxy.
+# TODO(user): set the annotation telling your ingress controller to reach it with HTTPS, the
+# one of ingress-nginx is set below, and the ingressClassName of your ingress controller.
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: metrics
+  namespace: system
+  annotations:
+    nginx.ingress.kubernetes.io/backend-protocol: HTTPS
+spec:
+  # ingressClassName: nginx
+  tls:
+  - hosts:
+    - {{ .Hostname }}
+    # TODO(user): provide the certificate of the host name in this secret, e.g. with cert-manager.
+    secretName: metrics-tls
+  rules:
+  - host: {{ .Hostname }}
+    http:
+      paths:
+      - path: /metrics
+        pathType: Exact
+        backend:
+          service:
+            name: controller-manager-metrics-service
+            port:
+              name: https
+`
+
+// MetricsHTTPRoute scaffolds a file that defines the Gateway API HTTPRoute exposing the metrics endpoint
+// of the manager
+type MetricsHTTPRoute struct {
+	file.TemplateMixin
+
+	// Hostname is the host name of the metrics endpoint
+	Hostname string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *MetricsHTTPRoute) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = metricsExposurePath("httproute.yaml")
+	}
+
+	f.TemplateBody = metricsHTTPRouteTemplate
+
+	f.IfExistsAction = file.Overwrite
+
+	return nil
+}
+
+const metricsHTTPRouteTemplate = `# The metrics service serves HTTPS, with the self-signed certificate of kube-rbac-proxy.
+# TODO(user): attach the route to a Gateway with an HTTPS listener for the host name, and let it
+# reach the service with HTTPS, e.g. with a BackendTLSPolicy if your Gateway supports it.
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: metrics
+  namespace: system
+spec:
+  parentRefs:
+  - name: gateway
+    # namespace: gateway-system
+  hostnames:
+  - {{ .Hostname }}
+  rules:
+  - matches:
+    - path:
+        type: Exact
+        value: /metrics
+    backendRefs:
+    - name: controller-manager-metrics-service
+      port: 8443
+`
+
+// MetricsReader scaffolds a file that defines the service account whose token scrapes the metrics
+// endpoint of the manager
+type MetricsReader struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *MetricsReader) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = metricsExposurePath("metrics_reader.yaml")
+	}
+
+	f.TemplateBody = metricsReaderTemplate
+
+	f.IfExistsAction = file.Overwrite
+
+	return nil
+}
+
+const metricsReaderTemplate = `# The Prometheus servers outside of the cluster scrape the metrics endpoint with a token of this
+# service account, created with "kubectl create token". kube-rbac-proxy authorizes it with the
+# metrics-reader role.
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: metrics-reader
+  namespace: system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: metrics-reader
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: metrics-reader
+subjects:
+- kind: ServiceAccount
+  name: metrics-reader
+  namespace: system
+`
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package components
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &PrometheusKustomization{}
+
+// PrometheusKustomization scaffolds a file that defines the kustomize component that enables the prometheus monitor
+type PrometheusKustomization struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *PrometheusKustomization) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "prometheus", "kustomization.yaml")
+	}
+
+	f.TemplateBody = prometheusKustomizationTemplate
+
+	return nil
+}
+
+//nolint:lll
+const prometheusKustomizationTemplate = `# This component creates a ServiceMonitor so that the prometheus operator scrapes the metrics
+# of the manager.
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+
+resources:
+- ../../prometheus
+`
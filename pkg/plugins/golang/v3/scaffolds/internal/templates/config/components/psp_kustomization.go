@@ -0,0 +1,57 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &PSPKustomization{}
+
+// PSPKustomization scaffolds a file that defines the kustomize component that grants a PodSecurityPolicy to the
+// manager
+type PSPKustomization struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *PSPKustomization) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "psp", "kustomization.yaml")
+	}
+
+	f.TemplateBody = pspKustomizationTemplate
+
+	return nil
+}
+
+//nolint:lll
+const pspKustomizationTemplate = `# This component grants the manager a PodSecurityPolicy complying with its Pod Security Standards
+# profile, for the clusters older than 1.25 enforcing PodSecurityPolicies. The newer clusters do not
+# serve them, and enforce the pod-security.kubernetes.io labels of the namespace instead.
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+
+resources:
+- policy.yaml
+- role.yaml
+
+configurations:
+- kustomizeconfig.yaml
+`
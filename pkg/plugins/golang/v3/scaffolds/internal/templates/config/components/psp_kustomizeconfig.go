@@ -0,0 +1,53 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &PSPKustomizeConfig{}
+
+// PSPKustomizeConfig scaffolds a file that teaches kustomize to update the name of the PodSecurityPolicy used by
+// the manager
+type PSPKustomizeConfig struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *PSPKustomizeConfig) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "psp", "kustomizeconfig.yaml")
+	}
+
+	f.TemplateBody = pspKustomizeConfigTemplate
+
+	return nil
+}
+
+const pspKustomizeConfigTemplate = `# This configuration is for teaching kustomize how to update the name of the PodSecurityPolicy
+# referred to by the role granting its use
+nameReference:
+- kind: PodSecurityPolicy
+  group: policy
+  fieldSpecs:
+  - kind: ClusterRole
+    group: rbac.authorization.k8s.io
+    path: rules/resourceNames
+`
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &PSPPolicy{}
+
+// PSPPolicy scaffolds a file that defines the PodSecurityPolicy of the manager
+type PSPPolicy struct {
+	file.TemplateMixin
+
+	// PodSecurity is the Pod Security Standards profile the policy enforces, either restricted or baseline
+	PodSecurity string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *PSPPolicy) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "psp", "policy.yaml")
+	}
+
+	f.TemplateBody = pspPolicyTemplate
+
+	if f.PodSecurity == "" {
+		f.PodSecurity = "restricted"
+	}
+
+	return nil
+}
+
+const pspPolicyTemplate = `apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: controller-manager
+  annotations:
+    seccomp.security.alpha.kubernetes.io/allowedProfileNames: runtime/default
+    seccomp.security.alpha.kubernetes.io/defaultProfileName: runtime/default
+spec:
+  privileged: false
+  allowPrivilegeEscalation: false
+{{- if eq .PodSecurity "restricted" }}
+  requiredDropCapabilities:
+  - ALL
+{{- end }}
+  volumes:
+  - configMap
+  - downwardAPI
+  - emptyDir
+  - projected
+  - secret
+  hostNetwork: false
+  hostIPC: false
+  hostPID: false
+  runAsUser:
+{{- if eq .PodSecurity "restricted" }}
+    rule: MustRunAsNonRoot
+{{- else }}
+    rule: RunAsAny
+{{- end }}
+  seLinux:
+    rule: RunAsAny
+  supplementalGroups:
+    rule: RunAsAny
+  fsGroup:
+    rule: RunAsAny
+`
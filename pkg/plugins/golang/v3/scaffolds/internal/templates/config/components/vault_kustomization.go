@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &VaultKustomization{}
+
+// VaultKustomization scaffolds a file that defines the kustomize component that retrieves the webhook
+// certificates from Vault
+type VaultKustomization struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *VaultKustomization) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "vault", "kustomization.yaml")
+	}
+
+	f.TemplateBody = vaultKustomizationTemplate
+
+	// If file exists (ex. because a webhook was already created), skip creation.
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const vaultKustomizationTemplate = `# This component makes the Vault agent injector write the webhook serving certificate, issued by the
+# PKI secrets engine of Vault, in the directory read by the manager. The agent renews the certificate,
+# which the manager reloads. It requires the webhook component.
+#
+# The Vault agent injector must be installed in the cluster, with a Vault role allowing the service
+# account of the manager to issue certificates for the webhook service, see manager_vault_patch.yaml.
+# The webhook configurations and the conversion webhooks of the CRDs must trust the CA of the PKI
+# secrets engine: set their caBundle to the output of
+#   vault read -field=certificate pki/cert/ca | base64 | tr -d '\n'
+apiVersion: kustomize.config.k8s.io/v1alpha1
+kind: Component
+
+patchesStrategicMerge:
+- manager_vault_patch.yaml
+`
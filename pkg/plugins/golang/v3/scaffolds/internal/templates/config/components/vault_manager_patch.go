@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package components
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &VaultManagerPatch{}
+
+// VaultManagerPatch scaffolds a file that defines the patch that makes the Vault agent injector write the
+// webhook certificates for the manager
+type VaultManagerPatch struct {
+	file.TemplateMixin
+	file.ProjectNameMixin
+	file.NamespaceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *VaultManagerPatch) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "components", "vault", "manager_vault_patch.yaml")
+	}
+
+	f.TemplateBody = vaultManagerPatchTemplate
+
+	// If file exists (ex. because a webhook was already created), skip creation.
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+//nolint:lll
+const vaultManagerPatchTemplate = `# The Vault agent injector writes tls.crt and tls.key in /vault/secrets, the default value of the
+# --webhook-cert-dir flag of the manager. They are rendered from the same request to the PKI secrets
+# engine, which the agent sends once.
+# TODO(user): update the Vault role, the path of the PKI secrets engine and its role, and the common
+# name of the certificate if the namespace or the name prefix of config/default/kustomization.yaml change.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+spec:
+  template:
+    metadata:
+      annotations:
+        vault.hashicorp.com/agent-inject: "true"
+        vault.hashicorp.com/role: {{ .ProjectName }}-controller-manager
+        vault.hashicorp.com/agent-inject-secret-tls.crt: pki/issue/{{ .ProjectName }}-webhook
+        vault.hashicorp.com/agent-inject-template-tls.crt: |
+          {{ "{{-" }} with secret "pki/issue/{{ .ProjectName }}-webhook" "common_name={{ .ProjectName }}-webhook-service.{{ .Namespace }}.svc" {{ "-}}" }}
+          {{ "{{" }} .Data.certificate {{ "}}" }}
+          {{ "{{-" }} end {{ "}}" }}
+        vault.hashicorp.com/agent-inject-secret-tls.key: pki/issue/{{ .ProjectName }}-webhook
+        vault.hashicorp.com/agent-inject-template-tls.key: |
+          {{ "{{-" }} with secret "pki/issue/{{ .ProjectName }}-webhook" "common_name={{ .ProjectName }}-webhook-service.{{ .Namespace }}.svc" {{ "-}}" }}
+          {{ "{{" }} .Data.private_key {{ "}}" }}
+          {{ "{{-" }} end {{ "}}" }}
+`
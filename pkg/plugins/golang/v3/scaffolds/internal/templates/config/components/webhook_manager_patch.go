@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package kdefault
+package components
 
 import (
 	"path/filepath"
@@ -28,13 +28,16 @@ var _ file.Template = &ManagerWebhookPatch{}
 type ManagerWebhookPatch struct {
 	file.TemplateMixin
 
+	// Vault indicates that the serving certificate is written by the Vault agent instead of mounted from a secret
+	Vault bool
+
 	Force bool
 }
 
 // SetTemplateDefaults implements file.Template
 func (f *ManagerWebhookPatch) SetTemplateDefaults() error {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "default", "manager_webhook_patch.yaml")
+		f.Path = filepath.Join("config", "components", "webhook", "manager_webhook_patch.yaml")
 	}
 
 	f.TemplateBody = managerWebhookPatchTemplate
@@ -63,6 +66,7 @@ spec:
         - containerPort: 9443
           name: webhook-server
           protocol: TCP
+{{- if not .Vault }}
         volumeMounts:
         - mountPath: /tmp/k8s-webhook-server/serving-certs
           name: cert
@@ -72,4 +76,5 @@ spec:
         secret:
           defaultMode: 420
           secretName: webhook-server-cert
+{{- end }}
 `
@@ -30,6 +30,11 @@ var _ file.Inserter = &Kustomization{}
 type Kustomization struct {
 	file.TemplateMixin
 	file.ResourceMixin
+
+	// ConversionWebhook adds the patch enabling the conversion webhook of the CRD
+	ConversionWebhook bool
+	// CAInjection adds the patch injecting the CA of the conversion webhook in the CRD
+	CAInjection bool
 }
 
 // SetTemplateDefaults implements file.Template
@@ -66,9 +71,9 @@ func (f *Kustomization) GetMarkers() []file.Marker {
 const (
 	resourceCodeFragment = `- bases/%s_%s.yaml
 `
-	webhookPatchCodeFragment = `#- patches/webhook_in_%s.yaml
+	webhookPatchCodeFragment = `- patches/webhook_in_%s.yaml
 `
-	caInjectionPatchCodeFragment = `#- patches/cainjection_in_%s.yaml
+	caInjectionPatchCodeFragment = `- patches/cainjection_in_%s.yaml
 `
 )
 
@@ -80,13 +85,17 @@ func (f *Kustomization) GetCodeFragments() file.CodeFragmentsMap {
 	res := make([]string, 0)
 	res = append(res, fmt.Sprintf(resourceCodeFragment, f.Resource.Domain, f.Resource.Plural))
 
-	// Generate resource code fragments
+	// Generate the conversion patch code fragments, once the CRD has a conversion webhook
 	webhookPatch := make([]string, 0)
-	webhookPatch = append(webhookPatch, fmt.Sprintf(webhookPatchCodeFragment, f.Resource.Plural))
+	if f.ConversionWebhook {
+		webhookPatch = append(webhookPatch, fmt.Sprintf(webhookPatchCodeFragment, f.Resource.Plural))
+	}
 
-	// Generate resource code fragments
+	// Generate the CA injection patch code fragments, once the CA of its conversion webhook is injected
 	caInjectionPatch := make([]string, 0)
-	caInjectionPatch = append(caInjectionPatch, fmt.Sprintf(caInjectionPatchCodeFragment, f.Resource.Plural))
+	if f.CAInjection {
+		caInjectionPatch = append(caInjectionPatch, fmt.Sprintf(caInjectionPatchCodeFragment, f.Resource.Plural))
+	}
 
 	// Only store code fragments in the map if the slices are non-empty
 	if len(res) != 0 {
@@ -109,12 +118,11 @@ resources:
 %s
 
 patchesStrategicMerge:
-# [WEBHOOK] To enable webhook, uncomment all the sections with [WEBHOOK] prefix.
-# patches here are for enabling the conversion webhook for each CRD
+# patches here are for enabling the conversion webhook of the CRDs with several versions and a conversion
+# webhook, they are added by kubebuilder from the resources of the PROJECT file
 %s
 
-# [CERTMANAGER] To enable webhook, uncomment all the sections with [CERTMANAGER] prefix.
-# patches here are for enabling the CA injection for each CRD
+# patches here are for enabling the CA injection in the conversion webhook of these CRDs by cert-manager
 %s
 
 # the following config is for teaching kustomize how to do kustomization for CRDs.
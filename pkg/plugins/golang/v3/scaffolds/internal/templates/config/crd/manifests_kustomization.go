@@ -0,0 +1,49 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crd
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ManifestsKustomization{}
+
+// ManifestsKustomization scaffolds a file that defines the kustomization scheme for the crd folder
+// of projects whose CRDs are not generated
+type ManifestsKustomization struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ManifestsKustomization) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "crd", "kustomization.yaml")
+	}
+
+	f.TemplateBody = manifestsKustomizationTemplate
+
+	return nil
+}
+
+const manifestsKustomizationTemplate = `# This kustomization.yaml lists the CRDs of the operator.
+# TODO(user): copy the CRDs to the bases folder, e.g. from the config/crd/bases folder generated
+# by "make manifests" in the repository of the operator, and add them below.
+resources:
+#- bases/<group>.<domain>_<plural>.yaml
+`
@@ -66,6 +66,9 @@ spec:
         namespace: system
         name: webhook-service
         path: /convert
+    # The conversion webhook of controller-runtime only handles v1beta1 ConversionReviews.
+    conversionReviewVersions:
+    - v1beta1
     {{- else }}
     webhook:
       clientConfig:
@@ -73,5 +76,8 @@ spec:
           namespace: system
           name: webhook-service
           path: /convert
+      # The conversion webhook of controller-runtime only handles v1beta1 ConversionReviews.
+      conversionReviewVersions:
+      - v1beta1
     {{- end }}
 `
@@ -28,7 +28,25 @@ var _ file.Template = &Kustomization{}
 type Kustomization struct {
 	file.TemplateMixin
 	file.ProjectNameMixin
+	file.NamespaceMixin
 	file.ComponentConfigMixin
+
+	// ImagePullSecret indicates whether to patch the manager with an image pull secret
+	ImagePullSecret bool
+
+	// Vault indicates that the serving certificates of the webhooks are retrieved from Vault
+	Vault bool
+
+	// AggregatedAPIServer indicates that the manager is an aggregated API server, which serves its own API
+	// instead of CRDs and protects its /metrics endpoint itself
+	AggregatedAPIServer bool
+
+	// PodSecurityPolicy indicates that the project supports the clusters enforcing PodSecurityPolicies, which
+	// enable the psp component
+	PodSecurityPolicy bool
+
+	// MetricsExposure enables the component exposing the metrics endpoint outside of the cluster
+	MetricsExposure bool
 }
 
 // SetTemplateDefaults implements file.Template
@@ -45,7 +63,7 @@ func (f *Kustomization) SetTemplateDefaults() error {
 }
 
 const kustomizeTemplate = `# Adds namespace to all resources.
-namespace: {{ .ProjectName }}-system
+namespace: {{ .Namespace }}
 
 # Value of this field is prepended to the
 # names of all resources, e.g. a deployment named
@@ -59,18 +77,46 @@ namePrefix: {{ .ProjectName }}-
 #  someName: someValue
 
 bases:
+{{- if .AggregatedAPIServer }}
+- ../apiserver
+{{- else }}
 - ../crd
+{{- end }}
 - ../rbac
 - ../manager
-# [WEBHOOK] To enable webhook, uncomment all the sections with [WEBHOOK] prefix including the one in
-# crd/kustomization.yaml
-#- ../webhook
-# [CERTMANAGER] To enable cert-manager, uncomment all sections with 'CERTMANAGER'. 'WEBHOOK' components are required.
-#- ../certmanager
-# [PROMETHEUS] To enable prometheus monitor, uncomment all sections with 'PROMETHEUS'.
-#- ../prometheus
+
+# Optional pieces of the deployment, each of them can be enabled by uncommenting one line.
+components:
+{{- if not .AggregatedAPIServer }}
+# [WEBHOOK] To enable webhook, uncomment the following line.
+#- ../components/webhook
+{{- if .Vault }}
+# [VAULT] To retrieve the webhook serving certificate from Vault, uncomment the following line.
+# The 'webhook' component is required.
+#- ../components/vault
+{{- else }}
+# [CERTMANAGER] To enable cert-manager, uncomment the following line. The 'webhook' component is required.
+#- ../components/certmanager
+{{- end }}
+{{- end }}
+# [PROMETHEUS] To enable prometheus monitor, uncomment the following line.
+#- ../components/prometheus
+{{- if .MetricsExposure }}
+# [METRICS-EXPOSURE] Exposes the metrics endpoint outside of the cluster, behind auth.
+- ../components/metrics-exposure
+{{- end }}
+{{- if not .AggregatedAPIServer }}
+# [HA] To run several replicas of the manager with leader election, uncomment the following line.
+#- ../components/ha
+{{- end }}
+{{- if .PodSecurityPolicy }}
+# [PSP] To grant the manager a PodSecurityPolicy on the clusters older than 1.25 enforcing them, uncomment
+# the following line.
+#- ../components/psp
+{{- end }}
 
 patchesStrategicMerge:
+{{- if not .AggregatedAPIServer }}
 # Protect the /metrics endpoint by putting it behind auth.
 # If you want your controller-manager to expose the /metrics
 # endpoint w/o any authn/z, please comment the following line.
@@ -79,43 +125,10 @@ patchesStrategicMerge:
 # Mount the controller config file for loading manager configurations
 # through a ComponentConfig type
 {{ if not .ComponentConfig }}#{{ end }}- manager_config_patch.yaml
+{{- end }}
+{{- if .ImagePullSecret }}
 
-# [WEBHOOK] To enable webhook, uncomment all the sections with [WEBHOOK] prefix including the one in
-# crd/kustomization.yaml
-#- manager_webhook_patch.yaml
-
-# [CERTMANAGER] To enable cert-manager, uncomment all sections with 'CERTMANAGER'.
-# Uncomment 'CERTMANAGER' sections in crd/kustomization.yaml to enable the CA injection in the admission webhooks.
-# 'CERTMANAGER' needs to be enabled to use ca injection
-#- webhookcainjection_patch.yaml
-
-# the following config is for teaching kustomize how to do var substitution
-vars:
-# [CERTMANAGER] To enable cert-manager, uncomment all sections with 'CERTMANAGER' prefix.
-#- name: CERTIFICATE_NAMESPACE # namespace of the certificate CR
-#  objref:
-#    kind: Certificate
-#    group: cert-manager.io
-#    version: v1
-#    name: serving-cert # this name should match the one in certificate.yaml
-#  fieldref:
-#    fieldpath: metadata.namespace
-#- name: CERTIFICATE_NAME
-#  objref:
-#    kind: Certificate
-#    group: cert-manager.io
-#    version: v1
-#    name: serving-cert # this name should match the one in certificate.yaml
-#- name: SERVICE_NAMESPACE # namespace of the service
-#  objref:
-#    kind: Service
-#    version: v1
-#    name: webhook-service
-#  fieldref:
-#    fieldpath: metadata.namespace
-#- name: SERVICE_NAME
-#  objref:
-#    kind: Service
-#    version: v1
-#    name: webhook-service
+# Pull the manager image from a private registry using an image pull secret
+- manager_image_pull_secret_patch.yaml
+{{- end }}
 `
@@ -28,6 +28,10 @@ var _ file.Template = &ManagerAuthProxyPatch{}
 type ManagerAuthProxyPatch struct {
 	file.TemplateMixin
 	file.ComponentConfigMixin
+	file.IPv6Mixin
+
+	// PodSecurity is the Pod Security Standards profile the proxy complies with, either restricted or baseline
+	PodSecurity string
 }
 
 // SetTemplateDefaults implements file.Template
@@ -38,6 +42,10 @@ func (f *ManagerAuthProxyPatch) SetTemplateDefaults() error {
 
 	f.TemplateBody = kustomizeAuthProxyPatchTemplate
 
+	if f.PodSecurity == "" {
+		f.PodSecurity = "restricted"
+	}
+
 	f.IfExistsAction = file.Error
 
 	return nil
@@ -57,10 +65,18 @@ spec:
       - name: kube-rbac-proxy
         image: gcr.io/kubebuilder/kube-rbac-proxy:v0.5.0
         args:
-        - "--secure-listen-address=0.0.0.0:8443"
-        - "--upstream=http://127.0.0.1:8080/"
+        - "--secure-listen-address=:8443"
+        - "--upstream=http://{{ if .IPv6 }}[::1]{{ else }}127.0.0.1{{ end }}:8080/"
         - "--logtostderr=true"
         - "--v=10"
+        securityContext:
+          allowPrivilegeEscalation: false
+{{- if eq .PodSecurity "restricted" }}
+          capabilities:
+            drop:
+            - ALL
+{{- end }}
+          readOnlyRootFilesystem: true
         ports:
         - containerPort: 8443
           name: https
@@ -68,7 +84,7 @@ spec:
       - name: manager
         args:
         - "--health-probe-bind-address=:8081"
-        - "--metrics-bind-address=127.0.0.1:8080"
+        - "--metrics-bind-address={{ if .IPv6 }}[::1]{{ else }}127.0.0.1{{ end }}:8080"
         - "--leader-elect"
 {{- end }}
 `
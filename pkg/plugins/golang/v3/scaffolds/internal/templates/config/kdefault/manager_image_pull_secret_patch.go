@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kdefault
+
+import (
+	"errors"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ManagerImagePullSecretPatch{}
+
+// ManagerImagePullSecretPatch scaffolds a file that defines the patch that sets the manager image pull secret
+type ManagerImagePullSecretPatch struct {
+	file.TemplateMixin
+
+	// ImagePullSecret is the name of the secret used to pull the manager image
+	ImagePullSecret string
+}
+
+// Validate implements file.RequiresValidation
+func (f *ManagerImagePullSecretPatch) Validate() error {
+	if f.ImagePullSecret == "" {
+		return errors.New("image pull secret name is required")
+	}
+
+	return nil
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ManagerImagePullSecretPatch) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "default", "manager_image_pull_secret_patch.yaml")
+	}
+
+	f.TemplateBody = managerImagePullSecretPatchTemplate
+
+	return nil
+}
+
+const managerImagePullSecretPatchTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+spec:
+  template:
+    spec:
+      imagePullSecrets:
+      - name: {{ .ImagePullSecret }}
+`
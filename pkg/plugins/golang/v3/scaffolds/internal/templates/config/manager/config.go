@@ -31,6 +31,16 @@ type Config struct {
 
 	// Image is controller manager image name
 	Image string
+
+	// PodSecurity is the Pod Security Standards profile the manager complies with, either restricted or baseline
+	PodSecurity string
+	// SeccompAnnotation sets the seccomp profile of the manager with the annotation read by the clusters older
+	// than 1.19 too
+	SeccompAnnotation bool
+
+	// AggregatedAPIServer indicates that the manager is an aggregated API server, which serves its API and its
+	// health checks on the port 8443
+	AggregatedAPIServer bool
 }
 
 // SetTemplateDefaults implements file.Template
@@ -41,6 +51,10 @@ func (f *Config) SetTemplateDefaults() error {
 
 	f.TemplateBody = configTemplate
 
+	if f.PodSecurity == "" {
+		f.PodSecurity = "restricted"
+	}
+
 	return nil
 }
 
@@ -49,6 +63,7 @@ kind: Namespace
 metadata:
   labels:
     control-plane: controller-manager
+    pod-security.kubernetes.io/enforce: {{ .PodSecurity }}
   name: system
 ---
 apiVersion: apps/v1
@@ -65,22 +80,65 @@ spec:
   replicas: 1
   template:
     metadata:
+{{- if .SeccompAnnotation }}
+      annotations:
+        seccomp.security.alpha.kubernetes.io/pod: runtime/default
+{{- end }}
       labels:
         control-plane: controller-manager
     spec:
       securityContext:
+{{- if eq .PodSecurity "restricted" }}
+        runAsNonRoot: true
         runAsUser: 65532
+{{- end }}
+        seccompProfile:
+          type: RuntimeDefault
       containers:
       - command:
         - /manager
-{{- if not .ComponentConfig }}
+{{- if not (or .ComponentConfig .AggregatedAPIServer) }}
         args:
         - --leader-elect
 {{- end }}
         image: {{ .Image }}
         name: manager
+{{- if .AggregatedAPIServer }}
+        ports:
+        - containerPort: 8443
+          name: https
+{{- end }}
         securityContext:
           allowPrivilegeEscalation: false
+{{- if eq .PodSecurity "restricted" }}
+          capabilities:
+            drop:
+            - ALL
+{{- end }}
+          readOnlyRootFilesystem: true
+{{- if .AggregatedAPIServer }}
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: 8443
+            scheme: HTTPS
+          initialDelaySeconds: 15
+          periodSeconds: 20
+        readinessProbe:
+          httpGet:
+            path: /healthz
+            port: 8443
+            scheme: HTTPS
+          initialDelaySeconds: 5
+          periodSeconds: 10
+        resources:
+          limits:
+            cpu: 200m
+            memory: 200Mi
+          requests:
+            cpu: 100m
+            memory: 100Mi
+{{- else }}
         livenessProbe:
           httpGet:
             path: /healthz
@@ -100,5 +158,6 @@ spec:
           requests:
             cpu: 100m
             memory: 20Mi
+{{- end }}
       terminationGracePeriodSeconds: 10
 `
@@ -29,6 +29,11 @@ type ControllerManagerConfig struct {
 	file.TemplateMixin
 	file.DomainMixin
 	file.RepositoryMixin
+	file.IPv6Mixin
+
+	// WebhookCertDir is the directory of the serving certificates of the webhooks, when they are not
+	// mounted in the default directory of controller-runtime
+	WebhookCertDir string
 }
 
 // SetTemplateDefaults implements input.Template
@@ -49,9 +54,12 @@ kind: ControllerManagerConfig
 health:
   healthProbeBindAddress: :8081
 metrics:
-  bindAddress: 127.0.0.1:8080
+  bindAddress: {{ if .IPv6 }}"[::1]:8080"{{ else }}127.0.0.1:8080{{ end }}
 webhook:
   port: 9443
+{{- if .WebhookCertDir }}
+  certDir: {{ .WebhookCertDir }}
+{{- end }}
 leaderElection:
   leaderElect: true
   resourceName: {{ hashFNV .Repo }}.{{ .Domain }}
@@ -27,6 +27,11 @@ var _ file.Template = &Kustomization{}
 // Kustomization scaffolds a file that defines the kustomization scheme for the manager folder
 type Kustomization struct {
 	file.TemplateMixin
+
+	// ImageRepo is the repository the controller image is replaced with, if any
+	ImageRepo string
+	// ImageTag is the tag the controller image is replaced with
+	ImageTag string
 }
 
 // SetTemplateDefaults implements file.Template
@@ -52,4 +57,11 @@ configMapGenerator:
 - name: manager-config
   files:
   - controller_manager_config.yaml
+{{- if .ImageRepo }}
+
+images:
+- name: controller
+  newName: {{ .ImageRepo }}
+  newTag: {{ .ImageTag }}
+{{- end }}
 `
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package overlays
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Kustomization{}
+
+// Kustomization scaffolds a file that defines the kustomize overlay of an environment, deploying config/default
+// with the image tag, the number of replicas and the log level of the manager in the environment
+type Kustomization struct {
+	file.TemplateMixin
+
+	// Env is the name of the environment, which is the directory of the overlay in config/overlays
+	Env string
+	// ImageName is the name of the manager image of config/manager
+	ImageName string
+	// ImageTag is the tag of the manager image deployed in the environment
+	ImageTag string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Kustomization) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "overlays", f.Env, "kustomization.yaml")
+	}
+
+	f.TemplateBody = overlayKustomizationTemplate
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+const overlayKustomizationTemplate = `# Overlay of the {{ .Env }} environment, deployed with "make deploy-{{ .Env }}".
+bases:
+- ../../default
+
+# The tag of the manager image deployed in the {{ .Env }} environment.
+images:
+- name: {{ .ImageName }}
+  newTag: {{ .ImageTag }}
+
+# The number of replicas and the log level of the manager in the {{ .Env }} environment.
+patchesStrategicMerge:
+- manager_patch.yaml
+`
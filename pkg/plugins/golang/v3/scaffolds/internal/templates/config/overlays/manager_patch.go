@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package overlays
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ManagerPatch{}
+
+// ManagerPatch scaffolds a file that defines the patch setting the number of replicas and the log level of the
+// manager in an environment
+type ManagerPatch struct {
+	file.TemplateMixin
+	file.ComponentConfigMixin
+	file.IPv6Mixin
+
+	// Env is the name of the environment, which is the directory of the overlay in config/overlays
+	Env string
+	// Replicas is the number of replicas of the manager, one of them being elected as leader
+	Replicas int
+	// LogLevel is the zap log level of the manager, e.g. debug, info or error
+	LogLevel string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ManagerPatch) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "overlays", f.Env, "manager_patch.yaml")
+	}
+
+	f.TemplateBody = overlayManagerPatchTemplate
+
+	f.IfExistsAction = file.Error
+
+	return nil
+}
+
+//nolint:lll
+const overlayManagerPatchTemplate = `# This patch sets the number of replicas and the log level of the manager in the {{ .Env }} environment.
+# The args replace the ones of the manager in config/default, keep them in sync.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+spec:
+  replicas: {{ .Replicas }}
+  template:
+    spec:
+      containers:
+      - name: manager
+        args:
+{{- if .ComponentConfig }}
+        - "--config=controller_manager_config.yaml"
+{{- else }}
+        - "--health-probe-bind-address=:8081"
+        - "--metrics-bind-address={{ if .IPv6 }}[::1]{{ else }}127.0.0.1{{ end }}:8080"
+        - "--leader-elect"
+{{- end }}
+        - "--zap-log-level={{ .LogLevel }}"
+`
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+// CertificateAlertsFile is the name of the file of the alerts on the serving certificates
+const CertificateAlertsFile = "certificate_alerts.yaml"
+
+var _ file.Template = &CertificateAlerts{}
+
+// CertificateAlerts scaffolds a file that defines the PrometheusRule alerting on the expiration of the serving
+// certificates of the manager
+type CertificateAlerts struct {
+	file.TemplateMixin
+	file.ProjectNameMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *CertificateAlerts) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "prometheus", CertificateAlertsFile)
+	}
+
+	f.TemplateBody = certificateAlertsTemplate
+
+	// The alerts cover the certificates of all the webhooks
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+//nolint:lll
+const certificateAlertsTemplate = `# The alerts on the expiration of the serving certificates of the manager, measured by the
+# serving_certificate_expiration_timestamp_seconds and serving_certificate_not_before_timestamp_seconds
+# gauges of the internal/certmetrics package. An expired certificate of the webhooks makes the API server
+# fail every request they serve.
+#
+# The thresholds are fractions of the lifetime of the certificates, which fits both the 90 days
+# certificates of cert-manager, renewed once two thirds of their lifetime elapsed, and the short-lived
+# ones of other issuers.
+# TODO(user): adjust the thresholds to the renewal policy of the issuer of the certificates.
+# The rules require the Prometheus Operator, enable them with the prometheus directory in
+# config/default/kustomization.yaml.
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  labels:
+    control-plane: controller-manager
+  name: certificate-alerts
+  namespace: system
+spec:
+  groups:
+  - name: {{ .ProjectName }}-certificates
+    rules:
+    # The remaining fraction of the lifetime of the certificates
+    - record: certificate:serving_certificate_remaining_lifetime:ratio
+      expr: |
+        (serving_certificate_expiration_timestamp_seconds{job="{{ .ProjectName }}-controller-manager-metrics-service"} - time())
+        /
+        (serving_certificate_expiration_timestamp_seconds{job="{{ .ProjectName }}-controller-manager-metrics-service"} - serving_certificate_not_before_timestamp_seconds{job="{{ .ProjectName }}-controller-manager-metrics-service"})
+    # The certificate should have been renewed once two thirds of its lifetime elapsed, e.g. by cert-manager
+    - alert: ServingCertificateNotRenewed
+      expr: certificate:serving_certificate_remaining_lifetime:ratio{job="{{ .ProjectName }}-controller-manager-metrics-service"} < 0.25
+      for: 1h
+      labels:
+        severity: warning
+      annotations:
+        summary: The {{ "{{ $labels.certificate }}" }} serving certificate of {{ "{{ $labels.pod }}" }} was not renewed and has {{ "{{ $value | humanizePercentage }}" }} of its lifetime left
+        description: Check the Certificate of cert-manager, or the issuer of the certificate, and that the certificate mounted in the manager is updated.
+    - alert: ServingCertificateExpiringSoon
+      expr: certificate:serving_certificate_remaining_lifetime:ratio{job="{{ .ProjectName }}-controller-manager-metrics-service"} < 0.1
+      labels:
+        severity: critical
+      annotations:
+        summary: The {{ "{{ $labels.certificate }}" }} serving certificate of {{ "{{ $labels.pod }}" }} has {{ "{{ $value | humanizePercentage }}" }} of its lifetime left, the API server will fail the requests served by the webhooks once it expires
+        description: Renew the certificate, e.g. by deleting its Secret for cert-manager to issue it again.
+`
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &SLO{}
+
+// SLO scaffolds a file that defines the PrometheusRule of the time to ready SLO of a kind
+type SLO struct {
+	file.TemplateMixin
+	file.ResourceMixin
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *SLO) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "prometheus", "%[group]_%[kind]_slo.yaml")
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = sloTemplate
+
+	// The SLO is defined once per kind, by its first version
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Skip
+	}
+
+	return nil
+}
+
+//nolint:lll
+const sloTemplate = `# The time to ready SLO of the {{ .Resource.Kind }} objects: the objective is the ratio of the objects
+# that are ready within the target after their creation, measured by the resource_time_to_ready_seconds
+# histogram recorded by the internal/readiness package.
+#
+# TODO(user): set the objective and the target of the SLO in the annotations and in the rules below. The
+# target must be one of the buckets of the histogram, readiness.Buckets, in seconds in the le label.
+# The rules require the Prometheus Operator, enable them with the prometheus directory in
+# config/default/kustomization.yaml.
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  labels:
+    control-plane: controller-manager
+  name: {{ lower .Resource.Kind }}-slo
+  namespace: system
+  annotations:
+    # The ratio of the objects ready within the target, over the window
+    slo.kubebuilder.io/objective: "99%"
+    # The time to ready of the objects counted as good
+    slo.kubebuilder.io/target: "5m"
+    # The window over which the objective is measured
+    slo.kubebuilder.io/window: "30d"
+spec:
+  groups:
+  - name: {{ lower .Resource.Kind }}-time-to-ready
+    rules:
+    # The ratio of the objects ready within the target, over the windows of the burn rate alert
+    - record: group_kind:resource_time_to_ready_within_target:ratio_rate30m
+      expr: |
+        sum by (group, kind) (rate(resource_time_to_ready_seconds_bucket{group="{{ .Resource.Domain }}",kind="{{ .Resource.Kind }}",le="300"}[30m]))
+        /
+        sum by (group, kind) (rate(resource_time_to_ready_seconds_count{group="{{ .Resource.Domain }}",kind="{{ .Resource.Kind }}"}[30m]))
+    - record: group_kind:resource_time_to_ready_within_target:ratio_rate6h
+      expr: |
+        sum by (group, kind) (rate(resource_time_to_ready_seconds_bucket{group="{{ .Resource.Domain }}",kind="{{ .Resource.Kind }}",le="300"}[6h]))
+        /
+        sum by (group, kind) (rate(resource_time_to_ready_seconds_count{group="{{ .Resource.Domain }}",kind="{{ .Resource.Kind }}"}[6h]))
+    # The error budget of the objective, 1%, is burning 6 times faster than it can over the window, in the
+    # last 6 hours and still in the last 30 minutes: 5% of the budget was spent in 6 hours.
+    - alert: {{ .Resource.Kind }}TimeToReadySLOBurnRate
+      expr: |
+        (1 - group_kind:resource_time_to_ready_within_target:ratio_rate6h{group="{{ .Resource.Domain }}",kind="{{ .Resource.Kind }}"}) > 6 * (1 - 0.99)
+        and
+        (1 - group_kind:resource_time_to_ready_within_target:ratio_rate30m{group="{{ .Resource.Domain }}",kind="{{ .Resource.Kind }}"}) > 6 * (1 - 0.99)
+      labels:
+        severity: warning
+      annotations:
+        summary: The {{ .Resource.Kind }} objects are not ready within 5m often enough to meet their 99% objective
+`
@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/api"
 )
 
 var _ file.Template = &CRDSample{}
@@ -29,6 +30,9 @@ type CRDSample struct {
 	file.TemplateMixin
 	file.ResourceMixin
 
+	// RawExtensionFields are the fields of the spec holding arbitrary JSON objects, set to an example object
+	RawExtensionFields []api.RawExtensionField
+
 	Force bool
 }
 
@@ -57,4 +61,18 @@ metadata:
 spec:
   # Add fields here
   foo: bar
+{{- range .RawExtensionFields }}
+{{- if .EmbeddedResource }}
+  {{ .JSONName }}:
+    apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: {{ lower $.Resource.Kind }}-sample
+    data:
+      key: value
+{{- else }}
+  {{ .JSONName }}:
+    key: value
+{{- end }}
+{{- end }}
 `
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package samples
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &HPASample{}
+
+// HPASample scaffolds a file that defines a sample HorizontalPodAutoscaler scaling the sample of the CRD
+// through its scale subresource
+type HPASample struct {
+	file.TemplateMixin
+	file.ResourceMixin
+
+	// APIVersion is the API version of the HorizontalPodAutoscaler
+	APIVersion string
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *HPASample) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "samples", "%[group]_%[version]_%[kind]_hpa.yaml")
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	f.TemplateBody = hpaSampleTemplate
+
+	return nil
+}
+
+const hpaSampleTemplate = `apiVersion: {{ .APIVersion }}
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{ lower .Resource.Kind }}-sample
+spec:
+  scaleTargetRef:
+    apiVersion: {{ .Resource.Domain }}/{{ .Resource.Version }}
+    kind: {{ .Resource.Kind }}
+    name: {{ lower .Resource.Kind }}-sample
+  minReplicas: 1
+  maxReplicas: 5
+  # The resource metrics are those of the pods selected by the label selector of the scale subresource
+  metrics:
+  - type: Resource
+    resource:
+      name: cpu
+      target:
+        type: Utilization
+        averageUtilization: 80
+`
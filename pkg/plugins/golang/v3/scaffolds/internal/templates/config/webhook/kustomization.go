@@ -17,12 +17,14 @@ limitations under the License.
 package webhook
 
 import (
+	"fmt"
 	"path/filepath"
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
 )
 
 var _ file.Template = &Kustomization{}
+var _ file.Inserter = &Kustomization{}
 
 // Kustomization scaffolds a file that defines the kustomization scheme for the webhook folder
 type Kustomization struct {
@@ -31,7 +33,8 @@ type Kustomization struct {
 	// Version of webhook the project was configured with.
 	WebhookVersion string
 
-	Force bool
+	// Patches are the paths, relative to the webhook folder, of the options patches to add to the kustomization
+	Patches []string
 }
 
 // SetTemplateDefaults implements file.Template
@@ -40,14 +43,13 @@ func (f *Kustomization) SetTemplateDefaults() error {
 		f.Path = filepath.Join("config", "webhook", "kustomization.yaml")
 	}
 
-	f.TemplateBody = kustomizeWebhookTemplate
+	f.TemplateBody = fmt.Sprintf(kustomizeWebhookTemplate,
+		file.NewMarkerFor(f.Path, patchMarker),
+	)
 
-	if f.Force {
-		f.IfExistsAction = file.Overwrite
-	} else {
-		// If file exists (ex. because a webhook was already created), skip creation.
-		f.IfExistsAction = file.Skip
-	}
+	// If file exists (ex. because a webhook was already created), skip creation, even with --force: overwriting
+	// it would drop the patches of the other webhooks.
+	f.IfExistsAction = file.Skip
 
 	if f.WebhookVersion == "" {
 		f.WebhookVersion = "v1"
@@ -56,10 +58,44 @@ func (f *Kustomization) SetTemplateDefaults() error {
 	return nil
 }
 
+const patchMarker = "webhookkustomizepatch"
+
+// GetMarkers implements file.Inserter
+func (f *Kustomization) GetMarkers() []file.Marker {
+	return []file.Marker{
+		file.NewMarkerFor(f.Path, patchMarker),
+	}
+}
+
+const patchCodeFragment = `- %s
+`
+
+// GetCodeFragments implements file.Inserter
+func (f *Kustomization) GetCodeFragments() file.CodeFragmentsMap {
+	fragments := make(file.CodeFragmentsMap, 1)
+
+	patches := make([]string, 0, len(f.Patches))
+	for _, patch := range f.Patches {
+		patches = append(patches, fmt.Sprintf(patchCodeFragment, patch))
+	}
+
+	// Only store code fragments in the map if the slices are non-empty
+	if len(patches) != 0 {
+		fragments[file.NewMarkerFor(f.Path, patchMarker)] = patches
+	}
+
+	return fragments
+}
+
 const kustomizeWebhookTemplate = `resources:
 - manifests{{ if ne .WebhookVersion "v1" }}.{{ .WebhookVersion }}{{ end }}.yaml
 - service.yaml
 
+patchesStrategicMerge:
+# patches here are for setting the options of the webhooks created with --timeout-seconds,
+# --reinvocation-policy or selectors, which are not supported by the webhook markers
+%s
+
 configurations:
 - kustomizeconfig.yaml
 `
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &OptionsPatch{}
+
+// OptionsPatch scaffolds a file that defines the patch setting the options of a defaulting or validating
+// webhook that are not supported by the webhook markers
+type OptionsPatch struct {
+	file.TemplateMixin
+	file.ResourceMixin
+
+	// Version of webhook the project was configured with.
+	WebhookVersion string
+
+	// Mutating is true for the defaulting webhook and false for the validating webhook.
+	Mutating bool
+
+	TimeoutSeconds     int
+	ReinvocationPolicy string
+
+	// ObjectSelector and NamespaceSelector narrow the objects and the namespaces of the objects sent to the
+	// webhook, if not nil
+	ObjectSelector    *LabelSelector
+	NamespaceSelector *LabelSelector
+
+	Force bool
+}
+
+// LabelSelector is a label selector of a webhook
+type LabelSelector struct {
+	MatchLabels      []LabelSelectorLabel
+	MatchExpressions []LabelSelectorRequirement
+}
+
+// LabelSelectorLabel is a label of the matchLabels of a label selector
+type LabelSelectorLabel struct {
+	Key, Value string
+}
+
+// LabelSelectorRequirement is a requirement of the matchExpressions of a label selector
+type LabelSelectorRequirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *OptionsPatch) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.Mutating {
+			f.Path = filepath.Join("config", "webhook", "patches", "mutating_in_%[plural].yaml")
+		} else {
+			f.Path = filepath.Join("config", "webhook", "patches", "validating_in_%[plural].yaml")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = optionsPatchTemplate
+
+	if f.WebhookVersion == "" {
+		f.WebhookVersion = "v1"
+	}
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+const optionsPatchTemplate = `# The following patch sets the options of the webhook that its marker does not support
+{{- if .NamespaceSelector }}
+#
+# The namespaceSelector skips the objects of the namespaces it does not match, by default kube-system and
+# the namespace of the manager, so that the webhook never blocks the rollout of the manager serving it. It
+# matches the namespaces by their kubernetes.io/metadata.name label, set by Kubernetes 1.21 or later.
+{{- end }}
+apiVersion: admissionregistration.k8s.io/{{ .WebhookVersion }}
+{{- if .Mutating }}
+kind: MutatingWebhookConfiguration
+metadata:
+  name: mutating-webhook-configuration
+webhooks:
+- name: m{{ lower .Resource.Kind }}.kb.io
+{{- else }}
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: validating-webhook-configuration
+webhooks:
+- name: v{{ lower .Resource.Kind }}.kb.io
+{{- end }}
+{{- if .TimeoutSeconds }}
+  timeoutSeconds: {{ .TimeoutSeconds }}
+{{- end }}
+{{- if .ReinvocationPolicy }}
+  reinvocationPolicy: {{ .ReinvocationPolicy }}
+{{- end }}
+{{- with .ObjectSelector }}
+  objectSelector:
+{{- template "selector" . }}
+{{- end }}
+{{- with .NamespaceSelector }}
+  namespaceSelector:
+{{- template "selector" . }}
+{{- end }}
+{{- define "selector" }}
+{{- if .MatchLabels }}
+    matchLabels:
+{{- range .MatchLabels }}
+      {{ .Key }}: {{ printf "%q" .Value }}
+{{- end }}
+{{- end }}
+{{- if .MatchExpressions }}
+    matchExpressions:
+{{- range .MatchExpressions }}
+    - key: {{ .Key }}
+      operator: {{ .Operator }}
+{{- if .Values }}
+      values:
+{{- range .Values }}
+      - {{ printf "%q" . }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+`
@@ -19,6 +19,7 @@ package controllers
 import (
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
 )
@@ -31,6 +32,10 @@ type Controller struct {
 	file.TemplateMixin
 	file.MultiGroupMixin
 	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.FeatureGatesMixin
+	file.MarkerDocsMixin
+	file.MultiClusterMixin
 	file.ResourceMixin
 
 	ControllerRuntimeVersion string
@@ -38,14 +43,117 @@ type Controller struct {
 	// WireResource defines the api resources are generated or not.
 	WireResource bool
 
+	// OwnerIndex defines whether the owned objects are indexed by their owner or not.
+	OwnerIndex bool
+
+	// Adoption defines whether the labeled objects are adopted and the objects no longer desired pruned or not.
+	Adoption bool
+
+	// Expectations defines whether the reconciliations wait for the cache to observe the created and deleted
+	// objects or not.
+	Expectations bool
+
+	// DefaultsConfigMap defines whether the operator-wide defaults are loaded from a ConfigMap or not.
+	DefaultsConfigMap bool
+
+	// MetadataOnlyWatches defines whether the secondary objects are watched and cached as metadata only or not.
+	MetadataOnlyWatches bool
+
+	// Children are the kinds of the objects created or patched by the controller, which controls them.
+	Children []Child
+
+	// ResyncPeriod is the period of the reconciliations of the objects even if nothing changed, if not zero.
+	ResyncPeriod time.Duration
+
+	// ReadinessMetrics defines whether the Ready condition of the objects is set and their time to ready
+	// recorded or not.
+	ReadinessMetrics bool
+
+	// Pausable defines whether the reconciliation of the objects annotated as paused is skipped or not.
+	Pausable bool
+
+	// CrossNamespaceChildren defines whether the controller creates objects in other namespaces, tracked by a
+	// label and deleted by a finalizer, or not.
+	CrossNamespaceChildren bool
+
+	// Mocks defines whether the controller calls the systems outside of the cluster through an interface faked
+	// in its unit tests or not.
+	Mocks bool
+
 	Force bool
 }
 
+// Child is a kind of the objects controlled by the reconciled objects, created or patched by their controller
+type Child struct {
+	// Kind is the kind of the objects, e.g. Deployment
+	Kind string
+	// ImportAlias is the alias of the Go package of the kind, e.g. appsv1
+	ImportAlias string
+	// Package is the import path of the Go package of the kind, e.g. k8s.io/api/apps/v1
+	Package string
+	// Group is the API group of the kind, core for the core group
+	Group string
+	// Resource is the resource of the kind, e.g. deployments
+	Resource string
+}
+
+// RBAC returns the RBAC marker allowing the controller to manage the objects of the child kind
+func (c Child) RBAC() string {
+	return fmt.Sprintf("kubebuilder:rbac:groups=%s,resources=%s,verbs=get;list;watch;create;update;patch;delete",
+		c.Group, c.Resource)
+}
+
+// HasCoreChild returns whether one of the children belongs to the core group, whose package is imported
+// as corev1
+func (f *Controller) HasCoreChild() bool {
+	for _, child := range f.Children {
+		if child.ImportAlias == "corev1" {
+			return true
+		}
+	}
+	return false
+}
+
+// ChildPackages returns the children of the kinds whose Go packages, other than the one of the core group,
+// are imported, one per package
+func (f *Controller) ChildPackages() []Child {
+	var packages []Child
+	imported := map[string]bool{"corev1": true}
+	for _, child := range f.Children {
+		if !imported[child.ImportAlias] {
+			packages = append(packages, child)
+			imported[child.ImportAlias] = true
+		}
+	}
+	return packages
+}
+
+// WritesStatus returns whether the controller writes the status of the reconciled objects
+func (f *Controller) WritesStatus() bool {
+	return f.WireResource && (f.ReadinessMetrics || f.Pausable || f.ResyncPeriod != 0)
+}
+
+// ResyncPeriodExpr returns the Go expression of the resync period, e.g. 90 * time.Minute
+func (f *Controller) ResyncPeriodExpr() string {
+	for _, unit := range []struct {
+		duration time.Duration
+		name     string
+	}{{time.Hour, "time.Hour"}, {time.Minute, "time.Minute"}, {time.Second, "time.Second"}} {
+		if f.ResyncPeriod%unit.duration == 0 {
+			if f.ResyncPeriod == unit.duration {
+				return unit.name
+			}
+			return fmt.Sprintf("%d * %s", f.ResyncPeriod/unit.duration, unit.name)
+		}
+	}
+	return fmt.Sprintf("%d", f.ResyncPeriod)
+}
+
 // SetTemplateDefaults implements file.Template
 func (f *Controller) SetTemplateDefaults() error {
 	if f.Path == "" {
 		if f.MultiGroup && f.Resource.Group != "" {
-			f.Path = filepath.Join("controllers", "%[group]", "%[kind]_controller.go")
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_controller.go")
 		} else {
 			f.Path = filepath.Join("controllers", "%[kind]_controller.go")
 		}
@@ -75,13 +183,84 @@ package controllers
 
 import (
 	"context"
+	{{- if .ResyncPeriod }}
+	"time"
+	{{- end }}
 	"github.com/go-logr/logr"
+	{{- range .ChildPackages }}
+	{{ .ImportAlias }} "{{ .Package }}"
+	{{- end }}
+	{{- if or .OwnerIndex .Adoption .Expectations .MetadataOnlyWatches .CrossNamespaceChildren .HasCoreChild }}
+	corev1 "k8s.io/api/core/v1"
+	{{- end }}
+	{{- if .Expectations }}
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	{{- end }}
+	{{- if or .Expectations .MetadataOnlyWatches .CrossNamespaceChildren .Children }}
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	{{- end }}
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	{{- if .MetadataOnlyWatches }}
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	{{- end }}
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	{{- if and .MultiCluster .WireResource }}
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	{{- end }}
+	{{- if or .CrossNamespaceChildren .Children }}
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	{{- end }}
+	{{- if and .MultiCluster .WireResource }}
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	{{- end }}
+	{{- if or (and .MultiCluster .WireResource) .Adoption .Expectations .CrossNamespaceChildren }}
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	{{- end }}
 	{{ if .WireResource -}}
 	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
 	{{- end }}
+	{{- if .Adoption }}
+	"{{ .Repo }}/internal/adoption"
+	{{- end }}
+	{{- if .Children }}
+	"{{ .Repo }}/internal/children"
+	{{- end }}
+	{{- if .MultiCluster }}
+	"{{ .Repo }}/internal/clusters"
+	{{- end }}
+	{{- if .CrossNamespaceChildren }}
+	"{{ .Repo }}/internal/crossnamespace"
+	{{- end }}
+	{{- if .DefaultsConfigMap }}
+	"{{ .Repo }}/internal/defaults"
+	{{- end }}
+	{{- if .WireResource }}
+	reconcileerrors "{{ .Repo }}/internal/errors"
+	"{{ .Repo }}/internal/events"
+	{{- end }}
+	{{- if .Expectations }}
+	"{{ .Repo }}/internal/expectations"
+	{{- end }}
+	{{- if .FeatureGates }}
+	"{{ .Repo }}/internal/featuregate"
+	{{- end }}
+	{{- if .OwnerIndex }}
+	"{{ .Repo }}/internal/indexer"
+	{{- end }}
+	{{- if .Pausable }}
+	"{{ .Repo }}/internal/pause"
+	{{- end }}
+	{{- if .ReadinessMetrics }}
+	"{{ .Repo }}/internal/readiness"
+	{{- end }}
+	{{- if .ResyncPeriod }}
+	"{{ .Repo }}/internal/resync"
+	{{- end }}
+	{{- if .WritesStatus }}
+	"{{ .Repo }}/internal/status"
+	{{- end }}
 )
 
 // {{ .Resource.Kind }}Reconciler reconciles a {{ .Resource.Kind }} object
@@ -89,11 +268,61 @@ type {{ .Resource.Kind }}Reconciler struct {
 	client.Client
 	Log logr.Logger
 	Scheme *runtime.Scheme
+	Recorder record.EventRecorder
+{{- if .MultiCluster }}
+	// Clusters are the remote clusters whose objects are reconciled too.
+	Clusters clusters.Clusters
+{{- end }}
+{{- if .Expectations }}
+	// Expectations tracks the ConfigMaps created and deleted by the reconciler that the cache has
+	// not observed yet, set by SetupWithManager if nil.
+	Expectations *expectations.Expectations
+{{- end }}
+{{- if .DefaultsConfigMap }}
+	// Defaults loads the operator-wide defaults from their ConfigMap, set by SetupWithManager if nil.
+	Defaults *defaults.Loader
+{{- end }}
+{{- if .ResyncPeriod }}
+	// Resync schedules the periodic reconciliations of the {{ .Resource.Kind }} objects, set by
+	// SetupWithManager if nil.
+	Resync *resync.Schedule
+{{- end }}
+{{- if .Mocks }}
+	// External calls the systems outside of the cluster, replaced by a fake in the unit tests.
+	External {{ .Resource.Kind }}External
+{{- end }}
 }
+{{- if .ResyncPeriod }}
+
+// {{ lower .Resource.Kind }}ResyncPeriod is the period of the reconciliations of a {{ .Resource.Kind }} even if
+// nothing changed, spread by resync.DefaultJitterFactor.
+const {{ lower .Resource.Kind }}ResyncPeriod = {{ .ResyncPeriodExpr }}
+{{- end }}
+{{- if .Adoption }}
+
+// {{ lower .Resource.Kind }}Label is the label naming the {{ .Resource.Kind }} of an object, which selects the
+// objects adopted or pruned by the {{ .Resource.Kind }}.
+const {{ lower .Resource.Kind }}Label = "{{ .Resource.Domain }}/{{ lower .Resource.Kind }}"
+{{- end }}
 
-//+kubebuilder:rbac:groups={{ .Resource.Domain }},resources={{ .Resource.Plural }},verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups={{ .Resource.Domain }},resources={{ .Resource.Plural }}/status,verbs=get;update;patch
-//+kubebuilder:rbac:groups={{ .Resource.Domain }},resources={{ .Resource.Plural }}/finalizers,verbs=update
+{{ $resources := printf "kubebuilder:rbac:groups=%s,resources=%s,verbs=get;list;watch;create;update;patch;delete" .Resource.Domain .Resource.Plural -}}
+{{ $status := printf "kubebuilder:rbac:groups=%s,resources=%s/status,verbs=get;update;patch" .Resource.Domain .Resource.Plural -}}
+{{ $finalizers := printf "kubebuilder:rbac:groups=%s,resources=%s/finalizers,verbs=update" .Resource.Domain .Resource.Plural -}}
+{{ $events := "kubebuilder:rbac:groups=core,resources=events,verbs=create;patch" -}}
+{{ $configMaps := "" -}}
+{{ if or .Adoption .Expectations .CrossNamespaceChildren -}}
+{{ $configMaps = "kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete" -}}
+{{ else if .OwnerIndex -}}
+{{ $configMaps = "kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch" -}}
+{{ end -}}
+{{ $secrets := "" -}}
+{{ if .MetadataOnlyWatches -}}
+{{ $secrets = "kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch" -}}
+{{ end -}}
+{{ markers .MarkerDocs $resources $status $finalizers $events $configMaps $secrets }}
+{{- range .Children }}
+//+{{ .RBAC }}
+{{- end }}
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -102,18 +331,483 @@ type {{ .Resource.Kind }}Reconciler struct {
 // perform operations to make the cluster state reflect the state specified by
 // the user.
 //
+// Long-running work must stop as soon as ctx is done, which happens when the
+// manager is shutting down, so that it finishes within the graceful shutdown
+// timeout. The internal/checkpoint package helps splitting such work into steps
+// that are resumed by the next reconciliation.
+//
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@{{ .ControllerRuntimeVersion }}/pkg/reconcile
 func (r *{{ .Resource.Kind }}Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = r.Log.WithValues("{{ .Resource.Kind | lower }}", req.NamespacedName)
+{{- if .WireResource }}
+
+	var obj {{ .Resource.ImportAlias }}.{{ .Resource.Kind }}
+	if err := r.Get(ctx, req.NamespacedName, &obj); err != nil {
+{{- if .Expectations }}
+		if apierrors.IsNotFound(err) {
+			// The expectations of a deleted {{ .Resource.Kind }} are no longer needed.
+			r.Expectations.Forget(req.NamespacedName)
+		}
+{{- end }}
+		// The object may have been deleted after the reconcile request was queued.
+		return reconcileerrors.Result(client.IgnoreNotFound(err))
+	}
+{{- end }}
+{{- if .CrossNamespaceChildren }}
+
+	// Owner references can not refer to an owner of another namespace: the ConfigMaps of this
+	// {{ .Resource.Kind }} in the other namespaces are labeled with its UID instead, and deleted by its
+	// finalizer before it is deleted.
+	if !obj.DeletionTimestamp.IsZero() {
+		_, err := crossnamespace.Finalize(ctx, r.Client, &obj, &corev1.ConfigMapList{})
+		return reconcileerrors.Result(err)
+	}
+	if err := crossnamespace.EnsureFinalizer(ctx, r.Client, &obj); err != nil {
+		return reconcileerrors.Result(err)
+	}
+{{- end }}
+{{- if .Pausable }}
+
+	// Skip the reconciliation of the {{ .Resource.Kind }} while it is paused by the pause.Annotation
+	// annotation, e.g. during a maintenance window.
+	if paused, err := r.reconcilePause(ctx, &obj); err != nil || paused {
+		return reconcileerrors.Result(err)
+	}
+{{- end }}
+{{- if .MetadataOnlyWatches }}
+
+	// The Secrets are watched and cached as metadata only, which saves the memory of their data, and
+	// are listed and read as PartialObjectMetadata: reading them as corev1.Secret would start a second
+	// cache holding their data. Read the data of the few Secrets that need it with mgr.GetAPIReader().
+	// TODO(user): replace Secret with the types of the numerous or big objects watched by the controller.
+	secrets := &metav1.PartialObjectMetadataList{}
+	secrets.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("SecretList"))
+	if err := r.List(ctx, secrets{{ if .Resource.Namespaced }}, client.InNamespace(req.Namespace){{ end }}); err != nil {
+		return reconcileerrors.Result(err)
+	}
+{{- end }}
+{{- if .DefaultsConfigMap }}
+
+	// Load the operator-wide defaults of the ConfigMap of the internal/defaults package. An invalid
+	// ConfigMap does not block the reconciliation, which goes on with the defaults last loaded.
+	// TODO(user): use the defaults for the fields that the {{ .Resource.Kind }} does not set, without
+	// writing them to its spec so that the changes of the ConfigMap apply to it.
+	defaultValues, err := r.Defaults.Load(ctx)
+	if err != nil {
+		events.Warning(r.Recorder, &obj, events.ReasonReconcileError, err)
+	}
+	r.Log.V(1).Info("reconciling with the defaults", "replicas", defaultValues.Replicas)
+{{- end }}
+{{- if .OwnerIndex }}
+
+	// List the ConfigMaps controlled by this {{ .Resource.Kind }} through the owner field
+	// index registered in SetupWithManager.
+	var owned corev1.ConfigMapList
+	if err := r.List(ctx, &owned, client.InNamespace(req.Namespace), client.MatchingFields{
+		indexer.OwnerField({{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}")): req.Name,
+	}); err != nil {
+		return reconcileerrors.Result(err)
+	}
+{{- end }}
+{{- if .Adoption }}
+
+	// Adopt the ConfigMaps labeled for this {{ .Resource.Kind }} that have no controller, e.g. created
+	// before it, then prune the ConfigMaps it controls that are no longer desired. The ConfigMaps
+	// created by the controller must carry the label too.
+	// TODO(user): replace ConfigMap with the type of the objects controlled by the {{ .Resource.Kind }},
+	// and compute the names of the desired objects from its spec.
+	var children corev1.ConfigMapList
+	if err := r.List(ctx, &children, client.InNamespace(req.Namespace),
+		client.MatchingLabels{ {{- lower .Resource.Kind }}Label: req.Name}); err != nil {
+		return reconcileerrors.Result(err)
+	}
+	if _, err := adoption.Adopt(ctx, r.Client, r.Scheme, &obj, &children); err != nil {
+		return reconcileerrors.Result(err)
+	}
+	var desired []string
+	if _, err := adoption.Prune(ctx, r.Client, &obj, &children, desired...); err != nil {
+		return reconcileerrors.Result(err)
+	}
+{{- end }}
+{{- if .Expectations }}
+
+	// Wait for the cache to observe the ConfigMaps created and deleted by the previous
+	// reconciliations: until then, it may miss a ConfigMap that was just created, which would be
+	// created twice. The watch of the ConfigMaps requests a new reconciliation when they are observed.
+	if !r.Expectations.Satisfied(req.NamespacedName) {
+		return ctrl.Result{RequeueAfter: r.Expectations.TTL()}, nil
+	}
+
+	// Create the ConfigMap of this {{ .Resource.Kind }} if the cache does not hold it, recording the
+	// creation as an expectation before creating it.
+	// TODO(user): replace ConfigMap with the type of the objects controlled by the {{ .Resource.Kind }},
+	// and create and delete them according to its spec, recording every creation with
+	// ExpectCreations and every deletion with ExpectDeletions.
+	child := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: req.Name + "-config", Namespace: {{ if .Resource.Namespaced }}req.Namespace{{ else }}"default"{{ end }},
+	}}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(child), child); apierrors.IsNotFound(err) {
+		if err := ctrl.SetControllerReference(&obj, child, r.Scheme); err != nil {
+			return reconcileerrors.Result(err)
+		}
+		r.Expectations.ExpectCreations(req.NamespacedName, child.Name)
+		if err := r.Create(ctx, child); err != nil {
+			// The failed creation will never be observed.
+			r.Expectations.CreationObserved(req.NamespacedName, child.Name)
+			return reconcileerrors.Result(err)
+		}
+	} else if err != nil {
+		return reconcileerrors.Result(err)
+	}
+{{- end }}
+{{- if .Children }}
+
+	// Create or patch the objects controlled by this {{ .Resource.Kind }}, whose desired state is only
+	// applied again when the spec of the {{ .Resource.Kind }} changes.
+	{{- range .Children }}
+	if err := r.reconcile{{ .Kind }}(ctx, &obj); err != nil {
+		return reconcileerrors.Result(err)
+	}
+	{{- end }}
+{{- end }}
+{{- if .CrossNamespaceChildren }}
+
+	// Create or update the ConfigMaps of this {{ .Resource.Kind }} in the other namespaces.
+	if err := r.reconcileCrossNamespaceChildren(ctx, &obj); err != nil {
+		return reconcileerrors.Result(err)
+	}
+{{- end }}
+{{- if .Mocks }}
+
+	// Create the external resource of this {{ .Resource.Kind }} through r.External.
+	if err := r.reconcileExternal(ctx, &obj); err != nil {
+		return reconcileerrors.Result(err)
+	}
+{{- end }}
+{{- if .FeatureGates }}
+
+	if featuregate.Default.Enabled(featuregate.ExampleFeature) {
+		// TODO(user): implement here the behavior gated by ExampleFeature.
+		r.Log.V(1).Info("reconciling with ExampleFeature enabled")
+	}
+{{- end }}
+
+{{- if and .MultiCluster .WireResource }}
+
+	// r.Client is the client of the cluster of the request: the cluster of the manager, or one of
+	// r.Clusters for the controllers of the remote clusters. Use the clients of r.Clusters to
+	// reconcile objects across the clusters.
+{{- else if .MultiCluster }}
+
+	// Use the clients of r.Clusters to reconcile objects across the clusters.
+{{- end }}
 
 	// your logic here
+{{- if .WireResource }}
+
+	// Write the status of the {{ .Resource.Kind }} with status.Patch, which only patches the changes
+	// of the status and applies them again to a fresh copy of the {{ .Resource.Kind }} when they
+	// conflict with the changes of another writer, e.g.:
+	//	if _, err := status.Patch(ctx, r.Client, &obj, func() error {
+	//		status.SetCondition(&obj.Status.Conditions, metav1.Condition{Type: "Available",
+	//			Status: metav1.ConditionTrue, Reason: "Available", ObservedGeneration: obj.Generation})
+	//		return nil
+	//	}); err != nil {
+	//		return reconcileerrors.Result(err)
+	//	}
+
+	// Return the errors of the reconciliation through reconcileerrors.Result: the terminal ones,
+	// e.g. of an invalid spec, are not requeued until the {{ .Resource.Kind }} changes, and the transient
+	// ones are requeued with a backoff, or after the delay of reconcileerrors.RequeueAfter. Record
+	// events about the outcome of the reconciliation with the helpers of the internal/events package,
+	// e.g. when it fails for good:
+	//	if err := validate(&obj); err != nil {
+	//		events.Warning(r.Recorder, &obj, events.ReasonReconcileError, err)
+	//		return reconcileerrors.Result(reconcileerrors.Terminal(err))
+	//	}
+	events.Normal(r.Recorder, &obj, events.ReasonReconciled, "{{ .Resource.Kind }} %s reconciled", req.Name)
+{{- end }}
+{{- if .ReadinessMetrics }}
+
+	// Set the Ready condition of the {{ .Resource.Kind }}, and record its time to ready the first time it
+	// is ready, once its status is updated.
+	// TODO(user): call readiness.SetNotReady instead while the {{ .Resource.Kind }} is not ready, e.g. until
+	// the objects it controls are available.
+	var first bool
+	if _, err := status.Patch(ctx, r.Client, &obj, func() error {
+		_, first = readiness.SetReady(&obj.Status.Conditions, obj.Generation, "{{ .Resource.Kind }} reconciled")
+		return nil
+	}); err != nil {
+		return reconcileerrors.Result(err)
+	}
+	if first {
+		readiness.Observe({{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}").GroupKind(), &obj, obj.Status.Conditions)
+	}
+{{- end }}
+{{- if .ResyncPeriod }}
+
+	return r.resync(ctx, &obj)
+{{- else }}
 
 	return ctrl.Result{}, nil
+{{- end }}
 }
+{{- if .ResyncPeriod }}
+
+// resync runs the periodic work of the {{ .Resource.Kind }} when its status.nextReconcileTime is due,
+// then schedules the next one, and requeues the {{ .Resource.Kind }} until then. The reconciliations
+// requested by the changes of the {{ .Resource.Kind }} or of its objects, including the update of its
+// status, do not postpone it.
+func (r *{{ .Resource.Kind }}Reconciler) resync(ctx context.Context, obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) (ctrl.Result, error) {
+	if due, after := r.Resync.Due(obj.Status.NextReconcileTime); !due {
+		return ctrl.Result{RequeueAfter: after}, nil
+	}
+
+	// TODO(user): do the periodic work of the {{ .Resource.Kind }} here, e.g. renew its certificate or
+	// check its license before they expire. An error retries it with a backoff, the next periodic
+	// reconciliation being scheduled once it succeeds.
+
+	next, after := r.Resync.Next()
+	if _, err := status.Patch(ctx, r.Client, obj, func() error {
+		obj.Status.NextReconcileTime = &next
+		return nil
+	}); err != nil {
+		return reconcileerrors.Result(err)
+	}
+	return ctrl.Result{RequeueAfter: after}, nil
+}
+{{- end }}
+{{- if .Pausable }}
+
+// reconcilePause returns whether the reconciliation of the {{ .Resource.Kind }} is paused, updating its
+// Paused condition when it is paused or resumed.
+func (r *{{ .Resource.Kind }}Reconciler) reconcilePause(ctx context.Context, obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) (bool, error) {
+	paused := pause.IsPaused(obj)
+	changed, err := status.Patch(ctx, r.Client, obj, func() error {
+		if paused {
+			pause.SetPaused(&obj.Status.Conditions, obj.Generation)
+		} else {
+			pause.SetResumed(&obj.Status.Conditions, obj.Generation)
+		}
+		return nil
+	})
+	if err != nil {
+		return paused, err
+	}
+	if changed {
+		r.Log.Info("{{ .Resource.Kind }} reconciliation paused or resumed", "{{ lower .Resource.Kind }}",
+			client.ObjectKeyFromObject(obj), "paused", paused)
+	}
+	return paused, nil
+}
+{{- end }}
+{{- if .CrossNamespaceChildren }}
+
+// reconcileCrossNamespaceChildren creates or updates the ConfigMaps of the {{ .Resource.Kind }} in other
+// namespaces than its own, labeled and annotated by crossnamespace.SetOwner instead of being owned.
+// TODO(user): replace ConfigMap with the type of the objects of the {{ .Resource.Kind }}, and compute
+// their namespaces from its spec. Delete the objects of the namespaces removed from the spec, listing
+// them with the crossnamespace.OwnerUIDLabel label.
+func (r *{{ .Resource.Kind }}Reconciler) reconcileCrossNamespaceChildren(ctx context.Context, obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) error {
+	var namespaces []string
+	for _, namespace := range namespaces {
+		// The name of the owner is only unique in its namespace
+		child := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: obj.Namespace + "-" + obj.Name, Namespace: namespace,
+		}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, child, func() error {
+			crossnamespace.SetOwner(obj, {{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}").GroupKind(), child)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+{{- end }}
+{{- if .Mocks }}
+
+// reconcileExternal creates the external resource of the {{ .Resource.Kind }} if it does not exist yet.
+// TODO(user): implement {{ .Resource.Kind }}External with the clients of the external systems, and set
+// the External field of the reconciler in main.go.
+func (r *{{ .Resource.Kind }}Reconciler) reconcileExternal(ctx context.Context, obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) error {
+	if r.External == nil {
+		return nil
+	}
+	exists, err := r.External.Observe(ctx, obj)
+	if err != nil || exists {
+		return err
+	}
+	r.Log.Info("creating the external resource", "{{ lower .Resource.Kind }}", client.ObjectKeyFromObject(obj))
+	return r.External.Create(ctx, obj)
+}
+{{- end }}
+{{- range .Children }}
+
+// reconcile{{ .Kind }} creates or patches the {{ .Kind }} controlled by the {{ $.Resource.Kind }}.
+func (r *{{ $.Resource.Kind }}Reconciler) reconcile{{ .Kind }}(ctx context.Context, obj *{{ $.Resource.ImportAlias }}.{{ $.Resource.Kind }}) error {
+	child := &{{ .ImportAlias }}.{{ .Kind }}{ObjectMeta: metav1.ObjectMeta{
+		Name: obj.Name, Namespace: {{ if $.Resource.Namespaced }}obj.Namespace{{ else }}"default"{{ end }},
+	}}
+	// TODO(user): set the fields of the {{ .Kind }} from the spec of the {{ $.Resource.Kind }} in the mutate
+	// function, which is only called when the spec changed since the {{ .Kind }} was last patched. Pass
+	// the fields of the spec it reads instead of the whole spec, so that the other changes are ignored.
+	result, err := children.CreateOrPatch(ctx, r.Client, r.Scheme, obj, child, obj.Spec, func() error {
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if result != controllerutil.OperationResultNone {
+		r.Log.V(1).Info("{{ .Kind }} reconciled", "{{ lower .Kind }}", client.ObjectKeyFromObject(child),
+			"operation", result)
+	}
+	return nil
+}
+{{- end }}
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *{{ .Resource.Kind }}Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+	{{- if .OwnerIndex }}
+	// TODO(user): replace ConfigMap with the type of the objects controlled by the {{ .Resource.Kind }}.
+	if err := indexer.IndexOwner(context.Background(), mgr.GetFieldIndexer(), &corev1.ConfigMap{},
+		{{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}")); err != nil {
+		return err
+	}
+
+	{{ end -}}
+	{{- if .Expectations }}
+	if r.Expectations == nil {
+		r.Expectations = expectations.New(expectations.DefaultTTL)
+	}
+
+	{{ end -}}
+	{{- if .DefaultsConfigMap }}
+	if r.Defaults == nil {
+		loader, err := defaults.NewLoader(mgr)
+		if err != nil {
+			return err
+		}
+		r.Defaults = loader
+	}
+
+	{{ end -}}
+	{{- if .ResyncPeriod }}
+	if r.Resync == nil {
+		r.Resync = resync.New({{ lower .Resource.Kind }}ResyncPeriod, resync.DefaultJitterFactor)
+	}
+
+	{{ end -}}
+{{- if and .MultiCluster .WireResource }}
+	if err := ctrl.NewControllerManagedBy(mgr).
+		For(&{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{}).
+		{{- if .OwnerIndex }}
+		Owns(&corev1.ConfigMap{}).
+		{{- end }}
+		{{- if .MetadataOnlyWatches }}
+		Owns(&corev1.Secret{}, builder.OnlyMetadata).
+		{{- end }}
+		{{- range .Children }}
+		Owns(&{{ .ImportAlias }}.{{ .Kind }}{}).
+		{{- end }}
+		{{- if .Adoption }}
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			adoption.EnqueueOwner({{ lower .Resource.Kind }}Label, {{ .Resource.Namespaced }})).
+		{{- end }}
+		{{- if .CrossNamespaceChildren }}
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			crossnamespace.EnqueueOwner({{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}").GroupKind())).
+		{{- end }}
+		{{- if .Expectations }}
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, expectations.EnqueueOwner(r.Expectations,
+			{{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}"), {{ .Resource.Namespaced }})).
+		{{- end }}
+		{{- if .DefaultsConfigMap }}
+		Watches(r.Defaults.Source(), r.Defaults.EnqueueAll(mgr.GetClient(), &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}List{})).
+		{{- end }}
+		Complete(r); err != nil {
+		return err
+	}
+
+	// The objects of each remote cluster are reconciled by a controller of their own, whose
+	// reconciler is a copy of r using the client of the cluster.
+	for _, cluster := range r.Clusters {
+		remote := *r
+		remote.Client = cluster.Client
+		remote.Log = r.Log.WithValues("cluster", cluster.Name)
+		{{- if .Expectations }}
+		// The names of the objects of the clusters may collide, each cluster has its own expectations
+		remote.Expectations = expectations.New(r.Expectations.TTL())
+		{{- end }}
+		{{- if .OwnerIndex }}
+		if err := indexer.IndexOwner(context.Background(), cluster.Cache, &corev1.ConfigMap{},
+			{{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}")); err != nil {
+			return err
+		}
+		{{- end }}
+		c, err := controller.New("{{ lower .Resource.Kind }}-"+cluster.Name, mgr, controller.Options{Reconciler: &remote})
+		if err != nil {
+			return err
+		}
+		if err := c.Watch(source.NewKindWithCache(&{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{}, cluster.Cache),
+			&handler.EnqueueRequestForObject{}); err != nil {
+			return err
+		}
+		{{- if .OwnerIndex }}
+		if err := c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, cluster.Cache), &handler.EnqueueRequestForOwner{
+			OwnerType:    &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{},
+			IsController: true,
+		}); err != nil {
+			return err
+		}
+		{{- end }}
+		{{- if .Adoption }}
+		if err := c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, cluster.Cache),
+			adoption.EnqueueOwner({{ lower .Resource.Kind }}Label, {{ .Resource.Namespaced }})); err != nil {
+			return err
+		}
+		{{- end }}
+		{{- if .CrossNamespaceChildren }}
+		if err := c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, cluster.Cache),
+			crossnamespace.EnqueueOwner({{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}").GroupKind())); err != nil {
+			return err
+		}
+		{{- end }}
+		{{- if .Expectations }}
+		if err := c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, cluster.Cache),
+			expectations.EnqueueOwner(remote.Expectations,
+				{{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}"), {{ .Resource.Namespaced }})); err != nil {
+			return err
+		}
+		{{- end }}
+		{{- if .MetadataOnlyWatches }}
+		secretMetadata := &metav1.PartialObjectMetadata{}
+		secretMetadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+		if err := c.Watch(source.NewKindWithCache(secretMetadata, cluster.Cache), &handler.EnqueueRequestForOwner{
+			OwnerType:    &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{},
+			IsController: true,
+		}); err != nil {
+			return err
+		}
+		{{- end }}
+		{{- range .Children }}
+		if err := c.Watch(source.NewKindWithCache(&{{ .ImportAlias }}.{{ .Kind }}{}, cluster.Cache), &handler.EnqueueRequestForOwner{
+			OwnerType:    &{{ $.Resource.ImportAlias }}.{{ $.Resource.Kind }}{},
+			IsController: true,
+		}); err != nil {
+			return err
+		}
+		{{- end }}
+		{{- if .DefaultsConfigMap }}
+		if err := c.Watch(r.Defaults.Source(),
+			r.Defaults.EnqueueAll(cluster.Client, &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}List{})); err != nil {
+			return err
+		}
+		{{- end }}
+	}
+	return nil
+{{- else }}
 	return ctrl.NewControllerManagedBy(mgr).
 		{{ if .WireResource -}}
 		For(&{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{}).
@@ -121,6 +815,31 @@ func (r *{{ .Resource.Kind }}Reconciler) SetupWithManager(mgr ctrl.Manager) erro
 		// Uncomment the following line adding a pointer to an instance of the controlled resource as an argument
 		// For().
 		{{- end }}
+		{{- if .OwnerIndex }}
+		Owns(&corev1.ConfigMap{}).
+		{{- end }}
+		{{- if .MetadataOnlyWatches }}
+		Owns(&corev1.Secret{}, builder.OnlyMetadata).
+		{{- end }}
+		{{- range .Children }}
+		Owns(&{{ .ImportAlias }}.{{ .Kind }}{}).
+		{{- end }}
+		{{- if .Adoption }}
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			adoption.EnqueueOwner({{ lower .Resource.Kind }}Label, {{ .Resource.Namespaced }})).
+		{{- end }}
+		{{- if .CrossNamespaceChildren }}
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			crossnamespace.EnqueueOwner({{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}").GroupKind())).
+		{{- end }}
+		{{- if .Expectations }}
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, expectations.EnqueueOwner(r.Expectations,
+			{{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}"), {{ .Resource.Namespaced }})).
+		{{- end }}
+		{{- if .DefaultsConfigMap }}
+		Watches(r.Defaults.Source(), r.Defaults.EnqueueAll(mgr.GetClient(), &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}List{})).
+		{{- end }}
 		Complete(r)
+{{- end }}
 }
 `
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &AdoptionTest{}
+
+// AdoptionTest scaffolds the file that tests the adoption and the pruning of the objects of a controller
+type AdoptionTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ResourceMixin
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *AdoptionTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_adoption_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_adoption_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = adoptionTestTemplate
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+const adoptionTestTemplate = `{{ .Boilerplate }}
+
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"{{ .Repo }}/internal/adoption"
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+var _ = Describe("{{ .Resource.Kind }} adoption", func() {
+	It("should adopt the labeled ConfigMaps and prune the ConfigMaps no longer desired", func() {
+		ctx := context.Background()
+
+		By("creating two {{ .Resource.Kind }} objects")
+		owner := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{Name: "adoption-test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}},
+		}
+		Expect(k8sClient.Create(ctx, owner)).To(Succeed())
+		other := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{Name: "adoption-test-other"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}},
+		}
+		Expect(k8sClient.Create(ctx, other)).To(Succeed())
+
+		By("creating ConfigMaps labeled for the first one, one of them controlled by the second one")
+		labels := map[string]string{ {{- lower .Resource.Kind }}Label: owner.Name}
+		kept := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "adoption-test-kept", Namespace: "default", Labels: labels,
+		}}
+		stale := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "adoption-test-stale", Namespace: "default", Labels: labels,
+		}}
+		foreign := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "adoption-test-foreign", Namespace: "default", Labels: labels,
+		}}
+		Expect(ctrl.SetControllerReference(other, foreign, scheme.Scheme)).To(Succeed())
+		for _, configMap := range []*corev1.ConfigMap{kept, stale, foreign} {
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+		}
+
+		By("adopting the ConfigMaps without controller")
+		var children corev1.ConfigMapList
+		Expect(k8sClient.List(ctx, &children, client.InNamespace("default"),
+			client.MatchingLabels(labels))).To(Succeed())
+		adopted, err := adoption.Adopt(ctx, k8sClient, scheme.Scheme, owner, &children)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(HaveLen(2))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(stale), stale)).To(Succeed())
+		Expect(metav1.IsControlledBy(stale, owner)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreign), foreign)).To(Succeed())
+		Expect(metav1.IsControlledBy(foreign, other)).To(BeTrue())
+
+		By("pruning the ConfigMaps that are no longer desired")
+		pruned, err := adoption.Prune(ctx, k8sClient, owner, &children, kept.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(HaveLen(1))
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(stale), stale)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(kept), kept)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreign), foreign)).To(Succeed())
+	})
+})
+`
@@ -0,0 +1,217 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &BenchmarkTest{}
+
+// BenchmarkTest scaffolds the file that benchmarks the reconciler of a resource against envtest
+type BenchmarkTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ResourceMixin
+
+	// CRDDirectoryRelativePath is the path of the CRDs relative to the package of the controller
+	CRDDirectoryRelativePath string
+
+	// OwnerIndex and Expectations indicate that the reconciler uses the owner index and the expectations
+	OwnerIndex, Expectations bool
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *BenchmarkTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_benchmark_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_benchmark_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = benchmarkTestTemplate
+
+	f.CRDDirectoryRelativePath = `".."`
+	if f.MultiGroup && f.Resource.Group != "" {
+		f.CRDDirectoryRelativePath = `"..", ".."`
+	}
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+const benchmarkTestTemplate = `{{ .Boilerplate }}
+
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+{{- if .OwnerIndex }}
+	corev1 "k8s.io/api/core/v1"
+{{- end }}
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+{{- if .Expectations }}
+	"{{ .Repo }}/internal/expectations"
+{{- end }}
+{{- if .OwnerIndex }}
+	"{{ .Repo }}/internal/indexer"
+{{- end }}
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+// Benchmark{{ .Resource.Kind }}Reconcile creates b.N {{ .Resource.Kind }} objects against envtest, reconciles each
+// of them once and reports the throughput of the reconciler and the percentiles of its latency. Run it with
+// "make bench", which sets b.N to BENCH_OBJECTS, and compare the results of two revisions with benchstat.
+func Benchmark{{ .Resource.Kind }}Reconcile(b *testing.B) {
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths: []string{filepath.Join({{ .CRDDirectoryRelativePath }}, "config", "crd", "bases")},
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() {
+		if err := testEnv.Stop(); err != nil {
+			b.Error(err)
+		}
+	}()
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		b.Fatal(err)
+	}
+	if err := {{ .Resource.ImportAlias }}.AddToScheme(s); err != nil {
+		b.Fatal(err)
+	}
+
+	// The manager only runs the cache read by the reconciler, whose reconciliations are driven by the benchmark
+	// instead of a controller.
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: s, MetricsBindAddress: "0"})
+	if err != nil {
+		b.Fatal(err)
+	}
+{{- if .OwnerIndex }}
+	if err := indexer.IndexOwner(context.Background(), mgr.GetFieldIndexer(), &corev1.ConfigMap{},
+		{{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}")); err != nil {
+		b.Fatal(err)
+	}
+{{- end }}
+	reconciler := &{{ .Resource.Kind }}Reconciler{
+		Client:   mgr.GetClient(),
+		Log:      ctrl.Log.WithName("benchmark"),
+		Scheme:   s,
+		Recorder: mgr.GetEventRecorderFor("{{ lower .Resource.Kind }}-benchmark"),
+{{- if .Expectations }}
+		Expectations: expectations.New(expectations.DefaultTTL),
+{{- end }}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- mgr.Start(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-stopped; err != nil {
+			b.Error(err)
+		}
+	}()
+
+	// The objects of each run are labeled, so that a run does not count the objects of the previous ones.
+	run := rand.String(8)
+	labels := map[string]string{"benchmark": run}
+	requests := make([]ctrl.Request, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		obj := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("benchmark-%s-%d", run, i),
+{{- if .Resource.Namespaced }}
+				Namespace: "default",
+{{- end }}
+				Labels:    labels,
+			},
+			// TODO(user): set the spec of a representative {{ .Resource.Kind }}.
+		}
+		if err := mgr.GetClient().Create(ctx, obj); err != nil {
+			b.Fatal(err)
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+	}
+
+	// The reconciler reads from the cache, which must observe all the objects before they are reconciled.
+	if err := wait.PollImmediate(100*time.Millisecond, time.Minute, func() (bool, error) {
+		var list {{ .Resource.ImportAlias }}.{{ .Resource.Kind }}List
+		if err := mgr.GetClient().List(ctx, &list, client.MatchingLabels(labels)); err != nil {
+			return false, err
+		}
+		return len(list.Items) == b.N, nil
+	}); err != nil {
+		b.Fatalf("the cache did not observe the %d {{ .Resource.Kind }} objects: %v", b.N, err)
+	}
+
+	latencies := make([]time.Duration, 0, b.N)
+	b.ResetTimer()
+	start := time.Now()
+	for _, req := range requests {
+		reconcileStart := time.Now()
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+		latencies = append(latencies, time.Since(reconcileStart))
+	}
+	elapsed := time.Since(start)
+	b.StopTimer()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	b.ReportMetric(float64(b.N)/elapsed.Seconds(), "reconciles/s")
+	for _, p := range []int{50, 90, 99} {
+		latency := latencies[(len(latencies)-1)*p/100]
+		b.ReportMetric(float64(latency.Microseconds())/1000, fmt.Sprintf("p%d-ms", p))
+	}
+}
+`
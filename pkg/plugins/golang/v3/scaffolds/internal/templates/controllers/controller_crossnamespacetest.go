@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &CrossNamespaceTest{}
+
+// CrossNamespaceTest scaffolds the file that tests the deletion of the objects created by a controller in other
+// namespaces against envtest
+type CrossNamespaceTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ResourceMixin
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *CrossNamespaceTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_cross_namespace_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_cross_namespace_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = crossNamespaceTestTemplate
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+const crossNamespaceTestTemplate = `{{ .Boilerplate }}
+
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"{{ .Repo }}/internal/crossnamespace"
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+var _ = Describe("{{ .Resource.Kind }} cross-namespace children", func() {
+	It("should delete the ConfigMaps of the {{ .Resource.Kind }} in the other namespaces before it is deleted", func() {
+		ctx := context.Background()
+		gk := {{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}").GroupKind()
+
+		By("creating a {{ .Resource.Kind }} with the finalizer")
+		owner := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{Name: "cross-namespace-test", Namespace: "default"},
+		}
+		Expect(k8sClient.Create(ctx, owner)).To(Succeed())
+		Expect(crossnamespace.EnsureFinalizer(ctx, k8sClient, owner)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(owner), owner)).To(Succeed())
+		Expect(controllerutil.ContainsFinalizer(owner, crossnamespace.Finalizer)).To(BeTrue())
+
+		By("creating a ConfigMap of the {{ .Resource.Kind }} in another namespace")
+		namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "cross-namespace-test"}}
+		Expect(k8sClient.Create(ctx, namespace)).To(Succeed())
+		child := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: owner.Namespace + "-" + owner.Name, Namespace: namespace.Name,
+		}}
+		crossnamespace.SetOwner(owner, gk, child)
+		Expect(k8sClient.Create(ctx, child)).To(Succeed())
+		ownerName, ok := crossnamespace.OwnerOf(child, gk)
+		Expect(ok).To(BeTrue())
+		Expect(ownerName).To(Equal(client.ObjectKeyFromObject(owner)))
+
+		By("deleting the ConfigMap when the {{ .Resource.Kind }} is deleted")
+		Expect(k8sClient.Delete(ctx, owner)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(owner), owner)).To(Succeed())
+		Expect(owner.DeletionTimestamp).NotTo(BeNil())
+		done, err := crossnamespace.Finalize(ctx, k8sClient, owner, &corev1.ConfigMapList{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeFalse())
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(child), child)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("removing the finalizer once the ConfigMap is gone")
+		done, err = crossnamespace.Finalize(ctx, k8sClient, owner, &corev1.ConfigMapList{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(done).To(BeTrue())
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(owner), owner)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
+`
@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ExpectationsTest{}
+
+// ExpectationsTest scaffolds the file that tests that a controller does not create its objects twice when its
+// cache is stale
+type ExpectationsTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ResourceMixin
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ExpectationsTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_expectations_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_expectations_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = expectationsTestTemplate
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+const expectationsTestTemplate = `{{ .Boilerplate }}
+
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"{{ .Repo }}/internal/expectations"
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+// {{ lower .Resource.Kind }}StaleClient reads the objects from a cache lagging behind the API server, to which
+// it writes them.
+type {{ lower .Resource.Kind }}StaleClient struct {
+	client.Client
+	cache client.Reader
+}
+
+func (c {{ lower .Resource.Kind }}StaleClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	return c.cache.Get(ctx, key, obj)
+}
+
+func (c {{ lower .Resource.Kind }}StaleClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.cache.List(ctx, list, opts...)
+}
+
+var _ = Describe("{{ .Resource.Kind }} expectations", func() {
+	It("should not create the ConfigMap twice while the cache has not observed it", func() {
+		ctx := context.Background()
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect({{ .Resource.ImportAlias }}.AddToScheme(s)).To(Succeed())
+
+		owner := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{Name: "expectations-test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}},
+		}
+		apiServer := fake.NewClientBuilder().WithScheme(s).WithObjects(owner.DeepCopy()).Build()
+		cache := fake.NewClientBuilder().WithScheme(s).WithObjects(owner.DeepCopy()).Build()
+		exp := expectations.New(time.Minute)
+		reconciler := &{{ .Resource.Kind }}Reconciler{
+			Client:       {{ lower .Resource.Kind }}StaleClient{Client: apiServer, cache: cache},
+			Log:          ctrl.Log.WithName("expectations-test"),
+			Scheme:       s,
+			Recorder:     record.NewFakeRecorder(10),
+			Expectations: exp,
+		}
+		req := ctrl.Request{NamespacedName: client.ObjectKeyFromObject(owner)}
+
+		By("reconciling twice before the cache observes the created ConfigMap")
+		for i := 0; i < 2; i++ {
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		var configMaps corev1.ConfigMapList
+		Expect(apiServer.List(ctx, &configMaps)).To(Succeed())
+		Expect(configMaps.Items).To(HaveLen(1))
+		Expect(exp.Satisfied(req.NamespacedName)).To(BeFalse())
+
+		By("observing the creation of the ConfigMap in the cache")
+		created := configMaps.Items[0].DeepCopy()
+		created.ResourceVersion = ""
+		Expect(cache.Create(ctx, created)).To(Succeed())
+		queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer queue.ShutDown()
+		expectations.EnqueueOwner(exp, {{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}"), {{ .Resource.Namespaced }}).
+			Create(event.CreateEvent{Object: created}, queue)
+		Expect(exp.Satisfied(req.NamespacedName)).To(BeTrue())
+		Expect(queue.Len()).To(Equal(1))
+
+		By("reconciling with the cache up to date")
+		_, err := reconciler.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(apiServer.List(ctx, &configMaps)).To(Succeed())
+		Expect(configMaps.Items).To(HaveLen(1))
+	})
+
+	It("should expire the expectations that are never observed", func() {
+		fakeClock := clock.NewFakeClock(time.Now())
+		exp := expectations.NewWithClock(time.Minute, fakeClock)
+		owner := types.NamespacedName{Name: "expectations-test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}}
+
+		exp.ExpectCreations(owner, "missed")
+		Expect(exp.Satisfied(owner)).To(BeFalse())
+		fakeClock.Step(2 * time.Minute)
+		Expect(exp.Satisfied(owner)).To(BeTrue())
+	})
+})
+`
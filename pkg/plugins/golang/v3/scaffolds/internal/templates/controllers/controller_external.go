@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &External{}
+
+// External scaffolds the file that defines the interface of the interactions of a controller with the systems
+// outside of the cluster, which its unit tests fake
+type External struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.ResourceMixin
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *External) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_external.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_external.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = externalTemplate
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+const externalTemplate = `{{ .Boilerplate }}
+
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+// The fake of {{ .Resource.Kind }}External used by the unit tests of the reconciler is generated by
+// counterfeiter, install it with:
+//   go get github.com/maxbrunsfeld/counterfeiter/v6
+// and run "go generate ./..." after changing the interface to generate the fake again.
+//go:generate counterfeiter -o {{ lower .Resource.Kind }}_external_fake_test.go . {{ .Resource.Kind }}External
+
+// {{ .Resource.Kind }}External is the interface of the interactions of the {{ .Resource.Kind }}Reconciler
+// with the systems outside of the cluster, e.g. the API of a cloud provider, so that its unit tests
+// replace them with a fake instead of calling them.
+// TODO(user): replace the methods with the calls of the reconciler to the external systems, and
+// implement the interface with their clients.
+type {{ .Resource.Kind }}External interface {
+	// Observe returns whether the external resource of the {{ .Resource.Kind }} exists.
+	Observe(ctx context.Context, obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) (bool, error)
+	// Create creates the external resource of the {{ .Resource.Kind }}.
+	Create(ctx context.Context, obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) error
+}
+`
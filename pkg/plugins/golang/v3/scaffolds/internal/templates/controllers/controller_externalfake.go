@@ -0,0 +1,259 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ExternalFake{}
+
+// ExternalFake scaffolds the fake of the interface of the external interactions of a controller, as generated by
+// counterfeiter so that the users generate it again when they change the interface
+type ExternalFake struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.ResourceMixin
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ExternalFake) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_external_fake_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_external_fake_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = externalFakeTemplate
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+//nolint:lll
+const externalFakeTemplate = `// Code generated by counterfeiter. DO NOT EDIT.
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+	"sync"
+
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+type Fake{{ .Resource.Kind }}External struct {
+	CreateStub        func(context.Context, *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) error
+	createMutex       sync.RWMutex
+	createArgsForCall []struct {
+		arg1 context.Context
+		arg2 *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}
+	}
+	createReturns struct {
+		result1 error
+	}
+	createReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ObserveStub        func(context.Context, *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) (bool, error)
+	observeMutex       sync.RWMutex
+	observeArgsForCall []struct {
+		arg1 context.Context
+		arg2 *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}
+	}
+	observeReturns struct {
+		result1 bool
+		result2 error
+	}
+	observeReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) Create(arg1 context.Context, arg2 *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) error {
+	fake.createMutex.Lock()
+	ret, specificReturn := fake.createReturnsOnCall[len(fake.createArgsForCall)]
+	fake.createArgsForCall = append(fake.createArgsForCall, struct {
+		arg1 context.Context
+		arg2 *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}
+	}{arg1, arg2})
+	stub := fake.CreateStub
+	fakeReturns := fake.createReturns
+	fake.recordInvocation("Create", []interface{}{arg1, arg2})
+	fake.createMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) CreateCallCount() int {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	return len(fake.createArgsForCall)
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) CreateCalls(stub func(context.Context, *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = stub
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) CreateArgsForCall(i int) (context.Context, *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) {
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	argsForCall := fake.createArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) CreateReturns(result1 error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = nil
+	fake.createReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) CreateReturnsOnCall(i int, result1 error) {
+	fake.createMutex.Lock()
+	defer fake.createMutex.Unlock()
+	fake.CreateStub = nil
+	if fake.createReturnsOnCall == nil {
+		fake.createReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) Observe(arg1 context.Context, arg2 *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) (bool, error) {
+	fake.observeMutex.Lock()
+	ret, specificReturn := fake.observeReturnsOnCall[len(fake.observeArgsForCall)]
+	fake.observeArgsForCall = append(fake.observeArgsForCall, struct {
+		arg1 context.Context
+		arg2 *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}
+	}{arg1, arg2})
+	stub := fake.ObserveStub
+	fakeReturns := fake.observeReturns
+	fake.recordInvocation("Observe", []interface{}{arg1, arg2})
+	fake.observeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) ObserveCallCount() int {
+	fake.observeMutex.RLock()
+	defer fake.observeMutex.RUnlock()
+	return len(fake.observeArgsForCall)
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) ObserveCalls(stub func(context.Context, *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) (bool, error)) {
+	fake.observeMutex.Lock()
+	defer fake.observeMutex.Unlock()
+	fake.ObserveStub = stub
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) ObserveArgsForCall(i int) (context.Context, *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) {
+	fake.observeMutex.RLock()
+	defer fake.observeMutex.RUnlock()
+	argsForCall := fake.observeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) ObserveReturns(result1 bool, result2 error) {
+	fake.observeMutex.Lock()
+	defer fake.observeMutex.Unlock()
+	fake.ObserveStub = nil
+	fake.observeReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) ObserveReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.observeMutex.Lock()
+	defer fake.observeMutex.Unlock()
+	fake.ObserveStub = nil
+	if fake.observeReturnsOnCall == nil {
+		fake.observeReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.observeReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createMutex.RLock()
+	defer fake.createMutex.RUnlock()
+	fake.observeMutex.RLock()
+	defer fake.observeMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Fake{{ .Resource.Kind }}External) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ {{ .Resource.Kind }}External = new(Fake{{ .Resource.Kind }}External)
+`
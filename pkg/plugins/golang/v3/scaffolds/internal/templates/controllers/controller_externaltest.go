@@ -0,0 +1,154 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ExternalTest{}
+
+// ExternalTest scaffolds the file that unit tests the external interactions of a controller without envtest,
+// with the fake client, an interceptor and the fake of its interface
+type ExternalTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ResourceMixin
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ExternalTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_external_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_external_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = externalTestTemplate
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+//nolint:lll
+const externalTestTemplate = `{{ .Boilerplate }}
+
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"{{ .Repo }}/internal/interceptor"
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+// Test{{ .Resource.Kind }}ReconcilerExternal unit tests the interactions of the {{ .Resource.Kind }}Reconciler
+// with the external systems without envtest: the API server is replaced by the fake client, made to
+// fail by an interceptor, and the external systems by the fake of {{ .Resource.Kind }}External generated
+// by counterfeiter. Run it without the envtest suite with:
+//   go test ./controllers/... -run Test{{ .Resource.Kind }}Reconciler
+func Test{{ .Resource.Kind }}ReconcilerExternal(t *testing.T) {
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := {{ .Resource.ImportAlias }}.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+
+	obj := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "external-test",
+			{{- if .Resource.Namespaced }}
+			Namespace: "default",
+			{{- end }}
+		},
+	}
+	external := &Fake{{ .Resource.Kind }}External{}
+	reconciler := &{{ .Resource.Kind }}Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(s).WithObjects(obj).Build(),
+		Log:      ctrl.Log.WithName("external-test"),
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(10),
+		External: external,
+	}
+
+	// The external resource is created when it does not exist
+	external.ObserveReturns(false, nil)
+	if err := reconciler.reconcileExternal(ctx, obj); err != nil {
+		t.Fatal(err)
+	}
+	if count := external.CreateCallCount(); count != 1 {
+		t.Fatalf("expected the external resource to be created once, got %d creations", count)
+	}
+	if _, created := external.CreateArgsForCall(0); created.Name != obj.Name {
+		t.Errorf("expected the external resource of %s to be created, got the one of %s", obj.Name, created.Name)
+	}
+
+	// The external resource is not created again once it exists
+	external.ObserveReturns(true, nil)
+	if err := reconciler.reconcileExternal(ctx, obj); err != nil {
+		t.Fatal(err)
+	}
+	if count := external.CreateCallCount(); count != 1 {
+		t.Errorf("expected the existing external resource not to be created again, got %d creations", count)
+	}
+
+	// The reconciliation fails without calling the external systems when the {{ .Resource.Kind }} can not
+	// be read, the interceptor making the fake client fail as an unavailable API server would.
+	reconciler.Client = interceptor.NewClient(reconciler.Client, interceptor.Funcs{
+		Get: func(ctx context.Context, c client.Client, key client.ObjectKey, obj client.Object) error {
+			return apierrors.NewServiceUnavailable("injected by the test")
+		},
+	})
+	if _, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)}); err == nil {
+		t.Error("expected the reconciliation to fail when the API server is unavailable")
+	}
+	if count := external.ObserveCallCount(); count != 2 {
+		t.Errorf("expected the external systems not to be called when the API server is unavailable, got %d calls", count)
+	}
+}
+`
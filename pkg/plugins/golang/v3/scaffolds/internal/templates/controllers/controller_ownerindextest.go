@@ -0,0 +1,119 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &OwnerIndexTest{}
+
+// OwnerIndexTest scaffolds the file that tests the owner field index of a controller
+type OwnerIndexTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ResourceMixin
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *OwnerIndexTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_owner_index_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_owner_index_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = ownerIndexTestTemplate
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+const ownerIndexTestTemplate = `{{ .Boilerplate }}
+
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"{{ .Repo }}/internal/indexer"
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+var _ = Describe("{{ .Resource.Kind }} owner index", func() {
+	It("should list the ConfigMaps controlled by a {{ .Resource.Kind }}", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		By("starting a manager with the owner field index")
+		mgr, err := ctrl.NewManager(testEnv.Config, ctrl.Options{Scheme: scheme.Scheme, MetricsBindAddress: "0"})
+		Expect(err).NotTo(HaveOccurred())
+		ownerGVK := {{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}")
+		Expect(indexer.IndexOwner(ctx, mgr.GetFieldIndexer(), &corev1.ConfigMap{}, ownerGVK)).To(Succeed())
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(ctx)).To(Succeed())
+		}()
+
+		By("creating a {{ .Resource.Kind }} that controls a ConfigMap")
+		owner := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{Name: "owner-index-test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}},
+		}
+		Expect(k8sClient.Create(ctx, owner)).To(Succeed())
+		owned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner-index-test", Namespace: "default"}}
+		Expect(ctrl.SetControllerReference(owner, owned, scheme.Scheme)).To(Succeed())
+		Expect(k8sClient.Create(ctx, owned)).To(Succeed())
+		unowned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner-index-test-unowned", Namespace: "default"}}
+		Expect(k8sClient.Create(ctx, unowned)).To(Succeed())
+
+		By("listing the ConfigMaps through the owner field index")
+		Eventually(func() ([]corev1.ConfigMap, error) {
+			var list corev1.ConfigMapList
+			err := mgr.GetClient().List(ctx, &list, client.InNamespace("default"),
+				client.MatchingFields{indexer.OwnerField(ownerGVK): owner.Name})
+			return list.Items, err
+		}, 10*time.Second).Should(HaveLen(1))
+	})
+})
+`
@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &PauseTest{}
+
+// PauseTest scaffolds the file that tests that a controller skips the reconciliation of the paused objects
+type PauseTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ResourceMixin
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *PauseTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_pause_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_pause_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	f.TemplateBody = pauseTestTemplate
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+const pauseTestTemplate = `{{ .Boilerplate }}
+
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"{{ .Repo }}/internal/pause"
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+var _ = Describe("{{ .Resource.Kind }} paused reconciliations", func() {
+	It("should skip the reconciliation of the {{ .Resource.Kind }} while it is paused", func() {
+		ctx := context.Background()
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect({{ .Resource.ImportAlias }}.AddToScheme(s)).To(Succeed())
+
+		obj := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pause-test",
+				{{- if .Resource.Namespaced }}
+				Namespace:   "default",
+				{{- end }}
+				Annotations: map[string]string{pause.Annotation: "true"},
+			},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(obj).Build()
+		reconciler := &{{ .Resource.Kind }}Reconciler{
+			Client:   c,
+			Log:      ctrl.Log.WithName("pause-test"),
+			Scheme:   s,
+			Recorder: record.NewFakeRecorder(10),
+		}
+		key := client.ObjectKeyFromObject(obj)
+
+		By("setting the Paused condition of the annotated {{ .Resource.Kind }}")
+		Expect(c.Get(ctx, key, obj)).To(Succeed())
+		paused, err := reconciler.reconcilePause(ctx, obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paused).To(BeTrue())
+		Expect(c.Get(ctx, key, obj)).To(Succeed())
+		Expect(meta.IsStatusConditionTrue(obj.Status.Conditions, pause.ConditionPaused)).To(BeTrue())
+
+		By("resuming the reconciliation once the annotation is removed")
+		delete(obj.Annotations, pause.Annotation)
+		Expect(c.Update(ctx, obj)).To(Succeed())
+		paused, err = reconciler.reconcilePause(ctx, obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paused).To(BeFalse())
+		Expect(c.Get(ctx, key, obj)).To(Succeed())
+		condition := meta.FindStatusCondition(obj.Status.Conditions, pause.ConditionPaused)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal(pause.ReasonResumed))
+	})
+})
+`
@@ -0,0 +1,173 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &SampleTest{}
+
+// SampleTest scaffolds the file that applies the sample of a kind to envtest, testing its server-side defaulting
+// and validation
+type SampleTest struct {
+	file.TemplateMixin
+	file.MultiGroupMixin
+	file.BoilerplateMixin
+	file.RepositoryMixin
+	file.ResourceMixin
+
+	// SamplePath is the Go expression of the path of the sample of the kind, relative to the test
+	SamplePath string
+
+	Force bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *SampleTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		if f.MultiGroup && f.Resource.Group != "" {
+			f.Path = filepath.Join("controllers", "%[group-path]", "%[kind]_%[version]_sample_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", "%[kind]_%[version]_sample_test.go")
+		}
+	}
+	f.Path = f.Resource.Replacer().Replace(f.Path)
+
+	// The samples are in the config directory of the project, like the CRDs loaded by the suite
+	sample := f.Resource.Replacer().Replace(`"config", "samples", "%[group]_%[version]_%[kind].yaml"`)
+	if f.MultiGroup && f.Resource.Group != "" {
+		f.SamplePath = `"..", "..", ` + sample
+	} else {
+		f.SamplePath = `"..", ` + sample
+	}
+
+	f.TemplateBody = sampleTestTemplate
+
+	if f.Force {
+		f.IfExistsAction = file.Overwrite
+	} else {
+		f.IfExistsAction = file.Error
+	}
+
+	return nil
+}
+
+//nolint:lll
+const sampleTestTemplate = `{{ .Boilerplate }}
+
+{{if and .MultiGroup .Resource.Group }}
+package {{ .Resource.GroupPackageName }}
+{{else}}
+package controllers
+{{end}}
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	{{ .Resource.ImportAlias }} "{{ .Resource.Package }}"
+)
+
+// The sample of the {{ .Resource.Kind }} {{ .Resource.Version }} is applied to the API server of envtest, which
+// defaults and validates it with the CRD generated from the markers of the Go types, as a cluster does.
+var _ = Describe("{{ .Resource.Kind }} {{ .Resource.Version }} sample", func() {
+	samplePath := filepath.Join({{ .SamplePath }})
+
+	// sample returns the sample decoded into the Go type, named name, failing if the Go type does not know one
+	// of its fields.
+	sample := func(name string) *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }} {
+		content, err := ioutil.ReadFile(samplePath)
+		Expect(err).NotTo(HaveOccurred())
+		obj := &{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}{}
+		Expect(yaml.UnmarshalStrict(content, obj)).To(Succeed())
+		obj.SetName(name)
+		{{- if .Resource.Namespaced }}
+		obj.SetNamespace("default")
+		{{- end }}
+		return obj
+	}
+
+	It("should be defaulted the same way as it is applied or created from the Go type", func() {
+		ctx := context.Background()
+
+		By("applying the sample as is")
+		content, err := ioutil.ReadFile(samplePath)
+		Expect(err).NotTo(HaveOccurred())
+		applied := &unstructured.Unstructured{}
+		Expect(yaml.Unmarshal(content, &applied.Object)).To(Succeed())
+		applied.SetName("{{ lower .Resource.Kind }}-sample-applied")
+		{{- if .Resource.Namespaced }}
+		applied.SetNamespace("default")
+		{{- end }}
+		Expect(k8sClient.Create(ctx, applied)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, applied)).To(Succeed())
+		}()
+
+		By("creating the sample decoded into the Go type")
+		obj := sample("{{ lower .Resource.Kind }}-sample-created")
+		Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+		}()
+
+		By("comparing the specs defaulted by the API server")
+		// The specs differ when the Go type serializes the zero value of a field that the sample does not
+		// set, e.g. a field without omitempty, which the API server does not default then: the field has the
+		// default of its +kubebuilder:default marker when applied, and the zero value of its Go type when
+		// created by a controller.
+		created := &unstructured.Unstructured{}
+		created.SetGroupVersionKind({{ .Resource.ImportAlias }}.GroupVersion.WithKind("{{ .Resource.Kind }}"))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), created)).To(Succeed())
+		Expect(created.Object["spec"]).To(Equal(applied.Object["spec"]))
+
+		// TODO(user): check the defaults of the fields that the sample does not set, e.g.
+		//	Expect(obj.Spec.Replicas).To(Equal(pointer.Int32Ptr(1)))
+	})
+
+	It("should be rejected when invalid", func() {
+		ctx := context.Background()
+
+		// TODO(user): add the changes of the sample that the validation markers of the {{ .Resource.Kind }}
+		// reject, by their description, e.g.
+		//	"negative replicas": func(obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}) { obj.Spec.Replicas = pointer.Int32Ptr(-1) },
+		invalid := map[string]func(obj *{{ .Resource.ImportAlias }}.{{ .Resource.Kind }}){}
+
+		for description, mutate := range invalid {
+			obj := sample("{{ lower .Resource.Kind }}-sample-invalid")
+			mutate(obj)
+			err := k8sClient.Create(ctx, obj, client.DryRunAll)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected the sample with %s to be rejected, got %v",
+				description, err)
+		}
+
+		By("accepting the sample")
+		Expect(k8sClient.Create(ctx, sample("{{ lower .Resource.Kind }}-sample-valid"), client.DryRunAll)).To(Succeed())
+	})
+})
+`
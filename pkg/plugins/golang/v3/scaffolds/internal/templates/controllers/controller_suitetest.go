@@ -47,7 +47,7 @@ type SuiteTest struct {
 func (f *SuiteTest) SetTemplateDefaults() error {
 	if f.Path == "" {
 		if f.MultiGroup && f.Resource.Group != "" {
-			f.Path = filepath.Join("controllers", "%[group]", "suite_test.go")
+			f.Path = filepath.Join("controllers", "%[group-path]", "suite_test.go")
 		} else {
 			f.Path = filepath.Join("controllers", "suite_test.go")
 		}
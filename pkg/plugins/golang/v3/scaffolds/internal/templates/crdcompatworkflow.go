@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+const (
+	// TaskRunnerMake defines the targets of the project in a Makefile run by make
+	TaskRunnerMake = "make"
+	// TaskRunnerTask defines the targets of the project in a Taskfile.yaml run by go-task
+	TaskRunnerTask = "task"
+	// TaskRunnerJust defines the targets of the project in a Justfile run by just
+	TaskRunnerJust = "just"
+)
+
+var _ file.Template = &CRDCompatWorkflow{}
+
+// CRDCompatWorkflow scaffolds the GitHub Actions workflow checking that the CRDs of the pull requests are
+// backwards compatible with the ones of their base branch
+type CRDCompatWorkflow struct {
+	file.TemplateMixin
+
+	// TaskRunner runs the verify-crd-compat target of the project, make, task or just
+	TaskRunner string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *CRDCompatWorkflow) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join(".github", "workflows", "crd-compat.yml")
+	}
+
+	f.TemplateBody = crdCompatWorkflowTemplate
+
+	return nil
+}
+
+// Command returns the command running the verify-crd-compat target against the base revision of the pull request
+func (f *CRDCompatWorkflow) Command() string {
+	const baseRef = "CRD_COMPAT_BASE_REF=${{ github.event.pull_request.base.sha }}"
+	switch f.TaskRunner {
+	case TaskRunnerJust:
+		// The variables of the Justfile are overridden before the recipe
+		return "just " + baseRef + " verify-crd-compat"
+	case TaskRunnerTask:
+		return "task verify-crd-compat " + baseRef
+	default:
+		return "make verify-crd-compat " + baseRef
+	}
+}
+
+// SetupTask returns whether go-task must be installed to run the target
+func (f *CRDCompatWorkflow) SetupTask() bool {
+	return f.TaskRunner == TaskRunnerTask
+}
+
+// SetupJust returns whether just must be installed to run the target
+func (f *CRDCompatWorkflow) SetupJust() bool {
+	return f.TaskRunner == TaskRunnerJust
+}
+
+const crdCompatWorkflowTemplate = `# Checks that the CRDs of the pull requests are backwards compatible with the ones of their base
+# branch: a served version must not lose fields, values or versions that the clients of the
+# previous release rely on.
+name: CRD compatibility
+
+on:
+  pull_request:
+
+jobs:
+  verify-crd-compat:
+    runs-on: ubuntu-latest
+    steps:
+    - uses: actions/checkout@v2
+      with:
+        # The CRDs of the base revision are read from the history of the repository
+        fetch-depth: 0
+    - uses: actions/setup-go@v2
+      with:
+        go-version: '1.15'
+{{- if .SetupTask }}
+    - uses: arduino/setup-task@v1
+{{- end }}
+{{- if .SetupJust }}
+    - uses: extractions/setup-just@v1
+{{- end }}
+    - name: Verify the compatibility of the CRDs
+      run: {{ .Command }}
+`
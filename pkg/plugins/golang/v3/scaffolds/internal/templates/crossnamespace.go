@@ -0,0 +1,174 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &CrossNamespace{}
+
+// CrossNamespace scaffolds a package that tracks the objects created by an owner in other namespaces than its
+// own with a label, and deletes them with a finalizer of the owner
+type CrossNamespace struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+	file.DomainMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *CrossNamespace) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "crossnamespace", "crossnamespace.go")
+	}
+
+	f.TemplateBody = crossNamespaceTemplate
+
+	// The package is shared by all the controllers that use it
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const crossNamespaceTemplate = `{{ .Boilerplate }}
+
+// Package crossnamespace manages the objects that an owner creates in other namespaces than its
+// own. Owner references can not refer to an owner of another namespace: the garbage collector
+// would delete these objects right away, and controller-runtime could not map their events to
+// their owner. Instead, the objects are:
+//   - labeled with the UID of their owner, selecting them in all the namespaces;
+//   - annotated with the group, kind, namespace and name of their owner, which their events are
+//     mapped to by EnqueueOwner;
+//   - deleted by the Finalizer of their owner, which is removed once they are all gone.
+package crossnamespace
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// OwnerUIDLabel is the label holding the UID of the owner of an object.
+	OwnerUIDLabel = "{{ .Domain }}/owner-uid"
+	// OwnerAnnotation is the annotation holding the owner of an object, as
+	// <kind>.<group>/<namespace>/<name>.
+	OwnerAnnotation = "{{ .Domain }}/owner"
+	// Finalizer is the finalizer of the owners, deleting their objects before they are deleted.
+	Finalizer = "{{ .Domain }}/cross-namespace-children"
+)
+
+// SetOwner labels and annotates obj as an object of owner, of the group and kind gk.
+func SetOwner(owner client.Object, gk schema.GroupKind, obj client.Object) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[OwnerUIDLabel] = string(owner.GetUID())
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[OwnerAnnotation] = fmt.Sprintf("%s/%s/%s", gk.String(), owner.GetNamespace(), owner.GetName())
+	obj.SetAnnotations(annotations)
+}
+
+// OwnerOf returns the namespace and name of the owner of obj, if it is of the group and kind gk.
+func OwnerOf(obj client.Object, gk schema.GroupKind) (types.NamespacedName, bool) {
+	parts := strings.Split(obj.GetAnnotations()[OwnerAnnotation], "/")
+	if len(parts) != 3 || parts[0] != gk.String() {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: parts[1], Name: parts[2]}, true
+}
+
+// EnqueueOwner returns an event handler requesting the reconciliation of the owner of the objects,
+// of the group and kind gk, so that it recreates them when they are deleted and removes its
+// finalizer once they are all gone.
+func EnqueueOwner(gk schema.GroupKind) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		owner, ok := OwnerOf(obj, gk)
+		if !ok {
+			return nil
+		}
+		return []reconcile.Request{ {NamespacedName: owner} }
+	})
+}
+
+// EnsureFinalizer adds the Finalizer to owner if it does not have it yet, before it creates its
+// objects.
+func EnsureFinalizer(ctx context.Context, c client.Client, owner client.Object) error {
+	if controllerutil.ContainsFinalizer(owner, Finalizer) {
+		return nil
+	}
+	controllerutil.AddFinalizer(owner, Finalizer)
+	return c.Update(ctx, owner)
+}
+
+// Finalize deletes the objects of owner, of the type of list, e.g. a *corev1.ConfigMapList, in all
+// the namespaces, then removes the Finalizer of owner once they are all gone. It returns whether
+// the finalizer was removed: until then, the deletions of the objects request the reconciliation
+// of owner through EnqueueOwner.
+func Finalize(ctx context.Context, c client.Client, owner client.Object, list client.ObjectList) (bool, error) {
+	if !controllerutil.ContainsFinalizer(owner, Finalizer) {
+		return true, nil
+	}
+	if err := c.List(ctx, list, client.MatchingLabels{OwnerUIDLabel: string(owner.GetUID())}); err != nil {
+		return false, err
+	}
+	objs, err := meta.ExtractList(list)
+	if err != nil {
+		return false, err
+	}
+	for _, runtimeObj := range objs {
+		obj, ok := runtimeObj.(client.Object)
+		if !ok {
+			return false, fmt.Errorf("%T is not a client.Object", runtimeObj)
+		}
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		// The precondition protects a new object with the same name, created meanwhile.
+		uid := obj.GetUID()
+		err := c.Delete(ctx, obj, client.Preconditions{UID: &uid},
+			client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("unable to delete %s/%s: %w", obj.GetNamespace(), obj.GetName(), err)
+		}
+	}
+	if len(objs) != 0 {
+		return false, nil
+	}
+
+	controllerutil.RemoveFinalizer(owner, Finalizer)
+	return true, c.Update(ctx, owner)
+}
+`
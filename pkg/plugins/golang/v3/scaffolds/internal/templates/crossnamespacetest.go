@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &CrossNamespaceTest{}
+
+// CrossNamespaceTest scaffolds the file that tests the crossnamespace package
+type CrossNamespaceTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *CrossNamespaceTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "crossnamespace", "crossnamespace_test.go")
+	}
+
+	f.TemplateBody = crossNamespaceTestTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const crossNamespaceTestTemplate = `{{ .Boilerplate }}
+
+package crossnamespace
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestOwnerOf(t *testing.T) {
+	gk := schema.GroupKind{Group: "test.example.org", Kind: "Frigate"}
+	owner := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{
+		Name: "frigate", Namespace: "fleet", UID: "0b4f6a7e-7c36-4b4d-9a3e-0d5c1f1e2a3b",
+	}}
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "fleet-frigate", Namespace: "harbor", Labels: map[string]string{"app": "frigate"},
+	}}
+
+	if _, ok := OwnerOf(obj, gk); ok {
+		t.Error("expected an object without owner annotation to have no owner")
+	}
+
+	SetOwner(owner, gk, obj)
+	if uid := obj.Labels[OwnerUIDLabel]; uid != string(owner.UID) {
+		t.Errorf("expected the %s label to be %s, got %q", OwnerUIDLabel, owner.UID, uid)
+	}
+	if obj.Labels["app"] != "frigate" {
+		t.Error("expected the other labels to be kept")
+	}
+
+	expected := types.NamespacedName{Namespace: "fleet", Name: "frigate"}
+	if name, ok := OwnerOf(obj, gk); !ok || name != expected {
+		t.Errorf("expected the owner to be %s, got %s", expected, name)
+	}
+	if _, ok := OwnerOf(obj, schema.GroupKind{Group: "test.example.org", Kind: "Destroyer"}); ok {
+		t.Error("expected an owner of another kind not to be returned")
+	}
+}
+`
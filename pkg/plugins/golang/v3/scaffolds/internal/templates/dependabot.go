@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Dependabot{}
+
+// Dependabot scaffolds the Dependabot configuration updating the Go modules and the base images of the project
+type Dependabot struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Dependabot) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join(".github", "dependabot.yml")
+	}
+
+	f.TemplateBody = dependabotTemplate
+
+	return nil
+}
+
+const dependabotTemplate = `# Dependabot configuration of the project, see https://docs.github.com/code-security/dependabot.
+version: 2
+updates:
+- package-ecosystem: gomod
+  directory: /
+  schedule:
+    interval: weekly
+  groups:
+    # The Kubernetes libraries are updated with controller-runtime, which requires their minor version.
+    kubernetes:
+      patterns:
+      - k8s.io/*
+      - sigs.k8s.io/controller-runtime
+  ignore:
+  # The minor versions of the Kubernetes libraries are only bumped by the updates of controller-runtime,
+  # as their newer minor versions are not supported by the current controller-runtime.
+  - dependency-name: k8s.io/*
+    update-types:
+    - version-update:semver-major
+    - version-update:semver-minor
+- package-ecosystem: docker
+  directory: /
+  schedule:
+    interval: weekly
+`
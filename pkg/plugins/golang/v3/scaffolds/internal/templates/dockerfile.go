@@ -25,6 +25,18 @@ var _ file.Template = &Dockerfile{}
 // Dockerfile scaffolds a file that defines the containerized build process
 type Dockerfile struct {
 	file.TemplateMixin
+
+	// SupplyChain indicates whether to label the image with the provenance metadata
+	// used by the SBOM and signing targets
+	SupplyChain bool
+
+	// RunAsRoot indicates whether the manager runs as root, which the baseline Pod Security Standards
+	// profile allows, instead of the non-root user required by the restricted profile
+	RunAsRoot bool
+
+	// AggregatedAPIServer indicates that the manager is an aggregated API server, whose code lives in the
+	// apiserver package instead of the controllers package
+	AggregatedAPIServer bool
 }
 
 // SetTemplateDefaults implements file.Template
@@ -42,6 +54,14 @@ const dockerfileTemplate = `# Build the manager binary
 FROM golang:1.15 as builder
 
 WORKDIR /workspace
+# The proxies and the Go module configuration of the build environment, passed by docker-build,
+# e.g. for the builds behind a corporate proxy
+ARG HTTP_PROXY
+ARG HTTPS_PROXY
+ARG NO_PROXY
+ARG GOPROXY
+ARG GOPRIVATE
+ARG GONOSUMDB
 # Copy the Go Modules manifests
 COPY go.mod go.mod
 COPY go.sum go.sum
@@ -52,17 +72,32 @@ RUN go mod download
 # Copy the go source
 COPY main.go main.go
 COPY api/ api/
+{{- if .AggregatedAPIServer }}
+COPY apiserver/ apiserver/
+{{- else }}
 COPY controllers/ controllers/
+{{- end }}
+COPY internal/ internal/
 
 # Build
 RUN CGO_ENABLED=0 GOOS=linux GOARCH=amd64 GO111MODULE=on go build -a -o manager main.go
 
 # Use distroless as minimal base image to package the manager binary
 # Refer to https://github.com/GoogleContainerTools/distroless for more details
-FROM gcr.io/distroless/static:nonroot
+FROM gcr.io/distroless/static{{ if not .RunAsRoot }}:nonroot{{ end }}
+{{- if .SupplyChain }}
+ARG VCS_REF
+LABEL org.opencontainers.image.revision=$VCS_REF
+{{- end }}
 WORKDIR /
 COPY --from=builder /workspace/manager .
+{{- if .RunAsRoot }}
+# The baseline Pod Security Standards profile allows the manager to run as root
+USER 0:0
+{{- else }}
+# The user must be numeric for the kubelet to check that the manager does not run as root
 USER 65532:65532
+{{- end }}
 
 ENTRYPOINT ["/manager"]
 `
@@ -0,0 +1,56 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package docs
+
+import (
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &APIReference{}
+
+// APIReference scaffolds the Markdown file documenting a kind, whose reference of the fields is generated by
+// hack/apidocs
+type APIReference struct {
+	file.TemplateMixin
+	file.ResourceMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *APIReference) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("docs", "api", strings.ToLower(f.Resource.Kind)+".md")
+	}
+
+	f.TemplateBody = apiReferenceTemplate
+
+	// The reference covers all the versions of the kind
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const apiReferenceTemplate = `# {{ .Resource.Kind }}
+
+TODO(user): describe the {{ .Resource.Kind }} kind. The reference of its fields is generated from the markers
+of its API types by "make api-docs", between the BEGIN and END comments, the rest of the file is kept.
+
+<!-- BEGIN apidocs group={{ .Resource.Domain }} kind={{ .Resource.Kind }} -->
+<!-- END apidocs -->
+`
@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ErrorsTest{}
+
+// ErrorsTest scaffolds the file that tests the errors package
+type ErrorsTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ErrorsTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "errors", "errors_test.go")
+	}
+
+	f.TemplateBody = errorsTestTemplate
+
+	// The package is shared by all the controllers
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const errorsTestTemplate = `{{ .Boilerplate }}
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestResult(t *testing.T) {
+	failure := errors.New("unable to reconcile")
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "test", failure)
+
+	for name, test := range map[string]struct {
+		err      error
+		result   ctrl.Result
+		expected error
+	}{
+		"nil":                   {err: nil},
+		"terminal":              {err: Terminal(failure)},
+		"wrapped terminal":      {err: fmt.Errorf("invalid spec: %w", Terminal(failure))},
+		"transient":             {err: Transient(failure), expected: failure},
+		"transient with delay":  {err: RequeueAfter(failure, time.Minute), result: ctrl.Result{RequeueAfter: time.Minute}},
+		"conflict":              {err: conflict, result: ctrl.Result{Requeue: true}},
+		"untyped":               {err: failure, expected: failure},
+		"terminal wrapping nil": {err: Terminal(nil)},
+	} {
+		result, err := Result(test.err)
+		if result != test.result {
+			t.Errorf("%s: expected the result %+v, got %+v", name, test.result, result)
+		}
+		if !errors.Is(err, test.expected) || (err == nil) != (test.expected == nil) {
+			t.Errorf("%s: expected the error %v, got %v", name, test.expected, err)
+		}
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	if !IsTerminal(fmt.Errorf("invalid spec: %w", Terminalf("replicas must be positive, got %d", -1))) {
+		t.Error("expected the wrapped terminal error to be terminal")
+	}
+	if IsTerminal(Transient(errors.New("unable to reconcile"))) || IsTerminal(nil) {
+		t.Error("expected the transient and nil errors not to be terminal")
+	}
+}
+`
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Events{}
+
+// Events scaffolds a package that records Kubernetes events with typed reasons
+type Events struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Events) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "events", "events.go")
+	}
+
+	f.TemplateBody = eventsTemplate
+
+	// The package is shared by all the controllers
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const eventsTemplate = `{{ .Boilerplate }}
+
+// Package events records Kubernetes events about the reconciled objects with typed reasons,
+// so that the reasons used by the controllers are consistent and can be relied upon by users
+// filtering events, e.g. with kubectl get events --field-selector reason=ReconcileError.
+package events
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reason is the reason of an event, in UpperCamelCase.
+type Reason string
+
+const (
+	// ReasonReconciled is recorded when an object has been reconciled successfully.
+	ReasonReconciled Reason = "Reconciled"
+	// ReasonReconcileError is recorded when the reconciliation of an object has failed.
+	ReasonReconcileError Reason = "ReconcileError"
+	// TODO(user): add the reasons of the events recorded by your controllers.
+)
+
+// Normal records an event of type Normal about obj.
+func Normal(recorder record.EventRecorder, obj runtime.Object, reason Reason,
+	messageFmt string, args ...interface{}) {
+	recorder.Eventf(obj, corev1.EventTypeNormal, string(reason), messageFmt, args...)
+}
+
+// Warning records an event of type Warning about obj, with err as message.
+func Warning(recorder record.EventRecorder, obj runtime.Object, reason Reason, err error) {
+	recorder.Event(obj, corev1.EventTypeWarning, string(reason), err.Error())
+}
+`
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &EventsTest{}
+
+// EventsTest scaffolds the file that tests the events package
+type EventsTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *EventsTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "events", "events_test.go")
+	}
+
+	f.TemplateBody = eventsTestTemplate
+
+	// The package is shared by all the controllers
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const eventsTestTemplate = `{{ .Boilerplate }}
+
+package events
+
+import (
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestNormal(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	Normal(recorder, obj, ReasonReconciled, "%s reconciled", obj.Name)
+
+	if event, expected := <-recorder.Events, "Normal Reconciled test reconciled"; event != expected {
+		t.Errorf("expected event %q, got %q", expected, event)
+	}
+}
+
+func TestWarning(t *testing.T) {
+	recorder := record.NewFakeRecorder(1)
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+	Warning(recorder, obj, ReasonReconcileError, errors.New("unable to reconcile"))
+
+	if event, expected := <-recorder.Events, "Warning ReconcileError unable to reconcile"; event != expected {
+		t.Errorf("expected event %q, got %q", expected, event)
+	}
+}
+`
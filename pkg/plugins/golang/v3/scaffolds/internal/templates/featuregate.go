@@ -0,0 +1,179 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &FeatureGate{}
+
+// FeatureGate scaffolds a package that gates features behind the --feature-gates flag of the manager
+type FeatureGate struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *FeatureGate) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "featuregate", "featuregate.go")
+	}
+
+	f.TemplateBody = featureGateTemplate
+
+	return nil
+}
+
+const featureGateTemplate = `{{ .Boilerplate }}
+
+// Package featuregate gates the features of the manager that are not stable yet, so that
+// they can be shipped disabled by default and enabled with the --feature-gates flag:
+//
+//	--feature-gates=ExampleFeature=true
+package featuregate
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+// PreRelease is the maturity of a feature.
+type PreRelease string
+
+const (
+	// Alpha features are disabled by default and may change or be removed at any time.
+	Alpha = PreRelease("ALPHA")
+	// Beta features are enabled by default and their behavior is not expected to change.
+	Beta = PreRelease("BETA")
+	// GA features are always enabled, their gates are kept for compatibility only.
+	GA = PreRelease("")
+)
+
+// FeatureSpec describes a feature gate.
+type FeatureSpec struct {
+	// Default is the state of the feature when it is not set with the flag.
+	Default bool
+	// PreRelease is the maturity of the feature.
+	PreRelease PreRelease
+}
+
+const (
+	// ExampleFeature gates an example alpha behavior of the controllers.
+	// TODO(user): replace it with the gates of your own features.
+	ExampleFeature Feature = "ExampleFeature"
+)
+
+// defaultFeatures are the feature gates known by the manager.
+var defaultFeatures = map[Feature]FeatureSpec{
+	ExampleFeature: {Default: false, PreRelease: Alpha},
+}
+
+// Default is the FeatureGate of the manager.
+var Default = New(defaultFeatures)
+
+// FeatureGate holds the state of a set of feature gates. It implements flag.Value.
+type FeatureGate struct {
+	mu      sync.RWMutex
+	known   map[Feature]FeatureSpec
+	enabled map[Feature]bool
+}
+
+// New returns a FeatureGate where the known features are set to their default state.
+func New(known map[Feature]FeatureSpec) *FeatureGate {
+	return &FeatureGate{known: known, enabled: make(map[Feature]bool)}
+}
+
+// AddFlag registers the --feature-gates flag in fs.
+func (f *FeatureGate) AddFlag(fs *flag.FlagSet) {
+	options := make([]string, 0, len(f.known))
+	for name, spec := range f.known {
+		options = append(options, fmt.Sprintf("%s=true|false (%s - default=%t)", name, spec.PreRelease, spec.Default))
+	}
+	sort.Strings(options)
+	fs.Var(f, "feature-gates", "A set of key=value pairs that describe feature gates for alpha/experimental features. "+
+		"Options are:\n"+strings.Join(options, "\n"))
+}
+
+// Set implements flag.Value. It parses a comma-separated list of feature=bool pairs.
+func (f *FeatureGate) Set(value string) error {
+	enabled := make(map[Feature]bool)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		name := Feature(strings.TrimSpace(kv[0]))
+		if _, found := f.known[name]; !found {
+			return fmt.Errorf("unknown feature gate %s", name)
+		}
+		if len(kv) != 2 {
+			return fmt.Errorf("missing bool value for feature gate %s", name)
+		}
+		on, err := strconv.ParseBool(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return fmt.Errorf("invalid value of feature gate %s: %v", name, err)
+		}
+		enabled[name] = on
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for name, on := range enabled {
+		f.enabled[name] = on
+	}
+	return nil
+}
+
+// String implements flag.Value.
+func (f *FeatureGate) String() string {
+	if f == nil {
+		return ""
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	pairs := make([]string, 0, len(f.enabled))
+	for name, on := range f.enabled {
+		pairs = append(pairs, fmt.Sprintf("%s=%t", name, on))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Enabled returns true if the feature is enabled. It panics if the feature is unknown.
+func (f *FeatureGate) Enabled(name Feature) bool {
+	spec, found := f.known[name]
+	if !found {
+		panic(fmt.Sprintf("unknown feature gate %s", name))
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if on, set := f.enabled[name]; set {
+		return on
+	}
+	return spec.Default
+}
+`
@@ -63,4 +63,7 @@ testbin/*
 *.swp
 *.swo
 *~
+
+# Journal of kubebuilder undo, local to each copy of the project
+.kubebuilder/undo.json
 `
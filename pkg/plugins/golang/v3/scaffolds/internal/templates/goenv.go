@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &GoEnv{}
+
+// GoEnv scaffolds a file that defines the Go module configuration used when running go commands
+type GoEnv struct {
+	file.TemplateMixin
+
+	// GoProxy is the value of GOPROXY, empty to use the default one
+	GoProxy string
+	// GoPrivate is the value of GOPRIVATE, empty to use the default one
+	GoPrivate string
+	// GoNoSumDB is the value of GONOSUMDB, empty to use the default one
+	GoNoSumDB string
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *GoEnv) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = ".go-env"
+	}
+
+	f.TemplateBody = goEnvTemplate
+
+	return nil
+}
+
+const goEnvTemplate = `# Go module configuration of the project, added to the environment of the go commands
+# run by kubebuilder and by the Makefile, e.g. to download and verify private modules.
+{{- if .GoProxy }}
+GOPROXY={{ .GoProxy }}
+{{- end }}
+{{- if .GoPrivate }}
+GOPRIVATE={{ .GoPrivate }}
+{{- end }}
+{{- if .GoNoSumDB }}
+GONOSUMDB={{ .GoNoSumDB }}
+{{- end }}
+`
@@ -28,6 +28,12 @@ type GoMod struct {
 	file.RepositoryMixin
 
 	ControllerRuntimeVersion string
+
+	// APIServerVersion is the k8s.io/apiserver version required by the aggregated API servers, if not empty
+	APIServerVersion string
+
+	// Replace holds the replace directives of go.mod, e.g. replacing controller-runtime by a fork
+	Replace []string
 }
 
 // SetTemplateDefaults implements file.Template
@@ -50,5 +56,16 @@ go 1.15
 
 require (
 	sigs.k8s.io/controller-runtime {{ .ControllerRuntimeVersion }}
+{{- if .APIServerVersion }}
+	k8s.io/apiserver {{ .APIServerVersion }}
+{{- end }}
+)
+{{- if .Replace }}
+
+replace (
+{{- range .Replace }}
+	{{ . }}
+{{- end }}
 )
+{{- end }}
 `
@@ -0,0 +1,379 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hack
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &CRDLint{}
+
+// CRDLint scaffolds a tool that checks that the CRDs fit in the size limits of the API server and that
+// their validation rules are unlikely to exceed the CEL cost budget
+type CRDLint struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *CRDLint) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("hack", "crdlint", "main.go")
+	}
+
+	f.TemplateBody = crdLintTemplate
+
+	return nil
+}
+
+//nolint:lll
+const crdLintTemplate = `{{ .Boilerplate }}
+
+// crdlint checks that the CRDs found in a directory can be installed in a cluster. It fails if a CRD
+// is larger than the size limits of the API server or of the last-applied-configuration annotation
+// of kubectl apply, and warns about the x-kubernetes-validations rules iterating over unbounded lists,
+// maps or strings, which may exceed the CEL cost budget.
+//
+// The CRDs applied with server-side apply, which does not write the last-applied-configuration
+// annotation, only have to fit in the size limit of the API server. With --server-side-apply=auto,
+// the CRDs too large for the annotation are reported as applied with server-side apply, and
+// --apply-flags prints the flags of kubectl apply applying them, used by the Makefile.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+func main() {
+	var dir, serverSideApply string
+	var applyFlags bool
+	flag.StringVar(&dir, "dir", "config/crd/bases", "directory containing the CRD manifests")
+	flag.StringVar(&serverSideApply, "server-side-apply", serverSideApplyFalse,
+		"whether the CRDs are applied with server-side apply: true, false, or auto to apply them with it "+
+			"only if a CRD is too large for the last-applied-configuration annotation")
+	flag.BoolVar(&applyFlags, "apply-flags", false,
+		"print the flags of kubectl apply applying the CRDs as set by --server-side-apply instead of checking them")
+	flag.Parse()
+
+	switch serverSideApply {
+	case serverSideApplyTrue, serverSideApplyFalse, serverSideApplyAuto:
+	default:
+		fmt.Fprintf(os.Stderr, "invalid --server-side-apply %q, must be one of true, false or auto\n", serverSideApply)
+		os.Exit(1)
+	}
+
+	crds, err := readDir(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to check the CRDs: %v\n", err)
+		os.Exit(1)
+	}
+
+	if applyFlags {
+		if serverSideApply == serverSideApplyTrue ||
+			serverSideApply == serverSideApplyAuto && largestSize(crds) > maxAnnotationsSize {
+			fmt.Println("--server-side --force-conflicts")
+		}
+		return
+	}
+
+	var problems []problem
+	for _, crd := range crds {
+		crdProblems, err := lint(crd, serverSideApply)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to check the CRDs: %v\n", err)
+			os.Exit(1)
+		}
+		problems = append(problems, crdProblems...)
+	}
+
+	errors := 0
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+		if problem.severity == severityError {
+			errors++
+		}
+	}
+	if errors != 0 {
+		fmt.Fprintf(os.Stderr, "found %d error(s) in the CRDs\n", errors)
+		os.Exit(1)
+	}
+}
+
+const (
+	// maxAnnotationsSize is the maximum size of the annotations of an object, which hold the
+	// last-applied-configuration annotation written by kubectl apply.
+	maxAnnotationsSize = 256 * 1024
+	// maxObjectSize is the maximum size of an object stored by the API server.
+	maxObjectSize = 1536 * 1024
+)
+
+const (
+	serverSideApplyTrue  = "true"
+	serverSideApplyFalse = "false"
+	serverSideApplyAuto  = "auto"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// problem is an issue found in a CRD.
+type problem struct {
+	crd      string
+	severity string
+	message  string
+}
+
+func (p problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.severity, p.crd, p.message)
+}
+
+type object = map[string]interface{}
+
+// readDir returns the CRDs found in the YAML files of dir. A missing dir holds no CRD.
+func readDir(dir string) ([]object, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var crds []object
+	for _, f := range files {
+		if filepath.Ext(f.Name()) != ".yaml" {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range strings.Split(string(content), "\n---") {
+			var crd object
+			if err := yaml.Unmarshal([]byte(doc), &crd); err != nil {
+				return nil, fmt.Errorf("unable to parse %s: %v", f.Name(), err)
+			}
+			if crd["kind"] != "CustomResourceDefinition" {
+				continue
+			}
+			crds = append(crds, crd)
+		}
+	}
+	return crds, nil
+}
+
+// largestSize returns the size of the JSON representation of the largest CRD.
+func largestSize(crds []object) int {
+	largest := 0
+	for _, crd := range crds {
+		if size := jsonSize(crd); size > largest {
+			largest = size
+		}
+	}
+	return largest
+}
+
+// lint checks a CRD decoded from its YAML representation, applied with server-side apply as set by
+// serverSideApply.
+func lint(crd object, serverSideApply string) ([]problem, error) {
+	name, _ := field(crd, "metadata")["name"].(string)
+	var problems []problem
+
+	// kubectl apply stores the JSON representation of the CRD in its last-applied-configuration annotation,
+	// which is why the CRD must also fit in the size limit of the annotations.
+	content, err := json.Marshal(crd)
+	if err != nil {
+		return nil, err
+	}
+	switch size := len(content); {
+	case size > maxObjectSize:
+		problems = append(problems, problem{name, severityError, fmt.Sprintf(
+			"the CRD is %d bytes, more than the %d bytes the API server stores for an object; %s",
+			size, maxObjectSize, slimmingHint(crd))})
+	case size > maxAnnotationsSize && serverSideApply == serverSideApplyAuto:
+		problems = append(problems, problem{name, severityWarning, fmt.Sprintf(
+			"the CRD is %d bytes, too large for the %d bytes last-applied-configuration annotation of "+
+				"kubectl apply, it is applied with kubectl apply --server-side", size, maxAnnotationsSize)})
+	case size > maxAnnotationsSize && serverSideApply == serverSideApplyFalse:
+		problems = append(problems, problem{name, severityError, fmt.Sprintf(
+			"the CRD is %d bytes, too large for the %d bytes last-applied-configuration annotation of "+
+				"kubectl apply; install it with kubectl apply --server-side or %s",
+			size, maxAnnotationsSize, slimmingHint(crd))})
+	}
+
+	for _, version := range schemas(crd) {
+		for _, unbounded := range unboundedRuleInputs(version.schema, "") {
+			problems = append(problems, problem{name, severityWarning, fmt.Sprintf(
+				"version %s: the x-kubernetes-validations rules of %s iterate over %s, which has no %s: "+
+					"the API server may reject the rules for exceeding the CEL cost budget, "+
+					"bound it with the +kubebuilder:validation:%s marker",
+				version.name, unbounded.rulePath, unbounded.path, unbounded.limit, unbounded.marker)})
+		}
+	}
+
+	return problems, nil
+}
+
+type versionSchema struct {
+	name   string
+	schema object
+}
+
+// schemas returns the schema of every version of the CRD, supporting both v1 and v1beta1 CRDs.
+func schemas(crd object) []versionSchema {
+	spec := field(crd, "spec")
+	topLevelSchema := field(field(spec, "validation"), "openAPIV3Schema")
+	rawVersions, _ := spec["versions"].([]interface{})
+	var versions []versionSchema
+	for _, rawVersion := range rawVersions {
+		version, _ := rawVersion.(object)
+		name, _ := version["name"].(string)
+		schema := field(field(version, "schema"), "openAPIV3Schema")
+		if len(schema) == 0 {
+			schema = topLevelSchema
+		}
+		versions = append(versions, versionSchema{name, schema})
+	}
+	return versions
+}
+
+// slimmingHint suggests how to reduce the size of the CRD, naming its largest version schemas.
+func slimmingHint(crd object) string {
+	var sizes []string
+	versions := schemas(crd)
+	sort.SliceStable(versions, func(i, j int) bool { return jsonSize(versions[i].schema) > jsonSize(versions[j].schema) })
+	for _, version := range versions {
+		sizes = append(sizes, fmt.Sprintf("%s (%d bytes)", version.name, jsonSize(version.schema)))
+	}
+	return fmt.Sprintf("reduce the size of the version schemas, %s: drop the descriptions with the "+
+		"crd:maxDescLen=0 option of controller-gen, stop serving old versions, or replace large embedded "+
+		"types with the +kubebuilder:pruning:PreserveUnknownFields and +kubebuilder:validation:Schemaless markers",
+		strings.Join(sizes, ", "))
+}
+
+type unboundedField struct {
+	rulePath, path string
+	limit, marker  string
+}
+
+// unboundedRuleInputs returns the unbounded lists, maps and strings the x-kubernetes-validations rules
+// found in schema iterate over, whose cost can not be estimated by the API server.
+func unboundedRuleInputs(schema object, path string) []unboundedField {
+	var unbounded []unboundedField
+	if rules, _ := schema["x-kubernetes-validations"].([]interface{}); len(rules) != 0 {
+		rulePath := path
+		if rulePath == "" {
+			rulePath = "the root object"
+		}
+		for _, f := range unboundedFields(schema, path, true) {
+			f.rulePath = rulePath
+			unbounded = append(unbounded, f)
+		}
+	}
+	for _, child := range children(schema, path) {
+		unbounded = append(unbounded, unboundedRuleInputs(child.schema, child.path)...)
+	}
+	return unbounded
+}
+
+// unboundedFields returns the unbounded lists and maps found in schema, and schema itself if it is the
+// unbounded string the rules are declared on. The strings of the children are not reported, as the rules
+// of an object seldom process all of them.
+func unboundedFields(schema object, path string, root bool) []unboundedField {
+	var unbounded []unboundedField
+	display := path
+	if display == "" {
+		display = "the root object"
+	}
+	switch schema["type"] {
+	case "array":
+		if _, found := schema["maxItems"]; !found {
+			unbounded = append(unbounded, unboundedField{path: display, limit: "maxItems", marker: "MaxItems"})
+		}
+	case "string":
+		_, bounded := schema["maxLength"]
+		_, enum := schema["enum"]
+		if !bounded && !enum && root {
+			unbounded = append(unbounded, unboundedField{path: display, limit: "maxLength", marker: "MaxLength"})
+		}
+	case "object":
+		if _, isMap := schema["additionalProperties"]; isMap {
+			if _, found := schema["maxProperties"]; !found {
+				unbounded = append(unbounded, unboundedField{path: display, limit: "maxProperties", marker: "MaxProperties"})
+			}
+		}
+	}
+	for _, child := range children(schema, path) {
+		unbounded = append(unbounded, unboundedFields(child.schema, child.path, false)...)
+	}
+	return unbounded
+}
+
+type childSchema struct {
+	path   string
+	schema object
+}
+
+// children returns the schemas of the properties, list items and map values of schema.
+func children(schema object, path string) []childSchema {
+	var result []childSchema
+	properties := field(schema, "properties")
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, _ := properties[name].(object)
+		childPath := name
+		if path != "" {
+			childPath = path + "." + name
+		}
+		result = append(result, childSchema{childPath, property})
+	}
+	if items := field(schema, "items"); len(items) != 0 {
+		result = append(result, childSchema{path + "[]", items})
+	}
+	if values := field(schema, "additionalProperties"); len(values) != 0 {
+		result = append(result, childSchema{path + "[*]", values})
+	}
+	return result
+}
+
+func field(obj object, name string) object {
+	value, _ := obj[name].(object)
+	return value
+}
+
+func jsonSize(obj object) int {
+	content, _ := json.Marshal(obj)
+	return len(content)
+}
+`
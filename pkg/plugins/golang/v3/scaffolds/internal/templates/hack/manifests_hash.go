@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hack
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ManifestsHash{}
+
+// ManifestsHash scaffolds a tool that hashes the inputs and outputs of the manifests generation, which lets
+// make manifests skip controller-gen when nothing changed
+type ManifestsHash struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ManifestsHash) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("hack", "manifestshash", "main.go")
+	}
+
+	f.TemplateBody = manifestsHashTemplate
+
+	return nil
+}
+
+const manifestsHashTemplate = `{{ .Boilerplate }}
+
+// manifestshash hashes the inputs and outputs of the manifests generation: the options of
+// controller-gen, the Go files of the project, whose markers describe the manifests, and the
+// generated manifests. Without --check, the hash is recorded in --file. With --check, it exits
+// with 0 if the recorded hash is up to date, in which case the generation can be skipped, and
+// with 1 otherwise.
+package main
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var hashFile, options, outputs string
+	var check bool
+	flag.StringVar(&hashFile, "file", "bin/manifests.sha256", "file recording the hash")
+	flag.StringVar(&options, "options", "", "options of the generation")
+	flag.StringVar(&outputs, "outputs", "config/crd/bases,config/rbac/role.yaml,config/webhook",
+		"comma-separated list of the generated files and directories")
+	flag.BoolVar(&check, "check", false, "check the recorded hash instead of recording it")
+	flag.Parse()
+
+	hash, err := computeHash(options, strings.Split(outputs, ","))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to hash the manifests: %v\n", err)
+		os.Exit(2)
+	}
+
+	if check {
+		recorded, err := ioutil.ReadFile(hashFile)
+		if err != nil || strings.TrimSpace(string(recorded)) != hash {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(hashFile), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to record the hash: %v\n", err)
+		os.Exit(2)
+	}
+	if err := ioutil.WriteFile(hashFile, []byte(hash+"\n"), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to record the hash: %v\n", err)
+		os.Exit(2)
+	}
+}
+
+// computeHash returns the hash of options, of the Go files of the project and of the outputs.
+func computeHash(options string, outputs []string) (string, error) {
+	files, err := listFiles(".", func(path string) bool { return filepath.Ext(path) == ".go" })
+	if err != nil {
+		return "", err
+	}
+	for _, output := range outputs {
+		outputFiles, err := listFiles(output, func(string) bool { return true })
+		if err != nil {
+			return "", err
+		}
+		files = append(files, outputFiles...)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "options %s\n", options)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "file %s %d\n", file, len(content))
+		_, _ = h.Write(content)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// listFiles returns the files of root that match, in lexical order. A missing root has no file, hidden
+// directories and the directories holding binaries and dependencies are skipped.
+func listFiles(root string, match func(string) bool) ([]string, error) {
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == root {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != root && (strings.HasPrefix(name, ".") || name == "bin" || name == "testbin" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if match(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+`
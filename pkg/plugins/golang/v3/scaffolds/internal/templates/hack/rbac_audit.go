@@ -0,0 +1,435 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hack
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &RBACAudit{}
+
+// RBACAudit scaffolds a tool that compares the RBAC rules of the manager with the requests it made, recorded in
+// an audit log of the API server, reporting the rules it never used and the requests it was denied
+type RBACAudit struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *RBACAudit) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("hack", "rbacaudit", "main.go")
+	}
+
+	f.TemplateBody = rbacAuditTemplate
+
+	return nil
+}
+
+//nolint:lll
+const rbacAuditTemplate = `{{ .Boilerplate }}
+
+// rbacaudit compares the RBAC rules of the manager role, generated from the +kubebuilder:rbac
+// markers, with the requests the manager made to the API server, recorded in an audit log of the
+// API server, e.g. while running the tests of the samples or an end-to-end test in a kind cluster
+// with auditing enabled:
+//
+//   - the rules the manager never used are reported with the markers granting them, so that the
+//     permissions accumulated by the markers can be trimmed;
+//   - the requests of the manager denied by the API server, or allowed by none of the roles bound
+//     to its service account in --dir, are reported as missing rules.
+//
+// The requests of the manager are the ones of the service accounts of its namespace, read from
+// config/default/kustomization.yaml by default. The audit log only records the requests made while
+// it was written: a rule used on a path the tests do not exercise, e.g. the deletion of an object,
+// is reported as unused. It fails if a rule is missing, and with --strict if a rule is unused.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// manifest is a Role, a ClusterRole, a RoleBinding or a ClusterRoleBinding of the RBAC manifests
+type manifest struct {
+	Kind     string
+	Metadata struct {
+		Name string
+	}
+	Rules    []rbacv1.PolicyRule
+	RoleRef  rbacv1.RoleRef
+	Subjects []rbacv1.Subject
+}
+
+// event is an event of an audit log of the API server, of the audit.k8s.io/v1 API
+type event struct {
+	Verb string
+	User struct {
+		Username string
+	}
+	ObjectRef *struct {
+		APIGroup, Resource, Subresource string
+	}
+	ResponseStatus *struct {
+		Code int
+	}
+}
+
+// request is a kind of request of the manager, the verb of a resource of an API group
+type request struct {
+	group, resource, verb string
+}
+
+func (r request) String() string {
+	if r.group == "" {
+		return r.verb + " " + r.resource
+	}
+	return r.verb + " " + r.group + "/" + r.resource
+}
+
+// marker is a +kubebuilder:rbac marker granting the rules of the manager role
+type marker struct {
+	position                string
+	groups, resources, verbs []string
+}
+
+var markerRegexp = regexp.MustCompile(` + "`" + `\+kubebuilder:rbac:(\S+)` + "`" + `)
+
+func main() {
+	var dir, role, auditLog, namespace string
+	var strict bool
+	flag.StringVar(&dir, "dir", "config/rbac", "directory of the RBAC manifests of the manager")
+	flag.StringVar(&role, "role", "manager-role", "name of the role generated from the +kubebuilder:rbac markers, whose unused rules are reported")
+	flag.StringVar(&auditLog, "audit-log", "", "audit log of the API server, with an audit.k8s.io/v1 event in JSON per line")
+	flag.StringVar(&namespace, "namespace", "", "namespace of the manager, read from config/default/kustomization.yaml by default")
+	flag.BoolVar(&strict, "strict", false, "fail if a rule of the role is unused")
+	flag.Parse()
+
+	if auditLog == "" {
+		fail("--audit-log is required, e.g. --audit-log=bin/audit.log")
+	}
+	if namespace == "" {
+		var err error
+		if namespace, err = readNamespace(filepath.Join("config", "default", "kustomization.yaml")); err != nil {
+			fail("unable to read the namespace of the manager, set it with --namespace: %v", err)
+		}
+	}
+	manifests, err := readManifests(dir)
+	if err != nil {
+		fail("unable to read the RBAC manifests: %v", err)
+	}
+	audited, bound := roles(manifests, role)
+	if audited == nil {
+		fail("no role %s in %s", role, dir)
+	}
+	requests, denied, err := readAuditLog(auditLog, "system:serviceaccount:"+namespace+":")
+	if err != nil {
+		fail("unable to read the audit log: %v", err)
+	}
+	if len(requests) == 0 {
+		fail("no request of the service accounts of the namespace %s in %s", namespace, auditLog)
+	}
+	markers, err := readMarkers(".")
+	if err != nil {
+		fail("unable to read the +kubebuilder:rbac markers: %v", err)
+	}
+
+	var unused []string
+	for _, rule := range audited.Rules {
+		for _, group := range rule.APIGroups {
+			for _, resource := range rule.Resources {
+				for _, verb := range rule.Verbs {
+					r := request{group: group, resource: resource, verb: verb}
+					if used(r, requests) {
+						continue
+					}
+					message := "  " + r.String()
+					if positions := grantedBy(r, markers); len(positions) != 0 {
+						message += ", granted by " + strings.Join(positions, ", ")
+					}
+					unused = append(unused, message)
+				}
+			}
+		}
+	}
+	var missing []string
+	for r, count := range requests {
+		if denied[r] != 0 || !allowed(r, bound) {
+			missing = append(missing, fmt.Sprintf("  %s, made %d times", r, count))
+		}
+	}
+	sort.Strings(unused)
+	sort.Strings(missing)
+
+	if len(unused) != 0 {
+		fmt.Printf("Rules of %s never used by the %d kinds of requests of the manager in %s:\n%s\n",
+			role, len(requests), auditLog, strings.Join(unused, "\n"))
+	}
+	if len(missing) != 0 {
+		fmt.Printf("Requests of the manager denied by the API server or allowed by no role of %s, "+
+			"add a +kubebuilder:rbac marker granting them:\n%s\n", dir, strings.Join(missing, "\n"))
+	}
+	if len(missing) != 0 || strict && len(unused) != 0 {
+		os.Exit(1)
+	}
+	if len(unused) == 0 {
+		fmt.Printf("Every rule of %s was used by the manager in %s\n", role, auditLog)
+	}
+}
+
+// readNamespace reads the namespace of a kustomization
+func readNamespace(path string) (string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	kustomization := struct {
+		Namespace string
+	}{}
+	if err := yaml.Unmarshal(content, &kustomization); err != nil {
+		return "", err
+	}
+	if kustomization.Namespace == "" {
+		return "", fmt.Errorf("%s has no namespace", path)
+	}
+	return kustomization.Namespace, nil
+}
+
+// readManifests reads the objects of the YAML files of dir
+func readManifests(dir string) ([]manifest, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var manifests []manifest
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range bytes.Split(content, []byte("\n---")) {
+			m := manifest{}
+			if err := yaml.Unmarshal(document, &m); err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			manifests = append(manifests, m)
+		}
+	}
+	return manifests, nil
+}
+
+// roles returns the role whose unused rules are reported and the roles bound to service accounts,
+// which allow the requests of the manager
+func roles(manifests []manifest, name string) (audited *manifest, bound []manifest) {
+	byName := map[string]manifest{}
+	for _, m := range manifests {
+		if m.Kind == "Role" || m.Kind == "ClusterRole" {
+			byName[m.Kind+"/"+m.Metadata.Name] = m
+		}
+	}
+	for i, m := range manifests {
+		if (m.Kind == "Role" || m.Kind == "ClusterRole") && m.Metadata.Name == name {
+			audited = &manifests[i]
+		}
+		if m.Kind != "RoleBinding" && m.Kind != "ClusterRoleBinding" {
+			continue
+		}
+		for _, subject := range m.Subjects {
+			if role, found := byName[m.RoleRef.Kind+"/"+m.RoleRef.Name]; found && subject.Kind == rbacv1.ServiceAccountKind {
+				bound = append(bound, role)
+				break
+			}
+		}
+	}
+	return audited, bound
+}
+
+// readAuditLog returns the number of requests of the users whose name starts with user in an audit
+// log, and the number of them denied by the API server. The requests recorded at several stages are
+// counted at each stage.
+func readAuditLog(path, user string) (requests, denied map[request]int, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	requests, denied = map[request]int{}, map[request]int{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		e := event{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if !strings.HasPrefix(e.User.Username, user) || e.ObjectRef == nil || e.ObjectRef.Resource == "" {
+			continue
+		}
+		r := request{group: e.ObjectRef.APIGroup, resource: e.ObjectRef.Resource, verb: e.Verb}
+		if e.ObjectRef.Subresource != "" {
+			r.resource += "/" + e.ObjectRef.Subresource
+		}
+		requests[r]++
+		if e.ResponseStatus != nil && e.ResponseStatus.Code == 403 {
+			denied[r]++
+		}
+	}
+	return requests, denied, scanner.Err()
+}
+
+// readMarkers reads the +kubebuilder:rbac markers of the Go files of dir
+func readMarkers(dir string) ([]marker, error) {
+	var markers []marker
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && (strings.HasPrefix(info.Name(), ".") || info.Name() == "vendor" || info.Name() == "bin") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for i, line := range strings.Split(string(content), "\n") {
+			match := markerRegexp.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			m := marker{position: fmt.Sprintf("%s:%d", path, i+1)}
+			for _, argument := range strings.Split(match[1], ",") {
+				name := strings.SplitN(argument, "=", 2)
+				if len(name) != 2 {
+					continue
+				}
+				values := strings.Split(name[1], ";")
+				switch name[0] {
+				case "groups":
+					for i := range values {
+						if values[i] == "core" {
+							values[i] = ""
+						}
+					}
+					m.groups = values
+				case "resources":
+					m.resources = values
+				case "verbs":
+					m.verbs = values
+				}
+			}
+			markers = append(markers, m)
+		}
+		return nil
+	})
+	return markers, err
+}
+
+// used returns true if a request of the manager matches the kind of request r of a rule
+func used(r request, requests map[request]int) bool {
+	for used := range requests {
+		if matches(r.group, used.group) && matches(r.verb, used.verb) && matchesResource(r.resource, used.resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowed returns true if a rule of the roles allows the kind of request r
+func allowed(r request, roles []manifest) bool {
+	for _, role := range roles {
+		for _, rule := range role.Rules {
+			if contains(rule.APIGroups, r.group) && contains(rule.Verbs, r.verb) &&
+				containsResource(rule.Resources, r.resource) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// grantedBy returns the positions of the markers granting the kind of request r
+func grantedBy(r request, markers []marker) []string {
+	var positions []string
+	for _, m := range markers {
+		if contains(m.groups, r.group) && contains(m.resources, r.resource) && contains(m.verbs, r.verb) {
+			positions = append(positions, m.position)
+		}
+	}
+	return positions
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if matches(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsResource(resources []string, resource string) bool {
+	for _, r := range resources {
+		if matchesResource(r, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns true if the value of a rule, which may be *, matches a value
+func matches(rule, value string) bool {
+	return rule == rbacv1.VerbAll || rule == value
+}
+
+// matchesResource returns true if the resource of a rule, which may be * or */<subresource>, matches
+// a resource
+func matchesResource(rule, resource string) bool {
+	if matches(rule, resource) {
+		return true
+	}
+	if strings.HasPrefix(rule, "*/") {
+		parts := strings.SplitN(resource, "/", 2)
+		return len(parts) == 2 && rule[2:] == parts[1]
+	}
+	return false
+}
+
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+`
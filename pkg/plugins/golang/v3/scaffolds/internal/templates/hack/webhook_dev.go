@@ -0,0 +1,300 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hack
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &WebhookDev{}
+
+// WebhookDev scaffolds a tool that serves the webhooks of the manager run on the machine of the developer to a
+// development cluster, through a Service forwarding the requests of the API server to the machine
+type WebhookDev struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+	file.ProjectNameMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *WebhookDev) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("hack", "webhook-dev", "main.go")
+	}
+
+	f.TemplateBody = webhookDevTemplate
+
+	return nil
+}
+
+//nolint:lll
+const webhookDevTemplate = `{{ .Boilerplate }}
+
+// webhook-dev serves the webhooks of the manager run on the machine of the developer, e.g. by
+// make run, to the API server of a development cluster, such as a kind cluster. It generates a
+// serving certificate of the webhooks, signed by a self-signed CA, in the directory read by the
+// manager, and applies to the cluster:
+//
+//   - a Service without selector, and its Endpoints, forwarding the requests of the API server to
+//     the address of the machine, which must be reachable from the cluster, e.g. 172.17.0.1 for a
+//     kind cluster on Linux. With --tunnel, the Service of a tunnel created by ktunnel or inlets
+//     is used instead, for the clusters that can not reach the machine;
+//   - the webhook configurations of config/webhook/manifests.yaml, generated by make manifests,
+//     prefixed with the name of the Service and calling the webhooks through it with the CA.
+//
+// Run it again when the webhooks change, and with --delete to delete the objects.
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// name is the name of the Service forwarding the requests to the manager, and the prefix of the
+	// names of the webhook configurations.
+	name = "{{ .ProjectName }}-webhook-dev"
+	// manifests are the webhook configurations generated by controller-gen.
+	manifests = "config/webhook/manifests.yaml"
+)
+
+type object = map[string]interface{}
+
+func main() {
+	var address, namespace, certDir string
+	var port int
+	var tunnel, remove bool
+	flag.StringVar(&address, "address", "",
+		"IP address of the machine running the manager, reachable from the cluster, "+
+			"e.g. 172.17.0.1 for a kind cluster on Linux")
+	flag.IntVar(&port, "port", 9443, "port of the webhook server of the manager")
+	flag.StringVar(&namespace, "namespace", "default", "namespace of the Service forwarding the requests to the manager")
+	flag.StringVar(&certDir, "cert-dir", filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs"),
+		"directory of the serving certificate, read by the manager, see its --webhook-cert-dir flag")
+	flag.BoolVar(&tunnel, "tunnel", false, "use the Service of a tunnel named "+name+" instead of --address, "+
+		"e.g. created by: ktunnel expose "+name+" 443:9443")
+	flag.BoolVar(&remove, "delete", false, "delete the Service and the webhook configurations")
+	flag.Parse()
+
+	if !tunnel && !remove && net.ParseIP(address) == nil {
+		fmt.Fprintln(os.Stderr, "--address must be the IP address of the machine running the manager, or --tunnel set")
+		os.Exit(1)
+	}
+
+	var caBundle []byte
+	if !remove {
+		var err error
+		if caBundle, err = writeCertificate(certDir, fmt.Sprintf("%s.%s.svc", name, namespace)); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to write the serving certificate: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	configurations, err := webhookConfigurations(namespace, caBundle)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to read the webhook configurations, run make manifests first: %v\n", err)
+		os.Exit(1)
+	}
+	objects := configurations
+	if !tunnel {
+		objects = append(service(namespace, address, port), objects...)
+	}
+
+	args := []string{"apply", "-f", "-"}
+	if remove {
+		args = []string{"delete", "--ignore-not-found", "-f", "-"}
+	}
+	if err := kubectl(objects, args...); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to %s the objects: %v\n", args[0], err)
+		os.Exit(1)
+	}
+	if !remove {
+		fmt.Printf("The webhooks call the manager on port %d, which serves the certificate of %s: run it with "+
+			"make run if it is its default directory, or with --webhook-cert-dir=%s\n", port, certDir, certDir)
+	}
+}
+
+// writeCertificate writes the tls.crt and tls.key serving certificate of host to dir, signed by a new
+// self-signed CA, and returns the certificate of the CA.
+func writeCertificate(dir, host string) ([]byte, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	notBefore := time.Now().Add(-time.Hour)
+	notAfter := notBefore.Add(365 * 24 * time.Hour)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name + "-ca"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host, host + ".cluster.local"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "tls.crt"),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "tls.key"),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER}), nil
+}
+
+// webhookConfigurations returns the webhook configurations of the manifests, renamed and calling the
+// webhooks through the Service of namespace, trusting caBundle.
+func webhookConfigurations(namespace string, caBundle []byte) ([]object, error) {
+	content, err := ioutil.ReadFile(manifests)
+	if err != nil {
+		return nil, err
+	}
+	var configurations []object
+	for _, document := range strings.Split(string(content), "\n---") {
+		var configuration object
+		if err := yaml.Unmarshal([]byte(document), &configuration); err != nil {
+			return nil, err
+		}
+		kind, _ := configuration["kind"].(string)
+		if kind != "MutatingWebhookConfiguration" && kind != "ValidatingWebhookConfiguration" {
+			continue
+		}
+		metadata, _ := configuration["metadata"].(object)
+		if metadata == nil {
+			return nil, fmt.Errorf("%s without metadata", kind)
+		}
+		metadata["name"] = fmt.Sprintf("%s-%v", name, metadata["name"])
+		webhooks, _ := configuration["webhooks"].([]interface{})
+		for _, webhook := range webhooks {
+			webhook, _ := webhook.(object)
+			clientConfig, _ := webhook["clientConfig"].(object)
+			if clientConfig == nil {
+				continue
+			}
+			svc := object{"name": name, "namespace": namespace, "port": 443}
+			if existing, _ := clientConfig["service"].(object); existing != nil && existing["path"] != nil {
+				svc["path"] = existing["path"]
+			}
+			clientConfig["service"] = svc
+			if caBundle != nil {
+				clientConfig["caBundle"] = base64.StdEncoding.EncodeToString(caBundle)
+			}
+		}
+		configurations = append(configurations, configuration)
+	}
+	if len(configurations) == 0 {
+		return nil, fmt.Errorf("%s holds no webhook configuration", manifests)
+	}
+	return configurations, nil
+}
+
+// service returns the Service of namespace forwarding the requests of the API server to port of address,
+// and its Endpoints.
+func service(namespace, address string, port int) []object {
+	metadata := object{"name": name, "namespace": namespace}
+	return []object{
+		{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   metadata,
+			"spec": object{
+				"ports": []interface{}{object{"name": "https", "port": 443, "targetPort": port}},
+			},
+		},
+		{
+			"apiVersion": "v1",
+			"kind":       "Endpoints",
+			"metadata":   metadata,
+			"subsets": []interface{}{object{
+				"addresses": []interface{}{object{"ip": address}},
+				"ports":     []interface{}{object{"name": "https", "port": port}},
+			}},
+		},
+	}
+}
+
+// kubectl runs kubectl with args, reading objects from its standard input.
+func kubectl(objects []object, args ...string) error {
+	var input bytes.Buffer
+	for _, obj := range objects {
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		input.WriteString("---\n")
+		input.Write(content)
+	}
+	cmd := exec.Command("kubectl", args...)
+	cmd.Stdin = &input
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+`
@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Interceptor{}
+
+// Interceptor scaffolds a package that wraps a client, e.g. the fake client of the unit tests, calling
+// functions instead of its methods
+type Interceptor struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Interceptor) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "interceptor", "interceptor.go")
+	}
+
+	f.TemplateBody = interceptorTemplate
+
+	// The package is shared by all the controllers that use it
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const interceptorTemplate = `{{ .Boilerplate }}
+
+// Package interceptor wraps a client.Client, e.g. the fake client of the unit tests of the
+// controllers, calling functions instead of some of its methods, to make the client fail as the
+// API server would or to record its calls:
+//
+//	c := interceptor.NewClient(fake.NewClientBuilder().Build(), interceptor.Funcs{
+//		Update: func(ctx context.Context, c client.Client, obj client.Object, opts ...client.UpdateOption) error {
+//			return apierrors.NewConflict(schema.GroupResource{}, obj.GetName(), errors.New("injected"))
+//		},
+//	})
+//
+// It mirrors the interceptor package of controller-runtime v0.15, which replaces it once the
+// project upgrades controller-runtime.
+package interceptor
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Funcs are the functions called instead of the methods of the wrapped client, with the wrapped
+// client. The methods whose function is nil call the wrapped client.
+type Funcs struct {
+	Get          func(ctx context.Context, c client.Client, key client.ObjectKey, obj client.Object) error
+	List         func(ctx context.Context, c client.Client, list client.ObjectList, opts ...client.ListOption) error
+	Create       func(ctx context.Context, c client.Client, obj client.Object, opts ...client.CreateOption) error
+	Delete       func(ctx context.Context, c client.Client, obj client.Object, opts ...client.DeleteOption) error
+	DeleteAllOf  func(ctx context.Context, c client.Client, obj client.Object, opts ...client.DeleteAllOfOption) error
+	Update       func(ctx context.Context, c client.Client, obj client.Object, opts ...client.UpdateOption) error
+	Patch        func(ctx context.Context, c client.Client, obj client.Object, patch client.Patch, opts ...client.PatchOption) error
+	StatusUpdate func(ctx context.Context, c client.Client, obj client.Object, opts ...client.UpdateOption) error
+	StatusPatch  func(ctx context.Context, c client.Client, obj client.Object, patch client.Patch, opts ...client.PatchOption) error
+}
+
+// NewClient returns a client calling the functions of funcs instead of the methods of c
+func NewClient(c client.Client, funcs Funcs) client.Client {
+	return &interceptor{Client: c, funcs: funcs}
+}
+
+// interceptor is a client.Client calling funcs
+type interceptor struct {
+	client.Client
+	funcs Funcs
+}
+
+// Get implements client.Client
+func (i *interceptor) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if i.funcs.Get != nil {
+		return i.funcs.Get(ctx, i.Client, key, obj)
+	}
+	return i.Client.Get(ctx, key, obj)
+}
+
+// List implements client.Client
+func (i *interceptor) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if i.funcs.List != nil {
+		return i.funcs.List(ctx, i.Client, list, opts...)
+	}
+	return i.Client.List(ctx, list, opts...)
+}
+
+// Create implements client.Client
+func (i *interceptor) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if i.funcs.Create != nil {
+		return i.funcs.Create(ctx, i.Client, obj, opts...)
+	}
+	return i.Client.Create(ctx, obj, opts...)
+}
+
+// Delete implements client.Client
+func (i *interceptor) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if i.funcs.Delete != nil {
+		return i.funcs.Delete(ctx, i.Client, obj, opts...)
+	}
+	return i.Client.Delete(ctx, obj, opts...)
+}
+
+// DeleteAllOf implements client.Client
+func (i *interceptor) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if i.funcs.DeleteAllOf != nil {
+		return i.funcs.DeleteAllOf(ctx, i.Client, obj, opts...)
+	}
+	return i.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// Update implements client.Client
+func (i *interceptor) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if i.funcs.Update != nil {
+		return i.funcs.Update(ctx, i.Client, obj, opts...)
+	}
+	return i.Client.Update(ctx, obj, opts...)
+}
+
+// Patch implements client.Client
+func (i *interceptor) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if i.funcs.Patch != nil {
+		return i.funcs.Patch(ctx, i.Client, obj, patch, opts...)
+	}
+	return i.Client.Patch(ctx, obj, patch, opts...)
+}
+
+// Status implements client.Client
+func (i *interceptor) Status() client.StatusWriter {
+	return &statusWriter{interceptor: i}
+}
+
+// statusWriter is a client.StatusWriter calling the status functions of the interceptor
+type statusWriter struct {
+	interceptor *interceptor
+}
+
+// Update implements client.StatusWriter
+func (w *statusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if w.interceptor.funcs.StatusUpdate != nil {
+		return w.interceptor.funcs.StatusUpdate(ctx, w.interceptor.Client, obj, opts...)
+	}
+	return w.interceptor.Client.Status().Update(ctx, obj, opts...)
+}
+
+// Patch implements client.StatusWriter
+func (w *statusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if w.interceptor.funcs.StatusPatch != nil {
+		return w.interceptor.funcs.StatusPatch(ctx, w.interceptor.Client, obj, patch, opts...)
+	}
+	return w.interceptor.Client.Status().Patch(ctx, obj, patch, opts...)
+}
+`
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &InterceptorTest{}
+
+// InterceptorTest scaffolds the file that tests the interceptor package
+type InterceptorTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *InterceptorTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "interceptor", "interceptor_test.go")
+	}
+
+	f.TemplateBody = interceptorTestTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const interceptorTestTemplate = `{{ .Boilerplate }}
+
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNewClient(t *testing.T) {
+	ctx := context.Background()
+	obj := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "interceptor-test", Namespace: "default"}}
+	injected := errors.New("injected")
+	var created []string
+	c := NewClient(fake.NewClientBuilder().WithObjects(obj).Build(), Funcs{
+		Create: func(ctx context.Context, c client.Client, obj client.Object, opts ...client.CreateOption) error {
+			created = append(created, obj.GetName())
+			return c.Create(ctx, obj, opts...)
+		},
+		Update: func(ctx context.Context, c client.Client, obj client.Object, opts ...client.UpdateOption) error {
+			return injected
+		},
+	})
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		t.Errorf("expected the methods without a function to call the wrapped client, got %v", err)
+	}
+	if err := c.Update(ctx, obj); !errors.Is(err, injected) {
+		t.Errorf("expected the Update function to be called, got %v", err)
+	}
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+	if err := c.Create(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 1 || created[0] != "other" {
+		t.Errorf("expected the creation to be recorded, got %v", created)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(other), other); err != nil {
+		t.Errorf("expected the Create function to create the object with the wrapped client, got %v", err)
+	}
+}
+`
@@ -0,0 +1,387 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Justfile{}
+
+// Justfile scaffolds a Justfile that defines the targets of the Makefile as just recipes
+type Justfile struct {
+	Makefile
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Justfile) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = "Justfile"
+	}
+
+	f.TemplateBody = justfileTemplate
+
+	f.IfExistsAction = file.Error
+
+	if f.Image == "" {
+		f.Image = "controller:latest"
+	}
+
+	return nil
+}
+
+//nolint:lll
+const justfileTemplate = `# Targets of the project, run with just (https://just.systems).
+# Override their variables on the command line, e.g. just IMG=registry.example.com/controller:v1 docker-build
+
+# Export the variables to the environment of the recipes, which refer to them as $NAME.
+set export
+{{- if .GoEnv }}
+
+# Go module configuration of the project (GOPROXY, GOPRIVATE and GONOSUMDB) used by the go commands.
+set dotenv-load
+set dotenv-filename := ".go-env"
+{{- end }}
+
+# Image URL to use all building/pushing image targets
+IMG := env_var_or_default("IMG", "{{ .Image }}")
+{{- if not .AggregatedAPIServer }}
+# Produce CRDs that work back to Kubernetes 1.11 (no version conversion)
+CRD_OPTIONS := env_var_or_default("CRD_OPTIONS", "crd:trivialVersions=true,preserveUnknownFields=false")
+{{- end }}
+
+# Tool mirror configuration, for environments without internet access.
+# TOOL_MIRROR is the base URL of a mirror serving the envtest binaries
+# with the same layout as the upstream locations. Leave it empty to use the upstream ones.
+TOOL_MIRROR := env_var_or_default("TOOL_MIRROR", "{{ .ToolMirror }}")
+# TOOL_GOPROXY and TOOL_GOSUMDB are used to download and verify controller-gen and kustomize, the ones of the
+# go commands if empty.
+TOOL_GOPROXY := env_var_or_default("TOOL_GOPROXY", "")
+TOOL_GOSUMDB := env_var_or_default("TOOL_GOSUMDB", "")
+# Expected sha256 checksum of the envtest binaries archive, verified if set.
+ENVTEST_TOOLS_SHA256 := env_var_or_default("ENVTEST_TOOLS_SHA256", "")
+ENVTEST_TOOLS_URL := if TOOL_MIRROR == "" { "https://storage.googleapis.com/kubebuilder-tools" } else { TOOL_MIRROR + "/kubebuilder-tools" }
+
+# The kubebuilder CLI, used to install the envtest binaries and to validate the PROJECT file
+KUBEBUILDER := env_var_or_default("KUBEBUILDER", "kubebuilder")
+# Kubernetes version of the envtest binaries. Set ENVTEST_TOOLS_ARCHIVE to the path of a kubebuilder-tools
+# archive to install them offline.
+ENVTEST_K8S_VERSION := env_var_or_default("ENVTEST_K8S_VERSION", "{{ .EnvtestK8sVersion }}")
+ENVTEST_TOOLS_ARCHIVE := env_var_or_default("ENVTEST_TOOLS_ARCHIVE", "")
+{{- if .AggregatedAPIServer }}
+
+# Kubeconfig of the cluster delegating the authentication and the authorization of the requests
+KUBECONFIG := env_var_or_default("KUBECONFIG", env_var_or_default("HOME", "") + "/.kube/config")
+{{- else }}
+
+# Number of objects reconciled by each benchmark of the controllers
+BENCH_OBJECTS := env_var_or_default("BENCH_OBJECTS", "100")
+
+# Apply the manifests with server-side apply when a CRD is too large for the 262144 bytes last-applied-configuration
+# annotation of client-side apply, which is checked after generating the CRDs. Set SERVER_SIDE_APPLY=true or
+# SERVER_SIDE_APPLY=false to always or never apply them with it.
+SERVER_SIDE_APPLY := env_var_or_default("SERVER_SIDE_APPLY", "auto")
+
+# Base revision of the CRDs checked by verify-crd-compat
+CRD_COMPAT_BASE_REF := env_var_or_default("CRD_COMPAT_BASE_REF", "HEAD~1")
+# Options of api-docs, e.g. --check
+API_DOCS_OPTIONS := env_var_or_default("API_DOCS_OPTIONS", "")
+# Manifests of the previous release tested by test-upgrade: a file, a URL or a kustomize directory
+UPGRADE_FROM := env_var_or_default("UPGRADE_FROM", "")
+# Audit log of the API server read by rbac-audit, and its options, e.g. --strict
+RBAC_AUDIT_LOG := env_var_or_default("RBAC_AUDIT_LOG", "bin/audit.log")
+RBAC_AUDIT_OPTIONS := env_var_or_default("RBAC_AUDIT_OPTIONS", "")
+# The kwctl CLI, used to annotate the wasm policies with their metadata
+KWCTL := env_var_or_default("KWCTL", "kwctl")
+{{- end }}
+
+# Set FORCE=1 to generate the manifests even if they are up to date
+FORCE := env_var_or_default("FORCE", "")
+{{- if .SBOM }}
+
+# Path of the SBOM of the docker image
+SBOM := env_var_or_default("SBOM", "bin/sbom.spdx.json")
+{{- end }}
+{{- if .SignsWithKey }}
+
+# Private key signing the docker image
+COSIGN_KEY := env_var_or_default("COSIGN_KEY", "cosign.key")
+{{- end }}
+
+# Tools downloaded locally
+CONTROLLER_GEN := justfile_directory() + "/bin/controller-gen"
+KUSTOMIZE := justfile_directory() + "/bin/kustomize"
+{{- if .SBOM }}
+SYFT := justfile_directory() + "/bin/syft"
+{{- end }}
+{{- if .ImageSigning }}
+COSIGN := justfile_directory() + "/bin/cosign"
+{{- end }}
+
+# Build manager binary
+all: manager
+
+# Run tests
+test: generate fmt vet manifests envtest
+    KUBEBUILDER_ASSETS="$($KUBEBUILDER envtest use $ENVTEST_K8S_VERSION --installed-only --print=path)" go test ./... -coverprofile cover.out
+{{- if not .AggregatedAPIServer }}
+
+# Run the benchmarks of the controllers, scaffolded with "create api --benchmark", each of them reconciling
+# BENCH_OBJECTS objects. Compare the results of two revisions with benchstat.
+bench: generate fmt vet manifests envtest
+    KUBEBUILDER_ASSETS="$($KUBEBUILDER envtest use $ENVTEST_K8S_VERSION --installed-only --print=path)" go test ./controllers/... -run='^$' -bench=. -benchtime=${BENCH_OBJECTS}x
+{{- end }}
+
+# Install the envtest binaries of ENVTEST_K8S_VERSION if necessary, in the directory shared by the projects
+# of the user
+envtest:
+    $KUBEBUILDER envtest install $ENVTEST_K8S_VERSION --remote-url=$ENVTEST_TOOLS_URL \
+        ${ENVTEST_TOOLS_SHA256:+--sha256=$ENVTEST_TOOLS_SHA256} ${ENVTEST_TOOLS_ARCHIVE:+--archive=$ENVTEST_TOOLS_ARCHIVE}
+
+# Build manager binary
+manager: generate fmt vet
+    go build -o bin/manager main.go
+{{- if .AggregatedAPIServer }}
+
+# Run the API server locally, delegating the authentication and the authorization of the requests to the
+# configured Kubernetes cluster in ~/.kube/config
+run: generate fmt vet manifests
+    go run ./main.go --kubeconfig=$KUBECONFIG --authentication-kubeconfig=$KUBECONFIG --authorization-kubeconfig=$KUBECONFIG
+
+# Deploy the API server in the configured Kubernetes cluster in ~/.kube/config and register its APIs
+deploy: manifests kustomize
+    cd config/manager && $KUSTOMIZE edit set image controller=$IMG
+    $KUSTOMIZE build config/default | kubectl apply -f -
+    $KUSTOMIZE build config/apiservice | kubectl apply -f -
+
+# UnDeploy the API server from the configured Kubernetes cluster in ~/.kube/config
+undeploy:
+    $KUSTOMIZE build config/apiservice | kubectl delete -f -
+    $KUSTOMIZE build config/default | kubectl delete -f -
+{{- else }}
+
+# Run against the configured Kubernetes cluster in ~/.kube/config
+run: generate fmt vet manifests
+    go run ./main.go
+
+# Run against the configured Kubernetes cluster in ~/.kube/config with the webhooks disabled,
+# as the API server of a remote cluster can not reach the webhook server of the local manager
+run-remote: generate fmt vet manifests install
+    ENABLE_WEBHOOKS=false go run ./main.go
+
+# Install CRDs into a cluster
+install: manifests kustomize
+    $KUSTOMIZE build config/crd | kubectl apply $(go run ./hack/crdlint --dir=config/crd/bases --server-side-apply=$SERVER_SIDE_APPLY --apply-flags) -f -
+
+# Build the CRDs in bin/crds.yaml and install them into a cluster with server-side apply, which is not subject to
+# the size limit of the last-applied-configuration annotation, e.g. to install them apart from the manager
+install-crds: manifests kustomize
+    mkdir -p bin
+    $KUSTOMIZE build config/crd > bin/crds.yaml
+    kubectl apply --server-side --force-conflicts -f bin/crds.yaml
+
+# Uninstall CRDs from a cluster
+uninstall: manifests kustomize
+    $KUSTOMIZE build config/crd | kubectl delete -f -
+
+# Deploy controller in the configured Kubernetes cluster in ~/.kube/config
+deploy: manifests kustomize
+    cd config/manager && $KUSTOMIZE edit set image controller=$IMG
+    $KUSTOMIZE build config/default | kubectl apply $(go run ./hack/crdlint --dir=config/crd/bases --server-side-apply=$SERVER_SIDE_APPLY --apply-flags) -f -
+
+# UnDeploy controller from the configured Kubernetes cluster in ~/.kube/config
+undeploy:
+    $KUSTOMIZE build config/default | kubectl delete -f -
+
+# Test the upgrade from the previous release, UPGRADE_FROM, to IMG in the configured Kubernetes cluster, e.g. a kind
+# cluster IMG was loaded into. The samples are created before the upgrade, and must still be reconciled and keep
+# their specs after it.
+test-upgrade: manifests kustomize
+    cd config/manager && $KUSTOMIZE edit set image controller=$IMG
+    mkdir -p bin && $KUSTOMIZE build config/default > bin/upgrade-to.yaml
+    go run ./hack/upgradetest --from=$UPGRADE_FROM --to=bin/upgrade-to.yaml --apply="kubectl apply $(go run ./hack/crdlint --dir=config/crd/bases --server-side-apply=$SERVER_SIDE_APPLY --apply-flags)"
+{{- range .Overlays }}
+
+# Deploy controller with the overlay of the {{ . }} environment in config/overlays/{{ . }}, setting the image tag,
+# the number of replicas and the log level of the manager in the environment
+deploy-{{ . }}: manifests kustomize
+    $KUSTOMIZE build config/overlays/{{ . }} | kubectl apply $(go run ./hack/crdlint --dir=config/crd/bases --server-side-apply=$SERVER_SIDE_APPLY --apply-flags) -f -
+
+# UnDeploy controller deployed with the overlay of the {{ . }} environment
+undeploy-{{ . }}:
+    $KUSTOMIZE build config/overlays/{{ . }} | kubectl delete -f -
+{{- end }}
+{{- end }}
+{{- if .AggregatedAPIServer }}
+
+# Generate the RBAC manifests. The generation is skipped when neither the Go files, the options nor the
+# generated manifests changed since the previous one, run "just FORCE=1 manifests" to run it anyway.
+manifests: controller-gen
+    #!/usr/bin/env sh
+    set -e
+    if [ "$FORCE" != "1" ] && go run ./hack/manifestshash --file=bin/manifests.sha256 --options='{{ .ControllerToolsVersion }} rbac' --outputs=config/rbac/role.yaml --check 2>/dev/null; then
+        echo "Manifests are up to date, run \"just FORCE=1 manifests\" to generate them anyway"
+    else
+        $CONTROLLER_GEN rbac:roleName=manager-role paths="./..."
+        go run ./hack/manifestshash --file=bin/manifests.sha256 --options='{{ .ControllerToolsVersion }} rbac' --outputs=config/rbac/role.yaml
+    fi
+{{- else }}
+
+# Generate manifests e.g. CRD, RBAC etc. The generation is skipped when neither the Go files, the options
+# nor the generated manifests changed since the previous one, run "just FORCE=1 manifests" to run it anyway.
+manifests: controller-gen
+    #!/usr/bin/env sh
+    set -e
+    if [ "$FORCE" != "1" ] && go run ./hack/manifestshash --file=bin/manifests.sha256 --options="{{ .ControllerToolsVersion }} $CRD_OPTIONS" --check 2>/dev/null; then
+        echo "Manifests are up to date, run \"just FORCE=1 manifests\" to generate them anyway"
+    else
+        $CONTROLLER_GEN $CRD_OPTIONS rbac:roleName=manager-role webhook paths="./..." output:crd:artifacts:config=config/crd/bases
+        go run ./hack/crdlint --dir=config/crd/bases --server-side-apply=$SERVER_SIDE_APPLY
+        go run ./hack/manifestshash --file=bin/manifests.sha256 --options="{{ .ControllerToolsVersion }} $CRD_OPTIONS"
+    fi
+
+# Check that the CRDs fit in the size limits of the API server and that their validation rules are
+# unlikely to exceed the CEL cost budget. It is also run by manifests, after generating the CRDs.
+lint-crds:
+    go run ./hack/crdlint --dir=config/crd/bases --server-side-apply=$SERVER_SIDE_APPLY
+
+# Verify that the CRDs are backwards compatible with the ones in CRD_COMPAT_BASE_REF.
+# It is run on the pull requests by .github/workflows/crd-compat.yml to catch breaking changes to served versions.
+verify-crd-compat: manifests
+    go run ./hack/crdcompat --base-ref=$CRD_COMPAT_BASE_REF --dir=config/crd/bases
+
+# Report the rules of the manager role, generated from the +kubebuilder:rbac markers, that the manager never used
+# in RBAC_AUDIT_LOG, an audit log of the API server recorded while testing it, and the requests it was denied.
+# Set RBAC_AUDIT_OPTIONS=--strict to fail if a rule is unused.
+rbac-audit: manifests
+    go run ./hack/rbacaudit --dir=config/rbac --audit-log=$RBAC_AUDIT_LOG $RBAC_AUDIT_OPTIONS
+
+# Generate the reference of the fields of the kinds documented in docs/api, created with "create api --api-docs",
+# from the markers of their API types. Run "just API_DOCS_OPTIONS=--check api-docs" in CI to check that it is
+# up to date.
+api-docs:
+    go run ./hack/apidocs --dir=docs/api $API_DOCS_OPTIONS
+
+# Compile the wasm policies of policies/, scaffolded with "create webhook --wasm-policy", to Kubewarden policies
+# annotated with their metadata in bin/policies. Requires Go 1.21 or later and kwctl (experimental).
+wasm-policies:
+    #!/usr/bin/env sh
+    set -e
+    for policy in $(ls policies 2>/dev/null); do
+        echo "Building bin/policies/$policy.wasm"
+        GOOS=wasip1 GOARCH=wasm go build -o bin/policies/$policy.wasm ./policies/$policy
+        $KWCTL annotate bin/policies/$policy.wasm --metadata-path policies/$policy/metadata.yml --output-path bin/policies/$policy.annotated.wasm
+    done
+{{- end }}
+
+# Validate the PROJECT file and check that it is in sync with the project, e.g. that every API is recorded
+# in it. Run it in CI to catch the changes made to the project without the kubebuilder CLI.
+validate-project:
+    $KUBEBUILDER config validate --strict
+
+# Check that the scaffold markers of main.go and of the test suites exist and that the APIs and webhooks of the
+# PROJECT file are wired in main.go. Run it in CI: without their markers, "create api" and "create webhook"
+# silently stop wiring the new APIs.
+verify-scaffold:
+    $KUBEBUILDER config validate --scaffold --strict
+
+# Run go fmt against code
+fmt:
+    go fmt ./...
+
+# Run go vet against code
+vet:
+    go vet ./...
+
+# Generate code
+generate: controller-gen
+    $CONTROLLER_GEN object:headerFile={{ printf "%q" .BoilerplatePath }} paths="./..."
+
+# Build the docker image, passing the proxies of the environment, if set, and the Go module configuration
+# of the go commands to the builder, e.g. for the builds behind a corporate proxy
+docker-build: test
+    docker build --build-arg HTTP_PROXY --build-arg HTTPS_PROXY --build-arg NO_PROXY \
+        --build-arg GOPROXY="$(go env GOPROXY)" --build-arg GOPRIVATE="$(go env GOPRIVATE)" \
+        --build-arg GONOSUMDB="$(go env GONOSUMDB)" \
+{{- if or .SBOM .ImageSigning }}
+        --build-arg VCS_REF=$(git rev-parse HEAD 2>/dev/null) \
+{{- end }}
+        -t $IMG .
+
+# Push the docker image
+docker-push:
+    docker push $IMG
+{{- if .SBOM }}
+
+# Generate an SPDX SBOM of the docker image
+docker-sbom: syft
+    $SYFT packages $IMG -o spdx-json > $SBOM
+{{- end }}
+{{- if .ImageSigning }}
+
+# Sign the pushed docker image
+docker-sign: cosign
+{{- if .SignsWithKey }}
+    $COSIGN sign --key $COSIGN_KEY $IMG
+{{- else }}
+    COSIGN_EXPERIMENTAL=1 $COSIGN sign $IMG
+{{- end }}
+{{- end }}
+
+# Download controller-gen locally if necessary
+controller-gen: (_go-get-tool CONTROLLER_GEN "sigs.k8s.io/controller-tools/cmd/controller-gen@{{ .ControllerToolsVersion }}")
+
+# Download kustomize locally if necessary
+kustomize: (_go-get-tool KUSTOMIZE "sigs.k8s.io/kustomize/kustomize/v3@{{ .KustomizeVersion }}")
+
+{{- if .SBOM }}
+
+# Download the syft release locally if necessary, syft can not be built by the Go version of the project
+syft:
+    #!/usr/bin/env sh
+    set -e
+    [ -f "$SYFT" ] && exit 0
+    echo "Downloading syft {{ .SyftVersion }}"
+    curl -sSfL https://raw.githubusercontent.com/anchore/syft/{{ .SyftVersion }}/install.sh | sh -s -- -b $(dirname "$SYFT") {{ .SyftVersion }}
+{{- end }}
+{{- if .ImageSigning }}
+
+# Download the cosign release locally if necessary, cosign can not be built by the Go version of the project
+cosign:
+    #!/usr/bin/env sh
+    set -e
+    [ -f "$COSIGN" ] && exit 0
+    echo "Downloading cosign {{ .CosignVersion }}"
+    mkdir -p $(dirname "$COSIGN")
+    curl -sSfL -o "$COSIGN" https://github.com/sigstore/cosign/releases/download/{{ .CosignVersion }}/cosign-$(go env GOOS)-$(go env GOARCH)
+    chmod +x "$COSIGN"
+{{- end }}
+
+# go-get-tool will 'go get' any package and install it to tool.
+_go-get-tool tool package:
+    #!/usr/bin/env sh
+    set -e
+    [ -f "$tool" ] && exit 0
+    GOPROXY=${TOOL_GOPROXY:-$(go env GOPROXY)}
+    GOSUMDB=${TOOL_GOSUMDB:-$(go env GOSUMDB)}
+    TMP_DIR=$(mktemp -d)
+    cd $TMP_DIR
+    go mod init tmp
+    echo "Downloading $package"
+    GOBIN=$(dirname "$tool") GOPROXY=$GOPROXY GOSUMDB=$GOSUMDB go get "$package"
+    rm -rf $TMP_DIR
+`
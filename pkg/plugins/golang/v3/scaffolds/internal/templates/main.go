@@ -17,8 +17,13 @@ limitations under the License.
 package templates
 
 import (
+	"errors"
 	"fmt"
+	"go/ast"
+	"go/token"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
 )
@@ -34,6 +39,23 @@ type Main struct {
 	file.DomainMixin
 	file.RepositoryMixin
 	file.ComponentConfigMixin
+	file.FeatureGatesMixin
+	file.MultiClusterMixin
+
+	// WebhookCertDir is the default directory of the serving certificates of the webhooks, when they are
+	// not mounted in the default directory of controller-runtime
+	WebhookCertDir string
+	// WebhookDev adds the --webhook-cert-dir flag even if the certificates are mounted in the default directory,
+	// so that the manager run by the developer can serve the webhooks with the certificate of hack/webhook-dev
+	WebhookDev bool
+
+	// Sharding spreads the objects of the resources across the managers of the shards selected by the
+	// --shard-id and --shard-count flags
+	Sharding bool
+
+	// Agent runs the manager as an agent reconciling the objects of its own namespace, without leader
+	// election, when the --mode=agent flag is set
+	Agent bool
 }
 
 // SetTemplateDefaults implements file.Template
@@ -51,16 +73,30 @@ func (f *Main) SetTemplateDefaults() error {
 	return nil
 }
 
-var _ file.Inserter = &MainUpdater{}
+var _ file.AnchoredInserter = &MainUpdater{}
 
 // MainUpdater updates main.go to run Controllers
 type MainUpdater struct { //nolint:maligned
 	file.RepositoryMixin
 	file.MultiGroupMixin
+	file.MultiClusterMixin
 	file.ResourceMixin
+	file.ASTWiringMixin
 
 	// Flags to indicate which parts need to be included when updating the file
-	WireResource, WireController, WireWebhook bool
+	WireResource, WireController, WireWebhook, WireOwnerLabelsWebhook bool
+	// WireSubresourceWebhooks are the subresources, status or scale, whose validating webhooks are wired
+	WireSubresourceWebhooks []string
+
+	// GroupRegistration adds the resource to the scheme through the registration package of its group
+	GroupRegistration bool
+
+	// WireCertMetrics registers the metrics of the serving certificate of the webhooks, once for all the webhooks
+	WireCertMetrics bool
+
+	// CacheNamespace and CacheLabelSelector narrow the objects of the resource cached by the manager to the
+	// ones of a namespace and to the ones matching a label selector, if not empty
+	CacheNamespace, CacheLabelSelector string
 }
 
 // GetPath implements file.Builder
@@ -77,6 +113,9 @@ const (
 	importMarker    = "imports"
 	addSchemeMarker = "scheme"
 	setupMarker     = "builder"
+	// CacheMarker is the value of the marker of the cache selectors of main.go, only scaffolded by the first
+	// resource whose cached objects are narrowed
+	CacheMarker = "cache"
 )
 
 // GetMarkers implements file.Inserter
@@ -85,6 +124,7 @@ func (f *MainUpdater) GetMarkers() []file.Marker {
 		file.NewMarkerFor(defaultMainPath, importMarker),
 		file.NewMarkerFor(defaultMainPath, addSchemeMarker),
 		file.NewMarkerFor(defaultMainPath, setupMarker),
+		file.NewMarkerFor(defaultMainPath, CacheMarker),
 	}
 }
 
@@ -97,27 +137,57 @@ const (
 `
 	addschemeCodeFragment = `utilruntime.Must(%s.AddToScheme(scheme))
 `
-	reconcilerSetupCodeFragment = `if err = (&controllers.%sReconciler{
+	reconcilerSetupCodeFragment = `if err = (&controllers.%[1]sReconciler{
 		Client: mgr.GetClient(),
-		Log: ctrl.Log.WithName("controllers").WithName("%s"),
+		Log: ctrl.Log.WithName("controllers").WithName("%[1]s"),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "%s")
+		Recorder: mgr.GetEventRecorderFor("%[2]s-controller"),
+		%[3]s}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "%[1]s")
 		os.Exit(1)
 	}
 `
-	multiGroupReconcilerSetupCodeFragment = `if err = (&%scontrollers.%sReconciler{
+	multiGroupReconcilerSetupCodeFragment = `if err = (&%[1]scontrollers.%[2]sReconciler{
 		Client: mgr.GetClient(),
-		Log: ctrl.Log.WithName("controllers").WithName("%s").WithName("%s"),
+		Log: ctrl.Log.WithName("controllers").WithName("%[3]s").WithName("%[2]s"),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "%s")
+		Recorder: mgr.GetEventRecorderFor("%[4]s-controller"),
+		%[5]s}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "%[2]s")
 		os.Exit(1)
 	}
 `
-	webhookSetupCodeFragment = `if err = (&%s.%s{}).SetupWebhookWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create webhook", "webhook", "%s")
-		os.Exit(1)
+	clustersCodeFragment = `Clusters: remoteClusters,
+	`
+	webhookSetupCodeFragment = `if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&%s.%s{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "%s")
+			os.Exit(1)
+		}
+	}
+`
+	ownerLabelsWebhookSetupCodeFragment = `if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&%s.%s{}).SetupOwnerLabelsWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "%s owner labels")
+			os.Exit(1)
+		}
+	}
+`
+	cacheSelectorImportCodeFragment = `"%s/internal/cacheselector"
+`
+	cacheSelectorCodeFragment = `{Resource: %s.%s.WithResource(%q)%s},
+`
+	certMetricsImportCodeFragment = `"%s/internal/certmetrics"
+`
+	certMetricsSetupCodeFragment = `if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		certmetrics.RegisterWebhookServer(mgr.GetWebhookServer())
+	}
+`
+	subresourceWebhookSetupCodeFragment = `if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&%s.%s{}).Setup%sWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "%s %s")
+			os.Exit(1)
+		}
 	}
 `
 )
@@ -131,9 +201,18 @@ func (f *MainUpdater) GetCodeFragments() file.CodeFragmentsMap {
 		return fragments
 	}
 
+	// The registration package of the group adds the version to the scheme, the version package is only
+	// imported to set up its webhooks
+	groupRegistration := f.GroupRegistration && f.MultiGroup && f.Resource.Group != ""
+	wireWebhooks := f.WireWebhook || f.WireOwnerLabelsWebhook || len(f.WireSubresourceWebhooks) != 0
+
 	// Generate import code fragments
 	imports := make([]string, 0)
-	if f.WireResource {
+	if f.WireResource && groupRegistration {
+		imports = append(imports, fmt.Sprintf(apiImportCodeFragment,
+			f.Resource.GroupPackageName, path.Dir(f.Resource.Package)))
+	}
+	if (f.WireResource && !groupRegistration) || (wireWebhooks && groupRegistration) {
 		imports = append(imports, fmt.Sprintf(apiImportCodeFragment, f.Resource.ImportAlias, f.Resource.Package))
 	}
 
@@ -142,36 +221,80 @@ func (f *MainUpdater) GetCodeFragments() file.CodeFragmentsMap {
 			imports = append(imports, fmt.Sprintf(controllerImportCodeFragment, f.Repo))
 		} else {
 			imports = append(imports, fmt.Sprintf(multiGroupControllerImportCodeFragment,
-				f.Resource.GroupPackageName, f.Repo, f.Resource.Group))
+				f.Resource.GroupPackageName, f.Repo, f.Resource.GroupPath()))
 		}
 	}
 
 	// Generate add scheme code fragments
 	addScheme := make([]string, 0)
-	if f.WireResource {
+	if f.WireResource && groupRegistration {
+		addScheme = append(addScheme, fmt.Sprintf(addschemeCodeFragment, f.Resource.GroupPackageName))
+	} else if f.WireResource {
 		addScheme = append(addScheme, fmt.Sprintf(addschemeCodeFragment, f.Resource.ImportAlias))
 	}
 
 	// Generate setup code fragments
 	setup := make([]string, 0)
 	if f.WireController {
+		clusters := ""
+		if f.MultiCluster {
+			clusters = clustersCodeFragment
+		}
 		if !f.MultiGroup || f.Resource.Group == "" {
 			setup = append(setup, fmt.Sprintf(reconcilerSetupCodeFragment,
-				f.Resource.Kind, f.Resource.Kind, f.Resource.Kind))
+				f.Resource.Kind, strings.ToLower(f.Resource.Kind), clusters))
 		} else {
 			setup = append(setup, fmt.Sprintf(multiGroupReconcilerSetupCodeFragment,
-				f.Resource.GroupPackageName, f.Resource.Kind, f.Resource.Group, f.Resource.Kind, f.Resource.Kind))
+				f.Resource.GroupPackageName, f.Resource.Kind, f.Resource.Group, strings.ToLower(f.Resource.Kind),
+				clusters))
 		}
 	}
 	if f.WireWebhook {
 		setup = append(setup, fmt.Sprintf(webhookSetupCodeFragment,
 			f.Resource.ImportAlias, f.Resource.Kind, f.Resource.Kind))
 	}
+	if f.WireOwnerLabelsWebhook {
+		setup = append(setup, fmt.Sprintf(ownerLabelsWebhookSetupCodeFragment,
+			f.Resource.ImportAlias, f.Resource.Kind, f.Resource.Kind))
+	}
+	for _, subresource := range f.WireSubresourceWebhooks {
+		setup = append(setup, fmt.Sprintf(subresourceWebhookSetupCodeFragment,
+			f.Resource.ImportAlias, f.Resource.Kind, strings.Title(subresource), f.Resource.Kind, subresource))
+	}
+
+	if f.WireCertMetrics {
+		imports = append(imports, fmt.Sprintf(certMetricsImportCodeFragment, f.Repo))
+		setup = append(setup, certMetricsSetupCodeFragment)
+	}
+
+	// Generate cache selector code fragments
+	cache := make([]string, 0)
+	if f.CacheNamespace != "" || f.CacheLabelSelector != "" {
+		imports = append(imports, fmt.Sprintf(cacheSelectorImportCodeFragment, f.Repo),
+			fmt.Sprintf(apiImportCodeFragment, f.Resource.ImportAlias, f.Resource.Package))
+		// The packages of the core types declare their group version as SchemeGroupVersion
+		groupVersion := "GroupVersion"
+		if strings.HasPrefix(f.Resource.Package, "k8s.io/api/") {
+			groupVersion = "SchemeGroupVersion"
+		}
+		selector := ""
+		if f.CacheNamespace != "" {
+			selector += fmt.Sprintf(", Namespace: %q", f.CacheNamespace)
+		}
+		if f.CacheLabelSelector != "" {
+			selector += fmt.Sprintf(", Labels: %q", f.CacheLabelSelector)
+		}
+		cache = append(cache, fmt.Sprintf(cacheSelectorCodeFragment,
+			f.Resource.ImportAlias, groupVersion, f.Resource.Plural, selector))
+	}
 
 	// Only store code fragments in the map if the slices are non-empty
 	if len(imports) != 0 {
 		fragments[file.NewMarkerFor(defaultMainPath, importMarker)] = imports
 	}
+	if len(cache) != 0 {
+		fragments[file.NewMarkerFor(defaultMainPath, CacheMarker)] = cache
+	}
 	if len(addScheme) != 0 {
 		fragments[file.NewMarkerFor(defaultMainPath, addSchemeMarker)] = addScheme
 	}
@@ -182,13 +305,112 @@ func (f *MainUpdater) GetCodeFragments() file.CodeFragmentsMap {
 	return fragments
 }
 
+// GetAnchors implements file.AnchoredInserter
+func (f *MainUpdater) GetAnchors() map[file.Marker]file.Anchor {
+	if !f.ASTWiring {
+		return nil
+	}
+	// The cache selectors are still inserted at their marker, in the block scaffolded by the CLI
+	return map[file.Marker]file.Anchor{
+		file.NewMarkerFor(defaultMainPath, importMarker):    importsAnchor,
+		file.NewMarkerFor(defaultMainPath, addSchemeMarker): addSchemeAnchor,
+		file.NewMarkerFor(defaultMainPath, setupMarker):     setupAnchor,
+	}
+}
+
+// importsAnchor locates the end of the first parenthesized import declaration.
+func importsAnchor(fset *token.FileSet, f *ast.File) (token.Pos, error) {
+	for _, decl := range f.Decls {
+		if gen, isGen := decl.(*ast.GenDecl); isGen && gen.Tok == token.IMPORT && gen.Lparen.IsValid() {
+			last := gen.Lparen
+			if len(gen.Specs) != 0 {
+				last = gen.Specs[len(gen.Specs)-1].End()
+			}
+			return beforeComments(fset, f, last, gen.Rparen), nil
+		}
+	}
+	return token.NoPos, errors.New("no parenthesized import declaration found")
+}
+
+// addSchemeAnchor locates the end of the first function adding types to the scheme, usually init.
+func addSchemeAnchor(fset *token.FileSet, f *ast.File) (token.Pos, error) {
+	for _, decl := range f.Decls {
+		if fn, isFunc := decl.(*ast.FuncDecl); isFunc && fn.Recv == nil && fn.Body != nil &&
+			callsMethod(fn.Body, "AddToScheme") {
+			return beforeComments(fset, f, fn.Body.List[len(fn.Body.List)-1].End(), fn.Body.Rbrace), nil
+		}
+	}
+	return token.NoPos, errors.New("no function calling AddToScheme found")
+}
+
+// setupAnchor locates, in the function creating the manager, the first statement adding a health check or
+// starting the manager after its creation.
+func setupAnchor(fset *token.FileSet, f *ast.File) (token.Pos, error) {
+	for _, decl := range f.Decls {
+		fn, isFunc := decl.(*ast.FuncDecl)
+		if !isFunc || fn.Body == nil || !callsMethod(fn.Body, "NewManager") {
+			continue
+		}
+		created := false
+		for i, stmt := range fn.Body.List {
+			if !created {
+				created = callsMethod(stmt, "NewManager")
+				continue
+			}
+			if callsMethod(stmt, "AddHealthzCheck", "AddReadyzCheck", "Start") {
+				return beforeComments(fset, f, fn.Body.List[i-1].End(), stmt.Pos()), nil
+			}
+		}
+		return token.NoPos, fmt.Errorf("no health check or start of the manager found after its creation in %s",
+			fn.Name.Name)
+	}
+	return token.NoPos, errors.New("no function calling NewManager found")
+}
+
+// beforeComments returns the position of the first comment on its own line between previous and pos, such as
+// a scaffold marker, so that the new code is inserted before it as at the marker, or pos if there is none.
+func beforeComments(fset *token.FileSet, f *ast.File, previous, pos token.Pos) token.Pos {
+	previousLine := fset.Position(previous).Line
+	for _, group := range f.Comments {
+		if group.Pos() > previous && group.Pos() < pos && fset.Position(group.Pos()).Line > previousLine {
+			return group.Pos()
+		}
+	}
+	return pos
+}
+
+// callsMethod returns true if node calls a method or a function of a package with one of the names.
+func callsMethod(node ast.Node, names ...string) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if call, isCall := n.(*ast.CallExpr); isCall {
+			if sel, isSel := call.Fun.(*ast.SelectorExpr); isSel {
+				for _, name := range names {
+					if sel.Sel.Name == name {
+						found = true
+					}
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
 var mainTemplate = `{{ .Boilerplate }}
 
 package main
 
 import (
 	"flag"
+{{- if not .ComponentConfig }}
+	"net"
+{{- end }}
 	"os"
+{{- if not .ComponentConfig }}
+	"strconv"
+{{- end }}
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -200,6 +422,20 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	{{- if or .Agent .FeatureGates .MultiCluster .Sharding }}
+{{ end }}
+	{{- if .Agent }}
+	"{{ .Repo }}/internal/agent"
+	{{- end }}
+	{{- if .MultiCluster }}
+	"{{ .Repo }}/internal/clusters"
+	{{- end }}
+	{{- if .FeatureGates }}
+	"{{ .Repo }}/internal/featuregate"
+	{{- end }}
+	{{- if .Sharding }}
+	"{{ .Repo }}/internal/sharding"
+	{{- end }}
 	%s
 )
 
@@ -219,17 +455,54 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var webhookAddr string
+	var gracefulShutdownTimeout time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&webhookAddr, "webhook-bind-address", ":9443", "The address the webhook server binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. " +
 		"Enabling this will ensure there is only one active controller manager.")
+{{- if or .WebhookCertDir .WebhookDev }}
+	var webhookCertDir string
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "{{ .WebhookCertDir }}",
+{{- if .WebhookCertDir }}
+		"The directory holding the tls.crt and tls.key serving certificate of the webhooks.")
+{{- else }}
+		"The directory holding the tls.crt and tls.key serving certificate of the webhooks, " +
+		"defaults to k8s-webhook-server/serving-certs in the temporary directory.")
+{{- end }}
+{{- end }}
 {{- else }}
   var configFile string
+	var gracefulShutdownTimeout time.Duration
 	flag.StringVar(&configFile, "config", "", 
 		"The controller will load its initial configuration from this file. " +
 		"Omit this flag to use the default configuration values. " +
 		"Command-line flags override configuration from this file.")
+{{- end }}
+	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 5*time.Second,
+		"The time given to the controllers to finish their in-flight reconciliations when the manager is stopped. " +
+		"It must be lower than the terminationGracePeriodSeconds of the manager Pod.")
+{{- if .MultiCluster }}
+	var clustersConfig string
+	flag.StringVar(&clustersConfig, "clusters-config", "",
+		"The cluster registry file listing the remote clusters whose objects are reconciled, in addition to " +
+		"the objects of the cluster of the manager.")
+{{- end }}
+{{- if .Sharding }}
+	var shard sharding.Shard
+	flag.IntVar(&shard.ID, "shard-id", 0,
+		"The shard whose objects are reconciled by the manager, from 0 to --shard-count - 1.")
+	flag.IntVar(&shard.Count, "shard-count", 1,
+		"The number of shards the objects are spread across, each one being reconciled by its own manager.")
+{{- end }}
+{{- if .FeatureGates }}
+	featuregate.Default.AddFlag(flag.CommandLine)
+{{- end }}
+{{- if .Agent }}
+	var mode agent.Mode
+	mode.AddFlag(flag.CommandLine)
 {{- end }}
 	opts := zap.Options{
 		Development: true,
@@ -238,19 +511,44 @@ func main() {
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+{{- if not .ComponentConfig }}
+
+	webhookHost, webhookPort, err := splitBindAddress(webhookAddr)
+	if err != nil {
+		setupLog.Error(err, "invalid webhook bind address")
+		os.Exit(1)
+	}
+{{- end }}
+{{- if .Sharding }}
+
+	if err := shard.Validate(); err != nil {
+		setupLog.Error(err, "invalid shard")
+		os.Exit(1)
+	}
+{{- end }}
 
 {{ if not .ComponentConfig }}
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "{{ hashFNV .Repo }}.{{ .Domain }}",
-	})
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), {{ if .Agent }}mode.Options({{ end }}ctrl.Options{
+		Scheme:                  scheme,
+		MetricsBindAddress:      metricsAddr,
+		Host:                    webhookHost,
+		Port:                    webhookPort,
+{{- if or .WebhookCertDir .WebhookDev }}
+		CertDir:                 webhookCertDir,
+{{- end }}
+		HealthProbeBindAddress:  probeAddr,
+		LeaderElection:          enableLeaderElection,
+{{- if .Sharding }}
+		LeaderElectionID:        shard.LeaderElectionID("{{ hashFNV .Repo }}.{{ .Domain }}"),
+		NewCache:                shard.NewCache,
+{{- else }}
+		LeaderElectionID:        "{{ hashFNV .Repo }}.{{ .Domain }}",
+{{- end }}
+		GracefulShutdownTimeout: &gracefulShutdownTimeout,
+	}{{ if .Agent }}){{ end }})
 {{- else }}
 	var err error
-	options := ctrl.Options{Scheme: scheme}
+	options := ctrl.Options{Scheme: scheme, GracefulShutdownTimeout: &gracefulShutdownTimeout}
 	if configFile != "" {
 		options, err = options.AndFrom(ctrl.ConfigFile().AtPath(configFile))
 		if err != nil {
@@ -258,6 +556,13 @@ func main() {
 			os.Exit(1)
 		}
 	}
+{{- if .Sharding }}
+	options.LeaderElectionID = shard.LeaderElectionID(options.LeaderElectionID)
+	options.NewCache = shard.NewCache
+{{- end }}
+{{- if .Agent }}
+	options = mode.Options(options)
+{{- end }}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
 {{- end }}
@@ -265,6 +570,26 @@ func main() {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+{{- if .MultiCluster }}
+
+	remoteClusters, err := clusters.Load(clustersConfig, mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to connect to the remote clusters")
+		os.Exit(1)
+	}
+	setupLog.Info("connected to the remote clusters", "clusters", remoteClusters.Names())
+{{- end }}
+{{- if .Sharding }}
+
+	// The first shard labels the objects created without a bucket, which no shard would reconcile otherwise
+	if shard.ID == 0 {
+		if err := sharding.SetupAssigner(mgr); err != nil {
+			setupLog.Error(err, "unable to set up the shard assigner")
+			os.Exit(1)
+		}
+	}
+	setupLog.Info("reconciling the objects of the shard", "shard", shard.ID, "shards", shard.Count)
+{{- end }}
 
 	%s
 
@@ -283,4 +608,17 @@ func main() {
 		os.Exit(1)
 	}
 }
+{{- if not .ComponentConfig }}
+
+// splitBindAddress splits a bind address, such as :9443 or [::1]:9443, into its host and its port.
+// An empty host binds all the addresses of both IP families.
+func splitBindAddress(address string) (string, int, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", 0, err
+	}
+	portNumber, err := strconv.Atoi(port)
+	return host, portNumber, err
+}
+{{- end }}
 `
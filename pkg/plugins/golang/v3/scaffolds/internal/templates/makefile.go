@@ -20,9 +20,17 @@ import (
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
 )
 
+const (
+	// ImageSigningKeyless signs images with cosign using keyless (OIDC) signing
+	ImageSigningKeyless = "keyless"
+	// ImageSigningKey signs images with cosign using a private key
+	ImageSigningKey = "key"
+)
+
 var _ file.Template = &Makefile{}
 
-// Makefile scaffolds a file that defines project management CLI commands
+// Makefile scaffolds a file that defines project management CLI commands. Its options also scaffold the
+// Taskfile and the Justfile, which define the same targets for the other task runners.
 type Makefile struct {
 	file.TemplateMixin
 	file.ComponentConfigMixin
@@ -35,8 +43,26 @@ type Makefile struct {
 	ControllerToolsVersion string
 	// Kustomize version to use in the project
 	KustomizeVersion string
-	// ControllerRuntimeVersion version to be used to download the envtest setup script
-	ControllerRuntimeVersion string
+	// ToolMirror is the default base URL used to download the envtest binaries
+	ToolMirror string
+	// EnvtestK8sVersion is the Kubernetes version, or version selector, of the envtest binaries
+	EnvtestK8sVersion string
+	// SBOM indicates whether to scaffold the target to generate the manager image SBOM
+	SBOM bool
+	// SyftVersion is the syft version used to generate the SBOM
+	SyftVersion string
+	// ImageSigning is the cosign signing mode of the manager image, ImageSigningKeyless or ImageSigningKey,
+	// empty if disabled
+	ImageSigning string
+	// CosignVersion is the cosign version used to sign the manager image
+	CosignVersion string
+	// GoEnv indicates whether the project defines its Go module configuration in a .go-env file
+	GoEnv bool
+	// Overlays are the environments whose kustomize overlay of config/overlays is deployed by a Makefile target
+	Overlays []string
+	// AggregatedAPIServer indicates whether the project is an aggregated API server, which serves its API
+	// instead of CRDs
+	AggregatedAPIServer bool
 }
 
 // SetTemplateDefaults implements file.Template
@@ -56,12 +82,25 @@ func (f *Makefile) SetTemplateDefaults() error {
 	return nil
 }
 
+// SignsWithKey returns whether the manager image is signed with a private key
+func (f *Makefile) SignsWithKey() bool {
+	return f.ImageSigning == ImageSigningKey
+}
+
 //nolint:lll
 const makefileTemplate = `
 # Image URL to use all building/pushing image targets
 IMG ?= {{ .Image }}
+{{- if not .AggregatedAPIServer }}
 # Produce CRDs that work back to Kubernetes 1.11 (no version conversion)
 CRD_OPTIONS ?= "crd:trivialVersions=true,preserveUnknownFields=false"
+{{- end }}
+{{- if .GoEnv }}
+
+# Go module configuration of the project (GOPROXY, GOPRIVATE and GONOSUMDB) used by the go commands.
+include .go-env
+export GOPROXY GOPRIVATE GONOSUMDB
+{{- end }}
 
 # Get the currently used golang install path (in GOPATH/bin, unless GOBIN is set)
 ifeq (,$(shell go env GOBIN))
@@ -72,24 +111,91 @@ endif
 
 all: manager
 
+# Tool mirror configuration, for environments without internet access.
+# TOOL_MIRROR is the base URL of a mirror serving the envtest binaries
+# with the same layout as the upstream locations. Leave it empty to use the upstream ones.
+TOOL_MIRROR ?={{ if .ToolMirror }} {{ .ToolMirror }}{{ end }}
+# TOOL_GOPROXY and TOOL_GOSUMDB are used to download and verify controller-gen and kustomize.
+TOOL_GOPROXY ?= $(shell go env GOPROXY)
+TOOL_GOSUMDB ?= $(shell go env GOSUMDB)
+# Expected sha256 checksum of the envtest binaries archive, verified if set.
+ENVTEST_TOOLS_SHA256 ?=
+ifeq (,$(TOOL_MIRROR))
+ENVTEST_TOOLS_URL = https://storage.googleapis.com/kubebuilder-tools
+else
+ENVTEST_TOOLS_URL = $(TOOL_MIRROR)/kubebuilder-tools
+endif
+
+# The kubebuilder CLI, used to install the envtest binaries and to validate the PROJECT file
+KUBEBUILDER ?= kubebuilder
+
 # Run tests
-ENVTEST_ASSETS_DIR=$(shell pwd)/testbin
-test: generate fmt vet manifests
-	mkdir -p ${ENVTEST_ASSETS_DIR}
-	test -f ${ENVTEST_ASSETS_DIR}/setup-envtest.sh || curl -sSLo ${ENVTEST_ASSETS_DIR}/setup-envtest.sh https://raw.githubusercontent.com/kubernetes-sigs/controller-runtime/{{ .ControllerRuntimeVersion }}/hack/setup-envtest.sh
-	source ${ENVTEST_ASSETS_DIR}/setup-envtest.sh; fetch_envtest_tools $(ENVTEST_ASSETS_DIR); setup_envtest_env $(ENVTEST_ASSETS_DIR); go test ./... -coverprofile cover.out
+test: generate fmt vet manifests envtest
+	KUBEBUILDER_ASSETS="$$($(KUBEBUILDER) envtest use $(ENVTEST_K8S_VERSION) --installed-only --print=path)" go test ./... -coverprofile cover.out
+{{- if not .AggregatedAPIServer }}
+
+# Run the benchmarks of the controllers, scaffolded with "create api --benchmark", each of them reconciling
+# BENCH_OBJECTS objects. Compare the results of two revisions with benchstat.
+BENCH_OBJECTS ?= 100
+bench: generate fmt vet manifests envtest
+	KUBEBUILDER_ASSETS="$$($(KUBEBUILDER) envtest use $(ENVTEST_K8S_VERSION) --installed-only --print=path)" go test ./controllers/... -run='^$$' -bench=. -benchtime=$(BENCH_OBJECTS)x
+{{- end }}
+
+# Install the envtest binaries of ENVTEST_K8S_VERSION if necessary, in the directory shared by the projects
+# of the user. Set ENVTEST_TOOLS_ARCHIVE to the path of a kubebuilder-tools archive to install it offline.
+ENVTEST_K8S_VERSION ?= {{ .EnvtestK8sVersion }}
+ENVTEST_TOOLS_ARCHIVE ?=
+envtest:
+	$(KUBEBUILDER) envtest install $(ENVTEST_K8S_VERSION) --remote-url=$(ENVTEST_TOOLS_URL) \
+		$(if $(ENVTEST_TOOLS_SHA256),--sha256=$(ENVTEST_TOOLS_SHA256)) $(if $(ENVTEST_TOOLS_ARCHIVE),--archive=$(ENVTEST_TOOLS_ARCHIVE))
 
 # Build manager binary
 manager: generate fmt vet
 	go build -o bin/manager main.go
 
+{{ if .AggregatedAPIServer -}}
+# Run the API server locally, delegating the authentication and the authorization of the requests to the
+# configured Kubernetes cluster in ~/.kube/config
+KUBECONFIG ?= $(HOME)/.kube/config
+run: generate fmt vet manifests
+	go run ./main.go --kubeconfig=$(KUBECONFIG) --authentication-kubeconfig=$(KUBECONFIG) --authorization-kubeconfig=$(KUBECONFIG)
+
+# Deploy the API server in the configured Kubernetes cluster in ~/.kube/config and register its APIs
+deploy: manifests kustomize
+	cd config/manager && $(KUSTOMIZE) edit set image controller=${IMG}
+	$(KUSTOMIZE) build config/default | kubectl apply -f -
+	$(KUSTOMIZE) build config/apiservice | kubectl apply -f -
+
+# UnDeploy the API server from the configured Kubernetes cluster in ~/.kube/config
+undeploy:
+	$(KUSTOMIZE) build config/apiservice | kubectl delete -f -
+	$(KUSTOMIZE) build config/default | kubectl delete -f -
+{{- else -}}
 # Run against the configured Kubernetes cluster in ~/.kube/config
 run: generate fmt vet manifests
 	go run ./main.go
 
+# Run against the configured Kubernetes cluster in ~/.kube/config with the webhooks disabled,
+# as the API server of a remote cluster can not reach the webhook server of the local manager
+run-remote: generate fmt vet manifests install
+	ENABLE_WEBHOOKS=false go run ./main.go
+
+# Apply the manifests with server-side apply when a CRD is too large for the 262144 bytes last-applied-configuration
+# annotation of client-side apply, which is checked after generating the CRDs. Set SERVER_SIDE_APPLY=true or
+# SERVER_SIDE_APPLY=false to always or never apply them with it.
+SERVER_SIDE_APPLY ?= auto
+KUBECTL_APPLY = $(strip kubectl apply $(shell go run ./hack/crdlint --dir=config/crd/bases --server-side-apply=$(SERVER_SIDE_APPLY) --apply-flags))
+
 # Install CRDs into a cluster
 install: manifests kustomize
-	$(KUSTOMIZE) build config/crd | kubectl apply -f -
+	$(KUSTOMIZE) build config/crd | $(KUBECTL_APPLY) -f -
+
+# Build the CRDs in bin/crds.yaml and install them into a cluster with server-side apply, which is not subject to
+# the size limit of the last-applied-configuration annotation, e.g. to install them apart from the manager
+install-crds: manifests kustomize
+	mkdir -p bin
+	$(KUSTOMIZE) build config/crd > bin/crds.yaml
+	kubectl apply --server-side --force-conflicts -f bin/crds.yaml
 
 # Uninstall CRDs from a cluster
 uninstall: manifests kustomize
@@ -98,15 +204,108 @@ uninstall: manifests kustomize
 # Deploy controller in the configured Kubernetes cluster in ~/.kube/config
 deploy: manifests kustomize
 	cd config/manager && $(KUSTOMIZE) edit set image controller=${IMG}
-	$(KUSTOMIZE) build config/default | kubectl apply -f -
+	$(KUSTOMIZE) build config/default | $(KUBECTL_APPLY) -f -
 
 # UnDeploy controller from the configured Kubernetes cluster in ~/.kube/config
 undeploy:
 	$(KUSTOMIZE) build config/default | kubectl delete -f -
 
-# Generate manifests e.g. CRD, RBAC etc.
+# Test the upgrade from the previous release to IMG in the configured Kubernetes cluster, e.g. a kind cluster IMG
+# was loaded into. UPGRADE_FROM are the manifests of the previous release: a file, a URL or a kustomize directory.
+# The samples are created before the upgrade, and must still be reconciled and keep their specs after it.
+UPGRADE_FROM ?=
+test-upgrade: manifests kustomize
+	cd config/manager && $(KUSTOMIZE) edit set image controller=${IMG}
+	mkdir -p bin && $(KUSTOMIZE) build config/default > bin/upgrade-to.yaml
+	go run ./hack/upgradetest --from=$(UPGRADE_FROM) --to=bin/upgrade-to.yaml --apply="$(KUBECTL_APPLY)"
+{{- if .Overlays }}
+
+# Environments with a kustomize overlay in config/overlays, setting the image tag, the number of replicas and the
+# log level of the manager in the environment.
+ENVS ={{ range .Overlays }} {{ . }}{{ end }}
+
+# Deploy controller with the overlay of an environment, e.g. make deploy-staging
+$(addprefix deploy-,$(ENVS)): deploy-%: manifests kustomize
+	$(KUSTOMIZE) build config/overlays/$* | $(KUBECTL_APPLY) -f -
+
+# UnDeploy controller deployed with the overlay of an environment, e.g. make undeploy-staging
+$(addprefix undeploy-,$(ENVS)): undeploy-%:
+	$(KUSTOMIZE) build config/overlays/$* | kubectl delete -f -
+{{- end }}
+{{- end }}
+
+{{ if .AggregatedAPIServer -}}
+# Generate the RBAC manifests. The generation is skipped when neither the Go files, the options nor the
+# generated manifests changed since the previous one, run "make manifests FORCE=1" to run it anyway.
+MANIFESTS_HASH_OPTIONS = --file=bin/manifests.sha256 --options='{{ .ControllerToolsVersion }} rbac' --outputs=config/rbac/role.yaml
+manifests: controller-gen
+	@if [ "$(FORCE)" != "1" ] && go run ./hack/manifestshash $(MANIFESTS_HASH_OPTIONS) --check 2>/dev/null; then \
+		echo "Manifests are up to date, run \"make manifests FORCE=1\" to generate them anyway"; \
+	else \
+		$(CONTROLLER_GEN) rbac:roleName=manager-role paths="./..." && \
+		go run ./hack/manifestshash $(MANIFESTS_HASH_OPTIONS); \
+	fi
+{{- else -}}
+# Generate manifests e.g. CRD, RBAC etc. The generation is skipped when neither the Go files, the options
+# nor the generated manifests changed since the previous one, run "make manifests FORCE=1" to run it anyway.
+MANIFESTS_HASH_OPTIONS = --file=bin/manifests.sha256 --options='{{ .ControllerToolsVersion }} $(CRD_OPTIONS)'
 manifests: controller-gen
-	$(CONTROLLER_GEN) $(CRD_OPTIONS) rbac:roleName=manager-role webhook paths="./..." output:crd:artifacts:config=config/crd/bases
+	@if [ "$(FORCE)" != "1" ] && go run ./hack/manifestshash $(MANIFESTS_HASH_OPTIONS) --check 2>/dev/null; then \
+		echo "Manifests are up to date, run \"make manifests FORCE=1\" to generate them anyway"; \
+	else \
+		$(CONTROLLER_GEN) $(CRD_OPTIONS) rbac:roleName=manager-role webhook paths="./..." output:crd:artifacts:config=config/crd/bases && \
+		go run ./hack/crdlint --dir=config/crd/bases --server-side-apply=$(SERVER_SIDE_APPLY) && \
+		go run ./hack/manifestshash $(MANIFESTS_HASH_OPTIONS); \
+	fi
+
+# Check that the CRDs fit in the size limits of the API server and that their validation rules are
+# unlikely to exceed the CEL cost budget. It is also run by manifests, after generating the CRDs.
+lint-crds:
+	go run ./hack/crdlint --dir=config/crd/bases --server-side-apply=$(SERVER_SIDE_APPLY)
+
+# Verify that the CRDs are backwards compatible with the ones in CRD_COMPAT_BASE_REF.
+# It is run on the pull requests by .github/workflows/crd-compat.yml to catch breaking changes to served versions.
+CRD_COMPAT_BASE_REF ?= HEAD~1
+verify-crd-compat: manifests
+	go run ./hack/crdcompat --base-ref=$(CRD_COMPAT_BASE_REF) --dir=config/crd/bases
+
+# Report the rules of the manager role, generated from the +kubebuilder:rbac markers, that the manager never used
+# in RBAC_AUDIT_LOG, an audit log of the API server recorded while testing it, and the requests it was denied.
+# Set RBAC_AUDIT_OPTIONS=--strict to fail if a rule is unused.
+RBAC_AUDIT_LOG ?= bin/audit.log
+RBAC_AUDIT_OPTIONS ?=
+rbac-audit: manifests
+	go run ./hack/rbacaudit --dir=config/rbac --audit-log=$(RBAC_AUDIT_LOG) $(RBAC_AUDIT_OPTIONS)
+
+# Generate the reference of the fields of the kinds documented in docs/api, created with "create api --api-docs",
+# from the markers of their API types. Run "make api-docs API_DOCS_OPTIONS=--check" in CI to check that it is
+# up to date.
+API_DOCS_OPTIONS ?=
+api-docs:
+	go run ./hack/apidocs --dir=docs/api $(API_DOCS_OPTIONS)
+
+# Compile the wasm policies of policies/, scaffolded with "create webhook --wasm-policy", to Kubewarden policies
+# annotated with their metadata in bin/policies. Requires Go 1.21 or later and kwctl (experimental).
+KWCTL ?= kwctl
+wasm-policies:
+	@for policy in $$(ls policies 2>/dev/null); do \
+		echo "Building bin/policies/$$policy.wasm" && \
+		GOOS=wasip1 GOARCH=wasm go build -o bin/policies/$$policy.wasm ./policies/$$policy && \
+		$(KWCTL) annotate bin/policies/$$policy.wasm --metadata-path policies/$$policy/metadata.yml \
+			--output-path bin/policies/$$policy.annotated.wasm || exit 1; \
+	done
+{{- end }}
+
+# Validate the PROJECT file and check that it is in sync with the project, e.g. that every API is recorded
+# in it. Run it in CI to catch the changes made to the project without the kubebuilder CLI.
+validate-project:
+	$(KUBEBUILDER) config validate --strict
+
+# Check that the scaffold markers of main.go and of the test suites exist and that the APIs and webhooks of the
+# PROJECT file are wired in main.go. Run it in CI: without their markers, "create api" and "create webhook"
+# silently stop wiring the new APIs.
+verify-scaffold:
+	$(KUBEBUILDER) config validate --scaffold --strict
 
 # Run go fmt against code
 fmt:
@@ -120,13 +319,39 @@ vet:
 generate: controller-gen
 	$(CONTROLLER_GEN) object:headerFile={{printf "%q" .BoilerplatePath}} paths="./..."
 
-# Build the docker image
+# Build the docker image, passing the proxies of the environment, if set, and the Go module configuration
+# of the go commands to the builder, e.g. for the builds behind a corporate proxy
 docker-build: test
-	docker build -t ${IMG} .
+	docker build --build-arg HTTP_PROXY --build-arg HTTPS_PROXY --build-arg NO_PROXY \
+		--build-arg GOPROXY="$$(go env GOPROXY)" --build-arg GOPRIVATE="$$(go env GOPRIVATE)" \
+		--build-arg GONOSUMDB="$$(go env GONOSUMDB)" \
+{{- if or .SBOM .ImageSigning }}
+		--build-arg VCS_REF=$(shell git rev-parse HEAD 2>/dev/null) \
+{{- end }}
+		-t ${IMG} .
 
 # Push the docker image
 docker-push:
 	docker push ${IMG}
+{{- if .SBOM }}
+
+# Generate an SPDX SBOM of the docker image
+SBOM ?= bin/sbom.spdx.json
+docker-sbom: syft
+	$(SYFT) packages ${IMG} -o spdx-json > $(SBOM)
+{{- end }}
+{{- if .ImageSigning }}
+
+# Sign the pushed docker image
+{{- if .SignsWithKey }}
+COSIGN_KEY ?= cosign.key
+docker-sign: cosign
+	$(COSIGN) sign --key $(COSIGN_KEY) ${IMG}
+{{- else }}
+docker-sign: cosign
+	COSIGN_EXPERIMENTAL=1 $(COSIGN) sign ${IMG}
+{{- end }}
+{{- end }}
 
 # Download controller-gen locally if necessary
 CONTROLLER_GEN = $(shell pwd)/bin/controller-gen
@@ -138,6 +363,31 @@ KUSTOMIZE = $(shell pwd)/bin/kustomize
 kustomize:
 	$(call go-get-tool,$(KUSTOMIZE),sigs.k8s.io/kustomize/kustomize/v3@{{ .KustomizeVersion }})
 
+{{- if .SBOM }}
+
+# Download the syft release locally if necessary, syft can not be built by the Go version of the project
+SYFT = $(shell pwd)/bin/syft
+syft:
+	@[ -f $(SYFT) ] || { \
+	set -e ;\
+	echo "Downloading syft {{ .SyftVersion }}" ;\
+	curl -sSfL https://raw.githubusercontent.com/anchore/syft/{{ .SyftVersion }}/install.sh | sh -s -- -b $(dir $(SYFT)) {{ .SyftVersion }} ;\
+	}
+{{- end }}
+{{- if .ImageSigning }}
+
+# Download the cosign release locally if necessary, cosign can not be built by the Go version of the project
+COSIGN = $(shell pwd)/bin/cosign
+cosign:
+	@[ -f $(COSIGN) ] || { \
+	set -e ;\
+	echo "Downloading cosign {{ .CosignVersion }}" ;\
+	mkdir -p $(dir $(COSIGN)) ;\
+	curl -sSfL -o $(COSIGN) https://github.com/sigstore/cosign/releases/download/{{ .CosignVersion }}/cosign-$$(go env GOOS)-$$(go env GOARCH) ;\
+	chmod +x $(COSIGN) ;\
+	}
+{{- end }}
+
 # go-get-tool will 'go get' any package $2 and install it to $1.
 PROJECT_DIR := $(shell dirname $(abspath $(lastword $(MAKEFILE_LIST))))
 define go-get-tool
@@ -147,7 +397,7 @@ TMP_DIR=$$(mktemp -d) ;\
 cd $$TMP_DIR ;\
 go mod init tmp ;\
 echo "Downloading $(2)" ;\
-GOBIN=$(PROJECT_DIR)/bin go get $(2) ;\
+GOBIN=$(PROJECT_DIR)/bin GOPROXY=$(TOOL_GOPROXY) GOSUMDB=$(TOOL_GOSUMDB) go get $(2) ;\
 rm -rf $$TMP_DIR ;\
 }
 endef
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ManifestsMakefile{}
+
+// ManifestsMakefile scaffolds a file that defines the project management CLI commands of
+// projects that only hold manifests
+type ManifestsMakefile struct {
+	file.TemplateMixin
+
+	// Image is controller manager image name
+	Image string
+	// Kustomize version to use in the project
+	KustomizeVersion string
+	// GoEnv indicates whether the project defines its Go module configuration in a .go-env file
+	GoEnv bool
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ManifestsMakefile) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = "Makefile"
+	}
+
+	f.TemplateBody = manifestsMakefileTemplate
+
+	f.IfExistsAction = file.Error
+
+	if f.Image == "" {
+		f.Image = "controller:latest"
+	}
+
+	return nil
+}
+
+const manifestsMakefileTemplate = `
+# Image URL of the manager to deploy
+IMG ?= {{ .Image }}
+{{- if .GoEnv }}
+
+# Go module configuration of the project (GOPROXY, GOPRIVATE and GONOSUMDB) used by the go commands.
+include .go-env
+export GOPROXY GOPRIVATE GONOSUMDB
+{{- end }}
+
+all: build
+
+# TOOL_GOPROXY and TOOL_GOSUMDB are used to download and verify kustomize.
+TOOL_GOPROXY ?= $(shell go env GOPROXY)
+TOOL_GOSUMDB ?= $(shell go env GOSUMDB)
+
+# Render the manifests of the project
+build: kustomize
+	$(KUSTOMIZE) build config/default
+
+# Install CRDs into a cluster
+install: kustomize
+	$(KUSTOMIZE) build config/crd | kubectl apply -f -
+
+# Uninstall CRDs from a cluster
+uninstall: kustomize
+	$(KUSTOMIZE) build config/crd | kubectl delete -f -
+
+# Deploy controller in the configured Kubernetes cluster in ~/.kube/config
+deploy: kustomize
+	cd config/manager && $(KUSTOMIZE) edit set image controller=${IMG}
+	$(KUSTOMIZE) build config/default | kubectl apply -f -
+
+# UnDeploy controller from the configured Kubernetes cluster in ~/.kube/config
+undeploy: kustomize
+	$(KUSTOMIZE) build config/default | kubectl delete -f -
+
+# Validate the PROJECT file and check that it is in sync with the project, e.g. that every CRD is recorded
+# in it. Run it in CI to catch the changes made to the project without the kubebuilder CLI.
+KUBEBUILDER ?= kubebuilder
+validate-project:
+	$(KUBEBUILDER) config validate --strict
+
+# Download kustomize locally if necessary
+KUSTOMIZE = $(shell pwd)/bin/kustomize
+kustomize:
+	$(call go-get-tool,$(KUSTOMIZE),sigs.k8s.io/kustomize/kustomize/v3@{{ .KustomizeVersion }})
+
+# go-get-tool will 'go get' any package $2 and install it to $1.
+PROJECT_DIR := $(shell dirname $(abspath $(lastword $(MAKEFILE_LIST))))
+define go-get-tool
+@[ -f $(1) ] || { \
+set -e ;\
+TMP_DIR=$$(mktemp -d) ;\
+cd $$TMP_DIR ;\
+go mod init tmp ;\
+echo "Downloading $(2)" ;\
+GOBIN=$(PROJECT_DIR)/bin GOPROXY=$(TOOL_GOPROXY) GOSUMDB=$(TOOL_GOSUMDB) go get $(2) ;\
+rm -rf $$TMP_DIR ;\
+}
+endef
+`
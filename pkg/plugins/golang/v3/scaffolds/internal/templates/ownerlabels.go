@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+// OwnerLabels scaffolds a package that labels the objects controlled by the resources of the project
+type OwnerLabels struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+	file.DomainMixin
+	file.ProjectNameMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *OwnerLabels) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "ownerlabels", "ownerlabels.go")
+	}
+
+	f.TemplateBody = ownerLabelsTemplate
+
+	// The package is shared by all the owner labels webhooks
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const ownerLabelsTemplate = `{{ .Boilerplate }}
+
+// Package ownerlabels stamps the recommended app.kubernetes.io labels and a managed-by
+// annotation on the objects controlled by the resources of the project, which lets users
+// and platform tools select them, e.g. with kubectl get all -l app.kubernetes.io/instance=<name>.
+//
+// Controllers label the objects they create with Propagate. The owner labels webhooks label
+// the objects created by other means, e.g. adopted objects created with kubectl.
+package ownerlabels
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// NameLabel is the name of the application, defaulting to the kind of the owner.
+	NameLabel = "app.kubernetes.io/name"
+	// InstanceLabel identifies the instance of the application, defaulting to the name of the owner.
+	InstanceLabel = "app.kubernetes.io/instance"
+	// ManagedByLabel is the tool managing the object, ManagedBy.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByAnnotation names the owner of the object, as <kind>.<group>/<name>.
+	ManagedByAnnotation = "{{ .Domain }}/managed-by"
+
+	// ManagedBy is the value of the ManagedByLabel of the objects managed by the project.
+	ManagedBy = "{{ .ProjectName }}"
+
+	recommendedLabelPrefix = "app.kubernetes.io/"
+)
+
+// Stamp sets the labels and the annotation of obj controlled by the owner of kind ownerKind and
+// named ownerName. The name and instance labels already set are kept.
+func Stamp(obj metav1.Object, ownerKind schema.GroupKind, ownerName string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if labels[NameLabel] == "" {
+		labels[NameLabel] = strings.ToLower(ownerKind.Kind)
+	}
+	if labels[InstanceLabel] == "" {
+		labels[InstanceLabel] = ownerName
+	}
+	labels[ManagedByLabel] = ManagedBy
+	obj.SetLabels(labels)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ManagedByAnnotation] = ownerKind.String() + "/" + ownerName
+	obj.SetAnnotations(annotations)
+}
+
+// Propagate copies the app.kubernetes.io labels of owner, of kind ownerKind, to child, then stamps
+// child. Reconcilers call it on the objects they create or update, so that they share the labels
+// of their owner.
+func Propagate(owner metav1.Object, ownerKind schema.GroupKind, child metav1.Object) {
+	labels := child.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	for key, value := range owner.GetLabels() {
+		if strings.HasPrefix(key, recommendedLabelPrefix) && key != ManagedByLabel {
+			labels[key] = value
+		}
+	}
+	child.SetLabels(labels)
+	Stamp(child, ownerKind, owner.GetName())
+}
+
+// Handler is a mutating webhook stamping the objects controlled by an object of kind Owner. The
+// other objects are admitted unchanged.
+type Handler struct {
+	Owner schema.GroupKind
+}
+
+var _ admission.Handler = &Handler{}
+
+// Handle implements admission.Handler
+func (h *Handler) Handle(_ context.Context, req admission.Request) admission.Response {
+	var obj unstructured.Unstructured
+	if err := obj.UnmarshalJSON(req.Object.Raw); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	ref := metav1.GetControllerOf(&obj)
+	if ref == nil || ref.Kind != h.Owner.Kind {
+		return admission.Allowed("not controlled by a " + h.Owner.Kind)
+	}
+	if gv, err := schema.ParseGroupVersion(ref.APIVersion); err != nil || gv.Group != h.Owner.Group {
+		return admission.Allowed("not controlled by a " + h.Owner.Kind)
+	}
+
+	Stamp(&obj, h.Owner, ref.Name)
+	stamped, err := json.Marshal(&obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, stamped)
+}
+`
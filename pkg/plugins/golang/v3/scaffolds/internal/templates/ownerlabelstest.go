@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+// OwnerLabelsTest scaffolds the file that tests the ownerlabels package
+type OwnerLabelsTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *OwnerLabelsTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "ownerlabels", "ownerlabels_test.go")
+	}
+
+	f.TemplateBody = ownerLabelsTestTemplate
+
+	// The package is shared by all the owner labels webhooks
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const ownerLabelsTestTemplate = `{{ .Boilerplate }}
+
+package ownerlabels
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var ownerKind = schema.GroupKind{Group: "example.com", Kind: "Owner"}
+
+func TestPropagate(t *testing.T) {
+	owner := &metav1.ObjectMeta{Name: "owner", Labels: map[string]string{
+		"app.kubernetes.io/part-of": "shop",
+		InstanceLabel:               "shop-eu",
+		ManagedByLabel:              "helm",
+		"team":                      "payments",
+	} }
+	child := &metav1.ObjectMeta{Name: "child", Labels: map[string]string{NameLabel: "cache"}}
+
+	Propagate(owner, ownerKind, child)
+
+	for key, expected := range map[string]string{
+		"app.kubernetes.io/part-of": "shop",
+		InstanceLabel:               "shop-eu",
+		NameLabel:                   "cache",
+		ManagedByLabel:              ManagedBy,
+		"team":                      "",
+	} {
+		if value := child.Labels[key]; value != expected {
+			t.Errorf("expected label %s to be %q, got %q", key, expected, value)
+		}
+	}
+	if value, expected := child.Annotations[ManagedByAnnotation], "Owner.example.com/owner"; value != expected {
+		t.Errorf("expected annotation %s to be %q, got %q", ManagedByAnnotation, expected, value)
+	}
+}
+
+func TestHandle(t *testing.T) {
+	// The objects of the admission requests have their type meta set
+	typeMeta := metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"}
+	controlled := &corev1.ConfigMap{TypeMeta: typeMeta, ObjectMeta: metav1.ObjectMeta{
+		Name: "controlled", Namespace: "default",
+	}}
+	controlled.OwnerReferences = []metav1.OwnerReference{ {
+		APIVersion: "example.com/v1", Kind: "Owner", Name: "owner", UID: "uid", Controller: &[]bool{true}[0],
+	} }
+	uncontrolled := &corev1.ConfigMap{TypeMeta: typeMeta, ObjectMeta: metav1.ObjectMeta{
+		Name: "uncontrolled", Namespace: "default",
+	}}
+
+	for _, tc := range []struct {
+		obj     *corev1.ConfigMap
+		patched bool
+	}{
+		{controlled, true},
+		{uncontrolled, false},
+	} {
+		raw, err := json.Marshal(tc.obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handler := &Handler{Owner: ownerKind}
+		response := handler.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Object: runtime.RawExtension{Raw: raw},
+		}})
+
+		if !response.Allowed {
+			t.Errorf("%s: expected the object to be allowed", tc.obj.Name)
+		}
+		if patched := len(response.Patches) != 0; patched != tc.patched {
+			t.Errorf("%s: expected patched to be %t, got %t", tc.obj.Name, tc.patched, patched)
+		}
+	}
+}
+`
@@ -0,0 +1,102 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &PauseTest{}
+
+// PauseTest scaffolds the file that tests the pause package
+type PauseTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *PauseTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "pause", "pause_test.go")
+	}
+
+	f.TemplateBody = pauseTestTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const pauseTestTemplate = `{{ .Boilerplate }}
+
+package pause
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsPaused(t *testing.T) {
+	for _, tc := range []struct {
+		annotations map[string]string
+		paused      bool
+	}{
+		{annotations: nil, paused: false},
+		{annotations: map[string]string{Annotation: "true"}, paused: true},
+		{annotations: map[string]string{Annotation: "false"}, paused: false},
+		{annotations: map[string]string{Annotation: ""}, paused: false},
+	} {
+		obj := &metav1.ObjectMeta{Annotations: tc.annotations}
+		if paused := IsPaused(obj); paused != tc.paused {
+			t.Errorf("expected IsPaused to be %t for the annotations %v, got %t", tc.paused, tc.annotations, paused)
+		}
+	}
+}
+
+func TestSetPaused(t *testing.T) {
+	var conditions []metav1.Condition
+
+	if changed := SetResumed(&conditions, 1); changed || len(conditions) != 0 {
+		t.Errorf("expected an object that was never paused to have no Paused condition, got %v", conditions)
+	}
+
+	if changed := SetPaused(&conditions, 1); !changed {
+		t.Error("expected the condition to be added")
+	}
+	if !meta.IsStatusConditionTrue(conditions, ConditionPaused) {
+		t.Errorf("expected the Paused condition to be True, got %v", conditions)
+	}
+	if changed := SetPaused(&conditions, 1); changed {
+		t.Error("expected the condition not to change")
+	}
+
+	if changed := SetResumed(&conditions, 1); !changed {
+		t.Error("expected the condition to change")
+	}
+	condition := meta.FindStatusCondition(conditions, ConditionPaused)
+	if condition.Status != metav1.ConditionFalse || condition.Reason != ReasonResumed {
+		t.Errorf("expected the Paused condition to be False with the reason %s, got %v", ReasonResumed, condition)
+	}
+	if changed := SetResumed(&conditions, 1); changed {
+		t.Error("expected the condition not to change")
+	}
+}
+`
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Readiness{}
+
+// Readiness scaffolds a package that maintains the Ready condition of the reconciled objects and records
+// their time to ready as a metric
+type Readiness struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Readiness) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "readiness", "readiness.go")
+	}
+
+	f.TemplateBody = readinessTemplate
+
+	// The package is shared by all the controllers that use it
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const readinessTemplate = `{{ .Boilerplate }}
+
+// Package readiness maintains the Ready condition of the objects reconciled by the controllers and
+// records their time to ready, the time from their creation to their first Ready condition, which
+// is the KPI the SLOs of the operator are usually defined on.
+//
+// The metric is served with the controller-runtime ones by the metrics endpoint of the manager:
+//
+//   - resource_time_to_ready_seconds is a histogram of the time to ready of the objects, by group
+//     and kind.
+//
+// The Ready condition of an object that was never ready has the reason Progressing. Once ready, an
+// object that is no longer ready has the reason Degraded instead, so that only its first readiness
+// is observed, whatever the number of reconciliations and restarts of the manager.
+package readiness
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// ConditionReady is the type of the condition telling whether an object is ready.
+const ConditionReady = "Ready"
+
+// The reasons of the Ready condition
+const (
+	// ReasonReady is the reason of the objects that are ready.
+	ReasonReady = "Ready"
+	// ReasonProgressing is the reason of the objects that were never ready.
+	ReasonProgressing = "Progressing"
+	// ReasonDegraded is the reason of the objects that were ready and are no longer.
+	ReasonDegraded = "Degraded"
+)
+
+// Buckets are the upper bounds, in seconds, of the buckets of the time to ready histogram, from a
+// second to an hour. The time to ready objectives of the SLOs must be one of them.
+var Buckets = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+
+var timeToReady = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "resource_time_to_ready_seconds",
+	Help:    "Time from the creation of the objects to their first Ready condition, by group and kind",
+	Buckets: Buckets,
+}, []string{"group", "kind"})
+
+func init() {
+	metrics.Registry.MustRegister(timeToReady)
+}
+
+// SetReady sets the Ready condition of an object of the given generation to True. It returns
+// whether the condition changed, and whether the object is ready for the first time: observe its
+// time to ready with Observe once its status is updated.
+func SetReady(conditions *[]metav1.Condition, generation int64, message string) (changed, first bool) {
+	current := meta.FindStatusCondition(*conditions, ConditionReady)
+	first = current == nil || (current.Status != metav1.ConditionTrue && current.Reason == ReasonProgressing)
+	return set(conditions, metav1.Condition{
+		Type:               ConditionReady,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             ReasonReady,
+		Message:            message,
+	}), first
+}
+
+// SetNotReady sets the Ready condition of an object of the given generation to False, with the
+// reason Progressing if it was never ready and Degraded otherwise. It returns whether the condition
+// changed.
+func SetNotReady(conditions *[]metav1.Condition, generation int64, message string) bool {
+	reason := ReasonProgressing
+	if current := meta.FindStatusCondition(*conditions, ConditionReady); current != nil &&
+		(current.Status == metav1.ConditionTrue || current.Reason == ReasonDegraded) {
+		reason = ReasonDegraded
+	}
+	return set(conditions, metav1.Condition{
+		Type:               ConditionReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// set sets the condition in conditions, and returns whether it changed.
+func set(conditions *[]metav1.Condition, condition metav1.Condition) bool {
+	current := meta.FindStatusCondition(*conditions, condition.Type)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason &&
+		current.Message == condition.Message && current.ObservedGeneration == condition.ObservedGeneration {
+		return false
+	}
+	meta.SetStatusCondition(conditions, condition)
+	return true
+}
+
+// Observe records the time to ready of obj, of the group and kind gk: the time from its creation
+// to the last transition of its Ready condition, if True.
+func Observe(gk schema.GroupKind, obj metav1.Object, conditions []metav1.Condition) {
+	ready := meta.FindStatusCondition(conditions, ConditionReady)
+	if ready == nil || ready.Status != metav1.ConditionTrue {
+		return
+	}
+	elapsed := ready.LastTransitionTime.Sub(obj.GetCreationTimestamp().Time)
+	if elapsed < 0 {
+		// The timestamps are truncated to the second
+		elapsed = 0
+	}
+	timeToReady.WithLabelValues(gk.Group, gk.Kind).Observe(elapsed.Seconds())
+}
+`
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ReadinessTest{}
+
+// ReadinessTest scaffolds the file that tests the readiness package
+type ReadinessTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ReadinessTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "readiness", "readiness_test.go")
+	}
+
+	f.TemplateBody = readinessTestTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const readinessTestTemplate = `{{ .Boilerplate }}
+
+package readiness
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestSetReady(t *testing.T) {
+	var conditions []metav1.Condition
+
+	if changed := SetNotReady(&conditions, 1, "waiting"); !changed {
+		t.Error("expected the condition to be added")
+	}
+	if reason := meta.FindStatusCondition(conditions, ConditionReady).Reason; reason != ReasonProgressing {
+		t.Errorf("expected a new object not to be ready with the reason %s, got %s", ReasonProgressing, reason)
+	}
+	if changed := SetNotReady(&conditions, 1, "waiting"); changed {
+		t.Error("expected the condition not to change")
+	}
+
+	if changed, first := SetReady(&conditions, 1, "reconciled"); !changed || !first {
+		t.Errorf("expected the object to be ready for the first time, got changed %t and first %t", changed, first)
+	}
+	if changed, first := SetReady(&conditions, 1, "reconciled"); changed || first {
+		t.Errorf("expected the condition not to change, got changed %t and first %t", changed, first)
+	}
+
+	SetNotReady(&conditions, 2, "failing")
+	if reason := meta.FindStatusCondition(conditions, ConditionReady).Reason; reason != ReasonDegraded {
+		t.Errorf("expected a ready object not to be ready with the reason %s, got %s", ReasonDegraded, reason)
+	}
+	SetNotReady(&conditions, 3, "still failing")
+	if reason := meta.FindStatusCondition(conditions, ConditionReady).Reason; reason != ReasonDegraded {
+		t.Errorf("expected a degraded object to stay %s, got %s", ReasonDegraded, reason)
+	}
+	if _, first := SetReady(&conditions, 3, "reconciled"); first {
+		t.Error("expected a degraded object not to be ready for the first time")
+	}
+}
+
+func TestObserve(t *testing.T) {
+	gk := schema.GroupKind{Group: "test.example.org", Kind: "Frigate"}
+	created := time.Now().Add(-time.Minute)
+	obj := &metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}
+
+	var conditions []metav1.Condition
+	SetNotReady(&conditions, 1, "waiting")
+	Observe(gk, obj, conditions)
+	if count := sampleCount(t, gk); count != 0 {
+		t.Errorf("expected an object that is not ready not to be observed, got %d samples", count)
+	}
+
+	SetReady(&conditions, 1, "reconciled")
+	Observe(gk, obj, conditions)
+	metric := write(t, gk)
+	if count := metric.GetHistogram().GetSampleCount(); count != 1 {
+		t.Errorf("expected a sample, got %d", count)
+	}
+	if sum := metric.GetHistogram().GetSampleSum(); sum < 59 || sum > 120 {
+		t.Errorf("expected a time to ready of about a minute, got %vs", sum)
+	}
+}
+
+func sampleCount(t *testing.T, gk schema.GroupKind) uint64 {
+	return write(t, gk).GetHistogram().GetSampleCount()
+}
+
+func write(t *testing.T, gk schema.GroupKind) *dto.Metric {
+	t.Helper()
+	metric := &dto.Metric{}
+	if err := timeToReady.WithLabelValues(gk.Group, gk.Kind).(prometheus.Metric).Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	return metric
+}
+`
@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Renovate{}
+
+// Renovate scaffolds the Renovate configuration updating the Go modules and the base images of the project
+type Renovate struct {
+	file.TemplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Renovate) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = "renovate.json"
+	}
+
+	f.TemplateBody = renovateTemplate
+
+	return nil
+}
+
+const renovateTemplate = `{
+  "$schema": "https://docs.renovatebot.com/renovate-schema.json",
+  "extends": ["config:recommended"],
+  "schedule": ["before 6am on monday"],
+  "postUpdateOptions": ["gomodTidy"],
+  "packageRules": [
+    {
+      "description": "Update the Kubernetes libraries with controller-runtime, which requires their minor version",
+      "matchDatasources": ["go"],
+      "matchPackageNames": ["k8s.io/**", "sigs.k8s.io/controller-runtime"],
+      "groupName": "kubernetes"
+    },
+    {
+      "description": "Only bump the minor versions of the Kubernetes libraries with the updates of controller-runtime",
+      "matchDatasources": ["go"],
+      "matchPackageNames": ["k8s.io/**"],
+      "matchUpdateTypes": ["major", "minor"],
+      "enabled": false
+    }
+  ]
+}
+`
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Resync{}
+
+// Resync scaffolds a package that schedules the periodic reconciliations of the objects of a controller
+type Resync struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Resync) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "resync", "resync.go")
+	}
+
+	f.TemplateBody = resyncTemplate
+
+	// The package is shared by all the controllers that use it
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const resyncTemplate = `{{ .Boilerplate }}
+
+// Package resync schedules the periodic reconciliations of the objects of a controller, which
+// reconciles them again after a period even if nothing changed, e.g. to renew a certificate or a
+// license before it expires.
+//
+// The time of the next periodic reconciliation of an object is recorded in its status, so that it
+// survives the restarts of the manager and the other reconciliations of the object do not postpone
+// it, and the object is requeued until then with the RequeueAfter of the reconcile result. The
+// period is spread by a random jitter, so that the objects created together, or all the objects
+// when the manager starts, are not reconciled again all at once.
+package resync
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// DefaultJitterFactor spreads the periodic reconciliations over 10% of their period.
+const DefaultJitterFactor = 0.1
+
+// Schedule schedules the periodic reconciliations of objects every period, plus a random jitter
+// of up to jitterFactor times the period.
+type Schedule struct {
+	period       time.Duration
+	jitterFactor float64
+	clock        clock.PassiveClock
+}
+
+// New returns a schedule reconciling the objects every period, spread by jitterFactor.
+func New(period time.Duration, jitterFactor float64) *Schedule {
+	return NewWithClock(period, jitterFactor, clock.RealClock{})
+}
+
+// NewWithClock returns a schedule reconciling the objects every period, spread by jitterFactor,
+// measured by c.
+func NewWithClock(period time.Duration, jitterFactor float64, c clock.PassiveClock) *Schedule {
+	return &Schedule{period: period, jitterFactor: jitterFactor, clock: c}
+}
+
+// Period returns the period of the reconciliations, without the jitter.
+func (s *Schedule) Period() time.Duration {
+	return s.period
+}
+
+// Due returns whether the periodic reconciliation of an object whose next one is scheduled at
+// next is due, which it is if next is nil, and otherwise the time left until it is.
+func (s *Schedule) Due(next *metav1.Time) (bool, time.Duration) {
+	if next == nil {
+		return true, 0
+	}
+	now := s.clock.Now()
+	if !now.Before(next.Time) {
+		return true, 0
+	}
+	return false, next.Sub(now)
+}
+
+// Next returns the time of the next periodic reconciliation of an object reconciled now, to
+// record in its status, and the time left until then, to requeue it after. The time is truncated
+// to the second, the precision it is serialized with.
+func (s *Schedule) Next() (metav1.Time, time.Duration) {
+	now := s.clock.Now()
+	next := metav1.NewTime(now.Add(wait.Jitter(s.period, s.jitterFactor))).Rfc3339Copy()
+	return next, next.Sub(now)
+}
+`
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ResyncTest{}
+
+// ResyncTest scaffolds the file that tests the resync package
+type ResyncTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ResyncTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "resync", "resync_test.go")
+	}
+
+	f.TemplateBody = resyncTestTemplate
+
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const resyncTestTemplate = `{{ .Boilerplate }}
+
+package resync
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+)
+
+func TestSchedule(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+	s := NewWithClock(time.Hour, 0.5, fakeClock)
+
+	if due, _ := s.Due(nil); !due {
+		t.Error("expected an object never reconciled periodically to be due")
+	}
+
+	next, after := s.Next()
+	if after < time.Hour-time.Second || after > 90*time.Minute {
+		t.Errorf("expected the next reconciliation within the period and its jitter, got %s", after)
+	}
+	if !next.Equal(&metav1.Time{Time: fakeClock.Now().Add(after)}) {
+		t.Errorf("expected the next reconciliation at %s, got %s", fakeClock.Now().Add(after), next)
+	}
+
+	fakeClock.Step(after / 2)
+	if due, left := s.Due(&next); due || left != after-after/2 {
+		t.Errorf("expected the reconciliation to be due in %s, got due=%t in %s", after-after/2, due, left)
+	}
+
+	fakeClock.Step(after - after/2)
+	if due, _ := s.Due(&next); !due {
+		t.Error("expected the reconciliation to be due at its time")
+	}
+}
+
+func TestScheduleJitter(t *testing.T) {
+	s := NewWithClock(time.Hour, 0.5, clock.NewFakeClock(time.Now()))
+	times := map[time.Duration]bool{}
+	for i := 0; i < 10; i++ {
+		_, after := s.Next()
+		times[after] = true
+	}
+	if len(times) == 1 {
+		t.Error("expected the jitter to spread the next reconciliations")
+	}
+}
+`
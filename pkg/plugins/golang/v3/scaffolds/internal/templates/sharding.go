@@ -0,0 +1,295 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Sharding{}
+
+// Sharding scaffolds a package that spreads the objects of the resources of the project across several managers
+type Sharding struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+	file.DomainMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Sharding) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "sharding", "sharding.go")
+	}
+
+	f.TemplateBody = shardingTemplate
+
+	return nil
+}
+
+const shardingTemplate = `{{ .Boilerplate }}
+
+// Package sharding spreads the objects of the resources of the project across several managers,
+// the shards, for the fleets too large to be cached and reconciled by a single manager.
+//
+// Every object is labeled with a bucket, a hash of its namespace and name. Each shard owns the
+// buckets whose number modulo the shard count is its ID, and only lists and watches their objects:
+// its cache requests the objects of the project groups with the label selector of its buckets. The
+// first shard also runs the assigner, which labels the objects created without a bucket.
+//
+// Sharding is experimental: the objects of the other groups, such as the children of the resources,
+// are cached by every shard, and the shards must all be stopped before changing the shard count.
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+const (
+	// BucketLabel is the label holding the bucket of an object.
+	BucketLabel = "{{ .Domain }}/shard-bucket"
+	// Buckets is the number of buckets, which is the maximum shard count.
+	Buckets = 64
+	// Domain is the domain of the groups of the sharded resources.
+	Domain = "{{ .Domain }}"
+)
+
+// Bucket returns the bucket of the object named name in namespace.
+func Bucket(namespace, name string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(namespace + "/" + name))
+	return int(hash.Sum32() % Buckets)
+}
+
+// Sharded returns true if the resources of group are sharded: the groups of the project, whose
+// domain is Domain.
+func Sharded(group string) bool {
+	return group == Domain || strings.HasSuffix(group, "."+Domain)
+}
+
+// Shard is the shard ID among Count shards. The zero value, like any shard of a single one, owns
+// every object.
+type Shard struct {
+	ID, Count int
+}
+
+// Validate checks that s is one of 1 to Buckets shards.
+func (s Shard) Validate() error {
+	if s.Count < 1 || s.Count > Buckets {
+		return fmt.Errorf("the shard count must be between 1 and %d, got %d", Buckets, s.Count)
+	}
+	if s.ID < 0 || s.ID >= s.Count {
+		return fmt.Errorf("the shard ID must be between 0 and %d, got %d", s.Count-1, s.ID)
+	}
+	return nil
+}
+
+// Owns returns true if the objects of bucket are reconciled by s.
+func (s Shard) Owns(bucket int) bool {
+	return s.Count <= 1 || bucket%s.Count == s.ID
+}
+
+// Selector returns the label selector of the objects of s, which selects every object if s is the
+// only shard.
+func (s Shard) Selector() labels.Selector {
+	if s.Count <= 1 {
+		return labels.Everything()
+	}
+	var buckets []string
+	for bucket := s.ID; bucket < Buckets; bucket += s.Count {
+		buckets = append(buckets, strconv.Itoa(bucket))
+	}
+	requirement, err := labels.NewRequirement(BucketLabel, selection.In, buckets)
+	if err != nil {
+		panic(err)
+	}
+	return labels.NewSelector().Add(*requirement)
+}
+
+// LeaderElectionID returns the leader election ID of the managers of s, whose replicas elect a
+// leader per shard.
+func (s Shard) LeaderElectionID(id string) string {
+	if s.Count <= 1 {
+		return id
+	}
+	return fmt.Sprintf("%s-shard-%d", id, s.ID)
+}
+
+// NewCache implements cache.NewCacheFunc, creating a cache that only lists and watches the objects
+// of s among the objects of the sharded resources.
+func (s Shard) NewCache(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+	if s.Count <= 1 {
+		return cache.New(config, opts)
+	}
+	return newSelectingCache(config, opts, s.Selector())
+}
+
+// newSelectingCache creates a cache that only lists and watches the objects of the sharded
+// resources matching selector. The cache of controller-runtime does not select the objects it lists
+// and watches, the selector is added to its requests instead.
+func newSelectingCache(config *rest.Config, opts cache.Options, selector labels.Selector) (cache.Cache, error) {
+	config = rest.CopyConfig(config)
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &selectingRoundTripper{selector: selector.String(), next: rt}
+	})
+	return cache.New(config, opts)
+}
+
+// selectingRoundTripper adds its label selector to the list and watch requests of the sharded
+// resources.
+type selectingRoundTripper struct {
+	selector string
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (rt *selectingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || !isShardedCollection(req.URL.Path) {
+		return rt.next.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	query := req.URL.Query()
+	selector := rt.selector
+	if existing := query.Get("labelSelector"); existing != "" {
+		selector = existing + "," + selector
+	}
+	query.Set("labelSelector", selector)
+	req.URL.RawQuery = query.Encode()
+	return rt.next.RoundTrip(req)
+}
+
+// isShardedCollection returns true if path is a collection of a sharded resource, which is listed
+// and watched with /apis/GROUP/VERSION/[namespaces/NAMESPACE/]RESOURCE.
+func isShardedCollection(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 4 || parts[0] != "apis" || !Sharded(parts[1]) {
+		return false
+	}
+	parts = parts[3:]
+	if len(parts) == 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	return len(parts) == 1
+}
+
+// SetupAssigner sets up the assigner with mgr, which labels the objects of the sharded resources
+// registered in the scheme of mgr that have no bucket yet. Only one shard must run it.
+func SetupAssigner(mgr ctrl.Manager) error {
+	unassigned, err := labels.NewRequirement(BucketLabel, selection.DoesNotExist, nil)
+	if err != nil {
+		return err
+	}
+	// The assigner only caches the objects that have no bucket
+	assignerCache, err := newSelectingCache(mgr.GetConfig(),
+		cache.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()},
+		labels.NewSelector().Add(*unassigned))
+	if err != nil {
+		return err
+	}
+	if err := mgr.Add(assignerCache); err != nil {
+		return err
+	}
+
+	kinds, err := shardedKinds(mgr)
+	if err != nil {
+		return err
+	}
+	for _, gvk := range kinds {
+		obj, err := mgr.GetScheme().New(gvk)
+		if err != nil {
+			return err
+		}
+		c, err := controller.New("shard-assigner-"+strings.ToLower(gvk.GroupKind().String()), mgr,
+			controller.Options{Reconciler: &assigner{client: mgr.GetClient(), gvk: gvk}})
+		if err != nil {
+			return err
+		}
+		if err := c.Watch(source.NewKindWithCache(obj.(client.Object), assignerCache),
+			&handler.EnqueueRequestForObject{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shardedKinds returns the kinds of the sharded resources registered in the scheme of mgr, in the
+// version preferred by the API server among the registered ones.
+func shardedKinds(mgr ctrl.Manager) ([]schema.GroupVersionKind, error) {
+	versions := map[schema.GroupKind][]string{}
+	for gvk, t := range mgr.GetScheme().AllKnownTypes() {
+		if !Sharded(gvk.Group) || gvk.Version == runtime.APIVersionInternal || strings.HasSuffix(gvk.Kind, "List") {
+			continue
+		}
+		// Skip the options and the events registered in every group
+		if _, found := t.FieldByName("ObjectMeta"); !found {
+			continue
+		}
+		versions[gvk.GroupKind()] = append(versions[gvk.GroupKind()], gvk.Version)
+	}
+
+	kinds := make([]schema.GroupVersionKind, 0, len(versions))
+	for gk, gkVersions := range versions {
+		mapping, err := mgr.GetRESTMapper().RESTMapping(gk, gkVersions...)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find the resource of %s: %w", gk, err)
+		}
+		kinds = append(kinds, mapping.GroupVersionKind)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].String() < kinds[j].String() })
+	return kinds, nil
+}
+
+// assigner labels the objects of gvk with their bucket.
+type assigner struct {
+	client client.Client
+	gvk    schema.GroupVersionKind
+}
+
+// Reconcile implements reconcile.Reconciler
+func (a *assigner) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(a.gvk)
+	obj.SetNamespace(req.Namespace)
+	obj.SetName(req.Name)
+	bucket := strconv.Itoa(Bucket(req.Namespace, req.Name))
+	patch := fmt.Sprintf(` + "`" + `{"metadata":{"labels":{%q:%q}}}` + "`" + `, BucketLabel, bucket)
+	err := a.client.Patch(ctx, obj, client.RawPatch(types.MergePatchType, []byte(patch)))
+	return reconcile.Result{}, client.IgnoreNotFound(err)
+}
+`
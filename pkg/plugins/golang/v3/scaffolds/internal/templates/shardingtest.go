@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &ShardingTest{}
+
+// ShardingTest scaffolds the file that tests the sharding package
+type ShardingTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *ShardingTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "sharding", "sharding_test.go")
+	}
+
+	f.TemplateBody = shardingTestTemplate
+
+	return nil
+}
+
+const shardingTestTemplate = `{{ .Boilerplate }}
+
+package sharding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestBucket(t *testing.T) {
+	if Bucket("default", "a") != Bucket("default", "a") {
+		t.Error("expected the bucket of an object to be stable")
+	}
+	if Bucket("default", "a") == Bucket("other", "a") && Bucket("default", "b") == Bucket("other", "b") {
+		t.Error("expected the namespace to be hashed")
+	}
+
+	counts := make([]int, Buckets)
+	for i := 0; i < 100*Buckets; i++ {
+		bucket := Bucket("default", "object-"+strconv.Itoa(i))
+		if bucket < 0 || bucket >= Buckets {
+			t.Fatalf("expected a bucket between 0 and %d, got %d", Buckets-1, bucket)
+		}
+		counts[bucket]++
+	}
+	for bucket, count := range counts {
+		if count < 50 || count > 150 {
+			t.Errorf("expected about 100 objects in bucket %d, got %d", bucket, count)
+		}
+	}
+}
+
+func TestShardValidate(t *testing.T) {
+	for _, shard := range []Shard{ {ID: 0, Count: 1}, {ID: 2, Count: 3}, {ID: Buckets - 1, Count: Buckets}} {
+		if err := shard.Validate(); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", shard, err)
+		}
+	}
+	for _, shard := range []Shard{ {}, {ID: 3, Count: 3}, {ID: -1, Count: 3}, {Count: Buckets + 1}} {
+		if err := shard.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", shard)
+		}
+	}
+}
+
+func TestShardOwnsEveryBucketOnce(t *testing.T) {
+	for _, count := range []int{1, 2, 3, 7, Buckets} {
+		for bucket := 0; bucket < Buckets; bucket++ {
+			set := labels.Set{BucketLabel: strconv.Itoa(bucket)}
+			owners := 0
+			for id := 0; id < count; id++ {
+				shard := Shard{ID: id, Count: count}
+				if shard.Owns(bucket) != shard.Selector().Matches(set) {
+					t.Errorf("expected the selector of %+v to match the buckets it owns, bucket %d", shard, bucket)
+				}
+				if shard.Owns(bucket) {
+					owners++
+				}
+			}
+			if owners != 1 {
+				t.Errorf("expected bucket %d to be owned by one of %d shards, got %d", bucket, count, owners)
+			}
+		}
+	}
+	if !(Shard{}).Selector().Empty() {
+		t.Error("expected the zero shard to select every object")
+	}
+}
+
+func TestShardLeaderElectionID(t *testing.T) {
+	if id := (Shard{ID: 0, Count: 1}).LeaderElectionID("manager"); id != "manager" {
+		t.Errorf("expected a single shard to keep the leader election ID, got %s", id)
+	}
+	if id := (Shard{ID: 1, Count: 2}).LeaderElectionID("manager"); id != "manager-shard-1" {
+		t.Errorf("expected the leader election ID of the shard, got %s", id)
+	}
+}
+
+func TestSelectingRoundTripper(t *testing.T) {
+	var selector string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		selector = r.URL.Query().Get("labelSelector")
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: &selectingRoundTripper{selector: "bucket=1", next: http.DefaultTransport}}
+
+	for path, expected := range map[string]string{
+		"/apis/crew." + Domain + "/v1/captains":                             "bucket=1",
+		"/apis/crew." + Domain + "/v1/namespaces/default/captains":          "bucket=1",
+		"/apis/" + Domain + "/v1/captains?watch=true":                       "bucket=1",
+		"/apis/crew." + Domain + "/v1/captains?labelSelector=team%3Dblue":   "team=blue,bucket=1",
+		"/apis/crew." + Domain + "/v1/namespaces/default/captains/captain":  "",
+		"/apis/crew." + Domain + "/v1/namespaces/default/captains/c/status": "",
+		"/apis/apps/v1/deployments":                                         "",
+		"/apis/crew.other" + Domain + "/v1/captains":                        "",
+		"/api/v1/namespaces/default/configmaps":                             "",
+	} {
+		selector = ""
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if selector != expected {
+			t.Errorf("%s: expected the label selector %q, got %q", path, expected, selector)
+		}
+	}
+}
+`
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Status{}
+
+// Status scaffolds a package that patches the status of the reconciled objects, retrying the conflicts
+type Status struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Status) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "status", "status.go")
+	}
+
+	f.TemplateBody = statusTemplate
+
+	// The package is shared by all the controllers
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const statusTemplate = `{{ .Boilerplate }}
+
+// Package status writes the status of the objects reconciled by the controllers.
+//
+// The status of an object is not only written by its controller: other controllers, or the
+// users with kubectl, may write it too. An update built from a stale copy of the object, such
+// as the one read from the cache at the start of the reconciliation, fails with a conflict, and
+// an update or a patch sent without the resourceVersion of the object silently reverts the
+// changes of the other writers.
+//
+// Patch sends a merge patch holding only the changes of the status, guarded by the
+// resourceVersion of the object, and applies the changes again to a fresh copy of the object
+// when the patch conflicts. Nothing is sent when the status did not change, which SetCondition
+// preserves by leaving the conditions already set untouched.
+package status
+
+import (
+	"context"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Backoff is the backoff of the retries of the conflicting patches.
+var Backoff = retry.DefaultRetry
+
+// Patch applies mutate to the status of obj and patches it, returning whether the status changed.
+//
+// The patch fails with a conflict if obj was modified since it was read: obj is then read again
+// with c and mutate applied to it again, until the patch succeeds or Backoff is exhausted. mutate
+// may thus be called several times, and must compute the status from obj, not from a copy of it
+// read before. The other errors, including the ones of mutate, are returned as is.
+func Patch(ctx context.Context, c client.Client, obj client.Object, mutate func() error) (bool, error) {
+	key := client.ObjectKeyFromObject(obj)
+	changed, read := false, false
+	err := retry.RetryOnConflict(Backoff, func() error {
+		if read {
+			// Reset obj first, the fields missing from the fresh copy would otherwise keep the values
+			// of the previous attempt
+			value := reflect.ValueOf(obj).Elem()
+			value.Set(reflect.Zero(value.Type()))
+			if err := c.Get(ctx, key, obj); err != nil {
+				return err
+			}
+		}
+		read = true
+
+		original := obj.DeepCopyObject()
+		if err := mutate(); err != nil {
+			return err
+		}
+		if changed = !equality.Semantic.DeepEqual(original, obj); !changed {
+			return nil
+		}
+		return c.Status().Patch(ctx, obj, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+	})
+	return changed, err
+}
+
+// SetCondition sets the condition in conditions and returns whether they changed. The duplicate
+// conditions of the same type, e.g. written by an older version of the controller, are removed,
+// and the conditions are left untouched if the condition is already set: its LastTransitionTime
+// is only updated when its status changes.
+func SetCondition(conditions *[]metav1.Condition, condition metav1.Condition) bool {
+	changed := dedup(conditions)
+	current := meta.FindStatusCondition(*conditions, condition.Type)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason &&
+		current.Message == condition.Message && current.ObservedGeneration == condition.ObservedGeneration {
+		return changed
+	}
+	meta.SetStatusCondition(conditions, condition)
+	return true
+}
+
+// RemoveCondition removes the conditions of the given type and returns whether they changed.
+func RemoveCondition(conditions *[]metav1.Condition, conditionType string) bool {
+	changed := dedup(conditions)
+	if meta.FindStatusCondition(*conditions, conditionType) == nil {
+		return changed
+	}
+	meta.RemoveStatusCondition(conditions, conditionType)
+	return true
+}
+
+// dedup removes the conditions whose type is the one of a previous condition, and returns whether
+// there were any.
+func dedup(conditions *[]metav1.Condition) bool {
+	seen := make(map[string]bool, len(*conditions))
+	deduped := (*conditions)[:0]
+	for _, condition := range *conditions {
+		if !seen[condition.Type] {
+			seen[condition.Type] = true
+			deduped = append(deduped, condition)
+		}
+	}
+	changed := len(deduped) != len(*conditions)
+	*conditions = deduped
+	return changed
+}
+`
@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &StatusTest{}
+
+// StatusTest scaffolds the file that tests the status package
+type StatusTest struct {
+	file.TemplateMixin
+	file.BoilerplateMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *StatusTest) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "status", "status_test.go")
+	}
+
+	f.TemplateBody = statusTestTemplate
+
+	// The package is shared by all the controllers
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+const statusTestTemplate = `{{ .Boilerplate }}
+
+package status
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newClient returns a fake client holding a pod, created with the client to be given a resourceVersion
+func newClient(t *testing.T) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}}
+	if err := c.Create(context.Background(), pod); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	obj := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "pod", Namespace: "default"}, obj); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := Patch(ctx, c, obj, func() error {
+		obj.Status.Message = "running"
+		return nil
+	})
+	if err != nil || !changed {
+		t.Fatalf("expected the status to be patched, got changed %t and %v", changed, err)
+	}
+
+	calls := 0
+	changed, err = Patch(ctx, c, obj, func() error {
+		calls++
+		obj.Status.Message = "running"
+		return nil
+	})
+	if err != nil || changed || calls != 1 {
+		t.Errorf("expected nothing to be patched, got changed %t, %d calls and %v", changed, calls, err)
+	}
+
+	testErr := errors.New("test")
+	if _, err := Patch(ctx, c, obj, func() error { return testErr }); !errors.Is(err, testErr) {
+		t.Errorf("expected the error of mutate, got %v", err)
+	}
+}
+
+func TestPatchConflict(t *testing.T) {
+	ctx := context.Background()
+	c := newClient(t)
+
+	stale := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "pod", Namespace: "default"}, stale); err != nil {
+		t.Fatal(err)
+	}
+	// Another writer modifies the status after the object was read
+	other := stale.DeepCopy()
+	other.Status.Reason = "Scheduled"
+	if err := c.Status().Update(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	changed, err := Patch(ctx, c, stale, func() error {
+		calls++
+		stale.Status.Message = "running"
+		return nil
+	})
+	if err != nil || !changed {
+		t.Fatalf("expected the status to be patched, got changed %t and %v", changed, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected mutate to be applied again after the conflict, got %d calls", calls)
+	}
+
+	obj := &corev1.Pod{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "pod", Namespace: "default"}, obj); err != nil {
+		t.Fatal(err)
+	}
+	if obj.Status.Reason != "Scheduled" || obj.Status.Message != "running" {
+		t.Errorf("expected the changes of both writers to be kept, got %+v", obj.Status)
+	}
+}
+
+func TestSetCondition(t *testing.T) {
+	condition := metav1.Condition{Type: "Available", Status: metav1.ConditionTrue, Reason: "Available",
+		ObservedGeneration: 1}
+	var conditions []metav1.Condition
+
+	if changed := SetCondition(&conditions, condition); !changed {
+		t.Error("expected the condition to be added")
+	}
+	transition := meta.FindStatusCondition(conditions, "Available").LastTransitionTime
+	if changed := SetCondition(&conditions, condition); changed {
+		t.Error("expected the condition not to change")
+	}
+
+	condition.ObservedGeneration = 2
+	if changed := SetCondition(&conditions, condition); !changed {
+		t.Error("expected the observed generation to change")
+	}
+	if current := meta.FindStatusCondition(conditions, "Available"); !current.LastTransitionTime.Equal(&transition) {
+		t.Errorf("expected the last transition time to be kept, got %v", current.LastTransitionTime)
+	}
+
+	// The duplicates of a condition are removed
+	conditions = append(conditions, metav1.Condition{Type: "Available", Status: metav1.ConditionFalse})
+	if changed := SetCondition(&conditions, condition); !changed || len(conditions) != 1 {
+		t.Errorf("expected the duplicate condition to be removed, got changed %t and %+v", changed, conditions)
+	}
+	if conditions[0].Status != metav1.ConditionTrue {
+		t.Errorf("expected the first condition to be kept, got %+v", conditions[0])
+	}
+
+	if changed := RemoveCondition(&conditions, "Available"); !changed || len(conditions) != 0 {
+		t.Errorf("expected the condition to be removed, got changed %t and %+v", changed, conditions)
+	}
+	if changed := RemoveCondition(&conditions, "Available"); changed {
+		t.Error("expected nothing to be removed")
+	}
+}
+`
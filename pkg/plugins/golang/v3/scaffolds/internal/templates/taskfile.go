@@ -0,0 +1,504 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &Taskfile{}
+
+// Taskfile scaffolds a Taskfile.yaml that defines the targets of the Makefile as go-task tasks
+type Taskfile struct {
+	Makefile
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *Taskfile) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = "Taskfile.yaml"
+	}
+
+	f.TemplateBody = taskfileTemplate
+
+	f.IfExistsAction = file.Error
+
+	if f.Image == "" {
+		f.Image = "controller:latest"
+	}
+
+	return nil
+}
+
+// Var returns the reference to the variable name expanded by go-task, whose templates use the same
+// delimiters as the scaffolded one
+func (f *Taskfile) Var(name string) string {
+	return "{{." + name + "}}"
+}
+
+//nolint:lll
+const taskfileTemplate = `# Targets of the project, run with go-task (https://taskfile.dev).
+# Override their variables on the command line, e.g. task docker-build IMG=registry.example.com/controller:v1
+version: '3'
+
+# Run every task once per invocation, as make does with its targets, whatever the number of tasks depending on it
+run: once
+{{- if .GoEnv }}
+
+# Go module configuration of the project (GOPROXY, GOPRIVATE and GONOSUMDB) used by the go commands.
+dotenv: ['.go-env']
+{{- end }}
+
+vars:
+  # Image URL to use all building/pushing image targets
+  IMG: {{ .Image }}
+{{- if not .AggregatedAPIServer }}
+  # Produce CRDs that work back to Kubernetes 1.11 (no version conversion)
+  CRD_OPTIONS: crd:trivialVersions=true,preserveUnknownFields=false
+{{- end }}
+  PROJECT_DIR:
+    sh: pwd
+
+  # Tool mirror configuration, for environments without internet access.
+  # TOOL_MIRROR is the base URL of a mirror serving the envtest binaries
+  # with the same layout as the upstream locations. Leave it empty to use the upstream ones.
+  TOOL_MIRROR: '{{ .ToolMirror }}'
+  # TOOL_GOPROXY and TOOL_GOSUMDB are used to download and verify controller-gen and kustomize.
+  TOOL_GOPROXY:
+    sh: go env GOPROXY
+  TOOL_GOSUMDB:
+    sh: go env GOSUMDB
+  # Expected sha256 checksum of the envtest binaries archive, verified if set.
+  ENVTEST_TOOLS_SHA256: ''
+
+  # The kubebuilder CLI, used to install the envtest binaries and to validate the PROJECT file
+  KUBEBUILDER: kubebuilder
+  # Kubernetes version of the envtest binaries. Set ENVTEST_TOOLS_ARCHIVE to the path of a kubebuilder-tools
+  # archive to install them offline.
+  ENVTEST_K8S_VERSION: '{{ .EnvtestK8sVersion }}'
+  ENVTEST_TOOLS_ARCHIVE: ''
+{{- if .AggregatedAPIServer }}
+
+  # Kubeconfig of the cluster delegating the authentication and the authorization of the requests
+  KUBECONFIG:
+    sh: echo ${KUBECONFIG:-$HOME/.kube/config}
+{{- else }}
+
+  # Number of objects reconciled by each benchmark of the controllers
+  BENCH_OBJECTS: '100'
+
+  # Apply the manifests with server-side apply when a CRD is too large for the 262144 bytes last-applied-configuration
+  # annotation of client-side apply, which is checked after generating the CRDs. Set SERVER_SIDE_APPLY=true or
+  # SERVER_SIDE_APPLY=false to always or never apply them with it.
+  SERVER_SIDE_APPLY: auto
+
+  # Base revision of the CRDs checked by verify-crd-compat
+  CRD_COMPAT_BASE_REF: HEAD~1
+  # Options of api-docs, e.g. --check
+  API_DOCS_OPTIONS: ''
+  # Manifests of the previous release tested by test-upgrade: a file, a URL or a kustomize directory
+  UPGRADE_FROM: ''
+  # Audit log of the API server read by rbac-audit, and its options, e.g. --strict
+  RBAC_AUDIT_LOG: bin/audit.log
+  RBAC_AUDIT_OPTIONS: ''
+  # The kwctl CLI, used to annotate the wasm policies with their metadata
+  KWCTL: kwctl
+{{- end }}
+
+  # Set FORCE=1 to generate the manifests even if they are up to date
+  FORCE: ''
+{{- if .SBOM }}
+
+  # Path of the SBOM of the docker image
+  SBOM: bin/sbom.spdx.json
+{{- end }}
+{{- if .SignsWithKey }}
+
+  # Private key signing the docker image
+  COSIGN_KEY: cosign.key
+{{- end }}
+
+  # Tools downloaded locally
+  CONTROLLER_GEN: '{{ .Var "PROJECT_DIR" }}/bin/controller-gen'
+  KUSTOMIZE: '{{ .Var "PROJECT_DIR" }}/bin/kustomize'
+{{- if .SBOM }}
+  SYFT: '{{ .Var "PROJECT_DIR" }}/bin/syft'
+{{- end }}
+{{- if .ImageSigning }}
+  COSIGN: '{{ .Var "PROJECT_DIR" }}/bin/cosign'
+{{- end }}
+
+tasks:
+  default:
+    cmds:
+      - task: all
+
+  all:
+    desc: Build manager binary
+    cmds:
+      - task: manager
+
+  test:
+    desc: Run tests
+    cmds:
+      - task: generate
+      - task: fmt
+      - task: vet
+      - task: manifests
+      - task: envtest
+      - KUBEBUILDER_ASSETS="$({{ .Var "KUBEBUILDER" }} envtest use {{ .Var "ENVTEST_K8S_VERSION" }} --installed-only --print=path)" go test ./... -coverprofile cover.out
+{{- if not .AggregatedAPIServer }}
+
+  # Compare the results of two revisions with benchstat.
+  bench:
+    desc: Run the benchmarks of the controllers, scaffolded with "create api --benchmark", each of them reconciling BENCH_OBJECTS objects
+    cmds:
+      - task: generate
+      - task: fmt
+      - task: vet
+      - task: manifests
+      - task: envtest
+      - KUBEBUILDER_ASSETS="$({{ .Var "KUBEBUILDER" }} envtest use {{ .Var "ENVTEST_K8S_VERSION" }} --installed-only --print=path)" go test ./controllers/... -run='^$' -bench=. -benchtime={{ .Var "BENCH_OBJECTS" }}x
+{{- end }}
+
+  envtest:
+    desc: Install the envtest binaries of ENVTEST_K8S_VERSION if necessary, in the directory shared by the projects of the user
+    cmds:
+      - |
+        URL=https://storage.googleapis.com/kubebuilder-tools
+        if [ -n "{{ .Var "TOOL_MIRROR" }}" ]; then URL="{{ .Var "TOOL_MIRROR" }}/kubebuilder-tools"; fi
+        SHA256="{{ .Var "ENVTEST_TOOLS_SHA256" }}"
+        ARCHIVE="{{ .Var "ENVTEST_TOOLS_ARCHIVE" }}"
+        {{ .Var "KUBEBUILDER" }} envtest install {{ .Var "ENVTEST_K8S_VERSION" }} --remote-url="$URL" ${SHA256:+--sha256="$SHA256"} ${ARCHIVE:+--archive="$ARCHIVE"}
+
+  manager:
+    desc: Build manager binary
+    cmds:
+      - task: generate
+      - task: fmt
+      - task: vet
+      - go build -o bin/manager main.go
+{{- if .AggregatedAPIServer }}
+
+  run:
+    desc: Run the API server locally, delegating the authentication and the authorization of the requests to the configured Kubernetes cluster in ~/.kube/config
+    cmds:
+      - task: generate
+      - task: fmt
+      - task: vet
+      - task: manifests
+      - go run ./main.go --kubeconfig={{ .Var "KUBECONFIG" }} --authentication-kubeconfig={{ .Var "KUBECONFIG" }} --authorization-kubeconfig={{ .Var "KUBECONFIG" }}
+
+  deploy:
+    desc: Deploy the API server in the configured Kubernetes cluster in ~/.kube/config and register its APIs
+    cmds:
+      - task: manifests
+      - task: kustomize
+      - cd config/manager && {{ .Var "KUSTOMIZE" }} edit set image controller={{ .Var "IMG" }}
+      - '{{ .Var "KUSTOMIZE" }} build config/default | kubectl apply -f -'
+      - '{{ .Var "KUSTOMIZE" }} build config/apiservice | kubectl apply -f -'
+
+  undeploy:
+    desc: UnDeploy the API server from the configured Kubernetes cluster in ~/.kube/config
+    cmds:
+      - '{{ .Var "KUSTOMIZE" }} build config/apiservice | kubectl delete -f -'
+      - '{{ .Var "KUSTOMIZE" }} build config/default | kubectl delete -f -'
+{{- else }}
+
+  run:
+    desc: Run against the configured Kubernetes cluster in ~/.kube/config
+    cmds:
+      - task: generate
+      - task: fmt
+      - task: vet
+      - task: manifests
+      - go run ./main.go
+
+  # The API server of a remote cluster can not reach the webhook server of the local manager.
+  run-remote:
+    desc: Run against the configured Kubernetes cluster in ~/.kube/config with the webhooks disabled
+    cmds:
+      - task: generate
+      - task: fmt
+      - task: vet
+      - task: manifests
+      - task: install
+      - ENABLE_WEBHOOKS=false go run ./main.go
+
+  install:
+    desc: Install CRDs into a cluster
+    cmds:
+      - task: manifests
+      - task: kustomize
+      - '{{ .Var "KUSTOMIZE" }} build config/crd | kubectl apply $(go run ./hack/crdlint --dir=config/crd/bases --server-side-apply={{ .Var "SERVER_SIDE_APPLY" }} --apply-flags) -f -'
+
+  # Server-side apply is not subject to the size limit of the last-applied-configuration annotation, e.g. to install
+  # the CRDs apart from the manager.
+  install-crds:
+    desc: Build the CRDs in bin/crds.yaml and install them into a cluster with server-side apply
+    cmds:
+      - task: manifests
+      - task: kustomize
+      - mkdir -p bin
+      - '{{ .Var "KUSTOMIZE" }} build config/crd > bin/crds.yaml'
+      - kubectl apply --server-side --force-conflicts -f bin/crds.yaml
+
+  uninstall:
+    desc: Uninstall CRDs from a cluster
+    cmds:
+      - task: manifests
+      - task: kustomize
+      - '{{ .Var "KUSTOMIZE" }} build config/crd | kubectl delete -f -'
+
+  deploy:
+    desc: Deploy controller in the configured Kubernetes cluster in ~/.kube/config
+    cmds:
+      - task: manifests
+      - task: kustomize
+      - cd config/manager && {{ .Var "KUSTOMIZE" }} edit set image controller={{ .Var "IMG" }}
+      - '{{ .Var "KUSTOMIZE" }} build config/default | kubectl apply $(go run ./hack/crdlint --dir=config/crd/bases --server-side-apply={{ .Var "SERVER_SIDE_APPLY" }} --apply-flags) -f -'
+
+  undeploy:
+    desc: UnDeploy controller from the configured Kubernetes cluster in ~/.kube/config
+    cmds:
+      - '{{ .Var "KUSTOMIZE" }} build config/default | kubectl delete -f -'
+
+  # The cluster is e.g. a kind cluster IMG was loaded into. The samples are created before the upgrade, and must still
+  # be reconciled and keep their specs after it.
+  test-upgrade:
+    desc: Test the upgrade from the previous release, UPGRADE_FROM, to IMG in the configured Kubernetes cluster
+    cmds:
+      - task: manifests
+      - task: kustomize
+      - cd config/manager && {{ .Var "KUSTOMIZE" }} edit set image controller={{ .Var "IMG" }}
+      - mkdir -p bin && {{ .Var "KUSTOMIZE" }} build config/default > bin/upgrade-to.yaml
+      - go run ./hack/upgradetest --from={{ .Var "UPGRADE_FROM" }} --to=bin/upgrade-to.yaml --apply="kubectl apply $(go run ./hack/crdlint --dir=config/crd/bases --server-side-apply={{ .Var "SERVER_SIDE_APPLY" }} --apply-flags)"
+{{- range .Overlays }}
+
+  # The kustomize overlay of config/overlays/{{ . }} sets the image tag, the number of replicas and the log level
+  # of the manager in the environment.
+  deploy-{{ . }}:
+    desc: Deploy controller with the overlay of the {{ . }} environment
+    cmds:
+      - task: manifests
+      - task: kustomize
+      - '{{ $.Var "KUSTOMIZE" }} build config/overlays/{{ . }} | kubectl apply $(go run ./hack/crdlint --dir=config/crd/bases --server-side-apply={{ $.Var "SERVER_SIDE_APPLY" }} --apply-flags) -f -'
+
+  undeploy-{{ . }}:
+    desc: UnDeploy controller deployed with the overlay of the {{ . }} environment
+    cmds:
+      - '{{ $.Var "KUSTOMIZE" }} build config/overlays/{{ . }} | kubectl delete -f -'
+{{- end }}
+{{- end }}
+{{- if .AggregatedAPIServer }}
+
+  # The generation is skipped when neither the Go files, the options nor the generated manifests changed since the
+  # previous one, run "task manifests FORCE=1" to run it anyway.
+  manifests:
+    desc: Generate the RBAC manifests
+    silent: true
+    vars:
+      MANIFESTS_HASH_OPTIONS: --file=bin/manifests.sha256 --options='{{ .ControllerToolsVersion }} rbac' --outputs=config/rbac/role.yaml
+    cmds:
+      - task: controller-gen
+      - |
+        if [ "{{ .Var "FORCE" }}" != "1" ] && go run ./hack/manifestshash {{ .Var "MANIFESTS_HASH_OPTIONS" }} --check 2>/dev/null; then
+          echo "Manifests are up to date, run \"task manifests FORCE=1\" to generate them anyway"
+        else
+          {{ .Var "CONTROLLER_GEN" }} rbac:roleName=manager-role paths="./..." &&
+          go run ./hack/manifestshash {{ .Var "MANIFESTS_HASH_OPTIONS" }}
+        fi
+{{- else }}
+
+  # The generation is skipped when neither the Go files, the options nor the generated manifests changed since the
+  # previous one, run "task manifests FORCE=1" to run it anyway.
+  manifests:
+    desc: Generate manifests e.g. CRD, RBAC etc.
+    silent: true
+    vars:
+      MANIFESTS_HASH_OPTIONS: --file=bin/manifests.sha256 --options='{{ .ControllerToolsVersion }} {{ .Var "CRD_OPTIONS" }}'
+    cmds:
+      - task: controller-gen
+      - |
+        if [ "{{ .Var "FORCE" }}" != "1" ] && go run ./hack/manifestshash {{ .Var "MANIFESTS_HASH_OPTIONS" }} --check 2>/dev/null; then
+          echo "Manifests are up to date, run \"task manifests FORCE=1\" to generate them anyway"
+        else
+          {{ .Var "CONTROLLER_GEN" }} {{ .Var "CRD_OPTIONS" }} rbac:roleName=manager-role webhook paths="./..." output:crd:artifacts:config=config/crd/bases &&
+          go run ./hack/crdlint --dir=config/crd/bases --server-side-apply={{ .Var "SERVER_SIDE_APPLY" }} &&
+          go run ./hack/manifestshash {{ .Var "MANIFESTS_HASH_OPTIONS" }}
+        fi
+
+  # It is also run by manifests, after generating the CRDs.
+  lint-crds:
+    desc: Check that the CRDs fit in the size limits of the API server and that their validation rules are unlikely to exceed the CEL cost budget
+    cmds:
+      - go run ./hack/crdlint --dir=config/crd/bases --server-side-apply={{ .Var "SERVER_SIDE_APPLY" }}
+
+  # It is run on the pull requests by .github/workflows/crd-compat.yml to catch breaking changes to served versions.
+  verify-crd-compat:
+    desc: Verify that the CRDs are backwards compatible with the ones in CRD_COMPAT_BASE_REF
+    cmds:
+      - task: manifests
+      - go run ./hack/crdcompat --base-ref={{ .Var "CRD_COMPAT_BASE_REF" }} --dir=config/crd/bases
+
+  # RBAC_AUDIT_LOG is an audit log of the API server recorded while testing the manager. Set
+  # RBAC_AUDIT_OPTIONS=--strict to fail if a rule is unused.
+  rbac-audit:
+    desc: Report the rules of the manager role, generated from the +kubebuilder:rbac markers, that the manager never used in RBAC_AUDIT_LOG, and the requests it was denied
+    cmds:
+      - task: manifests
+      - go run ./hack/rbacaudit --dir=config/rbac --audit-log={{ .Var "RBAC_AUDIT_LOG" }} {{ .Var "RBAC_AUDIT_OPTIONS" }}
+
+  # Run "task api-docs API_DOCS_OPTIONS=--check" in CI to check that it is up to date.
+  api-docs:
+    desc: Generate the reference of the fields of the kinds documented in docs/api, created with "create api --api-docs", from the markers of their API types
+    cmds:
+      - go run ./hack/apidocs --dir=docs/api {{ .Var "API_DOCS_OPTIONS" }}
+
+  # Requires Go 1.21 or later and kwctl (experimental).
+  wasm-policies:
+    desc: Compile the wasm policies of policies/, scaffolded with "create webhook --wasm-policy", to Kubewarden policies annotated with their metadata in bin/policies
+    cmds:
+      - |
+        for policy in $(ls policies 2>/dev/null); do
+          echo "Building bin/policies/$policy.wasm" &&
+          GOOS=wasip1 GOARCH=wasm go build -o bin/policies/$policy.wasm ./policies/$policy &&
+          {{ .Var "KWCTL" }} annotate bin/policies/$policy.wasm --metadata-path policies/$policy/metadata.yml --output-path bin/policies/$policy.annotated.wasm || exit 1
+        done
+{{- end }}
+
+  # Run it in CI to catch the changes made to the project without the kubebuilder CLI.
+  validate-project:
+    desc: Validate the PROJECT file and check that it is in sync with the project, e.g. that every API is recorded in it
+    cmds:
+      - '{{ .Var "KUBEBUILDER" }} config validate --strict'
+
+  # Run it in CI: without their markers, "create api" and "create webhook" silently stop wiring the new APIs.
+  verify-scaffold:
+    desc: Check that the scaffold markers of main.go and of the test suites exist and that the APIs and webhooks of the PROJECT file are wired in main.go
+    cmds:
+      - '{{ .Var "KUBEBUILDER" }} config validate --scaffold --strict'
+
+  fmt:
+    desc: Run go fmt against code
+    cmds:
+      - go fmt ./...
+
+  vet:
+    desc: Run go vet against code
+    cmds:
+      - go vet ./...
+
+  generate:
+    desc: Generate code
+    cmds:
+      - task: controller-gen
+      - '{{ .Var "CONTROLLER_GEN" }} object:headerFile={{ printf "%q" .BoilerplatePath }} paths="./..."'
+
+  docker-build:
+    desc: Build the docker image
+    cmds:
+      - task: test
+      # Pass the proxies of the environment, if set, and the Go module configuration of the go commands to the
+      # builder, e.g. for the builds behind a corporate proxy
+      - |
+        docker build --build-arg HTTP_PROXY --build-arg HTTPS_PROXY --build-arg NO_PROXY \
+          --build-arg GOPROXY="$(go env GOPROXY)" --build-arg GOPRIVATE="$(go env GOPRIVATE)" \
+          --build-arg GONOSUMDB="$(go env GONOSUMDB)" \
+{{- if or .SBOM .ImageSigning }}
+          --build-arg VCS_REF=$(git rev-parse HEAD 2>/dev/null) \
+{{- end }}
+          -t {{ .Var "IMG" }} .
+
+  docker-push:
+    desc: Push the docker image
+    cmds:
+      - docker push {{ .Var "IMG" }}
+{{- if .SBOM }}
+
+  docker-sbom:
+    desc: Generate an SPDX SBOM of the docker image
+    cmds:
+      - task: syft
+      - '{{ .Var "SYFT" }} packages {{ .Var "IMG" }} -o spdx-json > {{ .Var "SBOM" }}'
+{{- end }}
+{{- if .ImageSigning }}
+
+  docker-sign:
+    desc: Sign the pushed docker image
+    cmds:
+      - task: cosign
+{{- if .SignsWithKey }}
+      - '{{ .Var "COSIGN" }} sign --key {{ .Var "COSIGN_KEY" }} {{ .Var "IMG" }}'
+{{- else }}
+      - COSIGN_EXPERIMENTAL=1 {{ .Var "COSIGN" }} sign {{ .Var "IMG" }}
+{{- end }}
+{{- end }}
+
+  controller-gen:
+    desc: Download controller-gen locally if necessary
+    cmds:
+      - task: go-get-tool
+        vars: {TOOL: '{{ .Var "CONTROLLER_GEN" }}', PACKAGE: 'sigs.k8s.io/controller-tools/cmd/controller-gen@{{ .ControllerToolsVersion }}'}
+
+  kustomize:
+    desc: Download kustomize locally if necessary
+    cmds:
+      - task: go-get-tool
+        vars: {TOOL: '{{ .Var "KUSTOMIZE" }}', PACKAGE: 'sigs.k8s.io/kustomize/kustomize/v3@{{ .KustomizeVersion }}'}
+{{- if .SBOM }}
+
+  # The syft release is downloaded, syft can not be built by the Go version of the project
+  syft:
+    desc: Download syft locally if necessary
+    status:
+      - test -f {{ .Var "SYFT" }}
+    cmds:
+      - echo "Downloading syft {{ .SyftVersion }}"
+      - curl -sSfL https://raw.githubusercontent.com/anchore/syft/{{ .SyftVersion }}/install.sh | sh -s -- -b {{ .Var "PROJECT_DIR" }}/bin {{ .SyftVersion }}
+{{- end }}
+{{- if .ImageSigning }}
+
+  # The cosign release is downloaded, cosign can not be built by the Go version of the project
+  cosign:
+    desc: Download cosign locally if necessary
+    status:
+      - test -f {{ .Var "COSIGN" }}
+    cmds:
+      - echo "Downloading cosign {{ .CosignVersion }}"
+      - mkdir -p {{ .Var "PROJECT_DIR" }}/bin
+      - curl -sSfL -o {{ .Var "COSIGN" }} https://github.com/sigstore/cosign/releases/download/{{ .CosignVersion }}/cosign-$(go env GOOS)-$(go env GOARCH)
+      - chmod +x {{ .Var "COSIGN" }}
+{{- end }}
+
+  # go-get-tool will 'go get' any package PACKAGE and install it to TOOL, once per tool.
+  go-get-tool:
+    internal: true
+    run: when_changed
+    silent: true
+    status:
+      - test -f {{ .Var "TOOL" }}
+    cmds:
+      - |
+        set -e
+        TMP_DIR=$(mktemp -d)
+        cd $TMP_DIR
+        go mod init tmp
+        echo "Downloading {{ .Var "PACKAGE" }}"
+        GOBIN={{ .Var "PROJECT_DIR" }}/bin GOPROXY={{ .Var "TOOL_GOPROXY" }} GOSUMDB={{ .Var "TOOL_GOSUMDB" }} go get {{ .Var "PACKAGE" }}
+        rm -rf $TMP_DIR
+`
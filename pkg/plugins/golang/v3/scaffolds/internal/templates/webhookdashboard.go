@@ -0,0 +1,139 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package templates
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+var _ file.Template = &WebhookDashboard{}
+
+// WebhookDashboard scaffolds a Grafana dashboard of the metrics of the instrumented webhooks
+type WebhookDashboard struct {
+	file.TemplateMixin
+	file.ProjectNameMixin
+}
+
+// SetTemplateDefaults implements file.Template
+func (f *WebhookDashboard) SetTemplateDefaults() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("grafana", "webhook-metrics.json")
+	}
+
+	f.TemplateBody = webhookDashboardTemplate
+
+	// The dashboard shows the metrics of all the instrumented webhooks
+	f.IfExistsAction = file.Skip
+
+	return nil
+}
+
+//nolint:lll
+const webhookDashboardTemplate = `{
+  "__inputs": [
+    {
+      "name": "DS_PROMETHEUS",
+      "label": "Prometheus",
+      "type": "datasource",
+      "pluginId": "prometheus",
+      "pluginName": "Prometheus"
+    }
+  ],
+  "panels": [
+    {
+      "datasource": "${DS_PROMETHEUS}",
+      "fieldConfig": {"defaults": {"unit": "s"}, "overrides": []},
+      "gridPos": {"h": 8, "w": 12, "x": 0, "y": 0},
+      "id": 1,
+      "targets": [
+        {
+          "expr": "histogram_quantile(0.99, sum(rate(webhook_admission_duration_seconds_bucket{job=\"$job\"}[5m])) by (webhook, operation, le))",
+          "legendFormat": "{{ "{{webhook}} {{operation}}" }}",
+          "refId": "A"
+        }
+      ],
+      "title": "Admission Latency (p99)",
+      "type": "timeseries"
+    },
+    {
+      "datasource": "${DS_PROMETHEUS}",
+      "fieldConfig": {"defaults": {"unit": "reqps"}, "overrides": []},
+      "gridPos": {"h": 8, "w": 12, "x": 12, "y": 0},
+      "id": 2,
+      "targets": [
+        {
+          "expr": "sum(rate(webhook_admission_duration_seconds_count{job=\"$job\"}[5m])) by (webhook, result)",
+          "legendFormat": "{{ "{{webhook}} {{result}}" }}",
+          "refId": "A"
+        }
+      ],
+      "title": "Admission Requests by Result",
+      "type": "timeseries"
+    },
+    {
+      "datasource": "${DS_PROMETHEUS}",
+      "fieldConfig": {"defaults": {"unit": "short"}, "overrides": []},
+      "gridPos": {"h": 8, "w": 12, "x": 0, "y": 8},
+      "id": 3,
+      "targets": [
+        {
+          "expr": "sum(webhook_admission_requests_in_flight{job=\"$job\"}) by (webhook)",
+          "legendFormat": "{{ "{{webhook}}" }}",
+          "refId": "A"
+        }
+      ],
+      "title": "Admission Requests in Flight",
+      "type": "timeseries"
+    },
+    {
+      "datasource": "${DS_PROMETHEUS}",
+      "fieldConfig": {"defaults": {"unit": "reqps"}, "overrides": []},
+      "gridPos": {"h": 8, "w": 12, "x": 12, "y": 8},
+      "id": 4,
+      "targets": [
+        {
+          "expr": "sum(rate(webhook_admission_duration_seconds_count{job=\"$job\", result=\"shed\"}[5m])) by (webhook)",
+          "legendFormat": "{{ "{{webhook}}" }}",
+          "refId": "A"
+        }
+      ],
+      "title": "Shed Admission Requests",
+      "type": "timeseries"
+    }
+  ],
+  "refresh": "30s",
+  "schemaVersion": 27,
+  "templating": {
+    "list": [
+      {
+        "datasource": "${DS_PROMETHEUS}",
+        "definition": "label_values(webhook_admission_requests_in_flight, job)",
+        "label": "Job",
+        "name": "job",
+        "query": "label_values(webhook_admission_requests_in_flight, job)",
+        "refresh": 2,
+        "type": "query"
+      }
+    ]
+  },
+  "time": {"from": "now-1h", "to": "now"},
+  "title": "{{ .ProjectName }} Webhooks",
+  "uid": "{{ .ProjectName }}-webhooks"
+}
+`
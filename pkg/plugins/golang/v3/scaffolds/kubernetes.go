@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/api"
+)
+
+// KubernetesProfile holds the defaults of the scaffolded manifests and tests selected by the minimum Kubernetes
+// version of a project. The projects that do not set it get the defaults of the current versions.
+type KubernetesProfile struct {
+	// CRDVersion and WebhookVersion are the default versions of the CRDs and of the webhook configurations
+	CRDVersion, WebhookVersion string
+	// AdmissionReviewVersions are the AdmissionReview versions accepted by the webhooks
+	AdmissionReviewVersions string
+	// PDBVersion is the API version of the PodDisruptionBudgets
+	PDBVersion string
+	// HPAVersion is the API version of the HorizontalPodAutoscalers
+	HPAVersion string
+	// SeccompAnnotation sets the seccomp profile of the pods with the annotation read by the clusters older than
+	// 1.19 too, which ignore the seccompProfile field
+	SeccompAnnotation bool
+	// PodSecurityPolicy scaffolds a component granting a PodSecurityPolicy to the manager, for the clusters older
+	// than 1.25 which enforce them instead of the Pod Security Admission labels
+	PodSecurityPolicy bool
+	// CertManagerVersion is the API version of the cert-manager issuer and certificate
+	CertManagerVersion string
+	// EnvtestK8sVersion is the Kubernetes version, or version selector, of the envtest binaries run by the tests
+	EnvtestK8sVersion string
+}
+
+// SupportsPreV1Kubernetes returns true if the minimum Kubernetes version of the project is older than 1.16, whose
+// clusters serve neither the v1 CRDs nor the v1 webhook configurations, and only send v1beta1 AdmissionReviews
+func SupportsPreV1Kubernetes(cfg *config.Config) bool {
+	return cfg.SupportsKubernetesBefore(1, 16)
+}
+
+// KubernetesProfileFor returns the profile selected by the minimum Kubernetes version of cfg
+func KubernetesProfileFor(cfg *config.Config) KubernetesProfile {
+	profile := KubernetesProfile{
+		CRDVersion:              "v1",
+		WebhookVersion:          "v1",
+		AdmissionReviewVersions: api.DefaultAdmissionReviewVersions,
+		PDBVersion:              "policy/v1beta1",
+		HPAVersion:              "autoscaling/v2beta2",
+		CertManagerVersion:      "cert-manager.io/v1",
+		EnvtestK8sVersion:       EnvtestK8sVersion,
+	}
+	major, minor, err := config.ParseKubernetesVersion(cfg.MinKubernetesVersion)
+	if cfg.MinKubernetesVersion == "" || err != nil {
+		return profile
+	}
+
+	// The tests run against the oldest supported version
+	profile.EnvtestK8sVersion = fmt.Sprintf("%d.%d.x", major, minor)
+	if SupportsPreV1Kubernetes(cfg) {
+		profile.CRDVersion = "v1beta1"
+		profile.WebhookVersion = "v1beta1"
+		// cert-manager only serves its v1 API from v1.0, whose v1 CRDs require 1.16
+		profile.CertManagerVersion = "cert-manager.io/v1alpha2"
+	} else {
+		profile.AdmissionReviewVersions = "v1"
+	}
+	if !cfg.SupportsKubernetesBefore(1, 21) {
+		profile.PDBVersion = "policy/v1"
+	}
+	if !cfg.SupportsKubernetesBefore(1, 23) {
+		profile.HPAVersion = "autoscaling/v2"
+	}
+	profile.SeccompAnnotation = cfg.SupportsKubernetesBefore(1, 19)
+	profile.PodSecurityPolicy = cfg.SupportsKubernetesBefore(1, 25)
+	return profile
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+func TestKubernetesProfileFor(t *testing.T) {
+	current := KubernetesProfile{
+		CRDVersion:              "v1",
+		WebhookVersion:          "v1",
+		AdmissionReviewVersions: "v1,v1beta1",
+		PDBVersion:              "policy/v1beta1",
+		HPAVersion:              "autoscaling/v2beta2",
+		CertManagerVersion:      "cert-manager.io/v1",
+		EnvtestK8sVersion:       EnvtestK8sVersion,
+	}
+	v1beta1 := KubernetesProfile{
+		CRDVersion:              "v1beta1",
+		WebhookVersion:          "v1beta1",
+		AdmissionReviewVersions: "v1,v1beta1",
+		PDBVersion:              "policy/v1beta1",
+		HPAVersion:              "autoscaling/v2beta2",
+		SeccompAnnotation:       true,
+		PodSecurityPolicy:       true,
+		CertManagerVersion:      "cert-manager.io/v1alpha2",
+	}
+	v1 := KubernetesProfile{
+		CRDVersion:              "v1",
+		WebhookVersion:          "v1",
+		AdmissionReviewVersions: "v1",
+		PDBVersion:              "policy/v1beta1",
+		HPAVersion:              "autoscaling/v2beta2",
+		SeccompAnnotation:       true,
+		PodSecurityPolicy:       true,
+		CertManagerVersion:      "cert-manager.io/v1",
+	}
+	// with returns profile for the minimum version selected by envtest, changed by change
+	with := func(profile KubernetesProfile, envtest string, change func(*KubernetesProfile)) KubernetesProfile {
+		profile.EnvtestK8sVersion = envtest
+		if change != nil {
+			change(&profile)
+		}
+		return profile
+	}
+
+	tests := []struct {
+		minVersion string
+		expected   KubernetesProfile
+	}{
+		{"", current},
+		{"latest", current},
+		{"1.15", with(v1beta1, "1.15.x", nil)},
+		{"v1.15.3", with(v1beta1, "1.15.x", nil)},
+		{"1.16", with(v1, "1.16.x", nil)},
+		{"1.18", with(v1, "1.18.x", nil)},
+		{"1.19", with(v1, "1.19.x", func(p *KubernetesProfile) {
+			p.SeccompAnnotation = false
+		})},
+		{"1.20", with(v1, "1.20.x", func(p *KubernetesProfile) {
+			p.SeccompAnnotation = false
+		})},
+		{"1.21", with(v1, "1.21.x", func(p *KubernetesProfile) {
+			p.SeccompAnnotation = false
+			p.PDBVersion = "policy/v1"
+		})},
+		{"1.22", with(v1, "1.22.x", func(p *KubernetesProfile) {
+			p.SeccompAnnotation = false
+			p.PDBVersion = "policy/v1"
+		})},
+		{"1.23", with(v1, "1.23.x", func(p *KubernetesProfile) {
+			p.SeccompAnnotation = false
+			p.PDBVersion = "policy/v1"
+			p.HPAVersion = "autoscaling/v2"
+		})},
+		{"1.24", with(v1, "1.24.x", func(p *KubernetesProfile) {
+			p.SeccompAnnotation = false
+			p.PDBVersion = "policy/v1"
+			p.HPAVersion = "autoscaling/v2"
+		})},
+		{"1.25", with(v1, "1.25.x", func(p *KubernetesProfile) {
+			p.SeccompAnnotation = false
+			p.PDBVersion = "policy/v1"
+			p.HPAVersion = "autoscaling/v2"
+			p.PodSecurityPolicy = false
+		})},
+	}
+
+	for _, test := range tests {
+		profile := KubernetesProfileFor(&config.Config{MinKubernetesVersion: test.minVersion})
+		if profile != test.expected {
+			t.Errorf("%q: expected the profile %+v, got %+v", test.minVersion, test.expected, profile)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+)
+
+// addComponent enables the component of config/components/<name> in the kustomization of path, adding it with its
+// comment at the end of the list of components unless it is already listed, uncommenting it if commented out.
+func addComponent(path, name, comment string) error {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	component := "- ../components/" + name
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case component:
+			return nil
+		case "#" + component:
+			lines[i] = strings.Replace(line, "#"+component, component, 1)
+			fmt.Printf("Enabled the %s component in %s\n", name, path)
+			return journal.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644) //nolint:gosec
+		}
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "components:" {
+			continue
+		}
+		// The list of components ends with the first line that is neither an item nor a comment
+		end := i + 1
+		for end < len(lines) && (strings.HasPrefix(lines[end], "-") || strings.HasPrefix(lines[end], "#")) {
+			end++
+		}
+		lines = append(lines[:end], append([]string{comment, component}, lines[end:]...)...)
+		fmt.Printf("Enabled the %s component in %s\n", name, path)
+		return journal.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644) //nolint:gosec
+	}
+	fmt.Printf("Unable to find the components of %s, add %q to them to enable the %s component\n",
+		path, component, name)
+	return nil
+}
+
+// addResource adds the resource to the resources of the kustomization of path, at the end of their list unless it
+// is already listed.
+func addResource(path, resource string) error {
+	content, err := ioutil.ReadFile(path) //nolint:gosec
+	if err != nil {
+		return err
+	}
+	item := "- " + resource
+	lines := strings.Split(string(content), "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) == item {
+			return nil
+		}
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "resources:" {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && (strings.HasPrefix(lines[end], "-") || strings.HasPrefix(lines[end], "#")) {
+			end++
+		}
+		lines = append(lines[:end], append([]string{item}, lines[end:]...)...)
+		return journal.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644) //nolint:gosec
+	}
+	fmt.Printf("Unable to find the resources of %s, add %q to them\n", path, item)
+	return nil
+}
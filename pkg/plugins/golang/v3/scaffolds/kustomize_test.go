@@ -0,0 +1,98 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"strings"
+	"testing"
+)
+
+const defaultKustomization = `namePrefix: project-
+
+components:
+# [WEBHOOK] To enable webhook, uncomment the following line.
+#- ../components/webhook
+# [HA] To run several replicas of the manager, uncomment the following line.
+#- ../components/ha
+
+patchesStrategicMerge:
+- manager_auth_proxy_patch.yaml
+`
+
+func TestAddComponent(t *testing.T) {
+	path := writeTempFile(t, "kustomization.yaml", defaultKustomization)
+
+	if err := addComponent(path, "defaults", "# [DEFAULTS] The defaults."); err != nil {
+		t.Fatal(err)
+	}
+	expected := `namePrefix: project-
+
+components:
+# [WEBHOOK] To enable webhook, uncomment the following line.
+#- ../components/webhook
+# [HA] To run several replicas of the manager, uncomment the following line.
+#- ../components/ha
+# [DEFAULTS] The defaults.
+- ../components/defaults
+
+patchesStrategicMerge:
+- manager_auth_proxy_patch.yaml
+`
+	if content := readFile(t, path); content != expected {
+		t.Errorf("expected the component to be added to the list, got:\n%s", content)
+	}
+
+	// The component is not added twice
+	if err := addComponent(path, "defaults", "# [DEFAULTS] The defaults."); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, path); content != expected {
+		t.Errorf("expected the component not to be added twice, got:\n%s", content)
+	}
+}
+
+func TestAddCommentedComponent(t *testing.T) {
+	path := writeTempFile(t, "kustomization.yaml", defaultKustomization)
+
+	if err := addComponent(path, "ha", "# [HA] High availability."); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, path); content != strings.Replace(defaultKustomization, "#- ../components/ha",
+		"- ../components/ha", 1) {
+		t.Errorf("expected the component to be uncommented, got:\n%s", content)
+	}
+}
+
+func TestAddResource(t *testing.T) {
+	path := writeTempFile(t, "kustomization.yaml", "resources:\n- monitor.yaml\n")
+
+	if err := addResource(path, "crew_captain_slo.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	expected := "resources:\n- monitor.yaml\n- crew_captain_slo.yaml\n"
+	if content := readFile(t, path); content != expected {
+		t.Errorf("expected the resource to be added to the list, got:\n%s", content)
+	}
+
+	// The resource is not added twice
+	if err := addResource(path, "crew_captain_slo.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, path); content != expected {
+		t.Errorf("expected the resource not to be added twice, got:\n%s", content)
+	}
+}
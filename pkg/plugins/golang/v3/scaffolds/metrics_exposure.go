@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/components"
+)
+
+const (
+	// MetricsExposureIngress exposes the metrics endpoint of the manager with an Ingress
+	MetricsExposureIngress = "ingress"
+	// MetricsExposureHTTPRoute exposes the metrics endpoint of the manager with a Gateway API HTTPRoute
+	MetricsExposureHTTPRoute = "httproute"
+)
+
+// MetricsExposure exposes the metrics endpoint of the manager outside of the cluster, for the Prometheus
+// servers running outside of it
+type MetricsExposure struct {
+	// Kind is MetricsExposureIngress or MetricsExposureHTTPRoute, the endpoint is not exposed if empty.
+	Kind string
+	// Hostname is the host name of the endpoint.
+	Hostname string
+}
+
+// files returns the files of the kustomize component exposing the metrics endpoint
+func (e MetricsExposure) files() []file.Builder {
+	files := []file.Builder{
+		&components.MetricsExposureKustomization{HTTPRoute: e.Kind == MetricsExposureHTTPRoute},
+		&components.MetricsExposureKustomizeConfig{},
+		&components.MetricsReader{},
+	}
+	if e.Kind == MetricsExposureHTTPRoute {
+		return append(files, &components.MetricsHTTPRoute{Hostname: e.Hostname})
+	}
+	return append(files, &components.MetricsIngress{Hostname: e.Hostname})
+}
+
+// enableMetricsExposure adds the component exposing the metrics endpoint to the default kustomization
+func enableMetricsExposure() error {
+	return addComponent(filepath.Join("config", "default", "kustomization.yaml"), "metrics-exposure",
+		"# [METRICS-EXPOSURE] Exposes the metrics endpoint outside of the cluster, behind auth.")
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/overlays"
+)
+
+// environment is the configuration of the manager in an environment with a kustomize overlay
+type environment struct {
+	name     string
+	imageTag string
+	replicas int
+	logLevel string
+}
+
+// environments are the environments with a kustomize overlay in config/overlays
+var environments = []environment{
+	{name: "dev", imageTag: imageTag, replicas: 1, logLevel: "debug"},
+	{name: "staging", imageTag: "v0.1.0", replicas: 2, logLevel: "info"},
+	{name: "prod", imageTag: "v0.1.0", replicas: 3, logLevel: "info"},
+}
+
+// overlayFiles returns the files of the kustomize overlays of the environments, deploying the manager image
+// of the repository imageRepo
+func overlayFiles(imageRepo string) []file.Builder {
+	files := make([]file.Builder, 0, 2*len(environments))
+	for _, env := range environments {
+		files = append(files,
+			&overlays.Kustomization{Env: env.name, ImageName: imageRepo, ImageTag: env.imageTag},
+			&overlays.ManagerPatch{Env: env.name, Replicas: env.replicas, LogLevel: env.logLevel},
+		)
+	}
+	return files
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/api"
+)
+
+// RawExtensionField is a field of the spec holding arbitrary JSON objects, preserved as is by the API server
+type RawExtensionField = api.RawExtensionField
+
+const (
+	// rawExtensionObject is the type of the fields holding arbitrary JSON objects
+	rawExtensionObject = "object"
+	// rawExtensionResource is the type of the fields holding Kubernetes objects
+	rawExtensionResource = "resource"
+)
+
+var fieldNameRegexp = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// ParseRawExtensionFields returns the fields of the values of the --raw-extension-field flag, name[:type] where
+// the type is object, an arbitrary JSON object and the default, or resource, a Kubernetes object
+func ParseRawExtensionFields(values []string) ([]RawExtensionField, error) {
+	fields := make([]RawExtensionField, 0, len(values))
+	parsed := map[string]bool{}
+	for _, value := range values {
+		parts := strings.SplitN(value, ":", 2)
+		name, fieldType := parts[0], rawExtensionObject
+		if len(parts) == 2 {
+			fieldType = parts[1]
+		}
+		if !fieldNameRegexp.MatchString(name) {
+			return nil, fmt.Errorf("invalid --raw-extension-field %q, expected the JSON name of a field of the spec "+
+				"in lower camel case, e.g. values", value)
+		}
+		if fieldType != rawExtensionObject && fieldType != rawExtensionResource {
+			return nil, fmt.Errorf("invalid --raw-extension-field %q, the type must be %s or %s",
+				value, rawExtensionObject, rawExtensionResource)
+		}
+		// The spec of the kind is scaffolded with the foo field
+		if name == "foo" {
+			return nil, fmt.Errorf("invalid --raw-extension-field %q, the foo field is already scaffolded in the spec",
+				value)
+		}
+		if parsed[name] {
+			return nil, fmt.Errorf("raw extension field %q is set more than once", name)
+		}
+		parsed[name] = true
+		fields = append(fields, RawExtensionField{
+			Name:             strings.ToUpper(name[:1]) + name[1:],
+			JSONName:         name,
+			EmbeddedResource: fieldType == rawExtensionResource,
+		})
+	}
+	return fields, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"testing"
+)
+
+func TestParseRawExtensionFields(t *testing.T) {
+	fields, err := ParseRawExtensionFields([]string{"values", "template:resource", "settings:object"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []RawExtensionField{
+		{Name: "Values", JSONName: "values"},
+		{Name: "Template", JSONName: "template", EmbeddedResource: true},
+		{Name: "Settings", JSONName: "settings"},
+	}
+	if len(fields) != len(expected) {
+		t.Fatalf("expected %+v, got %+v", expected, fields)
+	}
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], fields[i])
+		}
+	}
+
+	for _, invalid := range [][]string{{"Values"}, {"values:map"}, {"foo"}, {"my-values"}, {"values", "values:resource"}} {
+		if _, err := ParseRawExtensionFields(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	modpath "golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+)
+
+const (
+	goModFile = "go.mod"
+
+	// gnosticModule is pinned to GnosticVersion in the aggregated API server projects
+	gnosticModule = "github.com/googleapis/gnostic"
+)
+
+// ModuleReplace is a replace directive of go.mod, e.g. replacing controller-runtime by the fork of a company
+type ModuleReplace struct {
+	// OldPath and OldVersion are the replaced module, every version of it if OldVersion is empty.
+	OldPath    string
+	OldVersion string
+	// NewPath and NewVersion are the replacement, a local directory if NewVersion is empty.
+	NewPath    string
+	NewVersion string
+}
+
+// ParseModuleReplace parses a replace directive written as the -replace flag of go mod edit, old[@v]=new[@v],
+// whose new version is required unless new is a local directory
+func ParseModuleReplace(value string) (ModuleReplace, error) {
+	i := strings.Index(value, "=")
+	if i < 0 {
+		return ModuleReplace{}, fmt.Errorf("replace (%s) is invalid: expected old[@v]=new[@v]", value)
+	}
+
+	r := ModuleReplace{}
+	r.OldPath, r.OldVersion = splitModuleVersion(strings.TrimSpace(value[:i]))
+	r.NewPath, r.NewVersion = splitModuleVersion(strings.TrimSpace(value[i+1:]))
+	if err := modpath.CheckImportPath(r.OldPath); err != nil {
+		return ModuleReplace{}, fmt.Errorf("replace (%s) is invalid: %v", value, err)
+	}
+	if r.OldVersion != "" && !semver.IsValid(r.OldVersion) {
+		return ModuleReplace{}, fmt.Errorf("replace (%s) is invalid: %s is not a semantic version",
+			value, r.OldVersion)
+	}
+
+	if modfile.IsDirectoryPath(r.NewPath) {
+		return r, nil
+	}
+	if err := modpath.CheckImportPath(r.NewPath); err != nil {
+		return ModuleReplace{}, fmt.Errorf("replace (%s) is invalid: %v", value, err)
+	}
+	if !semver.IsValid(r.NewVersion) {
+		return ModuleReplace{}, fmt.Errorf("replace (%s) is invalid: the replacement module requires a semantic "+
+			"version, e.g. %s@v0.7.0, unless it is a local directory starting with ./ or ../", value, r.NewPath)
+	}
+	return r, nil
+}
+
+// splitModuleVersion splits a module path suffixed with @version, a local directory having no version
+func splitModuleVersion(value string) (string, string) {
+	if i := strings.LastIndex(value, "@"); i >= 0 && !modfile.IsDirectoryPath(value) {
+		return value[:i], value[i+1:]
+	}
+	return value, ""
+}
+
+// String returns the replace directive written as the -replace flag of go mod edit, as recorded in the PROJECT
+// file
+func (r ModuleReplace) String() string {
+	s := r.OldPath
+	if r.OldVersion != "" {
+		s += "@" + r.OldVersion
+	}
+	s += "=" + r.NewPath
+	if r.NewVersion != "" {
+		s += "@" + r.NewVersion
+	}
+	return s
+}
+
+// GoModLine returns the replace directive as written in the replace block of go.mod
+func (r ModuleReplace) GoModLine() string {
+	s := modfile.AutoQuote(r.OldPath)
+	if r.OldVersion != "" {
+		s += " " + r.OldVersion
+	}
+	s += " => " + modfile.AutoQuote(r.NewPath)
+	if r.NewVersion != "" {
+		s += " " + r.NewVersion
+	}
+	return s
+}
+
+// Module returns the module replaced by the directive, old[@v]
+func (r ModuleReplace) Module() string {
+	if r.OldVersion == "" {
+		return r.OldPath
+	}
+	return r.OldPath + "@" + r.OldVersion
+}
+
+// DroppedBy returns whether the directive is removed by --drop-replace module, old[@v]: the directive of every
+// version of old if no version is given, or the one of old@v only
+func (r ModuleReplace) DroppedBy(module string) bool {
+	return module == r.Module() || module == r.OldPath
+}
+
+// goModReplaces returns the replace directives recorded in the PROJECT file as written in go.mod
+func goModReplaces(replaces []string) ([]string, error) {
+	lines := make([]string, 0, len(replaces))
+	for _, value := range replaces {
+		r, err := ParseModuleReplace(value)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the replace directives of the PROJECT file: %v", err)
+		}
+		lines = append(lines, r.GoModLine())
+	}
+	return lines, nil
+}
+
+// replacesModule returns whether the replace directives recorded in the PROJECT file, replaces, replace path
+func replacesModule(replaces []string, path string) bool {
+	for _, value := range replaces {
+		if r, err := ParseModuleReplace(value); err == nil && r.OldPath == path {
+			return true
+		}
+	}
+	return false
+}
+
+// applyModuleReplaces sets the replace directives of go.mod to the ones recorded in the PROJECT file, replaces,
+// dropping the ones of dropped, that were removed from it
+func applyModuleReplaces(replaces, dropped []string) error {
+	content, err := ioutil.ReadFile(goModFile)
+	if err != nil {
+		return err
+	}
+	f, err := modfile.Parse(goModFile, content, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range dropped {
+		r, err := ParseModuleReplace(value)
+		if err != nil {
+			return err
+		}
+		if err := f.DropReplace(r.OldPath, r.OldVersion); err != nil {
+			return err
+		}
+	}
+	for _, value := range replaces {
+		r, err := ParseModuleReplace(value)
+		if err != nil {
+			return err
+		}
+		if err := f.AddReplace(r.OldPath, r.OldVersion, r.NewPath, r.NewVersion); err != nil {
+			return err
+		}
+	}
+
+	f.Cleanup()
+	out, err := f.Format()
+	if err != nil {
+		return err
+	}
+	return journal.WriteFile(goModFile, out, 0644)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+)
+
+func TestParseModuleReplace(t *testing.T) {
+	for value, expected := range map[string]string{
+		"sigs.k8s.io/controller-runtime=example.com/controller-runtime@v0.7.0-1": "sigs.k8s.io/controller-runtime => " +
+			"example.com/controller-runtime v0.7.0-1",
+		"k8s.io/api@v0.19.2 = example.com/api@v0.19.2-1": "k8s.io/api v0.19.2 => example.com/api v0.19.2-1",
+		"sigs.k8s.io/controller-runtime=../controller-runtime": "sigs.k8s.io/controller-runtime => " +
+			"../controller-runtime",
+	} {
+		r, err := ParseModuleReplace(value)
+		if err != nil {
+			t.Errorf("%s: %v", value, err)
+			continue
+		}
+		if r.GoModLine() != expected {
+			t.Errorf("%s: expected %q, got %q", value, expected, r.GoModLine())
+		}
+		if reparsed, err := ParseModuleReplace(r.String()); err != nil || reparsed != r {
+			t.Errorf("%s: expected %s to be parsed again, got %+v (%v)", value, r, reparsed, err)
+		}
+	}
+
+	for _, value := range []string{
+		"sigs.k8s.io/controller-runtime",
+		"sigs.k8s.io/controller-runtime=example.com/controller-runtime",
+		"sigs.k8s.io/controller-runtime@latest=example.com/controller-runtime@v0.7.0",
+		"=example.com/controller-runtime@v0.7.0",
+	} {
+		if _, err := ParseModuleReplace(value); err == nil {
+			t.Errorf("expected %s to be rejected", value)
+		}
+	}
+}
+
+func TestApplyModuleReplaces(t *testing.T) {
+	dir := filepath.Dir(writeTempFile(t, goModFile, `module example.com/fleet
+
+go 1.15
+
+require sigs.k8s.io/controller-runtime v0.7.0
+
+replace k8s.io/api => example.com/api v0.19.2-1
+`))
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	if err := applyModuleReplaces([]string{
+		"sigs.k8s.io/controller-runtime=example.com/controller-runtime@v0.7.0-1",
+		"k8s.io/client-go@v0.19.2=../client-go",
+	}, []string{"k8s.io/api=example.com/api@v0.19.2-1"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `module example.com/fleet
+
+go 1.15
+
+require sigs.k8s.io/controller-runtime v0.7.0
+
+replace sigs.k8s.io/controller-runtime => example.com/controller-runtime v0.7.0-1
+
+replace k8s.io/client-go v0.19.2 => ../client-go
+`
+	if content := readFile(t, goModFile); content != expected {
+		t.Errorf("expected go.mod:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+func TestReplacesModule(t *testing.T) {
+	replaces := []string{
+		"sigs.k8s.io/controller-runtime=example.com/controller-runtime@v0.7.0-1",
+		"github.com/googleapis/gnostic@v0.5.1=github.com/googleapis/gnostic@v0.4.1",
+	}
+	if !replacesModule(replaces, gnosticModule) {
+		t.Errorf("expected %s to be replaced by %v", gnosticModule, replaces)
+	}
+	if replacesModule(replaces[:1], gnosticModule) {
+		t.Errorf("expected %s not to be replaced by %v", gnosticModule, replaces[:1])
+	}
+}
+
+func TestReplaceModules(t *testing.T) {
+	dir := filepath.Dir(writeTempFile(t, goModFile, `module example.com/fleet
+
+go 1.15
+
+require sigs.k8s.io/controller-runtime v0.7.0
+
+replace sigs.k8s.io/controller-runtime v0.7.0 => example.com/controller-runtime v0.7.0-1
+
+replace k8s.io/client-go v0.19.2 => ../client-go
+
+replace k8s.io/api v0.19.2 => example.com/api v0.19.2-1
+`))
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chdir(wd) }()
+
+	s := &editScaffolder{
+		config: &config.Config{Replace: []string{
+			"sigs.k8s.io/controller-runtime@v0.7.0=example.com/controller-runtime@v0.7.0-1",
+			"k8s.io/client-go@v0.19.2=../client-go",
+			"k8s.io/api@v0.19.2=example.com/api@v0.19.2-1",
+		}},
+		// The directive of every version is dropped without a version, and the one of the version only with it
+		replace: ReplaceOptions{Drop: []string{"sigs.k8s.io/controller-runtime", "k8s.io/api@v0.19.2"}},
+	}
+	if err := s.replaceModules(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"k8s.io/client-go@v0.19.2=../client-go"}; !reflect.DeepEqual(s.config.Replace, expected) {
+		t.Errorf("expected the replace directives %v, got %v", expected, s.config.Replace)
+	}
+	expected := `module example.com/fleet
+
+go 1.15
+
+require sigs.k8s.io/controller-runtime v0.7.0
+
+replace k8s.io/client-go v0.19.2 => ../client-go
+`
+	if content := readFile(t, goModFile); content != expected {
+		t.Errorf("expected go.mod:\n%s\ngot:\n%s", expected, content)
+	}
+
+	r, err := ParseModuleReplace("k8s.io/client-go@v0.19.2=../client-go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for module, dropped := range map[string]bool{
+		"k8s.io/client-go":         true,
+		"k8s.io/client-go@v0.19.2": true,
+		"k8s.io/client-go@v0.20.0": false,
+		"k8s.io/api":               false,
+	} {
+		if r.DroppedBy(module) != dropped {
+			t.Errorf("expected --drop-replace %s to drop %s: %t", module, r, dropped)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinResyncPeriod is the shortest period of the periodic reconciliations, whose load grows with their frequency
+const MinResyncPeriod = time.Minute
+
+// ParseResyncPeriod parses the value of the --resync-period flag, a duration of whole seconds, the precision of
+// the nextReconcileTime field of the status, of at least MinResyncPeriod, e.g. 12h
+func ParseResyncPeriod(value string) (time.Duration, error) {
+	period, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --resync-period %q: %v", value, err)
+	}
+	if period < MinResyncPeriod {
+		return 0, fmt.Errorf("invalid --resync-period %q, the period must be at least %s", value, MinResyncPeriod)
+	}
+	if period%time.Second != 0 {
+		return 0, fmt.Errorf("invalid --resync-period %q, the period must be a whole number of seconds", value)
+	}
+	return period, nil
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/controllers"
+)
+
+func TestParseResyncPeriod(t *testing.T) {
+	for value, expected := range map[string]string{
+		"1h":      "time.Hour",
+		"12h":     "12 * time.Hour",
+		"1h30m":   "90 * time.Minute",
+		"10m":     "10 * time.Minute",
+		"90s":     "90 * time.Second",
+		"1h0m30s": "3630 * time.Second",
+	} {
+		period, err := ParseResyncPeriod(value)
+		if err != nil {
+			t.Errorf("%s: %v", value, err)
+			continue
+		}
+		if expr := (&controllers.Controller{ResyncPeriod: period}).ResyncPeriodExpr(); expr != expected {
+			t.Errorf("%s: expected the expression %s, got %s", value, expected, expr)
+		}
+	}
+
+	for _, invalid := range []string{"", "1d", "-1h", "30s", "1m0.5s"} {
+		if _, err := ParseResyncPeriod(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+	if _, err := ParseResyncPeriod(MinResyncPeriod.String()); err != nil {
+		t.Errorf("expected the minimum period %s to be accepted: %v", MinResyncPeriod, err)
+	}
+}
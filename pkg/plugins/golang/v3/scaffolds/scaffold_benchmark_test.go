@@ -0,0 +1,115 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+)
+
+// inTempDir runs the benchmark in a new temporary directory, discarding the messages of the scaffolds
+func inTempDir(b *testing.B) {
+	dir, err := ioutil.TempDir("", "scaffold")
+	if err != nil {
+		b.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		b.Fatal(err)
+	}
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	b.Cleanup(func() {
+		os.Stdout = stdout
+		_ = devNull.Close()
+		_ = os.Chdir(wd)
+		_ = os.RemoveAll(dir)
+	})
+}
+
+func newBenchmarkConfig() *config.Config {
+	return &config.Config{
+		Version:     config.Version3Alpha,
+		Domain:      "testproject.org",
+		Repo:        "sigs.k8s.io/kubebuilder/testdata/benchmark",
+		ProjectName: "benchmark",
+	}
+}
+
+// initProject scaffolds a project in the current directory and returns its boilerplate
+func initProject(b *testing.B, cfg *config.Config) string {
+	s := NewInitScaffolder(cfg, InitOptions{
+		License:     "apache2",
+		Owner:       "The Kubernetes authors",
+		PodSecurity: PodSecurityRestricted,
+	}).(*initScaffolder)
+	if err := s.scaffold(); err != nil {
+		b.Fatal(err)
+	}
+	boilerplate, err := ioutil.ReadFile(s.boilerplatePath)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return string(boilerplate)
+}
+
+func BenchmarkInitScaffold(b *testing.B) {
+	inTempDir(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		dir := fmt.Sprintf("project%d", i)
+		if err := os.Mkdir(dir, 0750); err != nil {
+			b.Fatal(err)
+		}
+		if err := os.Chdir(dir); err != nil {
+			b.Fatal(err)
+		}
+		initProject(b, newBenchmarkConfig())
+		if err := os.Chdir(".."); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkAPIScaffold measures the scaffold of an API and its controller, as run for each API of a batch
+func BenchmarkAPIScaffold(b *testing.B) {
+	inTempDir(b)
+	cfg := newBenchmarkConfig()
+	boilerplate := initProject(b, cfg)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		opts := &resource.Options{Group: "crew", Version: "v1", Kind: fmt.Sprintf("Captain%d", i), Namespaced: true}
+		s := NewAPIScaffolder(cfg, boilerplate, opts.NewResource(cfg, true),
+			APIOptions{DoResource: true, DoController: true}, nil).(*apiScaffolder)
+		if err := s.scaffold(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates"
+)
+
+const (
+	// TaskRunnerMake defines the targets of the project in a Makefile run by make
+	TaskRunnerMake = templates.TaskRunnerMake
+	// TaskRunnerTask defines the targets of the project in a Taskfile.yaml run by go-task
+	TaskRunnerTask = templates.TaskRunnerTask
+	// TaskRunnerJust defines the targets of the project in a Justfile run by just
+	TaskRunnerJust = templates.TaskRunnerJust
+)
+
+// TaskRunnerFor returns the task runner of the project, make unless another one was selected by init
+func TaskRunnerFor(c *config.Config) string {
+	if c.TaskRunner != "" {
+		return c.TaskRunner
+	}
+	return TaskRunnerMake
+}
+
+// TaskRunnerPath returns the path of the file defining the targets of the project run by the task runner
+func TaskRunnerPath(c *config.Config) string {
+	switch TaskRunnerFor(c) {
+	case TaskRunnerTask:
+		return "Taskfile.yaml"
+	case TaskRunnerJust:
+		return "Justfile"
+	default:
+		return "Makefile"
+	}
+}
+
+// taskRunnerFile returns the file defining the targets of the project for its task runner. Every runner
+// defines the same targets from the options of the Makefile, so that the targets added to the Makefile
+// template must be added to the Taskfile and the Justfile templates as well.
+func taskRunnerFile(c *config.Config, makefile templates.Makefile) file.Builder {
+	switch TaskRunnerFor(c) {
+	case TaskRunnerTask:
+		return &templates.Taskfile{Makefile: makefile}
+	case TaskRunnerJust:
+		return &templates.Justfile{Makefile: makefile}
+	default:
+		return &makefile
+	}
+}
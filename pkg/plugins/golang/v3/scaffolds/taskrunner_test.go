@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates"
+)
+
+var (
+	makeTargetRegexp = regexp.MustCompile(`(?m)^([a-z][-a-z]*):`)
+	taskRegexp       = regexp.MustCompile(`(?m)^  ([a-z][-a-z]*):$`)
+	recipeRegexp     = regexp.MustCompile(`(?m)^([a-z][-a-z]*):( |$)`)
+)
+
+// render returns the content of the file scaffolded by the template
+func render(t *testing.T, f file.Template) string {
+	t.Helper()
+	if err := f.SetTemplateDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := template.New(f.GetPath()).Parse(f.GetBody())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	if err := tmpl.Execute(&out, f); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+// targets returns the sorted names of the targets matched by the regexp in content
+func targets(re *regexp.Regexp, content string, exclude ...string) []string {
+	var names []string
+	for _, match := range re.FindAllStringSubmatch(content, -1) {
+		excluded := false
+		for _, name := range exclude {
+			excluded = excluded || match[1] == name
+		}
+		if !excluded {
+			names = append(names, match[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestTaskRunnersDefineTheMakefileTargets(t *testing.T) {
+	for name, makefile := range map[string]templates.Makefile{
+		"default":               {},
+		"supply chain and envs": {SBOM: true, ImageSigning: ImageSigningKey, GoEnv: true, Overlays: []string{"dev"}},
+		"aggregated API server": {ImageSigning: ImageSigningKeyless, AggregatedAPIServer: true},
+	} {
+		mk := makefile
+		expected := targets(makeTargetRegexp, render(t, &mk))
+		// The targets of the overlays are defined by a pattern rule
+		for _, env := range makefile.Overlays {
+			expected = append(expected, "deploy-"+env, "undeploy-"+env)
+		}
+		sort.Strings(expected)
+		if len(expected) == 0 {
+			t.Fatalf("%s: expected the Makefile to define targets", name)
+		}
+
+		taskfile := render(t, &templates.Taskfile{Makefile: makefile})
+		var parsed struct {
+			Tasks map[string]interface{} `json:"tasks"`
+		}
+		if err := yaml.Unmarshal([]byte(taskfile), &parsed); err != nil {
+			t.Errorf("%s: expected the Taskfile to be valid YAML: %v", name, err)
+		} else if len(parsed.Tasks) != len(expected)+2 {
+			t.Errorf("%s: expected the Taskfile to define %d tasks, got %d", name, len(expected)+2, len(parsed.Tasks))
+		}
+		if actual := targets(taskRegexp, taskfile, "default", "go-get-tool"); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%s: expected the Taskfile to define the tasks %v, got %v", name, expected, actual)
+		}
+		if actual := targets(recipeRegexp, render(t, &templates.Justfile{Makefile: makefile})); !reflect.DeepEqual(
+			actual, expected) {
+			t.Errorf("%s: expected the Justfile to define the recipes %v, got %v", name, expected, actual)
+		}
+	}
+}
+
+func TestTaskRunnerPath(t *testing.T) {
+	for runner, expected := range map[string]string{
+		"":             "Makefile",
+		TaskRunnerTask: "Taskfile.yaml",
+		TaskRunnerJust: "Justfile",
+	} {
+		if actual := TaskRunnerPath(&config.Config{TaskRunner: runner}); actual != expected {
+			t.Errorf("%q: expected %s, got %s", runner, expected, actual)
+		}
+	}
+}
+
+func TestCRDCompatWorkflow(t *testing.T) {
+	for runner, expected := range map[string]string{
+		TaskRunnerMake: "make verify-crd-compat CRD_COMPAT_BASE_REF=",
+		TaskRunnerTask: "task verify-crd-compat CRD_COMPAT_BASE_REF=",
+		TaskRunnerJust: "just CRD_COMPAT_BASE_REF=${{ github.event.pull_request.base.sha }} verify-crd-compat",
+	} {
+		workflow := render(t, &templates.CRDCompatWorkflow{TaskRunner: runner})
+		var parsed struct {
+			Jobs map[string]struct {
+				Steps []struct {
+					Uses string `json:"uses"`
+					Run  string `json:"run"`
+				} `json:"steps"`
+			} `json:"jobs"`
+		}
+		if err := yaml.Unmarshal([]byte(workflow), &parsed); err != nil {
+			t.Fatalf("%s: expected the workflow to be valid YAML: %v", runner, err)
+		}
+		steps := parsed.Jobs["verify-crd-compat"].Steps
+		if len(steps) == 0 || !strings.HasPrefix(steps[len(steps)-1].Run, expected) {
+			t.Errorf("%s: expected the workflow to run %q, got %v", runner, expected, steps)
+		}
+		if runner != TaskRunnerMake && !strings.Contains(workflow, "setup-"+runner) {
+			t.Errorf("%s: expected the workflow to install %s", runner, runner)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+)
+
+// wasmBuildConstraint excludes a file from the wasm policies, compiled with GOOS=wasip1
+const wasmBuildConstraint = "//go:build !wasip1\n// +build !wasip1\n\n"
+
+// hasWasmPolicy returns true if a resource of the package of res is validated by a wasm policy
+func hasWasmPolicy(c *config.Config, res *resource.Resource) bool {
+	for _, r := range c.Resources {
+		if r.Group == res.Group && r.Version == res.Version && r.Webhooks != nil && r.Webhooks.WasmPolicy {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeFromWasm adds a build constraint excluding them from wasm to the files of dir importing controller-runtime
+// or its webhook packages, which do not compile with GOOS=wasip1. The test files are not compiled by the policies.
+func excludeFromWasm(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		content, err := ioutil.ReadFile(path) //nolint:gosec
+		if err != nil {
+			return err
+		}
+		if bytes.HasPrefix(content, []byte("//go:build")) {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), path, content, parser.ImportsOnly)
+		if err != nil {
+			return err
+		}
+		excluded := false
+		for _, spec := range f.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				return err
+			}
+			if importPath == "sigs.k8s.io/controller-runtime" ||
+				strings.HasPrefix(importPath, "sigs.k8s.io/controller-runtime/pkg/webhook") ||
+				strings.HasSuffix(importPath, "/internal/webhookmetrics") {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			continue
+		}
+
+		// The build constraint precedes the boilerplate, as only line comments can precede it
+		if err := journal.WriteFile(path, append([]byte(wasmBuildConstraint), content...), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
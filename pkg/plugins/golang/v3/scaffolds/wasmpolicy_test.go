@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestExcludeFromWasm(t *testing.T) {
+	webhook := writeTempFile(t, "frigate_webhook.go", `/*
+Boilerplate.
+*/
+
+package v1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+`)
+	dir := filepath.Dir(webhook)
+	files := map[string]string{
+		"frigate_validation.go": `package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+`,
+		"groupversion_info.go": `// Package v1 contains API Schema definitions for the ship v1 API group
+package v1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+`,
+		"frigate_status_webhook.go": `// Package v1 contains API Schema definitions for the ship v1 API group
+package v1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+`,
+		"webhook_suite_test.go": `package v1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+`,
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The files are only updated once
+	for i := 0; i < 2; i++ {
+		if err := excludeFromWasm(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := `//go:build !wasip1
+// +build !wasip1
+
+/*
+Boilerplate.
+*/
+
+package v1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+`
+	if content := readFile(t, webhook); content != expected {
+		t.Errorf("expected %s:\n%s\ngot:\n%s", webhook, expected, content)
+	}
+	expected = `//go:build !wasip1
+// +build !wasip1
+
+// Package v1 contains API Schema definitions for the ship v1 API group
+package v1
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+`
+	if content := readFile(t, filepath.Join(dir, "frigate_status_webhook.go")); content != expected {
+		t.Errorf("expected frigate_status_webhook.go:\n%s\ngot:\n%s", expected, content)
+	}
+	for _, name := range []string{"frigate_validation.go", "groupversion_info.go", "webhook_suite_test.go"} {
+		if content := readFile(t, filepath.Join(dir, name)); content != files[name] {
+			t.Errorf("expected %s to be compiled to wasm, got:\n%s", name, content)
+		}
+	}
+}
@@ -18,13 +18,23 @@ package scaffolds
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/semver"
 
 	"sigs.k8s.io/kubebuilder/v2/pkg/model"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/resource"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/immutable"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/api"
-	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/kdefault"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/components"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/prometheus"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/webhook"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/cmdutil"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/machinery"
@@ -32,13 +42,80 @@ import (
 
 var _ cmdutil.Scaffolder = &webhookScaffolder{}
 
+// WebhookOptions are the options of the scaffolded defaulting and validating webhooks
+type WebhookOptions struct {
+	// FailurePolicy, SideEffects and MatchPolicy are set in the markers of the webhooks.
+	FailurePolicy string
+	SideEffects   string
+	MatchPolicy   string
+
+	// TimeoutSeconds and ReinvocationPolicy are not supported by the markers, they are set by a kustomize patch.
+	TimeoutSeconds     int
+	ReinvocationPolicy string
+
+	// ObjectSelector and NamespaceSelector are the label selectors of the objects and of the namespaces of the
+	// objects sent to the webhooks, all of them if empty. They are set by a kustomize patch too.
+	ObjectSelector    string
+	NamespaceSelector string
+
+	// ImmutableFields are the spec fields whose updates are rejected, by the validating webhook or by CEL
+	// validation rules of the CRD, as selected by Immutability.
+	ImmutableFields []string
+	Immutability    string
+
+	// Metrics instruments the defaulting and validating webhooks with Prometheus metrics, and MaxInFlight
+	// limits the number of admission requests they serve concurrently when it is not zero.
+	Metrics     bool
+	MaxInFlight int
+
+	// Subresources are the subresources, status or scale, whose updates are validated by webhooks of their own,
+	// as the validating webhook of the resource does not receive them.
+	Subresources []string
+
+	// WasmPolicy moves the validation methods of the validating webhook to a file of their own, compiled to a
+	// Kubewarden wasm policy too.
+	WasmPolicy bool
+}
+
+const (
+	// ImmutabilityWebhook rejects the updates of the immutable fields in the validating webhook
+	ImmutabilityWebhook = "webhook"
+	// ImmutabilityCEL rejects the updates of the immutable fields with CEL validation rules of the CRD
+	ImmutabilityCEL = "cel"
+)
+
+const (
+	// SubresourceStatus validates the updates of the status subresource
+	SubresourceStatus = api.SubresourceStatus
+	// SubresourceScale validates the updates of the scale subresource
+	SubresourceScale = api.SubresourceScale
+)
+
+// TypesPath returns the path of the types file of res
+func TypesPath(cfg *config.Config, res *resource.Resource) string {
+	path := filepath.Join("api", "%[version]", "%[kind]_types.go")
+	if cfg.MultiGroup {
+		if res.Group != "" {
+			path = filepath.Join("apis", "%[group-path]", "%[version]", "%[kind]_types.go")
+		} else {
+			path = filepath.Join("apis", "%[version]", "%[kind]_types.go")
+		}
+	}
+	return res.Replacer().Replace(path)
+}
+
 type webhookScaffolder struct {
 	config      *config.Config
 	boilerplate string
 	resource    *resource.Resource
 
 	// Webhook type options.
-	defaulting, validation, conversion, force bool
+	defaulting, validation, conversion, ownerLabels, force bool
+
+	options WebhookOptions
+
+	// update indicates that the webhooks are added to the already scaffolded webhook file of the resource
+	update bool
 }
 
 // NewWebhookScaffolder returns a new Scaffolder for v2 webhook creation operations
@@ -49,7 +126,10 @@ func NewWebhookScaffolder(
 	defaulting bool,
 	validation bool,
 	conversion bool,
+	ownerLabels bool,
 	force bool,
+	update bool,
+	options WebhookOptions,
 ) cmdutil.Scaffolder {
 	return &webhookScaffolder{
 		config:      config,
@@ -58,7 +138,10 @@ func NewWebhookScaffolder(
 		defaulting:  defaulting,
 		validation:  validation,
 		conversion:  conversion,
+		ownerLabels: ownerLabels,
 		force:       force,
+		update:      update,
+		options:     options,
 	}
 }
 
@@ -76,34 +159,265 @@ func (s *webhookScaffolder) newUniverse() *model.Universe {
 	)
 }
 
+// celControllerGenVersion is the first controller-gen version generating the CEL validation rules
+const celControllerGenVersion = "v0.9.0"
+
+var controllerGenVersionRegexp = regexp.MustCompile(`controller-tools/cmd/controller-gen@(v[0-9.]+)`)
+
+// controllerGenVersion returns the version of controller-gen installed by the Makefile, or the file of the task
+// runner of the project, if found
+func controllerGenVersion(c *config.Config) string {
+	content, err := ioutil.ReadFile(TaskRunnerPath(c))
+	if err != nil {
+		return ""
+	}
+	if match := controllerGenVersionRegexp.FindSubmatch(content); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
 func (s *webhookScaffolder) scaffold() error {
+	var immutableFields []immutable.Field
+	if len(s.options.ImmutableFields) != 0 {
+		typesPath := TypesPath(s.config, s.resource)
+		fields, err := immutable.SpecFields(typesPath, s.resource.Kind)
+		if err != nil {
+			return err
+		}
+		if immutableFields, err = immutable.Select(fields, s.options.ImmutableFields); err != nil {
+			return err
+		}
+		if s.options.Immutability == ImmutabilityCEL {
+			if err := immutable.AddRules(typesPath, immutableFields); err != nil {
+				return err
+			}
+			fmt.Printf("Marked %s immutable with CEL validation rules in %s\n",
+				strings.Join(s.options.ImmutableFields, ", "), typesPath)
+			version := controllerGenVersion(s.config)
+			if version != "" && semver.Compare(version, celControllerGenVersion) < 0 {
+				fmt.Printf("The CEL validation rules are generated by controller-gen %s or later, update the "+
+					"controller-gen %s installed by the %s to add them to the CRD\n",
+					celControllerGenVersion, version, TaskRunnerPath(s.config))
+			}
+			// The webhook does not check the fields validated by the CRD
+			immutableFields = nil
+			if !s.defaulting && !s.validation && !s.conversion && !s.ownerLabels && len(s.options.Subresources) == 0 {
+				return nil
+			}
+		}
+	}
+
+	// The validating webhook validates the unions of the spec on the clusters without CEL validation rules
+	var unions []immutable.Field
+	if s.validation {
+		typesPath := TypesPath(s.config, s.resource)
+		if _, err := os.Stat(typesPath); err == nil {
+			if unions, err = immutable.Unions(typesPath, s.resource.Kind); err != nil {
+				fmt.Printf("Unable to find the unions of the spec, validate them in the validating webhook: %v\n", err)
+			}
+		}
+	}
+
 	if s.conversion {
 		fmt.Println(`Webhook server has been set up for you.
 You need to implement the conversion.Hub and conversion.Convertible interfaces for your CRD types.`)
 	}
 
+	// The webhook test suite already registers the resource if it had defaulting or validating webhooks
+	hadAdmissionWebhooks := false
+	if existing := s.config.GetResource(s.resource.Data()); existing != nil && existing.Webhooks != nil {
+		hadAdmissionWebhooks = existing.Webhooks.Defaulting || existing.Webhooks.Validation
+	}
+
+	// The serving certificate of the webhooks is shared by all of them, its metrics are wired with the first ones
+	hadWebhooks := false
+	for _, res := range s.config.Resources {
+		if res.Webhooks != nil && !res.Webhooks.IsEmpty() {
+			hadWebhooks = true
+		}
+	}
+
 	s.config.UpdateResources(s.resource.Data())
 
-	if err := machinery.NewScaffold().Execute(
-		s.newUniverse(),
-		&api.Webhook{
+	// The webhook file and its wiring in main.go already exist when adding webhooks to a resource,
+	// only the missing webhooks are inserted in it.
+	var webhookFiles []file.Builder
+	profile := KubernetesProfileFor(s.config)
+	mainUpdater := &templates.MainUpdater{
+		WireOwnerLabelsWebhook:  s.ownerLabels,
+		WireSubresourceWebhooks: s.options.Subresources,
+		GroupRegistration:       s.config.GroupRegistration,
+	}
+	if s.update {
+		if s.defaulting || s.validation {
+			webhookFiles = append(webhookFiles, &api.WebhookUpdater{
+				WebhookVersion: s.resource.Webhooks.WebhookVersion,
+				Defaulting:     s.defaulting,
+				Validating:     s.validation,
+				FailurePolicy:  s.options.FailurePolicy,
+				SideEffects:    s.options.SideEffects,
+				MatchPolicy:    s.options.MatchPolicy,
+
+				ImmutableFields:         immutableFields,
+				Unions:                  unions,
+				WasmPolicy:              s.options.WasmPolicy,
+				AdmissionReviewVersions: profile.AdmissionReviewVersions,
+			})
+		}
+	} else if s.defaulting || s.validation || s.conversion {
+		webhookFiles = append(webhookFiles, &api.Webhook{
 			WebhookVersion: s.resource.Webhooks.WebhookVersion,
 			Defaulting:     s.defaulting,
 			Validating:     s.validation,
+			FailurePolicy:  s.options.FailurePolicy,
+			SideEffects:    s.options.SideEffects,
+			MatchPolicy:    s.options.MatchPolicy,
 			Force:          s.force,
-		},
-		&templates.MainUpdater{WireWebhook: true},
-		&kdefault.WebhookCAInjectionPatch{WebhookVersion: s.resource.Webhooks.WebhookVersion},
-		&kdefault.ManagerWebhookPatch{},
-		&webhook.Kustomization{WebhookVersion: s.resource.Webhooks.WebhookVersion, Force: s.force},
+
+			ImmutableFields:         immutableFields,
+			Unions:                  unions,
+			WasmPolicy:              s.options.WasmPolicy,
+			AdmissionReviewVersions: profile.AdmissionReviewVersions,
+			Metrics:                 s.options.Metrics,
+			MaxInFlight:             s.options.MaxInFlight,
+		})
+		mainUpdater.WireWebhook = true
+		if s.options.Metrics {
+			webhookFiles = append(webhookFiles,
+				&templates.WebhookMetrics{},
+				&templates.WebhookMetricsTest{},
+				&templates.WebhookDashboard{},
+			)
+		}
+	}
+	if s.validation && s.options.WasmPolicy {
+		webhookFiles = append(webhookFiles,
+			&api.WebhookValidation{
+				ImmutableFields: immutableFields,
+				Unions:          unions,
+				PolicyPath:      templates.WasmPolicyPath(s.resource),
+				Force:           s.force,
+			},
+			&templates.WasmPolicy{Force: s.force},
+			&templates.WasmPolicyMetadata{Force: s.force},
+		)
+	}
+	if s.ownerLabels {
+		webhookFiles = append(webhookFiles,
+			&api.OwnerLabelsWebhook{
+				WebhookVersion:          s.resource.Webhooks.WebhookVersion,
+				AdmissionReviewVersions: profile.AdmissionReviewVersions,
+				Force:                   s.force,
+			},
+			&templates.OwnerLabels{},
+			&templates.OwnerLabelsTest{},
+		)
+	}
+	for _, subresource := range s.options.Subresources {
+		webhookFiles = append(webhookFiles,
+			&api.SubresourceWebhook{
+				Subresource:             subresource,
+				WebhookVersion:          s.resource.Webhooks.WebhookVersion,
+				AdmissionReviewVersions: profile.AdmissionReviewVersions,
+				Force:                   s.force,
+			},
+			&api.SubresourceWebhookTest{Subresource: subresource, Force: s.force},
+		)
+	}
+	wireWebhooks := mainUpdater.WireWebhook || mainUpdater.WireOwnerLabelsWebhook ||
+		len(mainUpdater.WireSubresourceWebhooks) != 0
+	if wireWebhooks {
+		mainUpdater.WireCertMetrics = !hadWebhooks
+		webhookFiles = append(webhookFiles,
+			mainUpdater,
+			&templates.CertMetrics{},
+			&templates.CertMetricsTest{},
+			&prometheus.CertificateAlerts{},
+		)
+	}
+
+	// The options not supported by the markers are set by a patch of each webhook
+	var patches []string
+	objectSelector, err := ParseLabelSelector(s.options.ObjectSelector)
+	if err != nil {
+		return err
+	}
+	namespaceSelector, err := ParseLabelSelector(s.options.NamespaceSelector)
+	if err != nil {
+		return err
+	}
+	selected := objectSelector != nil || namespaceSelector != nil
+	if s.defaulting && (s.options.TimeoutSeconds != 0 || s.options.ReinvocationPolicy != "" || selected) {
+		webhookFiles = append(webhookFiles, &webhook.OptionsPatch{
+			WebhookVersion:     s.resource.Webhooks.WebhookVersion,
+			Mutating:           true,
+			TimeoutSeconds:     s.options.TimeoutSeconds,
+			ReinvocationPolicy: s.options.ReinvocationPolicy,
+			ObjectSelector:     objectSelector,
+			NamespaceSelector:  namespaceSelector,
+			Force:              s.force,
+		})
+		patches = append(patches, fmt.Sprintf("patches/mutating_in_%s.yaml", s.resource.Plural))
+	}
+	if s.validation && (s.options.TimeoutSeconds != 0 || selected) {
+		webhookFiles = append(webhookFiles, &webhook.OptionsPatch{
+			WebhookVersion:    s.resource.Webhooks.WebhookVersion,
+			TimeoutSeconds:    s.options.TimeoutSeconds,
+			ObjectSelector:    objectSelector,
+			NamespaceSelector: namespaceSelector,
+			Force:             s.force,
+		})
+		patches = append(patches, fmt.Sprintf("patches/validating_in_%s.yaml", s.resource.Plural))
+	}
+
+	vault := s.config.CertProvider == CertProviderVault
+	webhookFiles = append(webhookFiles,
+		&components.WebhookKustomization{},
+		&components.ManagerWebhookPatch{Vault: vault},
+		&webhook.Kustomization{WebhookVersion: s.resource.Webhooks.WebhookVersion, Patches: patches},
 		&webhook.KustomizeConfig{},
 		&webhook.Service{},
-	); err != nil {
+	)
+	if vault {
+		webhookFiles = append(webhookFiles,
+			&components.VaultKustomization{},
+			&components.VaultManagerPatch{},
+		)
+	} else {
+		webhookFiles = append(webhookFiles,
+			&components.CertManagerKustomization{},
+			&components.WebhookCAInjectionPatch{WebhookVersion: s.resource.Webhooks.WebhookVersion},
+		)
+	}
+
+	if err := machinery.NewScaffold().Execute(s.newUniverse(), webhookFiles...); err != nil {
 		return err
 	}
+	if wireWebhooks {
+		if err := addResource(filepath.Join("config", "prometheus", "kustomization.yaml"),
+			prometheus.CertificateAlertsFile); err != nil {
+			return fmt.Errorf("error adding the certificate alerts to the prometheus kustomization: %v", err)
+		}
+	}
+
+	// The conversion webhook is enabled once the kind has several versions
+	if s.conversion && len(otherVersions(s.config, s.resource)) != 0 {
+		if err := enableConversion(s.config, s.resource); err != nil {
+			return fmt.Errorf("error enabling the conversion webhook: %v", err)
+		}
+	}
+
+	// The files of the package of a resource validated by a wasm policy that import controller-runtime are not
+	// compiled to wasm, including the ones of the webhooks scaffolded after the policy
+	if hasWasmPolicy(s.config, s.resource) {
+		if err := excludeFromWasm(filepath.Dir(TypesPath(s.config, s.resource))); err != nil {
+			return fmt.Errorf("error excluding the webhook files from the wasm policy: %v", err)
+		}
+	}
 
 	// TODO: Add test suite for conversion webhook after #1664 has been merged & conversion tests supported in envtest.
-	if s.defaulting || s.validation {
+	if (s.defaulting || s.validation) && !hadAdmissionWebhooks {
 		if err := machinery.NewScaffold().Execute(
 			s.newUniverse(),
 			&api.WebhookSuite{},
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/webhook"
+)
+
+// LabelSelector is a label selector of the webhook configurations, parsed by ParseLabelSelector
+type LabelSelector = webhook.LabelSelector
+
+// NamespaceNameLabel is the label of the namespaces holding their name, set by Kubernetes 1.21 or later
+const NamespaceNameLabel = "kubernetes.io/metadata.name"
+
+var labelSetRequirementRegexp = regexp.MustCompile(`^(\S+) +(in|notin) +\((.*)\)$`)
+
+// DefaultWebhookNamespaceSelector returns the namespace selector of the defaulting and validating webhooks when
+// --namespace-selector is not provided, which skips the objects of kube-system and of the namespace of the
+// manager, so that the webhooks never block the rollout of the manager serving them
+func DefaultWebhookNamespaceSelector(c *config.Config) string {
+	return fmt.Sprintf("%s notin (kube-system,%s)", NamespaceNameLabel, c.GetNamespace())
+}
+
+// ParseLabelSelector parses a label selector such as app=fleet,tier!=test or env in (dev,test), nil if empty
+func ParseLabelSelector(selector string) (*LabelSelector, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+
+	parsed := &LabelSelector{}
+	for _, requirement := range labelRequirements(selector) {
+		if !labelRequirementRegexp.MatchString(requirement) {
+			return nil, fmt.Errorf("label selector (%s) is invalid: %q is not a requirement such as key=value, "+
+				"key!=value, key in (value1,value2) or !key", selector, requirement)
+		}
+
+		var expression webhook.LabelSelectorRequirement
+		switch {
+		case labelSetRequirementRegexp.MatchString(requirement):
+			match := labelSetRequirementRegexp.FindStringSubmatch(requirement)
+			expression = webhook.LabelSelectorRequirement{Key: match[1], Operator: "In"}
+			if match[2] == "notin" {
+				expression.Operator = "NotIn"
+			}
+			for _, value := range strings.Split(match[3], ",") {
+				expression.Values = append(expression.Values, strings.TrimSpace(value))
+			}
+		case strings.HasPrefix(requirement, "!"):
+			expression = webhook.LabelSelectorRequirement{Key: requirement[1:], Operator: "DoesNotExist"}
+		case strings.Contains(requirement, "!="):
+			kv := strings.SplitN(requirement, "!=", 2)
+			expression = webhook.LabelSelectorRequirement{Key: strings.TrimSpace(kv[0]), Operator: "NotIn",
+				Values: []string{strings.TrimSpace(kv[1])}}
+		case strings.Contains(requirement, "="):
+			kv := strings.SplitN(strings.Replace(requirement, "==", "=", 1), "=", 2)
+			parsed.MatchLabels = append(parsed.MatchLabels, webhook.LabelSelectorLabel{
+				Key: strings.TrimSpace(kv[0]), Value: strings.TrimSpace(kv[1])})
+			continue
+		default:
+			expression = webhook.LabelSelectorRequirement{Key: requirement, Operator: "Exists"}
+		}
+		parsed.MatchExpressions = append(parsed.MatchExpressions, expression)
+	}
+	return parsed, nil
+}
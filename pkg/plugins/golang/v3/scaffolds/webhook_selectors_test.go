@@ -0,0 +1,66 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffolds
+
+import (
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds/internal/templates/config/webhook"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	for selector, expected := range map[string]*LabelSelector{
+		"": nil,
+		"app=fleet,tier==prod": {MatchLabels: []webhook.LabelSelectorLabel{
+			{Key: "app", Value: "fleet"}, {Key: "tier", Value: "prod"}}},
+		"tier!=test, !skip-webhooks,example.com/managed": {MatchExpressions: []webhook.LabelSelectorRequirement{
+			{Key: "tier", Operator: "NotIn", Values: []string{"test"}},
+			{Key: "skip-webhooks", Operator: "DoesNotExist"},
+			{Key: "example.com/managed", Operator: "Exists"},
+		}},
+		"env in (dev, test),kubernetes.io/metadata.name notin (kube-system,fleet-system)": {
+			MatchExpressions: []webhook.LabelSelectorRequirement{
+				{Key: "env", Operator: "In", Values: []string{"dev", "test"}},
+				{Key: "kubernetes.io/metadata.name", Operator: "NotIn", Values: []string{"kube-system", "fleet-system"}},
+			}},
+	} {
+		actual, err := ParseLabelSelector(selector)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", selector, err)
+		} else if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%q: expected %+v, got %+v", selector, expected, actual)
+		}
+	}
+
+	for _, selector := range []string{"app=a b", "=fleet", "env in dev", "app=fleet,,tier=prod"} {
+		if _, err := ParseLabelSelector(selector); err == nil {
+			t.Errorf("%q: expected an error", selector)
+		}
+	}
+}
+
+func TestDefaultWebhookNamespaceSelector(t *testing.T) {
+	selector := DefaultWebhookNamespaceSelector(&config.Config{ProjectName: "fleet"})
+	if selector != "kubernetes.io/metadata.name notin (kube-system,fleet-system)" {
+		t.Errorf("expected kube-system and the namespace of the manager to be skipped, got %q", selector)
+	}
+	if _, err := ParseLabelSelector(selector); err != nil {
+		t.Error(err)
+	}
+}
@@ -20,7 +20,9 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/pflag"
 
@@ -29,7 +31,6 @@ import (
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugin"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/golang/v3/scaffolds"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/cmdutil"
-	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/util"
 )
 
 // defaultWebhookVersion is the default mutating/validating webhook config API version to scaffold.
@@ -40,14 +41,27 @@ type createWebhookSubcommand struct {
 	// For help text.
 	commandName string
 
-	resource   *resource.Options
+	resource *resource.Options
+	// webhookVersionFlag is checked to default the webhook version to v1beta1 for the clusters older than 1.16
+	webhookVersionFlag *pflag.Flag
+
 	defaulting bool
 	validation bool
 	conversion bool
+	// ownerLabels indicates that the webhook labeling the objects controlled by the resource should be scaffolded
+	ownerLabels bool
+
+	// options are the options of the defaulting and validating webhooks
+	options scaffolds.WebhookOptions
+	// namespaceSelectorFlag is checked to default the namespace selector of the webhooks
+	namespaceSelectorFlag *pflag.Flag
 
 	// force indicates that the resource should be created even if it already exists
 	force bool
 
+	// update indicates that webhooks are added to the already scaffolded webhook of the resource
+	update bool
+
 	// runMake indicates whether to run make or not after scaffolding webhooks
 	runMake bool
 }
@@ -67,8 +81,57 @@ validating and (or) conversion webhooks.
 
   # Create conversion webhook for CRD of group ship, version v1beta1 and kind Frigate.
   %s create webhook --group ship --version v1beta1 --kind Frigate --conversion
+
+  # Add a validating webhook to the already scaffolded webhooks of the same kind,
+  # keeping the existing defaulting webhook.
+  %s create webhook --group ship --version v1beta1 --kind Frigate --programmatic-validation
+
+  # Create a webhook stamping the app.kubernetes.io labels and a managed-by annotation
+  # on the objects controlled by the objects of kind Frigate.
+  %s create webhook --group ship --version v1beta1 --kind Frigate --owner-labels
+
+  # Create a defaulting webhook that does not block the requests when it fails or does
+  # not answer within 5 seconds.
+  %s create webhook --group ship --version v1beta1 --kind Frigate --defaulting \
+      --failure-policy ignore --timeout-seconds 5
+
+  # Create a validating webhook rejecting the updates of the spec fields class and crew.
+  %s create webhook --group ship --version v1beta1 --kind Frigate --programmatic-validation \
+      --immutable-fields class,crew
+
+  # Mark the spec field class immutable with a CEL validation rule of the CRD instead.
+  %s create webhook --group ship --version v1beta1 --kind Frigate --immutable-fields class \
+      --immutability cel
+
+  # Create a webhook validating the updates of the status subresource of the objects of
+  # kind Frigate, which the validating webhook of Frigate does not receive.
+  %s create webhook --group ship --version v1beta1 --kind Frigate --subresource status
+
+  # Create a defaulting webhook only receiving the objects labeled tier=production, of the
+  # namespaces labeled with a team, instead of the ones of the namespaces other than kube-system
+  # and the namespace of the manager
+  %s create webhook --group ship --version v1beta1 --kind Frigate --defaulting \
+      --object-selector tier=production --namespace-selector team
+
+  # Create defaulting and validating webhooks recording the latency and the result of the
+  # admission requests, and rejecting the requests over 50 concurrent ones.
+  %s create webhook --group ship --version v1beta1 --kind Frigate --defaulting \
+      --programmatic-validation --metrics --max-in-flight 50
+
+  # Create a validating webhook whose validation is also compiled to a Kubewarden wasm policy by
+  # "make wasm-policies", for the clusters disallowing custom admission webhooks (experimental).
+  %s create webhook --group ship --version v1beta1 --kind Frigate --programmatic-validation --wasm-policy
 `,
-		ctx.CommandName, ctx.CommandName)
+		ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName,
+		ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName, ctx.CommandName)
+	ctx.Docs = `Documentation of the webhooks:
+  --defaulting, --programmatic-validation   https://book.kubebuilder.io/reference/admission-webhook.html
+  --conversion                              https://book.kubebuilder.io/reference/webhook-overview.html
+  --metrics, --max-in-flight                https://book.kubebuilder.io/reference/webhook-metrics.html
+  --subresource                             https://book.kubebuilder.io/reference/webhook-for-subresources.html
+  --object-selector, --namespace-selector   https://book.kubebuilder.io/reference/webhook-selectors.html
+  --wasm-policy                             https://book.kubebuilder.io/reference/wasm-policies.html
+`
 
 	p.commandName = ctx.CommandName
 }
@@ -80,9 +143,11 @@ func (p *createWebhookSubcommand) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&p.resource.Kind, "kind", "", "resource Kind")
 	fs.StringVar(&p.resource.Plural, "resource", "", "resource Resource")
 	fs.StringVar(&p.resource.Webhooks.WebhookVersion, "webhook-version", defaultWebhookVersion,
-		"version of {Mutating,Validating}WebhookConfigurations to scaffold. Options: [v1, v1beta1]")
+		"version of {Mutating,Validating}WebhookConfigurations to scaffold. Options: [v1, v1beta1], "+
+			"defaults to v1beta1 if the minimum Kubernetes version of the project is older than 1.16")
+	p.webhookVersionFlag = fs.Lookup("webhook-version")
 
-	fs.BoolVar(&p.runMake, "make", true, "if true, run make after generating files")
+	fs.BoolVar(&p.runMake, "make", true, "if true, run make, or the task runner of the project, after generating files")
 	fs.BoolVar(&p.force, "force", false,
 		"attempt to create resource even if it already exists")
 
@@ -92,6 +157,50 @@ func (p *createWebhookSubcommand) BindFlags(fs *pflag.FlagSet) {
 		"if set, scaffold the validating webhook")
 	fs.BoolVar(&p.conversion, "conversion", false,
 		"if set, scaffold the conversion webhook")
+	fs.BoolVar(&p.ownerLabels, "owner-labels", false,
+		"if set, scaffold a webhook stamping the app.kubernetes.io labels and a managed-by annotation on the "+
+			"objects controlled by the resource")
+	fs.StringSliceVar(&p.options.Subresources, "subresource", nil,
+		"subresources whose updates are validated by a webhook of their own, which requires the subresource "+
+			"marker on the type of the resource. Options: [status, scale]")
+
+	fs.StringVar(&p.options.FailurePolicy, "failure-policy", "fail",
+		"how the API server handles the requests when the defaulting and validating webhooks fail or time out. "+
+			"Options: [fail, ignore]")
+	fs.StringVar(&p.options.SideEffects, "side-effects", "None",
+		"whether the defaulting and validating webhooks have side effects. Options: [None, NoneOnDryRun], "+
+			"and [Some, Unknown] for v1beta1 webhooks")
+	fs.StringVar(&p.options.MatchPolicy, "match-policy", "",
+		"whether the defaulting and validating webhooks receive the requests to the other versions of the "+
+			"resource. Options: [Exact, Equivalent], defaults to the API server default")
+	fs.IntVar(&p.options.TimeoutSeconds, "timeout-seconds", 0,
+		"time after which the API server applies the failure policy to the requests to the defaulting and "+
+			"validating webhooks, between 1 and 30. Defaults to the API server default (10 for v1 webhooks)")
+	fs.StringVar(&p.options.ReinvocationPolicy, "reinvocation-policy", "",
+		"whether the defaulting webhook is called again when another mutating webhook modifies the object. "+
+			"Options: [Never, IfNeeded], defaults to Never")
+	fs.StringVar(&p.options.ObjectSelector, "object-selector", "",
+		"label selector of the objects sent to the defaulting and validating webhooks, e.g. "+
+			"app.kubernetes.io/managed-by=fleet, all of them if empty")
+	fs.StringVar(&p.options.NamespaceSelector, "namespace-selector", "",
+		"label selector of the namespaces of the objects sent to the defaulting and validating webhooks, all of "+
+			"them if empty. Defaults to the namespaces other than kube-system and the namespace of the manager")
+	p.namespaceSelectorFlag = fs.Lookup("namespace-selector")
+	fs.StringSliceVar(&p.options.ImmutableFields, "immutable-fields", nil,
+		"spec fields, by their Go or JSON name, whose updates are rejected")
+	fs.StringVar(&p.options.Immutability, "immutability", scaffolds.ImmutabilityWebhook,
+		"how the updates of the --immutable-fields are rejected: by the validating webhook, which requires "+
+			"--programmatic-validation, or by CEL validation rules of the CRD, which require Kubernetes 1.25. "+
+			"Options: [webhook, cel]")
+	fs.BoolVar(&p.options.Metrics, "metrics", false,
+		"if set, record the latency and the result of the admission requests of the defaulting and validating "+
+			"webhooks as Prometheus metrics, and scaffold a Grafana dashboard of them")
+	fs.IntVar(&p.options.MaxInFlight, "max-in-flight", 0,
+		"maximum number of admission requests served concurrently by each of the defaulting and validating "+
+			"webhooks, the others are rejected. Requires --metrics, defaults to no limit")
+	fs.BoolVar(&p.options.WasmPolicy, "wasm-policy", false,
+		"(experimental) if set, move the validation methods of the validating webhook to a file of their own, "+
+			"also compiled to a Kubewarden wasm policy by the wasm-policies target. Requires --programmatic-validation")
 }
 
 func (p *createWebhookSubcommand) InjectConfig(c *config.Config) {
@@ -103,13 +212,39 @@ func (p *createWebhookSubcommand) Run() error {
 }
 
 func (p *createWebhookSubcommand) Validate() error {
+	if p.config.ManifestsOnly {
+		return fmt.Errorf("%s create webhook is not supported by projects initialized with --manifests-only, "+
+			"whose Go code lives in another repository", p.commandName)
+	}
+	if p.config.Pattern == scaffolds.PatternAggregatedAPIServer {
+		return fmt.Errorf("%s create webhook is not supported by projects initialized with --pattern=%s, "+
+			"whose API server validates and defaults the objects itself", p.commandName, scaffolds.PatternAggregatedAPIServer)
+	}
+
 	if err := p.resource.Validate(); err != nil {
 		return err
 	}
 
-	if !p.defaulting && !p.validation && !p.conversion {
+	// The default webhook version is served by the minimum Kubernetes version of the project
+	if !p.webhookVersionFlag.Changed {
+		p.resource.Webhooks.WebhookVersion = scaffolds.KubernetesProfileFor(p.config).WebhookVersion
+	} else if scaffolds.SupportsPreV1Kubernetes(p.config) && p.resource.Webhooks.WebhookVersion == "v1" {
+		return fmt.Errorf("v1 webhooks require Kubernetes 1.16 or later, the minimum Kubernetes version "+
+			"of the project is %s, use --webhook-version=v1beta1", p.config.MinKubernetesVersion)
+	}
+
+	// The CEL validation rules are added to the types of the resource, without any webhook
+	celOnly := len(p.options.ImmutableFields) != 0 && p.options.Immutability == scaffolds.ImmutabilityCEL &&
+		!p.defaulting && !p.validation && !p.conversion && !p.ownerLabels && len(p.options.Subresources) == 0
+	if !p.defaulting && !p.validation && !p.conversion && !p.ownerLabels && len(p.options.Subresources) == 0 &&
+		!celOnly {
 		return fmt.Errorf("%s create webhook requires at least one of --defaulting,"+
-			" --programmatic-validation and --conversion to be true", p.commandName)
+			" --programmatic-validation, --conversion and --owner-labels to be true, or --subresource,"+
+			" or --immutable-fields with --immutability=cel", p.commandName)
+	}
+
+	if err := p.validateOptions(); err != nil {
+		return err
 	}
 
 	// check if resource exist to create webhook
@@ -118,8 +253,40 @@ func (p *createWebhookSubcommand) Validate() error {
 			" kind and version provided", p.commandName)
 	}
 
+	if err := p.validateImmutability(); err != nil {
+		return err
+	}
+	if err := p.validateSubresources(); err != nil {
+		return err
+	}
+	if celOnly {
+		return nil
+	}
+
 	if p.config.HasWebhook(p.resource.Data()) && !p.force {
-		return errors.New("webhook resource already exists")
+		// Only scaffold the webhook types that were not scaffolded yet.
+		scaffolded := p.config.GetResource(p.resource.Data()).Webhooks
+		if scaffolded.IsEmpty() {
+			return errors.New("webhook resource already exists and its webhook types are not recorded " +
+				"in the PROJECT file, use --force to scaffold it again")
+		}
+		p.defaulting = p.defaulting && !scaffolded.Defaulting
+		p.validation = p.validation && !scaffolded.Validation
+		p.conversion = p.conversion && !scaffolded.Conversion
+		p.ownerLabels = p.ownerLabels && !scaffolded.OwnerLabels
+		subresources := make([]string, 0, len(p.options.Subresources))
+		for _, subresource := range p.options.Subresources {
+			if !scaffolded.HasSubresource(subresource) {
+				subresources = append(subresources, subresource)
+			}
+		}
+		p.options.Subresources = subresources
+		if !p.defaulting && !p.validation && !p.conversion && !p.ownerLabels && len(p.options.Subresources) == 0 {
+			return errors.New("webhook resource already exists")
+		}
+		// The owner labels and subresource webhooks have files of their own, the webhook file of the resource
+		// only exists if one of the other webhooks was scaffolded.
+		p.update = scaffolded.Defaulting || scaffolded.Validation || scaffolded.Conversion
 	}
 
 	if !p.config.IsWebhookVersionCompatible(p.resource.Webhooks.WebhookVersion) {
@@ -127,6 +294,193 @@ func (p *createWebhookSubcommand) Validate() error {
 			p.resource.Webhooks.WebhookVersion)
 	}
 
+	// The SetupWebhookWithManager method of an already scaffolded webhook file belongs to the user, the
+	// webhooks added to it are instrumented if it already registers them with webhookmetrics.Register
+	if p.options.Metrics && p.update {
+		res := p.resource.NewResource(p.config, false)
+		webhookFile := strings.TrimSuffix(scaffolds.TypesPath(p.config, res), "_types.go") + "_webhook.go"
+		content, err := ioutil.ReadFile(webhookFile) //nolint:gosec
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(content), "webhookmetrics.Register(") {
+			return fmt.Errorf("--metrics requires scaffolding the webhook file of the resource: register its "+
+				"webhooks with webhookmetrics.Register in the SetupWebhookWithManager method of %s instead",
+				webhookFile)
+		}
+	}
+
+	// The ValidateUpdate method of an already scaffolded validating webhook belongs to the user
+	if len(p.options.ImmutableFields) != 0 && p.options.Immutability == scaffolds.ImmutabilityWebhook &&
+		!p.validation {
+		return errors.New("--immutable-fields requires --programmatic-validation, unless the validating webhook " +
+			"of the resource is already scaffolded: then check the fields in its ValidateUpdate method, " +
+			"or use --immutability=cel")
+	}
+
+	// The validation methods of an already scaffolded validating webhook belong to the user
+	if p.options.WasmPolicy && !p.validation {
+		return errors.New("--wasm-policy requires --programmatic-validation: the validation methods of an already " +
+			"scaffolded validating webhook belong to the user, use --force to scaffold the webhook file again")
+	}
+
+	return nil
+}
+
+// validateImmutability validates the immutable fields options.
+func (p *createWebhookSubcommand) validateImmutability() error {
+	switch p.options.Immutability {
+	case scaffolds.ImmutabilityWebhook:
+		return nil
+	case scaffolds.ImmutabilityCEL:
+	default:
+		return fmt.Errorf("invalid --immutability %q, the options are webhook and cel", p.options.Immutability)
+	}
+
+	if len(p.options.ImmutableFields) == 0 {
+		return errors.New("--immutability=cel requires --immutable-fields")
+	}
+	if p.config.SupportsKubernetesBefore(1, 25) {
+		return fmt.Errorf("the CEL validation rules require Kubernetes 1.25 or later, the minimum Kubernetes "+
+			"version of the project is %s, use --immutability=webhook", p.config.MinKubernetesVersion)
+	}
+	if res := p.config.GetResource(p.resource.Data()); res.API != nil && res.API.CRDVersion == "v1beta1" {
+		return errors.New("the CEL validation rules require a v1 CRD, use --immutability=webhook")
+	}
+	return nil
+}
+
+// subresourceMarkers are the markers enabling the subresources on the types of the resources
+var subresourceMarkers = map[string]string{
+	scaffolds.SubresourceStatus: "+kubebuilder:subresource:status",
+	scaffolds.SubresourceScale:  "+kubebuilder:subresource:scale",
+}
+
+// validateSubresources validates the subresources whose webhooks are scaffolded.
+func (p *createWebhookSubcommand) validateSubresources() error {
+	if len(p.options.Subresources) == 0 {
+		return nil
+	}
+
+	var content []byte
+	seen := make(map[string]bool, len(p.options.Subresources))
+	subresources := make([]string, 0, len(p.options.Subresources))
+	for _, subresource := range p.options.Subresources {
+		subresource = strings.ToLower(subresource)
+		marker, known := subresourceMarkers[subresource]
+		if !known {
+			return fmt.Errorf("invalid --subresource %q, the options are status and scale", subresource)
+		}
+		if seen[subresource] {
+			continue
+		}
+		seen[subresource] = true
+		subresources = append(subresources, subresource)
+
+		// The webhook would never be called for a subresource the CRD does not serve
+		if content == nil {
+			typesPath := scaffolds.TypesPath(p.config, p.resource.NewResource(p.config, false))
+			var err error
+			if content, err = ioutil.ReadFile(typesPath); err != nil { //nolint:gosec
+				return fmt.Errorf("--subresource requires the types file of the resource: %v", err)
+			}
+		}
+		if !strings.Contains(string(content), marker) {
+			return fmt.Errorf("the %s subresource is not enabled for %s, add the //%s marker to its type first",
+				subresource, p.resource.Kind, marker)
+		}
+	}
+	p.options.Subresources = subresources
+	return nil
+}
+
+// validateOptions validates the options of the defaulting and validating webhooks.
+func (p *createWebhookSubcommand) validateOptions() error {
+	p.options.FailurePolicy = strings.ToLower(p.options.FailurePolicy)
+	if p.options.FailurePolicy != "fail" && p.options.FailurePolicy != "ignore" {
+		return fmt.Errorf("invalid --failure-policy %q, the options are fail and ignore", p.options.FailurePolicy)
+	}
+
+	switch p.options.SideEffects {
+	case "None", "NoneOnDryRun":
+	case "Some", "Unknown":
+		if p.resource.Webhooks.WebhookVersion != "v1beta1" {
+			return fmt.Errorf("--side-effects %s is only supported by v1beta1 webhooks", p.options.SideEffects)
+		}
+	default:
+		return fmt.Errorf("invalid --side-effects %q, the options are None and NoneOnDryRun", p.options.SideEffects)
+	}
+
+	if p.options.MatchPolicy != "" && p.options.MatchPolicy != "Exact" && p.options.MatchPolicy != "Equivalent" {
+		return fmt.Errorf("invalid --match-policy %q, the options are Exact and Equivalent", p.options.MatchPolicy)
+	}
+
+	if p.options.TimeoutSeconds < 0 || p.options.TimeoutSeconds > 30 {
+		return fmt.Errorf("invalid --timeout-seconds %d, the timeout must be between 1 and 30 seconds",
+			p.options.TimeoutSeconds)
+	}
+
+	if p.options.ReinvocationPolicy != "" {
+		if p.options.ReinvocationPolicy != "Never" && p.options.ReinvocationPolicy != "IfNeeded" {
+			return fmt.Errorf("invalid --reinvocation-policy %q, the options are Never and IfNeeded",
+				p.options.ReinvocationPolicy)
+		}
+		if !p.defaulting {
+			return errors.New("--reinvocation-policy can only be used with --defaulting")
+		}
+	}
+
+	customized := p.options.FailurePolicy != "fail" || p.options.SideEffects != "None" ||
+		p.options.MatchPolicy != "" || p.options.TimeoutSeconds != 0 || p.options.ReinvocationPolicy != "" ||
+		p.options.ObjectSelector != "" || p.options.NamespaceSelector != ""
+	if customized && !p.defaulting && !p.validation {
+		return errors.New("--failure-policy, --side-effects, --match-policy, --timeout-seconds and the selectors " +
+			"can only be used with --defaulting or --programmatic-validation")
+	}
+
+	if _, err := scaffolds.ParseLabelSelector(p.options.ObjectSelector); err != nil {
+		return fmt.Errorf("invalid --object-selector: %v", err)
+	}
+	if _, err := scaffolds.ParseLabelSelector(p.options.NamespaceSelector); err != nil {
+		return fmt.Errorf("invalid --namespace-selector: %v", err)
+	}
+
+	if p.options.MaxInFlight < 0 {
+		return fmt.Errorf("invalid --max-in-flight %d, the limit must be positive", p.options.MaxInFlight)
+	}
+	if p.options.MaxInFlight != 0 && !p.options.Metrics {
+		return errors.New("--max-in-flight requires --metrics")
+	}
+	if p.options.Metrics && !p.defaulting && !p.validation {
+		return errors.New("--metrics can only be used with --defaulting or --programmatic-validation")
+	}
+
+	// Projects scaffolded before the webhook options patches do not list them in their webhook kustomization
+	kustomization := filepath.Join("config", "webhook", "kustomization.yaml")
+	content, err := ioutil.ReadFile(kustomization) //nolint:gosec
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	patchable := err != nil || strings.Contains(string(content), "+kubebuilder:scaffold:webhookkustomizepatch")
+
+	// The webhooks skip kube-system and the namespace of the manager by default, so that they never block the
+	// rollout of the manager serving them
+	if !p.namespaceSelectorFlag.Changed && (p.defaulting || p.validation) {
+		if patchable {
+			p.options.NamespaceSelector = scaffolds.DefaultWebhookNamespaceSelector(p.config)
+		} else {
+			fmt.Printf("%s has no \"#+kubebuilder:scaffold:webhookkustomizepatch\" marker, the webhooks do not "+
+				"skip kube-system and the namespace of the manager by default\n", kustomization)
+		}
+	}
+
+	if !patchable && (p.options.TimeoutSeconds != 0 || p.options.ReinvocationPolicy != "" ||
+		p.options.ObjectSelector != "" || p.options.NamespaceSelector != "") {
+		return fmt.Errorf("--timeout-seconds, --reinvocation-policy, --object-selector and --namespace-selector "+
+			"require the \"#+kubebuilder:scaffold:webhookkustomizepatch\" marker under a patchesStrategicMerge "+
+			"field of %s", kustomization)
+	}
+
 	return nil
 }
 
@@ -138,14 +492,20 @@ func (p *createWebhookSubcommand) GetScaffolder() (cmdutil.Scaffolder, error) {
 	}
 
 	// Create the actual resource from the resource options
+	p.resource.Webhooks.Defaulting = p.defaulting
+	p.resource.Webhooks.Validation = p.validation
+	p.resource.Webhooks.Conversion = p.conversion
+	p.resource.Webhooks.OwnerLabels = p.ownerLabels
+	p.resource.Webhooks.Subresources = p.options.Subresources
+	p.resource.Webhooks.WasmPolicy = p.options.WasmPolicy
 	res := p.resource.NewResource(p.config, false)
 	return scaffolds.NewWebhookScaffolder(p.config, string(bp), res, p.defaulting, p.validation, p.conversion,
-		p.force), nil
+		p.ownerLabels, p.force, p.update, p.options), nil
 }
 
 func (p *createWebhookSubcommand) PostScaffold() error {
 	if p.runMake {
-		return util.RunCmd("Running make", "make")
+		return runTargets(p.config)
 	}
 	return nil
 }
@@ -16,6 +16,18 @@ limitations under the License.
 
 package cmdutil
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/debug"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
+)
+
 // Scaffolder interface creates files to set up a controller manager
 type Scaffolder interface {
 	// Scaffold performs the scaffolding
@@ -36,25 +48,63 @@ type RunOptions interface {
 // Run executes a command
 func Run(options RunOptions) error {
 	// Step 1: validate
+	start := time.Now()
 	if err := options.Validate(); err != nil {
 		return err
 	}
+	debug.Since(start, "validation")
 
-	// Step 2: get scaffolder
-	scaffolder, err := options.GetScaffolder()
-	if err != nil {
+	// Steps 2 and 3 are transactional: the files they change are restored if they fail or are interrupted
+	if err := scaffold(options); err != nil {
 		return err
 	}
-	// Step 3: scaffold
-	if scaffolder != nil {
-		if err := scaffolder.Scaffold(); err != nil {
-			return err
-		}
-	}
 	// Step 4: finish
+	start = time.Now()
 	if err := options.PostScaffold(); err != nil {
 		return err
 	}
+	debug.Since(start, "post-scaffolding")
 
 	return nil
 }
+
+// scaffold runs the steps 2 and 3 of options, restoring the changed files if they fail. The interrupts received
+// meanwhile are delayed until they are done, and then restore the changed files too.
+func scaffold(options RunOptions) error {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+
+	journal.Begin()
+	err := func() error {
+		// Step 2: get scaffolder
+		scaffolder, err := options.GetScaffolder()
+		if err != nil {
+			return err
+		}
+		// Step 3: scaffold
+		if scaffolder != nil {
+			start := time.Now()
+			if err := scaffolder.Scaffold(); err != nil {
+				return err
+			}
+			debug.Since(start, "scaffolding")
+		}
+		return nil
+	}()
+	if err == nil {
+		select {
+		case <-interrupts:
+			err = errors.New("interrupted")
+		default:
+			journal.Commit()
+			return nil
+		}
+	}
+
+	if rollbackErr := journal.Rollback(); rollbackErr != nil {
+		return fmt.Errorf("%v, and the changed files could not be restored: %v", err, rollbackErr)
+	}
+	fmt.Println("The changed files were restored")
+	return err
+}
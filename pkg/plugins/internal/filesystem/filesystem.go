@@ -22,6 +22,8 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/afero"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/journal"
 )
 
 const (
@@ -110,6 +112,11 @@ func (fs fileSystem) Open(path string) (io.ReadCloser, error) {
 
 // Create implements FileSystem.Create
 func (fs fileSystem) Create(path string) (io.Writer, error) {
+	// Record the file so that it is restored if the scaffold fails
+	if err := journal.Record(path); err != nil {
+		return nil, createFileError{path, err}
+	}
+
 	// Create the directory if needed
 	if err := fs.fs.MkdirAll(filepath.Dir(path), fs.dirPerm); err != nil {
 		return nil, createDirectoryError{path, err}
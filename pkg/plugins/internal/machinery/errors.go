@@ -34,7 +34,8 @@ type fileAlreadyExistsError struct {
 
 // Error implements error interface
 func (e fileAlreadyExistsError) Error() string {
-	return fmt.Sprintf("failed to create %s: file already exists", e.path)
+	return fmt.Sprintf("failed to create %s: file already exists, set --on-conflict to skip, overwrite or merge it",
+		e.path)
 }
 
 // IsFileAlreadyExistsError checks if the returned error is because the file already existed when expected not to
@@ -72,3 +73,20 @@ func (e unknownIfExistsActionError) Error() string {
 func IsUnknownIfExistsActionError(err error) bool {
 	return errors.As(err, &unknownIfExistsActionError{})
 }
+
+// anchorNotFoundError is returned if the position of a marker is not found in the syntax tree of a Go file
+type anchorNotFoundError struct {
+	path   string
+	marker file.Marker
+	err    error
+}
+
+// Error implements error interface
+func (e anchorNotFoundError) Error() string {
+	return fmt.Sprintf("unable to locate where the code of %s is inserted in %s: %v", e.marker, e.path, e.err)
+}
+
+// IsAnchorNotFoundError checks if the returned error is because the position of a marker was not found
+func IsAnchorNotFoundError(err error) bool {
+	return errors.As(err, &anchorNotFoundError{})
+}
@@ -20,13 +20,18 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"go/parser"
+	"go/token"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
 	"golang.org/x/tools/imports"
 
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/conflict"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/debug"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/filesystem"
@@ -39,6 +44,10 @@ var options = imports.Options{
 	FormatOnly: true,
 }
 
+// LockDir is the directory of the scaffold lock. When it exists, the content of each scaffolded file is recorded
+// in it, at the same path, as the base of the merges of the file when it is scaffolded again.
+var LockDir = filepath.Join(".kubebuilder", "scaffold-lock")
+
 // Scaffold uses templates to scaffold new files
 type Scaffold interface {
 	// Execute writes to disk the provided files
@@ -52,13 +61,24 @@ type scaffold struct {
 
 	// fs allows to mock the file system for tests
 	fs filesystem.FileSystem
+
+	// locked is true if the scaffolded files are recorded in the scaffold lock
+	locked bool
 }
 
 // NewScaffold returns a new Scaffold with the provided plugins
 func NewScaffold(plugins ...model.Plugin) Scaffold {
+	return NewScaffoldWithFileSystem(filesystem.New(), plugins...)
+}
+
+// NewScaffoldWithFileSystem returns a new Scaffold with the provided plugins that reads and writes the files
+// through fs instead of the disk
+func NewScaffoldWithFileSystem(fs filesystem.FileSystem, plugins ...model.Plugin) Scaffold {
+	locked, _ := fs.Exists(LockDir)
 	return &scaffold{
 		plugins: plugins,
-		fs:      filesystem.New(),
+		fs:      fs,
+		locked:  locked,
 	}
 }
 
@@ -105,6 +125,22 @@ func (s *scaffold) Execute(universe *model.Universe, files ...file.Builder) erro
 		}
 	}
 
+	// Check the conflicts before writing any file, so that an error does not leave a half-written scaffold
+	if conflict.CurrentStrategy() == conflict.StrategyError {
+		for _, f := range universe.Files {
+			if f.IfExistsAction != file.Error {
+				continue
+			}
+			exists, err := s.fs.Exists(f.Path)
+			if err != nil {
+				return err
+			}
+			if exists {
+				return fileAlreadyExistsError{f.Path}
+			}
+		}
+	}
+
 	// Persist the files to disk
 	for _, f := range universe.Files {
 		if err := s.writeFile(f); err != nil {
@@ -141,6 +177,8 @@ func (scaffold) buildFileModel(t file.Template, models map[string]*file.File) er
 		IfExistsAction: t.GetIfExistsAction(),
 	}
 
+	debug.Tracef("rendering template %T into %s", t, t.GetPath())
+
 	b, err := doTemplate(t)
 	if err != nil {
 		return err
@@ -153,7 +191,7 @@ func (scaffold) buildFileModel(t file.Template, models map[string]*file.File) er
 
 // doTemplate executes the template for a file using the input
 func doTemplate(t file.Template) ([]byte, error) {
-	temp, err := newTemplate(t).Parse(t.GetBody())
+	temp, err := parseTemplate(t)
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +246,16 @@ func (s scaffold) updateFileModel(i file.Inserter, models map[string]*file.File)
 		return nil
 	}
 
-	content, err := insertStrings(m.Contents, codeFragments)
+	for marker, fragments := range codeFragments {
+		debug.Verbosef("inserting %d code fragment(s) at marker %q in %s", len(fragments), marker, i.GetPath())
+	}
+
+	var content []byte
+	if anchored, isAnchored := i.(file.AnchoredInserter); isAnchored && anchored.GetAnchors() != nil {
+		content, err = insertAtAnchors(i.GetPath(), m.Contents, codeFragments, anchored.GetAnchors())
+	} else {
+		content, err = insertStrings(m.Contents, codeFragments)
+	}
 	if err != nil {
 		return err
 	}
@@ -253,8 +300,21 @@ func (s scaffold) loadPreviousModel(i file.Inserter, models map[string]*file.Fil
 			}
 			return fromFile, nil
 		case file.Error:
-			// Writing will result in an error, so we can return error now
-			return nil, fileAlreadyExistsError{i.GetPath()}
+			switch conflict.CurrentStrategy() {
+			case conflict.StrategyError:
+				// Writing will result in an error, so we can return error now
+				return nil, fileAlreadyExistsError{i.GetPath()}
+			case conflict.StrategySkip:
+				// File has preference, as it is kept
+				fromFile, err := s.loadModelFromFile(i.GetPath())
+				if err != nil {
+					return m, nil
+				}
+				return fromFile, nil
+			default:
+				// Model has preference, its conflict with the file is resolved when writing it
+				return m, nil
+			}
 		case file.Overwrite:
 			// Model has preference
 			return m, nil
@@ -359,6 +419,60 @@ func insertStrings(content string, codeFragmentsMap file.CodeFragmentsMap) ([]by
 	return out.Bytes(), nil
 }
 
+// insertAtAnchors inserts the code fragments of the markers located by anchors at the start of the line of the
+// position they return in the Go file at path, and the code fragments of the other markers at the markers.
+func insertAtAnchors(
+	path, content string, codeFragmentsMap file.CodeFragmentsMap, anchors map[file.Marker]file.Anchor,
+) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	tokenFile := fset.File(f.Pos())
+
+	type insertion struct {
+		offset    int
+		marker    string
+		fragments file.CodeFragments
+	}
+	var insertions []insertion
+	atMarkers := make(file.CodeFragmentsMap, len(codeFragmentsMap))
+	for marker, codeFragments := range codeFragmentsMap {
+		anchor, found := anchors[marker]
+		if !found {
+			atMarkers[marker] = codeFragments
+			continue
+		}
+		pos, err := anchor(fset, f)
+		if err != nil {
+			return nil, anchorNotFoundError{path, marker, err}
+		}
+		offset := tokenFile.Offset(tokenFile.LineStart(tokenFile.Line(pos)))
+		insertions = append(insertions, insertion{offset, marker.String(), codeFragments})
+	}
+	// Markers located at the same line are inserted in a stable order
+	sort.Slice(insertions, func(i, j int) bool {
+		if insertions[i].offset != insertions[j].offset {
+			return insertions[i].offset < insertions[j].offset
+		}
+		return insertions[i].marker < insertions[j].marker
+	})
+
+	out := new(bytes.Buffer)
+	last := 0
+	for _, in := range insertions {
+		_, _ = out.WriteString(content[last:in.offset]) // bytes.Buffer.WriteString always returns nil errors
+		for _, codeFragment := range in.fragments {
+			_, _ = out.WriteString(codeFragment)
+		}
+		last = in.offset
+	}
+	_, _ = out.WriteString(content[last:])
+
+	return insertStrings(out.String(), atMarkers)
+}
+
 func (s scaffold) writeFile(f *file.File) error {
 	// Check if the file to write already exists
 	exists, err := s.fs.Exists(f.Path)
@@ -371,19 +485,94 @@ func (s scaffold) writeFile(f *file.File) error {
 			// By not returning, the file is written as if it didn't exist
 		case file.Skip:
 			// By returning nil, the file is not written but the process will carry on
+			debug.Verbosef("skipping %s, it already exists", f.Path)
 			return nil
 		case file.Error:
-			// By returning an error, the file is not written and the process will fail
-			return fileAlreadyExistsError{f.Path}
+			// The conflict is resolved by the strategy of the run, an error by default
+			return s.resolveConflict(f)
 		}
 	}
 
-	writer, err := s.fs.Create(f.Path)
+	return s.write(f.Path, f.Contents, true)
+}
+
+// resolveConflict writes f, which already exists, following the conflict strategy
+func (s scaffold) resolveConflict(f *file.File) error {
+	switch conflict.CurrentStrategy() {
+	case conflict.StrategySkip:
+		fmt.Printf("Kept %s, it already exists\n", f.Path)
+		return nil
+	case conflict.StrategyOverwrite:
+		fmt.Printf("Overwrote %s\n", f.Path)
+		return s.write(f.Path, f.Contents, true)
+	case conflict.StrategySideFile:
+		return s.writeSideFile(f)
+	case conflict.StrategyMerge:
+		return s.merge(f)
+	default:
+		// By returning an error, the file is not written and the process will fail
+		return fileAlreadyExistsError{f.Path}
+	}
+}
+
+// writeSideFile writes f next to the existing file, which is kept
+func (s scaffold) writeSideFile(f *file.File) error {
+	fmt.Printf("Wrote %s%s, %s already exists\n", f.Path, conflict.SideFileSuffix, f.Path)
+	return s.write(f.Path+conflict.SideFileSuffix, f.Contents, false)
+}
+
+// merge merges the changes of the existing file and of f since the previous scaffold of the file recorded in the
+// scaffold lock, writing f next to the existing file instead if none is recorded
+func (s scaffold) merge(f *file.File) error {
+	base, err := s.loadModelFromFile(filepath.Join(LockDir, f.Path))
+	if err != nil {
+		fmt.Printf("No previous scaffold of %s is recorded in %s to merge it. ", f.Path, LockDir)
+		return s.writeSideFile(f)
+	}
+	current, err := s.loadModelFromFile(f.Path)
+	if err != nil {
+		return err
+	}
+
+	merged, conflicts := conflict.Merge(base.Contents, current.Contents, f.Contents)
+	if err := s.write(f.Path, merged, false); err != nil {
+		return err
+	}
+	if conflicts != 0 {
+		fmt.Printf("CONFLICT: merged %s with %d conflict(s), resolve them before building the project\n",
+			f.Path, conflicts)
+	} else {
+		fmt.Printf("Merged %s\n", f.Path)
+	}
+	return s.record(f.Path, f.Contents)
+}
+
+// write writes contents to path, and records them in the scaffold lock if record is set
+func (s scaffold) write(path, contents string, record bool) error {
+	debug.Verbosef("writing %s", path)
+	writer, err := s.fs.Create(path)
 	if err != nil {
 		return err
 	}
+	if _, err := writer.Write([]byte(contents)); err != nil {
+		return err
+	}
 
-	_, err = writer.Write([]byte(f.Contents))
+	if record {
+		return s.record(path, contents)
+	}
+	return nil
+}
 
+// record records contents as the scaffold of path in the scaffold lock, if it exists
+func (s scaffold) record(path, contents string) error {
+	if !s.locked {
+		return nil
+	}
+	writer, err := s.fs.Create(filepath.Join(LockDir, path))
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write([]byte(contents))
 	return err
 }
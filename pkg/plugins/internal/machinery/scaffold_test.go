@@ -17,12 +17,15 @@ package machinery
 import (
 	"bytes"
 	"errors"
+	"go/ast"
+	"go/token"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/conflict"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model"
 	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
 	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/filesystem"
@@ -316,6 +319,34 @@ var _ = Describe("Scaffold", func() {
 					},
 				},
 			),
+			Entry("should insert lines at the anchors of go files",
+				`package p
+
+func f() {
+	a()
+}
+
+//+kubebuilder:scaffold:*
+`,
+				`package p
+
+func f() {
+	a()
+1
+2
+}
+
+3
+//+kubebuilder:scaffold:*
+`,
+				fakeAnchoredInserter{
+					fakeInserter: fakeInserter{codeFragments: file.CodeFragmentsMap{
+						file.NewMarkerFor("file.go", "-"): {"1\n", "2\n"},
+						file.NewMarkerFor("file.go", "*"): {"3\n"},
+					}},
+					anchors: map[file.Marker]file.Anchor{file.NewMarkerFor("file.go", "-"): endOfFirstFunc},
+				},
+			),
 			Entry("should not insert anything if no code fragment",
 				"", // input is provided through a template as mock fs doesn't copy it to the output buffer if no-op
 				`
@@ -356,6 +387,24 @@ var _ = Describe("Scaffold", func() {
 			),
 		)
 
+		It("should fail if an anchor is not found", func() {
+			s := &scaffold{
+				fs: filesystem.NewMock(
+					filesystem.MockInput(bytes.NewBufferString("package p\n")),
+					filesystem.MockExists(func(_ string) bool { return true }),
+				),
+			}
+
+			err := s.Execute(model.NewUniverse(), fakeAnchoredInserter{
+				fakeInserter: fakeInserter{codeFragments: file.CodeFragmentsMap{
+					file.NewMarkerFor("file.go", "-"): {"1\n"},
+				}},
+				anchors: map[file.Marker]file.Anchor{file.NewMarkerFor("file.go", "-"): endOfFirstFunc},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(IsAnchorNotFoundError(err)).To(BeTrue())
+		})
+
 		It("should fail if a plugin fails", func() {
 			s := &scaffold{
 				fs:      filesystem.NewMock(),
@@ -407,6 +456,45 @@ var _ = Describe("Scaffold", func() {
 				Expect(IsFileAlreadyExistsError(err)).To(BeTrue())
 				Expect(output.String()).To(BeEmpty())
 			})
+
+			It("should error before writing any file", func() {
+				s = &scaffold{
+					fs: filesystem.NewMock(
+						filesystem.MockExists(func(path string) bool { return path == "filename" }),
+						filesystem.MockOutput(&output),
+					),
+				}
+				err := s.Execute(
+					model.NewUniverse(),
+					fakeTemplate{fakeBuilder: fakeBuilder{path: "other"}, body: fileContent},
+					fakeTemplate{fakeBuilder: fakeBuilder{path: "filename", ifExistsAction: file.Error}, body: fileContent},
+				)
+				Expect(err).To(HaveOccurred())
+				Expect(IsFileAlreadyExistsError(err)).To(BeTrue())
+				Expect(output.String()).To(BeEmpty())
+			})
+
+			Context("with a conflict strategy", func() {
+				AfterEach(func() {
+					conflict.SetStrategy(conflict.StrategyError)
+				})
+
+				DescribeTable("should resolve the conflict",
+					func(strategy conflict.Strategy, expected string) {
+						conflict.SetStrategy(strategy)
+						Expect(s.Execute(
+							model.NewUniverse(),
+							fakeTemplate{fakeBuilder: fakeBuilder{path: "filename", ifExistsAction: file.Error}, body: fileContent},
+						)).To(Succeed())
+						Expect(output.String()).To(Equal(expected))
+					},
+					Entry("skip", conflict.StrategySkip, ""),
+					Entry("overwrite", conflict.StrategyOverwrite, fileContent),
+					Entry("side-file", conflict.StrategySideFile, fileContent),
+					// Without a scaffold lock, the scaffolded file is written next to the existing one
+					Entry("merge", conflict.StrategyMerge, fileContent),
+				)
+			})
 		})
 
 		DescribeTable("filesystem errors",
@@ -558,3 +646,27 @@ func (f fakeInserter) GetMarkers() []file.Marker {
 func (f fakeInserter) GetCodeFragments() file.CodeFragmentsMap {
 	return f.codeFragments
 }
+
+var _ file.AnchoredInserter = fakeAnchoredInserter{}
+
+// fakeAnchoredInserter is used to mock a file.AnchoredInserter in order to test Scaffold
+type fakeAnchoredInserter struct {
+	fakeInserter
+
+	anchors map[file.Marker]file.Anchor
+}
+
+// GetAnchors implements file.AnchoredInserter
+func (f fakeAnchoredInserter) GetAnchors() map[file.Marker]file.Anchor {
+	return f.anchors
+}
+
+// endOfFirstFunc is an anchor locating the closing brace of the first function
+func endOfFirstFunc(_ *token.FileSet, f *ast.File) (token.Pos, error) {
+	for _, decl := range f.Decls {
+		if fn, isFunc := decl.(*ast.FuncDecl); isFunc {
+			return fn.Body.Rbrace, nil
+		}
+	}
+	return token.NoPos, errors.New("no function found")
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinery
+
+import (
+	"reflect"
+	"sync"
+	"text/template"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/debug"
+	"sigs.k8s.io/kubebuilder/v2/pkg/model/file"
+)
+
+// templateKey identifies a parsed template by the type of its file.Template, whose package path includes the
+// version of the plugin, and by its body, which some templates build at runtime
+type templateKey struct {
+	typ  reflect.Type
+	body string
+}
+
+// templates caches the parsed templates of the process, so that the scaffolds executed several times by a
+// command, such as the batch scaffolds of create api --from-file, parse each template once
+var templates = struct {
+	sync.Mutex
+	parsed map[templateKey]*template.Template
+}{
+	parsed: make(map[templateKey]*template.Template),
+}
+
+// parseTemplate returns the parsed template of t, from the cache if it was already parsed. The templates with a
+// custom FuncMap are parsed every time, as their functions may depend on the fields of t.
+func parseTemplate(t file.Template) (*template.Template, error) {
+	if _, hasCustomFuncMap := t.(file.UseCustomFuncMap); hasCustomFuncMap {
+		return newTemplate(t).Parse(t.GetBody())
+	}
+
+	key := templateKey{typ: reflect.TypeOf(t), body: t.GetBody()}
+
+	templates.Lock()
+	defer templates.Unlock()
+	if temp, found := templates.parsed[key]; found {
+		debug.Tracef("reusing the parsed template of %T", t)
+		return temp, nil
+	}
+	temp, err := newTemplate(t).Parse(key.body)
+	if err != nil {
+		return nil, err
+	}
+	templates.parsed[key] = temp
+	return temp, nil
+}
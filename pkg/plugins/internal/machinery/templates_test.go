@@ -0,0 +1,111 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machinery
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("parseTemplate", func() {
+	It("should reuse the parsed template of the same type and body", func() {
+		first, err := parseTemplate(fakeTemplate{body: "{{ lower \"Cached\" }}"})
+		Expect(err).NotTo(HaveOccurred())
+		second, err := parseTemplate(fakeTemplate{body: "{{ lower \"Cached\" }}"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeIdenticalTo(first))
+	})
+
+	It("should parse the templates of different bodies", func() {
+		b, err := doTemplate(fakeTemplate{body: "{{ lower \"First\" }}"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal("first"))
+		b, err = doTemplate(fakeTemplate{body: "{{ lower \"Second\" }}"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal("second"))
+	})
+
+	It("should parse the templates of the same body but different types", func() {
+		first, err := parseTemplate(fakeTemplate{body: "{{ .GetPath }}"})
+		Expect(err).NotTo(HaveOccurred())
+		second, err := parseTemplate(fakeFuncMapTemplate{fakeTemplate: fakeTemplate{body: "{{ .GetPath }}"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).NotTo(BeIdenticalTo(first))
+	})
+
+	It("should not cache the templates that fail to parse", func() {
+		_, err := parseTemplate(fakeTemplate{body: "{{ .Unclosed "})
+		Expect(err).To(HaveOccurred())
+		_, err = parseTemplate(fakeTemplate{body: "{{ .Unclosed "})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should parse the templates with a custom FuncMap every time", func() {
+		t := fakeFuncMapTemplate{fakeTemplate: fakeTemplate{body: "{{ shout }}"}, value: "first"}
+		b, err := doTemplate(t)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal("FIRST"))
+		t.value = "second"
+		b, err = doTemplate(t)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal("SECOND"))
+	})
+})
+
+// fakeFuncMapTemplate is used to mock a file.Template with a custom FuncMap depending on its fields
+type fakeFuncMapTemplate struct {
+	fakeTemplate
+
+	value string
+}
+
+// GetFuncMap implements file.UseCustomFuncMap
+func (f fakeFuncMapTemplate) GetFuncMap() template.FuncMap {
+	return template.FuncMap{"shout": func() string { return strings.ToUpper(f.value) }}
+}
+
+// benchmarkTemplateBody is representative of the templates of the plugins, which range over the fields of the
+// file.Template and call the functions of the default FuncMap
+const benchmarkTemplateBody = `{{ range $i, $line := .Lines }}
+{{- if $i }}
+{{ end }}// {{ title $line }} is the {{ lower $line }} of the scaffold, hash {{ hashFNV $line }}.
+{{- end }}
+`
+
+// benchmarkTemplate is used to benchmark the execution of the templates
+type benchmarkTemplate struct {
+	fakeTemplate
+
+	Lines []string
+}
+
+func BenchmarkDoTemplate(b *testing.B) {
+	t := benchmarkTemplate{
+		fakeTemplate: fakeTemplate{fakeBuilder: fakeBuilder{path: "file.yaml"}, body: benchmarkTemplateBody},
+		Lines:        strings.Fields(strings.Repeat("controller webhook manager ", 20)),
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := doTemplate(t); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
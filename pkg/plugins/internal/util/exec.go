@@ -21,13 +21,69 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/debug"
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/execution"
 )
 
-// RunCmd prints the provided message and command and then executes it binding stdout and stderr
+// RunCmd prints the provided message and command and then executes it binding stdout and stderr, unless the
+// execution of the command is not allowed or not confirmed, in which case it is skipped. The decision is printed.
+// The variables defined in the GoEnvFile of the current directory, if any, are added to its environment.
 func RunCmd(msg, cmd string, args ...string) error {
+	env, err := readGoEnv()
+	if err != nil {
+		return err
+	}
+
 	c := exec.Command(cmd, args...) //nolint:gosec
+	if len(env) != 0 {
+		c.Env = append(os.Environ(), env...)
+	}
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
 	fmt.Println(msg + ":\n$ " + strings.Join(c.Args, " "))
-	return c.Run()
+	decision := execution.Decide(cmd, args...)
+	if !decision.Execute {
+		fmt.Printf("Skipped (%s), run it to complete the scaffold\n", decision.Reason)
+		return nil
+	}
+	fmt.Printf("Executing (%s)\n", decision.Reason)
+	return run(c)
+}
+
+// run executes the command logging its duration and exit code
+func run(c *exec.Cmd) error {
+	return logExecution(c, c.Run)
+}
+
+// output executes the command returning its standard output and logging its duration and exit code
+func output(c *exec.Cmd) (out []byte, err error) {
+	err = logExecution(c, func() error {
+		out, err = c.Output()
+		return err
+	})
+	return
+}
+
+func logExecution(c *exec.Cmd, execute func() error) error {
+	command := strings.Join(c.Args, " ")
+	debug.Verbosef("running %q", command)
+	start := time.Now()
+	err := execute()
+	debug.Since(start, fmt.Sprintf("%q", command))
+	debug.Verbosef("%q exited with code %d after %v",
+		command, exitCode(c, err), time.Since(start).Round(time.Millisecond))
+	return err
+}
+
+// exitCode returns the exit code of an executed command, or -1 if it could not be started
+func exitCode(c *exec.Cmd, err error) int {
+	if c.ProcessState != nil {
+		return c.ProcessState.ExitCode()
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
 }
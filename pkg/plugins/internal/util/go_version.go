@@ -22,8 +22,16 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 )
 
+// goVersionCache holds the output of 'go version' so that it is only executed once per process
+var goVersionCache struct {
+	once sync.Once
+	out  []byte
+	err  error
+}
+
 // ValidateGoVersion verifies that Go is installed and the current go version is supported by kubebuilder
 func ValidateGoVersion() error {
 	err := fetchAndCheckGoVersion()
@@ -33,18 +41,33 @@ func ValidateGoVersion() error {
 	return nil
 }
 
-func fetchAndCheckGoVersion() error {
-	cmd := exec.Command("go", "version")
-	out, err := cmd.Output()
-	if err != nil {
-		return fmt.Errorf("failed to retrieve 'go version': %v", string(out))
+// fetchGoVersion returns the version of Go, read from `go env` along with the other variables used by kubebuilder,
+// or from the output of 'go version' for the Go versions older than 1.16, which is cached after the first call
+func fetchGoVersion() (string, error) {
+	if env, err := fetchGoEnv(); err == nil && env.GOVERSION != "" {
+		return env.GOVERSION, nil
+	}
+
+	goVersionCache.once.Do(func() {
+		goVersionCache.out, goVersionCache.err = output(exec.Command("go", "version"))
+	})
+	out := goVersionCache.out
+	if goVersionCache.err != nil {
+		return "", fmt.Errorf("failed to retrieve 'go version': %v", string(out))
 	}
 
 	split := strings.Split(string(out), " ")
 	if len(split) < 3 {
-		return fmt.Errorf("found invalid Go version: %q", string(out))
+		return "", fmt.Errorf("found invalid Go version: %q", string(out))
+	}
+	return split[2], nil
+}
+
+func fetchAndCheckGoVersion() error {
+	goVer, err := fetchGoVersion()
+	if err != nil {
+		return err
 	}
-	goVer := split[2]
 	if err := checkGoVersion(goVer); err != nil {
 		return fmt.Errorf("go version '%s' is incompatible because '%s'", goVer, err)
 	}
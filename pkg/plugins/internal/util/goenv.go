@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// GoEnvFile is the file of a project that holds the Go module configuration (GOPROXY, GOPRIVATE, GONOSUMDB)
+// used when running go commands, so that private modules can be downloaded and verified
+const GoEnvFile = ".go-env"
+
+// readGoEnv returns the KEY=VALUE variables defined in the GoEnvFile of the current directory, if present.
+// Empty lines and lines starting with '#' are ignored.
+func readGoEnv() ([]string, error) {
+	content, err := ioutil.ReadFile(GoEnvFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var env []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		variable := strings.TrimSpace(scanner.Text())
+		if variable == "" || strings.HasPrefix(variable, "#") {
+			continue
+		}
+		if strings.Index(variable, "=") <= 0 {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE, got %q", GoEnvFile, line, variable)
+		}
+		env = append(env, variable)
+	}
+	return env, scanner.Err()
+}
+
+// goEnvVars holds the variables of `go env` used by kubebuilder
+type goEnvVars struct {
+	// GOVERSION is the version of Go, e.g. go1.16.3, it is empty for the Go versions older than 1.16
+	GOVERSION string
+	// GOROOT is the root of the Go installation
+	GOROOT string
+	// GOMOD is the go.mod file of the main module, it is empty or os.DevNull outside of a module
+	GOMOD string
+}
+
+// goEnvCache holds the variables of `go env` so that they are read with a single call per process
+var goEnvCache struct {
+	once sync.Once
+	vars goEnvVars
+	err  error
+}
+
+// fetchGoEnv returns the variables of `go env` used by kubebuilder, which are all read by the first call
+// with a single execution of the go command, instead of one execution per variable.
+func fetchGoEnv() (goEnvVars, error) {
+	goEnvCache.once.Do(func() {
+		out, err := output(exec.Command("go", "env", "-json", "GOVERSION", "GOROOT", "GOMOD"))
+		if err != nil {
+			goEnvCache.err = fmt.Errorf("failed to retrieve 'go env': %v", err)
+			return
+		}
+		goEnvCache.err = json.Unmarshal(out, &goEnvCache.vars)
+	})
+	return goEnvCache.vars, goEnvCache.err
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadGoEnv(t *testing.T) {
+	tests := []struct {
+		content string
+		env     []string
+		isValid bool
+	}{
+		{"GOPROXY=https://proxy.example.com\n", []string{"GOPROXY=https://proxy.example.com"}, true},
+		{"# comment\n\nGOPRIVATE=example.com/*\nGONOSUMDB=example.com/*\n",
+			[]string{"GOPRIVATE=example.com/*", "GONOSUMDB=example.com/*"}, true},
+		{"GOPROXY\n", nil, false},
+		{"=direct\n", nil, false},
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "kubebuilder-goenv-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd) //nolint:errcheck
+
+	if env, err := readGoEnv(); err != nil || env != nil {
+		t.Errorf("expected no variables and no error when no %s is present, got %v and %v", GoEnvFile, env, err)
+	}
+
+	for _, test := range tests {
+		if err := ioutil.WriteFile(GoEnvFile, []byte(test.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		env, err := readGoEnv()
+		if err != nil {
+			if test.isValid {
+				t.Errorf("reading variables from %q failed with error '%s'", test.content, err)
+			}
+		} else if !test.isValid {
+			t.Errorf("variables from %q should be invalid, but got %v", test.content, env)
+		} else if !reflect.DeepEqual(env, test.env) {
+			t.Errorf("expected variables %v from %q, but got %v", test.env, test.content, env)
+		}
+	}
+}
+
+func TestFetchGoEnv(t *testing.T) {
+	env, err := fetchGoEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.GOROOT == "" {
+		t.Errorf("expected GOROOT to be set, got %+v", env)
+	}
+	if env.GOVERSION != "" {
+		if err := checkGoVersion(env.GOVERSION); err != nil {
+			t.Errorf("expected a supported GOVERSION, got %q: %v", env.GOVERSION, err)
+		}
+	}
+
+	if again, err := fetchGoEnv(); err != nil || again != env {
+		t.Errorf("expected the cached variables %+v, got %+v and %v", env, again, err)
+	}
+}
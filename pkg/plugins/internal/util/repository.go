@@ -17,48 +17,84 @@ limitations under the License.
 package util
 
 import (
-	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
+	"golang.org/x/mod/modfile"
+	modpath "golang.org/x/mod/module"
 	"golang.org/x/tools/go/packages"
+
+	"sigs.k8s.io/kubebuilder/v2/pkg/internal/debug"
 )
 
-// module and goMod arg just enough of the output of `go mod edit -json` for our purposes
-type goMod struct {
-	Module module
+// currentRepoCache holds the result of FindCurrentRepo so that the repository is only detected once per process
+var currentRepoCache struct {
+	once sync.Once
+	repo string
+	err  error
 }
-type module struct {
-	Path string
+
+// ReadGoModulePath reads the path of the module defined by the go.mod file in the current directory,
+// which avoids calling the go command in the most common case.
+func ReadGoModulePath() (string, error) {
+	content, err := ioutil.ReadFile("go.mod")
+	if err != nil {
+		return "", err
+	}
+	path := modfile.ModulePath(content)
+	if path == "" {
+		return "", fmt.Errorf("go.mod does not define a module path")
+	}
+	return path, nil
 }
 
-// findGoModulePath finds the path of the current module, if present.
-func findGoModulePath(forceModules bool) (string, error) {
-	cmd := exec.Command("go", "mod", "edit", "-json")
-	cmd.Env = append(cmd.Env, os.Environ()...)
-	if forceModules {
-		cmd.Env = append(cmd.Env, "GO111MODULE=on" /* turn on modules just for these commands */)
+// findGoModulePath finds the path of the current module, if present, reading the go.mod file of the current
+// directory or else the one reported by `go env`, in any of the parent directories.
+func findGoModulePath() (string, error) {
+	if path, err := ReadGoModulePath(); err == nil {
+		return path, nil
 	}
-	out, err := cmd.Output()
+
+	env, err := fetchGoEnv()
 	if err != nil {
-		if exitErr, isExitErr := err.(*exec.ExitError); isExitErr {
-			err = fmt.Errorf("%s", string(exitErr.Stderr))
-		}
 		return "", err
 	}
-	mod := goMod{}
-	if err := json.Unmarshal(out, &mod); err != nil {
+	if env.GOMOD == "" || env.GOMOD == os.DevNull {
+		return "", fmt.Errorf("no go.mod file found in the current directory or any of its parents")
+	}
+	content, err := ioutil.ReadFile(env.GOMOD)
+	if err != nil {
 		return "", err
 	}
-	return mod.Module.Path, nil
+	path := modfile.ModulePath(content)
+	if path == "" {
+		return "", fmt.Errorf("%s does not define a module path", env.GOMOD)
+	}
+	return path, nil
 }
 
 // FindCurrentRepo attempts to determine the current repository
 // though a combination of go/packages and `go mod` commands/tricks.
+// The result is cached, so subsequent calls do not execute any external command, and the `go env`
+// variables it reads are shared with the Go version check.
 func FindCurrentRepo() (string, error) {
+	currentRepoCache.once.Do(func() {
+		defer debug.Since(time.Now(), "repository detection")
+		currentRepoCache.repo, currentRepoCache.err = findCurrentRepo()
+	})
+	return currentRepoCache.repo, currentRepoCache.err
+}
+
+func findCurrentRepo() (string, error) {
 	// easiest case: existing go module
-	path, err := findGoModulePath(false)
+	path, err := findGoModulePath()
 	if err == nil {
 		return path, nil
 	}
@@ -67,7 +103,9 @@ func FindCurrentRepo() (string, error) {
 	pkgCfg := &packages.Config{
 		Mode: packages.NeedName, // name gives us path as well
 	}
+	start := time.Now()
 	pkgs, err := packages.Load(pkgCfg, ".")
+	debug.Since(start, "package loading")
 	// NB(directxman12): when go modules are off and we're outside GOPATH and
 	// we don't otherwise have a good guess packages.Load will fabricate a path
 	// that consists of `_/absolute/path/to/current/directory`.  We shouldn't
@@ -80,7 +118,7 @@ func FindCurrentRepo() (string, error) {
 	cmd := exec.Command("go", "mod", "init")
 	cmd.Env = append(cmd.Env, os.Environ()...)
 	cmd.Env = append(cmd.Env, "GO111MODULE=on" /* turn on modules just for these commands */)
-	if _, err := cmd.Output(); err != nil {
+	if _, err := output(cmd); err != nil {
 		if exitErr, isExitErr := err.(*exec.ExitError); isExitErr {
 			err = fmt.Errorf("%s", string(exitErr.Stderr))
 		}
@@ -90,5 +128,57 @@ func FindCurrentRepo() (string, error) {
 	}
 	//nolint:errcheck
 	defer os.Remove("go.mod") // clean up after ourselves
-	return findGoModulePath(true)
+	return ReadGoModulePath()
+}
+
+// scpLikeURLRegexp matches the git@host:path URLs of the repositories cloned with SSH
+var scpLikeURLRegexp = regexp.MustCompile(`^[a-zA-Z0-9._-]+@([a-zA-Z0-9.-]+):(.+)$`)
+
+// NormalizeModulePath returns the module path of path without the parts commonly copied along with the
+// URL of a repository: the scheme, the user of SSH URLs, the .git suffix and the trailing slashes.
+// The host, the first element of the path if it contains a dot, is lower-cased as hosts are case-insensitive.
+func NormalizeModulePath(path string) string {
+	path = strings.TrimSpace(path)
+	for _, scheme := range []string{"https://", "http://", "ssh://", "git://"} {
+		path = strings.TrimPrefix(path, scheme)
+	}
+	if match := scpLikeURLRegexp.FindStringSubmatch(path); match != nil {
+		path = match[1] + "/" + match[2]
+	}
+	path = strings.TrimSuffix(strings.TrimRight(path, "/"), ".git")
+
+	elems := strings.SplitN(path, "/", 2)
+	if strings.Contains(elems[0], ".") {
+		elems[0] = strings.ToLower(elems[0])
+	}
+	return strings.Join(elems, "/")
+}
+
+// invalidModulePathRegexp matches the runs of characters that are not valid in module paths
+var invalidModulePathRegexp = regexp.MustCompile(`[^a-zA-Z0-9._~/-]+`)
+
+// ValidateModulePath checks that path is a valid module path that does not collide with a package of the
+// standard library, suggesting a valid path when possible.
+func ValidateModulePath(path string) error {
+	if err := modpath.CheckImportPath(path); err != nil {
+		suggestion := strings.Trim(invalidModulePathRegexp.ReplaceAllString(path, "-"), "-/")
+		if suggestion != path && modpath.CheckImportPath(suggestion) == nil {
+			return fmt.Errorf("%v, did you mean %q?", err, suggestion)
+		}
+		return err
+	}
+
+	// The paths of the standard library packages have no dot in their first element
+	if !strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+		env, err := fetchGoEnv()
+		if err != nil {
+			return fmt.Errorf("unable to find the standard library: %v", err)
+		}
+		dir := filepath.Join(env.GOROOT, "src", filepath.FromSlash(path))
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return fmt.Errorf("module path %q collides with the standard library package of the same path, "+
+				"use a path starting with a domain, e.g. example.com/%s", path, path)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadGoModulePath(t *testing.T) {
+	tests := []struct {
+		content string
+		path    string
+		isValid bool
+	}{
+		{"module example.com/repo\n\ngo 1.15\n", "example.com/repo", true},
+		{"// comment\nmodule \"example.com/quoted\"\n", "example.com/quoted", true},
+		{"go 1.15\n", "", false},
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "kubebuilder-repository-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir) //nolint:errcheck
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd) //nolint:errcheck
+
+	if _, err := ReadGoModulePath(); err == nil {
+		t.Errorf("expected an error when no go.mod is present")
+	}
+
+	for _, test := range tests {
+		if err := ioutil.WriteFile("go.mod", []byte(test.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		path, err := ReadGoModulePath()
+		if err != nil {
+			if test.isValid {
+				t.Errorf("reading module path from %q failed with error '%s'", test.content, err)
+			}
+		} else if !test.isValid {
+			t.Errorf("module path from %q should be invalid, but got %q", test.content, path)
+		} else if path != test.path {
+			t.Errorf("expected module path %q from %q, but got %q", test.path, test.content, path)
+		}
+	}
+}
+
+func TestNormalizeModulePath(t *testing.T) {
+	tests := []struct {
+		path       string
+		normalized string
+	}{
+		{"example.com/repo", "example.com/repo"},
+		{" https://github.com/User/Repo.git/ ", "github.com/User/Repo"},
+		{"git@github.com:user/repo.git", "github.com/user/repo"},
+		{"GitHub.com/User/Repo", "github.com/User/Repo"},
+		{"Local/Repo", "Local/Repo"},
+	}
+
+	for _, test := range tests {
+		if normalized := NormalizeModulePath(test.path); normalized != test.normalized {
+			t.Errorf("expected %q to be normalized to %q, got %q", test.path, test.normalized, normalized)
+		}
+	}
+}
+
+func TestValidateModulePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		isValid bool
+		message string
+	}{
+		{"example.com/repo", true, ""},
+		{"github.com/User/Repo", true, ""},
+		{"myoperator", true, ""},
+		{"example.com/my repo", false, `did you mean "example.com/my-repo"?`},
+		{"example.com//repo", false, "double slash"},
+		{"net/http", false, "collides with the standard library"},
+	}
+
+	for _, test := range tests {
+		err := ValidateModulePath(test.path)
+		if test.isValid {
+			if err != nil {
+				t.Errorf("expected %q to be valid, got error '%s'", test.path, err)
+			}
+		} else if err == nil {
+			t.Errorf("expected %q to be invalid", test.path)
+		} else if !strings.Contains(err.Error(), test.message) {
+			t.Errorf("expected the error of %q to contain %q, got '%s'", test.path, test.message, err)
+		}
+	}
+}
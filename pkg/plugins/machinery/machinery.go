@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package machinery scaffolds files from the templates and inserters of the model/file package: it renders
+// the templates, inserts the code fragments at the markers of the existing files and writes them, formatting
+// the Go files.
+//
+// It is the part of the plugin SDK that lets the plugins outside of this repository scaffold their files as
+// the plugins of kubebuilder do, and follows its compatibility guarantees, see plugin.SDKVersion.
+package machinery
+
+import (
+	"sigs.k8s.io/kubebuilder/v2/pkg/model"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/v2/pkg/plugins/internal/machinery"
+)
+
+// Scaffold scaffolds the files of a universe
+type Scaffold = machinery.Scaffold
+
+// NewScaffold returns a Scaffold whose universe is transformed by plugins before the files are written
+func NewScaffold(plugins ...model.Plugin) Scaffold {
+	return machinery.NewScaffold(plugins...)
+}
+
+// FileSystem reads and writes the files of a Scaffold, e.g. plugintest.MemFS keeps them in memory
+type FileSystem = filesystem.FileSystem
+
+// NewScaffoldWithFileSystem returns a Scaffold as NewScaffold does, reading and writing the files through fs
+func NewScaffoldWithFileSystem(fs FileSystem, plugins ...model.Plugin) Scaffold {
+	return machinery.NewScaffoldWithFileSystem(fs, plugins...)
+}
+
+// IsFileAlreadyExistsError returns whether err was returned because a file already exists and its template
+// does not allow it to be overwritten nor skipped
+func IsFileAlreadyExistsError(err error) bool {
+	return machinery.IsFileAlreadyExistsError(err)
+}
+
+// IsModelAlreadyExistsError returns whether err was returned because two templates scaffold the same file
+func IsModelAlreadyExistsError(err error) bool {
+	return machinery.IsModelAlreadyExistsError(err)
+}
+
+// IsUnknownIfExistsActionError returns whether err was returned because a template has an unknown
+// file.IfExistsAction
+func IsUnknownIfExistsActionError(err error) bool {
+	return machinery.IsUnknownIfExistsActionError(err)
+}
@@ -45,6 +45,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -58,6 +59,7 @@ type {{ .Resource.Kind }}Reconciler struct {
 	client.Client
 	Log logr.Logger
 	Scheme *runtime.Scheme
+	Recorder record.EventRecorder
 
 	declarative.Reconciler
 }
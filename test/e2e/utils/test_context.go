@@ -36,6 +36,7 @@ type TestContext struct {
 	Version    string
 	Kind       string
 	Resources  string
+	ImageRepo  string
 	ImageName  string
 	BinaryName string
 	Kubectl    *Kubectl
@@ -69,6 +70,7 @@ func NewTestContext(binaryName string, env ...string) (*TestContext, error) {
 		return nil, err
 	}
 
+	imageRepo := "e2e-test/controller-manager"
 	return &TestContext{
 		TestSuffix: testSuffix,
 		Domain:     "example.com" + testSuffix,
@@ -76,7 +78,8 @@ func NewTestContext(binaryName string, env ...string) (*TestContext, error) {
 		Version:    "v1alpha1",
 		Kind:       "Foo" + testSuffix,
 		Resources:  "foo" + testSuffix + "s",
-		ImageName:  "e2e-test/controller-manager:" + testSuffix,
+		ImageRepo:  imageRepo,
+		ImageName:  imageRepo + ":" + testSuffix,
 		CmdContext: cc,
 		Kubectl:    kubectl,
 		K8sVersion: &k8sVersion,
@@ -233,11 +236,16 @@ func (t *TestContext) Destroy() {
 
 // LoadImageToKindCluster loads a local docker image to the kind cluster
 func (t *TestContext) LoadImageToKindCluster() error {
+	return t.LoadImageToKindClusterWithName(t.ImageName)
+}
+
+// LoadImageToKindClusterWithName loads the local docker image with the given name to the kind cluster
+func (t *TestContext) LoadImageToKindClusterWithName(image string) error {
 	cluster := "kind"
 	if v, ok := os.LookupEnv("KIND_CLUSTER"); ok {
 		cluster = v
 	}
-	kindOptions := []string{"load", "docker-image", t.ImageName, "--name", cluster}
+	kindOptions := []string{"load", "docker-image", image, "--name", cluster}
 	cmd := exec.Command("kind", kindOptions...)
 	_, err := t.Run(cmd)
 	return err
@@ -132,6 +132,7 @@ func GenerateV3(kbc *utils.TestContext, crdAndWebhookVersion string) {
 		"--project-version", "3-alpha",
 		"--plugins", "go/v3",
 		"--domain", kbc.Domain,
+		"--image-repo", kbc.ImageRepo,
 		"--fetch-deps=false",
 	)
 	ExpectWithOffset(1, err).NotTo(HaveOccurred())
@@ -191,47 +192,14 @@ Count int `+"`"+`json:"count,omitempty"`+"`"+`
 		fmt.Sprintf("%s_webhook.go", strings.ToLower(kbc.Kind))))
 	ExpectWithOffset(1, err).NotTo(HaveOccurred())
 
-	By("uncomment kustomization.yaml to enable webhook and ca injection")
-	ExpectWithOffset(1, utils.UncommentCode(
-		filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-		"#- ../webhook", "#")).To(Succeed())
-	ExpectWithOffset(1, utils.UncommentCode(
-		filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-		"#- ../certmanager", "#")).To(Succeed())
+	By("uncomment kustomization.yaml to enable the webhook, cert-manager and prometheus components")
 	ExpectWithOffset(1, utils.UncommentCode(
 		filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-		"#- ../prometheus", "#")).To(Succeed())
+		"#- ../components/webhook", "#")).To(Succeed())
 	ExpectWithOffset(1, utils.UncommentCode(
 		filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-		"#- manager_webhook_patch.yaml", "#")).To(Succeed())
+		"#- ../components/certmanager", "#")).To(Succeed())
 	ExpectWithOffset(1, utils.UncommentCode(
 		filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-		"#- webhookcainjection_patch.yaml", "#")).To(Succeed())
-	ExpectWithOffset(1, utils.UncommentCode(filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-		`#- name: CERTIFICATE_NAMESPACE # namespace of the certificate CR
-#  objref:
-#    kind: Certificate
-#    group: cert-manager.io
-#    version: v1
-#    name: serving-cert # this name should match the one in certificate.yaml
-#  fieldref:
-#    fieldpath: metadata.namespace
-#- name: CERTIFICATE_NAME
-#  objref:
-#    kind: Certificate
-#    group: cert-manager.io
-#    version: v1
-#    name: serving-cert # this name should match the one in certificate.yaml
-#- name: SERVICE_NAMESPACE # namespace of the service
-#  objref:
-#    kind: Service
-#    version: v1
-#    name: webhook-service
-#  fieldref:
-#    fieldpath: metadata.namespace
-#- name: SERVICE_NAME
-#  objref:
-#    kind: Service
-#    version: v1
-#    name: webhook-service`, "#")).To(Succeed())
+		"#- ../components/prometheus", "#")).To(Succeed())
 }
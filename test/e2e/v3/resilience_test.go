@@ -0,0 +1,128 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+   http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo" //nolint:golint
+	. "github.com/onsi/gomega" //nolint:golint
+
+	"sigs.k8s.io/kubebuilder/v2/test/e2e/utils"
+)
+
+// RotateCertificate verifies that the webhooks of a project deployed by Run keep working after
+// cert-manager issues a new serving certificate.
+func RotateCertificate(kbc *utils.TestContext) {
+	mwhName := fmt.Sprintf("e2e-%s-mutating-webhook-configuration", kbc.TestSuffix)
+	getCABundle := func() string {
+		caBundle, err := kbc.Kubectl.Get(
+			false,
+			"mutatingwebhookconfigurations.admissionregistration.k8s.io", mwhName,
+			"-o", "go-template={{ range .webhooks }}{{ .clientConfig.caBundle }}{{ end }}")
+		ExpectWithOffset(2, err).NotTo(HaveOccurred())
+		return caBundle
+	}
+	oldCABundle := getCABundle()
+
+	By("deleting the certificate Secret to make cert-manager issue a new certificate")
+	_, err := kbc.Kubectl.Delete(true, "secrets", "webhook-server-cert")
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	By("validating that cert-manager has provisioned the new certificate Secret")
+	EventuallyWithOffset(1, func() error {
+		_, err := kbc.Kubectl.Get(true, "secrets", "webhook-server-cert")
+		return err
+	}, time.Minute, time.Second).Should(Succeed())
+
+	By("validating that the new CA has been injected in the webhooks")
+	EventuallyWithOffset(1, getCABundle, time.Minute, time.Second).ShouldNot(Equal(oldCABundle))
+
+	By("validating that the webhooks are served with the new certificate")
+	// The kubelet may take up to a minute to update the mounted Secret, and the webhook
+	// server reloads the certificate once it has changed on disk.
+	sampleFile := filepath.Join("config", "samples",
+		fmt.Sprintf("%s_%s_%s.yaml", kbc.Group, kbc.Version, strings.ToLower(kbc.Kind)))
+	_, err = kbc.Kubectl.Delete(true, "-f", sampleFile)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	EventuallyWithOffset(1, func() error {
+		_, err := kbc.Kubectl.Apply(true, "-f", sampleFile)
+		return err
+	}, 3*time.Minute, 5*time.Second).Should(Succeed())
+	cnt, err := kbc.Kubectl.Get(true, "-f", sampleFile, "-o", "go-template={{ .spec.count }}")
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	ExpectWithOffset(1, cnt).To(Equal("5"))
+}
+
+// Upgrade verifies that a project deployed by Run can be upgraded by rolling out a new image of
+// the controller-manager while the CRDs and the existing resources are left untouched.
+func Upgrade(kbc *utils.TestContext) {
+	upgradeImage := kbc.ImageName + "-upgrade"
+	deployment := fmt.Sprintf("e2e-%s-controller-manager", kbc.TestSuffix)
+
+	By("building and loading the upgraded controller image")
+	err := kbc.Make("docker-build", "IMG="+upgradeImage)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	defer func() {
+		//nolint:gosec
+		if _, err := kbc.Run(exec.Command("docker", "rmi", "-f", upgradeImage)); err != nil {
+			fmt.Fprintf(GinkgoWriter, "warning: error when removing the upgraded image: %v\n", err)
+		}
+	}()
+	err = kbc.LoadImageToKindClusterWithName(upgradeImage)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	By("rolling out the upgraded controller image without updating the CRDs")
+	_, err = kbc.Kubectl.Command("-n", kbc.Kubectl.Namespace,
+		"set", "image", "deployment/"+deployment, "manager="+upgradeImage)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	_, err = kbc.Kubectl.Command("-n", kbc.Kubectl.Namespace,
+		"rollout", "status", "deployment/"+deployment, "--timeout=3m")
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	By("validating that the upgraded controller-manager pod is running")
+	EventuallyWithOffset(1, func() (string, error) {
+		return kbc.Kubectl.Get(
+			true,
+			"pods", "-l", "control-plane=controller-manager",
+			"-o", "go-template={{ range .items }}{{ if not .metadata.deletionTimestamp }}"+
+				"{{ range .spec.containers }}{{ if eq .name \"manager\" }}{{ .image }}{{ end }}{{ end }}"+
+				"{{ end }}{{ end }}")
+	}, time.Minute, time.Second).Should(Equal(upgradeImage))
+
+	By("validating that the existing resource object is still reconciled")
+	sampleFile := filepath.Join("config", "samples",
+		fmt.Sprintf("%s_%s_%s.yaml", kbc.Group, kbc.Version, strings.ToLower(kbc.Kind)))
+	_, err = kbc.Kubectl.Command("-n", kbc.Kubectl.Namespace,
+		"annotate", "-f", sampleFile, "e2e-upgrade="+kbc.TestSuffix, "--overwrite")
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	EventuallyWithOffset(1, func() string {
+		return curlMetrics(kbc)
+	}, 2*time.Minute, 10*time.Second).Should(MatchRegexp(
+		`controller_runtime_reconcile_total\{controller="%s",result="success"\} [1-9]`,
+		strings.ToLower(kbc.Kind),
+	))
+
+	By("validating that the webhooks are still served")
+	cnt, err := kbc.Kubectl.Get(true, "-f", sampleFile, "-o", "go-template={{ .spec.count }}")
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	ExpectWithOffset(1, cnt).To(Equal("5"))
+}
@@ -0,0 +1,412 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// apidocs generates the reference of the fields of the APIs of the project in the Markdown files of
+// a directory, from their Go types and markers. Each file holds blocks delimited by the following
+// comments, between which apidocs writes a table per version of the kind, listing the fields of its
+// spec with their type, default, validation and description:
+//
+//	<!-- BEGIN apidocs group=ship.example.com kind=Frigate -->
+//	<!-- END apidocs -->
+//
+// The text outside of the blocks is kept. With --check, apidocs fails if the files are not up to date
+// instead of writing them.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/version"
+)
+
+func main() {
+	var dir, apis string
+	var check bool
+	flag.StringVar(&dir, "dir", "docs/api", "directory containing the Markdown files")
+	flag.StringVar(&apis, "apis", "api,apis", "comma-separated directories containing the API packages")
+	flag.BoolVar(&check, "check", false, "fail if the files are not up to date instead of writing them")
+	flag.Parse()
+
+	packages, err := loadPackages(strings.Split(apis, ","))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load the API packages: %v\n", err)
+		os.Exit(1)
+	}
+	outdated, err := generateDir(dir, packages, check)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to generate the API reference: %v\n", err)
+		os.Exit(1)
+	}
+	if check && len(outdated) != 0 {
+		fmt.Fprintf(os.Stderr, "the API reference of %s is not up to date, run \"make api-docs\"\n",
+			strings.Join(outdated, ", "))
+		os.Exit(1)
+	}
+}
+
+// apiPackage is a package of the types of a group version.
+type apiPackage struct {
+	group   string
+	version string
+	types   map[string]*ast.TypeSpec
+	docs    map[string]*ast.CommentGroup
+}
+
+var groupNameRegexp = regexp.MustCompile(`(?m)^//\s*\+groupName=(\S*)\s*$`)
+
+// loadPackages parses the packages found in the directories, skipping the missing ones. The packages
+// without +groupName marker do not define a group version.
+func loadPackages(dirs []string) ([]*apiPackage, error) {
+	var packages []*apiPackage
+	for _, dir := range dirs {
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			if err != nil || !info.IsDir() {
+				return err
+			}
+			pkg, err := loadPackage(path)
+			if err != nil || pkg == nil {
+				return err
+			}
+			packages = append(packages, pkg)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return packages, nil
+}
+
+func loadPackage(dir string) (*apiPackage, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, p := range pkgs {
+		pkg := &apiPackage{
+			version: name,
+			types:   map[string]*ast.TypeSpec{},
+			docs:    map[string]*ast.CommentGroup{},
+		}
+		found := false
+		for _, f := range p.Files {
+			for _, comment := range f.Comments {
+				for _, c := range comment.List {
+					if match := groupNameRegexp.FindStringSubmatch(c.Text); match != nil {
+						pkg.group, found = match[1], true
+					}
+				}
+			}
+			for _, decl := range f.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					pkg.types[typeSpec.Name.Name] = typeSpec
+					pkg.docs[typeSpec.Name.Name] = typeSpec.Doc
+					if typeSpec.Doc == nil && len(gen.Specs) == 1 {
+						pkg.docs[typeSpec.Name.Name] = gen.Doc
+					}
+				}
+			}
+		}
+		if found {
+			return pkg, nil
+		}
+	}
+	return nil, nil
+}
+
+var blockRegexp = regexp.MustCompile(`(?s)(<!-- BEGIN apidocs group=(\S*) kind=(\S+) -->\n).*?(<!-- END apidocs -->)`)
+
+// generateDir generates the blocks of the Markdown files of dir, returning the files that were not up to
+// date. A missing dir holds no file.
+func generateDir(dir string, packages []*apiPackage, check bool) ([]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var outdated []string
+	for _, f := range files {
+		if filepath.Ext(f.Name()) != ".md" {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var generateErr error
+		generated := blockRegexp.ReplaceAllStringFunc(string(content), func(block string) string {
+			match := blockRegexp.FindStringSubmatch(block)
+			tables, err := kindTables(packages, match[2], match[3])
+			if err != nil {
+				generateErr = fmt.Errorf("%s: %v", path, err)
+				return block
+			}
+			return match[1] + tables + match[4]
+		})
+		if generateErr != nil {
+			return nil, generateErr
+		}
+		if generated == string(content) {
+			continue
+		}
+		outdated = append(outdated, path)
+		if !check {
+			if err := ioutil.WriteFile(path, []byte(generated), f.Mode()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return outdated, nil
+}
+
+// kindTables returns the tables of the fields of the spec of kind, one per version of the group.
+func kindTables(packages []*apiPackage, group, kind string) (string, error) {
+	var versions []*apiPackage
+	for _, pkg := range packages {
+		if _, found := pkg.types[kind]; found && pkg.group == group {
+			versions = append(versions, pkg)
+		}
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("kind %s of group %q not found in the API packages", kind, group)
+	}
+	// The most stable versions first
+	sort.Slice(versions, func(i, j int) bool {
+		return version.CompareKubeAwareVersionStrings(versions[i].version, versions[j].version) > 0
+	})
+
+	var b strings.Builder
+	for _, pkg := range versions {
+		fmt.Fprintf(&b, "## %s/%s\n\n", group, pkg.version)
+		spec, found := pkg.types[kind+"Spec"]
+		if !found {
+			fmt.Fprintf(&b, "%s has no spec.\n\n", kind)
+			continue
+		}
+		fields, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			fmt.Fprintf(&b, "%s has no spec.\n\n", kind)
+			continue
+		}
+		var rows [][]string
+		pkg.addRows(&rows, fields, "", map[string]bool{kind + "Spec": true})
+		b.WriteString("| Field | Type | Default | Validation | Description |\n")
+		b.WriteString("| --- | --- | --- | --- | --- |\n")
+		for _, row := range rows {
+			b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		}
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// addRows adds the rows of the fields of st, whose paths start with prefix, recursing into the structs
+// of the package that are not in seen.
+func (pkg *apiPackage) addRows(rows *[][]string, st *ast.StructType, prefix string, seen map[string]bool) {
+	for _, field := range st.Fields.List {
+		name, omitEmpty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		typeName := namedType(field.Type)
+		// The fields of the embedded structs are inlined in their parent
+		if len(field.Names) == 0 && name == "" {
+			if nested, ok := pkg.structType(typeName); ok && !seen[typeName] {
+				seen[typeName] = true
+				pkg.addRows(rows, nested, prefix, seen)
+				delete(seen, typeName)
+			}
+			continue
+		}
+
+		for _, ident := range field.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			fieldName := name
+			if fieldName == "" {
+				fieldName = ident.Name
+			}
+			description, markers := parseComments(field.Doc)
+			if _, found := pkg.types[typeName]; found {
+				_, typeMarkers := parseComments(pkg.docs[typeName])
+				markers = append(typeMarkers, markers...)
+			}
+			defaultValue, validation := describeMarkers(markers, omitEmpty)
+			*rows = append(*rows, []string{
+				"`" + prefix + fieldName + "`",
+				"`" + escape(exprString(field.Type)) + "`",
+				escape(defaultValue),
+				escape(strings.Join(validation, "; ")),
+				escape(description),
+			})
+
+			if nested, ok := pkg.structType(typeName); ok && !seen[typeName] {
+				seen[typeName] = true
+				nestedPrefix := prefix + fieldName + "."
+				if _, isSlice := field.Type.(*ast.ArrayType); isSlice {
+					nestedPrefix = prefix + fieldName + "[]."
+				}
+				pkg.addRows(rows, nested, nestedPrefix, seen)
+				delete(seen, typeName)
+			}
+		}
+	}
+}
+
+// structType returns the struct type of the package named name, if any.
+func (pkg *apiPackage) structType(name string) (*ast.StructType, bool) {
+	spec, found := pkg.types[name]
+	if !found {
+		return nil, false
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	return st, ok
+}
+
+// jsonName returns the name of the json tag of field, and whether it is omitted when empty.
+func jsonName(field *ast.Field) (string, bool) {
+	if field.Tag == nil {
+		return "", false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+	parts := strings.Split(reflect.StructTag(tag).Get("json"), ",")
+	omitEmpty, inline := false, false
+	for _, option := range parts[1:] {
+		omitEmpty = omitEmpty || option == "omitempty"
+		inline = inline || option == "inline"
+	}
+	if inline {
+		return "", omitEmpty
+	}
+	return parts[0], omitEmpty
+}
+
+// namedType returns the name of the type of the package that expr refers to, through pointers, slices
+// and maps.
+func namedType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return namedType(t.X)
+	case *ast.ArrayType:
+		return namedType(t.Elt)
+	case *ast.MapType:
+		return namedType(t.Value)
+	}
+	return ""
+}
+
+func exprString(expr ast.Expr) string {
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, token.NewFileSet(), expr); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// parseComments returns the description and the markers of the comments.
+func parseComments(comments *ast.CommentGroup) (string, []string) {
+	if comments == nil {
+		return "", nil
+	}
+	var description, markers []string
+	for _, c := range comments.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if strings.HasPrefix(text, "+") {
+			markers = append(markers, strings.TrimPrefix(text, "+"))
+		} else if text != "" {
+			description = append(description, text)
+		}
+	}
+	return strings.Join(description, " "), markers
+}
+
+var messageRegexp = regexp.MustCompile(`message="((?:[^"\\]|\\.)*)"`)
+
+// describeMarkers returns the default and the validation of a field from its markers. A field is
+// required unless it is omitted when empty or marked as optional, like in the CRDs of controller-gen.
+func describeMarkers(markers []string, omitEmpty bool) (string, []string) {
+	var defaultValue string
+	var validation []string
+	required := !omitEmpty
+	for _, marker := range markers {
+		switch {
+		case strings.HasPrefix(marker, "kubebuilder:default="):
+			defaultValue = "`" + strings.TrimPrefix(marker, "kubebuilder:default=") + "`"
+		case marker == "optional" || marker == "kubebuilder:validation:Optional":
+			required = false
+		case marker == "kubebuilder:validation:Required":
+			required = true
+		case strings.HasPrefix(marker, "kubebuilder:validation:XValidation:"):
+			rule := strings.TrimPrefix(marker, "kubebuilder:validation:XValidation:")
+			if match := messageRegexp.FindStringSubmatch(rule); match != nil {
+				rule = match[1]
+			}
+			validation = append(validation, "Rule: "+rule)
+		case strings.HasPrefix(marker, "kubebuilder:validation:"):
+			rule := strings.SplitN(strings.TrimPrefix(marker, "kubebuilder:validation:"), "=", 2)
+			if len(rule) == 1 {
+				validation = append(validation, rule[0])
+			} else {
+				validation = append(validation, rule[0]+": "+strings.ReplaceAll(rule[1], ";", ", "))
+			}
+		}
+	}
+	if required {
+		validation = append([]string{"Required"}, validation...)
+	}
+	return defaultValue, validation
+}
+
+// escape escapes the pipes of a table cell.
+func escape(cell string) string {
+	return strings.ReplaceAll(cell, "|", "\\|")
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apiserver serves the resources of the project from an aggregated API server: the
+// kube-apiserver proxies the requests for their API groups, registered by the APIService objects
+// of config/apiservice, to this server, which delegates the authentication and the authorization
+// of the requests to the kube-apiserver. The objects are not stored in etcd but by the storage
+// of the internal/storage package.
+package apiserver
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	genericoptions "k8s.io/apiserver/pkg/server/options"
+
+	shipv1 "sigs.k8s.io/kubebuilder/testdata/project-v3-aggregated-apiserver/api/v1"
+	shipv1alpha1 "sigs.k8s.io/kubebuilder/testdata/project-v3-aggregated-apiserver/api/v1alpha1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-aggregated-apiserver/internal/storage"
+	//+kubebuilder:scaffold:imports
+)
+
+// The API server looks up the namespaces and the admission webhooks to admit the requests.
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;list;watch
+
+var (
+	// Scheme registers the types of the served resources.
+	Scheme = runtime.NewScheme()
+	// Codecs encodes and decodes the objects of the served resources.
+	Codecs = serializer.NewCodecFactory(Scheme)
+)
+
+// Resource is a resource served by the API server.
+type Resource struct {
+	GroupVersion schema.GroupVersion
+	// Resource is the plural name of the resource.
+	Resource string
+	// Object and List are empty objects of the types of the objects and of the lists of the resource.
+	Object, List runtime.Object
+	Namespaced   bool
+}
+
+// Resources are the resources served by the API server.
+var Resources = []Resource{
+	{GroupVersion: shipv1.GroupVersion, Resource: "frigates", Object: &shipv1.Frigate{}, List: &shipv1.FrigateList{}, Namespaced: true},
+	{GroupVersion: shipv1alpha1.GroupVersion, Resource: "cruisers", Object: &shipv1alpha1.Cruiser{}, List: &shipv1alpha1.CruiserList{}, Namespaced: false},
+	//+kubebuilder:scaffold:resource
+}
+
+func init() {
+	// The types of the discovery and of the errors are unversioned
+	metav1.AddToGroupVersion(Scheme, schema.GroupVersion{Version: "v1"})
+	Scheme.AddUnversionedTypes(schema.GroupVersion{Version: "v1"},
+		&metav1.Status{}, &metav1.APIVersions{}, &metav1.APIGroupList{}, &metav1.APIGroup{}, &metav1.APIResourceList{})
+
+	// A kind is served in a single version, which is also its internal version: the API server converts
+	// the objects to the internal version of their group to handle them.
+	for _, resource := range Resources {
+		internal := schema.GroupVersion{Group: resource.GroupVersion.Group, Version: runtime.APIVersionInternal}
+		Scheme.AddKnownTypes(resource.GroupVersion, resource.Object, resource.List)
+		Scheme.AddKnownTypes(internal, resource.Object, resource.List)
+		metav1.AddToGroupVersion(Scheme, resource.GroupVersion)
+	}
+}
+
+// Options are the options of the API server, set by the flags of the command line.
+type Options struct {
+	// RecommendedOptions are the options of a generic API server, without the options of etcd.
+	RecommendedOptions *genericoptions.RecommendedOptions
+}
+
+// NewOptions returns the default options of the API server, which serves on the port 8443 with a
+// self-signed certificate, kept in memory, unless a certificate is provided.
+func NewOptions() *Options {
+	options := genericoptions.NewRecommendedOptions("", Codecs.LegacyCodec())
+	options.Etcd = nil
+	options.SecureServing.BindPort = 8443
+	options.SecureServing.ServerCert.CertDirectory = ""
+	return &Options{RecommendedOptions: options}
+}
+
+// AddFlags adds the flags of the options to fs.
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.RecommendedOptions.AddFlags(fs)
+}
+
+// Run runs the API server until stopCh is closed.
+func (o *Options) Run(stopCh <-chan struct{}) error {
+	if errs := o.RecommendedOptions.Validate(); len(errs) != 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	if err := o.RecommendedOptions.SecureServing.MaybeDefaultWithSelfSignedCerts(
+		"localhost", nil, []net.IP{net.ParseIP("127.0.0.1")}); err != nil {
+		return fmt.Errorf("unable to create the self-signed certificate: %w", err)
+	}
+
+	config := genericapiserver.NewRecommendedConfig(Codecs)
+	if err := o.RecommendedOptions.ApplyTo(config); err != nil {
+		return err
+	}
+	server, err := config.Complete().New("project-v3-aggregated-apiserver-apiserver", genericapiserver.NewEmptyDelegate())
+	if err != nil {
+		return err
+	}
+
+	for _, group := range apiGroups() {
+		if err := server.InstallAPIGroup(group); err != nil {
+			return err
+		}
+	}
+	return server.PrepareRun().Run(stopCh)
+}
+
+// apiGroups returns the API groups of the served resources, with the storage of their resources.
+func apiGroups() []*genericapiserver.APIGroupInfo {
+	var groups []*genericapiserver.APIGroupInfo
+	groupsByName := make(map[string]*genericapiserver.APIGroupInfo)
+	for _, resource := range Resources {
+		group, found := groupsByName[resource.GroupVersion.Group]
+		if !found {
+			info := genericapiserver.NewDefaultAPIGroupInfo(resource.GroupVersion.Group, Scheme, metav1.ParameterCodec, Codecs)
+			group = &info
+			groupsByName[resource.GroupVersion.Group] = group
+			groups = append(groups, group)
+		}
+
+		version := resource.GroupVersion.Version
+		if group.VersionedResourcesStorageMap[version] == nil {
+			group.VersionedResourcesStorageMap[version] = make(map[string]rest.Storage)
+		}
+		groupResource := schema.GroupResource{Group: resource.GroupVersion.Group, Resource: resource.Resource}
+		group.VersionedResourcesStorageMap[version][resource.Resource] =
+			storage.NewMemory(groupResource, resource.Object, resource.List, resource.Namespaced)
+	}
+	return groups
+}
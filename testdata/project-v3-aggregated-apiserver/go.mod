@@ -0,0 +1,13 @@
+module sigs.k8s.io/kubebuilder/testdata/project-v3-aggregated-apiserver
+
+go 1.15
+
+require (
+	github.com/spf13/pflag v1.0.5
+	k8s.io/apimachinery v0.19.2
+	k8s.io/apiserver v0.19.2
+	k8s.io/klog/v2 v2.2.0
+	sigs.k8s.io/controller-runtime v0.7.0
+)
+
+replace github.com/googleapis/gnostic => github.com/googleapis/gnostic v0.4.1
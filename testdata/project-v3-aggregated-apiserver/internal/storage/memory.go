@@ -0,0 +1,335 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package storage keeps the objects of the resources served by the aggregated API server.
+//
+// Memory keeps them in memory: they are lost when the API server restarts and are not shared
+// between its replicas. It suits the resources whose objects are computed from other sources,
+// and is a starting point for a storage backed by the system that holds the objects, which
+// implements the same interfaces of k8s.io/apiserver/pkg/registry/rest.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/watch"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage/names"
+)
+
+var (
+	_ rest.Scoper          = &Memory{}
+	_ rest.Getter          = &Memory{}
+	_ rest.Lister          = &Memory{}
+	_ rest.Creater         = &Memory{}
+	_ rest.Updater         = &Memory{}
+	_ rest.GracefulDeleter = &Memory{}
+	_ rest.Watcher         = &Memory{}
+)
+
+// watchQueueLength is the number of events queued for a watcher, which is stopped when it falls further
+// behind so that its client lists the objects again.
+const watchQueueLength = 100
+
+// Memory keeps the objects of a resource in memory.
+//
+// The resource versions of the objects only allow detecting conflicting updates: a watch starts
+// from the current objects, whatever the resource version it requests. Finalizers are ignored.
+type Memory struct {
+	rest.TableConvertor
+
+	resource   schema.GroupResource
+	object     runtime.Object
+	list       runtime.Object
+	namespaced bool
+
+	lock        sync.RWMutex
+	objects     map[string]runtime.Object
+	version     uint64
+	watchers    map[int]*watcher
+	nextWatcher int
+}
+
+type watcher struct {
+	*watch.ProxyWatcher
+	events    chan watch.Event
+	namespace string
+	selector  labels.Selector
+}
+
+// NewMemory returns the storage of resource, whose objects and lists are of the types of object and list.
+func NewMemory(resource schema.GroupResource, object, list runtime.Object, namespaced bool) *Memory {
+	return &Memory{
+		TableConvertor: rest.NewDefaultTableConvertor(resource),
+		resource:       resource,
+		object:         object,
+		list:           list,
+		namespaced:     namespaced,
+		objects:        make(map[string]runtime.Object),
+		watchers:       make(map[int]*watcher),
+	}
+}
+
+// New implements rest.Storage
+func (m *Memory) New() runtime.Object {
+	return m.object.DeepCopyObject()
+}
+
+// NewList implements rest.Lister
+func (m *Memory) NewList() runtime.Object {
+	return m.list.DeepCopyObject()
+}
+
+// NamespaceScoped implements rest.Scoper
+func (m *Memory) NamespaceScoped() bool {
+	return m.namespaced
+}
+
+// Get implements rest.Getter
+func (m *Memory) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	obj, found := m.objects[m.key(genericapirequest.NamespaceValue(ctx), name)]
+	if !found {
+		return nil, apierrors.NewNotFound(m.resource, name)
+	}
+	return obj.DeepCopyObject(), nil
+}
+
+// List implements rest.Lister
+func (m *Memory) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	namespace, selector := genericapirequest.NamespaceValue(ctx), labelSelector(options)
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	keys := make([]string, 0, len(m.objects))
+	for key := range m.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := make([]runtime.Object, 0, len(keys))
+	for _, key := range keys {
+		if obj := m.objects[key]; matches(obj, namespace, selector) {
+			items = append(items, obj.DeepCopyObject())
+		}
+	}
+
+	list := m.NewList()
+	if err := meta.SetList(list, items); err != nil {
+		return nil, err
+	}
+	listMeta, err := meta.ListAccessor(list)
+	if err != nil {
+		return nil, err
+	}
+	listMeta.SetResourceVersion(strconv.FormatUint(m.version, 10))
+	return list, nil
+}
+
+// Create implements rest.Creater
+func (m *Memory) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc,
+	_ *metav1.CreateOptions) (runtime.Object, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if m.namespaced {
+		accessor.SetNamespace(genericapirequest.NamespaceValue(ctx))
+	}
+	if accessor.GetName() == "" && accessor.GetGenerateName() != "" {
+		accessor.SetName(names.SimpleNameGenerator.GenerateName(accessor.GetGenerateName()))
+	}
+	if accessor.GetName() == "" {
+		return nil, apierrors.NewBadRequest("name or generateName is required")
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := m.key(accessor.GetNamespace(), accessor.GetName())
+	if _, found := m.objects[key]; found {
+		return nil, apierrors.NewAlreadyExists(m.resource, accessor.GetName())
+	}
+	accessor.SetUID(uuid.NewUUID())
+	accessor.SetCreationTimestamp(metav1.Now())
+	accessor.SetGeneration(1)
+	m.store(key, obj, watch.Added)
+	return obj, nil
+}
+
+// Update implements rest.Updater, the objects are not created by updates
+func (m *Memory) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo,
+	_ rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, _ bool,
+	_ *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := m.key(genericapirequest.NamespaceValue(ctx), name)
+	old, found := m.objects[key]
+	if !found {
+		return nil, false, apierrors.NewNotFound(m.resource, name)
+	}
+	obj, err := objInfo.UpdatedObject(ctx, old.DeepCopyObject())
+	if err != nil {
+		return nil, false, err
+	}
+	if updateValidation != nil {
+		if err := updateValidation(ctx, obj, old); err != nil {
+			return nil, false, err
+		}
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	oldAccessor, err := meta.Accessor(old)
+	if err != nil {
+		return nil, false, err
+	}
+	if version := accessor.GetResourceVersion(); version != "" && version != oldAccessor.GetResourceVersion() {
+		return nil, false, apierrors.NewConflict(m.resource, name,
+			fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+	}
+	accessor.SetNamespace(oldAccessor.GetNamespace())
+	accessor.SetName(oldAccessor.GetName())
+	accessor.SetUID(oldAccessor.GetUID())
+	accessor.SetCreationTimestamp(oldAccessor.GetCreationTimestamp())
+	accessor.SetGeneration(oldAccessor.GetGeneration())
+	m.store(key, obj, watch.Modified)
+	return obj, false, nil
+}
+
+// Delete implements rest.GracefulDeleter, the objects are deleted immediately
+func (m *Memory) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc,
+	_ *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := m.key(genericapirequest.NamespaceValue(ctx), name)
+	old, found := m.objects[key]
+	if !found {
+		return nil, false, apierrors.NewNotFound(m.resource, name)
+	}
+	if deleteValidation != nil {
+		if err := deleteValidation(ctx, old); err != nil {
+			return nil, false, err
+		}
+	}
+
+	obj := old.DeepCopyObject()
+	m.store(key, obj, watch.Deleted)
+	delete(m.objects, key)
+	return obj, true, nil
+}
+
+// Watch implements rest.Watcher
+func (m *Memory) Watch(ctx context.Context, options *metainternalversion.ListOptions) (watch.Interface, error) {
+	events := make(chan watch.Event, watchQueueLength)
+	w := &watcher{
+		ProxyWatcher: watch.NewProxyWatcher(events),
+		events:       events,
+		namespace:    genericapirequest.NamespaceValue(ctx),
+		selector:     labelSelector(options),
+	}
+
+	m.lock.Lock()
+	id := m.nextWatcher
+	m.nextWatcher++
+	m.watchers[id] = w
+	m.lock.Unlock()
+
+	go func() {
+		<-w.StopChan()
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		if _, found := m.watchers[id]; found {
+			delete(m.watchers, id)
+			close(w.events)
+		}
+	}()
+	return w, nil
+}
+
+// store records obj under key with a new resource version and sends the event of its change to the
+// watchers, m.lock must be held
+func (m *Memory) store(key string, obj runtime.Object, eventType watch.EventType) {
+	m.version++
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetResourceVersion(strconv.FormatUint(m.version, 10))
+	}
+	if eventType != watch.Deleted {
+		m.objects[key] = obj.DeepCopyObject()
+	}
+
+	for id, w := range m.watchers {
+		if !matches(obj, w.namespace, w.selector) {
+			continue
+		}
+		select {
+		case w.events <- watch.Event{Type: eventType, Object: obj.DeepCopyObject()}:
+		default:
+			// The watcher fell behind: end its watch so that its client lists the objects again
+			delete(m.watchers, id)
+			close(w.events)
+		}
+	}
+}
+
+func (m *Memory) key(namespace, name string) string {
+	if m.namespaced {
+		return namespace + "/" + name
+	}
+	return name
+}
+
+// matches returns true if obj is in namespace, any namespace if empty, and its labels match selector.
+func matches(obj runtime.Object, namespace string, selector labels.Selector) bool {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	return (namespace == "" || accessor.GetNamespace() == namespace) &&
+		selector.Matches(labels.Set(accessor.GetLabels()))
+}
+
+func labelSelector(options *metainternalversion.ListOptions) labels.Selector {
+	if options == nil || options.LabelSelector == nil {
+		return labels.Everything()
+	}
+	return options.LabelSelector
+}
@@ -20,6 +20,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	//+kubebuilder:scaffold:imports
 )
 
 // log is for logging in this package.
@@ -43,3 +44,5 @@ func (r *Admiral) Default() {
 
 	// TODO(user): fill in your defaulting logic.
 }
+
+//+kubebuilder:scaffold:webhooks
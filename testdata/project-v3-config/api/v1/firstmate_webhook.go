@@ -19,6 +19,7 @@ package v1
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	//+kubebuilder:scaffold:imports
 )
 
 // log is for logging in this package.
@@ -31,3 +32,5 @@ func (r *FirstMate) SetupWebhookWithManager(mgr ctrl.Manager) error {
 }
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+
+//+kubebuilder:scaffold:webhooks
@@ -21,22 +21,27 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v3-config/api/v1"
+	reconcileerrors "sigs.k8s.io/kubebuilder/testdata/project-v3-config/internal/errors"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-config/internal/events"
 )
 
 // AdmiralReconciler reconciles a Admiral object
 type AdmiralReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=crew.testproject.org,resources=admirals,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=crew.testproject.org,resources=admirals/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=crew.testproject.org,resources=admirals/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -45,13 +50,46 @@ type AdmiralReconciler struct {
 // perform operations to make the cluster state reflect the state specified by
 // the user.
 //
+// Long-running work must stop as soon as ctx is done, which happens when the
+// manager is shutting down, so that it finishes within the graceful shutdown
+// timeout. The internal/checkpoint package helps splitting such work into steps
+// that are resumed by the next reconciliation.
+//
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *AdmiralReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = r.Log.WithValues("admiral", req.NamespacedName)
 
+	var obj crewv1.Admiral
+	if err := r.Get(ctx, req.NamespacedName, &obj); err != nil {
+		// The object may have been deleted after the reconcile request was queued.
+		return reconcileerrors.Result(client.IgnoreNotFound(err))
+	}
+
 	// your logic here
 
+	// Write the status of the Admiral with status.Patch, which only patches the changes
+	// of the status and applies them again to a fresh copy of the Admiral when they
+	// conflict with the changes of another writer, e.g.:
+	//	if _, err := status.Patch(ctx, r.Client, &obj, func() error {
+	//		status.SetCondition(&obj.Status.Conditions, metav1.Condition{Type: "Available",
+	//			Status: metav1.ConditionTrue, Reason: "Available", ObservedGeneration: obj.Generation})
+	//		return nil
+	//	}); err != nil {
+	//		return reconcileerrors.Result(err)
+	//	}
+
+	// Return the errors of the reconciliation through reconcileerrors.Result: the terminal ones,
+	// e.g. of an invalid spec, are not requeued until the Admiral changes, and the transient
+	// ones are requeued with a backoff, or after the delay of reconcileerrors.RequeueAfter. Record
+	// events about the outcome of the reconciliation with the helpers of the internal/events package,
+	// e.g. when it fails for good:
+	//	if err := validate(&obj); err != nil {
+	//		events.Warning(r.Recorder, &obj, events.ReasonReconcileError, err)
+	//		return reconcileerrors.Result(reconcileerrors.Terminal(err))
+	//	}
+	events.Normal(r.Recorder, &obj, events.ReasonReconciled, "Admiral %s reconciled", req.Name)
+
 	return ctrl.Result{}, nil
 }
 
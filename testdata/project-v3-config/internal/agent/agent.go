@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package agent runs the manager either as the manager of the cluster, which reconciles the
+// objects of every namespace, or as an agent, which reconciles the objects of its own namespace.
+// Both are built from the same code, the mode is selected by the --mode flag:
+//
+//	--mode=agent
+//
+// The agents are deployed by config/agent in each namespace they reconcile, with the permissions
+// of the manager granted in their namespace only. They cache the objects of their namespace and
+// run without leader election: a namespace is reconciled by a single agent, or by one agent per
+// node when they are run by a DaemonSet.
+package agent
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// ModeManager reconciles the objects of every namespace, it is the default mode.
+	ModeManager = "manager"
+	// ModeAgent reconciles the objects of the namespace of the agent.
+	ModeAgent = "agent"
+
+	// NamespaceEnvVar is the environment variable holding the namespace of the agent, which is
+	// set to the namespace of its Pod.
+	NamespaceEnvVar = "POD_NAMESPACE"
+	// NodeNameEnvVar is the environment variable holding the node of the agents run by a
+	// DaemonSet.
+	NodeNameEnvVar = "NODE_NAME"
+)
+
+// Mode is the mode of the manager, ModeManager or ModeAgent. The zero value is ModeManager.
+type Mode struct {
+	agent     bool
+	namespace string
+}
+
+// AddFlag adds the --mode flag setting m to fs.
+func (m *Mode) AddFlag(fs *flag.FlagSet) {
+	fs.Var(m, "mode", fmt.Sprintf("The mode of the manager: %s reconciles the objects of every "+
+		"namespace, %s reconciles the objects of the namespace set by the %s environment variable, "+
+		"without leader election.", ModeManager, ModeAgent, NamespaceEnvVar))
+}
+
+// String implements flag.Value
+func (m *Mode) String() string {
+	if m.agent {
+		return ModeAgent
+	}
+	return ModeManager
+}
+
+// Set implements flag.Value, reading the namespace of the agent from NamespaceEnvVar.
+func (m *Mode) Set(value string) error {
+	switch value {
+	case ModeManager:
+		*m = Mode{}
+	case ModeAgent:
+		namespace := os.Getenv(NamespaceEnvVar)
+		if namespace == "" {
+			return fmt.Errorf("the %s environment variable must be set to the namespace of the agent",
+				NamespaceEnvVar)
+		}
+		*m = Mode{agent: true, namespace: namespace}
+	default:
+		return fmt.Errorf("the mode must be %s or %s, got %q", ModeManager, ModeAgent, value)
+	}
+	return nil
+}
+
+// IsAgent returns true if the manager runs as an agent.
+func (m *Mode) IsAgent() bool {
+	return m.agent
+}
+
+// Namespace returns the namespace reconciled by the agent, or an empty string for every namespace.
+func (m *Mode) Namespace() string {
+	return m.namespace
+}
+
+// NodeName returns the node of the agent when it is run by a DaemonSet, or an empty string. The
+// agents of the nodes all cache the objects of their namespace: the controllers only reconcile the
+// objects of their node, e.g. the Pods whose spec.nodeName is NodeName.
+func (m *Mode) NodeName() string {
+	if !m.agent {
+		return ""
+	}
+	return os.Getenv(NodeNameEnvVar)
+}
+
+// Options returns options restricted to the namespace of the agent, without leader election, when the
+// manager runs as an agent, and options unchanged otherwise.
+func (m *Mode) Options(options ctrl.Options) ctrl.Options {
+	if m.agent {
+		options.Namespace = m.namespace
+		options.LeaderElection = false
+	}
+	return options
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestMode(t *testing.T) {
+	defer os.Unsetenv(NamespaceEnvVar)
+
+	var mode Mode
+	fs := flag.NewFlagSet("manager", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	mode.AddFlag(fs)
+	options := mode.Options(ctrl.Options{LeaderElection: true})
+	if mode.IsAgent() || options.Namespace != "" || !options.LeaderElection {
+		t.Errorf("expected the manager mode by default, got %s with %+v", mode.String(), options)
+	}
+
+	os.Unsetenv(NamespaceEnvVar)
+	if err := fs.Parse([]string{"--mode=agent"}); err == nil {
+		t.Error("expected the agent mode to require the namespace of the agent")
+	}
+
+	os.Setenv(NamespaceEnvVar, "tenant")
+	if err := fs.Parse([]string{"--mode=agent"}); err != nil {
+		t.Fatal(err)
+	}
+	options = mode.Options(ctrl.Options{LeaderElection: true})
+	if !mode.IsAgent() || options.Namespace != "tenant" || options.LeaderElection {
+		t.Errorf("expected the agent of the tenant namespace without leader election, got %+v", options)
+	}
+
+	if err := fs.Parse([]string{"--mode=standalone"}); err == nil {
+		t.Error("expected an unknown mode to be rejected")
+	}
+}
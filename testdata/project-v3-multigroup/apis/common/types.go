@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package common contains the spec fragments shared by the kinds of the project. Embedding them in the specs
+// of the kinds validates and documents the same fields the same way across the kinds.
+//+kubebuilder:object:generate=true
+package common
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ImageSpec is the image of a container.
+type ImageSpec struct {
+	// Repository is the repository of the image, e.g. gcr.io/example/app.
+	//+kubebuilder:validation:MinLength=1
+	Repository string `json:"repository"`
+
+	// Tag is the tag of the image, ignored if Digest is set.
+	//+optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest is the sha256 digest of the image, pinning its content.
+	//+kubebuilder:validation:Pattern="^sha256:[a-f0-9]{64}$"
+	//+optional
+	Digest string `json:"digest,omitempty"`
+
+	// PullPolicy is the pull policy of the image.
+	//+kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	//+optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+}
+
+// Reference returns the reference of the image, pinned by its digest if set.
+func (s ImageSpec) Reference() string {
+	if s.Digest != "" {
+		return s.Repository + "@" + s.Digest
+	}
+	if s.Tag != "" {
+		return s.Repository + ":" + s.Tag
+	}
+	return s.Repository
+}
+
+// ContainerSpec is the image and the compute resources of a container.
+type ContainerSpec struct {
+	// Image is the image of the container.
+	Image ImageSpec `json:"image"`
+
+	// Resources are the compute resources required by the container.
+	//+optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// LocalObjectReference refers to an object in the namespace of the referrer.
+type LocalObjectReference struct {
+	// Name is the name of the object.
+	//+kubebuilder:validation:MinLength=1
+	//+kubebuilder:validation:MaxLength=253
+	//+kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?([.][a-z0-9]([-a-z0-9]*[a-z0-9])?)*$"
+	Name string `json:"name"`
+}
+
+// NamespacedObjectReference refers to an object in any namespace.
+type NamespacedObjectReference struct {
+	// Name is the name of the object.
+	//+kubebuilder:validation:MinLength=1
+	//+kubebuilder:validation:MaxLength=253
+	//+kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?([.][a-z0-9]([-a-z0-9]*[a-z0-9])?)*$"
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the object, the namespace of the referrer if empty.
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
+	//+optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// SecretKeySelector selects a key of a Secret in the namespace of the referrer.
+type SecretKeySelector struct {
+	LocalObjectReference `json:",inline"`
+
+	// Key is the key of the Secret.
+	//+kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+}
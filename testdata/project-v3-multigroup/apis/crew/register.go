@@ -0,0 +1,35 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crew
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+
+	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/apis/crew/v1"
+	//+kubebuilder:scaffold:imports
+)
+
+var (
+	// SchemeBuilder adds the types of every version of the group to a scheme
+	SchemeBuilder = runtime.NewSchemeBuilder(
+		crewv1.AddToScheme,
+	//+kubebuilder:scaffold:versions
+	)
+
+	// AddToScheme adds the types of every version of the group to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -38,7 +38,11 @@ type HealthCheckPolicyStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
+// Enables the status subresource of the CRD.
+// See https://book.kubebuilder.io/reference/markers/crd.html
 //+kubebuilder:subresource:status
 
 // HealthCheckPolicy is the Schema for the healthcheckpolicies API
@@ -50,6 +54,8 @@ type HealthCheckPolicy struct {
 	Status HealthCheckPolicyStatus `json:"status,omitempty"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
 
 // HealthCheckPolicyList contains a list of HealthCheckPolicy
@@ -18,6 +18,8 @@ package v1beta1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/apis/common"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -29,7 +31,13 @@ type KrakenSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Foo is an example field of Kraken. Edit kraken_types.go to remove/update
+	//+kubebuilder:validation:XValidation:rule="self == oldSelf",message="foo is immutable"
 	Foo string `json:"foo,omitempty"`
+
+	// Container is an example field of Kraken reusing the common types of the project, which
+	// validate the image and the compute resources of a container the same way for all the kinds.
+	//+optional
+	Container *common.ContainerSpec `json:"container,omitempty"`
 }
 
 // KrakenStatus defines the observed state of Kraken
@@ -38,7 +46,11 @@ type KrakenStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
+// Enables the status subresource of the CRD.
+// See https://book.kubebuilder.io/reference/markers/crd.html
 //+kubebuilder:subresource:status
 
 // Kraken is the Schema for the krakens API
@@ -50,6 +62,8 @@ type Kraken struct {
 	Status KrakenStatus `json:"status,omitempty"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
 
 // KrakenList contains a list of Kraken
@@ -36,10 +36,27 @@ type LeviathanSpec struct {
 type LeviathanStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Conditions are the observations of the state of the Leviathan, whose Ready and Paused
+	// conditions are maintained by the internal/readiness and internal/pause packages.
+	//+listType=map
+	//+listMapKey=type
+	//+patchStrategy=merge
+	//+patchMergeKey=type
+	//+optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
+// Enables the status subresource of the CRD.
+// See https://book.kubebuilder.io/reference/markers/crd.html
 //+kubebuilder:subresource:status
+// Adds a column to the output of kubectl get.
+// See https://book.kubebuilder.io/reference/markers/crd.html
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+//+kubebuilder:printcolumn:name="Paused",type=string,priority=1,JSONPath=".status.conditions[?(@.type==\"Paused\")].status"
 
 // Leviathan is the Schema for the leviathans API
 type Leviathan struct {
@@ -50,6 +67,8 @@ type Leviathan struct {
 	Status LeviathanStatus `json:"status,omitempty"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
 
 // LeviathanList contains a list of Leviathan
@@ -17,7 +17,11 @@ limitations under the License.
 package v1
 
 import (
+	"encoding/json"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -30,16 +34,64 @@ type DestroyerSpec struct {
 
 	// Foo is an example field of Destroyer. Edit destroyer_types.go to remove/update
 	Foo string `json:"foo,omitempty"`
+
+	// Values is an example field of Destroyer holding an arbitrary JSON object, e.g. the values
+	// passed through to a template. The API server preserves its fields as is: they are neither pruned,
+	// validated nor defaulted by the CRD, validate them in the validating webhook. Read it with DecodeValues.
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+optional
+	Values *runtime.RawExtension `json:"values,omitempty"`
+
+	// Template is an example field of Destroyer holding a Kubernetes object, e.g. the template of
+	// an object created by the controller. The API server validates its apiVersion, kind and metadata, and
+	// preserves its other fields as is: they are neither pruned, validated nor defaulted by the CRD, validate
+	// them in the validating webhook. Read it with TemplateObject.
+	//+kubebuilder:validation:EmbeddedResource
+	//+kubebuilder:pruning:PreserveUnknownFields
+	//+optional
+	Template *runtime.RawExtension `json:"template,omitempty"`
+}
+
+// DecodeValues decodes the object of the values field into the value pointed to by v, leaving it
+// unchanged if the field is not set.
+func (s *DestroyerSpec) DecodeValues(v interface{}) error {
+	if s.Values == nil || len(s.Values.Raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(s.Values.Raw, v)
+}
+
+// TemplateObject returns the object of the template field, nil if it is not set.
+func (s *DestroyerSpec) TemplateObject() (*unstructured.Unstructured, error) {
+	if s.Template == nil || len(s.Template.Raw) == 0 {
+		return nil, nil
+	}
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(s.Template.Raw, &obj.Object); err != nil {
+		return nil, err
+	}
+	return obj, nil
 }
 
 // DestroyerStatus defines the observed state of Destroyer
 type DestroyerStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// NextReconcileTime is the time of the next periodic reconciliation of the Destroyer, which
+	// its controller reconciles again after a period even if nothing changed.
+	//+optional
+	NextReconcileTime *metav1.Time `json:"nextReconcileTime,omitempty"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
+// Enables the status subresource of the CRD.
+// See https://book.kubebuilder.io/reference/markers/crd.html
 //+kubebuilder:subresource:status
+// Configures the resource of the CRD, e.g. its scope.
+// See https://book.kubebuilder.io/reference/markers/crd.html
 //+kubebuilder:resource:scope=Cluster
 
 // Destroyer is the Schema for the destroyers API
@@ -51,6 +103,8 @@ type Destroyer struct {
 	Status DestroyerStatus `json:"status,omitempty"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
 
 // DestroyerList contains a list of Destroyer
@@ -17,9 +17,14 @@ limitations under the License.
 package v1
 
 import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	//+kubebuilder:scaffold:imports
 )
 
 // log is for logging in this package.
@@ -43,3 +48,47 @@ func (r *Destroyer) Default() {
 
 	// TODO(user): fill in your defaulting logic.
 }
+
+// TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
+//+kubebuilder:webhook:path=/validate-ship-testproject-org-v1-destroyer,mutating=false,failurePolicy=fail,sideEffects=None,groups=ship.testproject.org,resources=destroyers,verbs=create;update,versions=v1,name=vdestroyer.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Validator = &Destroyer{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Destroyer) ValidateCreate() error {
+	destroyerlog.Info("validate create", "name", r.Name)
+
+	// TODO(user): fill in your validation logic upon object creation.
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Destroyer) ValidateUpdate(old runtime.Object) error {
+	destroyerlog.Info("validate update", "name", r.Name)
+
+	// TODO(user): fill in your validation logic upon object update.
+	return r.validateImmutableFields(old.(*Destroyer))
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Destroyer) ValidateDelete() error {
+	destroyerlog.Info("validate delete", "name", r.Name)
+
+	// TODO(user): fill in your validation logic upon object deletion.
+	return nil
+}
+
+// validateImmutableFields rejects the updates of the immutable fields of the spec
+func (r *Destroyer) validateImmutableFields(old *Destroyer) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	allErrs = append(allErrs,
+		apivalidation.ValidateImmutableField(r.Spec.Foo, old.Spec.Foo, specPath.Child("foo"))...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("Destroyer").GroupKind(), r.Name, allErrs)
+}
+
+//+kubebuilder:scaffold:webhooks
@@ -30,16 +30,36 @@ type FrigateSpec struct {
 
 	// Foo is an example field of Frigate. Edit frigate_types.go to remove/update
 	Foo string `json:"foo,omitempty"`
+
+	// Replicas is the desired number of replicas of the Frigate, set by kubectl scale and the
+	// HorizontalPodAutoscaler through the scale subresource.
+	//+kubebuilder:validation:Minimum=0
+	//+optional
+	Replicas *int32 `json:"replicas,omitempty"`
 }
 
 // FrigateStatus defines the observed state of Frigate
 type FrigateStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Replicas is the observed number of replicas of the Frigate.
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Selector is the label selector of the pods of the Frigate in its string form, which the
+	// HorizontalPodAutoscaler uses to find their metrics. Set it with SetSelector.
+	Selector string `json:"selector,omitempty"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
+// Enables the status subresource of the CRD.
+// See https://book.kubebuilder.io/reference/markers/crd.html
 //+kubebuilder:subresource:status
+// Enables the scale subresource of the CRD.
+// See https://book.kubebuilder.io/reference/markers/crd.html
+//+kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 
 // Frigate is the Schema for the frigates API
 type Frigate struct {
@@ -50,6 +70,8 @@ type Frigate struct {
 	Status FrigateStatus `json:"status,omitempty"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
 
 // FrigateList contains a list of Frigate
@@ -59,6 +81,17 @@ type FrigateList struct {
 	Items           []Frigate `json:"items"`
 }
 
+// SetSelector records the label selector of the pods of the Frigate in its status, in the string
+// form served by the scale subresource.
+func (r *Frigate) SetSelector(selector *metav1.LabelSelector) error {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return err
+	}
+	r.Status.Selector = s.String()
+	return nil
+}
+
 func init() {
 	SchemeBuilder.Register(&Frigate{}, &FrigateList{})
 }
@@ -18,6 +18,7 @@ package v2alpha1
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -30,6 +31,89 @@ type CruiserSpec struct {
 
 	// Foo is an example field of Cruiser. Edit cruiser_types.go to remove/update
 	Foo string `json:"foo,omitempty"`
+
+	// Source is an example discriminated union of Cruiser, whose type names the only one of
+	// its members that is set.
+	//+optional
+	Source *CruiserSource `json:"source,omitempty"`
+}
+
+// CruiserSource is an example discriminated union: exactly one of its members is set, the one
+// named by its type. It is validated by the CEL validation rules of the CRD, and by the Validate method
+// which the validating webhook calls on the clusters that do not enforce them.
+//+union
+//+kubebuilder:validation:XValidation:rule="[has(self.git), has(self.configMap)].exists_one(m, m)",message="exactly one of git and configMap must be set"
+//+kubebuilder:validation:XValidation:rule="self.type == 'Git' ? has(self.git) : has(self.configMap)",message="type must name the member that is set"
+type CruiserSource struct {
+	// Type names the member of the union that is set.
+	//+unionDiscriminator
+	//+kubebuilder:validation:Enum=Git;ConfigMap
+	Type CruiserSourceType `json:"type"`
+
+	// Git is the member of the union set when the type is Git.
+	//+optional
+	Git *CruiserGitSource `json:"git,omitempty"`
+
+	// ConfigMap is the member of the union set when the type is ConfigMap.
+	//+optional
+	ConfigMap *CruiserConfigMapSource `json:"configMap,omitempty"`
+}
+
+// CruiserSourceType names a member of CruiserSource.
+type CruiserSourceType string
+
+const (
+	// CruiserSourceTypeGit names the Git member of CruiserSource.
+	CruiserSourceTypeGit CruiserSourceType = "Git"
+	// CruiserSourceTypeConfigMap names the ConfigMap member of CruiserSource.
+	CruiserSourceTypeConfigMap CruiserSourceType = "ConfigMap"
+)
+
+// CruiserGitSource is a Git repository.
+type CruiserGitSource struct {
+	// URL is the URL of the repository.
+	//+kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+}
+
+// CruiserConfigMapSource is a ConfigMap of the namespace of the Cruiser.
+type CruiserConfigMapSource struct {
+	// Name is the name of the ConfigMap.
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// Validate returns the errors of the union at path, the ones of its CEL validation rules: exactly one of its
+// members must be set, the one named by its type. Keep them in sync when adding members.
+func (s *CruiserSource) Validate(path *field.Path) field.ErrorList {
+	if s == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	members := map[CruiserSourceType]bool{
+		CruiserSourceTypeGit:       s.Git != nil,
+		CruiserSourceTypeConfigMap: s.ConfigMap != nil,
+	}
+	set := 0
+	for _, isSet := range members {
+		if isSet {
+			set++
+		}
+	}
+	switch {
+	case set == 0:
+		allErrs = append(allErrs, field.Required(path, "exactly one of git and configMap must be set"))
+	case set > 1:
+		allErrs = append(allErrs, field.Forbidden(path, "exactly one of git and configMap must be set"))
+	}
+	if isSet, known := members[s.Type]; !known {
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), s.Type,
+			[]string{string(CruiserSourceTypeGit), string(CruiserSourceTypeConfigMap)}))
+	} else if !isSet && set != 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("type"), s.Type, "type must name the member that is set"))
+	}
+	return allErrs
 }
 
 // CruiserStatus defines the observed state of Cruiser
@@ -38,8 +122,14 @@ type CruiserStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
+// Enables the status subresource of the CRD.
+// See https://book.kubebuilder.io/reference/markers/crd.html
 //+kubebuilder:subresource:status
+// Configures the resource of the CRD, e.g. its scope.
+// See https://book.kubebuilder.io/reference/markers/crd.html
 //+kubebuilder:resource:scope=Cluster
 
 // Cruiser is the Schema for the cruisers API
@@ -51,6 +141,8 @@ type Cruiser struct {
 	Status CruiserStatus `json:"status,omitempty"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
 
 // CruiserList contains a list of Cruiser
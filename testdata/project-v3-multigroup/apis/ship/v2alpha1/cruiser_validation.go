@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2alpha1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// The validation of the Cruiser objects is run by the validating webhook of the manager, and by
+// the wasm policy of policies/ship-v2alpha1-cruiser in the clusters disallowing custom admission webhooks.
+// It must only depend on the API types and on the apimachinery packages: the files importing
+// controller-runtime are not compiled to wasm.
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Cruiser) ValidateCreate() error {
+	// TODO(user): fill in your validation logic upon object creation.
+	return r.validateUnions()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Cruiser) ValidateUpdate(old runtime.Object) error {
+	// TODO(user): fill in your validation logic upon object update.
+	return r.validateUnions()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Cruiser) ValidateDelete() error {
+	// TODO(user): fill in your validation logic upon object deletion.
+	return nil
+}
+
+// validateUnions rejects the specs whose unions do not set exactly one member, which the CEL validation rules
+// of the CRD only reject on Kubernetes 1.25 or later
+func (r *Cruiser) validateUnions() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	allErrs = append(allErrs, r.Spec.Source.Validate(specPath.Child("source"))...)
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("Cruiser").GroupKind(), r.Name, allErrs)
+}
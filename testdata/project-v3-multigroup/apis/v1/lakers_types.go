@@ -38,7 +38,11 @@ type LakersStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
+// Enables the status subresource of the CRD.
+// See https://book.kubebuilder.io/reference/markers/crd.html
 //+kubebuilder:subresource:status
 
 // Lakers is the Schema for the lakers API
@@ -50,6 +54,8 @@ type Lakers struct {
 	Status LakersStatus `json:"status,omitempty"`
 }
 
+// Marks the type as a root object, which implements runtime.Object.
+// See https://book.kubebuilder.io/reference/markers/object.html
 //+kubebuilder:object:root=true
 
 // LakersList contains a list of Lakers
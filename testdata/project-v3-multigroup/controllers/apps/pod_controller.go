@@ -21,20 +21,30 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/clusters"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/featuregate"
 )
 
 // PodReconciler reconciles a Pod object
 type PodReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Clusters are the remote clusters whose objects are reconciled too.
+	Clusters clusters.Clusters
 }
 
+// Grants the manager a permission.
+// See https://book.kubebuilder.io/reference/markers/rbac.html
 //+kubebuilder:rbac:groups=apps,resources=pods,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=pods/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=apps,resources=pods/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -43,11 +53,23 @@ type PodReconciler struct {
 // perform operations to make the cluster state reflect the state specified by
 // the user.
 //
+// Long-running work must stop as soon as ctx is done, which happens when the
+// manager is shutting down, so that it finishes within the graceful shutdown
+// timeout. The internal/checkpoint package helps splitting such work into steps
+// that are resumed by the next reconciliation.
+//
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *PodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = r.Log.WithValues("pod", req.NamespacedName)
 
+	if featuregate.Default.Enabled(featuregate.ExampleFeature) {
+		// TODO(user): implement here the behavior gated by ExampleFeature.
+		r.Log.V(1).Info("reconciling with ExampleFeature enabled")
+	}
+
+	// Use the clients of r.Clusters to reconcile objects across the clusters.
+
 	// your logic here
 
 	return ctrl.Result{}, nil
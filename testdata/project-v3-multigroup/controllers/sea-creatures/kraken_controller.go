@@ -20,23 +20,42 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	seacreaturesv1beta1 "sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/apis/sea-creatures/v1beta1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/clusters"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/crossnamespace"
+	reconcileerrors "sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/errors"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/events"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/featuregate"
 )
 
 // KrakenReconciler reconciles a Kraken object
 type KrakenReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Clusters are the remote clusters whose objects are reconciled too.
+	Clusters clusters.Clusters
 }
 
+// Grants the manager a permission.
+// See https://book.kubebuilder.io/reference/markers/rbac.html
 //+kubebuilder:rbac:groups=sea-creatures.testproject.org,resources=krakens,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=sea-creatures.testproject.org,resources=krakens/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=sea-creatures.testproject.org,resources=krakens/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -45,19 +64,124 @@ type KrakenReconciler struct {
 // perform operations to make the cluster state reflect the state specified by
 // the user.
 //
+// Long-running work must stop as soon as ctx is done, which happens when the
+// manager is shutting down, so that it finishes within the graceful shutdown
+// timeout. The internal/checkpoint package helps splitting such work into steps
+// that are resumed by the next reconciliation.
+//
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *KrakenReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = r.Log.WithValues("kraken", req.NamespacedName)
 
+	var obj seacreaturesv1beta1.Kraken
+	if err := r.Get(ctx, req.NamespacedName, &obj); err != nil {
+		// The object may have been deleted after the reconcile request was queued.
+		return reconcileerrors.Result(client.IgnoreNotFound(err))
+	}
+
+	// Owner references can not refer to an owner of another namespace: the ConfigMaps of this
+	// Kraken in the other namespaces are labeled with its UID instead, and deleted by its
+	// finalizer before it is deleted.
+	if !obj.DeletionTimestamp.IsZero() {
+		_, err := crossnamespace.Finalize(ctx, r.Client, &obj, &corev1.ConfigMapList{})
+		return reconcileerrors.Result(err)
+	}
+	if err := crossnamespace.EnsureFinalizer(ctx, r.Client, &obj); err != nil {
+		return reconcileerrors.Result(err)
+	}
+
+	// Create or update the ConfigMaps of this Kraken in the other namespaces.
+	if err := r.reconcileCrossNamespaceChildren(ctx, &obj); err != nil {
+		return reconcileerrors.Result(err)
+	}
+
+	if featuregate.Default.Enabled(featuregate.ExampleFeature) {
+		// TODO(user): implement here the behavior gated by ExampleFeature.
+		r.Log.V(1).Info("reconciling with ExampleFeature enabled")
+	}
+
+	// r.Client is the client of the cluster of the request: the cluster of the manager, or one of
+	// r.Clusters for the controllers of the remote clusters. Use the clients of r.Clusters to
+	// reconcile objects across the clusters.
+
 	// your logic here
 
+	// Write the status of the Kraken with status.Patch, which only patches the changes
+	// of the status and applies them again to a fresh copy of the Kraken when they
+	// conflict with the changes of another writer, e.g.:
+	//	if _, err := status.Patch(ctx, r.Client, &obj, func() error {
+	//		status.SetCondition(&obj.Status.Conditions, metav1.Condition{Type: "Available",
+	//			Status: metav1.ConditionTrue, Reason: "Available", ObservedGeneration: obj.Generation})
+	//		return nil
+	//	}); err != nil {
+	//		return reconcileerrors.Result(err)
+	//	}
+
+	// Return the errors of the reconciliation through reconcileerrors.Result: the terminal ones,
+	// e.g. of an invalid spec, are not requeued until the Kraken changes, and the transient
+	// ones are requeued with a backoff, or after the delay of reconcileerrors.RequeueAfter. Record
+	// events about the outcome of the reconciliation with the helpers of the internal/events package,
+	// e.g. when it fails for good:
+	//	if err := validate(&obj); err != nil {
+	//		events.Warning(r.Recorder, &obj, events.ReasonReconcileError, err)
+	//		return reconcileerrors.Result(reconcileerrors.Terminal(err))
+	//	}
+	events.Normal(r.Recorder, &obj, events.ReasonReconciled, "Kraken %s reconciled", req.Name)
+
 	return ctrl.Result{}, nil
 }
 
+// reconcileCrossNamespaceChildren creates or updates the ConfigMaps of the Kraken in other
+// namespaces than its own, labeled and annotated by crossnamespace.SetOwner instead of being owned.
+// TODO(user): replace ConfigMap with the type of the objects of the Kraken, and compute
+// their namespaces from its spec. Delete the objects of the namespaces removed from the spec, listing
+// them with the crossnamespace.OwnerUIDLabel label.
+func (r *KrakenReconciler) reconcileCrossNamespaceChildren(ctx context.Context, obj *seacreaturesv1beta1.Kraken) error {
+	var namespaces []string
+	for _, namespace := range namespaces {
+		// The name of the owner is only unique in its namespace
+		child := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: obj.Namespace + "-" + obj.Name, Namespace: namespace,
+		}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, r.Client, child, func() error {
+			crossnamespace.SetOwner(obj, seacreaturesv1beta1.GroupVersion.WithKind("Kraken").GroupKind(), child)
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *KrakenReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&seacreaturesv1beta1.Kraken{}).
-		Complete(r)
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			crossnamespace.EnqueueOwner(seacreaturesv1beta1.GroupVersion.WithKind("Kraken").GroupKind())).
+		Complete(r); err != nil {
+		return err
+	}
+
+	// The objects of each remote cluster are reconciled by a controller of their own, whose
+	// reconciler is a copy of r using the client of the cluster.
+	for _, cluster := range r.Clusters {
+		remote := *r
+		remote.Client = cluster.Client
+		remote.Log = r.Log.WithValues("cluster", cluster.Name)
+		c, err := controller.New("kraken-"+cluster.Name, mgr, controller.Options{Reconciler: &remote})
+		if err != nil {
+			return err
+		}
+		if err := c.Watch(source.NewKindWithCache(&seacreaturesv1beta1.Kraken{}, cluster.Cache),
+			&handler.EnqueueRequestForObject{}); err != nil {
+			return err
+		}
+		if err := c.Watch(source.NewKindWithCache(&corev1.ConfigMap{}, cluster.Cache),
+			crossnamespace.EnqueueOwner(seacreaturesv1beta1.GroupVersion.WithKind("Kraken").GroupKind())); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -20,23 +20,44 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	seacreaturesv1beta2 "sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/apis/sea-creatures/v1beta2"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/clusters"
+	reconcileerrors "sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/errors"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/events"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/featuregate"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/pause"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/readiness"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/status"
 )
 
 // LeviathanReconciler reconciles a Leviathan object
 type LeviathanReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Clusters are the remote clusters whose objects are reconciled too.
+	Clusters clusters.Clusters
 }
 
+// Grants the manager a permission.
+// See https://book.kubebuilder.io/reference/markers/rbac.html
 //+kubebuilder:rbac:groups=sea-creatures.testproject.org,resources=leviathans,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=sea-creatures.testproject.org,resources=leviathans/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=sea-creatures.testproject.org,resources=leviathans/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -45,19 +66,142 @@ type LeviathanReconciler struct {
 // perform operations to make the cluster state reflect the state specified by
 // the user.
 //
+// Long-running work must stop as soon as ctx is done, which happens when the
+// manager is shutting down, so that it finishes within the graceful shutdown
+// timeout. The internal/checkpoint package helps splitting such work into steps
+// that are resumed by the next reconciliation.
+//
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *LeviathanReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = r.Log.WithValues("leviathan", req.NamespacedName)
 
+	var obj seacreaturesv1beta2.Leviathan
+	if err := r.Get(ctx, req.NamespacedName, &obj); err != nil {
+		// The object may have been deleted after the reconcile request was queued.
+		return reconcileerrors.Result(client.IgnoreNotFound(err))
+	}
+
+	// Skip the reconciliation of the Leviathan while it is paused by the pause.Annotation
+	// annotation, e.g. during a maintenance window.
+	if paused, err := r.reconcilePause(ctx, &obj); err != nil || paused {
+		return reconcileerrors.Result(err)
+	}
+
+	// The Secrets are watched and cached as metadata only, which saves the memory of their data, and
+	// are listed and read as PartialObjectMetadata: reading them as corev1.Secret would start a second
+	// cache holding their data. Read the data of the few Secrets that need it with mgr.GetAPIReader().
+	// TODO(user): replace Secret with the types of the numerous or big objects watched by the controller.
+	secrets := &metav1.PartialObjectMetadataList{}
+	secrets.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("SecretList"))
+	if err := r.List(ctx, secrets, client.InNamespace(req.Namespace)); err != nil {
+		return reconcileerrors.Result(err)
+	}
+
+	if featuregate.Default.Enabled(featuregate.ExampleFeature) {
+		// TODO(user): implement here the behavior gated by ExampleFeature.
+		r.Log.V(1).Info("reconciling with ExampleFeature enabled")
+	}
+
+	// r.Client is the client of the cluster of the request: the cluster of the manager, or one of
+	// r.Clusters for the controllers of the remote clusters. Use the clients of r.Clusters to
+	// reconcile objects across the clusters.
+
 	// your logic here
 
+	// Write the status of the Leviathan with status.Patch, which only patches the changes
+	// of the status and applies them again to a fresh copy of the Leviathan when they
+	// conflict with the changes of another writer, e.g.:
+	//	if _, err := status.Patch(ctx, r.Client, &obj, func() error {
+	//		status.SetCondition(&obj.Status.Conditions, metav1.Condition{Type: "Available",
+	//			Status: metav1.ConditionTrue, Reason: "Available", ObservedGeneration: obj.Generation})
+	//		return nil
+	//	}); err != nil {
+	//		return reconcileerrors.Result(err)
+	//	}
+
+	// Return the errors of the reconciliation through reconcileerrors.Result: the terminal ones,
+	// e.g. of an invalid spec, are not requeued until the Leviathan changes, and the transient
+	// ones are requeued with a backoff, or after the delay of reconcileerrors.RequeueAfter. Record
+	// events about the outcome of the reconciliation with the helpers of the internal/events package,
+	// e.g. when it fails for good:
+	//	if err := validate(&obj); err != nil {
+	//		events.Warning(r.Recorder, &obj, events.ReasonReconcileError, err)
+	//		return reconcileerrors.Result(reconcileerrors.Terminal(err))
+	//	}
+	events.Normal(r.Recorder, &obj, events.ReasonReconciled, "Leviathan %s reconciled", req.Name)
+
+	// Set the Ready condition of the Leviathan, and record its time to ready the first time it
+	// is ready, once its status is updated.
+	// TODO(user): call readiness.SetNotReady instead while the Leviathan is not ready, e.g. until
+	// the objects it controls are available.
+	var first bool
+	if _, err := status.Patch(ctx, r.Client, &obj, func() error {
+		_, first = readiness.SetReady(&obj.Status.Conditions, obj.Generation, "Leviathan reconciled")
+		return nil
+	}); err != nil {
+		return reconcileerrors.Result(err)
+	}
+	if first {
+		readiness.Observe(seacreaturesv1beta2.GroupVersion.WithKind("Leviathan").GroupKind(), &obj, obj.Status.Conditions)
+	}
+
 	return ctrl.Result{}, nil
 }
 
+// reconcilePause returns whether the reconciliation of the Leviathan is paused, updating its
+// Paused condition when it is paused or resumed.
+func (r *LeviathanReconciler) reconcilePause(ctx context.Context, obj *seacreaturesv1beta2.Leviathan) (bool, error) {
+	paused := pause.IsPaused(obj)
+	changed, err := status.Patch(ctx, r.Client, obj, func() error {
+		if paused {
+			pause.SetPaused(&obj.Status.Conditions, obj.Generation)
+		} else {
+			pause.SetResumed(&obj.Status.Conditions, obj.Generation)
+		}
+		return nil
+	})
+	if err != nil {
+		return paused, err
+	}
+	if changed {
+		r.Log.Info("Leviathan reconciliation paused or resumed", "leviathan",
+			client.ObjectKeyFromObject(obj), "paused", paused)
+	}
+	return paused, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *LeviathanReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&seacreaturesv1beta2.Leviathan{}).
-		Complete(r)
+		Owns(&corev1.Secret{}, builder.OnlyMetadata).
+		Complete(r); err != nil {
+		return err
+	}
+
+	// The objects of each remote cluster are reconciled by a controller of their own, whose
+	// reconciler is a copy of r using the client of the cluster.
+	for _, cluster := range r.Clusters {
+		remote := *r
+		remote.Client = cluster.Client
+		remote.Log = r.Log.WithValues("cluster", cluster.Name)
+		c, err := controller.New("leviathan-"+cluster.Name, mgr, controller.Options{Reconciler: &remote})
+		if err != nil {
+			return err
+		}
+		if err := c.Watch(source.NewKindWithCache(&seacreaturesv1beta2.Leviathan{}, cluster.Cache),
+			&handler.EnqueueRequestForObject{}); err != nil {
+			return err
+		}
+		secretMetadata := &metav1.PartialObjectMetadata{}
+		secretMetadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+		if err := c.Watch(source.NewKindWithCache(secretMetadata, cluster.Cache), &handler.EnqueueRequestForOwner{
+			OwnerType:    &seacreaturesv1beta2.Leviathan{},
+			IsController: true,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
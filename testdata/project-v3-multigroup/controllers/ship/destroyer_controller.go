@@ -18,25 +18,49 @@ package ship
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	shipv1 "sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/apis/ship/v1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/clusters"
+	reconcileerrors "sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/errors"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/events"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/featuregate"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/resync"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/status"
 )
 
 // DestroyerReconciler reconciles a Destroyer object
 type DestroyerReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Clusters are the remote clusters whose objects are reconciled too.
+	Clusters clusters.Clusters
+	// Resync schedules the periodic reconciliations of the Destroyer objects, set by
+	// SetupWithManager if nil.
+	Resync *resync.Schedule
 }
 
+// destroyerResyncPeriod is the period of the reconciliations of a Destroyer even if
+// nothing changed, spread by resync.DefaultJitterFactor.
+const destroyerResyncPeriod = time.Hour
+
+// Grants the manager a permission.
+// See https://book.kubebuilder.io/reference/markers/rbac.html
 //+kubebuilder:rbac:groups=ship.testproject.org,resources=destroyers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=ship.testproject.org,resources=destroyers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=ship.testproject.org,resources=destroyers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -45,19 +69,107 @@ type DestroyerReconciler struct {
 // perform operations to make the cluster state reflect the state specified by
 // the user.
 //
+// Long-running work must stop as soon as ctx is done, which happens when the
+// manager is shutting down, so that it finishes within the graceful shutdown
+// timeout. The internal/checkpoint package helps splitting such work into steps
+// that are resumed by the next reconciliation.
+//
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *DestroyerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = r.Log.WithValues("destroyer", req.NamespacedName)
 
+	var obj shipv1.Destroyer
+	if err := r.Get(ctx, req.NamespacedName, &obj); err != nil {
+		// The object may have been deleted after the reconcile request was queued.
+		return reconcileerrors.Result(client.IgnoreNotFound(err))
+	}
+
+	if featuregate.Default.Enabled(featuregate.ExampleFeature) {
+		// TODO(user): implement here the behavior gated by ExampleFeature.
+		r.Log.V(1).Info("reconciling with ExampleFeature enabled")
+	}
+
+	// r.Client is the client of the cluster of the request: the cluster of the manager, or one of
+	// r.Clusters for the controllers of the remote clusters. Use the clients of r.Clusters to
+	// reconcile objects across the clusters.
+
 	// your logic here
 
-	return ctrl.Result{}, nil
+	// Write the status of the Destroyer with status.Patch, which only patches the changes
+	// of the status and applies them again to a fresh copy of the Destroyer when they
+	// conflict with the changes of another writer, e.g.:
+	//	if _, err := status.Patch(ctx, r.Client, &obj, func() error {
+	//		status.SetCondition(&obj.Status.Conditions, metav1.Condition{Type: "Available",
+	//			Status: metav1.ConditionTrue, Reason: "Available", ObservedGeneration: obj.Generation})
+	//		return nil
+	//	}); err != nil {
+	//		return reconcileerrors.Result(err)
+	//	}
+
+	// Return the errors of the reconciliation through reconcileerrors.Result: the terminal ones,
+	// e.g. of an invalid spec, are not requeued until the Destroyer changes, and the transient
+	// ones are requeued with a backoff, or after the delay of reconcileerrors.RequeueAfter. Record
+	// events about the outcome of the reconciliation with the helpers of the internal/events package,
+	// e.g. when it fails for good:
+	//	if err := validate(&obj); err != nil {
+	//		events.Warning(r.Recorder, &obj, events.ReasonReconcileError, err)
+	//		return reconcileerrors.Result(reconcileerrors.Terminal(err))
+	//	}
+	events.Normal(r.Recorder, &obj, events.ReasonReconciled, "Destroyer %s reconciled", req.Name)
+
+	return r.resync(ctx, &obj)
+}
+
+// resync runs the periodic work of the Destroyer when its status.nextReconcileTime is due,
+// then schedules the next one, and requeues the Destroyer until then. The reconciliations
+// requested by the changes of the Destroyer or of its objects, including the update of its
+// status, do not postpone it.
+func (r *DestroyerReconciler) resync(ctx context.Context, obj *shipv1.Destroyer) (ctrl.Result, error) {
+	if due, after := r.Resync.Due(obj.Status.NextReconcileTime); !due {
+		return ctrl.Result{RequeueAfter: after}, nil
+	}
+
+	// TODO(user): do the periodic work of the Destroyer here, e.g. renew its certificate or
+	// check its license before they expire. An error retries it with a backoff, the next periodic
+	// reconciliation being scheduled once it succeeds.
+
+	next, after := r.Resync.Next()
+	if _, err := status.Patch(ctx, r.Client, obj, func() error {
+		obj.Status.NextReconcileTime = &next
+		return nil
+	}); err != nil {
+		return reconcileerrors.Result(err)
+	}
+	return ctrl.Result{RequeueAfter: after}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *DestroyerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	if r.Resync == nil {
+		r.Resync = resync.New(destroyerResyncPeriod, resync.DefaultJitterFactor)
+	}
+
+	if err := ctrl.NewControllerManagedBy(mgr).
 		For(&shipv1.Destroyer{}).
-		Complete(r)
+		Complete(r); err != nil {
+		return err
+	}
+
+	// The objects of each remote cluster are reconciled by a controller of their own, whose
+	// reconciler is a copy of r using the client of the cluster.
+	for _, cluster := range r.Clusters {
+		remote := *r
+		remote.Client = cluster.Client
+		remote.Log = r.Log.WithValues("cluster", cluster.Name)
+		c, err := controller.New("destroyer-"+cluster.Name, mgr, controller.Options{Reconciler: &remote})
+		if err != nil {
+			return err
+		}
+		if err := c.Watch(source.NewKindWithCache(&shipv1.Destroyer{}, cluster.Cache),
+			&handler.EnqueueRequestForObject{}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
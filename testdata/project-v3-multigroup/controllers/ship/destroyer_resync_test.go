@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ship
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	shipv1 "sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/apis/ship/v1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/internal/resync"
+)
+
+var _ = Describe("Destroyer periodic reconciliations", func() {
+	It("should reconcile the Destroyer again at its nextReconcileTime", func() {
+		ctx := context.Background()
+		s := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+		Expect(shipv1.AddToScheme(s)).To(Succeed())
+
+		obj := &shipv1.Destroyer{
+			ObjectMeta: metav1.ObjectMeta{Name: "resync-test"},
+		}
+		c := fake.NewClientBuilder().WithScheme(s).WithObjects(obj).Build()
+		fakeClock := clock.NewFakeClock(time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+		reconciler := &DestroyerReconciler{
+			Client:   c,
+			Log:      ctrl.Log.WithName("resync-test"),
+			Scheme:   s,
+			Recorder: record.NewFakeRecorder(10),
+			Resync:   resync.NewWithClock(destroyerResyncPeriod, resync.DefaultJitterFactor, fakeClock),
+		}
+		key := client.ObjectKeyFromObject(obj)
+
+		By("scheduling the first periodic reconciliation")
+		Expect(c.Get(ctx, key, obj)).To(Succeed())
+		result, err := reconciler.resync(ctx, obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, key, obj)).To(Succeed())
+		Expect(obj.Status.NextReconcileTime).NotTo(BeNil())
+		next := *obj.Status.NextReconcileTime
+		Expect(result.RequeueAfter).To(Equal(next.Sub(fakeClock.Now())))
+		Expect(result.RequeueAfter).To(BeNumerically(">=", destroyerResyncPeriod))
+
+		By("requeueing the Destroyer until then when it is reconciled for another reason")
+		fakeClock.Step(result.RequeueAfter / 2)
+		result, err = reconciler.resync(ctx, obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(next.Sub(fakeClock.Now())))
+		Expect(c.Get(ctx, key, obj)).To(Succeed())
+		Expect(obj.Status.NextReconcileTime.Equal(&next)).To(BeTrue())
+
+		By("scheduling the next periodic reconciliation once it is due")
+		fakeClock.SetTime(next.Time)
+		result, err = reconciler.resync(ctx, obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Get(ctx, key, obj)).To(Succeed())
+		Expect(obj.Status.NextReconcileTime.After(next.Time)).To(BeTrue())
+		Expect(result.RequeueAfter).To(BeNumerically(">=", destroyerResyncPeriod))
+	})
+})
@@ -0,0 +1,231 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// crdcompat checks that the CRDs found in a directory are backwards compatible with the
+// ones found in the same directory at a previous git revision. A change is considered
+// breaking if it removes a served version, changes the scope, or, for versions served in
+// both revisions, removes a field, changes its type, removes an enum value or makes a
+// field required.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+type object = map[string]interface{}
+
+func main() {
+	var baseRef, dir string
+	flag.StringVar(&baseRef, "base-ref", "HEAD~1", "git revision to compare the CRDs against")
+	flag.StringVar(&dir, "dir", "config/crd/bases", "directory containing the CRD manifests")
+	flag.Parse()
+
+	oldCRDs, err := loadBaseCRDs(baseRef, dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load CRDs from %s: %v\n", baseRef, err)
+		os.Exit(1)
+	}
+	newCRDs, err := loadCRDs(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load CRDs: %v\n", err)
+		os.Exit(1)
+	}
+
+	var problems []string
+	for name, oldCRD := range oldCRDs {
+		newCRD, found := newCRDs[name]
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s: CRD was removed", name))
+			continue
+		}
+		problems = append(problems, compareCRDs(name, oldCRD, newCRD)...)
+	}
+
+	if len(problems) != 0 {
+		sort.Strings(problems)
+		fmt.Fprintf(os.Stderr, "found %d breaking change(s) compared to %s:\n", len(problems), baseRef)
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("CRDs are compatible with %s\n", baseRef)
+}
+
+// loadBaseCRDs reads the CRDs found in dir at the git revision ref.
+func loadBaseCRDs(ref, dir string) (map[string]object, error) {
+	out, err := exec.Command("git", "ls-tree", "--name-only", ref, dir+"/").Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list files: %v", err)
+	}
+	crds := make(map[string]object)
+	for _, file := range strings.Fields(string(out)) {
+		if path.Ext(file) != ".yaml" {
+			continue
+		}
+		content, err := exec.Command("git", "show", ref+":./"+file).Output()
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %v", file, err)
+		}
+		if err := addCRD(crds, content); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %v", file, err)
+		}
+	}
+	return crds, nil
+}
+
+// loadCRDs reads the CRDs found in dir from the working tree.
+func loadCRDs(dir string) (map[string]object, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	crds := make(map[string]object)
+	for _, file := range files {
+		if path.Ext(file.Name()) != ".yaml" {
+			continue
+		}
+		content, err := ioutil.ReadFile(path.Join(dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := addCRD(crds, content); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %v", file.Name(), err)
+		}
+	}
+	return crds, nil
+}
+
+func addCRD(crds map[string]object, content []byte) error {
+	for _, doc := range strings.Split(string(content), "\n---") {
+		var crd object
+		if err := yaml.Unmarshal([]byte(doc), &crd); err != nil {
+			return err
+		}
+		if crd["kind"] != "CustomResourceDefinition" {
+			continue
+		}
+		name, _ := field(crd, "metadata")["name"].(string)
+		crds[name] = crd
+	}
+	return nil
+}
+
+func compareCRDs(name string, oldCRD, newCRD object) []string {
+	var problems []string
+
+	oldSpec, newSpec := field(oldCRD, "spec"), field(newCRD, "spec")
+	if oldSpec["scope"] != newSpec["scope"] {
+		problems = append(problems, fmt.Sprintf("%s: scope changed from %v to %v", name, oldSpec["scope"], newSpec["scope"]))
+	}
+
+	oldVersions, newVersions := servedVersions(oldSpec), servedVersions(newSpec)
+	for version, oldSchema := range oldVersions {
+		newSchema, served := newVersions[version]
+		if !served {
+			problems = append(problems, fmt.Sprintf("%s: version %s is no longer served", name, version))
+			continue
+		}
+		problems = append(problems, compareSchemas(name+"/"+version, oldSchema, newSchema)...)
+	}
+
+	return problems
+}
+
+// servedVersions returns the schema of every served version, supporting both v1 and v1beta1 CRDs.
+func servedVersions(spec object) map[string]object {
+	versions := make(map[string]object)
+	topLevelSchema := field(field(spec, "validation"), "openAPIV3Schema")
+	rawVersions, _ := spec["versions"].([]interface{})
+	for _, rawVersion := range rawVersions {
+		version, _ := rawVersion.(object)
+		if served, _ := version["served"].(bool); !served {
+			continue
+		}
+		name, _ := version["name"].(string)
+		schema := field(field(version, "schema"), "openAPIV3Schema")
+		if len(schema) == 0 {
+			schema = topLevelSchema
+		}
+		versions[name] = schema
+	}
+	return versions
+}
+
+func compareSchemas(fieldPath string, oldSchema, newSchema object) []string {
+	var problems []string
+
+	if oldType, newType := oldSchema["type"], newSchema["type"]; oldType != newType {
+		return []string{fmt.Sprintf("%s: type changed from %v to %v", fieldPath, oldType, newType)}
+	}
+
+	oldRequired, newRequired := stringSet(oldSchema["required"]), stringSet(newSchema["required"])
+	for required := range newRequired {
+		if !oldRequired[required] {
+			problems = append(problems, fmt.Sprintf("%s.%s: field is now required", fieldPath, required))
+		}
+	}
+
+	if oldEnum, found := oldSchema["enum"]; found {
+		newEnum := stringSet(newSchema["enum"])
+		for value := range stringSet(oldEnum) {
+			if !newEnum[value] {
+				problems = append(problems, fmt.Sprintf("%s: enum value %s was removed", fieldPath, value))
+			}
+		}
+	}
+
+	oldProperties, newProperties := field(oldSchema, "properties"), field(newSchema, "properties")
+	for property, rawOldProperty := range oldProperties {
+		rawNewProperty, found := newProperties[property]
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s.%s: field was removed", fieldPath, property))
+			continue
+		}
+		oldProperty, _ := rawOldProperty.(object)
+		newProperty, _ := rawNewProperty.(object)
+		problems = append(problems, compareSchemas(fieldPath+"."+property, oldProperty, newProperty)...)
+	}
+
+	if oldItems := field(oldSchema, "items"); len(oldItems) != 0 {
+		problems = append(problems, compareSchemas(fieldPath+"[]", oldItems, field(newSchema, "items"))...)
+	}
+
+	return problems
+}
+
+func field(obj object, name string) object {
+	value, _ := obj[name].(object)
+	return value
+}
+
+func stringSet(raw interface{}) map[string]bool {
+	values, _ := raw.([]interface{})
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[fmt.Sprint(value)] = true
+	}
+	return set
+}
@@ -0,0 +1,459 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// upgradetest tests the upgrade of the project from its previous release to the current build in
+// the cluster of the current kubectl context, e.g. a kind cluster the image of the current build
+// was loaded into:
+//
+//  1. it applies the manifests of the previous release, --from, and waits for the rollout of the
+//     manager;
+//  2. it creates the samples of config/samples and waits for them to be reconciled;
+//  3. it reads the samples in every served version of their CRD, through the conversions of the
+//     previous release;
+//  4. it applies the manifests of the current build, --to, and waits for the rollout of the manager;
+//  5. it checks that the samples are still reconciled, and that the fields of their specs read in
+//     every version served by both releases are unchanged, through the conversions of the current
+//     build. The fields added by the defaults of the current build are allowed;
+//  6. it deletes the samples, which the manager of the current build must finalize, unless --keep.
+//
+// A sample is reconciled when its status.observedGeneration matches its generation or, if its CRD
+// has no such field, when its Ready condition is True. The samples whose CRD has neither field are
+// not waited for.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+type object = map[string]interface{}
+
+// crd is a CustomResourceDefinition of the manifests
+type crd struct {
+	name, group, kind, plural string
+	// served are the served versions
+	served []string
+	// observedGeneration and readyCondition are whether the status of the objects has the
+	// observedGeneration and the conditions fields
+	observedGeneration, readyCondition bool
+}
+
+// manifests are the objects of the manifests of a release
+type manifests struct {
+	content []byte
+	crds    []crd
+	// workloads are the Deployments and DaemonSets running the manager, as kind/name and namespace
+	workloads [][2]string
+}
+
+// sample is a sample object of a resource of the project
+type sample struct {
+	path, name, namespace string
+	crd                   crd
+	// specs are the specs of the sample read in the served versions of its CRD before the upgrade
+	specs map[string]interface{}
+}
+
+var (
+	apply   string
+	timeout time.Duration
+)
+
+func main() {
+	var from, to, samplesDir string
+	var keep bool
+	flag.StringVar(&from, "from", "", "manifests of the previous release: a file, a URL, such as the install "+
+		"manifests published with the release, or a kustomize directory")
+	flag.StringVar(&to, "to", "", "manifests of the current build, e.g. built from config/default")
+	flag.StringVar(&samplesDir, "samples", "config/samples", "directory of the samples created before the upgrade")
+	flag.StringVar(&apply, "apply", "kubectl apply", "command applying the manifests read from its standard input")
+	flag.DurationVar(&timeout, "timeout", 5*time.Minute, "timeout of each rollout and of the reconciliation of each sample")
+	flag.BoolVar(&keep, "keep", false, "keep the samples after the test instead of deleting them")
+	flag.Parse()
+
+	if from == "" || to == "" {
+		fail("--from and --to are required, e.g. --from=https://github.com/<org>/<project>/releases/download/v0.1.0/install.yaml")
+	}
+	previous, err := readManifests(from)
+	if err != nil {
+		fail("unable to read the manifests of the previous release: %v", err)
+	}
+	current, err := readManifests(to)
+	if err != nil {
+		fail("unable to read the manifests of the current build: %v", err)
+	}
+
+	fmt.Printf("Installing the previous release from %s\n", from)
+	if err := install(previous); err != nil {
+		fail("unable to install the previous release: %v", err)
+	}
+
+	fmt.Printf("Creating the samples of %s\n", samplesDir)
+	samples, err := createSamples(samplesDir, previous.crds)
+	if err != nil {
+		fail("unable to create the samples: %v", err)
+	}
+	for i := range samples {
+		if err := waitReconciled(samples[i]); err != nil {
+			fail("%v", err)
+		}
+		if samples[i].specs, err = readSpecs(samples[i], samples[i].crd.served); err != nil {
+			fail("%v", err)
+		}
+	}
+
+	fmt.Printf("Upgrading to the current build from %s\n", to)
+	if err := install(current); err != nil {
+		fail("unable to upgrade to the current build: %v", err)
+	}
+
+	var problems []string
+	for _, s := range samples {
+		found := false
+		for _, c := range current.crds {
+			if c.name == s.crd.name {
+				s.crd, found = c, true
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("the current build has no %s CRD", s.crd.name))
+			continue
+		}
+		if err := waitReconciled(s); err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+
+		// The specs are compared in the versions served by both releases
+		var versions []string
+		for _, version := range s.crd.served {
+			if _, ok := s.specs[version]; ok {
+				versions = append(versions, version)
+			}
+		}
+		specs, err := readSpecs(s, versions)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		for _, version := range versions {
+			if path, changed := changedField(s.specs[version], specs[version], ""); changed {
+				problems = append(problems, fmt.Sprintf("%s %s read in %s: spec%s changed from %s to %s", s.crd.kind,
+					s.name, version, path, marshal(field(s.specs[version], path)), marshal(field(specs[version], path))))
+			}
+		}
+	}
+
+	if !keep {
+		fmt.Println("Deleting the samples")
+		for _, s := range samples {
+			if err := kubectl(nil, "delete", "-f", s.path, "--ignore-not-found", "--timeout="+timeout.String()); err != nil {
+				problems = append(problems, fmt.Sprintf("%s %s was not deleted by the manager of the current build: %v",
+					s.crd.kind, s.name, err))
+			}
+		}
+	}
+
+	if len(problems) != 0 {
+		fail("the upgrade test failed:\n  %s", strings.Join(problems, "\n  "))
+	}
+	fmt.Println("The samples were reconciled after the upgrade and their specs were preserved")
+}
+
+// readManifests reads the manifests of source, a file, a URL or a kustomize directory.
+func readManifests(source string) (manifests, error) {
+	var content []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		content, err = download(source)
+	} else if info, statErr := os.Stat(source); statErr == nil && info.IsDir() {
+		content, err = exec.Command("kubectl", "kustomize", source).Output()
+	} else {
+		content, err = ioutil.ReadFile(source)
+	}
+	if err != nil {
+		return manifests{}, err
+	}
+
+	m := manifests{content: content}
+	for _, document := range strings.Split(string(content), "\n---") {
+		var obj object
+		if err := yaml.Unmarshal([]byte(document), &obj); err != nil {
+			return manifests{}, err
+		}
+		metadata, _ := obj["metadata"].(object)
+		name, _ := metadata["name"].(string)
+		namespace, _ := metadata["namespace"].(string)
+		switch obj["kind"] {
+		case "Deployment", "DaemonSet":
+			m.workloads = append(m.workloads, [2]string{strings.ToLower(obj["kind"].(string)) + "/" + name, namespace})
+		case "CustomResourceDefinition":
+			m.crds = append(m.crds, parseCRD(name, obj))
+		}
+	}
+	return m, nil
+}
+
+// download returns the content of url.
+func download(url string) ([]byte, error) {
+	resp, err := http.Get(url) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parseCRD returns the versions and the status fields of a v1 CRD.
+func parseCRD(name string, obj object) crd {
+	spec, _ := obj["spec"].(object)
+	names, _ := spec["names"].(object)
+	c := crd{name: name}
+	c.group, _ = spec["group"].(string)
+	c.kind, _ = names["kind"].(string)
+	c.plural, _ = names["plural"].(string)
+	versions, _ := spec["versions"].([]interface{})
+	for _, v := range versions {
+		v, _ := v.(object)
+		if served, _ := v["served"].(bool); !served {
+			continue
+		}
+		name, _ := v["name"].(string)
+		c.served = append(c.served, name)
+		if storage, _ := v["storage"].(bool); storage {
+			status := field(v, ".schema.openAPIV3Schema.properties.status.properties")
+			properties, _ := status.(object)
+			_, c.observedGeneration = properties["observedGeneration"]
+			_, c.readyCondition = properties["conditions"]
+		}
+	}
+	return c
+}
+
+// install applies the manifests, and waits for their CRDs to be established and for the rollout of
+// their workloads.
+func install(m manifests) error {
+	args := strings.Fields(apply)
+	cmd := exec.Command(args[0], append(args[1:], "-f", "-")...) //nolint:gosec
+	cmd.Stdin = bytes.NewReader(m.content)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+	for _, c := range m.crds {
+		if err := kubectl(nil, "wait", "--for=condition=Established", "crd/"+c.name, "--timeout="+timeout.String()); err != nil {
+			return err
+		}
+	}
+	for _, workload := range m.workloads {
+		if err := kubectl(nil, "rollout", "status", workload[0], "-n", workload[1], "--timeout="+timeout.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createSamples applies the samples of dir, and returns the ones of the resources of crds.
+func createSamples(dir string, crds []crd) ([]sample, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var samples []sample
+	for _, path := range paths {
+		if filepath.Base(path) == "kustomization.yaml" {
+			continue
+		}
+		if err := kubectl(nil, "apply", "-f", path); err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadFile(path) //nolint:gosec
+		if err != nil {
+			return nil, err
+		}
+		for _, document := range strings.Split(string(content), "\n---") {
+			var obj object
+			if err := yaml.Unmarshal([]byte(document), &obj); err != nil {
+				return nil, err
+			}
+			apiVersion, _ := obj["apiVersion"].(string)
+			metadata, _ := obj["metadata"].(object)
+			for _, c := range crds {
+				if strings.HasPrefix(apiVersion, c.group+"/") && obj["kind"] == c.kind {
+					s := sample{path: path, crd: c}
+					s.name, _ = metadata["name"].(string)
+					s.namespace, _ = metadata["namespace"].(string)
+					samples = append(samples, s)
+				}
+			}
+		}
+	}
+	return samples, nil
+}
+
+// waitReconciled waits for the status of a sample to show that it was reconciled.
+func waitReconciled(s sample) error {
+	if !s.crd.observedGeneration && !s.crd.readyCondition {
+		return nil
+	}
+	var obj object
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(2 * time.Second) {
+		var err error
+		if obj, err = get(s, s.crd.plural+"."+s.crd.group); err != nil {
+			return err
+		}
+		if reconciled(obj, s.crd) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s was not reconciled within %s, its status is %s", s.crd.kind, s.name, timeout,
+		marshal(obj["status"]))
+}
+
+// reconciled returns whether the status of obj shows that it was reconciled.
+func reconciled(obj object, c crd) bool {
+	status, _ := obj["status"].(object)
+	if c.observedGeneration {
+		observed, _ := status["observedGeneration"].(float64)
+		generation, _ := field(obj, ".metadata.generation").(float64)
+		return observed >= generation && observed != 0
+	}
+	conditions, _ := status["conditions"].([]interface{})
+	for _, condition := range conditions {
+		condition, _ := condition.(object)
+		if condition["type"] == "Ready" {
+			return condition["status"] == "True"
+		}
+	}
+	return false
+}
+
+// readSpecs returns the specs of a sample read in versions.
+func readSpecs(s sample, versions []string) (map[string]interface{}, error) {
+	specs := make(map[string]interface{}, len(versions))
+	for _, version := range versions {
+		obj, err := get(s, s.crd.plural+"."+version+"."+s.crd.group)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s %s in %s: %v", s.crd.kind, s.name, version, err)
+		}
+		specs[version] = obj["spec"]
+	}
+	return specs, nil
+}
+
+// get returns a sample read as resource, e.g. frigates.v1.ship.example.com.
+func get(s sample, resource string) (object, error) {
+	args := []string{"get", resource, s.name, "-o", "json"}
+	if s.namespace != "" {
+		args = append(args, "-n", s.namespace)
+	}
+	var out bytes.Buffer
+	if err := kubectl(&out, args...); err != nil {
+		return nil, err
+	}
+	var obj object
+	return obj, json.Unmarshal(out.Bytes(), &obj)
+}
+
+// changedField returns the path, below path, of the first field of before that is changed or missing in
+// after. The fields of after missing in before, e.g. added by the defaults of the CRD, are allowed.
+func changedField(before, after interface{}, path string) (string, bool) {
+	switch before := before.(type) {
+	case object:
+		after, ok := after.(object)
+		if !ok {
+			return path, true
+		}
+		keys := make([]string, 0, len(before))
+		for key := range before {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if changed, ok := changedField(before[key], after[key], path+"."+key); ok {
+				return changed, true
+			}
+		}
+		return "", false
+	case []interface{}:
+		after, ok := after.([]interface{})
+		if !ok || len(after) != len(before) {
+			return path, true
+		}
+		for i := range before {
+			if changed, ok := changedField(before[i], after[i], fmt.Sprintf("%s[%d]", path, i)); ok {
+				return changed, true
+			}
+		}
+		return "", false
+	default:
+		return path, marshal(before) != marshal(after)
+	}
+}
+
+// field returns the field of obj at path, e.g. .metadata.generation, nil if not found. The path of an
+// element of a list returns the list.
+func field(obj interface{}, path string) interface{} {
+	for _, key := range strings.Split(strings.TrimPrefix(path, "."), ".") {
+		if key == "" {
+			continue
+		}
+		key = strings.SplitN(key, "[", 2)[0]
+		m, _ := obj.(object)
+		obj = m[key]
+	}
+	return obj
+}
+
+// marshal returns the JSON of value.
+func marshal(value interface{}) string {
+	content, _ := json.Marshal(value)
+	return string(content)
+}
+
+// kubectl runs kubectl with args, writing its output to out, or to the standard output if nil.
+func kubectl(out *bytes.Buffer, args ...string) error {
+	cmd := exec.Command("kubectl", args...)
+	cmd.Stdout = os.Stdout
+	if out != nil {
+		cmd.Stdout = out
+	}
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// fail prints the message and exits.
+func fail(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package certmetrics exposes the validity of the serving certificates of the manager as Prometheus
+// metrics. An expired serving certificate of the webhooks makes the API server fail every request
+// they serve: the objects of the project can no longer be created or updated, and the ones of the
+// kinds with a conversion webhook can no longer be read, until the certificate is renewed.
+//
+// The certificates are read again on every scrape of the metrics endpoint of the manager, so that
+// the metrics follow their rotations by cert-manager, or by any other issuer writing them in the
+// certificate directory:
+//
+//   - serving_certificate_expiration_timestamp_seconds is the notAfter time of each certificate,
+//     as a Unix timestamp, by certificate.
+//   - serving_certificate_not_before_timestamp_seconds is its notBefore time, which gives its
+//     lifetime, e.g. to alert once most of it elapsed without the certificate being renewed.
+//
+// The certificates that can not be read, e.g. when the webhooks are disabled, have no metrics.
+package certmetrics
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// WebhookCertificate is the certificate label of the serving certificate of the webhooks
+const WebhookCertificate = "webhook"
+
+// RegisterWebhookServer registers the metrics of the serving certificate of the webhooks of server.
+// Registering it again is a no-op.
+func RegisterWebhookServer(server *webhook.Server) {
+	Register(WebhookCertificate, webhookCertPath(server))
+}
+
+// webhookCertPath returns the path of the serving certificate of server, with the defaults of
+// controller-runtime
+func webhookCertPath(server *webhook.Server) string {
+	dir, name := server.CertDir, server.CertName
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+	}
+	if name == "" {
+		name = "tls.crt"
+	}
+	return filepath.Join(dir, name)
+}
+
+// Register registers the metrics of the PEM-encoded certificate at path under the certificate label
+// name, e.g. of the serving certificate of another server of the manager. Registering a name again
+// is a no-op.
+func Register(name, path string) {
+	err := metrics.Registry.Register(NewCollector(name, path))
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if err != nil && !errors.As(err, &alreadyRegistered) {
+		panic(err)
+	}
+}
+
+// NewCollector returns a collector of the metrics of the PEM-encoded certificate at path under the
+// certificate label name.
+func NewCollector(name, path string) prometheus.Collector {
+	labels := prometheus.Labels{"certificate": name}
+	return &collector{
+		path: path,
+		expiration: prometheus.NewDesc("serving_certificate_expiration_timestamp_seconds",
+			"Expiration time of the serving certificate, as a Unix timestamp", nil, labels),
+		notBefore: prometheus.NewDesc("serving_certificate_not_before_timestamp_seconds",
+			"Time from which the serving certificate is valid, as a Unix timestamp", nil, labels),
+	}
+}
+
+type collector struct {
+	path                  string
+	expiration, notBefore *prometheus.Desc
+}
+
+// Describe implements prometheus.Collector
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.expiration
+	ch <- c.notBefore
+}
+
+// Collect implements prometheus.Collector
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	cert, err := readCertificate(c.path)
+	if err != nil {
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.expiration, prometheus.GaugeValue, float64(cert.NotAfter.Unix()))
+	ch <- prometheus.MustNewConstMetric(c.notBefore, prometheus.GaugeValue, float64(cert.NotBefore.Unix()))
+}
+
+// readCertificate returns the first certificate of the PEM file at path, the one served before the
+// certificates of its chain.
+func readCertificate(path string) (*x509.Certificate, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for block, rest := pem.Decode(content); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type == "CERTIFICATE" {
+			return x509.ParseCertificate(block.Bytes)
+		}
+	}
+	return nil, fmt.Errorf("no certificate found in %s", path)
+}
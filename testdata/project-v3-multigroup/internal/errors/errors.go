@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package errors types the errors of the reconciliations, so that the controllers map them to the
+// result of Reconcile consistently with Result:
+//
+//   - a terminal error, e.g. of an invalid spec, can not be fixed by retrying the reconciliation,
+//     which is not requeued: the object is reconciled again when it changes.
+//   - a transient error, e.g. of an unavailable dependency, is retried with the exponential backoff
+//     of the controller, or after a fixed delay set by RequeueAfter.
+//   - a conflict, when the object was changed since it was read, is retried with the backoff of the
+//     controller without logging an error, the next reconciliation reading the latest version.
+//
+// The untyped errors are transient. Returning a terminal error to controller-runtime would retry it
+// forever, and returning a transient error with a RequeueAfter result would ignore the delay: both
+// requeue the object far more often than needed.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// TerminalError is an error that retrying the reconciliation can not fix.
+type TerminalError struct {
+	Err error
+}
+
+// Error implements error
+func (e *TerminalError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *TerminalError) Unwrap() error {
+	return e.Err
+}
+
+// TransientError is an error that a later reconciliation may not have, retried after RequeueAfter,
+// or with the backoff of the controller if zero.
+type TransientError struct {
+	Err          error
+	RequeueAfter time.Duration
+}
+
+// Error implements error
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// Terminal returns err as a terminal error, nil if err is nil.
+func Terminal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TerminalError{Err: err}
+}
+
+// Terminalf returns a terminal error formatted as fmt.Errorf.
+func Terminalf(format string, args ...interface{}) error {
+	return &TerminalError{Err: fmt.Errorf(format, args...)}
+}
+
+// Transient returns err as a transient error retried with the backoff of the controller, nil if err
+// is nil.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err}
+}
+
+// RequeueAfter returns err as a transient error retried after the delay, e.g. the time a dependency
+// takes to be available, instead of the backoff of the controller, nil if err is nil.
+func RequeueAfter(err error, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err, RequeueAfter: after}
+}
+
+// IsTerminal returns true if err, or one of the errors it wraps, is a terminal error.
+func IsTerminal(err error) bool {
+	var terminal *TerminalError
+	return errors.As(err, &terminal)
+}
+
+// Result returns the result of Reconcile for err, the error of the reconciliation or nil:
+//
+//   - nil and the terminal errors are not requeued, record the latter as an event or a condition of
+//     the object to report them to the user.
+//   - the transient errors with a delay are requeued after it.
+//   - the conflicts are requeued with the backoff of the controller, without logging an error.
+//   - the other errors are returned, to be logged and retried with the backoff of the controller.
+func Result(err error) (ctrl.Result, error) {
+	if err == nil || IsTerminal(err) {
+		return ctrl.Result{}, nil
+	}
+	var transient *TransientError
+	if errors.As(err, &transient) && transient.RequeueAfter > 0 {
+		return ctrl.Result{RequeueAfter: transient.RequeueAfter}, nil
+	}
+	if apierrors.IsConflict(err) {
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, err
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pause lets the users pause the reconciliation of the objects of the controllers, e.g.
+// during a maintenance window or while an incident is investigated, by annotating them:
+//
+//	kubectl annotate <kind> <name> testproject.org/paused=true
+//
+// The controllers skip the reconciliation of the paused objects, whose Paused condition is True,
+// until the annotation is removed or set to another value:
+//
+//	kubectl annotate <kind> <name> testproject.org/paused-
+//
+// The Paused condition is only added to the objects that were paused once, and is False since
+// they were resumed.
+package pause
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Annotation is the annotation pausing the reconciliation of an object when set to true.
+const Annotation = "testproject.org/paused"
+
+// ConditionPaused is the type of the condition telling whether the reconciliation of an object is
+// paused.
+const ConditionPaused = "Paused"
+
+// The reasons of the Paused condition
+const (
+	// ReasonPaused is the reason of the objects whose reconciliation is paused.
+	ReasonPaused = "Paused"
+	// ReasonResumed is the reason of the objects whose reconciliation was paused and is no longer.
+	ReasonResumed = "Resumed"
+)
+
+// IsPaused returns whether the reconciliation of obj is paused, i.e. whether its Annotation is set
+// to true.
+func IsPaused(obj metav1.Object) bool {
+	return obj.GetAnnotations()[Annotation] == "true"
+}
+
+// SetPaused sets the Paused condition of an object of the given generation to True. It returns
+// whether the condition changed.
+func SetPaused(conditions *[]metav1.Condition, generation int64) bool {
+	return set(conditions, metav1.Condition{
+		Type:               ConditionPaused,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             ReasonPaused,
+		Message:            "The reconciliation is paused by the " + Annotation + " annotation",
+	})
+}
+
+// SetResumed sets the Paused condition of an object of the given generation to False if the object
+// was paused, leaving the objects that were never paused without the condition. It returns whether
+// the condition changed.
+func SetResumed(conditions *[]metav1.Condition, generation int64) bool {
+	if meta.FindStatusCondition(*conditions, ConditionPaused) == nil {
+		return false
+	}
+	return set(conditions, metav1.Condition{
+		Type:               ConditionPaused,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             ReasonResumed,
+		Message:            "The reconciliation is resumed",
+	})
+}
+
+// set sets the condition in conditions, and returns whether it changed.
+func set(conditions *[]metav1.Condition, condition metav1.Condition) bool {
+	current := meta.FindStatusCondition(*conditions, condition.Type)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason &&
+		current.Message == condition.Message && current.ObservedGeneration == condition.ObservedGeneration {
+		return false
+	}
+	meta.SetStatusCondition(conditions, condition)
+	return true
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This program is a Kubewarden policy running the validation of the Cruiser objects, the
+// ValidateCreate, ValidateUpdate and ValidateDelete methods of the validating webhook, in the clusters
+// disallowing custom admission webhooks. "make wasm-policies" compiles it to wasm with GOOS=wasip1 GOARCH=wasm.
+//
+// The policy server runs it with the validate argument, the validation request on the standard input, and reads
+// the validation response on the standard output.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	shipv2alpha1 "sigs.k8s.io/kubebuilder/testdata/project-v3-multigroup/apis/ship/v2alpha1"
+)
+
+// validationRequest is the request of the validate command
+type validationRequest struct {
+	Request  admissionv1.AdmissionRequest `json:"request"`
+	Settings json.RawMessage              `json:"settings"`
+}
+
+// validationResponse is the response of the validate command
+type validationResponse struct {
+	Accepted bool   `json:"accepted"`
+	Message  string `json:"message,omitempty"`
+	Code     int32  `json:"code,omitempty"`
+}
+
+// settingsValidationResponse is the response of the validate-settings command
+type settingsValidationResponse struct {
+	Valid   bool   `json:"valid"`
+	Message string `json:"message,omitempty"`
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintf(os.Stderr, "usage: %s validate|validate-settings\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	var response interface{}
+	switch os.Args[1] {
+	case "validate":
+		request := validationRequest{}
+		if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+			response = validationResponse{
+				Message: fmt.Sprintf("unable to decode the validation request: %v", err),
+				Code:    http.StatusBadRequest,
+			}
+		} else {
+			response = validate(request.Request)
+		}
+	case "validate-settings":
+		// TODO(user): validate the settings of the policy, read on the standard input, if it has any.
+		response = settingsValidationResponse{Valid: true}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected validate or validate-settings\n", os.Args[1])
+		os.Exit(1)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(response); err != nil {
+		fmt.Fprintf(os.Stderr, "unable to encode the response: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// validate runs the validation of the validating webhook matching the operation of the request
+func validate(request admissionv1.AdmissionRequest) validationResponse {
+	obj, old := &shipv2alpha1.Cruiser{}, &shipv2alpha1.Cruiser{}
+	var err error
+	switch request.Operation {
+	case admissionv1.Create:
+		if err := json.Unmarshal(request.Object.Raw, obj); err != nil {
+			return invalidRequest(err)
+		}
+		err = obj.ValidateCreate()
+	case admissionv1.Update:
+		if err := json.Unmarshal(request.Object.Raw, obj); err != nil {
+			return invalidRequest(err)
+		}
+		if err := json.Unmarshal(request.OldObject.Raw, old); err != nil {
+			return invalidRequest(err)
+		}
+		err = obj.ValidateUpdate(old)
+	case admissionv1.Delete:
+		// The deleted object is the old object of the request
+		if err := json.Unmarshal(request.OldObject.Raw, obj); err != nil {
+			return invalidRequest(err)
+		}
+		err = obj.ValidateDelete()
+	}
+
+	if err == nil {
+		return validationResponse{Accepted: true}
+	}
+	// The API status errors, e.g. the ones of apierrors.NewInvalid, are returned as is, as by the webhook
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return validationResponse{Message: status.Status().Message, Code: status.Status().Code}
+	}
+	return validationResponse{Message: err.Error(), Code: http.StatusForbidden}
+}
+
+// invalidRequest rejects a request whose objects cannot be decoded
+func invalidRequest(err error) validationResponse {
+	return validationResponse{
+		Message: fmt.Sprintf("unable to decode the object: %v", err),
+		Code:    http.StatusBadRequest,
+	}
+}
@@ -17,9 +17,11 @@ limitations under the License.
 package v1
 
 import (
+	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	//+kubebuilder:scaffold:imports
 )
 
 // log is for logging in this package.
@@ -43,3 +45,34 @@ func (r *Admiral) Default() {
 
 	// TODO(user): fill in your defaulting logic.
 }
+
+// TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
+//+kubebuilder:webhook:path=/validate-crew-testproject-org-v1-admiral,mutating=false,failurePolicy=ignore,sideEffects=None,groups=crew.testproject.org,resources=admirals,verbs=create;update,versions=v1,name=vadmiral.kb.io,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Validator = &Admiral{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Admiral) ValidateCreate() error {
+	admirallog.Info("validate create", "name", r.Name)
+
+	// TODO(user): fill in your validation logic upon object creation.
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Admiral) ValidateUpdate(old runtime.Object) error {
+	admirallog.Info("validate update", "name", r.Name)
+
+	// TODO(user): fill in your validation logic upon object update.
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Admiral) ValidateDelete() error {
+	admirallog.Info("validate delete", "name", r.Name)
+
+	// TODO(user): fill in your validation logic upon object deletion.
+	return nil
+}
+
+//+kubebuilder:scaffold:webhooks
@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// The updates of the status subresource are not received by the validating webhook of the Captain,
+// this webhook validates them, e.g. to enforce the invariants of the status written by other controllers.
+//+kubebuilder:webhook:path=/validate-status-crew-testproject-org-v1-captain,mutating=false,failurePolicy=fail,sideEffects=None,groups=crew.testproject.org,resources=captains/status,verbs=update,versions=v1,name=vcaptain-status.kb.io,admissionReviewVersions={v1,v1beta1}
+
+// SetupStatusWebhookWithManager registers the webhook validating the updates of the status of the
+// Captain objects.
+func (r *Captain) SetupStatusWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register("/validate-status-crew-testproject-org-v1-captain", &webhook.Admission{
+		Handler: &captainStatusValidator{},
+	})
+	return nil
+}
+
+// captainStatusValidator validates the updates of the status subresource of the Captain objects
+type captainStatusValidator struct{}
+
+var _ admission.Handler = &captainStatusValidator{}
+
+// Handle implements admission.Handler
+func (v *captainStatusValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	obj, old := &Captain{}, &Captain{}
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if err := obj.ValidateStatusUpdate(old); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// ValidateStatusUpdate validates the update of the status of the Captain from the status of old.
+func (r *Captain) ValidateStatusUpdate(old *Captain) error {
+	// TODO(user): check the invariants of the status, e.g. that a counter never decreases or that a
+	// terminal phase is never left, and return an error describing the violated one.
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestCaptainStatusValidator(t *testing.T) {
+	old := &Captain{ObjectMeta: metav1.ObjectMeta{Name: "captain", Namespace: "default"}}
+	obj := old.DeepCopy()
+	// TODO(user): update the status of obj, and test the updates rejected by ValidateStatusUpdate.
+
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldRaw, err := json.Marshal(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := &captainStatusValidator{}
+	response := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		SubResource: "status",
+		Object:      runtime.RawExtension{Raw: raw},
+		OldObject:   runtime.RawExtension{Raw: oldRaw},
+	}})
+	if !response.Allowed {
+		t.Errorf("expected the update of the status to be allowed, got %v", response.Result)
+	}
+
+	response = validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		SubResource: "status",
+		Object:      runtime.RawExtension{Raw: []byte("{")},
+		OldObject:   runtime.RawExtension{Raw: oldRaw},
+	}})
+	if response.Allowed || response.Result.Code != http.StatusBadRequest {
+		t.Errorf("expected a malformed object to be rejected as a bad request, got %v", response.Result)
+	}
+}
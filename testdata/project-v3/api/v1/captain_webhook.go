@@ -21,12 +21,23 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	//+kubebuilder:scaffold:imports
+
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/webhookmetrics"
 )
 
 // log is for logging in this package.
 var captainlog = logf.Log.WithName("captain-resource")
 
 func (r *Captain) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	// The defaulting and validating webhooks are registered with their metrics first, the builder skips them.
+	// The admission requests over MaxInFlight concurrent ones are rejected, and handled by the API server
+	// according to the failure policy of the webhooks.
+	opts := webhookmetrics.Options{MaxInFlight: 50}
+	if err := webhookmetrics.Register(mgr, r, opts); err != nil {
+		return err
+	}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -73,3 +84,5 @@ func (r *Captain) ValidateDelete() error {
 	// TODO(user): fill in your validation logic upon object deletion.
 	return nil
 }
+
+//+kubebuilder:scaffold:webhooks
@@ -0,0 +1,39 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/ownerlabels"
+)
+
+// TODO(user): replace configmaps with the resources of the objects controlled by the FirstMate.
+// The webhook receives the requests for all the objects of these resources, and only labels the
+// objects controlled by a FirstMate: its failure policy is ignore so that it never blocks
+// the other objects.
+//+kubebuilder:webhook:path=/mutate-owner-labels-crew-testproject-org-v1-firstmate,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=configmaps,verbs=create;update,versions=v1,name=mfirstmate-owner-labels.kb.io,admissionReviewVersions={v1,v1beta1}
+
+// SetupOwnerLabelsWebhookWithManager registers the webhook stamping the app.kubernetes.io labels
+// and the managed-by annotation on the objects controlled by a FirstMate.
+func (r *FirstMate) SetupOwnerLabelsWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register("/mutate-owner-labels-crew-testproject-org-v1-firstmate", &webhook.Admission{
+		Handler: &ownerlabels.Handler{Owner: GroupVersion.WithKind("FirstMate").GroupKind()},
+	})
+	return nil
+}
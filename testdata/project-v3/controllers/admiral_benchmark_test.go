@@ -0,0 +1,137 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v3/api/v1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/expectations"
+)
+
+// BenchmarkAdmiralReconcile creates b.N Admiral objects against envtest, reconciles each
+// of them once and reports the throughput of the reconciler and the percentiles of its latency. Run it with
+// "make bench", which sets b.N to BENCH_OBJECTS, and compare the results of two revisions with benchstat.
+func BenchmarkAdmiralReconcile(b *testing.B) {
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() {
+		if err := testEnv.Stop(); err != nil {
+			b.Error(err)
+		}
+	}()
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		b.Fatal(err)
+	}
+	if err := crewv1.AddToScheme(s); err != nil {
+		b.Fatal(err)
+	}
+
+	// The manager only runs the cache read by the reconciler, whose reconciliations are driven by the benchmark
+	// instead of a controller.
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: s, MetricsBindAddress: "0"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	reconciler := &AdmiralReconciler{
+		Client:       mgr.GetClient(),
+		Log:          ctrl.Log.WithName("benchmark"),
+		Scheme:       s,
+		Recorder:     mgr.GetEventRecorderFor("admiral-benchmark"),
+		Expectations: expectations.New(expectations.DefaultTTL),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() { stopped <- mgr.Start(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-stopped; err != nil {
+			b.Error(err)
+		}
+	}()
+
+	// The objects of each run are labeled, so that a run does not count the objects of the previous ones.
+	run := rand.String(8)
+	labels := map[string]string{"benchmark": run}
+	requests := make([]ctrl.Request, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		obj := &crewv1.Admiral{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("benchmark-%s-%d", run, i),
+				Labels: labels,
+			},
+			// TODO(user): set the spec of a representative Admiral.
+		}
+		if err := mgr.GetClient().Create(ctx, obj); err != nil {
+			b.Fatal(err)
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+	}
+
+	// The reconciler reads from the cache, which must observe all the objects before they are reconciled.
+	if err := wait.PollImmediate(100*time.Millisecond, time.Minute, func() (bool, error) {
+		var list crewv1.AdmiralList
+		if err := mgr.GetClient().List(ctx, &list, client.MatchingLabels(labels)); err != nil {
+			return false, err
+		}
+		return len(list.Items) == b.N, nil
+	}); err != nil {
+		b.Fatalf("the cache did not observe the %d Admiral objects: %v", b.N, err)
+	}
+
+	latencies := make([]time.Duration, 0, b.N)
+	b.ResetTimer()
+	start := time.Now()
+	for _, req := range requests {
+		reconcileStart := time.Now()
+		if _, err := reconciler.Reconcile(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+		latencies = append(latencies, time.Since(reconcileStart))
+	}
+	elapsed := time.Since(start)
+	b.StopTimer()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	b.ReportMetric(float64(b.N)/elapsed.Seconds(), "reconciles/s")
+	for _, p := range []int{50, 90, 99} {
+		latency := latencies[(len(latencies)-1)*p/100]
+		b.ReportMetric(float64(latency.Microseconds())/1000, fmt.Sprintf("p%d-ms", p))
+	}
+}
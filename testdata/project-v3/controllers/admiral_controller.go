@@ -20,23 +20,37 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v3/api/v1"
+	reconcileerrors "sigs.k8s.io/kubebuilder/testdata/project-v3/internal/errors"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/events"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/expectations"
 )
 
 // AdmiralReconciler reconciles a Admiral object
 type AdmiralReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Expectations tracks the ConfigMaps created and deleted by the reconciler that the cache has
+	// not observed yet, set by SetupWithManager if nil.
+	Expectations *expectations.Expectations
 }
 
 //+kubebuilder:rbac:groups=crew.testproject.org,resources=admirals,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=crew.testproject.org,resources=admirals/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=crew.testproject.org,resources=admirals/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -45,19 +59,91 @@ type AdmiralReconciler struct {
 // perform operations to make the cluster state reflect the state specified by
 // the user.
 //
+// Long-running work must stop as soon as ctx is done, which happens when the
+// manager is shutting down, so that it finishes within the graceful shutdown
+// timeout. The internal/checkpoint package helps splitting such work into steps
+// that are resumed by the next reconciliation.
+//
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *AdmiralReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = r.Log.WithValues("admiral", req.NamespacedName)
 
+	var obj crewv1.Admiral
+	if err := r.Get(ctx, req.NamespacedName, &obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			// The expectations of a deleted Admiral are no longer needed.
+			r.Expectations.Forget(req.NamespacedName)
+		}
+		// The object may have been deleted after the reconcile request was queued.
+		return reconcileerrors.Result(client.IgnoreNotFound(err))
+	}
+
+	// Wait for the cache to observe the ConfigMaps created and deleted by the previous
+	// reconciliations: until then, it may miss a ConfigMap that was just created, which would be
+	// created twice. The watch of the ConfigMaps requests a new reconciliation when they are observed.
+	if !r.Expectations.Satisfied(req.NamespacedName) {
+		return ctrl.Result{RequeueAfter: r.Expectations.TTL()}, nil
+	}
+
+	// Create the ConfigMap of this Admiral if the cache does not hold it, recording the
+	// creation as an expectation before creating it.
+	// TODO(user): replace ConfigMap with the type of the objects controlled by the Admiral,
+	// and create and delete them according to its spec, recording every creation with
+	// ExpectCreations and every deletion with ExpectDeletions.
+	child := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: req.Name + "-config", Namespace: "default",
+	}}
+	if err := r.Get(ctx, client.ObjectKeyFromObject(child), child); apierrors.IsNotFound(err) {
+		if err := ctrl.SetControllerReference(&obj, child, r.Scheme); err != nil {
+			return reconcileerrors.Result(err)
+		}
+		r.Expectations.ExpectCreations(req.NamespacedName, child.Name)
+		if err := r.Create(ctx, child); err != nil {
+			// The failed creation will never be observed.
+			r.Expectations.CreationObserved(req.NamespacedName, child.Name)
+			return reconcileerrors.Result(err)
+		}
+	} else if err != nil {
+		return reconcileerrors.Result(err)
+	}
+
 	// your logic here
 
+	// Write the status of the Admiral with status.Patch, which only patches the changes
+	// of the status and applies them again to a fresh copy of the Admiral when they
+	// conflict with the changes of another writer, e.g.:
+	//	if _, err := status.Patch(ctx, r.Client, &obj, func() error {
+	//		status.SetCondition(&obj.Status.Conditions, metav1.Condition{Type: "Available",
+	//			Status: metav1.ConditionTrue, Reason: "Available", ObservedGeneration: obj.Generation})
+	//		return nil
+	//	}); err != nil {
+	//		return reconcileerrors.Result(err)
+	//	}
+
+	// Return the errors of the reconciliation through reconcileerrors.Result: the terminal ones,
+	// e.g. of an invalid spec, are not requeued until the Admiral changes, and the transient
+	// ones are requeued with a backoff, or after the delay of reconcileerrors.RequeueAfter. Record
+	// events about the outcome of the reconciliation with the helpers of the internal/events package,
+	// e.g. when it fails for good:
+	//	if err := validate(&obj); err != nil {
+	//		events.Warning(r.Recorder, &obj, events.ReasonReconcileError, err)
+	//		return reconcileerrors.Result(reconcileerrors.Terminal(err))
+	//	}
+	events.Normal(r.Recorder, &obj, events.ReasonReconciled, "Admiral %s reconciled", req.Name)
+
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *AdmiralReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Expectations == nil {
+		r.Expectations = expectations.New(expectations.DefaultTTL)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&crewv1.Admiral{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, expectations.EnqueueOwner(r.Expectations,
+			crewv1.GroupVersion.WithKind("Admiral"), false)).
 		Complete(r)
 }
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v3/api/v1"
+)
+
+// The sample of the Admiral v1 is applied to the API server of envtest, which
+// defaults and validates it with the CRD generated from the markers of the Go types, as a cluster does.
+var _ = Describe("Admiral v1 sample", func() {
+	samplePath := filepath.Join("..", "config", "samples", "crew_v1_admiral.yaml")
+
+	// sample returns the sample decoded into the Go type, named name, failing if the Go type does not know one
+	// of its fields.
+	sample := func(name string) *crewv1.Admiral {
+		content, err := ioutil.ReadFile(samplePath)
+		Expect(err).NotTo(HaveOccurred())
+		obj := &crewv1.Admiral{}
+		Expect(yaml.UnmarshalStrict(content, obj)).To(Succeed())
+		obj.SetName(name)
+		return obj
+	}
+
+	It("should be defaulted the same way as it is applied or created from the Go type", func() {
+		ctx := context.Background()
+
+		By("applying the sample as is")
+		content, err := ioutil.ReadFile(samplePath)
+		Expect(err).NotTo(HaveOccurred())
+		applied := &unstructured.Unstructured{}
+		Expect(yaml.Unmarshal(content, &applied.Object)).To(Succeed())
+		applied.SetName("admiral-sample-applied")
+		Expect(k8sClient.Create(ctx, applied)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, applied)).To(Succeed())
+		}()
+
+		By("creating the sample decoded into the Go type")
+		obj := sample("admiral-sample-created")
+		Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+		defer func() {
+			Expect(k8sClient.Delete(ctx, obj)).To(Succeed())
+		}()
+
+		By("comparing the specs defaulted by the API server")
+		// The specs differ when the Go type serializes the zero value of a field that the sample does not
+		// set, e.g. a field without omitempty, which the API server does not default then: the field has the
+		// default of its +kubebuilder:default marker when applied, and the zero value of its Go type when
+		// created by a controller.
+		created := &unstructured.Unstructured{}
+		created.SetGroupVersionKind(crewv1.GroupVersion.WithKind("Admiral"))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), created)).To(Succeed())
+		Expect(created.Object["spec"]).To(Equal(applied.Object["spec"]))
+
+		// TODO(user): check the defaults of the fields that the sample does not set, e.g.
+		//	Expect(obj.Spec.Replicas).To(Equal(pointer.Int32Ptr(1)))
+	})
+
+	It("should be rejected when invalid", func() {
+		ctx := context.Background()
+
+		// TODO(user): add the changes of the sample that the validation markers of the Admiral
+		// reject, by their description, e.g.
+		//	"negative replicas": func(obj *crewv1.Admiral) { obj.Spec.Replicas = pointer.Int32Ptr(-1) },
+		invalid := map[string]func(obj *crewv1.Admiral){}
+
+		for description, mutate := range invalid {
+			obj := sample("admiral-sample-invalid")
+			mutate(obj)
+			err := k8sClient.Create(ctx, obj, client.DryRunAll)
+			Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected the sample with %s to be rejected, got %v",
+				description, err)
+		}
+
+		By("accepting the sample")
+		Expect(k8sClient.Create(ctx, sample("admiral-sample-valid"), client.DryRunAll)).To(Succeed())
+	})
+})
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v3/api/v1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/adoption"
+)
+
+var _ = Describe("FirstMate adoption", func() {
+	It("should adopt the labeled ConfigMaps and prune the ConfigMaps no longer desired", func() {
+		ctx := context.Background()
+
+		By("creating two FirstMate objects")
+		owner := &crewv1.FirstMate{
+			ObjectMeta: metav1.ObjectMeta{Name: "adoption-test", Namespace: "default"},
+		}
+		Expect(k8sClient.Create(ctx, owner)).To(Succeed())
+		other := &crewv1.FirstMate{
+			ObjectMeta: metav1.ObjectMeta{Name: "adoption-test-other", Namespace: "default"},
+		}
+		Expect(k8sClient.Create(ctx, other)).To(Succeed())
+
+		By("creating ConfigMaps labeled for the first one, one of them controlled by the second one")
+		labels := map[string]string{firstmateLabel: owner.Name}
+		kept := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "adoption-test-kept", Namespace: "default", Labels: labels,
+		}}
+		stale := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "adoption-test-stale", Namespace: "default", Labels: labels,
+		}}
+		foreign := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "adoption-test-foreign", Namespace: "default", Labels: labels,
+		}}
+		Expect(ctrl.SetControllerReference(other, foreign, scheme.Scheme)).To(Succeed())
+		for _, configMap := range []*corev1.ConfigMap{kept, stale, foreign} {
+			Expect(k8sClient.Create(ctx, configMap)).To(Succeed())
+		}
+
+		By("adopting the ConfigMaps without controller")
+		var children corev1.ConfigMapList
+		Expect(k8sClient.List(ctx, &children, client.InNamespace("default"),
+			client.MatchingLabels(labels))).To(Succeed())
+		adopted, err := adoption.Adopt(ctx, k8sClient, scheme.Scheme, owner, &children)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adopted).To(HaveLen(2))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(stale), stale)).To(Succeed())
+		Expect(metav1.IsControlledBy(stale, owner)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreign), foreign)).To(Succeed())
+		Expect(metav1.IsControlledBy(foreign, other)).To(BeTrue())
+
+		By("pruning the ConfigMaps that are no longer desired")
+		pruned, err := adoption.Prune(ctx, k8sClient, owner, &children, kept.Name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pruned).To(HaveLen(1))
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(stale), stale)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(kept), kept)).To(Succeed())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(foreign), foreign)).To(Succeed())
+	})
+})
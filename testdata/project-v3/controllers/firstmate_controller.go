@@ -20,23 +20,45 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v3/api/v1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/adoption"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/children"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/defaults"
+	reconcileerrors "sigs.k8s.io/kubebuilder/testdata/project-v3/internal/errors"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/events"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/indexer"
 )
 
 // FirstMateReconciler reconciles a FirstMate object
 type FirstMateReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Defaults loads the operator-wide defaults from their ConfigMap, set by SetupWithManager if nil.
+	Defaults *defaults.Loader
 }
 
+// firstmateLabel is the label naming the FirstMate of an object, which selects the
+// objects adopted or pruned by the FirstMate.
+const firstmateLabel = "crew.testproject.org/firstmate"
+
 //+kubebuilder:rbac:groups=crew.testproject.org,resources=firstmates,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=crew.testproject.org,resources=firstmates/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=crew.testproject.org,resources=firstmates/finalizers,verbs=update
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -45,19 +67,135 @@ type FirstMateReconciler struct {
 // perform operations to make the cluster state reflect the state specified by
 // the user.
 //
+// Long-running work must stop as soon as ctx is done, which happens when the
+// manager is shutting down, so that it finishes within the graceful shutdown
+// timeout. The internal/checkpoint package helps splitting such work into steps
+// that are resumed by the next reconciliation.
+//
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.7.0/pkg/reconcile
 func (r *FirstMateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	_ = r.Log.WithValues("firstmate", req.NamespacedName)
 
+	var obj crewv1.FirstMate
+	if err := r.Get(ctx, req.NamespacedName, &obj); err != nil {
+		// The object may have been deleted after the reconcile request was queued.
+		return reconcileerrors.Result(client.IgnoreNotFound(err))
+	}
+
+	// Load the operator-wide defaults of the ConfigMap of the internal/defaults package. An invalid
+	// ConfigMap does not block the reconciliation, which goes on with the defaults last loaded.
+	// TODO(user): use the defaults for the fields that the FirstMate does not set, without
+	// writing them to its spec so that the changes of the ConfigMap apply to it.
+	defaultValues, err := r.Defaults.Load(ctx)
+	if err != nil {
+		events.Warning(r.Recorder, &obj, events.ReasonReconcileError, err)
+	}
+	r.Log.V(1).Info("reconciling with the defaults", "replicas", defaultValues.Replicas)
+
+	// List the ConfigMaps controlled by this FirstMate through the owner field
+	// index registered in SetupWithManager.
+	var owned corev1.ConfigMapList
+	if err := r.List(ctx, &owned, client.InNamespace(req.Namespace), client.MatchingFields{
+		indexer.OwnerField(crewv1.GroupVersion.WithKind("FirstMate")): req.Name,
+	}); err != nil {
+		return reconcileerrors.Result(err)
+	}
+
+	// Adopt the ConfigMaps labeled for this FirstMate that have no controller, e.g. created
+	// before it, then prune the ConfigMaps it controls that are no longer desired. The ConfigMaps
+	// created by the controller must carry the label too.
+	// TODO(user): replace ConfigMap with the type of the objects controlled by the FirstMate,
+	// and compute the names of the desired objects from its spec.
+	var children corev1.ConfigMapList
+	if err := r.List(ctx, &children, client.InNamespace(req.Namespace),
+		client.MatchingLabels{firstmateLabel: req.Name}); err != nil {
+		return reconcileerrors.Result(err)
+	}
+	if _, err := adoption.Adopt(ctx, r.Client, r.Scheme, &obj, &children); err != nil {
+		return reconcileerrors.Result(err)
+	}
+	var desired []string
+	if _, err := adoption.Prune(ctx, r.Client, &obj, &children, desired...); err != nil {
+		return reconcileerrors.Result(err)
+	}
+
+	// Create or patch the objects controlled by this FirstMate, whose desired state is only
+	// applied again when the spec of the FirstMate changes.
+	if err := r.reconcileDeployment(ctx, &obj); err != nil {
+		return reconcileerrors.Result(err)
+	}
+
 	// your logic here
 
+	// Write the status of the FirstMate with status.Patch, which only patches the changes
+	// of the status and applies them again to a fresh copy of the FirstMate when they
+	// conflict with the changes of another writer, e.g.:
+	//	if _, err := status.Patch(ctx, r.Client, &obj, func() error {
+	//		status.SetCondition(&obj.Status.Conditions, metav1.Condition{Type: "Available",
+	//			Status: metav1.ConditionTrue, Reason: "Available", ObservedGeneration: obj.Generation})
+	//		return nil
+	//	}); err != nil {
+	//		return reconcileerrors.Result(err)
+	//	}
+
+	// Return the errors of the reconciliation through reconcileerrors.Result: the terminal ones,
+	// e.g. of an invalid spec, are not requeued until the FirstMate changes, and the transient
+	// ones are requeued with a backoff, or after the delay of reconcileerrors.RequeueAfter. Record
+	// events about the outcome of the reconciliation with the helpers of the internal/events package,
+	// e.g. when it fails for good:
+	//	if err := validate(&obj); err != nil {
+	//		events.Warning(r.Recorder, &obj, events.ReasonReconcileError, err)
+	//		return reconcileerrors.Result(reconcileerrors.Terminal(err))
+	//	}
+	events.Normal(r.Recorder, &obj, events.ReasonReconciled, "FirstMate %s reconciled", req.Name)
+
 	return ctrl.Result{}, nil
 }
 
+// reconcileDeployment creates or patches the Deployment controlled by the FirstMate.
+func (r *FirstMateReconciler) reconcileDeployment(ctx context.Context, obj *crewv1.FirstMate) error {
+	child := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name: obj.Name, Namespace: obj.Namespace,
+	}}
+	// TODO(user): set the fields of the Deployment from the spec of the FirstMate in the mutate
+	// function, which is only called when the spec changed since the Deployment was last patched. Pass
+	// the fields of the spec it reads instead of the whole spec, so that the other changes are ignored.
+	result, err := children.CreateOrPatch(ctx, r.Client, r.Scheme, obj, child, obj.Spec, func() error {
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if result != controllerutil.OperationResultNone {
+		r.Log.V(1).Info("Deployment reconciled", "deployment", client.ObjectKeyFromObject(child),
+			"operation", result)
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *FirstMateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// TODO(user): replace ConfigMap with the type of the objects controlled by the FirstMate.
+	if err := indexer.IndexOwner(context.Background(), mgr.GetFieldIndexer(), &corev1.ConfigMap{},
+		crewv1.GroupVersion.WithKind("FirstMate")); err != nil {
+		return err
+	}
+
+	if r.Defaults == nil {
+		loader, err := defaults.NewLoader(mgr)
+		if err != nil {
+			return err
+		}
+		r.Defaults = loader
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&crewv1.FirstMate{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&appsv1.Deployment{}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			adoption.EnqueueOwner(firstmateLabel, true)).
+		Watches(r.Defaults.Source(), r.Defaults.EnqueueAll(mgr.GetClient(), &crewv1.FirstMateList{})).
 		Complete(r)
 }
@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v3/api/v1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v3/internal/indexer"
+)
+
+var _ = Describe("FirstMate owner index", func() {
+	It("should list the ConfigMaps controlled by a FirstMate", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		By("starting a manager with the owner field index")
+		mgr, err := ctrl.NewManager(testEnv.Config, ctrl.Options{Scheme: scheme.Scheme, MetricsBindAddress: "0"})
+		Expect(err).NotTo(HaveOccurred())
+		ownerGVK := crewv1.GroupVersion.WithKind("FirstMate")
+		Expect(indexer.IndexOwner(ctx, mgr.GetFieldIndexer(), &corev1.ConfigMap{}, ownerGVK)).To(Succeed())
+		go func() {
+			defer GinkgoRecover()
+			Expect(mgr.Start(ctx)).To(Succeed())
+		}()
+
+		By("creating a FirstMate that controls a ConfigMap")
+		owner := &crewv1.FirstMate{
+			ObjectMeta: metav1.ObjectMeta{Name: "owner-index-test", Namespace: "default"},
+		}
+		Expect(k8sClient.Create(ctx, owner)).To(Succeed())
+		owned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner-index-test", Namespace: "default"}}
+		Expect(ctrl.SetControllerReference(owner, owned, scheme.Scheme)).To(Succeed())
+		Expect(k8sClient.Create(ctx, owned)).To(Succeed())
+		unowned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owner-index-test-unowned", Namespace: "default"}}
+		Expect(k8sClient.Create(ctx, unowned)).To(Succeed())
+
+		By("listing the ConfigMaps through the owner field index")
+		Eventually(func() ([]corev1.ConfigMap, error) {
+			var list corev1.ConfigMapList
+			err := mgr.GetClient().List(ctx, &list, client.InNamespace("default"),
+				client.MatchingFields{indexer.OwnerField(ownerGVK): owner.Name})
+			return list.Items, err
+		}, 10*time.Second).Should(HaveLen(1))
+	})
+})
@@ -0,0 +1,221 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package defaults loads the operator-wide defaults of the controllers from a ConfigMap in the
+// namespace of the manager, deployed by config/components/defaults, so that they are tuned
+// without rebuilding the manager.
+//
+// The ConfigMap is watched: the reconciliations load its changes, and the controllers reconcile
+// all their objects again when it changes. The defaults that it does not set, or all of them if
+// it does not exist, are the builtin ones. An invalid ConfigMap does not stop the controllers,
+// which keep the defaults last loaded and report the error.
+package defaults
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ConfigMapName is the name of the ConfigMap of the defaults, including the prefix added to
+	// the names by config/default/kustomization.yaml.
+	ConfigMapName = "project-v3-defaults"
+
+	// Key is the key of the defaults in the data of the ConfigMap.
+	Key = "defaults.yaml"
+
+	// defaultNamespace is the namespace of the manager deployed by config/default, in which the
+	// ConfigMap is read when the manager runs out of the cluster, e.g. with make run.
+	defaultNamespace = "project-v3-system"
+
+	namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+var log = ctrl.Log.WithName("defaults")
+
+// Defaults are the operator-wide defaults of the controllers.
+// TODO(user): add the defaults of the fields of your resources, and their builtin values to Builtin.
+type Defaults struct {
+	// Replicas is an example default, the number of replicas of the objects that do not set it.
+	Replicas int32 `json:"replicas,omitempty"`
+}
+
+// Builtin returns the defaults used when the ConfigMap does not set them.
+func Builtin() Defaults {
+	return Defaults{
+		Replicas: 1,
+	}
+}
+
+// Parse returns the defaults of the data of the ConfigMap, with the builtin values for the
+// defaults it does not set.
+func Parse(data map[string]string) (Defaults, error) {
+	defaults := Builtin()
+	if err := yaml.UnmarshalStrict([]byte(data[Key]), &defaults); err != nil {
+		return Builtin(), fmt.Errorf("invalid %s of ConfigMap %s: %v", Key, ConfigMapName, err)
+	}
+
+	// TODO(user): validate the defaults.
+	if defaults.Replicas < 0 {
+		return Builtin(), fmt.Errorf("invalid %s of ConfigMap %s: replicas must not be negative", Key, ConfigMapName)
+	}
+	return defaults, nil
+}
+
+// Namespace returns the namespace of the manager: the POD_NAMESPACE environment variable if
+// set, the namespace of its service account in the cluster, and the namespace of config/default
+// out of the cluster.
+func Namespace() string {
+	if namespace := os.Getenv("POD_NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	if content, err := ioutil.ReadFile(namespaceFile); err == nil {
+		if namespace := strings.TrimSpace(string(content)); namespace != "" {
+			return namespace
+		}
+	}
+	return defaultNamespace
+}
+
+// Loader loads the defaults from the ConfigMap, parsing it again only when it changes. The
+// controllers sharing the defaults can share a Loader.
+type Loader struct {
+	reader client.Reader
+	cache  cache.Cache
+	key    types.NamespacedName
+
+	mu              sync.Mutex
+	resourceVersion string
+	defaults        Defaults
+	err             error
+}
+
+// NewLoader returns a Loader reading the ConfigMap of the namespace of the manager from a cache
+// of the ConfigMaps of this namespace only, started by mgr, so that the manager only needs to
+// read the ConfigMaps of its namespace.
+func NewLoader(mgr ctrl.Manager) (*Loader, error) {
+	namespace := Namespace()
+	c, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:    mgr.GetScheme(),
+		Mapper:    mgr.GetRESTMapper(),
+		Namespace: namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := mgr.Add(c); err != nil {
+		return nil, err
+	}
+
+	loader := NewLoaderWithReader(c, namespace)
+	loader.cache = c
+	return loader, nil
+}
+
+// NewLoaderWithReader returns a Loader reading the ConfigMap of namespace with reader, e.g. a
+// fake client in tests.
+func NewLoaderWithReader(reader client.Reader, namespace string) *Loader {
+	return &Loader{
+		reader:   reader,
+		key:      types.NamespacedName{Namespace: namespace, Name: ConfigMapName},
+		defaults: Builtin(),
+	}
+}
+
+// Load returns the defaults of the ConfigMap, or the builtin defaults if it does not exist. If
+// the ConfigMap is invalid, it returns the defaults last loaded with the error. A nil Loader, e.g.
+// of a reconciler created by a test, returns the builtin defaults.
+func (l *Loader) Load(ctx context.Context) (Defaults, error) {
+	if l == nil {
+		return Builtin(), nil
+	}
+
+	var configMap corev1.ConfigMap
+	if err := l.reader.Get(ctx, l.key, &configMap); err != nil && !apierrors.IsNotFound(err) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.defaults, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// A missing ConfigMap has no resource version, like the builtin defaults
+	if configMap.ResourceVersion == l.resourceVersion {
+		return l.defaults, l.err
+	}
+	l.resourceVersion = configMap.ResourceVersion
+	defaults, err := Parse(configMap.Data)
+	if err != nil {
+		l.err = err
+		return l.defaults, err
+	}
+	l.defaults, l.err = defaults, nil
+	log.Info("loaded the defaults", "configMap", l.key, "resourceVersion", l.resourceVersion)
+	return l.defaults, nil
+}
+
+// Source returns the source of the events of the ConfigMaps, from the cache of the Loader if it
+// was created by NewLoader, from the cache of the manager otherwise.
+func (l *Loader) Source() source.Source {
+	if l.cache == nil {
+		return &source.Kind{Type: &corev1.ConfigMap{}}
+	}
+	return source.NewKindWithCache(&corev1.ConfigMap{}, l.cache)
+}
+
+// EnqueueAll returns the handler requesting the reconciliation of all the objects of the type of
+// list, listed with reader, when the ConfigMap of the defaults changes.
+func (l *Loader) EnqueueAll(reader client.Reader, list client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		if obj.GetNamespace() != l.key.Namespace || obj.GetName() != l.key.Name {
+			return nil
+		}
+
+		objs := list.DeepCopyObject().(client.ObjectList)
+		if err := reader.List(context.Background(), objs); err != nil {
+			log.Error(err, "unable to list the objects to reconcile with the new defaults")
+			return nil
+		}
+		items, err := meta.ExtractList(objs)
+		if err != nil {
+			log.Error(err, "unable to list the objects to reconcile with the new defaults")
+			return nil
+		}
+		requests := make([]reconcile.Request, 0, len(items))
+		for _, item := range items {
+			if obj, ok := item.(client.Object); ok {
+				requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)})
+			}
+		}
+		return requests
+	})
+}
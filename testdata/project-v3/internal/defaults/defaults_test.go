@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestLoad(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().Build()
+	loader := NewLoaderWithReader(c, "default")
+
+	defaults, err := loader.Load(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults != Builtin() {
+		t.Errorf("expected the builtin defaults without ConfigMap, got %+v", defaults)
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: "default"},
+		Data:       map[string]string{Key: "replicas: 3"},
+	}
+	if err := c.Create(ctx, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if defaults, err = loader.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if defaults.Replicas != 3 {
+		t.Errorf("expected the replicas of the ConfigMap, got %d", defaults.Replicas)
+	}
+
+	// An invalid ConfigMap keeps the defaults last loaded
+	configMap.Data[Key] = "replicas: -1"
+	if err := c.Update(ctx, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if defaults, err = loader.Load(ctx); err == nil {
+		t.Error("expected an error for an invalid ConfigMap")
+	}
+	if defaults.Replicas != 3 {
+		t.Errorf("expected the replicas last loaded, got %d", defaults.Replicas)
+	}
+
+	if err := c.Delete(ctx, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if defaults, err = loader.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if defaults != Builtin() {
+		t.Errorf("expected the builtin defaults once the ConfigMap is deleted, got %+v", defaults)
+	}
+}
+
+func TestLoadNil(t *testing.T) {
+	var loader *Loader
+	if defaults, err := loader.Load(context.Background()); err != nil || defaults != Builtin() {
+		t.Errorf("expected the builtin defaults of a nil Loader, got %+v, %v", defaults, err)
+	}
+}
+
+func TestParseUnknownField(t *testing.T) {
+	if _, err := Parse(map[string]string{Key: "unknown: 1"}); err == nil {
+		t.Error("expected an error for an unknown default")
+	}
+}
+
+func TestEnqueueAll(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	for _, name := range []string{"first", "second"} {
+		if err := c.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loader := NewLoaderWithReader(c, "default")
+	handler := loader.EnqueueAll(c, &corev1.SecretList{})
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+
+	handler.Create(event.CreateEvent{Object: &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+	}}, queue)
+	if queue.Len() != 0 {
+		t.Errorf("expected no reconciliation for another ConfigMap, got %d", queue.Len())
+	}
+
+	handler.Create(event.CreateEvent{Object: &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: "default"},
+	}}, queue)
+	if queue.Len() != 2 {
+		t.Errorf("expected the reconciliation of all the objects, got %d", queue.Len())
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package expectations tracks the children that a controller created or deleted but that its
+// cache has not observed yet, like the expectations of the ReplicaSet controller.
+//
+// The cache of a controller lags behind the API server: a reconciliation that follows the
+// creation of a child may not find it in the cache and create it a second time, or count a
+// deleted child that is still cached. The controller records the children it creates and
+// deletes as expectations of their owner, the watch of the children observes them, and the
+// owner is reconciled again only once its expectations are satisfied. The expectations that
+// are not observed within their TTL, e.g. because an event was missed, expire so that the
+// owner is not blocked forever.
+package expectations
+
+import (
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultTTL is the time after which the expectations that were not observed expire, the same
+// as the ReplicaSet controller.
+const DefaultTTL = 5 * time.Minute
+
+// Expectations tracks, for each owner, the names of the children created and deleted by its
+// controller that were not observed yet. It is safe for concurrent use.
+type Expectations struct {
+	ttl   time.Duration
+	clock clock.PassiveClock
+
+	lock    sync.Mutex
+	pending map[types.NamespacedName]*pending
+}
+
+type pending struct {
+	creations map[string]bool
+	deletions map[string]bool
+	deadline  time.Time
+}
+
+// New returns empty expectations that expire after ttl.
+func New(ttl time.Duration) *Expectations {
+	return NewWithClock(ttl, clock.RealClock{})
+}
+
+// NewWithClock returns empty expectations that expire after ttl, measured by c.
+func NewWithClock(ttl time.Duration, c clock.PassiveClock) *Expectations {
+	return &Expectations{ttl: ttl, clock: c, pending: make(map[types.NamespacedName]*pending)}
+}
+
+// TTL returns the time after which the expectations that were not observed expire.
+func (e *Expectations) TTL() time.Duration {
+	return e.ttl
+}
+
+// ExpectCreations records that the children named names are about to be created for owner.
+// Call it before creating them, and call CreationObserved for the ones whose creation failed.
+func (e *Expectations) ExpectCreations(owner types.NamespacedName, names ...string) {
+	e.expect(owner, names, func(p *pending) map[string]bool { return p.creations })
+}
+
+// ExpectDeletions records that the children named names are about to be deleted for owner.
+// Call it before deleting them, and call DeletionObserved for the ones whose deletion failed.
+func (e *Expectations) ExpectDeletions(owner types.NamespacedName, names ...string) {
+	e.expect(owner, names, func(p *pending) map[string]bool { return p.deletions })
+}
+
+// CreationObserved records that the creation of the child named name of owner was observed, or
+// that it will never be because the creation failed.
+func (e *Expectations) CreationObserved(owner types.NamespacedName, name string) {
+	e.observe(owner, name, func(p *pending) map[string]bool { return p.creations })
+}
+
+// DeletionObserved records that the deletion of the child named name of owner was observed, or
+// that it will never be because the deletion failed.
+func (e *Expectations) DeletionObserved(owner types.NamespacedName, name string) {
+	e.observe(owner, name, func(p *pending) map[string]bool { return p.deletions })
+}
+
+// Satisfied returns true if the creations and the deletions expected for owner were all observed
+// or expired: the cache then reflects the children of owner, which can be reconciled.
+func (e *Expectations) Satisfied(owner types.NamespacedName) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	p, found := e.pending[owner]
+	if !found {
+		return true
+	}
+	if e.clock.Now().After(p.deadline) {
+		delete(e.pending, owner)
+		return true
+	}
+	return false
+}
+
+// Forget drops the expectations of owner, e.g. once it is deleted.
+func (e *Expectations) Forget(owner types.NamespacedName) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	delete(e.pending, owner)
+}
+
+func (e *Expectations) expect(owner types.NamespacedName, names []string, set func(*pending) map[string]bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	p, found := e.pending[owner]
+	if !found {
+		p = &pending{creations: make(map[string]bool), deletions: make(map[string]bool)}
+		e.pending[owner] = p
+	}
+	for _, name := range names {
+		set(p)[name] = true
+	}
+	p.deadline = e.clock.Now().Add(e.ttl)
+}
+
+func (e *Expectations) observe(owner types.NamespacedName, name string, set func(*pending) map[string]bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	p, found := e.pending[owner]
+	if !found {
+		return
+	}
+	delete(set(p), name)
+	if len(p.creations) == 0 && len(p.deletions) == 0 {
+		delete(e.pending, owner)
+	}
+}
+
+// EnqueueOwner returns an event handler that observes the creations and the deletions of the
+// children controlled by an owner of kind owner, then requests the reconciliation of their owner.
+// The owner is looked up in the namespace of the children if namespaced, at the cluster scope
+// otherwise.
+func EnqueueOwner(e *Expectations, owner schema.GroupVersionKind, namespaced bool) handler.EventHandler {
+	return &enqueueOwner{expectations: e, owner: owner.GroupKind(), namespaced: namespaced}
+}
+
+type enqueueOwner struct {
+	expectations *Expectations
+	owner        schema.GroupKind
+	namespaced   bool
+}
+
+// Create implements handler.EventHandler
+func (h *enqueueOwner) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	if owner, found := h.ownerOf(evt.Object); found {
+		h.expectations.CreationObserved(owner, evt.Object.GetName())
+		q.Add(reconcile.Request{NamespacedName: owner})
+	}
+}
+
+// Update implements handler.EventHandler
+func (h *enqueueOwner) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	if owner, found := h.ownerOf(evt.ObjectOld); found {
+		q.Add(reconcile.Request{NamespacedName: owner})
+	}
+	if owner, found := h.ownerOf(evt.ObjectNew); found {
+		// A child being deleted gracefully is as good as deleted for its owner
+		if evt.ObjectNew.GetDeletionTimestamp() != nil {
+			h.expectations.DeletionObserved(owner, evt.ObjectNew.GetName())
+		}
+		q.Add(reconcile.Request{NamespacedName: owner})
+	}
+}
+
+// Delete implements handler.EventHandler
+func (h *enqueueOwner) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	if owner, found := h.ownerOf(evt.Object); found {
+		h.expectations.DeletionObserved(owner, evt.Object.GetName())
+		q.Add(reconcile.Request{NamespacedName: owner})
+	}
+}
+
+// Generic implements handler.EventHandler
+func (h *enqueueOwner) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	if owner, found := h.ownerOf(evt.Object); found {
+		q.Add(reconcile.Request{NamespacedName: owner})
+	}
+}
+
+// ownerOf returns the owner of obj, if it is controlled by an owner of the kind of h.
+func (h *enqueueOwner) ownerOf(obj client.Object) (types.NamespacedName, bool) {
+	if obj == nil {
+		return types.NamespacedName{}, false
+	}
+	ref := metav1.GetControllerOf(obj)
+	if ref == nil || ref.Kind != h.owner.Kind {
+		return types.NamespacedName{}, false
+	}
+	if gv, err := schema.ParseGroupVersion(ref.APIVersion); err != nil || gv.Group != h.owner.Group {
+		return types.NamespacedName{}, false
+	}
+	owner := types.NamespacedName{Name: ref.Name}
+	if h.namespaced {
+		owner.Namespace = obj.GetNamespace()
+	}
+	return owner, true
+}
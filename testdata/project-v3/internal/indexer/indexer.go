@@ -0,0 +1,52 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package indexer registers field indexes in the manager cache, which allow listing
+// objects with client.MatchingFields instead of filtering all of them in memory.
+package indexer
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// OwnerField returns the name of the field index that maps objects to the name of their
+// controller owner of the given kind.
+func OwnerField(owner schema.GroupVersionKind) string {
+	return ".metadata.controller." + owner.GroupKind().String()
+}
+
+// IndexOwner registers the OwnerField index for the objects of the type of obj, so that
+// the objects controlled by an owner can be listed with:
+//
+//	client.MatchingFields{indexer.OwnerField(owner): ownerName}
+func IndexOwner(ctx context.Context, fieldIndexer client.FieldIndexer, obj client.Object,
+	owner schema.GroupVersionKind) error {
+	return fieldIndexer.IndexField(ctx, obj, OwnerField(owner), func(o client.Object) []string {
+		ref := metav1.GetControllerOf(o)
+		if ref == nil || ref.Kind != owner.Kind {
+			return nil
+		}
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil || gv.Group != owner.Group {
+			return nil
+		}
+		return []string{ref.Name}
+	})
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhookmetrics instruments the defaulting and validating webhooks of the project with
+// Prometheus metrics, since their latency is otherwise invisible until the API server starts timing
+// out, and optionally limits the number of admission requests each webhook serves concurrently,
+// shedding the others before they pile up.
+//
+// The metrics are served with the controller-runtime ones by the metrics endpoint of the manager:
+//
+//   - webhook_admission_duration_seconds is a histogram of the latency of the admission requests,
+//     by webhook, operation (CREATE, UPDATE, DELETE or CONNECT) and result (allowed, denied, error
+//     or shed).
+//   - webhook_admission_requests_in_flight is the number of admission requests being served by
+//     each webhook.
+package webhookmetrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// The results of the admission requests
+const (
+	// ResultAllowed is the result of the allowed requests, patched or not
+	ResultAllowed = "allowed"
+	// ResultDenied is the result of the requests denied by the webhook
+	ResultDenied = "denied"
+	// ResultError is the result of the requests the webhook failed to serve, e.g. undecodable objects
+	ResultError = "error"
+	// ResultShed is the result of the requests rejected because the webhook served too many requests
+	ResultShed = "shed"
+)
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "webhook_admission_duration_seconds",
+		Help: "Latency of the admission requests served by the webhooks, by operation and result",
+		// The API server times out the admission requests after 10 seconds by default
+		Buckets: []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, []string{"webhook", "operation", "result"})
+
+	requestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "webhook_admission_requests_in_flight",
+		Help: "Number of admission requests being served by the webhooks",
+	}, []string{"webhook"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(requestDuration, requestsInFlight)
+}
+
+var errTooManyRequests = errors.New("too many admission requests in flight, retry later")
+
+// Options are the options of the instrumented webhooks
+type Options struct {
+	// MaxInFlight is the maximum number of admission requests served concurrently by each webhook.
+	// The other requests are rejected at once with a 429 error, which the API server handles
+	// according to the failure policy of the webhook. Zero means no limit.
+	MaxInFlight int
+}
+
+// Register registers the defaulting and validating webhooks of obj, as implemented by its Default
+// and Validate methods, instrumented with the metrics. The webhooks are served on the paths of the
+// webhook builder of controller-runtime: call Register before ctrl.NewWebhookManagedBy, which
+// skips the paths already registered.
+func Register(mgr ctrl.Manager, obj runtime.Object, opts Options) error {
+	gvk, err := apiutil.GVKForObject(obj, mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	name := strings.Replace(gvk.Group, ".", "-", -1) + "-" + gvk.Version + "-" + strings.ToLower(gvk.Kind)
+
+	server := mgr.GetWebhookServer()
+	if defaulter, ok := obj.(admission.Defaulter); ok {
+		path := "/mutate-" + name
+		server.Register(path, Instrument(path, admission.DefaultingWebhookFor(defaulter), opts))
+	}
+	if validator, ok := obj.(admission.Validator); ok {
+		path := "/validate-" + name
+		server.Register(path, Instrument(path, admission.ValidatingWebhookFor(validator), opts))
+	}
+	return nil
+}
+
+// Instrument returns a webhook serving the admission requests with the handler of hook, which
+// records their metrics under the webhook label name and limits their concurrency as set by opts.
+func Instrument(name string, hook *admission.Webhook, opts Options) *admission.Webhook {
+	h := &handler{name: name, handler: hook.Handler}
+	if opts.MaxInFlight > 0 {
+		h.slots = make(chan struct{}, opts.MaxInFlight)
+	}
+	return &admission.Webhook{Handler: h}
+}
+
+// handler records the metrics of the admission requests served by the instrumented handler
+type handler struct {
+	name    string
+	handler admission.Handler
+	// slots holds a value per request in flight, when their number is limited
+	slots chan struct{}
+}
+
+var (
+	_ admission.DecoderInjector = &handler{}
+	_ inject.Injector           = &handler{}
+)
+
+// Handle implements admission.Handler
+func (h *handler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	start := time.Now()
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+			defer func() { <-h.slots }()
+		default:
+			observe(h.name, req, ResultShed, start)
+			return admission.Errored(http.StatusTooManyRequests, errTooManyRequests)
+		}
+	}
+
+	inFlight := requestsInFlight.WithLabelValues(h.name)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
+	resp := h.handler.Handle(ctx, req)
+	observe(h.name, req, result(resp), start)
+	return resp
+}
+
+// InjectDecoder injects the decoder of the webhook into the instrumented handler
+func (h *handler) InjectDecoder(decoder *admission.Decoder) error {
+	_, err := admission.InjectDecoderInto(decoder, h.handler)
+	return err
+}
+
+// InjectFunc injects the dependencies of the webhook into the instrumented handler
+func (h *handler) InjectFunc(f inject.Func) error {
+	return f(h.handler)
+}
+
+// observe records the latency of an admission request since start
+func observe(name string, req admission.Request, result string, start time.Time) {
+	requestDuration.WithLabelValues(name, string(req.Operation), result).Observe(time.Since(start).Seconds())
+}
+
+// result returns the result of an admission request from its response. The webhooks of
+// controller-runtime answer 400 to the undecodable requests, and the validation errors of the
+// Validate methods are denials, with the code of their status if they are API errors.
+func result(resp admission.Response) string {
+	switch {
+	case resp.Allowed:
+		return ResultAllowed
+	case resp.Result != nil && (resp.Result.Code == http.StatusBadRequest ||
+		resp.Result.Code >= http.StatusInternalServerError):
+		return ResultError
+	default:
+		return ResultDenied
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhookmetrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestResult(t *testing.T) {
+	invalid := apierrors.NewInvalid(schema.GroupKind{Kind: "Frigate"}, "frigate", nil)
+	for _, tc := range []struct {
+		name     string
+		resp     admission.Response
+		expected string
+	}{
+		{"allowed", admission.Allowed(""), ResultAllowed},
+		{"denied", admission.Denied("no"), ResultDenied},
+		{"invalid", admission.Response{AdmissionResponse: admissionv1.AdmissionResponse{
+			Result: &invalid.ErrStatus}}, ResultDenied},
+		{"undecodable", admission.Errored(http.StatusBadRequest, errors.New("bad")), ResultError},
+		{"failed", admission.Errored(http.StatusInternalServerError, errors.New("failed")), ResultError},
+	} {
+		if actual := result(tc.resp); actual != tc.expected {
+			t.Errorf("%s: expected the result %s, got %s", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestHandleSheds(t *testing.T) {
+	release := make(chan struct{})
+	served := make(chan struct{}, 2)
+	hook := Instrument("/validate-test-shed", &admission.Webhook{
+		Handler: admission.HandlerFunc(func(context.Context, admission.Request) admission.Response {
+			served <- struct{}{}
+			<-release
+			return admission.Allowed("")
+		}),
+	}, Options{MaxInFlight: 1})
+
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Create}}
+	done := make(chan admission.Response)
+	go func() { done <- hook.Handler.Handle(context.Background(), req) }()
+	<-served
+
+	if gauge := testutil.ToFloat64(requestsInFlight.WithLabelValues("/validate-test-shed")); gauge != 1 {
+		t.Errorf("expected 1 request in flight, got %v", gauge)
+	}
+	resp := hook.Handler.Handle(context.Background(), req)
+	if resp.Allowed || resp.Result.Code != http.StatusTooManyRequests {
+		t.Errorf("expected the request over the limit to be shed, got %+v", resp.Result)
+	}
+
+	close(release)
+	if resp := <-done; !resp.Allowed {
+		t.Errorf("expected the request in flight to be allowed, got %+v", resp.Result)
+	}
+	if resp := hook.Handler.Handle(context.Background(), req); !resp.Allowed {
+		t.Errorf("expected the requests to be served once the request in flight is done, got %+v", resp.Result)
+	}
+	for result, expected := range map[string]uint64{ResultAllowed: 2, ResultShed: 1} {
+		metric := &dto.Metric{}
+		observer := requestDuration.WithLabelValues("/validate-test-shed", "CREATE", result)
+		if err := observer.(prometheus.Metric).Write(metric); err != nil {
+			t.Fatal(err)
+		}
+		if count := metric.GetHistogram().GetSampleCount(); count != expected {
+			t.Errorf("expected %d %s requests, got %d", expected, result, count)
+		}
+	}
+}